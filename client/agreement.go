@@ -0,0 +1,218 @@
+package client
+
+import (
+"github.com/wipro-blockchain/TF-v1/internal/router"
+)
+
+// ============================================================================================================================
+// agreementArgs - the positional arguments of create_agreement and update_agreement
+// ============================================================================================================================
+func agreementArgs(record Agreement) []string {
+	return []string{record.AgreementID, record.TransID, record.Agreement_status, record.BuyerName, record.SellerName,
+		record.ShipperName, record.BB_name, record.SB_name, record.PortAuthName, record.AgreementCU_date, record.ItemId,
+		record.Item_name, record.Item_quantity, record.Total_Value, record.Delivery_date, record.ExtraCharges,
+		record.Shipper_fees, record.DocumentName, record.DocumentURL, record.TC_Text, record.Buyer_sign,
+		record.BuyerBank_sign, record.Seller_sign, record.SellerBank_sign, record.Industry, record.GoodsPrice}
+}
+// ============================================================================================================================
+// CreateAgreement - create an Agreement, the clearance and shipping status are set by the chaincode. Without an agreementId
+// the chaincode generates one, e.g. AGR-1F2E3D4C5B6A7980
+// ============================================================================================================================
+func (c *Client) CreateAgreement(record Agreement) (*Agreement, error) {
+	agreementId, err := c.create("create_agreement", agreementArgs(record)...)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetAgreement(agreementId)
+}
+// ============================================================================================================================
+// UpdateAgreement - replace an Agreement, a party signs by updating it with its sign field set. ErrConflict when it changed
+// since record was read
+// ============================================================================================================================
+func (c *Client) UpdateAgreement(record Agreement) (*Agreement, error) {
+	if _, err := c.Submit("update_agreement", router.WithVersion(agreementArgs(record), record.Version)...); err != nil {
+		return nil, err
+	}
+	return c.GetAgreement(record.AgreementID)
+}
+// ============================================================================================================================
+// PatchAgreement - change only the fields of an Agreement given in patch, e.g. Patch{"buyerBank_sign": "true"}
+// ============================================================================================================================
+func (c *Client) PatchAgreement(agreementId string, patch Patch) (*Agreement, error) {
+	if _, err := c.Submit("update_agreement", patchArg("agreementId", agreementId, patch)); err != nil {
+		return nil, err
+	}
+	return c.GetAgreement(agreementId)
+}
+// ============================================================================================================================
+// DeleteAgreement - delete an Agreement
+// ============================================================================================================================
+func (c *Client) DeleteAgreement(agreementId string) error {
+	_, err := c.Submit("delete_agreement", agreementId)
+	return err
+}
+// ============================================================================================================================
+// AddFraud - add a party to the fraud list
+// ============================================================================================================================
+func (c *Client) AddFraud(fraud Fraud) error {
+	_, err := c.Submit("update_fraud_list", fraud.FraudID, fraud.FraudName)
+	return err
+}
+// ============================================================================================================================
+// UpdateClearanceStatus - mirror the port clearance status of a shipment on its Agreement
+// ============================================================================================================================
+func (c *Client) UpdateClearanceStatus(agreementId string, shipmentId string, clearanceStatus string) (*Agreement, error) {
+	if _, err := c.Submit("update_clearance_status", agreementId, shipmentId, clearanceStatus); err != nil {
+		return nil, err
+	}
+	return c.GetAgreement(agreementId)
+}
+// ============================================================================================================================
+// SetLiquidatedDamages - set the late delivery terms of an Agreement
+// ============================================================================================================================
+func (c *Client) SetLiquidatedDamages(terms LiquidatedDamages) (*LiquidatedDamages, error) {
+	if _, err := c.Submit("set_liquidated_damages", terms.AgreementID, terms.RatePerDay, terms.CapPercent, terms.GraceDays); err != nil {
+		return nil, err
+	}
+	return c.GetLiquidatedDamages(terms.AgreementID)
+}
+// ============================================================================================================================
+// RecordShippedQuantity - book the items of a shipment against the ordered quantity of an Agreement
+// ============================================================================================================================
+func (c *Client) RecordShippedQuantity(agreementId string, shipmentId string, items []ShipmentItem) (*ShippedBalance, error) {
+	if _, err := c.Submit("record_shipped_quantity", agreementId, shipmentId, jsonArg(items)); err != nil {
+		return nil, err
+	}
+	return c.GetShippedBalance(agreementId)
+}
+// ============================================================================================================================
+// ReleaseShippedQuantity - give the items of a deleted shipment back to the ordered quantity of an Agreement
+// ============================================================================================================================
+func (c *Client) ReleaseShippedQuantity(agreementId string, shipmentId string, items []ShipmentItem) (*ShippedBalance, error) {
+	if _, err := c.Submit("release_shipped_quantity", agreementId, shipmentId, jsonArg(items)); err != nil {
+		return nil, err
+	}
+	return c.GetShippedBalance(agreementId)
+}
+// ============================================================================================================================
+// GetAgreement - an Agreement by agreementId
+// ============================================================================================================================
+func (c *Client) GetAgreement(agreementId string) (*Agreement, error) {
+	record := &Agreement{}
+	if err := c.query(record, "getAgreement_byID", agreementId); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+// ============================================================================================================================
+// GetAgreementsByBuyer - the Agreements of a buyer
+// ============================================================================================================================
+func (c *Client) GetAgreementsByBuyer(buyerName string) ([]*Agreement, error) {
+	return c.agreements("getAgreement_byBuyer", buyerName)
+}
+// ============================================================================================================================
+// GetAgreementsBySeller - the Agreements of a seller
+// ============================================================================================================================
+func (c *Client) GetAgreementsBySeller(sellerName string) ([]*Agreement, error) {
+	return c.agreements("getAgreement_bySeller", sellerName)
+}
+// ============================================================================================================================
+// GetAgreementsByShipper - the Agreements of a shipper
+// ============================================================================================================================
+func (c *Client) GetAgreementsByShipper(shipperName string) ([]*Agreement, error) {
+	return c.agreements("getAgreement_byShipper", shipperName)
+}
+// ============================================================================================================================
+// GetAgreementsByBuyerBank - the Agreements of a buyer bank
+// ============================================================================================================================
+func (c *Client) GetAgreementsByBuyerBank(bankName string) ([]*Agreement, error) {
+	return c.agreements("getAgreement_byBuyerBank", bankName)
+}
+// ============================================================================================================================
+// GetAgreementsBySellerBank - the Agreements of a seller bank
+// ============================================================================================================================
+func (c *Client) GetAgreementsBySellerBank(bankName string) ([]*Agreement, error) {
+	return c.agreements("getAgreement_bySellerBank", bankName)
+}
+// ============================================================================================================================
+// GetAgreementsByPortAuthority - the Agreements of a port authority
+// ============================================================================================================================
+func (c *Client) GetAgreementsByPortAuthority(portAuthName string) ([]*Agreement, error) {
+	return c.agreements("getAgreement_byPortAuthority", portAuthName)
+}
+// ============================================================================================================================
+// GetAllAgreements - every Agreement
+// ============================================================================================================================
+func (c *Client) GetAllAgreements() ([]*Agreement, error) {
+	return c.agreements("get_AllAgreement", " ")
+}
+// ============================================================================================================================
+// agreements - the Agreements returned by a list query
+// ============================================================================================================================
+func (c *Client) agreements(function string, arg string) ([]*Agreement, error) {
+	records := []*Agreement{}
+	if err := c.queryList(&records, function, arg); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+// ============================================================================================================================
+// GetFraudList - every party on the fraud list
+// ============================================================================================================================
+func (c *Client) GetFraudList() ([]*Fraud, error) {
+	records := []*Fraud{}
+	if err := c.queryList(&records, "get_fraud_list", " "); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+// ============================================================================================================================
+// GetFraudDetails - the fraud list entries of a party
+// ============================================================================================================================
+func (c *Client) GetFraudDetails(fraudName string) ([]*Fraud, error) {
+	records := []*Fraud{}
+	if err := c.queryList(&records, "get_fraud_details", fraudName); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+// ============================================================================================================================
+// GetApprovalStatus - the signature of a party on an Agreement, keyed by agreementId and the party's sign field
+// ============================================================================================================================
+func (c *Client) GetApprovalStatus(user string, agreementId string) (map[string]string, error) {
+	status := map[string]string{}
+	if err := c.query(&status, "getApprovalStatus", user, agreementId); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+// ============================================================================================================================
+// GetLiquidatedDamages - the late delivery terms of an Agreement
+// ============================================================================================================================
+func (c *Client) GetLiquidatedDamages(agreementId string) (*LiquidatedDamages, error) {
+	terms := &LiquidatedDamages{}
+	if err := c.query(terms, "get_liquidated_damages", agreementId); err != nil {
+		return nil, err
+	}
+	return terms, nil
+}
+// ============================================================================================================================
+// GetShippedBalance - shipped versus ordered quantity of every line of an Agreement
+// ============================================================================================================================
+func (c *Client) GetShippedBalance(agreementId string) (*ShippedBalance, error) {
+	balance := &ShippedBalance{}
+	if err := c.query(balance, "get_shipped_balance", agreementId); err != nil {
+		return nil, err
+	}
+	return balance, nil
+}
+// ============================================================================================================================
+// GetTradeRecord - an Agreement with its PO, payments, shipments and shipped balance
+// ============================================================================================================================
+func (c *Client) GetTradeRecord(agreementId string) (*TradeRecord, error) {
+	record := &TradeRecord{}
+	if err := c.query(record, "get_trade_record", agreementId); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
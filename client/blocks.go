@@ -0,0 +1,144 @@
+package client
+
+import (
+"context"
+"errors"
+"fmt"
+"math"
+
+"github.com/golang/protobuf/proto"
+"github.com/hyperledger/fabric-protos-go/common"
+"github.com/hyperledger/fabric-protos-go/orderer"
+"github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// CommittedEvent is the chaincode event of a valid transaction of a block of the channel
+type CommittedEvent struct {
+	Block uint64
+	TxID string
+	Chaincode string
+	Event
+}
+
+// ============================================================================================================================
+// FollowBlocks - call f with the chaincode event of every valid transaction of the blocks from start on, in order, until ctx
+// is done or f fails. Only the events of the chaincodes of the profile are passed, an invalid transaction emits none. The
+// stream ending before ctx is done, e.g. as the peer went down, is an error
+// ============================================================================================================================
+func (p *Peer) FollowBlocks(ctx context.Context, start uint64, f func(*CommittedEvent) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	seek, err := p.seek(start)
+	if err != nil {
+		return err
+	}
+	stream, err := p.deliver.Deliver(ctx)
+	if err != nil {
+		return err
+	}
+	if err = stream.Send(seek); err != nil {
+		return err
+	}
+	for {
+		response, err := stream.Recv()
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch response.GetType().(type) {
+		case *peer.DeliverResponse_Block:
+			if err = p.blockEvents(response.GetBlock(), f); err != nil {
+				return err
+			}
+		case *peer.DeliverResponse_Status:
+			return fmt.Errorf("the peer ended the block stream: %s", response.GetStatus().String())
+		}
+	}
+}
+// ============================================================================================================================
+// seek - the signed request of the blocks of the channel from start on, waiting for the blocks still to come
+// ============================================================================================================================
+func (p *Peer) seek(start uint64) (*common.Envelope, error) {
+	header, err := p.header(common.HeaderType_DELIVER_SEEK_INFO, "", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	seekInfo, err := proto.Marshal(&orderer.SeekInfo{
+		Start: &orderer.SeekPosition{Type: &orderer.SeekPosition_Specified{Specified: &orderer.SeekSpecified{Number: start}}},
+		Stop: &orderer.SeekPosition{Type: &orderer.SeekPosition_Specified{Specified: &orderer.SeekSpecified{Number: math.MaxUint64}}},
+		Behavior: orderer.SeekInfo_BLOCK_UNTIL_READY})
+	if err != nil {
+		return nil, err
+	}
+	payload, err := proto.Marshal(&common.Payload{Header: header, Data: seekInfo})
+	if err != nil {
+		return nil, err
+	}
+	signature, err := p.sign(payload)
+	if err != nil {
+		return nil, err
+	}
+	return &common.Envelope{Payload: payload, Signature: signature}, nil
+}
+// ============================================================================================================================
+// blockEvents - call f with the chaincode event of every valid endorser transaction of a block
+// ============================================================================================================================
+func (p *Peer) blockEvents(block *common.Block, f func(*CommittedEvent) error) error {
+	if block == nil {
+		return errors.New("the peer sent an empty block")
+	}
+	var filter []byte
+	if metadata := block.GetMetadata().GetMetadata(); len(metadata) > int(common.BlockMetadataIndex_TRANSACTIONS_FILTER) {
+		filter = metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER]
+	}
+	for i, envelopeAsBytes := range block.GetData().GetData() {
+		if i >= len(filter) || peer.TxValidationCode(filter[i]) != peer.TxValidationCode_VALID {
+			continue											//invalid, its writes and event were dropped
+		}
+		envelope := &common.Envelope{}
+		if err := proto.Unmarshal(envelopeAsBytes, envelope); err != nil {
+			return err
+		}
+		payload := &common.Payload{}
+		if err := proto.Unmarshal(envelope.GetPayload(), payload); err != nil {
+			return err
+		}
+		channelHeader := &common.ChannelHeader{}
+		if err := proto.Unmarshal(payload.GetHeader().GetChannelHeader(), channelHeader); err != nil {
+			return err
+		}
+		if common.HeaderType(channelHeader.GetType()) != common.HeaderType_ENDORSER_TRANSACTION {
+			continue											//e.g. a config transaction
+		}
+		action, err := payloadAction(payload)
+		if err != nil {
+			return err
+		}
+		event := &peer.ChaincodeEvent{}
+		if err = proto.Unmarshal(action.GetEvents(), event); err != nil {
+			return err
+		}
+		if event.GetEventName() == "" || !p.deployed(event.GetChaincodeId()) {
+			continue
+		}
+		err = f(&CommittedEvent{Block: block.GetHeader().GetNumber(), TxID: channelHeader.GetTxId(), Chaincode: event.GetChaincodeId(),
+			Event: Event{Name: event.GetEventName(), Payload: event.GetPayload()}})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+// ============================================================================================================================
+// deployed - whether the profile names the chaincode
+// ============================================================================================================================
+func (p *Peer) deployed(name string) bool {
+	for _, cc := range p.chaincodes {
+		if cc.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,295 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements.  See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership.  The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License.  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+// Package client is a typed Go client of the trade-finance chaincodes. Every function has a method
+// taking and returning the domain records, e.g. CreatePO runs create_po with its positional arguments
+// built from a PO. An errEvent becomes an *Error and the functions run on a Transport: the REST
+// gateway in front of the peers or the in-process simulated network for tests.
+package client
+
+import (
+"errors"
+"strings"
+"encoding/json"
+
+"github.com/wipro-blockchain/TF-v1/internal/agreement"
+"github.com/wipro-blockchain/TF-v1/internal/contracts"
+"github.com/wipro-blockchain/TF-v1/internal/payment"
+"github.com/wipro-blockchain/TF-v1/internal/po"
+"github.com/wipro-blockchain/TF-v1/internal/router"
+"github.com/wipro-blockchain/TF-v1/internal/shipment"
+)
+
+// The records of the chaincodes, as the functions take and return them
+type (
+	PO = po.PO
+	Agreement = agreement.Agreement
+	Fraud = agreement.Fraud_list
+	LiquidatedDamages = agreement.LiquidatedDamages
+	ShippedBalance = agreement.ShippedBalance
+	ShippedLine = agreement.ShippedLine
+	Payment = payment.Payment
+	AccountInfo = payment.AccountInfo
+	Escrow = payment.Escrow
+	EscrowCondition = payment.EscrowCondition
+	EscrowMovement = payment.EscrowMovement
+	StatementLine = payment.StatementLine
+	Reconciliation = payment.Reconciliation
+	ReconciliationResult = payment.ReconciliationResult
+	Shipment = shipment.Shipment
+	ShipmentItem = shipment.ShipmentItem			// also the line of record_shipped_quantity, the JSON is the same
+	TrackingEvent = shipment.TrackingEvent
+	BillOfLading = shipment.BillOfLading
+	SensorReading = shipment.SensorReading
+	TelemetrySummary = shipment.TelemetrySummary
+	Clearance = shipment.Clearance
+	DeliverySLA = shipment.DeliverySLA
+	ShipperPerformance = shipment.ShipperPerformance
+	TradeRecord = contracts.TradeRecord
+	BatchStep = router.BatchStep
+)
+
+var ErrNotFound = errors.New("not found")				//matches, with errors.Is, an Error for a missing record
+var ErrExists = errors.New("already exists")			//matches, with errors.Is, an Error for a duplicate record
+var ErrConflict = errors.New("version conflict")		//matches, with errors.Is, an Error for an update of a stale version
+var ErrKeyReused = errors.New("idempotency key reused")	//matches, with errors.Is, an Error for an idempotency key used by another invoke
+
+// Patch is a JSON Merge Patch of a record by the JSON names of its fields, a nil value clears a field
+type Patch map[string]interface{}
+
+// Client runs the chaincode functions on a Transport
+type Client struct {
+	Transport Transport
+	idempotencyKey string							// runs the invokes through execute_once, see WithIdempotencyKey
+}
+
+// Error is a function refused by the chaincode, the message of its errEvent or of the transport
+type Error struct {
+	Function string
+	Message string
+	TxID string										// empty for a query or a transaction the transport rejected
+}
+
+func (e *Error) Error() string {
+	return e.Function + ": " + e.Message
+}
+
+// ============================================================================================================================
+// Is - match ErrNotFound, ErrExists, ErrConflict and ErrKeyReused by the message, the chaincodes report them only in their text
+// ============================================================================================================================
+func (e *Error) Is(target error) bool {
+	lower := strings.ToLower(e.Message)
+	switch target {
+	case ErrNotFound:
+		return strings.Contains(lower, "not found")
+	case ErrExists:
+		return strings.Contains(lower, "already") || strings.Contains(lower, "arleady")
+	case ErrConflict:
+		return strings.Contains(lower, "version conflict")
+	case ErrKeyReused:
+		return strings.HasPrefix(lower, "idempotency key") && strings.Contains(lower, "other arguments")
+	}
+	return false
+}
+
+// ============================================================================================================================
+// New - a client on a Transport
+// ============================================================================================================================
+func New(transport Transport) *Client {
+	return &Client{Transport: transport}
+}
+// ============================================================================================================================
+// WithIdempotencyKey - a client whose invokes run once under key: a retry, e.g. after a timeout, gets the outcome of the
+// first run instead of creating or settling again. Use a new key for every operation, e.g.
+// c.WithIdempotencyKey("settle-PAY1").UpdatePayment(payment)
+// ============================================================================================================================
+func (c *Client) WithIdempotencyKey(key string) *Client {
+	return &Client{Transport: c.Transport, idempotencyKey: key}
+}
+// ============================================================================================================================
+// Init - reset the state of a chaincode, the accounts get their opening balance again
+// ============================================================================================================================
+func (c *Client) Init(chaincode string) error {
+	_, err := c.Submit(qualified(chaincode, "init"))
+	return err
+}
+// ============================================================================================================================
+// RegisterChaincode - record on a chaincode the deployed name of the chaincode of a role, e.g. "payment"
+// ============================================================================================================================
+func (c *Client) RegisterChaincode(chaincode string, role string, name string) error {
+	_, err := c.Submit(qualified(chaincode, "register_chaincode"), role, name)
+	return err
+}
+// ============================================================================================================================
+// RegisterParty - record on a chaincode the identity acting for a trade party, an MSP ID or <mspId>:<commonName>
+// ============================================================================================================================
+func (c *Client) RegisterParty(chaincode string, party string, identity string) error {
+	_, err := c.Submit(qualified(chaincode, "register_party"), party, identity)
+	return err
+}
+// ============================================================================================================================
+// ExecuteBatch - run several invokes of a chaincode as one transaction, the payload of the event of every step is returned
+// ============================================================================================================================
+func (c *Client) ExecuteBatch(chaincode string, steps []BatchStep) ([]json.RawMessage, error) {
+	event, err := c.Submit(qualified(chaincode, "execute_batch"), jsonArg(steps))
+	if err != nil {
+		return nil, err
+	}
+	result := struct {
+		Data struct {
+			Steps []json.RawMessage `json:"steps"`
+		} `json:"data"`
+	}{}
+	if event != nil {
+		json.Unmarshal(event.Payload, &result)
+	}
+	return result.Data.Steps, nil
+}
+// ============================================================================================================================
+// qualified - a function every chaincode has, on the chaincode of that deployed name, e.g. managePO:execute_batch; the
+// chaincode is empty when the single TradeFinance chaincode is deployed
+// ============================================================================================================================
+func qualified(chaincode string, function string) string {
+	if chaincode == "" {
+		return function
+	}
+	return chaincode + ":" + function
+}
+// ============================================================================================================================
+// Submit - run an invoke function by its original name, an errEvent becomes an *Error, its event is returned
+// ============================================================================================================================
+func (c *Client) Submit(function string, args ...string) (*Event, error) {
+	tx, err := c.submit(function, args)
+	if err != nil {
+		return nil, err
+	}
+	return tx.Event, nil
+}
+// ============================================================================================================================
+// create - run a create function and return the ID of the record: the first argument, or the ID the chaincode generated and
+// answered with when that is empty
+// ============================================================================================================================
+func (c *Client) create(function string, args ...string) (string, error) {
+	tx, err := c.submit(function, args)
+	if err != nil {
+		return "", err
+	}
+	if id := strings.TrimSpace(string(tx.Payload)); id != "" {
+		return id, nil
+	}
+	return args[0], nil
+}
+// ============================================================================================================================
+// submit - run an invoke function, through execute_once under the idempotency key of the client
+// ============================================================================================================================
+func (c *Client) submit(function string, args []string) (*Transaction, error) {
+	name, callArgs := function, args
+	if c.idempotencyKey != "" {
+		chaincode := ""
+		if i := strings.Index(function, ":"); i >= 0 {
+			chaincode, name = function[:i], function[i+1:]
+		}
+		name, callArgs = qualified(chaincode, "execute_once"), append([]string{c.idempotencyKey, name}, args...)
+	}
+	tx, err := c.Transport.Submit(name, callArgs...)
+	if err != nil {
+		return nil, &Error{Function: function, Message: err.Error()}
+	}
+	if tx.Event != nil && tx.Event.Name == "errEvent" {
+		return nil, &Error{Function: function, Message: eventMessage(tx.Event.Payload), TxID: tx.ID}
+	}
+	return tx, nil
+}
+// ============================================================================================================================
+// Evaluate - run a query function by its original name, an empty response means the record was not found
+// ============================================================================================================================
+func (c *Client) Evaluate(function string, args ...string) ([]byte, error) {
+	tx, err := c.Transport.Evaluate(function, args...)
+	if err != nil {
+		return nil, &Error{Function: function, Message: err.Error()}
+	}
+	if tx.Event != nil && tx.Event.Name == "errEvent" {
+		return nil, &Error{Function: function, Message: eventMessage(tx.Event.Payload)}
+	}
+	if len(strings.TrimSpace(string(tx.Payload))) == 0 {
+		return nil, &Error{Function: function, Message: strings.TrimSpace(strings.Join(args, " ")) + " Not Found"}
+	}
+	return tx.Payload, nil
+}
+// ============================================================================================================================
+// query - run a query function and decode its response into v
+// ============================================================================================================================
+func (c *Client) query(v interface{}, function string, args ...string) error {
+	valAsBytes, err := c.Evaluate(function, args...)
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(valAsBytes, v); err != nil {
+		return &Error{Function: function, Message: "malformed response: " + err.Error()}
+	}
+	return nil
+}
+// ============================================================================================================================
+// queryList - run a list query and decode it into a slice v, nothing found is an empty list
+// ============================================================================================================================
+func (c *Client) queryList(v interface{}, function string, args ...string) error {
+	valAsBytes, err := c.Evaluate(function, args...)
+	if errors.Is(err, ErrNotFound) {
+		valAsBytes, err = []byte("[]"), nil				//the by-name queries report an unknown name as not found
+	}
+	if err != nil {
+		return err
+	}
+	items, err := contracts.ListJSON(valAsBytes)
+	if err != nil {
+		return &Error{Function: function, Message: "malformed response: " + err.Error()}
+	}
+	itemsAsBytes, _ := json.Marshal(items)
+	return json.Unmarshal(itemsAsBytes, v)
+}
+// ============================================================================================================================
+// eventMessage - the "message" field of an event payload, or the payload itself
+// ============================================================================================================================
+func eventMessage(payload []byte) string {
+	msg := struct {
+		Message string `json:"message"`
+	}{}
+	if json.Unmarshal(payload, &msg) == nil && msg.Message != "" {
+		return strings.TrimSpace(msg.Message)
+	}
+	return string(payload)
+}
+// ============================================================================================================================
+// patchArg - the one JSON object argument of an update changing only the fields of a patch
+// ============================================================================================================================
+func patchArg(idField string, id string, patch Patch) string {
+	object := Patch{}
+	for field, value := range patch {
+		object[field] = value
+	}
+	object[idField] = id
+	return jsonArg(object)
+}
+// ============================================================================================================================
+// jsonArg - a JSON argument, e.g. the items of set_shipment_items
+// ============================================================================================================================
+func jsonArg(v interface{}) string {
+	valAsBytes, _ := json.Marshal(v)
+	return string(valAsBytes)
+}
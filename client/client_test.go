@@ -0,0 +1,520 @@
+package client_test
+
+import (
+"context"
+"crypto/ecdsa"
+"crypto/elliptic"
+"crypto/rand"
+"crypto/sha256"
+"crypto/x509"
+"crypto/x509/pkix"
+"encoding/asn1"
+"encoding/pem"
+"errors"
+"math/big"
+"net"
+"net/http/httptest"
+"os"
+"path/filepath"
+"strings"
+"sync"
+"testing"
+"time"
+
+"github.com/golang/protobuf/proto"
+"github.com/hyperledger/fabric-protos-go/common"
+fabricgateway "github.com/hyperledger/fabric-protos-go/gateway"
+"github.com/hyperledger/fabric-protos-go/msp"
+"github.com/hyperledger/fabric-protos-go/orderer"
+"github.com/hyperledger/fabric-protos-go/peer"
+"github.com/wipro-blockchain/TF-v1/client"
+"github.com/wipro-blockchain/TF-v1/internal/gateway"
+"github.com/wipro-blockchain/TF-v1/internal/simulator"
+"google.golang.org/grpc"
+"google.golang.org/grpc/codes"
+"google.golang.org/grpc/credentials/insecure"
+"google.golang.org/grpc/status"
+)
+
+// transports - a fresh simulated network reached directly and through the REST gateway
+func transports(t *testing.T) map[string]*network {
+	direct, err := client.NewSimulated(false, simulator.DefaultBalance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	directShipper, err := direct.As("ShipMSP", "shipper")
+	if err != nil {
+		t.Fatal(err)
+	}
+	behindGateway, err := client.NewSimulated(true, simulator.DefaultBalance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shipper, err := behindGateway.As("ShipMSP", "shipper")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := gateway.New(behindGateway)
+	if err = g.AddCaller(gateway.Caller{Name: "Admin", TokenSHA256: gateway.TokenSHA256("admin-token")}, behindGateway); err != nil {
+		t.Fatal(err)
+	}
+	if err = g.AddCaller(gateway.Caller{Name: "Shipco", TokenSHA256: gateway.TokenSHA256("shipper-token")}, shipper); err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(g)
+	t.Cleanup(server.Close)
+	return map[string]*network{
+		"simulated": {Transport: direct, Shipper: directShipper},
+		"http": {Transport: &client.HTTPTransport{BaseURL: server.URL, Token: "admin-token", Client: server.Client()},
+			Shipper: &client.HTTPTransport{BaseURL: server.URL, Token: "shipper-token", Client: server.Client()}},
+	}
+}
+
+// network submits as the admin of the chaincodes, Shipper as the shipper ShipMSP:shipper
+type network struct {
+	client.Transport
+	Shipper client.Transport
+}
+
+var purchaseOrder = client.PO{TransID: "PO1", SellerName: "Sellerco", BuyerName: "Buyerco", ExpectedDeliveryDate: "2024-03-01",
+	PO_status: "Created", PO_date: "2024-01-15", ItemId: "ITM-1", Item_name: "Rice", Item_quantity: "100", Price: "25",
+	Buyer_sign: "true", Seller_sign: "false"}
+
+var tradeAgreement = client.Agreement{AgreementID: "AGR1", TransID: "PO1", Agreement_status: "Created", BuyerName: "Buyerco",
+	SellerName: "Sellerco", ShipperName: "Shipco", BB_name: "Buybank", SB_name: "Sellbank", PortAuthName: "Portauth",
+	AgreementCU_date: "2024-01-16", ItemId: "ITM-1", Item_name: "Rice", Item_quantity: "100", Total_Value: "2500",
+	Delivery_date: "2024-03-01", ExtraCharges: "0", Shipper_fees: "100", DocumentName: "Contract",
+	DocumentURL: "http://docs/contract", TC_Text: "Terms", Buyer_sign: "true", BuyerBank_sign: "false", Seller_sign: "false",
+	SellerBank_sign: "false", Industry: "Food", GoodsPrice: "25"}
+
+var tradePayment = client.Payment{PaymentID: "PAY1", AgreementID: "AGR1", BuyerName: "Buyerco", SellerName: "Sellerco",
+	AmountTransferred: "2500", PaymentCUDate: "2024-02-01", PaymentStatus: "Created", PaymentDeadlineDate: "2024-03-01",
+	BuyerBank_sign: "false", BB_name: "Buybank", SB_name: "Sellbank"}
+
+var tradeShipment = client.Shipment{ShipmentID: "SHP1", TransID: "PO1", AgreementID: "AGR1", Shipment_status: "Created",
+	Source: "Mumbai", Destination: "Rotterdam", Shipment_date: "2024-02-01", ShipperName: "Shipco"}
+
+func TestTrade(t *testing.T) {
+	for name, transport := range transports(t) {
+		t.Run(name, func(t *testing.T) {
+			c := client.New(transport)
+			record, err := c.CreatePO(purchaseOrder)
+			if err != nil || record.PO_status != "Created" {
+				t.Fatalf("CreatePO: %+v %v", record, err)
+			}
+			_, err = c.CreatePO(purchaseOrder)
+			var e *client.Error
+			if !errors.As(err, &e) || e.Function != "create_po" || e.Message != "This PO arleady exists" || e.TxID == "" || !errors.Is(err, client.ErrExists) {
+				t.Errorf("duplicate PO: %#v", err)
+			}
+			accepted := *record
+			accepted.PO_status, accepted.Seller_sign, accepted.Seller_Remarks = "Accepted", "true", "Can deliver by March"
+			if record, err = c.UpdatePO(accepted); err != nil || record.Seller_Remarks != "Can deliver by March" {
+				t.Errorf("UpdatePO: %+v %v", record, err)
+			}
+			if _, err = c.UpdatePO(accepted); !errors.Is(err, client.ErrConflict) || errors.Is(err, client.ErrExists) {
+				t.Errorf("UpdatePO of a stale version: %v", err)
+			}
+			if record, err = c.PatchPO("PO1", client.Patch{"seller_remarks": "March at the latest", "version": record.Version}); err != nil || record.Version != 3 {
+				t.Errorf("PatchPO at the current version: %+v %v", record, err)
+			}
+
+			agreement, err := c.CreateAgreement(tradeAgreement)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err = c.CreatePayment(tradePayment); err == nil {
+				t.Errorf("CreatePayment before the agreement is approved succeeded")
+			}
+			agreement.BuyerBank_sign = "true"
+			if agreement, err = c.UpdateAgreement(*agreement); err != nil || agreement.BuyerBank_sign != "true" {
+				t.Fatalf("UpdateAgreement: %+v %v", agreement, err)
+			}
+			agreement, err = c.PatchAgreement("AGR1", client.Patch{"seller_sign": "true", "sellerBank_sign": true})
+			if err != nil || agreement.Agreement_status != "Approved By Seller Bank" || agreement.TC_Text != "Terms" {
+				t.Fatalf("PatchAgreement: %+v %v", agreement, err)
+			}
+			if _, err = c.PatchAgreement("AGR1", client.Patch{"clearance_status": "Cleared"}); err == nil {
+				t.Errorf("PatchAgreement of a field set by the chaincode succeeded")
+			}
+
+			payment, err := c.CreatePayment(tradePayment)
+			if err != nil {
+				t.Fatal(err)
+			}
+			escrow, err := c.CreateEscrow("PAY1", []string{"ShipmentDelivered"})
+			if err != nil || escrow.EscrowStatus != "Pending" {
+				t.Fatalf("CreateEscrow: %+v %v", escrow, err)
+			}
+			payment.BuyerBank_sign, payment.PaymentStatus = "true", "Paid"
+			if _, err = c.UpdatePayment(*payment); err != nil {
+				t.Fatal(err)
+			}
+			if escrow, err = c.GetEscrow("PAY1"); err != nil || escrow.EscrowStatus != "Held" {
+				t.Errorf("escrow after settle: %+v %v", escrow, err)
+			}
+
+			if _, err = c.CreateShipment(tradeShipment); err != nil {
+				t.Fatal(err)
+			}
+			timeline, err := c.AddTrackingEvent(client.TrackingEvent{ShipmentID: "SHP1", EventType: "Delivered", Location: "Rotterdam",
+				Timestamp: "2024-02-28", ReportingParty: "Shipco"})
+			if err != nil || len(timeline) != 1 || timeline[0].EventType != "Delivered" {
+				t.Errorf("AddTrackingEvent: %+v %v", timeline, err)
+			}
+			if err = c.RegisterParty("payment", "Shipco", "ShipMSP"); err != nil {
+				t.Fatal(err)
+			}
+			if _, err = c.SatisfyEscrowCondition("PAY1", "ShipmentDelivered", "Shipco"); err == nil {
+				t.Errorf("SatisfyEscrowCondition in the name of the shipper by the admin succeeded")
+			}
+			shipper := client.New(transport.Shipper)
+			if escrow, err = shipper.SatisfyEscrowCondition("PAY1", "ShipmentDelivered", "Shipco"); err != nil || escrow.EscrowStatus != "Released" {
+				t.Errorf("SatisfyEscrowCondition: %+v %v", escrow, err)
+			}
+			accounts, err := c.GetAccountDetails()
+			if err != nil || accounts.BuyerAccountBalance != "97500.00" || accounts.SellerAccountBalance != "102500.00" {
+				t.Errorf("GetAccountDetails: %+v %v", accounts, err)
+			}
+
+			trade, err := c.GetTradeRecord("AGR1")
+			if err != nil || trade.PO == nil || trade.PO.PO_status != "Accepted" || len(trade.Payments) != 1 || len(trade.Shipments) != 1 {
+				t.Errorf("GetTradeRecord: %+v %v", trade, err)
+			}
+			pos, err := c.GetPOsByBuyer("Buyerco")
+			if err != nil || len(pos) != 1 || pos[0].TransID != "PO1" {
+				t.Errorf("GetPOsByBuyer: %+v %v", pos, err)
+			}
+			if pos, err = c.GetPOsByBuyer("Nobody"); err != nil || len(pos) != 0 {
+				t.Errorf("GetPOsByBuyer of an unknown buyer: %+v %v", pos, err)
+			}
+			shipments, err := c.GetAllShipments()
+			if err != nil || len(shipments) != 1 {
+				t.Errorf("GetAllShipments: %+v %v", shipments, err)
+			}
+		})
+	}
+}
+
+func TestErrors(t *testing.T) {
+	for name, transport := range transports(t) {
+		t.Run(name, func(t *testing.T) {
+			c := client.New(transport)
+			_, err := c.GetPO("PO9")
+			if !errors.Is(err, client.ErrNotFound) || errors.Is(err, client.ErrExists) {
+				t.Errorf("GetPO of a missing PO: %v", err)
+			}
+			if _, err = c.Submit("no_such_function"); err == nil {
+				t.Errorf("an unknown function succeeded")
+			}
+			if _, err = c.Evaluate("create_po", `{"transId": "PO9"}`); err == nil || !strings.Contains(err.Error(), "create_po is not a query") {
+				t.Errorf("Evaluate of an invoke: %v", err)
+			}
+			if _, err = c.GetPO("PO9"); !errors.Is(err, client.ErrNotFound) {
+				t.Errorf("an evaluated invoke was committed: %v", err)
+			}
+			if err = c.DeletePO("PO9"); err != nil {
+				t.Errorf("DeletePO of a missing PO: %v", err)
+			}
+		})
+	}
+}
+
+func TestExecuteBatch(t *testing.T) {
+	for name, transport := range transports(t) {
+		t.Run(name, func(t *testing.T) {
+			c := client.New(transport)
+			if _, err := c.CreatePO(purchaseOrder); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := c.CreateAgreement(tradeAgreement); err != nil {
+				t.Fatal(err)
+			}
+			steps := []client.BatchStep{
+				{Function: "update_fraud_list", Args: []string{"F1", "Crookco"}},
+				{Function: "update_fraud_list", Args: []string{"F2", "Shadyco"}},
+			}
+			chaincode := "manageAgreement"
+			if name == "http" {
+				chaincode = ""						//the gateway runs the single TradeFinance chaincode
+			}
+			results, err := c.ExecuteBatch(chaincode, steps)
+			if err != nil || len(results) != 2 {
+				t.Fatalf("ExecuteBatch: %s %v", results, err)
+			}
+			frauds, err := c.GetFraudList()
+			if err != nil || len(frauds) != 2 {
+				t.Errorf("GetFraudList: %+v %v", frauds, err)
+			}
+		})
+	}
+}
+
+func TestGeneratedID(t *testing.T) {
+	for name, transport := range transports(t) {
+		t.Run(name, func(t *testing.T) {
+			c := client.New(transport)
+			unnamed := purchaseOrder
+			unnamed.TransID = ""
+			record, err := c.CreatePO(unnamed)
+			if err != nil || !strings.HasPrefix(record.TransID, "PO-") || record.Item_name != "Rice" {
+				t.Fatalf("CreatePO without a transId: %+v %v", record, err)
+			}
+			again, err := c.WithIdempotencyKey("create-unnamed").CreatePO(unnamed)
+			if err != nil || again.TransID == record.TransID {
+				t.Errorf("second CreatePO without a transId: %+v %v", again, err)
+			}
+		})
+	}
+}
+
+func TestIdempotencyKey(t *testing.T) {
+	for name, transport := range transports(t) {
+		t.Run(name, func(t *testing.T) {
+			c := client.New(transport)
+			once := c.WithIdempotencyKey("create-PO1")
+			for i := 0; i < 2; i++ {
+				if record, err := once.CreatePO(purchaseOrder); err != nil || record.TransID != "PO1" {
+					t.Fatalf("CreatePO run %d: %+v %v", i, record, err)
+				}
+			}
+			if _, err := c.CreatePO(purchaseOrder); !errors.Is(err, client.ErrExists) {
+				t.Errorf("CreatePO without the key: %v", err)
+			}
+			reused := purchaseOrder
+			reused.TransID = "PO2"
+			_, err := once.CreatePO(reused)
+			var e *client.Error
+			if !errors.As(err, &e) || e.Function != "create_po" || !errors.Is(err, client.ErrKeyReused) {
+				t.Errorf("CreatePO of another PO under the key: %#v", err)
+			}
+			if _, err = c.GetPO("PO2"); !errors.Is(err, client.ErrNotFound) {
+				t.Errorf("PO2 under a reused key: %v", err)
+			}
+		})
+	}
+}
+
+func TestHTTPTransportUnreachable(t *testing.T) {
+	server := httptest.NewServer(nil)
+	server.Close()
+	c := client.New(&client.HTTPTransport{BaseURL: server.URL})
+	_, err := c.GetPO("PO1")
+	var e *client.Error
+	if !errors.As(err, &e) || e.Function != "getPO_byID" || errors.Is(err, client.ErrNotFound) {
+		t.Errorf("unreachable gateway: %#v", err)
+	}
+}
+
+
+// fakePeer is the Gateway and Deliver services of a peer on channel trade. It checks the signature of every request,
+// endorses a function with the result "<function> done" and an event named after it, refuses delete_po as the chaincode
+// would, and commits each submitted transaction in a block of its own, after a block holding it as an invalid transaction
+type fakePeer struct {
+	fabricgateway.UnimplementedGatewayServer
+	peer.UnimplementedDeliverServer
+	t *testing.T
+	mu sync.Mutex
+	blocks []*common.Block
+}
+
+// check - the channel header of a request, its creator checked to have signed message with signature
+func (f *fakePeer) check(header *common.Header, message []byte, signature []byte) *common.ChannelHeader {
+	channelHeader := &common.ChannelHeader{}
+	signatureHeader := &common.SignatureHeader{}
+	id := &msp.SerializedIdentity{}
+	proto.Unmarshal(header.GetChannelHeader(), channelHeader)
+	proto.Unmarshal(header.GetSignatureHeader(), signatureHeader)
+	proto.Unmarshal(signatureHeader.GetCreator(), id)
+	if channelHeader.GetChannelId() != "trade" || id.GetMspid() != "Org1MSP" {
+		f.t.Errorf("a request of %s on channel %q", id.GetMspid(), channelHeader.GetChannelId())
+	}
+	block, _ := pem.Decode(id.GetIdBytes())
+	if block == nil {
+		f.t.Errorf("a creator with no certificate")
+		return channelHeader
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		f.t.Error(err)
+		return channelHeader
+	}
+	key := cert.PublicKey.(*ecdsa.PublicKey)
+	digest := sha256.Sum256(message)
+	var sig struct{ R, S *big.Int }
+	if _, err = asn1.Unmarshal(signature, &sig); err != nil || !ecdsa.VerifyASN1(key, digest[:], signature) ||
+		sig.S.Cmp(new(big.Int).Rsh(key.Params().N, 1)) > 0 {
+		f.t.Errorf("a request without a low-S signature of its creator")
+	}
+	return channelHeader
+}
+
+// invocation - the header, chaincode and arguments of a signed proposal, the function first
+func (f *fakePeer) invocation(signed *peer.SignedProposal, txID string) (*common.Header, string, []string) {
+	proposal := &peer.Proposal{}
+	header := &common.Header{}
+	payload := &peer.ChaincodeProposalPayload{}
+	spec := &peer.ChaincodeInvocationSpec{}
+	proto.Unmarshal(signed.GetProposalBytes(), proposal)
+	proto.Unmarshal(proposal.GetHeader(), header)
+	proto.Unmarshal(proposal.GetPayload(), payload)
+	proto.Unmarshal(payload.GetInput(), spec)
+	if channelHeader := f.check(header, signed.GetProposalBytes(), signed.GetSignature()); channelHeader.GetTxId() != txID {
+		f.t.Errorf("the proposal of %s in the request of %s", channelHeader.GetTxId(), txID)
+	}
+	var args []string
+	for _, arg := range spec.GetChaincodeSpec().GetInput().GetArgs() {
+		args = append(args, string(arg))
+	}
+	return header, spec.GetChaincodeSpec().GetChaincodeId().GetName(), args
+}
+
+func (f *fakePeer) Evaluate(ctx context.Context, request *fabricgateway.EvaluateRequest) (*fabricgateway.EvaluateResponse, error) {
+	_, _, args := f.invocation(request.GetProposedTransaction(), request.GetTransactionId())
+	return &fabricgateway.EvaluateResponse{Result: &peer.Response{Status: 200, Payload: []byte(args[0] + " done")}}, nil
+}
+
+func (f *fakePeer) Endorse(ctx context.Context, request *fabricgateway.EndorseRequest) (*fabricgateway.EndorseResponse, error) {
+	header, cc, args := f.invocation(request.GetProposedTransaction(), request.GetTransactionId())
+	if args[0] == "delete_po" {
+		refused, _ := status.New(codes.Aborted, "failed to endorse transaction").WithDetails(
+			&fabricgateway.ErrorDetail{Address: "peer0:7051", MspId: "Org1MSP", Message: "chaincode response 500, PO1 can not be deleted"})
+		return nil, refused.Err()
+	}
+	event, _ := proto.Marshal(&peer.ChaincodeEvent{ChaincodeId: cc, TxId: request.GetTransactionId(), EventName: args[0],
+		Payload: []byte(`{"entityId": "PO1"}`)})
+	action, _ := proto.Marshal(&peer.ChaincodeAction{Response: &peer.Response{Status: 200, Payload: []byte(args[0] + " done")}, Events: event})
+	responsePayload, _ := proto.Marshal(&peer.ProposalResponsePayload{Extension: action})
+	actionPayload, _ := proto.Marshal(&peer.ChaincodeActionPayload{Action: &peer.ChaincodeEndorsedAction{ProposalResponsePayload: responsePayload}})
+	tx, _ := proto.Marshal(&peer.Transaction{Actions: []*peer.TransactionAction{{Payload: actionPayload}}})
+	payload, _ := proto.Marshal(&common.Payload{Header: header, Data: tx})
+	return &fabricgateway.EndorseResponse{PreparedTransaction: &common.Envelope{Payload: payload}}, nil
+}
+
+func (f *fakePeer) Submit(ctx context.Context, request *fabricgateway.SubmitRequest) (*fabricgateway.SubmitResponse, error) {
+	envelope := request.GetPreparedTransaction()
+	payload := &common.Payload{}
+	proto.Unmarshal(envelope.GetPayload(), payload)
+	f.check(payload.GetHeader(), envelope.GetPayload(), envelope.GetSignature())
+	envelopeAsBytes, _ := proto.Marshal(envelope)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, code := range []peer.TxValidationCode{peer.TxValidationCode_MVCC_READ_CONFLICT, peer.TxValidationCode_VALID} {
+		f.blocks = append(f.blocks, &common.Block{Header: &common.BlockHeader{Number: uint64(len(f.blocks)) + 1},
+			Data: &common.BlockData{Data: [][]byte{envelopeAsBytes}},
+			Metadata: &common.BlockMetadata{Metadata: [][]byte{nil, nil, {byte(code)}}}})
+	}
+	return &fabricgateway.SubmitResponse{}, nil
+}
+
+func (f *fakePeer) CommitStatus(ctx context.Context, request *fabricgateway.SignedCommitStatusRequest) (*fabricgateway.CommitStatusResponse, error) {
+	statusRequest := &fabricgateway.CommitStatusRequest{}
+	proto.Unmarshal(request.GetRequest(), statusRequest)
+	f.check(&common.Header{ChannelHeader: mustMarshal(&common.ChannelHeader{ChannelId: statusRequest.GetChannelId()}),
+		SignatureHeader: mustMarshal(&common.SignatureHeader{Creator: statusRequest.GetIdentity()})}, request.GetRequest(), request.GetSignature())
+	return &fabricgateway.CommitStatusResponse{Result: peer.TxValidationCode_VALID, BlockNumber: 2}, nil
+}
+
+func (f *fakePeer) Deliver(stream peer.Deliver_DeliverServer) error {
+	envelope, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	payload := &common.Payload{}
+	seekInfo := &orderer.SeekInfo{}
+	proto.Unmarshal(envelope.GetPayload(), payload)
+	proto.Unmarshal(payload.GetData(), seekInfo)
+	f.check(payload.GetHeader(), envelope.GetPayload(), envelope.GetSignature())
+	f.mu.Lock()
+	blocks := f.blocks
+	f.mu.Unlock()
+	for _, block := range blocks {
+		if block.GetHeader().GetNumber() < seekInfo.GetStart().GetSpecified().GetNumber() {
+			continue
+		}
+		if err = stream.Send(&peer.DeliverResponse{Type: &peer.DeliverResponse_Block{Block: block}}); err != nil {
+			return err
+		}
+	}
+	<-stream.Context().Done()										//blocks still to come
+	return nil
+}
+
+func mustMarshal(message proto.Message) []byte {
+	messageAsBytes, _ := proto.Marshal(message)
+	return messageAsBytes
+}
+
+// identity - a certificate and ECDSA key of MSP Org1MSP, in PEM files of dir
+func identity(t *testing.T, dir string) client.Identity {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "projector"},
+		NotBefore: time.Now(), NotAfter: time.Now().Add(time.Hour)}
+	certAsBytes, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyAsBytes, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := client.Identity{MSPID: "Org1MSP", Cert: filepath.Join(dir, "cert.pem"), Key: filepath.Join(dir, "key.pem")}
+	os.WriteFile(id.Cert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certAsBytes}), 0600)
+	os.WriteFile(id.Key, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyAsBytes}), 0600)
+	return id
+}
+
+func TestPeer(t *testing.T) {
+	fake := &fakePeer{t: t}
+	server := grpc.NewServer()
+	fabricgateway.RegisterGatewayServer(server, fake)
+	peer.RegisterDeliverServer(server, fake)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+	conn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	profile := &client.Profile{Endpoint: listener.Addr().String(), Channel: "trade",
+		Chaincodes: []client.ProfileChaincode{{Name: "managePO", Roles: []string{"po"}}}}
+	p, err := client.NewPeer(conn, profile, identity(t, t.TempDir()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx, err := p.Submit("create_po", `{"transId": "PO1"}`)
+	if err != nil || string(tx.Payload) != "create_po done" || tx.Event == nil || tx.Event.Name != "create_po" {
+		t.Fatalf("Submit: %+v %v", tx, err)
+	}
+	if evaluated, err := p.Evaluate("getPO_byID", "PO1"); err != nil || string(evaluated.Payload) != "getPO_byID done" {
+		t.Errorf("Evaluate: %+v %v", evaluated, err)
+	}
+	if _, err = p.Evaluate("create_po", `{"transId": "PO2"}`); err == nil || !strings.Contains(err.Error(), "create_po is not a query") {
+		t.Errorf("Evaluate of an invoke: %v", err)
+	}
+	refused, err := p.Submit("delete_po", "PO1")
+	if err != nil || refused.Event == nil || refused.Event.Name != "errEvent" || !strings.Contains(string(refused.Event.Payload), "PO1 can not be deleted") {
+		t.Errorf("Submit refused by the chaincode: %+v %v", refused, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5 * time.Second)
+	defer cancel()
+	var followed []*client.CommittedEvent
+	err = p.FollowBlocks(ctx, 0, func(committed *client.CommittedEvent) error {
+		followed = append(followed, committed)
+		cancel()
+		return nil
+	})
+	if err != nil || len(followed) != 1 || followed[0].Block != 2 || followed[0].TxID != tx.ID || followed[0].Chaincode != "managePO" ||
+		followed[0].Name != "create_po" {
+		t.Errorf("FollowBlocks: %+v %v", followed, err)
+	}
+}
@@ -0,0 +1,237 @@
+package client
+
+import (
+"strconv"
+
+"github.com/wipro-blockchain/TF-v1/internal/router"
+)
+
+// ============================================================================================================================
+// CreatePayment - create a Payment, the accounts are assigned by the chaincode. Without a paymentId the chaincode generates
+// one, e.g. PAY-1F2E3D4C5B6A7980
+// ============================================================================================================================
+func (c *Client) CreatePayment(record Payment) (*Payment, error) {
+	paymentId, err := c.create("createPayment", record.PaymentID, record.AgreementID, record.BuyerName, record.SellerName,
+		record.AmountTransferred, record.PaymentCUDate, record.PaymentStatus, record.PaymentDeadlineDate,
+		record.BuyerBank_sign, record.BB_name, record.SB_name)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetPayment(paymentId)
+}
+// ============================================================================================================================
+// UpdatePayment - replace a Payment, the buyer bank signing it moves the amount. ErrConflict when it changed since record
+// was read
+// ============================================================================================================================
+func (c *Client) UpdatePayment(record Payment) (*Payment, error) {
+	_, err := c.Submit("updatePayment", router.WithVersion([]string{record.PaymentID, record.AgreementID, record.BuyerName,
+		record.SellerName, record.BuyerAccount, record.SellerAccount, record.AmountTransferred, record.PaymentCUDate,
+		record.PaymentStatus, record.PaymentDeadlineDate, record.BuyerBank_sign, record.BB_name, record.SB_name}, record.Version)...)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetPayment(record.PaymentID)
+}
+// ============================================================================================================================
+// PatchPayment - change only the fields of a Payment given in patch, e.g. Patch{"buyerBank_sign": "true"}
+// ============================================================================================================================
+func (c *Client) PatchPayment(paymentId string, patch Patch) (*Payment, error) {
+	if _, err := c.Submit("updatePayment", patchArg("paymentId", paymentId, patch)); err != nil {
+		return nil, err
+	}
+	return c.GetPayment(paymentId)
+}
+// ============================================================================================================================
+// DeletePayment - delete a Payment
+// ============================================================================================================================
+func (c *Client) DeletePayment(paymentId string) error {
+	_, err := c.Submit("deletePayment", paymentId)
+	return err
+}
+// ============================================================================================================================
+// CreateEscrow - hold the amount of a Payment until every condition is satisfied
+// ============================================================================================================================
+func (c *Client) CreateEscrow(paymentId string, conditions []string) (*Escrow, error) {
+	if _, err := c.Submit("createEscrow", append([]string{paymentId}, conditions...)...); err != nil {
+		return nil, err
+	}
+	return c.GetEscrow(paymentId)
+}
+// ============================================================================================================================
+// SatisfyEscrowCondition - mark a release condition as met, the funds are released with the last one
+// ============================================================================================================================
+func (c *Client) SatisfyEscrowCondition(paymentId string, condition string, satisfiedBy string) (*Escrow, error) {
+	if _, err := c.Submit("satisfyEscrowCondition", paymentId, condition, satisfiedBy); err != nil {
+		return nil, err
+	}
+	return c.GetEscrow(paymentId)
+}
+// ============================================================================================================================
+// RefundEscrow - return the escrowed funds to the buyer
+// ============================================================================================================================
+func (c *Client) RefundEscrow(paymentId string, reason string) (*Escrow, error) {
+	if _, err := c.Submit("refundEscrow", paymentId, reason); err != nil {
+		return nil, err
+	}
+	return c.GetEscrow(paymentId)
+}
+// ============================================================================================================================
+// ReconcileStatement - match the lines of a bank statement to the payments, within dateToleranceDays of their date
+// ============================================================================================================================
+func (c *Client) ReconcileStatement(statementId string, lines []StatementLine, dateToleranceDays int) (*Reconciliation, error) {
+	if _, err := c.Submit("reconcile_statement", statementId, "json", jsonArg(lines), strconv.Itoa(dateToleranceDays)); err != nil {
+		return nil, err
+	}
+	return c.GetReconciliation(statementId)
+}
+// ============================================================================================================================
+// ReconcileStatementCSV - ReconcileStatement for a statement exported by the bank as CSV, with its dates in dateFormat,
+// e.g. "DD/MM/YYYY", or YYYY-MM-DD when it is empty
+// ============================================================================================================================
+func (c *Client) ReconcileStatementCSV(statementId string, statement string, dateToleranceDays int, dateFormat string) (*Reconciliation, error) {
+	if _, err := c.Submit("reconcile_statement", statementId, "csv", statement, strconv.Itoa(dateToleranceDays), dateFormat); err != nil {
+		return nil, err
+	}
+	return c.GetReconciliation(statementId)
+}
+// ============================================================================================================================
+// ResolveReconciliationException - close an open exception of a reconciled statement
+// ============================================================================================================================
+func (c *Client) ResolveReconciliationException(statementId string, lineNo int, resolution string) (*Reconciliation, error) {
+	if _, err := c.Submit("resolve_reconciliation_exception", statementId, strconv.Itoa(lineNo), resolution); err != nil {
+		return nil, err
+	}
+	return c.GetReconciliation(statementId)
+}
+// ============================================================================================================================
+// ExportPain001 - assign a pain.001 message ID to a settled Payment and return the pain.001 XML
+// ============================================================================================================================
+func (c *Client) ExportPain001(paymentId string) (string, error) {
+	if _, err := c.Submit("exportPain001", paymentId); err != nil {
+		return "", err
+	}
+	return c.GetPaymentPain001(paymentId)
+}
+// ============================================================================================================================
+// ImportCamt054 - apply a camt.054 bank notification to the payments
+// ============================================================================================================================
+func (c *Client) ImportCamt054(document string) error {
+	_, err := c.Submit("importCamt054", document)
+	return err
+}
+// ============================================================================================================================
+// ApplyLiquidatedDamages - deduct the liquidated damages of the late Shipments of an Agreement from its settled Payments
+// ============================================================================================================================
+func (c *Client) ApplyLiquidatedDamages(agreementId string) ([]*Payment, error) {
+	if _, err := c.Submit("apply_liquidated_damages", agreementId); err != nil {
+		return nil, err
+	}
+	return c.GetPaymentsByAgreement(agreementId)
+}
+// ============================================================================================================================
+// GetPayment - a Payment by paymentId
+// ============================================================================================================================
+func (c *Client) GetPayment(paymentId string) (*Payment, error) {
+	record := &Payment{}
+	if err := c.query(record, "getPaymentByID", paymentId); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+// ============================================================================================================================
+// GetPaymentsByBuyer - the Payments of a buyer
+// ============================================================================================================================
+func (c *Client) GetPaymentsByBuyer(buyerName string) ([]*Payment, error) {
+	return c.payments("getPaymentByBuyer", buyerName)
+}
+// ============================================================================================================================
+// GetPaymentsBySeller - the Payments of a seller
+// ============================================================================================================================
+func (c *Client) GetPaymentsBySeller(sellerName string) ([]*Payment, error) {
+	return c.payments("getPaymentBySeller", sellerName)
+}
+// ============================================================================================================================
+// GetPaymentsByAgreement - the Payments of an Agreement
+// ============================================================================================================================
+func (c *Client) GetPaymentsByAgreement(agreementId string) ([]*Payment, error) {
+	return c.payments("getPaymentByAgreement", agreementId)
+}
+// ============================================================================================================================
+// GetAllPayments - every Payment
+// ============================================================================================================================
+func (c *Client) GetAllPayments() ([]*Payment, error) {
+	return c.payments("getAllPayment", " ")
+}
+// ============================================================================================================================
+// payments - the Payments returned by a list query
+// ============================================================================================================================
+func (c *Client) payments(function string, arg string) ([]*Payment, error) {
+	records := []*Payment{}
+	if err := c.queryList(&records, function, arg); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+// ============================================================================================================================
+// GetAccountDetails - the balances of the buyer, seller and escrow accounts
+// ============================================================================================================================
+func (c *Client) GetAccountDetails() (*AccountInfo, error) {
+	accounts := &AccountInfo{}
+	if err := c.query(accounts, "getAccountDetails"); err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+// ============================================================================================================================
+// GetEscrow - the escrow of a Payment
+// ============================================================================================================================
+func (c *Client) GetEscrow(paymentId string) (*Escrow, error) {
+	escrow := &Escrow{}
+	if err := c.query(escrow, "getEscrowByPaymentID", paymentId); err != nil {
+		return nil, err
+	}
+	return escrow, nil
+}
+// ============================================================================================================================
+// GetEscrowMovements - the escrow movements of a Payment, of every Payment when paymentId is empty
+// ============================================================================================================================
+func (c *Client) GetEscrowMovements(paymentId string) ([]*EscrowMovement, error) {
+	if paymentId == "" {
+		paymentId = " "
+	}
+	movements := []*EscrowMovement{}
+	if err := c.queryList(&movements, "getEscrowMovements", paymentId); err != nil {
+		return nil, err
+	}
+	return movements, nil
+}
+// ============================================================================================================================
+// GetReconciliation - a reconciled bank statement
+// ============================================================================================================================
+func (c *Client) GetReconciliation(statementId string) (*Reconciliation, error) {
+	reconciliation := &Reconciliation{}
+	if err := c.query(reconciliation, "get_reconciliation", statementId); err != nil {
+		return nil, err
+	}
+	return reconciliation, nil
+}
+// ============================================================================================================================
+// GetReconciliationExceptions - the open reconciliation exceptions by statementId
+// ============================================================================================================================
+func (c *Client) GetReconciliationExceptions() (map[string][]ReconciliationResult, error) {
+	exceptions := map[string][]ReconciliationResult{}
+	if err := c.query(&exceptions, "get_reconciliation_exceptions", " "); err != nil {
+		return nil, err
+	}
+	return exceptions, nil
+}
+// ============================================================================================================================
+// GetPaymentPain001 - a Payment as pain.001 XML
+// ============================================================================================================================
+func (c *Client) GetPaymentPain001(paymentId string) (string, error) {
+	valAsBytes, err := c.Evaluate("getPaymentPain001", paymentId)
+	if err != nil {
+		return "", err
+	}
+	return string(valAsBytes), nil
+}
@@ -0,0 +1,366 @@
+package client
+
+import (
+"context"
+"crypto/ecdsa"
+"crypto/rand"
+"crypto/sha256"
+"crypto/x509"
+"encoding/asn1"
+"encoding/hex"
+"encoding/pem"
+"errors"
+"fmt"
+"math/big"
+"os"
+"strings"
+"time"
+"encoding/json"
+
+"github.com/golang/protobuf/proto"
+"github.com/golang/protobuf/ptypes"
+"github.com/hyperledger/fabric-protos-go/common"
+"github.com/hyperledger/fabric-protos-go/gateway"
+"github.com/hyperledger/fabric-protos-go/msp"
+"github.com/hyperledger/fabric-protos-go/peer"
+"github.com/wipro-blockchain/TF-v1/internal/simulator"
+"google.golang.org/grpc"
+"google.golang.org/grpc/credentials"
+"google.golang.org/grpc/status"
+)
+
+// Profile is the connection profile of a Fabric network: the peer whose gateway service runs the transactions, and the
+// chaincodes deployed on the channel
+type Profile struct {
+	Endpoint string `json:"endpoint"`					// host:port of the peer, e.g. peer0.org1.example.com:7051
+	TLSCACert string `json:"tlsCACert"`				// PEM file of the CA of the peer's TLS certificate
+	ServerName string `json:"serverName,omitempty"`		// the host name in the peer's TLS certificate, when Endpoint differs
+	Channel string `json:"channel"`
+	Chaincodes []ProfileChaincode `json:"chaincodes"`
+}
+
+// ProfileChaincode is a chaincode deployed on the channel and the roles it runs, e.g. tradeFinance with every role or
+// managePO with po
+type ProfileChaincode struct {
+	Name string `json:"name"`
+	Roles []string `json:"roles"`						// po, agreement, payment and shipment
+}
+
+// Identity is the X.509 identity a Peer transport submits with, the files its organization's CA issued
+type Identity struct {
+	MSPID string `json:"mspId"`
+	Cert string `json:"cert"`							// PEM file of the certificate
+	Key string `json:"key"`								// PEM file of the private key
+}
+
+// Peer is a Transport on the peers of a Fabric network through their Gateway service, as one Identity. It signs its
+// requests itself, with the protos the chaincodes are built on, so that it links into the same binaries as the shim
+type Peer struct {
+	gateway gateway.GatewayClient
+	deliver peer.DeliverClient
+	channel string
+	creator []byte									// the serialized identity, in the signature header of every request
+	key *ecdsa.PrivateKey
+	chaincodes []*simulator.Chaincode				// to find the chaincode of a function, they have no ledger
+}
+
+// ============================================================================================================================
+// ReadProfile - read a connection profile from a JSON file
+// ============================================================================================================================
+func ReadProfile(path string) (*Profile, error) {
+	profileAsBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	profile := &Profile{}
+	if err = json.Unmarshal(profileAsBytes, profile); err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err.Error())
+	}
+	if profile.Endpoint == "" || profile.TLSCACert == "" || profile.Channel == "" || len(profile.Chaincodes) == 0 {
+		return nil, errors.New(path + ": a profile names the endpoint, tlsCACert, channel and chaincodes")
+	}
+	return profile, nil
+}
+// ============================================================================================================================
+// Dial - a TLS connection to the peer of the profile, shared by the Peer transports of every identity
+// ============================================================================================================================
+func (p *Profile) Dial() (*grpc.ClientConn, error) {
+	caAsBytes, err := os.ReadFile(p.TLSCACert)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caAsBytes) {
+		return nil, errors.New(p.TLSCACert + " holds no PEM certificate")
+	}
+	return grpc.NewClient(p.Endpoint, grpc.WithTransportCredentials(credentials.NewClientTLSFromCert(pool, p.ServerName)))
+}
+// ============================================================================================================================
+// NewPeer - a Transport on the chaincodes of profile, over conn, submitting as id. The key is an ECDSA key, as a Fabric CA
+// issues them
+// ============================================================================================================================
+func NewPeer(conn *grpc.ClientConn, profile *Profile, id Identity) (*Peer, error) {
+	certAsBytes, err := os.ReadFile(id.Cert)
+	if err != nil {
+		return nil, err
+	}
+	if block, _ := pem.Decode(certAsBytes); block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New(id.Cert + " holds no PEM certificate")
+	}
+	keyAsBytes, err := os.ReadFile(id.Key)
+	if err != nil {
+		return nil, err
+	}
+	key, err := privateKey(keyAsBytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", id.Key, err.Error())
+	}
+	creator, err := proto.Marshal(&msp.SerializedIdentity{Mspid: id.MSPID, IdBytes: certAsBytes})
+	if err != nil {
+		return nil, err
+	}
+	p := &Peer{gateway: gateway.NewGatewayClient(conn), deliver: peer.NewDeliverClient(conn), channel: profile.Channel,
+		creator: creator, key: key}
+	for _, deployed := range profile.Chaincodes {
+		cc, err := simulator.NewChaincode(deployed.Name, deployed.Roles...)
+		if err != nil {
+			return nil, err
+		}
+		p.chaincodes = append(p.chaincodes, cc)
+	}
+	return p, nil
+}
+// ============================================================================================================================
+// Submit - endorse an invoke function, submit it to the orderer and wait for its commit. A refusal of the chaincode is
+// answered as an errEvent, as the chaincode would emit it, and is not submitted
+// ============================================================================================================================
+func (p *Peer) Submit(function string, args ...string) (*Transaction, error) {
+	proposal, txID, err := p.proposal(function, args)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 15 * time.Second)
+	endorsed, err := p.gateway.Endorse(ctx, &gateway.EndorseRequest{TransactionId: txID, ChannelId: p.channel, ProposedTransaction: proposal})
+	cancel()
+	if err != nil {
+		return refusal(function, txID, err)
+	}
+	envelope := endorsed.GetPreparedTransaction()
+	action, err := envelopeAction(envelope)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", function, err.Error())
+	}
+	if envelope.Signature, err = p.sign(envelope.GetPayload()); err != nil {
+		return nil, err
+	}
+	statusAsBytes, err := proto.Marshal(&gateway.CommitStatusRequest{TransactionId: txID, ChannelId: p.channel, Identity: p.creator})
+	if err != nil {
+		return nil, err
+	}
+	statusSignature, err := p.sign(statusAsBytes)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), 5 * time.Second)
+	_, err = p.gateway.Submit(ctx, &gateway.SubmitRequest{TransactionId: txID, ChannelId: p.channel, PreparedTransaction: envelope})
+	cancel()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", function, err.Error())
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	commitStatus, err := p.gateway.CommitStatus(ctx, &gateway.SignedCommitStatusRequest{Request: statusAsBytes, Signature: statusSignature})
+	cancel()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", function, err.Error())
+	}
+	if commitStatus.GetResult() != peer.TxValidationCode_VALID {
+		return nil, fmt.Errorf("%s: transaction %s was not committed: %s", function, txID, commitStatus.GetResult().String())
+	}
+	return &Transaction{ID: txID, Payload: action.GetResponse().GetPayload(), Event: actionEvent(action)}, nil
+}
+// ============================================================================================================================
+// Evaluate - run a query function on the peer, nothing is submitted. An invoke is refused, as it would run on the peer
+// without being committed
+// ============================================================================================================================
+func (p *Peer) Evaluate(function string, args ...string) (*Transaction, error) {
+	if _, _, err := simulator.Query(p.chaincodes, function, args); err != nil {
+		return nil, err
+	}
+	proposal, txID, err := p.proposal(function, args)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5 * time.Second)
+	defer cancel()
+	evaluated, err := p.gateway.Evaluate(ctx, &gateway.EvaluateRequest{TransactionId: txID, ChannelId: p.channel, ProposedTransaction: proposal})
+	if err != nil {
+		return refusal(function, txID, err)
+	}
+	return &Transaction{ID: txID, Payload: evaluated.GetResult().GetPayload()}, nil
+}
+// ============================================================================================================================
+// proposal - a signed proposal of a function to the chaincode that runs it, and its transaction ID, the hex SHA-256 of a
+// random nonce and the creator
+// ============================================================================================================================
+func (p *Peer) proposal(function string, args []string) (*peer.SignedProposal, string, error) {
+	cc, name, err := simulator.Route(p.chaincodes, function, args)
+	if err != nil {
+		return nil, "", err
+	}
+	nonce := make([]byte, 24)
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, "", err
+	}
+	txHash := sha256.Sum256(append(append([]byte{}, nonce...), p.creator...))
+	txID := hex.EncodeToString(txHash[:])
+	extension, err := proto.Marshal(&peer.ChaincodeHeaderExtension{ChaincodeId: &peer.ChaincodeID{Name: cc.Name}})
+	if err != nil {
+		return nil, "", err
+	}
+	header, err := p.header(common.HeaderType_ENDORSER_TRANSACTION, txID, nonce, extension)
+	if err != nil {
+		return nil, "", err
+	}
+	headerAsBytes, err := proto.Marshal(header)
+	if err != nil {
+		return nil, "", err
+	}
+	input := [][]byte{[]byte(name)}
+	for _, arg := range args {
+		input = append(input, []byte(arg))
+	}
+	spec, err := proto.Marshal(&peer.ChaincodeInvocationSpec{ChaincodeSpec: &peer.ChaincodeSpec{
+		ChaincodeId: &peer.ChaincodeID{Name: cc.Name}, Input: &peer.ChaincodeInput{Args: input}}})
+	if err != nil {
+		return nil, "", err
+	}
+	payload, err := proto.Marshal(&peer.ChaincodeProposalPayload{Input: spec})
+	if err != nil {
+		return nil, "", err
+	}
+	proposalAsBytes, err := proto.Marshal(&peer.Proposal{Header: headerAsBytes, Payload: payload})
+	if err != nil {
+		return nil, "", err
+	}
+	signature, err := p.sign(proposalAsBytes)
+	if err != nil {
+		return nil, "", err
+	}
+	return &peer.SignedProposal{ProposalBytes: proposalAsBytes, Signature: signature}, txID, nil
+}
+// ============================================================================================================================
+// header - the header of a request of the creator on the channel of the peer
+// ============================================================================================================================
+func (p *Peer) header(headerType common.HeaderType, txID string, nonce []byte, extension []byte) (*common.Header, error) {
+	channelHeader, err := proto.Marshal(&common.ChannelHeader{Type: int32(headerType), Timestamp: ptypes.TimestampNow(),
+		ChannelId: p.channel, TxId: txID, Extension: extension})
+	if err != nil {
+		return nil, err
+	}
+	signatureHeader, err := proto.Marshal(&common.SignatureHeader{Creator: p.creator, Nonce: nonce})
+	if err != nil {
+		return nil, err
+	}
+	return &common.Header{ChannelHeader: channelHeader, SignatureHeader: signatureHeader}, nil
+}
+// ============================================================================================================================
+// sign - the ECDSA signature of the SHA-256 of message, with the low S value a peer requires
+// ============================================================================================================================
+func (p *Peer) sign(message []byte) ([]byte, error) {
+	digest := sha256.Sum256(message)
+	r, s, err := ecdsa.Sign(rand.Reader, p.key, digest[:])
+	if err != nil {
+		return nil, err
+	}
+	n := p.key.Params().N
+	if s.Cmp(new(big.Int).Rsh(n, 1)) > 0 {
+		s = new(big.Int).Sub(n, s)
+	}
+	return asn1.Marshal(struct{ R, S *big.Int }{r, s})
+}
+// ============================================================================================================================
+// privateKey - the ECDSA key of a PEM file, PKCS #8 or SEC 1
+// ============================================================================================================================
+func privateKey(keyAsBytes []byte) (*ecdsa.PrivateKey, error) {
+	block, _ := pem.Decode(keyAsBytes)
+	if block == nil {
+		return nil, errors.New("no PEM private key")
+	}
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	ecKey, ok := key.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("a %T key, a peer identity signs with ECDSA", key)
+	}
+	return ecKey, nil
+}
+// ============================================================================================================================
+// refusal - a function the chaincode refused as an errEvent, the contract API answers an errEvent as an error. Any other
+// error, e.g. an unreachable peer, is returned as it is
+// ============================================================================================================================
+func refusal(function string, txID string, err error) (*Transaction, error) {
+	for _, detail := range status.Convert(err).Details() {
+		errorDetail, ok := detail.(*gateway.ErrorDetail)
+		if !ok || !strings.HasPrefix(errorDetail.GetMessage(), "chaincode response ") {
+			continue
+		}
+		message := errorDetail.GetMessage()
+		if i := strings.Index(message, ", "); i >= 0 {
+			message = message[i+2:]						//chaincode response 500, <message>
+		}
+		payload, _ := json.Marshal(map[string]string{"message": message, "code": "503"})
+		return &Transaction{ID: txID, Event: &Event{Name: "errEvent", Payload: payload}}, nil
+	}
+	return nil, fmt.Errorf("%s: %s", function, err.Error())
+}
+// ============================================================================================================================
+// envelopeAction - the chaincode action of a transaction envelope
+// ============================================================================================================================
+func envelopeAction(envelope *common.Envelope) (*peer.ChaincodeAction, error) {
+	payload := &common.Payload{}
+	if err := proto.Unmarshal(envelope.GetPayload(), payload); err != nil {
+		return nil, err
+	}
+	return payloadAction(payload)
+}
+// ============================================================================================================================
+// payloadAction - the chaincode action of the payload of a transaction envelope, e.g. one of a block. A transaction of the
+// Gateway service has one action
+// ============================================================================================================================
+func payloadAction(payload *common.Payload) (*peer.ChaincodeAction, error) {
+	tx := &peer.Transaction{}
+	if err := proto.Unmarshal(payload.GetData(), tx); err != nil {
+		return nil, err
+	}
+	if len(tx.GetActions()) == 0 {
+		return nil, errors.New("a transaction with no action")
+	}
+	actionPayload := &peer.ChaincodeActionPayload{}
+	if err := proto.Unmarshal(tx.GetActions()[0].GetPayload(), actionPayload); err != nil {
+		return nil, err
+	}
+	responsePayload := &peer.ProposalResponsePayload{}
+	if err := proto.Unmarshal(actionPayload.GetAction().GetProposalResponsePayload(), responsePayload); err != nil {
+		return nil, err
+	}
+	chaincodeAction := &peer.ChaincodeAction{}
+	if err := proto.Unmarshal(responsePayload.GetExtension(), chaincodeAction); err != nil {
+		return nil, err
+	}
+	return chaincodeAction, nil
+}
+// ============================================================================================================================
+// actionEvent - the chaincode event of an action, nil when it emits none
+// ============================================================================================================================
+func actionEvent(action *peer.ChaincodeAction) *Event {
+	event := &peer.ChaincodeEvent{}
+	if err := proto.Unmarshal(action.GetEvents(), event); err != nil || event.GetEventName() == "" {
+		return nil
+	}
+	return &Event{Name: event.GetEventName(), Payload: event.GetPayload()}
+}
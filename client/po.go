@@ -0,0 +1,85 @@
+package client
+
+import (
+"github.com/wipro-blockchain/TF-v1/internal/router"
+)
+
+// ============================================================================================================================
+// CreatePO - create a PO, seller_remarks is ignored. Without a transId the chaincode generates one, e.g. PO-1F2E3D4C5B6A7980
+// ============================================================================================================================
+func (c *Client) CreatePO(record PO) (*PO, error) {
+	transId, err := c.create("create_po", record.TransID, record.SellerName, record.BuyerName, record.ExpectedDeliveryDate,
+		record.PO_date, record.PO_status, record.ItemId, record.Item_name, record.Item_quantity, record.Price,
+		record.Buyer_sign, record.Seller_sign)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetPO(transId)
+}
+// ============================================================================================================================
+// UpdatePO - replace a PO, ErrConflict when it changed since record was read
+// ============================================================================================================================
+func (c *Client) UpdatePO(record PO) (*PO, error) {
+	_, err := c.Submit("update_po", router.WithVersion([]string{record.TransID, record.SellerName, record.BuyerName,
+		record.ExpectedDeliveryDate, record.PO_date, record.PO_status, record.ItemId, record.Item_name, record.Item_quantity,
+		record.Price, record.Buyer_sign, record.Seller_sign, record.Seller_Remarks}, record.Version)...)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetPO(record.TransID)
+}
+// ============================================================================================================================
+// PatchPO - change only the fields of a PO given in patch, e.g. Patch{"seller_sign": "true"}, a "version" in patch is the
+// version the change is based on
+// ============================================================================================================================
+func (c *Client) PatchPO(transId string, patch Patch) (*PO, error) {
+	if _, err := c.Submit("update_po", patchArg("transId", transId, patch)); err != nil {
+		return nil, err
+	}
+	return c.GetPO(transId)
+}
+// ============================================================================================================================
+// DeletePO - delete a PO
+// ============================================================================================================================
+func (c *Client) DeletePO(transId string) error {
+	_, err := c.Submit("delete_po", transId)
+	return err
+}
+// ============================================================================================================================
+// GetPO - a PO by transId
+// ============================================================================================================================
+func (c *Client) GetPO(transId string) (*PO, error) {
+	record := &PO{}
+	if err := c.query(record, "getPO_byID", transId); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+// ============================================================================================================================
+// GetPOsByBuyer - the POs of a buyer
+// ============================================================================================================================
+func (c *Client) GetPOsByBuyer(buyerName string) ([]*PO, error) {
+	return c.pos("getPO_byBuyer", buyerName)
+}
+// ============================================================================================================================
+// GetPOsBySeller - the POs of a seller
+// ============================================================================================================================
+func (c *Client) GetPOsBySeller(sellerName string) ([]*PO, error) {
+	return c.pos("getPO_bySeller", sellerName)
+}
+// ============================================================================================================================
+// GetAllPOs - every PO
+// ============================================================================================================================
+func (c *Client) GetAllPOs() ([]*PO, error) {
+	return c.pos("get_AllPO", " ")
+}
+// ============================================================================================================================
+// pos - the POs returned by a list query
+// ============================================================================================================================
+func (c *Client) pos(function string, arg string) ([]*PO, error) {
+	records := []*PO{}
+	if err := c.queryList(&records, function, arg); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
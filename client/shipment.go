@@ -0,0 +1,298 @@
+package client
+
+import (
+"strconv"
+
+"github.com/wipro-blockchain/TF-v1/internal/router"
+)
+
+// ColdChainThresholds is the temperature and humidity range of a cold-chain Shipment, the request of SetColdChainThresholds
+type ColdChainThresholds struct {
+	ShipmentID string `json:"shipmentId"`
+	MinTemperature float64 `json:"min_temperature"`
+	MaxTemperature float64 `json:"max_temperature"`
+	MinHumidity float64 `json:"min_humidity"`
+	MaxHumidity float64 `json:"max_humidity"`
+}
+
+// ============================================================================================================================
+// shipmentArgs - the positional arguments of create_shipment and update_shipment
+// ============================================================================================================================
+func shipmentArgs(record Shipment) []string {
+	return []string{record.ShipmentID, record.TransID, record.AgreementID, record.Shipment_status, record.Source,
+		record.Destination, record.ActualDelivery_date, record.Shipment_date, record.ShipperName}
+}
+// ============================================================================================================================
+// CreateShipment - create a Shipment, the clearance status is set by the chaincode. Without a shipmentId the chaincode
+// generates one, e.g. SHP-1F2E3D4C5B6A7980
+// ============================================================================================================================
+func (c *Client) CreateShipment(record Shipment) (*Shipment, error) {
+	shipmentId, err := c.create("create_shipment", shipmentArgs(record)...)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetShipment(shipmentId)
+}
+// ============================================================================================================================
+// UpdateShipment - replace a Shipment, ErrConflict when it changed since record was read
+// ============================================================================================================================
+func (c *Client) UpdateShipment(record Shipment) (*Shipment, error) {
+	if _, err := c.Submit("update_shipment", router.WithVersion(shipmentArgs(record), record.Version)...); err != nil {
+		return nil, err
+	}
+	return c.GetShipment(record.ShipmentID)
+}
+// ============================================================================================================================
+// PatchShipment - change only the fields of a Shipment given in patch, e.g. Patch{"shipment_status": "InTransit"}
+// ============================================================================================================================
+func (c *Client) PatchShipment(shipmentId string, patch Patch) (*Shipment, error) {
+	if _, err := c.Submit("update_shipment", patchArg("shipmentId", shipmentId, patch)); err != nil {
+		return nil, err
+	}
+	return c.GetShipment(shipmentId)
+}
+// ============================================================================================================================
+// DeleteShipment - delete a Shipment
+// ============================================================================================================================
+func (c *Client) DeleteShipment(shipmentId string) error {
+	_, err := c.Submit("delete_shipment", shipmentId)
+	return err
+}
+// ============================================================================================================================
+// AddTrackingEvent - append a tracking event to a Shipment and return its timeline, the sequence is assigned by the chaincode
+// ============================================================================================================================
+func (c *Client) AddTrackingEvent(event TrackingEvent) ([]*TrackingEvent, error) {
+	if _, err := c.Submit("add_tracking_event", event.ShipmentID, event.EventType, event.Location, event.Timestamp, event.ReportingParty); err != nil {
+		return nil, err
+	}
+	return c.GetShipmentTimeline(event.ShipmentID)
+}
+// ============================================================================================================================
+// IssueEBL - issue the bill of lading of a Shipment
+// ============================================================================================================================
+func (c *Client) IssueEBL(shipmentId string, eblId string) (*BillOfLading, error) {
+	if _, err := c.Submit("issue_ebl", shipmentId, eblId); err != nil {
+		return nil, err
+	}
+	return c.GetEBL(shipmentId)
+}
+// ============================================================================================================================
+// TransferEBL - endorse the bill of lading of a Shipment to a new holder
+// ============================================================================================================================
+func (c *Client) TransferEBL(shipmentId string, currentHolder string, newHolder string) (*BillOfLading, error) {
+	if _, err := c.Submit("transfer_ebl", shipmentId, currentHolder, newHolder); err != nil {
+		return nil, err
+	}
+	return c.GetEBL(shipmentId)
+}
+// ============================================================================================================================
+// SurrenderEBL - surrender the bill of lading of a Shipment at destination
+// ============================================================================================================================
+func (c *Client) SurrenderEBL(shipmentId string, holder string, location string) (*BillOfLading, error) {
+	if _, err := c.Submit("surrender_ebl", shipmentId, holder, location); err != nil {
+		return nil, err
+	}
+	return c.GetEBL(shipmentId)
+}
+// ============================================================================================================================
+// ReleaseCargo - release the cargo of a Shipment whose bill of lading was surrendered
+// ============================================================================================================================
+func (c *Client) ReleaseCargo(shipmentId string) (*Shipment, error) {
+	if _, err := c.Submit("release_cargo", shipmentId); err != nil {
+		return nil, err
+	}
+	return c.GetShipment(shipmentId)
+}
+// ============================================================================================================================
+// SetColdChainThresholds - configure the temperature and humidity range of a cold-chain Shipment
+// ============================================================================================================================
+func (c *Client) SetColdChainThresholds(thresholds ColdChainThresholds) error {
+	_, err := c.Submit("set_cold_chain_thresholds", thresholds.ShipmentID, formatFloat(thresholds.MinTemperature),
+		formatFloat(thresholds.MaxTemperature), formatFloat(thresholds.MinHumidity), formatFloat(thresholds.MaxHumidity))
+	return err
+}
+// ============================================================================================================================
+// RegisterSensorDevice - trust a sensor device, by its PEM encoded public key, for a Shipment
+// ============================================================================================================================
+func (c *Client) RegisterSensorDevice(shipmentId string, deviceId string, publicKey string) error {
+	_, err := c.Submit("register_sensor_device", shipmentId, deviceId, publicKey)
+	return err
+}
+// ============================================================================================================================
+// AddSensorReadings - record a batch of signed sensor readings, the excursions are set by the chaincode
+// ============================================================================================================================
+func (c *Client) AddSensorReadings(shipmentId string, readings []SensorReading) (*TelemetrySummary, error) {
+	if _, err := c.Submit("add_sensor_readings", shipmentId, jsonArg(readings)); err != nil {
+		return nil, err
+	}
+	return c.GetTelemetrySummary(shipmentId)
+}
+// ============================================================================================================================
+// PortClearanceAction - the port authority acts on a Shipment: RequestDocuments, PlaceHold, Inspect or Clear
+// ============================================================================================================================
+func (c *Client) PortClearanceAction(shipmentId string, portAuthority string, action string, reason string) (*Clearance, error) {
+	if _, err := c.Submit("port_clearance_action", shipmentId, portAuthority, action, reason); err != nil {
+		return nil, err
+	}
+	return c.GetClearance(shipmentId)
+}
+// ============================================================================================================================
+// SubmitClearanceDocuments - answer a document request of the port authority
+// ============================================================================================================================
+func (c *Client) SubmitClearanceDocuments(shipmentId string, party string, documents string) (*Clearance, error) {
+	if _, err := c.Submit("submit_clearance_documents", shipmentId, party, documents); err != nil {
+		return nil, err
+	}
+	return c.GetClearance(shipmentId)
+}
+// ============================================================================================================================
+// EvaluateDeliverySLA - compare the planned and actual delivery of a Shipment
+// ============================================================================================================================
+func (c *Client) EvaluateDeliverySLA(shipmentId string) (*DeliverySLA, error) {
+	if _, err := c.Submit("evaluate_delivery_sla", shipmentId); err != nil {
+		return nil, err
+	}
+	return c.GetDeliverySLA(shipmentId)
+}
+// ============================================================================================================================
+// SetShipmentItems - record the Agreement lines a Shipment carries
+// ============================================================================================================================
+func (c *Client) SetShipmentItems(shipmentId string, items []ShipmentItem) ([]*ShipmentItem, error) {
+	if _, err := c.Submit("set_shipment_items", shipmentId, jsonArg(items)); err != nil {
+		return nil, err
+	}
+	return c.GetShipmentItems(shipmentId)
+}
+// ============================================================================================================================
+// GetShipment - a Shipment by shipmentId
+// ============================================================================================================================
+func (c *Client) GetShipment(shipmentId string) (*Shipment, error) {
+	record := &Shipment{}
+	if err := c.query(record, "getShipment_byID", shipmentId); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+// ============================================================================================================================
+// GetShipmentsByStatus - the Shipments in a status
+// ============================================================================================================================
+func (c *Client) GetShipmentsByStatus(status string) ([]*Shipment, error) {
+	return c.shipments("getShipment_byStatus", status)
+}
+// ============================================================================================================================
+// GetShipmentsByShipper - the Shipments of a shipper
+// ============================================================================================================================
+func (c *Client) GetShipmentsByShipper(shipperName string) ([]*Shipment, error) {
+	return c.shipments("getShipment_byShipper", shipperName)
+}
+// ============================================================================================================================
+// GetShipmentsByAgreement - the Shipments of an Agreement
+// ============================================================================================================================
+func (c *Client) GetShipmentsByAgreement(agreementId string) ([]*Shipment, error) {
+	return c.shipments("getShipment_byAgreement", agreementId)
+}
+// ============================================================================================================================
+// GetAllShipments - every Shipment
+// ============================================================================================================================
+func (c *Client) GetAllShipments() ([]*Shipment, error) {
+	return c.shipments("get_AllShipment", " ")
+}
+// ============================================================================================================================
+// shipments - the Shipments returned by a list query
+// ============================================================================================================================
+func (c *Client) shipments(function string, arg string) ([]*Shipment, error) {
+	records := []*Shipment{}
+	if err := c.queryList(&records, function, arg); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+// ============================================================================================================================
+// GetShipmentTimeline - the tracking events of a Shipment in sequence
+// ============================================================================================================================
+func (c *Client) GetShipmentTimeline(shipmentId string) ([]*TrackingEvent, error) {
+	events := []*TrackingEvent{}
+	if err := c.queryList(&events, "get_shipment_timeline", shipmentId); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+// ============================================================================================================================
+// GetEBL - the bill of lading of a Shipment
+// ============================================================================================================================
+func (c *Client) GetEBL(shipmentId string) (*BillOfLading, error) {
+	ebl := &BillOfLading{}
+	if err := c.query(ebl, "get_ebl", shipmentId); err != nil {
+		return nil, err
+	}
+	return ebl, nil
+}
+// ============================================================================================================================
+// GetSensorReadings - the sensor readings of a Shipment
+// ============================================================================================================================
+func (c *Client) GetSensorReadings(shipmentId string) ([]*SensorReading, error) {
+	readings := []*SensorReading{}
+	if err := c.queryList(&readings, "get_sensor_readings", shipmentId); err != nil {
+		return nil, err
+	}
+	return readings, nil
+}
+// ============================================================================================================================
+// GetTelemetrySummary - the readings and excursions of a cold-chain Shipment
+// ============================================================================================================================
+func (c *Client) GetTelemetrySummary(shipmentId string) (*TelemetrySummary, error) {
+	summary := &TelemetrySummary{}
+	if err := c.query(summary, "get_telemetry_summary", shipmentId); err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+// ============================================================================================================================
+// GetClearance - the port clearance of a Shipment
+// ============================================================================================================================
+func (c *Client) GetClearance(shipmentId string) (*Clearance, error) {
+	clearance := &Clearance{}
+	if err := c.query(clearance, "get_clearance", shipmentId); err != nil {
+		return nil, err
+	}
+	return clearance, nil
+}
+// ============================================================================================================================
+// GetDeliverySLA - the delivery SLA of a Shipment
+// ============================================================================================================================
+func (c *Client) GetDeliverySLA(shipmentId string) (*DeliverySLA, error) {
+	sla := &DeliverySLA{}
+	if err := c.query(sla, "get_delivery_sla", shipmentId); err != nil {
+		return nil, err
+	}
+	return sla, nil
+}
+// ============================================================================================================================
+// GetShipperPerformance - on-time performance of a shipper, of every shipper when shipperName is empty
+// ============================================================================================================================
+func (c *Client) GetShipperPerformance(shipperName string) ([]*ShipperPerformance, error) {
+	if shipperName == "" {
+		shipperName = " "
+	}
+	performance := []*ShipperPerformance{}
+	if err := c.queryList(&performance, "get_shipper_performance", shipperName); err != nil {
+		return nil, err
+	}
+	return performance, nil
+}
+// ============================================================================================================================
+// GetShipmentItems - the Agreement lines a Shipment carries
+// ============================================================================================================================
+func (c *Client) GetShipmentItems(shipmentId string) ([]*ShipmentItem, error) {
+	items := []*ShipmentItem{}
+	if err := c.queryList(&items, "get_shipment_items", shipmentId); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+// ============================================================================================================================
+// formatFloat - a float argument in the form the chaincode parses
+// ============================================================================================================================
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,184 @@
+package client
+
+import (
+"bytes"
+"errors"
+"fmt"
+"net/http"
+"net/url"
+"strings"
+"sync"
+"encoding/json"
+
+"github.com/wipro-blockchain/TF-v1/internal/mockstub"
+"github.com/wipro-blockchain/TF-v1/internal/simulator"
+)
+
+// Transport runs the chaincode functions by their original names, on the peers or on the in-memory test ledger
+type Transport interface {
+	Submit(function string, args ...string) (*Transaction, error)		// an invoke, committed to the ledger
+	Evaluate(function string, args ...string) (*Transaction, error)	// a query, nothing is committed
+}
+
+// Transaction is the outcome of a function, the error of Submit and Evaluate means it was rejected
+type Transaction struct {
+	ID string `json:"txId"`
+	Payload []byte `json:"payload"`
+	Event *Event `json:"event,omitempty"`			// the event the transaction emitted, a peer emits only the last one
+}
+
+// Event is a chaincode event, e.g. po.created or errEvent, see package events
+type Event struct {
+	Name string `json:"name"`
+	Payload []byte `json:"payload"`
+}
+
+// Simulated is a Transport on the in-process simulated network, the in-memory test ledger
+type Simulated struct {
+	Network *simulator.Network
+	mu sync.Mutex									// the in-memory ledger runs one transaction at a time
+}
+
+// ============================================================================================================================
+// NewSimulated - a Transport on a fresh simulated network, the single TradeFinance chaincode or the four separate ones
+// ============================================================================================================================
+func NewSimulated(single bool, balance string) (*Simulated, error) {
+	var n *simulator.Network
+	var err error
+	if single {
+		n, err = simulator.NewTradeFinance(balance)
+	}else{
+		n, err = simulator.New(balance)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Simulated{Network: n}, nil
+}
+// ============================================================================================================================
+// Submit - run an invoke function on the simulated network
+// ============================================================================================================================
+func (s *Simulated) Submit(function string, args ...string) (*Transaction, error) {
+	return s.call(nil, false, function, args)
+}
+// ============================================================================================================================
+// Evaluate - run a query function on the simulated network, an invoke is refused
+// ============================================================================================================================
+func (s *Simulated) Evaluate(function string, args ...string) (*Transaction, error) {
+	return s.call(nil, true, function, args)
+}
+// ============================================================================================================================
+// As - a Transport on the same simulated network submitting as mspID and the certificate common name commonName, e.g. for
+// a caller of the gateway. Without it the functions run as the identity that initialized the chaincodes, their admin
+// ============================================================================================================================
+func (s *Simulated) As(mspID string, commonName string) (Transport, error) {
+	creator, err := mockstub.Identity(mspID, commonName)
+	if err != nil {
+		return nil, err
+	}
+	return &simulatedCaller{simulated: s, creator: creator}, nil
+}
+// ============================================================================================================================
+// call - run a function as one transaction, by creator when it is not nil, and only a query function when query is set.
+// Only the last event is kept as a peer emits only that one
+// ============================================================================================================================
+func (s *Simulated) call(creator []byte, query bool, function string, args []string) (*Transaction, error) {
+	if query {
+		if _, _, err := simulator.Query(s.Network.Chaincodes, function, args); err != nil {
+			return nil, err
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var res simulator.Result
+	if creator != nil {
+		res = s.Network.CallAs(creator, function, args...)
+	}else{
+		res = s.Network.Call(function, args...)
+	}
+	if res.Err != nil {
+		return nil, errors.New(res.Err.Error())
+	}
+	tx := &Transaction{ID: res.TxID, Payload: res.Response}
+	if len(res.Events) > 0 {
+		event := res.Events[len(res.Events)-1]
+		tx.Event = &Event{Name: event.Name, Payload: event.Payload}
+	}
+	return tx, nil
+}
+
+// simulatedCaller is a Transport on a simulated network submitting as one identity, see Simulated.As
+type simulatedCaller struct {
+	simulated *Simulated
+	creator []byte
+}
+
+func (c *simulatedCaller) Submit(function string, args ...string) (*Transaction, error) {
+	return c.simulated.call(c.creator, false, function, args)
+}
+
+func (c *simulatedCaller) Evaluate(function string, args ...string) (*Transaction, error) {
+	return c.simulated.call(c.creator, true, function, args)
+}
+
+// HTTPTransport is a Transport through the REST gateway, which runs the functions as the caller of Token
+type HTTPTransport struct {
+	BaseURL string									// e.g. http://localhost:8080
+	Token string									// the caller's bearer token, see the gateway's -callers
+	Client *http.Client								// http.DefaultClient when nil
+}
+
+// ============================================================================================================================
+// Submit - run an invoke function through POST /transactions/{function}
+// ============================================================================================================================
+func (h *HTTPTransport) Submit(function string, args ...string) (*Transaction, error) {
+	return h.post("/transactions/", function, args)
+}
+// ============================================================================================================================
+// Evaluate - run a query function through POST /queries/{function}
+// ============================================================================================================================
+func (h *HTTPTransport) Evaluate(function string, args ...string) (*Transaction, error) {
+	return h.post("/queries/", function, args)
+}
+// ============================================================================================================================
+// post - send a function and its arguments to the gateway, an answer other than 200 carries the error
+// ============================================================================================================================
+func (h *HTTPTransport) post(path string, function string, args []string) (*Transaction, error) {
+	if args == nil {
+		args = []string{}
+	}
+	body, _ := json.Marshal(struct {
+		Args []string `json:"args"`
+	}{args})
+	httpClient := h.Client
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	req, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(h.BaseURL, "/") + path + url.PathEscape(function), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if h.Token != "" {
+		req.Header.Set("Authorization", "Bearer " + h.Token)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		answer := struct {
+			Error string `json:"error"`
+		}{}
+		if json.NewDecoder(resp.Body).Decode(&answer) != nil || answer.Error == "" {
+			return nil, fmt.Errorf("%s: gateway answered %s", function, resp.Status)
+		}
+		return nil, errors.New(answer.Error)
+	}
+	tx := &Transaction{}
+	if err = json.NewDecoder(resp.Body).Decode(tx); err != nil {
+		return nil, fmt.Errorf("%s: malformed gateway answer: %s", function, err.Error())
+	}
+	return tx, nil
+}
@@ -0,0 +1,316 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements.  See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership.  The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License.  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+// Package events is the catalog of the chaincode events of the trade-finance chaincodes. A successful
+// invoke emits one event named after what happened, e.g. po.created or payment.settled, with an Event
+// as its payload; a refused one emits errEvent with an Error. Listeners subscribe to the names they
+// need with Filter, the regular expression a Fabric event listener takes, and read the payload with
+// Decode.
+package events
+
+import (
+"errors"
+"fmt"
+"regexp"
+"sort"
+"strings"
+"encoding/json"
+)
+
+// SchemaVersion is the version of the Event payload, raised when a field changes meaning or is removed
+const SchemaVersion = "1"
+
+// ErrorName is the name of the event of a refused invoke, its payload is an Error
+const ErrorName = "errEvent"
+
+// LegacyName is the name every successful invoke emitted before the catalog. While a chaincode's admin keeps the legacy
+// events on, see set_legacy_events, its events are emitted under it with the field of LegacyIDFields added, and the
+// catalog name stays in the type
+const LegacyName = "evtsender"
+
+// LegacyIDFields are the fields the evtsender payload named its record with, by entity
+var LegacyIDFields = map[string]string{"po": "transID", "agreement": "agreementID", "fraud": "Fraud ID", "payment": "paymentID",
+	"escrow": "paymentID", "shipment": "shipmentID"}
+
+// The event names, <entity>.<what happened>
+const (
+	ChaincodeDeployed = "chaincode.deployed"
+	ChaincodeRegistered = "chaincode.registered"
+	ChaincodeKeysMigrated = "chaincode.keys_migrated"
+	ChaincodeLegacyEventsSet = "chaincode.legacy_events_set"
+	PartyRegistered = "party.registered"
+	BatchExecuted = "batch.executed"
+
+	POCreated = "po.created"
+	POUpdated = "po.updated"
+	POSigned = "po.signed"
+	PODeleted = "po.deleted"
+
+	AgreementCreated = "agreement.created"
+	AgreementUpdated = "agreement.updated"
+	AgreementSigned = "agreement.signed"
+	AgreementDeleted = "agreement.deleted"
+	AgreementClearanceUpdated = "agreement.clearance_updated"
+	AgreementTermsSet = "agreement.terms_set"
+	AgreementShipped = "agreement.shipped"
+	FraudListed = "fraud.listed"
+
+	PaymentCreated = "payment.created"
+	PaymentUpdated = "payment.updated"
+	PaymentSettled = "payment.settled"
+	PaymentDeleted = "payment.deleted"
+	PaymentExported = "payment.exported"
+	PaymentNotified = "payment.notified"
+	PaymentDamagesDeducted = "payment.damages_deducted"
+	EscrowCreated = "escrow.created"
+	EscrowConditionSatisfied = "escrow.condition_satisfied"
+	EscrowReleased = "escrow.released"
+	EscrowRefunded = "escrow.refunded"
+	StatementReconciled = "statement.reconciled"
+	StatementExceptionResolved = "statement.exception_resolved"
+
+	ShipmentCreated = "shipment.created"
+	ShipmentUpdated = "shipment.updated"
+	ShipmentDeleted = "shipment.deleted"
+	ShipmentTracked = "shipment.tracked"
+	ShipmentDelivered = "shipment.delivered"
+	ShipmentReleased = "shipment.released"
+	ShipmentItemsRecorded = "shipment.items_recorded"
+	ShipmentThresholdsSet = "shipment.thresholds_set"
+	ShipmentSensorRegistered = "shipment.sensor_registered"
+	ShipmentReadingsAdded = "shipment.readings_added"
+	ShipmentClearanceUpdated = "shipment.clearance_updated"
+	ShipmentDocumentsSubmitted = "shipment.documents_submitted"
+	ShipmentSLAEvaluated = "shipment.sla_evaluated"
+	BillOfLadingIssued = "ebl.issued"
+	BillOfLadingTransferred = "ebl.transferred"
+	BillOfLadingSurrendered = "ebl.surrendered"
+)
+
+// Type is an entry of the catalog, an event name and the entity it is about
+type Type struct {
+	Name string `json:"name"`
+	Entity string `json:"entity"`							// the kind of record the entityId names, e.g. po or payment
+	Description string `json:"description"`
+	Since string `json:"since"`							// the SchemaVersion that introduced it
+}
+
+// Catalog lists every event the chaincodes emit, errEvent aside
+var Catalog = []Type{
+	{ChaincodeDeployed, "chaincode", "A chaincode was deployed or reset with init", "1"},
+	{ChaincodeRegistered, "chaincode", "The deployed name of another chaincode was recorded by register_chaincode", "1"},
+	{ChaincodeKeysMigrated, "chaincode", "The records stored under their bare ID were moved to their namespaced keys by migrate_keys, data.moved maps each new key to the former one", "1"},
+	{ChaincodeLegacyEventsSet, "chaincode", "The admin turned the legacy evtsender events on or off with set_legacy_events, the status is true or false", "1"},
+	{PartyRegistered, "party", "The identity acting for a trade party was recorded by register_party", "1"},
+	{BatchExecuted, "batch", "Several invokes were committed together by execute_batch, data.steps holds the event of each", "1"},
+
+	{POCreated, "po", "A PO was created", "1"},
+	{POUpdated, "po", "A PO was updated without a new signature", "1"},
+	{POSigned, "po", "A PO was updated with a new signature, e.g. the seller accepting it", "1"},
+	{PODeleted, "po", "A PO was deleted", "1"},
+
+	{AgreementCreated, "agreement", "An Agreement was created", "1"},
+	{AgreementUpdated, "agreement", "An Agreement was updated without a new signature", "1"},
+	{AgreementSigned, "agreement", "A party signed an Agreement, the status tells which approval it reached", "1"},
+	{AgreementDeleted, "agreement", "An Agreement was deleted", "1"},
+	{AgreementClearanceUpdated, "agreement", "The clearance status of a Shipment was copied to its Agreement", "1"},
+	{AgreementTermsSet, "agreement", "The liquidated damages terms of an Agreement were set", "1"},
+	{AgreementShipped, "agreement", "A Shipment, data.shipmentId, was booked against an Agreement and changed its shipped balance", "1"},
+	{FraudListed, "fraud", "A name was added to the fraud list", "1"},
+
+	{PaymentCreated, "payment", "A Payment was created", "1"},
+	{PaymentUpdated, "payment", "A Payment was updated without being settled", "1"},
+	{PaymentSettled, "payment", "The buyer bank signed a Payment and its amount was debited, into escrow if it has one", "1"},
+	{PaymentDeleted, "payment", "A Payment was deleted", "1"},
+	{PaymentExported, "payment", "A Payment was exported as an ISO 20022 pain.001 message", "1"},
+	{PaymentNotified, "notification", "An ISO 20022 camt.054 notification, the entityId is its message ID, updated the Payments in data.paymentIds", "1"},
+	{PaymentDamagesDeducted, "agreement", "Liquidated damages for late delivery, data.amount, were deducted from the settled Payments of an Agreement, the entityId", "1"},
+	{EscrowCreated, "escrow", "An escrow was opened for a Payment, the entityId is the payment ID", "1"},
+	{EscrowConditionSatisfied, "escrow", "A release condition of an escrow was met, others are still pending", "1"},
+	{EscrowReleased, "escrow", "The escrowed funds of a Payment were released to the seller", "1"},
+	{EscrowRefunded, "escrow", "The escrow of a Payment was refunded to the buyer or cancelled", "1"},
+	{StatementReconciled, "statement", "A bank statement was reconciled with the Payments, data counts the matched, partially matched and unmatched lines", "1"},
+	{StatementExceptionResolved, "statement", "A reconciliation exception of a statement line was resolved", "1"},
+
+	{ShipmentCreated, "shipment", "A Shipment was created", "1"},
+	{ShipmentUpdated, "shipment", "A Shipment was updated", "1"},
+	{ShipmentDeleted, "shipment", "A Shipment was deleted", "1"},
+	{ShipmentTracked, "shipment", "A tracking milestone other than delivery was reported", "1"},
+	{ShipmentDelivered, "shipment", "A Shipment was reported delivered", "1"},
+	{ShipmentReleased, "shipment", "The cargo of a Shipment was released to the holder of its bill of lading", "1"},
+	{ShipmentItemsRecorded, "shipment", "The shipped quantities of a partial Shipment were recorded", "1"},
+	{ShipmentThresholdsSet, "shipment", "The cold-chain thresholds of a Shipment were set", "1"},
+	{ShipmentSensorRegistered, "shipment", "A sensor device was registered for a Shipment", "1"},
+	{ShipmentReadingsAdded, "shipment", "Sensor readings were added to a Shipment, data.excursions counts those out of range", "1"},
+	{ShipmentClearanceUpdated, "shipment", "The port authority acted on a Shipment", "1"},
+	{ShipmentDocumentsSubmitted, "shipment", "The documents the port authority requested were submitted", "1"},
+	{ShipmentSLAEvaluated, "shipment", "The delivery of a Shipment was compared with its SLA, the changes hold the SLA status and damages", "1"},
+	{BillOfLadingIssued, "ebl", "An electronic bill of lading was issued for the Shipment in data.shipmentId", "1"},
+	{BillOfLadingTransferred, "ebl", "The title of a bill of lading was transferred to a new holder", "1"},
+	{BillOfLadingSurrendered, "ebl", "A bill of lading was surrendered to the carrier", "1"},
+}
+
+// Event is the payload of every event in the Catalog
+type Event struct {
+	Type string `json:"type"`								// the event name, e.g. po.created
+	SchemaVersion string `json:"schemaVersion"`
+	EntityType string `json:"entityType"`					// the Entity of the Type
+	EntityID string `json:"entityId"`
+	Status string `json:"status,omitempty"`				// the status of the entity after the event
+	Actor string `json:"actor"`							// the identity that submitted the transaction, empty on a test ledger
+	TxID string `json:"txId"`
+	Timestamp string `json:"timestamp"`					// the transaction time, RFC 3339 in UTC
+	Version int `json:"version,omitempty"`				// the version of the record after the event
+	Changes []Change `json:"changes"`						// the fields the event changed, in field order
+	Data map[string]interface{} `json:"data,omitempty"`	// facts of the event that are not record fields, see the Description
+	Message string `json:"message"`						// the message the events carried before the catalog
+	Code string `json:"code"`
+}
+
+// Change is a field of the record changed by an event, From is null for a created record and To for a deleted one
+type Change struct {
+	Field string `json:"field"`
+	From json.RawMessage `json:"from"`
+	To json.RawMessage `json:"to"`
+}
+
+// Error is the payload of errEvent
+type Error struct {
+	Message string `json:"message"`
+	Code string `json:"code"`								// 409 for a version conflict, 422 for a reused idempotency key, otherwise 503
+}
+
+// ============================================================================================================================
+// Lookup - the catalog entry of an event name
+// ============================================================================================================================
+func Lookup(name string) (Type, bool) {
+	for _, t := range Catalog {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return Type{}, false
+}
+// ============================================================================================================================
+// Names - the names of the catalog events of some entities, e.g. Names("payment"), or of all of them without arguments
+// ============================================================================================================================
+func Names(entities ...string) []string {
+	names := []string{}
+	for _, t := range Catalog {
+		if len(entities) == 0 || contains(entities, t.Entity) {
+			names = append(names, t.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+// ============================================================================================================================
+// Filter - the regular expression matching exactly the event names given, for a Fabric listener, e.g.
+// Filter(events.POCreated, events.PaymentSettled). A name ending in ".*" matches the events of an entity, e.g. "payment.*"
+// ============================================================================================================================
+func Filter(names ...string) (string, error) {
+	parts := []string{}
+	for _, name := range names {
+		if strings.HasSuffix(name, ".*") {
+			prefix := strings.TrimSuffix(name, ".*")
+			if !hasPrefix(prefix) {
+				return "", fmt.Errorf("no event of the catalog starts with %s.", prefix)
+			}
+			parts = append(parts, regexp.QuoteMeta(prefix + ".") + "[a-z_]+")
+			continue
+		}
+		if _, found := Lookup(name); !found && name != ErrorName && name != LegacyName {
+			return "", fmt.Errorf("%s is not an event of the catalog", name)
+		}
+		parts = append(parts, regexp.QuoteMeta(name))
+	}
+	if len(parts) == 0 {
+		return "", errors.New("no event names to filter")
+	}
+	return "^(" + strings.Join(parts, "|") + ")$", nil
+}
+// ============================================================================================================================
+// Decode - the payload of an event of the catalog. An event of a newer schema is refused, so a listener notices before
+// reading a field whose meaning changed
+// ============================================================================================================================
+func Decode(name string, payload []byte) (*Event, error) {
+	name = CatalogName(name, payload)
+	if _, found := Lookup(name); !found {
+		return nil, fmt.Errorf("%s is not an event of the catalog", name)
+	}
+	event := Event{}
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("malformed %s event: %w", name, err)
+	}
+	if event.Type != name {
+		return nil, fmt.Errorf("%s event with the type %q", name, event.Type)
+	}
+	if event.SchemaVersion != SchemaVersion {
+		return nil, fmt.Errorf("%s event of schema version %s, this catalog reads version %s", name, event.SchemaVersion, SchemaVersion)
+	}
+	return &event, nil
+}
+// ============================================================================================================================
+// CatalogName - the catalog name of an event: its name, or the type in its payload for an event emitted under LegacyName
+// ============================================================================================================================
+func CatalogName(name string, payload []byte) string {
+	if name != LegacyName {
+		return name
+	}
+	event := struct {
+		Type string `json:"type"`
+	}{}
+	if json.Unmarshal(payload, &event) != nil || event.Type == "" {
+		return name
+	}
+	return event.Type
+}
+// ============================================================================================================================
+// Changed - the change of a field, nil when the event left it as it was
+// ============================================================================================================================
+func (e *Event) Changed(field string) *Change {
+	for i := range e.Changes {
+		if e.Changes[i].Field == field {
+			return &e.Changes[i]
+		}
+	}
+	return nil
+}
+// ============================================================================================================================
+// contains - whether a list holds a value
+// ============================================================================================================================
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+// ============================================================================================================================
+// hasPrefix - whether an event name of the catalog starts with prefix and a dot, e.g. escrow for escrow.created
+// ============================================================================================================================
+func hasPrefix(prefix string) bool {
+	for _, t := range Catalog {
+		if strings.HasPrefix(t.Name, prefix + ".") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package events_test
+
+import (
+"regexp"
+"strings"
+"testing"
+"encoding/json"
+
+"github.com/wipro-blockchain/TF-v1/events"
+)
+
+func TestCatalog(t *testing.T) {
+	seen := map[string]bool{}
+	for _, entry := range events.Catalog {
+		if seen[entry.Name] || entry.Entity == "" || entry.Description == "" || entry.Since != events.SchemaVersion {
+			t.Errorf("catalog entry %+v", entry)
+		}
+		seen[entry.Name] = true
+		if !regexp.MustCompile(`^[a-z]+\.[a-z_]+$`).MatchString(entry.Name) {
+			t.Errorf("event name %s is not <entity>.<what happened>", entry.Name)
+		}
+	}
+	if entry, found := events.Lookup(events.PaymentSettled); !found || entry.Entity != "payment" {
+		t.Errorf("Lookup(payment.settled): %+v %v", entry, found)
+	}
+	if _, found := events.Lookup("evtsender"); found {
+		t.Errorf("evtsender is in the catalog")
+	}
+	if names := events.Names("po"); strings.Join(names, ",") != "po.created,po.deleted,po.signed,po.updated" {
+		t.Errorf("Names(po): %v", names)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	filter, err := events.Filter(events.POCreated, "escrow.*", events.ErrorName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	matcher := regexp.MustCompile(filter)
+	for name, expected := range map[string]bool{"po.created": true, "escrow.released": true, "errEvent": true,
+		"po.updated": false, "po.createdX": false, "xpo.created": false, "payment.settled": false} {
+		if matcher.MatchString(name) != expected {
+			t.Errorf("filter %s on %s: expected %v", filter, name, expected)
+		}
+	}
+	for _, names := range [][]string{{"po.archived"}, {"customs.*"}, {}} {
+		if _, err = events.Filter(names...); err == nil {
+			t.Errorf("Filter(%v) succeeded", names)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	payload := `{"type": "payment.settled", "schemaVersion": "1", "entityType": "payment", "entityId": "PAY1", "status": "Paid",
+		"actor": "BuyerBankMSP:admin", "txId": "tx9", "timestamp": "2024-01-01T00:00:09Z", "version": 2,
+		"changes": [{"field": "buyerBank_sign", "from": "false", "to": "true"}], "message": "Payment updated succcessfully", "code": "200"}`
+	event, err := events.Decode(events.PaymentSettled, []byte(payload))
+	if err != nil || event.EntityID != "PAY1" || event.Version != 2 || event.Actor != "BuyerBankMSP:admin" {
+		t.Fatalf("Decode: %+v %v", event, err)
+	}
+	if change := event.Changed("buyerBank_sign"); change == nil || string(change.To) != `"true"` || event.Changed("paymentStatus") != nil {
+		t.Errorf("Changed: %+v", event.Changes)
+	}
+	if _, err = events.Decode(events.PaymentCreated, []byte(payload)); err == nil {
+		t.Errorf("Decode under another name succeeded")
+	}
+	if _, err = events.Decode(events.PaymentSettled, []byte(strings.Replace(payload, `"schemaVersion": "1"`, `"schemaVersion": "2"`, 1))); err == nil {
+		t.Errorf("Decode of a newer schema version succeeded")
+	}
+	if _, err = events.Decode("evtsender", []byte(`{"message": "PO created succcessfully"}`)); err == nil {
+		t.Errorf("Decode of evtsender succeeded")
+	}
+}
+
+func TestSchema(t *testing.T) {
+	schema := struct {
+		Required []string `json:"required"`
+		Properties map[string]struct {
+			Enum []string `json:"enum"`
+		} `json:"properties"`
+	}{}
+	if err := json.Unmarshal(events.Schema(), &schema); err != nil {
+		t.Fatal(err)
+	}
+	if len(schema.Properties["type"].Enum) != len(events.Catalog) || !strings.Contains(strings.Join(schema.Required, ","), "entityId") {
+		t.Errorf("schema: %+v", schema)
+	}
+	if entities := schema.Properties["entityType"].Enum; len(entities) == 0 || entities[0] != "chaincode" {
+		t.Errorf("schema entities: %v", entities)
+	}
+}
@@ -0,0 +1,57 @@
+package events
+
+import (
+"encoding/json"
+)
+
+// ============================================================================================================================
+// Schema - the JSON Schema of the payload of the catalog events, for listeners that do not read Go. The type is one of the
+// catalog names, and a listener reading schema version 1 may ignore fields it does not know
+// ============================================================================================================================
+func Schema() []byte {
+	changeSchema := map[string]interface{}{
+		"type": "object",
+		"required": []string{"field", "from", "to"},
+		"properties": map[string]interface{}{
+			"field": map[string]string{"type": "string"},
+			"from": map[string]string{"description": "the value before the event, null for a created record"},
+			"to": map[string]string{"description": "the value after the event, null for a deleted record"},
+		},
+	}
+	schema := map[string]interface{}{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$id": "https://github.com/wipro-blockchain/TF-v1/events/v" + SchemaVersion,
+		"title": "Trade-finance chaincode event",
+		"type": "object",
+		"required": []string{"type", "schemaVersion", "entityType", "entityId", "actor", "txId", "timestamp", "changes", "message", "code"},
+		"properties": map[string]interface{}{
+			"type": map[string]interface{}{"enum": Names()},
+			"schemaVersion": map[string]interface{}{"const": SchemaVersion},
+			"entityType": map[string]interface{}{"enum": entities()},
+			"entityId": map[string]string{"type": "string"},
+			"status": map[string]string{"type": "string"},
+			"actor": map[string]string{"type": "string", "description": "MSP and common name of the submitter, e.g. Org1MSP:User1"},
+			"txId": map[string]string{"type": "string"},
+			"timestamp": map[string]string{"type": "string", "format": "date-time"},
+			"version": map[string]string{"type": "integer"},
+			"changes": map[string]interface{}{"type": "array", "items": changeSchema},
+			"data": map[string]string{"type": "object"},
+			"message": map[string]string{"type": "string"},
+			"code": map[string]interface{}{"const": "200"},
+		},
+	}
+	schemaAsBytes, _ := json.MarshalIndent(schema, "", "  ")
+	return schemaAsBytes
+}
+// ============================================================================================================================
+// entities - the entities of the catalog, each once in catalog order
+// ============================================================================================================================
+func entities() []string {
+	found := []string{}
+	for _, t := range Catalog {
+		if !contains(found, t.Entity) {
+			found = append(found, t.Entity)
+		}
+	}
+	return found
+}
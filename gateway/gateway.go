@@ -0,0 +1,161 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements.  See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership.  The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License.  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+
+package main
+
+import (
+"errors"
+"flag"
+"fmt"
+"io"
+"log"
+"net/http"
+"os"
+
+tfclient "github.com/wipro-blockchain/TF-v1/client"
+"github.com/wipro-blockchain/TF-v1/internal/gateway"
+"github.com/wipro-blockchain/TF-v1/internal/relay"
+"github.com/wipro-blockchain/TF-v1/internal/router"
+"github.com/wipro-blockchain/TF-v1/internal/simulator"
+)
+
+// statusRecorder keeps the status written to a response, for the request log
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// ============================================================================================================================
+// Main - serve the REST gateway on the peers of a connection profile, or on the in-process simulated network. Each caller
+// authenticates with its bearer token and its requests run as its own identity
+// ============================================================================================================================
+func main() {
+	addr := flag.String("addr", ":8080", "address to listen on")
+	profile := flag.String("profile", "", "connection profile of the peers to run on, instead of the simulated network")
+	callers := flag.String("callers", "", "JSON file of the callers: name, tokenSha256 and identity, mspId with cert and key on the peers, mspId and commonName on the simulated network")
+	insecure := flag.Bool("insecure", false, "serve the simulated network without callers, every request runs as its admin")
+	single := flag.Bool("single", false, "host the single TradeFinance chaincode instead of the four separate chaincodes")
+	balance := flag.String("balance", simulator.DefaultBalance, "opening balance of the buyer and seller accounts")
+	verbose := flag.Bool("v", false, "show what the chaincodes print while they run")
+	eventLog := flag.String("event-log", "", "append the event of every committed transaction to this file, for the relay")
+	flag.Parse()
+
+	if !*verbose {
+		router.Output = io.Discard
+	}
+	var g *gateway.Gateway
+	var err error
+	switch {
+	case *callers == "" && (*profile != "" || !*insecure):
+		err = errors.New("give the callers with -callers, or -insecure to serve the simulated network without authentication")
+	case *profile != "":
+		if *eventLog != "" {
+			err = errors.New("-event-log is written on the simulated network only, on the peers the relay and projector follow the channel with -profile")
+			break
+		}
+		g, err = onPeers(*profile, *callers)
+	default:
+		g, err = onSimulated(*single, *balance, *callers, *eventLog)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error starting the gateway: %s\n", err)
+		os.Exit(1)
+	}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		g.ServeHTTP(recorder, r)
+		log.Printf("%s %s %d", r.Method, r.URL.RequestURI(), recorder.status)
+	})
+	log.Printf("Trade-finance gateway on %s, the API is described at /openapi.json", *addr)
+	log.Fatal(http.ListenAndServe(*addr, handler))
+}
+// ============================================================================================================================
+// onPeers - a gateway on the peers of a connection profile, each caller submitting with its certificate and key
+// ============================================================================================================================
+func onPeers(profilePath string, callersPath string) (*gateway.Gateway, error) {
+	profile, err := tfclient.ReadProfile(profilePath)
+	if err != nil {
+		return nil, err
+	}
+	callers, err := gateway.ReadCallers(callersPath)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := profile.Dial()
+	if err != nil {
+		return nil, err
+	}
+	g := gateway.New(nil)
+	for _, c := range callers {
+		if c.MSPID == "" || c.Cert == "" || c.Key == "" {
+			return nil, fmt.Errorf("caller %s: on the peers a caller names its mspId, cert and key", c.Name)
+		}
+		ledger, err := gateway.NewPeer(conn, profile, tfclient.Identity{MSPID: c.MSPID, Cert: c.Cert, Key: c.Key})
+		if err != nil {
+			return nil, fmt.Errorf("caller %s: %s", c.Name, err.Error())
+		}
+		if err = g.AddCaller(c, ledger); err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
+// ============================================================================================================================
+// onSimulated - a gateway on a fresh simulated network, each caller submitting as its mspId and commonName. Without
+// callers every request runs as the admin of the chaincodes
+// ============================================================================================================================
+func onSimulated(single bool, balance string, callersPath string, eventLog string) (*gateway.Gateway, error) {
+	ledger, err := gateway.NewSimulated(single, balance)
+	if err != nil {
+		return nil, err
+	}
+	if eventLog != "" {
+		relay.Watch(ledger.Network, &relay.Log{Path: eventLog}, func(err error) {
+			log.Printf("Error writing the event log: %s", err)
+		})
+	}
+	g := gateway.New(ledger)
+	if callersPath == "" {
+		log.Printf("No -callers: every request runs unauthenticated as the admin of the simulated network")
+		return g, nil
+	}
+	callers, err := gateway.ReadCallers(callersPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range callers {
+		if c.MSPID == "" {
+			return nil, fmt.Errorf("caller %s: a caller names its mspId", c.Name)
+		}
+		callerLedger, err := ledger.As(c.MSPID, c.CommonName)
+		if err != nil {
+			return nil, err
+		}
+		if err = g.AddCaller(c, callerLedger); err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
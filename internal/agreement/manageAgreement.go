@@ -0,0 +1,2058 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements.  See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership.  The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License.  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package agreement
+
+import (
+"errors"
+"strconv"
+"strings"
+"encoding/json"
+
+"github.com/hyperledger/fabric-chaincode-go/shim"
+"github.com/wipro-blockchain/TF-v1/events"
+"github.com/wipro-blockchain/TF-v1/internal/router"
+)
+
+// ManageAgreement is the Agreement domain of the trade-finance chaincodes
+type ManageAgreement struct {
+	linker router.Linker						// reaches the other chaincodes, see router.Linker
+}
+
+var AgreementIndexStr = "_Agreementindex"				//name for the key/value that will store a list of all known Agreement
+var FraudListIndexStr = "_FraudListIndexStr"
+var agreementFields = []string{"agreementId", "transId", "agreement_status", "buyer_name", "seller_name", "shipper_name",
+	"bb_name", "sb_name", "agreementPortAuth_name", "agreementCU_date", "item_id", "item_name", "item_quantity", "total_value",
+	"delivery_date", "extraCharges", "shipper_fees", "document_name", "document_url", "tc_text", "buyer_sign", "buyerBank_sign",
+	"seller_sign", "sellerBank_sign", "industry", "goodsPrice"}		//create_agreement arguments by JSON name, in order
+var agreementUpdateFields = append(append([]string{}, agreementFields...), "version")	//update_agreement arguments by JSON name, in order
+
+type Agreement struct{							// Attributes of a Agreement 
+	AgreementID string `json:"agreementId"`	
+	TransID string `json:"transId"`
+	Agreement_status string `json:"agreement_status"`
+	BuyerName string `json:"buyer_name"`					
+	SellerName string `json:"seller_name"`
+	ShipperName string `json:"shipper_name"`
+	BB_name string `json:"bb_name"`
+	SB_name string `json:"sb_name"`	
+	PortAuthName string `json:"agreementPortAuth_name"`
+	AgreementCU_date string `json:"agreementCU_date"`
+	ItemId string `json:"item_id"`
+	Item_name string `json:"item_name"`
+	Item_quantity string `json:"item_quantity"`
+	Total_Value string `json:"total_value"`
+	Delivery_date string `json:"delivery_date"`
+	ExtraCharges string `json:"extraCharges"`
+	Shipper_fees string `json:"shipper_fees"`
+	DocumentName string `json:"document_name"`
+	DocumentURL string `json:"document_url"`
+	TC_Text string `json:"tc_text"`
+	Buyer_sign string `json:"buyer_sign"`
+	BuyerBank_sign string `json:"buyerBank_sign"`
+	Seller_sign string `json:"seller_sign"`
+	SellerBank_sign string `json:"sellerBank_sign"`
+	Industry string `json:"industry"`
+	GoodsPrice string `json:"goodsPrice"`
+	Clearance_status string `json:"clearance_status" metadata:",optional"`
+	Clearance_shipment string `json:"clearance_shipment" metadata:",optional"`
+	Shipping_status string `json:"shipping_status" metadata:",optional"`
+	Version int `json:"version" metadata:",optional"`						// incremented by every write
+	LastModifiedTxID string `json:"lastModifiedTxId" metadata:",optional"`	// the transaction of the last write
+	CreatedAt string `json:"createdAt" metadata:",optional"`					// the ledger time of the create, see router.TxStamp
+	CreatedBy string `json:"createdBy" metadata:",optional"`					// the caller of the create
+	UpdatedAt string `json:"updatedAt" metadata:",optional"`					// the ledger time of the last write
+	UpdatedBy string `json:"updatedBy" metadata:",optional"`					// the caller of the last write
+}
+type LiquidatedDamages struct{					// Liquidated damages owed by the shipper for late delivery
+	AgreementID string `json:"agreementId"`
+	RatePerDay string `json:"rate_per_day"`			// percent of total_value per day late
+	CapPercent string `json:"cap_percent"`				// maximum percent of total_value
+	GraceDays string `json:"grace_days"`				// days late before damages apply
+}
+type ShippedBalance struct{						// Shipped versus ordered quantity of every line of an Agreement
+	AgreementID string `json:"agreementId"`
+	Lines []ShippedLine `json:"lines"`
+	Shipments []string `json:"shipments"`
+}
+type ShippedLine struct{
+	ItemId string `json:"item_id"`
+	Ordered string `json:"ordered"`
+	Shipped string `json:"shipped"`
+	Remaining string `json:"remaining"`
+}
+type ShipmentItem struct{
+	ItemId string `json:"item_id"`
+	Quantity string `json:"quantity"`
+}
+type Fraud_list struct{
+	FraudID string `json:"fraudId"`	
+	FraudName string `json:"fraudName"`
+}
+// ============================================================================================================================
+// New - Agreement management, reaching the other chaincodes through linker
+// ============================================================================================================================
+func New(linker router.Linker) *ManageAgreement {
+	return &ManageAgreement{linker: linker}
+}
+// ============================================================================================================================
+// agreementKey - key under which an Agreement is stored, apart from the records of the other types and the indexes
+// ============================================================================================================================
+func agreementKey(agreementId string) string {
+	return "Agreement_" + agreementId
+}
+// ============================================================================================================================
+// fraudKey - key under which an entry of the fraud list is stored
+// ============================================================================================================================
+func fraudKey(fraudId string) string {
+	return "Fraud_" + fraudId
+}
+// ============================================================================================================================
+// Init - reset all the things
+// ============================================================================================================================
+func (t *ManageAgreement) Init(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+	var msg string
+	var err error
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting \"Intial_Value\" as an argument\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	// Initialize the chaincode
+	msg = args[0]
+	router.Println("ManageAgreement chaincode is deployed successfully.");
+	
+	// Write the state to the ledger
+	err = stub.PutState("abc", []byte(msg))				//making a test var "abc", I find it handy to read/write to it right away to test the network
+	if err != nil {
+		return nil, err
+	}
+	
+	var empty []string
+	jsonAsBytes, _ := json.Marshal(empty)								//marshal an emtpy array of strings to clear the index
+	err = stub.PutState(AgreementIndexStr, jsonAsBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	var frauds []string
+	fraudListAsBytes, _ := json.Marshal(frauds)								//marshal an emtpy array of strings to clear the index
+	err = stub.PutState(FraudListIndexStr, fraudListAsBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	err = router.Emit(stub, events.Event{Type: events.ChaincodeDeployed, EntityID: "ManageAgreement",
+		Message: "ManageAgreement chaincode is deployed successfully."}, nil, nil)
+	if err != nil {
+		return nil, err
+	} 
+	return nil, nil
+}
+// ============================================================================================================================
+// KeyMoves - the records of Agreement management to move from their bare ID to their namespaced key, see router.Migrator
+// ============================================================================================================================
+func (t *ManageAgreement) KeyMoves() []router.KeyMove {
+	return []router.KeyMove{{Index: AgreementIndexStr, Key: agreementKey}, {Index: FraudListIndexStr, Key: fraudKey}}
+}
+// ============================================================================================================================
+// Invokes - the invoke functions of Agreement management, by function name
+// ============================================================================================================================
+func (t *ManageAgreement) Invokes() map[string]router.Handler {
+	return map[string]router.Handler{
+		"create_agreement": router.Named(t.create_agreement, "create_agreement", agreementFields, nil),					//create a new Agreement
+		"delete_agreement": t.delete_agreement,					// delete an Agreement
+		"update_agreement": router.Named(t.update_agreement, "update_agreement", agreementUpdateFields, t.getAgreement_byID),					//update an Agreement
+		"update_fraud_list": t.update_fraud_list,					//update an Agreement
+		"update_clearance_status": t.update_clearance_status,					//mirror the port clearance status of a shipment
+		"set_liquidated_damages": t.set_liquidated_damages,					//set the late delivery terms of an Agreement
+		"record_shipped_quantity": t.record_shipped_quantity,					//book a shipment against the ordered quantity
+		"release_shipped_quantity": t.release_shipped_quantity,					//give a deleted shipment back to the ordered quantity
+	}
+}
+// ============================================================================================================================
+// Queries - the query functions of Agreement management, by function name
+// ============================================================================================================================
+func (t *ManageAgreement) Queries() map[string]router.Handler {
+	return map[string]router.Handler{
+		"getAgreement_byID": t.getAgreement_byID,					//Read a Agreement by AgreementID
+		"getAgreement_byBuyer": t.getAgreement_byBuyer,					//Read a Agreement by Buyer
+		"getAgreement_bySeller": t.getAgreement_bySeller,					//Read a Agreement by Seller
+		"get_AllAgreement": t.get_AllAgreement,					//Read all Agreements
+		"getAgreement_byShipper": t.getAgreement_byShipper,					//Read a Agreement by Shipper
+		"getAgreement_byBuyerBank": t.getAgreement_byBuyerBank,					//Read a Agreement by Buyer bank
+		"getAgreement_bySellerBank": t.getAgreement_bySellerBank,					//Read a Agreement by Seller bank
+		"getAgreement_byPortAuthority": t.getAgreement_byPortAuthority,					//Read a Agreement by Port Authority
+		"get_fraud_list": t.get_fraud_list,					//Read a Agreement by Port Authority
+		"getApprovalStatus": t.getApprovalStatus,					//Read a Agreement by Port Authority
+		"get_fraud_details": t.get_fraud_details_byName,					//Read a Agreement by Port Authority
+		"get_liquidated_damages": t.get_liquidated_damages,					//Read the late delivery terms of an Agreement
+		"get_shipped_balance": t.get_shipped_balance,					//Read shipped versus ordered quantities
+		"get_trade_record": t.get_trade_record,					//Read an Agreement with its PO, payments and shipments
+	}
+}
+// ============================================================================================================================
+// getAgreement_byID - get Agreement details for a specific AgreementID from chaincode state
+// ============================================================================================================================
+func (t *ManageAgreement) getAgreement_byID(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var agreementId string
+	var err error
+	router.Println("start getAgreement_byID")
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting \"AgreementID\" as an argument\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	// set agreementId
+	agreementId = args[0]
+	valAsbytes, err := stub.GetState(agreementKey(agreementId))									//get the agreementId from chaincode state
+	if err != nil {
+		errMsg := "{ \"message\" : " + router.JSONString(agreementId + " not Found.") + ", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	router.Print("valAsbytes : ")
+	router.Println(valAsbytes)
+	router.Println("end getAgreement_byID")
+	return valAsbytes, nil													//send it onward
+}
+// ============================================================================================================================
+//  getAgreement_byBuyer - get Agreement details by buyer's name from chaincode state
+// ============================================================================================================================
+func (t *ManageAgreement) getAgreement_byBuyer(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var jsonResp, buyer_name, errResp string
+	var agreementIndex []string
+	var valIndex Agreement
+	router.Println("start getAgreement_byBuyer")
+	var err error
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting \"Buyer_Name\" as an argument\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	// set buyer's name
+	buyer_name = args[0]
+	router.Println("buyer_name : " + buyer_name)
+	agreementAsBytes, err := stub.GetState(AgreementIndexStr)
+	if err != nil {
+		return nil, errors.New("Failed to get Agreement index string")
+	}
+	router.Print("agreementAsBytes : ")
+	router.Println(agreementAsBytes)
+	json.Unmarshal(agreementAsBytes, &agreementIndex)								//un stringify it aka JSON.parse()
+	router.Print("agreementIndex : ")
+	router.Println(agreementIndex)
+	router.Println("len(agreementIndex) : ")
+	router.Println(len(agreementIndex))
+	jsonResp = "{"
+	for i,val := range agreementIndex{
+		router.Println(strconv.Itoa(i) + " - looking at " + val + " for getAgreement_byBuyer")
+		valueAsBytes, err := stub.GetState(agreementKey(val))
+		if err != nil {
+			errResp = "{\"Error\":\"Failed to get state for " + val + "\"}"
+			return nil, errors.New(errResp)
+		}
+		router.Print("valueAsBytes : ")
+		router.Println(valueAsBytes)
+		json.Unmarshal(valueAsBytes, &valIndex)
+		router.Print("valIndex: ")
+		router.Print(valIndex)
+		if valIndex.BuyerName == buyer_name{
+			router.Println("Buyer found")
+			jsonResp = jsonResp + "\""+ val + "\":" + string(valueAsBytes[:])
+			router.Println("jsonResp inside if")
+			router.Println(jsonResp)
+			if i < len(agreementIndex)-1 {
+				jsonResp = jsonResp + ","
+			}
+		} else{
+			errMsg := "{ \"message\" : " + router.JSONString(buyer_name + " Not Found.") + ", \"code\" : \"503\"}"
+			err = stub.SetEvent("errEvent", []byte(errMsg))
+			if err != nil {
+				return nil, err
+			} 
+			return nil, nil
+		}
+	}
+	jsonResp = jsonResp + "}"
+	router.Println("jsonResp : " + jsonResp)
+	router.Print("jsonResp in bytes : ")
+	router.Println([]byte(jsonResp))
+	router.Println("end getAgreement_byBuyer")
+	return []byte(jsonResp), nil											//send it onward
+}
+
+// ============================================================================================================================
+//  getApprovalStatus - get approval details of an Agreement for a specific user from chaincode state
+// ============================================================================================================================
+func (t *ManageAgreement) getApprovalStatus(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var user , result string
+	var agreementIndex Agreement
+	router.Println("Fetching Agreements")
+	var err error
+	if len(args) != 2 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting \"User\" and \" agreementID\" as an argument\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	// set user and agreementID
+	user = args[0]
+	agreementId := args[1]
+	agreementAsBytes, err := stub.GetState(agreementKey(agreementId))
+	if err != nil {
+		errMsg := "{ \"message\" : " + router.JSONString(agreementId + " not Found.") + ", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	router.Print("agreementAsBytes : ")
+	router.Println(agreementAsBytes)
+	json.Unmarshal(agreementAsBytes, &agreementIndex)								//un stringify it aka JSON.parse()
+	if agreementIndex.SellerName == user{
+		router.Println("Seller found")
+		result = "{" + "\""+ "agreementId" + "\": \"" + agreementId + "\", \""+ "Seller_sign" + "\":\"" + string(agreementIndex.Seller_sign) + "\"}"
+		router.Println("result: "+ result)
+		if string(agreementIndex.Seller_sign) == "false"{
+			// call update agreement and set seller_sign "true"
+		}
+	}else if agreementIndex.BuyerName == user{
+		router.Println("Buyer found")
+		router.Print(string(agreementIndex.Agreement_status));
+		result = "{" + "\""+ "agreementId" + "\": \"" + agreementId + "\", \""+ "Buyer_sign" + "\":\"" + string(agreementIndex.Buyer_sign) + "\"}"
+		router.Println("result: "+ result)
+	}else if agreementIndex.BB_name == user{
+		router.Println("Buyer Bank found")
+		result = "{" + "\""+ "agreementId" + "\": \"" + agreementId + "\", \""+ "BuyerBank_sign" + "\":\"" + string(agreementIndex.BuyerBank_sign) + "\"}"
+		router.Println("result: "+ result)
+		if string(agreementIndex.Seller_sign) == "true"{
+			// check for conditions and call update agreement and set buyer bank sign "true"
+		}
+	}else if agreementIndex.SB_name == user{
+		router.Println("Seller Bank found")
+		result = "{" + "\""+ "agreementId" + "\": \"" + agreementId + "\", \""+ "SellerBank_sign" + "\":\"" + string(agreementIndex.SellerBank_sign) + "\"}"
+		router.Println("result: "+ result)
+	}else{
+		errMsg := "{ \"message\" : " + router.JSONString(user + " Not Found.") + ", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	router.Println("Fetched Approval Status")
+	return []byte(result), nil											//send it onward
+}
+
+
+// ============================================================================================================================
+//  getAgreement_bySeller - get Agreement details for a specific Seller from chaincode state
+// ============================================================================================================================
+func (t *ManageAgreement) getAgreement_bySeller(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var jsonResp, seller_name, errResp string
+	var agreementIndex []string
+	var valIndex Agreement
+	router.Println("start getAgreement_bySeller")
+	var err error
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting \"Seller_Name\" as an argument\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	// set seller name
+	seller_name = args[0]
+	router.Println("seller_name: " + seller_name)
+	agreementAsBytes, err := stub.GetState(AgreementIndexStr)
+	if err != nil {
+		return nil, errors.New("Failed to get Agreement index")
+	}
+	router.Print("agreementAsBytes : ")
+	router.Println(agreementAsBytes)
+	json.Unmarshal(agreementAsBytes, &agreementIndex)								//un stringify it aka JSON.parse()
+	router.Print("agreementIndex : ")
+	router.Println(agreementIndex)
+	router.Println("len(agreementIndex) : ")
+	router.Println(len(agreementIndex))
+	jsonResp = "{"
+	for i,val := range agreementIndex{
+		router.Println(strconv.Itoa(i) + " - looking at " + val + " for getting sellerName")
+		valueAsBytes, err := stub.GetState(agreementKey(val))
+		if err != nil {
+			errResp = "{\"Error\":\"Failed to get state for " + val + "\"}"
+			return nil, errors.New(errResp)
+		}
+		router.Print("valueAsBytes : ")
+		router.Println(valueAsBytes)
+		json.Unmarshal(valueAsBytes, &valIndex)
+		router.Print("valIndex: ")
+		router.Print(valIndex)
+		if valIndex.SellerName == seller_name{
+			router.Println("Seller found")
+			jsonResp = jsonResp + "\""+ val + "\":" + string(valueAsBytes[:])
+			router.Println("jsonResp inside if")
+			router.Println(jsonResp)
+			if i < len(agreementIndex)-1 {
+				jsonResp = jsonResp + ","
+			}
+		}else{
+			errMsg := "{ \"message\" : " + router.JSONString(seller_name + " Not Found.") + ", \"code\" : \"503\"}"
+			err = stub.SetEvent("errEvent", []byte(errMsg))
+			if err != nil {
+				return nil, err
+			} 
+			return nil, nil
+		}
+		
+	}
+	
+	jsonResp = jsonResp + "}"
+	router.Println("jsonResp : " + jsonResp)
+	router.Print("jsonResp in bytes : ")
+	router.Println([]byte(jsonResp))
+	router.Println("end getAgreement_bySeller")
+	return []byte(jsonResp), nil											//send it onward
+}
+// ============================================================================================================================
+//  get_AllAgreement- get details of all Agreement from chaincode state
+// ============================================================================================================================
+func (t *ManageAgreement) get_AllAgreement(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var jsonResp, errResp string
+	var agreementIndex []string
+	router.Println("start get_AllAgreement")
+	var err error
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting \" \" as an argument\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	agreementAsBytes, err := stub.GetState(AgreementIndexStr)
+	if err != nil {
+		return nil, errors.New("Failed to get Agreement index")
+	}
+	router.Print("agreementAsBytes : ")
+	router.Println(agreementAsBytes)
+	json.Unmarshal(agreementAsBytes, &agreementIndex)								//un stringify it aka JSON.parse()
+	router.Print("agreementIndex : ")
+	router.Println(agreementIndex)
+	jsonResp = "{"
+	for i,val := range agreementIndex{
+		router.Println(strconv.Itoa(i) + " - looking at " + val + " for all Agreement")
+		valueAsBytes, err := stub.GetState(agreementKey(val))
+		if err != nil {
+			errResp = "{\"Error\":\"Failed to get state for " + val + "\"}"
+			return nil, errors.New(errResp)
+		}
+		router.Print("valueAsBytes : ")
+		router.Println(valueAsBytes)
+		jsonResp = jsonResp + "\""+ val + "\":" + string(valueAsBytes[:])
+		if i < len(agreementIndex)-1 {
+			jsonResp = jsonResp + ","
+		}
+	}
+	router.Println("len(agreementIndex) : ")
+	router.Println(len(agreementIndex))
+	jsonResp = jsonResp + "}"
+	router.Println("jsonResp : " + jsonResp)
+	router.Print("jsonResp in bytes : ")
+	router.Println([]byte(jsonResp))
+	router.Println("end get_AllAgreement")
+	return []byte(jsonResp), nil
+											//send it onward
+}
+
+// ============================================================================================================================
+//  getAgreement_byShipper - get Agreement details for a specific Shipper from chaincode state
+// ============================================================================================================================
+func (t *ManageAgreement) getAgreement_byShipper(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var jsonResp, shipper_name, errResp string
+	var agreementIndex []string
+	var valIndex Agreement
+	router.Println("start getAgreement_byShipper")
+	var err error
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting \"Shipper_Name\" as an argument\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	// set Shipper name
+	shipper_name = args[0]
+	router.Println("shipper_name: " + shipper_name)
+	agreementAsBytes, err := stub.GetState(AgreementIndexStr)
+	if err != nil {
+		return nil, errors.New("Failed to get Agreement index")
+	}
+	router.Print("agreementAsBytes : ")
+	router.Println(agreementAsBytes)
+	json.Unmarshal(agreementAsBytes, &agreementIndex)								//un stringify it aka JSON.parse()
+	router.Print("agreementIndex : ")
+	router.Println(agreementIndex)
+	router.Println("len(agreementIndex) : ")
+	router.Println(len(agreementIndex))
+	jsonResp = "{"
+	for i,val := range agreementIndex{
+		router.Println(strconv.Itoa(i) + " - looking at " + val + " for getting sellerName")
+		valueAsBytes, err := stub.GetState(agreementKey(val))
+		if err != nil {
+			errResp = "{\"Error\":\"Failed to get state for " + val + "\"}"
+			return nil, errors.New(errResp)
+		}
+		router.Print("valueAsBytes : ")
+		router.Println(valueAsBytes)
+		json.Unmarshal(valueAsBytes, &valIndex)
+		router.Print("valIndex: ")
+		router.Print(valIndex)
+		if valIndex.ShipperName == shipper_name{
+			router.Println("Shipper found")
+			jsonResp = jsonResp + "\""+ val + "\":" + string(valueAsBytes[:])
+			router.Println("jsonResp inside if")
+			router.Println(jsonResp)
+			if i < len(agreementIndex)-1 {
+				jsonResp = jsonResp + ","
+			}
+		}else{
+			errMsg := "{ \"message\" : " + router.JSONString(shipper_name + " Not Found.") + ", \"code\" : \"503\"}"
+			err = stub.SetEvent("errEvent", []byte(errMsg))
+			if err != nil {
+				return nil, err
+			} 
+			return nil, nil
+		}
+		
+	}
+	
+	jsonResp = jsonResp + "}"
+	router.Println("jsonResp : " + jsonResp)
+	router.Print("jsonResp in bytes : ")
+	router.Println([]byte(jsonResp))
+	router.Println("end getAgreement_byShipper")
+	return []byte(jsonResp), nil											//send it onward
+}
+
+// ============================================================================================================================
+//  getAgreement_byBuyerBank - get Agreement details for a specific Buyer bank from chaincode state
+// ============================================================================================================================
+func (t *ManageAgreement) getAgreement_byBuyerBank(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var jsonResp, bb_name, errResp string
+	var agreementIndex []string
+	var valIndex Agreement
+	router.Println("start getAgreement_byBuyerBank")
+	var err error
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting \"Buyer_Bank_Name\" as an argument\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	// set Buyer Bank
+	bb_name = args[0]
+	router.Println("bb_name: " + bb_name)
+	agreementAsBytes, err := stub.GetState(AgreementIndexStr)
+	if err != nil {
+		return nil, errors.New("Failed to get Agreement index")
+	}
+	router.Print("agreementAsBytes : ")
+	router.Println(agreementAsBytes)
+	json.Unmarshal(agreementAsBytes, &agreementIndex)								//un stringify it aka JSON.parse()
+	router.Print("agreementIndex : ")
+	router.Println(agreementIndex)
+	router.Println("len(agreementIndex) : ")
+	router.Println(len(agreementIndex))
+	jsonResp = "{"
+	for i,val := range agreementIndex{
+		router.Println(strconv.Itoa(i) + " - looking at " + val + " for getting sellerName")
+		valueAsBytes, err := stub.GetState(agreementKey(val))
+		if err != nil {
+			errResp = "{\"Error\":\"Failed to get state for " + val + "\"}"
+			return nil, errors.New(errResp)
+		}
+		router.Print("valueAsBytes : ")
+		router.Println(valueAsBytes)
+		json.Unmarshal(valueAsBytes, &valIndex)
+		router.Print("valIndex: ")
+		router.Print(valIndex)
+		if valIndex.BB_name == bb_name{
+			router.Println("Buyer Bank found")
+			jsonResp = jsonResp + "\""+ val + "\":" + string(valueAsBytes[:])
+			router.Println("jsonResp inside if")
+			router.Println(jsonResp)
+			if i < len(agreementIndex)-1 {
+				jsonResp = jsonResp + ","
+			}
+		}else{
+			errMsg := "{ \"message\" : " + router.JSONString(bb_name + " Not Found.") + ", \"code\" : \"503\"}"
+			err = stub.SetEvent("errEvent", []byte(errMsg))
+			if err != nil {
+				return nil, err
+			} 
+			return nil, nil
+		}
+		
+	}
+	
+	jsonResp = jsonResp + "}"
+	router.Println("jsonResp : " + jsonResp)
+	router.Print("jsonResp in bytes : ")
+	router.Println([]byte(jsonResp))
+	router.Println("end getAgreement_byBuyerBank")
+	return []byte(jsonResp), nil											//send it onward
+}
+
+// ============================================================================================================================
+//  getAgreement_bySellerBank - get Agreement details for a specific Seller bank from chaincode state
+// ============================================================================================================================
+func (t *ManageAgreement) getAgreement_bySellerBank(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var jsonResp, sb_name, errResp string
+	var agreementIndex []string
+	var valIndex Agreement
+	router.Println("start getAgreement_bySellerBank")
+	var err error
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting \"Seller_Bank_Name\" as an argument\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	// set seller bank 
+	sb_name = args[0]
+	router.Println("sb_name: " + sb_name)
+	agreementAsBytes, err := stub.GetState(AgreementIndexStr)
+	if err != nil {
+		return nil, errors.New("Failed to get Agreement index")
+	}
+	router.Print("agreementAsBytes : ")
+	router.Println(agreementAsBytes)
+	json.Unmarshal(agreementAsBytes, &agreementIndex)								//un stringify it aka JSON.parse()
+	router.Print("agreementIndex : ")
+	router.Println(agreementIndex)
+	router.Println("len(agreementIndex) : ")
+	router.Println(len(agreementIndex))
+	jsonResp = "{"
+	for i,val := range agreementIndex{
+		router.Println(strconv.Itoa(i) + " - looking at " + val + " for getting sellerName")
+		valueAsBytes, err := stub.GetState(agreementKey(val))
+		if err != nil {
+			errResp = "{\"Error\":\"Failed to get state for " + val + "\"}"
+			return nil, errors.New(errResp)
+		}
+		router.Print("valueAsBytes : ")
+		router.Println(valueAsBytes)
+		json.Unmarshal(valueAsBytes, &valIndex)
+		router.Print("valIndex: ")
+		router.Print(valIndex)
+		if valIndex.SB_name == sb_name{
+			router.Println("Seller found")
+			jsonResp = jsonResp + "\""+ val + "\":" + string(valueAsBytes[:])
+			router.Println("jsonResp inside if")
+			router.Println(jsonResp)
+			if i < len(agreementIndex)-1 {
+				jsonResp = jsonResp + ","
+			}
+		}else{
+			errMsg := "{ \"message\" : " + router.JSONString(sb_name + " Not Found.") + ", \"code\" : \"503\"}"
+			err = stub.SetEvent("errEvent", []byte(errMsg))
+			if err != nil {
+				return nil, err
+			} 
+			return nil, nil
+		}
+		
+	}
+	
+	jsonResp = jsonResp + "}"
+	router.Println("jsonResp : " + jsonResp)
+	router.Print("jsonResp in bytes : ")
+	router.Println([]byte(jsonResp))
+	router.Println("end getAgreement_bySellerBank")
+	return []byte(jsonResp), nil											//send it onward
+}
+
+// ============================================================================================================================
+//  getAgreement_byPortAuthority - get Agreement details for a specific Port Authority from chaincode state
+// ============================================================================================================================
+func (t *ManageAgreement) getAgreement_byPortAuthority(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var jsonResp, agreementPortAuth_name, errResp string
+	var agreementIndex []string
+	var valIndex Agreement
+	router.Println("start getAgreement_byPortAuthority")
+	var err error
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting \"Port_Authority_Name\" as an argument\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	// set Port authority name
+	agreementPortAuth_name = args[0]
+	router.Println("agreementPortAuth_name: " + agreementPortAuth_name)
+	agreementAsBytes, err := stub.GetState(AgreementIndexStr)
+	if err != nil {
+		return nil, errors.New("Failed to get Agreement index")
+	}
+	router.Print("agreementAsBytes : ")
+	router.Println(agreementAsBytes)
+	json.Unmarshal(agreementAsBytes, &agreementIndex)								//un stringify it aka JSON.parse()
+	router.Print("agreementIndex : ")
+	router.Println(agreementIndex)
+	router.Println("len(agreementIndex) : ")
+	router.Println(len(agreementIndex))
+	jsonResp = "{"
+	for i,val := range agreementIndex{
+		router.Println(strconv.Itoa(i) + " - looking at " + val + " for getting sellerName")
+		valueAsBytes, err := stub.GetState(agreementKey(val))
+		if err != nil {
+			errResp = "{\"Error\":\"Failed to get state for " + val + "\"}"
+			return nil, errors.New(errResp)
+		}
+		router.Print("valueAsBytes : ")
+		router.Println(valueAsBytes)
+		json.Unmarshal(valueAsBytes, &valIndex)
+		router.Print("valIndex: ")
+		router.Print(valIndex)
+		if valIndex.PortAuthName == agreementPortAuth_name{
+			router.Println("Seller found")
+			jsonResp = jsonResp + "\""+ val + "\":" + string(valueAsBytes[:])
+			router.Println("jsonResp inside if")
+			router.Println(jsonResp)
+			if i < len(agreementIndex)-1 {
+				jsonResp = jsonResp + ","
+			}
+		} else{
+			errMsg := "{ \"message\" : " + router.JSONString(agreementPortAuth_name + " Not Found.") + ", \"code\" : \"503\"}"
+			err = stub.SetEvent("errEvent", []byte(errMsg))
+			if err != nil {
+				return nil, err
+			} 
+			return nil, nil
+		}
+		
+	}
+	
+	jsonResp = jsonResp + "}"
+	router.Println("jsonResp : " + jsonResp)
+	router.Print("jsonResp in bytes : ")
+	router.Println([]byte(jsonResp))
+	router.Println("end getAgreement_byPortAuthority")
+	return []byte(jsonResp), nil											//send it onward
+}
+// ============================================================================================================================
+//  get_fraud_details_byName - get Fraud details for the fraud's name given as the only argument
+// ============================================================================================================================
+func (t *ManageAgreement) get_fraud_details_byName(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting \"Fraud_Name\" as an argument\", \"code\" : \"503\"}"
+		err := stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	return t.get_fraud_details(stub, args[0])
+}
+// ============================================================================================================================
+//  get_fraud_details - get Fraud details by fraud's name from chaincode state
+// ============================================================================================================================
+func (t *ManageAgreement) get_fraud_details(stub shim.ChaincodeStubInterface, args string) ([]byte, error) {
+	var jsonResp, fraud_name, errResp string
+	var fraudListIndex []string
+	var valIndex Fraud_list
+	router.Println("Fetching Fraud details.")
+	var err error
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting \"Fraud_Name\" as an argument\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	// set fraud's name
+	fraud_name = args
+	router.Println("fraud_name : " + fraud_name)
+	fraudListAsBytes, err := stub.GetState(FraudListIndexStr)
+	if err != nil {
+		return nil, errors.New("Failed to get Fraud List index string")
+	}
+	router.Print("fraudListAsBytes : ")
+	router.Println(fraudListAsBytes)
+	json.Unmarshal(fraudListAsBytes, &fraudListIndex)								//un stringify it aka JSON.parse()
+	router.Print("fraudListIndex : ")
+	router.Println(fraudListIndex)
+	router.Println("len(fraudListIndex) : ")
+	router.Println(len(fraudListIndex))
+	jsonResp = "{"
+	for i,val := range fraudListIndex{
+		router.Println(strconv.Itoa(i) + " - looking at " + val + " for get_fraud_details()")
+		valueAsBytes, err := stub.GetState(fraudKey(val))
+		if err != nil {
+			errResp = "{\"Error\":\"Failed to get state for " + val + "\"}"
+			return nil, errors.New(errResp)
+		}
+		router.Print("valueAsBytes : ")
+		router.Println(valueAsBytes)
+		json.Unmarshal(valueAsBytes, &valIndex)
+		router.Print("valIndex: ")
+		router.Print(valIndex)
+		if valIndex.FraudName == fraud_name{
+			router.Println("Fraud Name found")
+			jsonResp = jsonResp + "\""+ val + "\":" + string(valueAsBytes[:])
+			if i < len(fraudListIndex)-1 {
+				jsonResp = jsonResp + ","
+			}
+		} else{
+			errMsg := "{ \"message\" : " + router.JSONString(fraud_name + " Not Found.") + ", \"code\" : \"503\"}"
+			err = stub.SetEvent("errEvent", []byte(errMsg))
+			if err != nil {
+				return nil, err
+			} 
+			return nil, nil
+		}
+	}
+	jsonResp = jsonResp + "}"
+	router.Println("jsonResp : " + jsonResp)
+	router.Print("jsonResp in bytes : ")
+	router.Println([]byte(jsonResp))
+	router.Println("Fetched Fraud details.")
+	return []byte(jsonResp), nil											//send it onward
+}
+// ============================================================================================================================
+//  get_fraud_list - get Fraud list from chaincode state
+// ============================================================================================================================
+func (t *ManageAgreement) get_fraud_list(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var jsonResp, errResp string
+	var fraudListIndex []string
+	router.Println("Fetching Fraud list.")
+	var err error
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting \" \" as an argument\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	fraudListAsBytes, err := stub.GetState(FraudListIndexStr)
+	if err != nil {
+		return nil, errors.New("Failed to get Fraud List index")
+	}
+	router.Print("fraudListAsBytes : ")
+	router.Println(fraudListAsBytes)
+	json.Unmarshal(fraudListAsBytes, &fraudListIndex)								//un stringify it aka JSON.parse()
+	router.Print("fraudListIndex : ")
+	router.Println(fraudListIndex)
+	jsonResp = "{"
+	for i,val := range fraudListIndex{
+		router.Println(strconv.Itoa(i) + " - looking at " + val + " for Fetching Fraud List")
+		valueAsBytes, err := stub.GetState(fraudKey(val))
+		if err != nil {
+			errResp = "{\"Error\":\"Failed to get state for " + val + "\"}"
+			return nil, errors.New(errResp)
+		}
+		router.Print("valueAsBytes : ")
+		router.Println(valueAsBytes)
+		jsonResp = jsonResp + "\""+ val + "\":" + string(valueAsBytes[:])
+		if i < len(fraudListIndex)-1 {
+			jsonResp = jsonResp + ","
+		}
+	}
+	router.Println("len(fraudListIndex) : ")
+	router.Println(len(fraudListIndex))
+	jsonResp = jsonResp + "}"
+	router.Println("jsonResp : " + jsonResp)
+	router.Print("jsonResp in bytes : ")
+	router.Println([]byte(jsonResp))
+	router.Println("Fetched Fraud list.")
+	return []byte(jsonResp), nil
+}
+// ============================================================================================================================
+// Delete - remove a Agreement from chain
+// ============================================================================================================================
+func (t *ManageAgreement) delete_agreement(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting \"agreementID\" as an argument.\", \"code\" : \"503\"}"
+		err := stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	// set agreementId
+	agreementId := args[0]
+	recordAsBytes, err := stub.GetState(agreementKey(agreementId))
+	if err != nil {
+		errMsg := "{ \"message\" : " + router.JSONString("Failed to get state for " + agreementId) + ", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	err = stub.DelState(agreementKey(agreementId))													//remove the Agreement from chaincode
+	if err != nil {
+		errMsg := "{ \"message\" : \"Failed to delete state\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+
+	//get the Agreement index
+	agreementAsBytes, err := stub.GetState(AgreementIndexStr)
+	if err != nil {
+		return nil, errors.New("Failed to get Agreement index")
+	}
+	router.Println("agreementAsBytes in delete agreement")
+	router.Println(agreementAsBytes);
+	var agreementIndex []string
+	json.Unmarshal(agreementAsBytes, &agreementIndex)								//un stringify it aka JSON.parse()
+	router.Println("agreementIndex in delete agreement")
+	router.Println(agreementIndex);
+	//remove agreement from index
+	for i,val := range agreementIndex{
+		router.Println(strconv.Itoa(i) + " - looking at " + val + " for " + agreementId)
+		if val == agreementId{															//find the correct Agreement
+			router.Println("found Agreement with matching agreementId")
+			agreementIndex = append(agreementIndex[:i], agreementIndex[i+1:]...)			//remove it
+			for x:= range agreementIndex{											//debug prints...
+				router.Println(strconv.Itoa(x) + " - " + agreementIndex[x])
+			}
+			break
+		}
+	}
+	jsonAsBytes, _ := json.Marshal(agreementIndex)									//save new index
+	err = stub.PutState(AgreementIndexStr, jsonAsBytes)
+	err = router.Emit(stub, events.Event{Type: events.AgreementDeleted, EntityID: agreementId, Message: "Agreement deleted succcessfully"},
+		recordAsBytes, nil)
+	if err != nil {
+		return nil, err
+	} 
+	return nil, nil
+}
+// ============================================================================================================================
+// Write - update Agreement into chaincode state
+// ============================================================================================================================
+func (t *ManageAgreement) update_agreement(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var jsonResp string
+	var err error
+	router.Println("start update_agreement")
+	args, expectedVersion := router.ExpectedVersion(args, 26)
+	if len(args) != 26{
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting 26 arguments, or 27 with the expected version.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	// set agreementId
+	agreementId := args[0]
+	agreementAsBytes, err := stub.GetState(agreementKey(agreementId))									//get the Agreement for the specified agreementId from chaincode state
+	if err != nil {
+		jsonResp = "{\"Error\":\"Failed to get state for " + agreementId + "\"}"
+		return nil, errors.New(jsonResp)
+	}
+	router.Print("agreementAsBytes in update agreement")
+	router.Println(agreementAsBytes);
+	res := Agreement{}
+	json.Unmarshal(agreementAsBytes, &res)
+	previous := res
+
+	if res.AgreementID == agreementId{
+		router.Println("Agreement found with agreementId : " + agreementId)
+		router.Println(res);
+		err = router.CheckVersion(agreementId, res.Version, expectedVersion)
+		if err != nil {
+			err = router.VersionEvent(stub, err)
+			if err != nil {
+				return nil, err
+			}
+			return nil, nil
+		}
+		if res.TransID != args[1] || res.BuyerName != args[3] || res.SellerName != args[4] {
+			err = t.checkLinkedPO(stub, args[1], args[3], args[4])
+			if err != nil {
+				errMsg := "{ \"Agreement ID\" : " + router.JSONString(agreementId) + ", \"message\" : " + router.JSONString(err.Error() + ".") + ", \"code\" : \"503\"}"
+				err = stub.SetEvent("errEvent", []byte(errMsg))
+				if err != nil {
+					return nil, err
+				} 
+				return nil, nil
+			}
+		}
+		
+		if args[9] != res.AgreementCU_date {
+			err = router.CheckNotPast(stub, "agreementCU_date", args[9])
+			if err != nil {
+				err = router.ErrorEvent(stub, err)
+				if err != nil {
+					return nil, err
+				}
+				return nil, nil
+			}
+		}
+
+		res.TransID = args[1]
+		res.Agreement_status = args[2]
+		res.BuyerName = args[3]
+		res.SellerName = args[4]
+		res.ShipperName = args[5]
+		res.BB_name = args[6]
+		res.SB_name	= args[7]
+		res.PortAuthName = args[8]
+		res.AgreementCU_date = args[9]
+		res.ItemId = args[10]
+		res.Item_name = args[11]
+		res.Item_quantity = args[12]
+		res.Total_Value = args[13]
+		res.Delivery_date = args[14]
+		res.ExtraCharges = args[15]
+		res.Shipper_fees = args[16]
+		res.DocumentName = args[17]
+		res.DocumentURL = args[18]
+		res.TC_Text = args[19]
+		res.Buyer_sign = args[20]
+		res.BuyerBank_sign = args[21]
+		res.Seller_sign = args[22]
+		res.SellerBank_sign = args[23]
+		res.Industry = args[24]
+		res.GoodsPrice = args[25]
+		
+		totalValue,err := strconv.Atoi(res.Total_Value)
+		if err != nil {
+			return nil, errors.New("Error while converting string 'total_value' to int ")
+		}
+
+		// Auto Approval
+		/*if (totalValue <= 10000 || res.Industry == "Books" || res.Industry == "Mobiles & Tablets"){
+			res.BuyerBank_sign = "true";
+			if (res.Industry == "Books" || res.Industry == "Mobiles & Tablets"){
+				res.SellerBank_sign = "true";
+			}
+		}*/
+		if (totalValue <= 10000 && (res.Industry == "Books" || res.Industry == "Mobiles & Tablets")){
+			res.BuyerBank_sign = "true";
+		}
+		if (res.Industry == "Books" || res.Industry == "Mobiles & Tablets"){
+			res.SellerBank_sign = "true";
+		}
+		if(res.BuyerBank_sign == "true" && res.Seller_sign == "false" && res.SellerBank_sign == "false"){
+			res.Agreement_status = "Approved By Buyer Bank"
+		}
+		if(res.BuyerBank_sign == "true" && res.Seller_sign == "true" && res.SellerBank_sign == "false"){
+			res.Agreement_status = "Approved By Seller"
+		}
+		if(res.BuyerBank_sign == "true" && res.Seller_sign == "true" && res.SellerBank_sign == "true"){
+			res.Agreement_status = router.ApprovedStatus
+		}
+		res.Version++
+		res.LastModifiedTxID = stub.GetTxID()
+		res.UpdatedAt, res.UpdatedBy, err = router.TxStamp(stub)
+		if err != nil {
+			return nil, err
+		}
+	}else{
+		errMsg := "{ \"message\" : " + router.JSONString(agreementId + " Not Found.") + ", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+
+	input := agreementJSON(res)										//build the Agreement json string
+	err = stub.PutState(agreementKey(agreementId), []byte(input))									//store Agreement with id as key
+	if err != nil {
+		return nil, err
+	}
+	eventType := events.AgreementUpdated
+	if router.Signed(previous.Buyer_sign, res.Buyer_sign) || router.Signed(previous.BuyerBank_sign, res.BuyerBank_sign) ||
+		router.Signed(previous.Seller_sign, res.Seller_sign) || router.Signed(previous.SellerBank_sign, res.SellerBank_sign) {
+		eventType = events.AgreementSigned
+	}
+	err = router.Emit(stub, events.Event{Type: eventType, EntityID: agreementId, Status: res.Agreement_status,
+		Message: "Agreement updated succcessfully"}, agreementAsBytes, input)
+	if err != nil {
+		return nil, err
+	} 
+	router.Println("end update_agreement")
+	return nil, nil
+}
+// ============================================================================================================================
+// agreementJSON - build the Agreement json string manually
+// ============================================================================================================================
+func agreementJSON(res Agreement) string {
+	return `{`+
+		`"agreementId": "` + res.AgreementID + `" , `+
+		`"transId": "` + res.TransID + `" , `+ 
+		`"agreement_status": "` + res.Agreement_status + `" , `+ 
+		`"buyer_name": "` + res.BuyerName + `" , `+
+		`"seller_name": "` + res.SellerName + `" , `+
+		`"shipper_name": "` + res.ShipperName + `" , `+ 
+		`"bb_name": "` + res.BB_name + `" , `+ 
+		`"sb_name": "` + res.SB_name + `" , `+ 
+		`"agreementPortAuth_name": "` + res.PortAuthName + `" , `+ 
+		`"agreementCU_date": "` + res.AgreementCU_date + `" , `+ 
+		`"item_id": "` + res.ItemId + `" , `+ 
+		`"item_name": "` + res.Item_name + `" , `+ 
+		`"item_quantity": "` + res.Item_quantity + `" , `+ 
+		`"total_value": "` + res.Total_Value + `" , `+ 
+		`"delivery_date": "` + res.Delivery_date + `" , `+ 
+		`"extraCharges": "` + res.ExtraCharges + `" , `+ 
+		`"shipper_fees" : "` + res.Shipper_fees + `", `+ 
+		`"document_name": "` + res.DocumentName + `" , `+ 
+		`"document_url": "` + res.DocumentURL + `" , `+ 
+		`"tc_text" : "` + res.TC_Text + `" , `+ 
+		`"buyer_sign": "` + res.Buyer_sign + `" , `+ 
+		`"buyerBank_sign": "` + res.BuyerBank_sign + `" , `+ 
+		`"seller_sign": "` + res.Seller_sign + `" , `+ 
+		`"sellerBank_sign" : "` + res.SellerBank_sign + `" , `+ 
+		`"industry" : "` + res.Industry + `" , `+
+		`"goodsPrice" : "` + res.GoodsPrice + `" , `+
+		`"clearance_status" : "` + res.Clearance_status + `" , `+
+		`"clearance_shipment" : "` + res.Clearance_shipment + `" , `+
+		`"shipping_status" : "` + res.Shipping_status + `" , `+
+		`"version" : ` + strconv.Itoa(res.Version) + ` , `+
+		`"lastModifiedTxId" : "` + res.LastModifiedTxID + `" , `+
+		`"createdAt" : "` + res.CreatedAt + `" , `+
+		`"createdBy" : ` + router.JSONString(res.CreatedBy) + ` , `+
+		`"updatedAt" : "` + res.UpdatedAt + `" , `+
+		`"updatedBy" : ` + router.JSONString(res.UpdatedBy) + ` `+
+		`}`
+}
+// ============================================================================================================================
+// create Agreement - create a new Agreement, store into chaincode state
+// ============================================================================================================================
+func (t *ManageAgreement) create_agreement(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var err error
+	if len(args) != 26 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting 26 arguments.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	router.Println("start create_agreement")
+	
+		agreementId := args[0]
+		if agreementId == "" {
+			agreementId, err = router.NewID(stub, "AGR-", agreementKey)			//no ID given, one is derived from the transaction ID
+			if err != nil {
+				return nil, err
+			}
+		}
+		transId := args[1]
+		agreement_status := args[2]
+		buyer_name := args[3]
+		seller_name := args[4]
+		shipper_name := args[5]
+		bb_name := args[6]
+		sb_name	:= args[7]
+		agreementPortAuth_name := args[8]
+		agreementCU_date := args[9]
+		item_id := args[10]
+		item_name := args[11]
+		item_quantity:= args[12]
+		total_value := args[13]
+		delivery_date := args[14]
+		extraCharges := args[15]
+		shipper_fees := args[16]
+		document_name := args[17]
+		document_url := args[18]
+		tc_text := args[19]
+		buyer_sign := args[20]
+		buyerBank_sign := args[21]
+		seller_sign := args[22]
+		sellerBank_sign := args[23]
+		industry := args[24]
+		goodsPrice := args[25]
+		createdAt, createdBy, err := router.TxStamp(stub)
+		if err != nil {
+			return nil, err
+		}
+		agreementCU_date, err = router.RecordDate(stub, "agreementCU_date", agreementCU_date)
+		if err != nil {
+			err = router.ErrorEvent(stub, err)
+			if err != nil {
+				return nil, err
+			}
+			return nil, nil
+		}
+		err = router.CheckNotPast(stub, "delivery_date", delivery_date)
+		if err != nil {
+			err = router.ErrorEvent(stub, err)
+			if err != nil {
+				return nil, err
+			}
+			return nil, nil
+		}
+		
+		router.Println("Checking fraud list...");
+
+		buyer, err:= t.get_fraud_details(stub, buyer_name)
+		if buyer != nil{
+			errMsg := "{ \"Agreement ID\" : " + router.JSONString(agreementId) + ", \"message\" : \"Buyer name exists in Fraud list. So, Agreement auto-rejected by System. \", \"code\" : \"503\"}"
+			err = stub.SetEvent("errEvent", []byte(errMsg))
+			if err != nil {
+				return nil, err
+			} 
+			return nil, nil
+		} else if err != nil{
+			errMsg := "{ \"message\" : \"Error while checking for Buyer in Fraud list. \", \"code\" : \"503\"}"
+			err = stub.SetEvent("errEvent", []byte(errMsg))
+			if err != nil {
+				return nil, err
+			} 
+			return nil, nil
+		}
+		seller, err := t.get_fraud_details(stub, seller_name)
+		if seller != nil{
+			errMsg := "{ \"Agreement ID\" : " + router.JSONString(agreementId) + ", \"message\" : \"Seller name exists in Fraud list. So, Agreement auto-rejected by System. \", \"code\" : \"503\"}"
+			err = stub.SetEvent("errEvent", []byte(errMsg))
+			if err != nil {
+				return nil, err
+			} 
+			return nil, nil
+		}else if err != nil{
+			errMsg := "{ \"message\" : \"Error while checking for Seller in Fraud list. \", \"code\" : \"503\"}"
+			err = stub.SetEvent("errEvent", []byte(errMsg))
+			if err != nil {
+				return nil, err
+			} 
+			return nil, nil
+		}
+		router.Println("Checked fraud list successfully.");
+
+		agreementAsBytes, err := stub.GetState(agreementKey(agreementId))
+		if err != nil {
+			return nil, errors.New("Failed to get Agreement ID")
+		}
+		router.Print("agreementAsBytes: ")
+		router.Println(agreementAsBytes)
+		res := Agreement{}
+		json.Unmarshal(agreementAsBytes, &res)
+		router.Print("res: ")
+		router.Println(res)
+		if res.AgreementID == agreementId{
+			router.Println("This Agreement already exists: " + agreementId)
+			errMsg := "{ \"message\" : \"This Agreement already exists.\", \"code\" : \"503\"}"
+			err := stub.SetEvent("errEvent", []byte(errMsg))
+			if err != nil {
+				return nil, err
+			} 
+		return nil, nil				//all stop a Agreement by this name exists
+	}
+	err = t.checkLinkedPO(stub, transId, buyer_name, seller_name)
+	if err != nil {
+		errMsg := "{ \"Agreement ID\" : " + router.JSONString(agreementId) + ", \"message\" : " + router.JSONString(err.Error() + ".") + ", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	
+	//build the Agreement json string manually
+	input := 	`{`+
+		`"agreementId": "` + agreementId + `" , `+
+		`"transId": "` + transId + `" , `+ 
+		`"agreement_status": "` + agreement_status + `" , `+ 
+		`"buyer_name": "` + buyer_name + `" , `+
+		`"seller_name": "` + seller_name + `" , `+
+		`"shipper_name": "` + shipper_name + `" , `+ 
+		`"bb_name": "` + bb_name + `" , `+ 
+		`"sb_name": "` + sb_name + `" , `+ 	
+		`"agreementPortAuth_name": "` + agreementPortAuth_name + `" , `+ 
+		`"agreementCU_date": "` + agreementCU_date + `" , `+ 
+		`"item_id": "` + item_id + `" , `+ 
+		`"item_name": "` + item_name + `" , `+ 
+		`"item_quantity": "` + item_quantity + `" , `+ 
+		`"total_value": "` + total_value + `" , `+ 
+		`"delivery_date": "` + delivery_date + `" , `+ 
+		`"extraCharges": "` + extraCharges + `" , `+ 
+		`"shipper_fees": "` + shipper_fees + `", `+ 
+		`"document_name": "` + document_name + `" , `+ 
+		`"document_url": "` + document_url + `" , `+ 
+		`"tc_text": "` + tc_text + `" , `+ 
+		`"buyer_sign": "` + buyer_sign + `" , `+ 
+		`"buyerBank_sign": "` + buyerBank_sign + `" , `+ 
+		`"seller_sign": "` + seller_sign + `" , `+ 
+		`"sellerBank_sign": "` + sellerBank_sign + `", `+ 
+		`"industry": "` + industry + `" , `+
+		`"goodsPrice": "` + goodsPrice + `" , `+
+		`"clearance_status": "" , `+
+		`"clearance_shipment": "" , `+
+		`"shipping_status": "Not Shipped" , `+
+		`"version": 1 , `+
+		`"lastModifiedTxId": "` + stub.GetTxID() + `" , `+
+		`"createdAt": "` + createdAt + `" , `+
+		`"createdBy": ` + router.JSONString(createdBy) + ` , `+
+		`"updatedAt": "` + createdAt + `" , `+
+		`"updatedBy": ` + router.JSONString(createdBy) + ` `+
+		`}`
+		router.Println("input: " + input)
+		router.Print("input in bytes array: ")
+		router.Println([]byte(input))
+	err = stub.PutState(agreementKey(agreementId), []byte(input))									//store Agreement with agreementId as key
+	if err != nil {
+		return nil, err
+	}
+	//get the Agreement index
+	agreementIndexAsBytes, err := stub.GetState(AgreementIndexStr)
+	if err != nil {
+		return nil, errors.New("Failed to get Agreement index")
+	}
+	var agreementIndex []string
+	router.Print("agreementIndexAsBytes: ")
+	router.Println(agreementIndexAsBytes)
+	
+	json.Unmarshal(agreementIndexAsBytes, &agreementIndex)							//un stringify it aka JSON.parse()
+	router.Print("agreementIndex after unmarshal..before append: ")
+	router.Println(agreementIndex)
+	//append
+	agreementIndex = append(agreementIndex, agreementId)									//add Agreement transID to index list
+	router.Println("! Agreement index after appending agreementId: ", agreementIndex)
+	jsonAsBytes, _ := json.Marshal(agreementIndex)
+	router.Print("jsonAsBytes: ")
+	router.Println(jsonAsBytes)
+	err = stub.PutState(AgreementIndexStr, jsonAsBytes)						//store name of Agreement
+	if err != nil {
+		return nil, err
+	}
+
+	err = router.Emit(stub, events.Event{Type: events.AgreementCreated, EntityID: agreementId, Status: agreement_status,
+		Message: "Agreement created succcessfully"}, nil, input)
+	if err != nil {
+		return nil, err
+	}
+
+	router.Println("end create_agreement")
+	return []byte(agreementId), nil
+}
+// ============================================================================================================================
+// create Fraud_list - add an entry in the farus list, store into chaincode state
+// ============================================================================================================================
+func (t *ManageAgreement) update_fraud_list(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	// update_fraud_list("fraudID","fraudName")
+	var err error
+	if len(args) != 2 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting 2 arguments.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	router.Println("Updating Fraud list.")
+	
+	fraudId := args[0]
+	if fraudId == "" {
+		fraudId, err = router.NewID(stub, "FRD-", fraudKey)			//no ID given, one is derived from the transaction ID
+		if err != nil {
+			return nil, err
+		}
+	}
+	fraudName := args[1]
+
+	fraudListAsBytes, err := stub.GetState(fraudKey(fraudId))
+	if err != nil {
+		return nil, errors.New("Failed to get fraudID")
+	}
+	router.Print("fraudListAsBytes: ")
+	router.Println(fraudListAsBytes)
+	res := Fraud_list{}
+	json.Unmarshal(fraudListAsBytes, &res)
+	router.Print("res: ")
+	router.Println(res)
+	if res.FraudID == fraudId{
+		router.Println("This Fraud Name already exists: " + fraudId)
+		errMsg := "{ \"message\" : \"This Fraud Name already exists.\", \"code\" : \"503\"}"
+		err := stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil				//all stop a Fraud List by this name exists
+	}
+	
+	//build the fraud json string manually
+	input := 	`{`+
+		`"fraudId": "` + fraudId + `" , `+
+		`"fraudName": "` + fraudName  + `" `+ 
+		`}`
+		router.Println("input: " + input)
+		router.Print("input in bytes array: ")
+		router.Println([]byte(input))
+	err = stub.PutState(fraudKey(fraudId), []byte(input))									//store Fraud with fraudId as key
+	if err != nil {
+		return nil, err
+	}
+	//get the Fraud List index
+	fraudListIndexAsBytes, err := stub.GetState(FraudListIndexStr)
+	if err != nil {
+		return nil, errors.New("Failed to get Fraud List index")
+	}
+	var fraudListIndex []string
+	router.Print("fraudListIndexAsBytes: ")
+	router.Println(fraudListIndexAsBytes)
+	
+	json.Unmarshal(fraudListIndexAsBytes, &fraudListIndex)							//un stringify it aka JSON.parse()
+	//append
+	fraudListIndex = append(fraudListIndex, fraudId)									//add fraudId to index list
+	router.Println("! fraud List index after appending fraudId: ", fraudListIndex)
+	jsonAsBytes, _ := json.Marshal(fraudListIndex)
+	router.Print("jsonAsBytes: ")
+	router.Println(jsonAsBytes)
+	err = stub.PutState(FraudListIndexStr, jsonAsBytes)						//store name of Agreement
+	if err != nil {
+		return nil, err
+	}
+	err = router.Emit(stub, events.Event{Type: events.FraudListed, EntityID: fraudId, Message: "Fraud ID added succcessfully"}, nil, input)
+	if err != nil {
+		return nil, err
+	} 
+
+	router.Println("Fraud list updated successfully.")
+	return []byte(fraudId), nil
+}
+// ============================================================================================================================
+// update_clearance_status - record the port clearance status of a shipment of an Agreement, called by ManageShipment or by
+// the port authority of the Agreement
+// ============================================================================================================================
+func (t *ManageAgreement) update_clearance_status(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	// update_clearance_status("agreementId", "shipmentId", "clearanceStatus")
+	var err error
+	if len(args) != 3 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting 3 arguments.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	router.Println("start update_clearance_status")
+	agreementId := args[0]
+	agreementAsBytes, err := stub.GetState(agreementKey(agreementId))
+	if err != nil {
+		return nil, errors.New("Failed to get Agreement ID")
+	}
+	res := Agreement{}
+	json.Unmarshal(agreementAsBytes, &res)
+	if res.AgreementID != agreementId{
+		errMsg := "{ \"message\" : " + router.JSONString(agreementId + " Not Found.") + ", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	if router.CheckLinked(stub, "shipment") != nil {
+		if err = router.CheckParty(stub, res.PortAuthName); err != nil {
+			return nil, router.ErrorEvent(stub, errors.New("Only the shipment chaincode or the port authority updates the clearance status. " + err.Error()))
+		}
+	}
+	res.Clearance_shipment = args[1]
+	res.Clearance_status = args[2]
+	res.Version++
+	res.LastModifiedTxID = stub.GetTxID()
+	res.UpdatedAt, res.UpdatedBy, err = router.TxStamp(stub)
+	if err != nil {
+		return nil, err
+	}
+	input := agreementJSON(res)
+	err = stub.PutState(agreementKey(agreementId), []byte(input))
+	if err != nil {
+		return nil, err
+	}
+	err = router.Emit(stub, events.Event{Type: events.AgreementClearanceUpdated, EntityID: agreementId, Status: res.Agreement_status,
+		Message: "Agreement clearance status updated succcessfully"}, agreementAsBytes, input)
+	if err != nil {
+		return nil, err
+	} 
+	router.Println("end update_clearance_status")
+	return nil, nil
+}
+// ============================================================================================================================
+// liquidatedDamagesKey - key under which the liquidated damages terms of an Agreement are stored
+// ============================================================================================================================
+func liquidatedDamagesKey(agreementId string) string {
+	return "LiquidatedDamages_" + agreementId
+}
+// ============================================================================================================================
+// set_liquidated_damages - set the liquidated damages owed for late delivery under an Agreement
+// ============================================================================================================================
+func (t *ManageAgreement) set_liquidated_damages(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	// set_liquidated_damages("agreementId", "ratePerDay", "capPercent", "graceDays")
+	var err error
+	if len(args) != 4 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting 4 arguments.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	router.Println("start set_liquidated_damages")
+	agreementId := args[0]
+	for _, val := range args[1:] {
+		number, err := strconv.ParseFloat(val, 64)
+		if err != nil || number < 0 {
+			errMsg := "{ \"message\" : \"Liquidated damages terms must be non-negative numbers.\", \"code\" : \"503\"}"
+			err = stub.SetEvent("errEvent", []byte(errMsg))
+			if err != nil {
+				return nil, err
+			} 
+			return nil, nil
+		}
+	}
+	agreementAsBytes, err := stub.GetState(agreementKey(agreementId))
+	if err != nil {
+		return nil, errors.New("Failed to get Agreement ID")
+	}
+	agreement := Agreement{}
+	json.Unmarshal(agreementAsBytes, &agreement)
+	if agreement.AgreementID != agreementId{
+		errMsg := "{ \"message\" : " + router.JSONString(agreementId + " Not Found.") + ", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	previousAsBytes, err := stub.GetState(liquidatedDamagesKey(agreementId))
+	if err != nil {
+		return nil, errors.New("Failed to get liquidated damages terms")
+	}
+	res := LiquidatedDamages{AgreementID: agreementId, RatePerDay: args[1], CapPercent: args[2], GraceDays: args[3]}
+	termsAsBytes, _ := json.Marshal(res)
+	err = stub.PutState(liquidatedDamagesKey(agreementId), termsAsBytes)
+	if err != nil {
+		return nil, err
+	}
+	err = router.Emit(stub, events.Event{Type: events.AgreementTermsSet, EntityID: agreementId, Status: agreement.Agreement_status,
+		Message: "Liquidated damages terms set succcessfully"}, previousAsBytes, termsAsBytes)
+	if err != nil {
+		return nil, err
+	} 
+	router.Println("end set_liquidated_damages")
+	return nil, nil
+}
+// ============================================================================================================================
+// get_liquidated_damages - get the liquidated damages terms of an Agreement, empty when it has none
+// ============================================================================================================================
+func (t *ManageAgreement) get_liquidated_damages(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var err error
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting \"AgreementID\" as an argument\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	valAsbytes, err := stub.GetState(liquidatedDamagesKey(args[0]))
+	if err != nil {
+		return nil, errors.New("Failed to get liquidated damages for " + args[0])
+	}
+	return valAsbytes, nil
+}
+// ============================================================================================================================
+// shippedBalanceKey - key under which the shipped balance of an Agreement is stored
+// ============================================================================================================================
+func shippedBalanceKey(agreementId string) string {
+	return "ShippedBalance_" + agreementId
+}
+// ============================================================================================================================
+// getShippedBalance - get the shipped balance of an Agreement, starting from its ordered quantity when nothing shipped yet
+// ============================================================================================================================
+func getShippedBalance(stub shim.ChaincodeStubInterface, agreement Agreement) (ShippedBalance, error) {
+	res := ShippedBalance{}
+	balanceAsBytes, err := stub.GetState(shippedBalanceKey(agreement.AgreementID))
+	if err != nil {
+		return res, errors.New("Failed to get shipped balance for " + agreement.AgreementID)
+	}
+	json.Unmarshal(balanceAsBytes, &res)
+	if res.AgreementID != agreement.AgreementID {
+		res = ShippedBalance{
+			AgreementID: agreement.AgreementID,
+			Lines: []ShippedLine{{ItemId: agreement.ItemId, Ordered: agreement.Item_quantity, Shipped: "0", Remaining: agreement.Item_quantity}},
+			Shipments: []string{},
+		}
+	}
+	return res, nil
+}
+// ============================================================================================================================
+// record_shipped_quantity - book the items of a shipment against the Agreement, called by ManageShipment or the admin MSP
+// ============================================================================================================================
+func (t *ManageAgreement) record_shipped_quantity(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	// record_shipped_quantity("agreementId", "shipmentId", "[{item_id, quantity}, ...]")
+	// Errors are returned rather than sent as errEvent so the calling shipment transaction is rejected as well.
+	if len(args) != 3 {
+		return nil, errors.New("Incorrect number of arguments. Expecting 3 arguments.")
+	}
+	if router.CheckLinked(stub, "shipment") != nil && router.CheckAdmin(stub) != nil {
+		return nil, errors.New("Only the shipment chaincode or the admin MSP books shipped quantities.")
+	}
+	router.Println("start record_shipped_quantity")
+	agreementId := args[0]
+	shipmentId := args[1]
+	var items []ShipmentItem
+	err := json.Unmarshal([]byte(args[2]), &items)
+	if err != nil || len(items) == 0 {
+		return nil, errors.New("Shipment items must be a non-empty JSON array.")
+	}
+
+	agreementAsBytes, err := stub.GetState(agreementKey(agreementId))
+	if err != nil {
+		return nil, errors.New("Failed to get Agreement ID")
+	}
+	agreement := Agreement{}
+	json.Unmarshal(agreementAsBytes, &agreement)
+	if agreement.AgreementID != agreementId {
+		return nil, errors.New(agreementId + " Not Found.")
+	}
+	res, err := getShippedBalance(stub, agreement)
+	if err != nil {
+		return nil, err
+	}
+	for _, val := range res.Shipments {
+		if val == shipmentId {
+			return nil, errors.New("Shipment " + shipmentId + " is already booked against " + agreementId + ".")
+		}
+	}
+
+	for _, item := range items {
+		quantity, err := strconv.ParseFloat(item.Quantity, 64)
+		if err != nil || quantity <= 0 {
+			return nil, errors.New("Quantity of item " + item.ItemId + " must be a positive number.")
+		}
+		found := false
+		for i := range res.Lines {
+			if res.Lines[i].ItemId != item.ItemId {
+				continue
+			}
+			found = true
+			remaining, _ := strconv.ParseFloat(res.Lines[i].Remaining, 64)
+			shipped, _ := strconv.ParseFloat(res.Lines[i].Shipped, 64)
+			if quantity > remaining {
+				return nil, errors.New("Over-shipment of item " + item.ItemId + ": " + item.Quantity + " shipped, " + res.Lines[i].Remaining + " remaining.")
+			}
+			res.Lines[i].Shipped = strconv.FormatFloat(shipped + quantity, 'f', -1, 64)
+			res.Lines[i].Remaining = strconv.FormatFloat(remaining - quantity, 'f', -1, 64)
+		}
+		if !found {
+			return nil, errors.New("Item " + item.ItemId + " is not ordered under " + agreementId + ".")
+		}
+	}
+	res.Shipments = append(res.Shipments, shipmentId)
+	balanceAsBytes, _ := json.Marshal(res)
+	err = stub.PutState(shippedBalanceKey(agreementId), balanceAsBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	agreement.Shipping_status = shippingStatus(res)
+	agreement.Version++
+	agreement.LastModifiedTxID = stub.GetTxID()
+	agreement.UpdatedAt, agreement.UpdatedBy, err = router.TxStamp(stub)
+	if err != nil {
+		return nil, err
+	}
+	input := agreementJSON(agreement)
+	err = stub.PutState(agreementKey(agreementId), []byte(input))
+	if err != nil {
+		return nil, err
+	}
+	err = router.Emit(stub, events.Event{Type: events.AgreementShipped, EntityID: agreementId, Status: agreement.Agreement_status,
+		Data: map[string]interface{}{"shipmentId": shipmentId}, Message: "Shipped quantity recorded succcessfully"}, agreementAsBytes, input)
+	if err != nil {
+		return nil, err
+	} 
+	router.Println("end record_shipped_quantity")
+	return nil, nil
+}
+// ============================================================================================================================
+// shippingStatus - shipping status of an Agreement from its shipped balance
+// ============================================================================================================================
+func shippingStatus(res ShippedBalance) string {
+	shipped := false
+	remaining := false
+	for _, line := range res.Lines {
+		if quantity, _ := strconv.ParseFloat(line.Shipped, 64); quantity > 0 {
+			shipped = true
+		}
+		if quantity, _ := strconv.ParseFloat(line.Remaining, 64); quantity > 0 {
+			remaining = true
+		}
+	}
+	if !shipped {
+		return "Not Shipped"
+	}else if remaining {
+		return "Partially Shipped"
+	}
+	return "Fully Shipped"
+}
+// ============================================================================================================================
+// release_shipped_quantity - give the items of a deleted shipment back to the Agreement, called by ManageShipment or the
+// admin MSP
+// ============================================================================================================================
+func (t *ManageAgreement) release_shipped_quantity(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	// release_shipped_quantity("agreementId", "shipmentId", "[{item_id, quantity}, ...]")
+	// Errors are returned rather than sent as errEvent so the calling shipment transaction is rejected as well.
+	if len(args) != 3 {
+		return nil, errors.New("Incorrect number of arguments. Expecting 3 arguments.")
+	}
+	if router.CheckLinked(stub, "shipment") != nil && router.CheckAdmin(stub) != nil {
+		return nil, errors.New("Only the shipment chaincode or the admin MSP releases shipped quantities.")
+	}
+	router.Println("start release_shipped_quantity")
+	agreementId := args[0]
+	shipmentId := args[1]
+	var items []ShipmentItem
+	err := json.Unmarshal([]byte(args[2]), &items)
+	if err != nil || len(items) == 0 {
+		return nil, errors.New("Shipment items must be a non-empty JSON array.")
+	}
+
+	agreementAsBytes, err := stub.GetState(agreementKey(agreementId))
+	if err != nil {
+		return nil, errors.New("Failed to get Agreement ID")
+	}
+	agreement := Agreement{}
+	json.Unmarshal(agreementAsBytes, &agreement)
+	if agreement.AgreementID != agreementId {
+		return nil, errors.New(agreementId + " Not Found.")
+	}
+	res, err := getShippedBalance(stub, agreement)
+	if err != nil {
+		return nil, err
+	}
+	booked := false
+	for i, val := range res.Shipments {
+		if val == shipmentId {
+			res.Shipments = append(res.Shipments[:i], res.Shipments[i+1:]...)
+			booked = true
+			break
+		}
+	}
+	if !booked {
+		return nil, errors.New("Shipment " + shipmentId + " is not booked against " + agreementId + ".")
+	}
+
+	for _, item := range items {
+		quantity, err := strconv.ParseFloat(item.Quantity, 64)
+		if err != nil || quantity <= 0 {
+			return nil, errors.New("Quantity of item " + item.ItemId + " must be a positive number.")
+		}
+		found := false
+		for i := range res.Lines {
+			if res.Lines[i].ItemId != item.ItemId {
+				continue
+			}
+			found = true
+			remaining, _ := strconv.ParseFloat(res.Lines[i].Remaining, 64)
+			shipped, _ := strconv.ParseFloat(res.Lines[i].Shipped, 64)
+			if quantity > shipped {
+				return nil, errors.New("Release of item " + item.ItemId + ": " + item.Quantity + " released, " + res.Lines[i].Shipped + " shipped.")
+			}
+			res.Lines[i].Shipped = strconv.FormatFloat(shipped - quantity, 'f', -1, 64)
+			res.Lines[i].Remaining = strconv.FormatFloat(remaining + quantity, 'f', -1, 64)
+		}
+		if !found {
+			return nil, errors.New("Item " + item.ItemId + " is not ordered under " + agreementId + ".")
+		}
+	}
+	balanceAsBytes, _ := json.Marshal(res)
+	err = stub.PutState(shippedBalanceKey(agreementId), balanceAsBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	agreement.Shipping_status = shippingStatus(res)
+	agreement.Version++
+	agreement.LastModifiedTxID = stub.GetTxID()
+	agreement.UpdatedAt, agreement.UpdatedBy, err = router.TxStamp(stub)
+	if err != nil {
+		return nil, err
+	}
+	input := agreementJSON(agreement)
+	err = stub.PutState(agreementKey(agreementId), []byte(input))
+	if err != nil {
+		return nil, err
+	}
+	err = router.Emit(stub, events.Event{Type: events.AgreementShipped, EntityID: agreementId, Status: agreement.Agreement_status,
+		Data: map[string]interface{}{"shipmentId": shipmentId, "released": true}, Message: "Shipped quantity released succcessfully"}, agreementAsBytes, input)
+	if err != nil {
+		return nil, err
+	} 
+	router.Println("end release_shipped_quantity")
+	return nil, nil
+}
+// ============================================================================================================================
+// get_shipped_balance - get shipped versus ordered quantity of every line of an Agreement
+// ============================================================================================================================
+func (t *ManageAgreement) get_shipped_balance(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var err error
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting \"AgreementID\" as an argument\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	agreementAsBytes, err := stub.GetState(agreementKey(args[0]))
+	if err != nil {
+		return nil, errors.New("Failed to get Agreement ID")
+	}
+	agreement := Agreement{}
+	json.Unmarshal(agreementAsBytes, &agreement)
+	if agreement.AgreementID != args[0] {
+		errMsg := "{ \"message\" : " + router.JSONString(args[0] + " Not Found.") + ", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	res, err := getShippedBalance(stub, agreement)
+	if err != nil {
+		return nil, err
+	}
+	balanceAsBytes, _ := json.Marshal(res)
+	return balanceAsBytes, nil
+}
+// ============================================================================================================================
+// checkLinkedPO - confirm with ManagePO that the PO exists, is still open and is between the same parties
+// ============================================================================================================================
+func (t *ManageAgreement) checkLinkedPO(stub shim.ChaincodeStubInterface, transId string, buyerName string, sellerName string) error {
+	poAsBytes, err := t.linker.QueryLinked(stub, "po", "getPO_byID", transId)
+	if err != nil {
+		return errors.New("Failed to query PO " + transId + ": " + err.Error())
+	}
+	po := struct{
+		TransID string `json:"transId"`
+		SellerName string `json:"sellerName"`
+		BuyerName string `json:"buyerName"`
+		PO_status string `json:"po_status"`
+	}{}
+	json.Unmarshal(poAsBytes, &po)
+	if po.TransID != transId || transId == "" {
+		return errors.New("PO " + transId + " Not Found")
+	}
+	status := strings.ToLower(po.PO_status)
+	if status == "rejected" || status == "cancelled" {
+		return errors.New("PO " + transId + " is " + po.PO_status)
+	}
+	if po.BuyerName != buyerName || po.SellerName != sellerName {
+		return errors.New("Buyer and seller do not match PO " + transId)
+	}
+	return nil
+}
+// ============================================================================================================================
+// queryLinked - query a linked domain for the trade record, null when it cannot be reached or has nothing
+// ============================================================================================================================
+func (t *ManageAgreement) queryLinked(stub shim.ChaincodeStubInterface, role string, function string, id string) (json.RawMessage, error) {
+	if !t.linker.Linked(stub, role) {
+		return json.RawMessage("null"), nil
+	}
+	valAsBytes, err := t.linker.QueryLinked(stub, role, function, id)
+	if err != nil {
+		return nil, errors.New("Failed to query the " + role + " chaincode for " + id)
+	}
+	if len(valAsBytes) == 0 {
+		return json.RawMessage("null"), nil
+	}
+	return json.RawMessage(valAsBytes), nil
+}
+// ============================================================================================================================
+// get_trade_record - get an Agreement together with its PO, payments, shipments and shipped balance in one response
+// ============================================================================================================================
+func (t *ManageAgreement) get_trade_record(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var err error
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting \"AgreementID\" as an argument\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	router.Println("start get_trade_record")
+	agreementAsBytes, err := stub.GetState(agreementKey(args[0]))
+	if err != nil {
+		return nil, errors.New("Failed to get Agreement ID")
+	}
+	agreement := Agreement{}
+	json.Unmarshal(agreementAsBytes, &agreement)
+	if agreement.AgreementID != args[0] {
+		errMsg := "{ \"message\" : " + router.JSONString(args[0] + " Not Found.") + ", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	balance, err := getShippedBalance(stub, agreement)
+	if err != nil {
+		return nil, err
+	}
+	record := struct{
+		Agreement json.RawMessage `json:"agreement"`
+		PO json.RawMessage `json:"po"`
+		Payments json.RawMessage `json:"payments"`
+		Shipments json.RawMessage `json:"shipments"`
+		ShippedBalance ShippedBalance `json:"shipped_balance"`
+	}{Agreement: json.RawMessage(agreementAsBytes), ShippedBalance: balance}
+	if record.PO, err = t.queryLinked(stub, "po", "getPO_byID", agreement.TransID); err != nil {
+		return nil, err
+	}
+	if record.Payments, err = t.queryLinked(stub, "payment", "getPaymentByAgreement", agreement.AgreementID); err != nil {
+		return nil, err
+	}
+	if record.Shipments, err = t.queryLinked(stub, "shipment", "getShipment_byAgreement", agreement.AgreementID); err != nil {
+		return nil, err
+	}
+	recordAsBytes, _ := json.Marshal(record)
+	router.Println("end get_trade_record")
+	return recordAsBytes, nil
+}
+/*func (t *ManageAgreement) approve_agreement(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	/*var jsonResp , str string
+	var err error
+	router.Println("start approve_agreement")
+	if len(args) != 3{
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting 3 arguments.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	// set agreementId
+	agreementId := args[0]
+	bb_name := args[1]
+	sb_name := args[2]
+	//sign := args[2]
+	agreementAsBytes, err := stub.GetState(agreementKey(agreementId))									//get the Agreement for the specified agreementId from chaincode state
+	if err != nil {
+		jsonResp = "{\"Error\":\"Failed to get state for " + agreementId + "\"}"
+		return nil, errors.New(jsonResp)
+	}
+	res := Agreement{}
+	json.Unmarshal(agreementAsBytes, &res)
+	if res.AgreementID == agreementId{
+		router.Println("Agreement found with agreementId : " + agreementId)
+		router.Println("res: "+res);
+		if res.BB_name == bb_name {
+			totalValue,err := strconv.Atoi(res.Total_Value)
+			if err != nil {
+				return nil, errors.New("Error while converting string 'Total_Value' to int ")
+			}
+			if (totalValue <= 10000 && res.Industry == "Books" || res.Industry == "Mobiles & Tablets"){
+					res.BuyerBank_sign = "true";
+					str = "Buyer Bank Signature"
+			}
+		} else if res.SB_name == sb_name {
+			if (res.Industry == "Books" || res.Industry == "Mobiles & Tablets"){
+					res.SellerBank_sign = "true";
+					str = "Seller Bank Signature"
+			}
+		}
+	}else{
+		errMsg := "{ \"message\" : " + router.JSONString(agreementId + " Not Found.") + ", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}*/
+	
+	//build the Agreement json string manually
+	/*input := 	`{`+
+		`"agreementId": "` + res.AgreementID + `" , `+
+		`"transId": "` + res.TransID + `" , `+ 
+		`"agreement_status": "` + res.Agreement_status + `" , `+ 
+		`"buyer_name": "` + res.BuyerName + `" , `+
+		`"seller_name": "` + res.SellerName + `" , `+
+		`"shipper_name": "` + res.ShipperName + `" , `+ 
+		`"bb_name": "` + res.BB_name + `" , `+ 
+		`"sb_name": "` + res.SB_name + `" , `+ 
+		`"agreementPortAuth_name": "` + res.PortAuthName + `" , `+ 
+		`"agreementCU_date": "` + res.AgreementCU_date + `" , `+ 
+		`"item_id": "` + res.ItemId + `" , `+ 
+		`"item_name": "` + res.Item_name + `" , `+ 
+		`"item_quantity": "` + res.Item_quantity + `" , `+ 
+		`"total_value": "` + res.Total_Value + `" , `+ 
+		`"delivery_date": "` + res.Delivery_date + `" , `+ 
+		`"extraCharges": "` + res.ExtraCharges + `" , `+ 
+		`"shipper_fees": "` + res.Shipper_fees + `" , `+ 
+		`"document_name": "` + res.DocumentName + `" , `+ 
+		`"document_url": "` + res.DocumentURL + `" , `+ 
+		`"tc_text" : "` + res.TC_Text + `" , `+ 
+		`"buyer_sign": "` + res.Buyer_sign + `" , `+ 
+		`"buyerBank_sign": "` + res.BuyerBank_sign + `" , `+ 
+		`"seller_sign": "` + res.Seller_sign + `" , `+ 
+		`"sellerBank_sign" : "` + res.SellerBank_sign + `" , `+ 
+		`"industry" : "` + res.Industry + `" , `+ 
+		`"goodsPrice" : "` + res.GoodsPrice + `" `+ 
+		`}`
+	router.Println("input: "+input)
+	err = stub.PutState(agreementKey(agreementId), []byte(input))									//store Agreement with id as key
+	if err != nil {
+		return nil, err
+	}*/
+	/*tosend := "{ \"agreementID\" : \""+agreementId+"\", \"message\" : \"" + str + " updated succcessfully\", \"code\" : \"200\"}"
+	err = stub.SetEvent("evtsender", []byte(tosend))
+	if err != nil {
+		return nil, err
+	} 
+	router.Println("end approve_agreement")
+	return nil, nil
+}*/
@@ -0,0 +1,368 @@
+package agreement
+
+import (
+"strings"
+"testing"
+"encoding/json"
+
+"github.com/wipro-blockchain/TF-v1/internal/mockstub"
+"github.com/wipro-blockchain/TF-v1/internal/mocktest"
+"github.com/wipro-blockchain/TF-v1/internal/payment"
+"github.com/wipro-blockchain/TF-v1/internal/po"
+"github.com/wipro-blockchain/TF-v1/internal/router"
+"github.com/wipro-blockchain/TF-v1/internal/shipment"
+)
+
+// newTradeFinance - all four domains on an initialized in-memory ledger with PO PO1 between Buyerco and Sellerco
+func newTradeFinance(t *testing.T) (*router.Router, *mockstub.MockStub) {
+	r := router.New("TradeFinance")
+	r.Register("po", po.New())
+	r.Register("agreement", New(r))
+	r.Register("payment", payment.New(r))
+	r.Register("shipment", shipment.New(r))
+	stub := mockstub.New("tradeFinance")
+	if _, err := stub.Init(r, " "); err != nil || stub.Failed() {
+		t.Fatalf("init failed: %v %s", err, stub.LastEvent().Payload)
+	}
+	mocktest.Succeed(t, stub, r, "create_po", "PO1", "Sellerco", "Buyerco", "2024-03-01", "2024-01-15", "Accepted", "ITM-1", "Rice",
+		"100", "25", "true", "true")
+	return r, stub
+}
+
+// agreementArgs - the create_agreement arguments of an Agreement of PO1 worth 2500
+func agreementArgs(agreementId string) []string {
+	return []string{agreementId, "PO1", "Created", "Buyerco", "Sellerco", "Shipco", "Buybank", "Sellbank", "Portauth",
+		"2024-01-16", "ITM-1", "Rice", "100", "2500", "2024-03-01", "0", "100", "Contract", "http://docs/contract", "Terms",
+		"true", "false", "false", "false", "Food", "25"}
+}
+
+// agreementField - the index of a field in the create_agreement and update_agreement arguments
+var agreementField = map[string]int{"agreement_status": 2, "buyer_name": 3, "total_value": 13, "buyer_sign": 20,
+	"buyerBank_sign": 21, "seller_sign": 22, "sellerBank_sign": 23, "industry": 24}
+
+func getAgreement(t *testing.T, r *router.Router, stub *mockstub.MockStub, agreementId string) Agreement {
+	res := Agreement{}
+	if err := json.Unmarshal(mocktest.Succeed(t, stub, r, "getAgreement_byID", agreementId), &res); err != nil {
+		t.Fatalf("getAgreement_byID(%s): %s", agreementId, err)
+	}
+	return res
+}
+
+func TestArgumentCounts(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	for _, function := range []string{"create_agreement", "update_agreement", "delete_agreement", "update_fraud_list",
+		"update_clearance_status", "set_liquidated_damages", "record_shipped_quantity", "release_shipped_quantity", "getAgreement_byID",
+		"getAgreement_byBuyer", "getAgreement_bySeller", "get_AllAgreement", "getAgreement_byShipper",
+		"getAgreement_byBuyerBank", "getAgreement_bySellerBank", "getAgreement_byPortAuthority", "get_fraud_list",
+		"getApprovalStatus", "get_fraud_details", "get_liquidated_damages", "get_shipped_balance", "get_trade_record"} {
+		mocktest.Reject(t, stub, r, function, "Incorrect number of arguments")
+	}
+}
+
+func TestCreateAgreement(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	mocktest.Succeed(t, stub, r, "create_agreement", agreementArgs("AGR1")...)
+	if stub.Message() != "Agreement created succcessfully" {
+		t.Errorf("create_agreement event: %s", stub.LastEvent().Payload)
+	}
+	res := getAgreement(t, r, stub, "AGR1")
+	if res.TransID != "PO1" || res.Total_Value != "2500" || res.Shipping_status != "Not Shipped" || res.Clearance_status != "" {
+		t.Errorf("created Agreement: %+v", res)
+	}
+	if string(stub.State[AgreementIndexStr]) != `["AGR1"]` {
+		t.Errorf("Agreement index: %s", stub.State[AgreementIndexStr])
+	}
+}
+
+func TestCreateAgreementDuplicate(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	mocktest.Succeed(t, stub, r, "create_agreement", agreementArgs("AGR1")...)
+	mocktest.Reject(t, stub, r, "create_agreement", "This Agreement already exists.", agreementArgs("AGR1")...)
+}
+
+func TestCreateAgreementLinkedPO(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	args := agreementArgs("AGR1")
+	args[1] = "PO9"
+	mocktest.Reject(t, stub, r, "create_agreement", "PO PO9 Not Found", args...)
+
+	args = agreementArgs("AGR1")
+	args[agreementField["buyer_name"]] = "Otherbuy"
+	mocktest.Reject(t, stub, r, "create_agreement", "Buyer and seller do not match PO PO1", args...)
+
+	mocktest.Succeed(t, stub, r, "update_po", "PO1", "Sellerco", "Buyerco", "2024-03-01", "2024-01-15", "Rejected", "ITM-1", "Rice",
+		"100", "25", "true", "false", "Out of stock")
+	mocktest.Reject(t, stub, r, "create_agreement", "PO PO1 is Rejected", agreementArgs("AGR1")...)
+	if stub.State[agreementKey("AGR1")] != nil {
+		t.Errorf("rejected Agreement was stored")
+	}
+}
+
+func TestFraudList(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	mocktest.Succeed(t, stub, r, "update_fraud_list", "F1", "Fraudco")
+	if stub.Message() != "Fraud ID added succcessfully" {
+		t.Errorf("update_fraud_list event: %s", stub.LastEvent().Payload)
+	}
+	mocktest.Reject(t, stub, r, "update_fraud_list", "This Fraud Name already exists.", "F1", "Fraudco")
+	frauds := map[string]Fraud_list{}
+	if err := json.Unmarshal(mocktest.Succeed(t, stub, r, "get_fraud_list", " "), &frauds); err != nil || frauds["F1"].FraudName != "Fraudco" {
+		t.Errorf("get_fraud_list: %v %v", frauds, err)
+	}
+}
+
+func TestCreateAgreementFraudRejected(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	mocktest.Succeed(t, stub, r, "update_fraud_list", "F1", "Z")
+	args := agreementArgs("AGR1")
+	args[agreementField["buyer_name"]] = "Z"
+	mocktest.Reject(t, stub, r, "create_agreement", "Buyer name exists in Fraud list", args...)
+	if valAsBytes := mocktest.Succeed(t, stub, r, "get_fraud_details", "Z"); !strings.Contains(string(valAsBytes), `"F1":`) {
+		t.Errorf("get_fraud_details: %s", valAsBytes)
+	}
+}
+
+func TestUpdateAgreementAutoApproval(t *testing.T) {
+	cases := []struct {
+		name string
+		set map[string]string
+		status string
+		buyerBank string
+		sellerBank string
+	}{
+		{"no signatures", map[string]string{}, "Created", "false", "false"},
+		{"small books trade", map[string]string{"industry": "Books", "total_value": "10000"}, "Created", "true", "true"},
+		{"small books trade signed by seller", map[string]string{"industry": "Books", "total_value": "5000", "seller_sign": "true"}, "Approved By Seller Bank", "true", "true"},
+		{"large mobiles trade", map[string]string{"industry": "Mobiles & Tablets", "total_value": "10001"}, "Created", "false", "true"},
+		{"small food trade", map[string]string{"total_value": "500"}, "Created", "false", "false"},
+		{"buyer bank", map[string]string{"buyerBank_sign": "true"}, "Approved By Buyer Bank", "true", "false"},
+		{"seller", map[string]string{"buyerBank_sign": "true", "seller_sign": "true"}, "Approved By Seller", "true", "false"},
+		{"seller bank", map[string]string{"buyerBank_sign": "true", "seller_sign": "true", "sellerBank_sign": "true"}, "Approved By Seller Bank", "true", "true"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r, stub := newTradeFinance(t)
+			mocktest.Succeed(t, stub, r, "create_agreement", agreementArgs("AGR1")...)
+			args := agreementArgs("AGR1")
+			for field, value := range c.set {
+				args[agreementField[field]] = value
+			}
+			mocktest.Succeed(t, stub, r, "update_agreement", args...)
+			if stub.Message() != "Agreement updated succcessfully" {
+				t.Errorf("update_agreement event: %s", stub.LastEvent().Payload)
+			}
+			res := getAgreement(t, r, stub, "AGR1")
+			if res.Agreement_status != c.status || res.BuyerBank_sign != c.buyerBank || res.SellerBank_sign != c.sellerBank {
+				t.Errorf("status %q buyer bank %q seller bank %q", res.Agreement_status, res.BuyerBank_sign, res.SellerBank_sign)
+			}
+		})
+	}
+}
+
+func TestUpdateAgreementErrors(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	mocktest.Reject(t, stub, r, "update_agreement", "AGR9 Not Found.", agreementArgs("AGR9")...)
+	mocktest.Succeed(t, stub, r, "create_agreement", agreementArgs("AGR1")...)
+
+	args := agreementArgs("AGR1")
+	args[agreementField["total_value"]] = "a lot"
+	mocktest.Reject(t, stub, r, "update_agreement", "Error while converting string 'total_value' to int", args...)
+
+	args = agreementArgs("AGR1")
+	args[1] = "PO9"
+	mocktest.Reject(t, stub, r, "update_agreement", "PO PO9 Not Found", args...)
+	if res := getAgreement(t, r, stub, "AGR1"); res.TransID != "PO1" {
+		t.Errorf("rejected update changed the Agreement: %+v", res)
+	}
+}
+
+func TestUpdateAgreementVersionConflict(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	mocktest.Succeed(t, stub, r, "create_agreement", agreementArgs("AGR1")...)
+	if res := getAgreement(t, r, stub, "AGR1"); res.Version != 1 || res.LastModifiedTxID == "" {
+		t.Fatalf("created Agreement: version %d tx %q", res.Version, res.LastModifiedTxID)
+	}
+
+	//two clerks read version 1, the first update wins
+	args := agreementArgs("AGR1")
+	args[agreementField["buyerBank_sign"]] = "true"
+	mocktest.Succeed(t, stub, r, "update_agreement", append(args, "1")...)
+	txID := stub.TxID
+	event := struct {
+		Version int `json:"version"`
+	}{}
+	if json.Unmarshal(stub.LastEvent().Payload, &event); event.Version != 2 {
+		t.Errorf("update_agreement event: %s", stub.LastEvent().Payload)
+	}
+	args = agreementArgs("AGR1")
+	args[agreementField["seller_sign"]] = "true"
+	mocktest.Reject(t, stub, r, "update_agreement", "version conflict on AGR1: expected version 1 but it is at version 2", append(args, "1")...)
+	if !strings.Contains(string(stub.LastEvent().Payload), `"409"`) {
+		t.Errorf("conflict event: %s", stub.LastEvent().Payload)
+	}
+	mocktest.Reject(t, stub, r, "update_agreement", "version conflict on AGR1", `{"agreementId": "AGR1", "seller_sign": "true", "version": 1}`)
+	mocktest.Reject(t, stub, r, "update_agreement", "version must be a number", append(args, "two")...)
+	res := getAgreement(t, r, stub, "AGR1")
+	if res.Version != 2 || res.LastModifiedTxID != txID || res.BuyerBank_sign != "true" || res.Seller_sign != "false" {
+		t.Errorf("Agreement after the conflict: %+v", res)
+	}
+
+	//without an expected version the update is not checked
+	mocktest.Succeed(t, stub, r, "update_agreement", `{"agreementId": "AGR1", "seller_sign": "true"}`)
+	mocktest.Succeed(t, stub, r, "register_party", "Portauth", "PortMSP")
+	mocktest.ActAs(t, stub, "PortMSP", "officer")
+	mocktest.Succeed(t, stub, r, "update_clearance_status", "AGR1", "SHP1", "On Hold")
+	if res = getAgreement(t, r, stub, "AGR1"); res.Version != 4 || res.Seller_sign != "true" || res.BuyerBank_sign != "true" {
+		t.Errorf("Agreement after the unchecked updates: %+v", res)
+	}
+}
+
+func TestDeleteAgreement(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	mocktest.Succeed(t, stub, r, "create_agreement", agreementArgs("AGR1")...)
+	mocktest.Succeed(t, stub, r, "create_agreement", agreementArgs("AGR2")...)
+	mocktest.Succeed(t, stub, r, "delete_agreement", "AGR1")
+	if stub.Message() != "Agreement deleted succcessfully" {
+		t.Errorf("delete_agreement event: %s", stub.LastEvent().Payload)
+	}
+	if valAsBytes := mocktest.Succeed(t, stub, r, "getAgreement_byID", "AGR1"); len(valAsBytes) != 0 {
+		t.Errorf("deleted Agreement still readable: %s", valAsBytes)
+	}
+	if string(stub.State[AgreementIndexStr]) != `["AGR2"]` {
+		t.Errorf("Agreement index after delete: %s", stub.State[AgreementIndexStr])
+	}
+}
+
+func TestGetAgreementLists(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	mocktest.Succeed(t, stub, r, "create_agreement", agreementArgs("AGR1")...)
+	all := map[string]Agreement{}
+	if err := json.Unmarshal(mocktest.Succeed(t, stub, r, "get_AllAgreement", " "), &all); err != nil || len(all) != 1 {
+		t.Errorf("get_AllAgreement: %v %v", all, err)
+	}
+	for function, name := range map[string]string{"getAgreement_byBuyer": "Buyerco", "getAgreement_bySeller": "Sellerco",
+		"getAgreement_byShipper": "Shipco", "getAgreement_byBuyerBank": "Buybank", "getAgreement_bySellerBank": "Sellbank",
+		"getAgreement_byPortAuthority": "Portauth"} {
+		if valAsBytes := mocktest.Succeed(t, stub, r, function, name); !strings.Contains(string(valAsBytes), `"AGR1":`) {
+			t.Errorf("%s(%s): %s", function, name, valAsBytes)
+		}
+		mocktest.Reject(t, stub, r, function, "Nobody Not Found.", "Nobody")
+	}
+}
+
+func TestGetApprovalStatus(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	mocktest.Succeed(t, stub, r, "create_agreement", agreementArgs("AGR1")...)
+	for user, expected := range map[string]string{"Sellerco": `"Seller_sign":"false"`, "Buyerco": `"Buyer_sign":"true"`,
+		"Buybank": `"BuyerBank_sign":"false"`, "Sellbank": `"SellerBank_sign":"false"`} {
+		if valAsBytes := mocktest.Succeed(t, stub, r, "getApprovalStatus", user, "AGR1"); !strings.Contains(string(valAsBytes), expected) {
+			t.Errorf("getApprovalStatus(%s): %s", user, valAsBytes)
+		}
+	}
+	mocktest.Reject(t, stub, r, "getApprovalStatus", "Nobody Not Found.", "Nobody", "AGR1")
+}
+
+func TestUpdateClearanceStatus(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	mocktest.Reject(t, stub, r, "update_clearance_status", "AGR9 Not Found.", "AGR9", "SHP1", "Cleared")
+	mocktest.Succeed(t, stub, r, "create_agreement", agreementArgs("AGR1")...)
+	mocktest.Succeed(t, stub, r, "register_party", "Portauth", "PortMSP")
+
+	//only the port authority of the Agreement records a clearance directly, other callers go through the shipment domain
+	mocktest.ActAs(t, stub, "ShipMSP", "clerk")
+	mocktest.Reject(t, stub, r, "update_clearance_status", "Only the shipment chaincode or the port authority updates the clearance status",
+		"AGR1", "SHP1", "Cleared")
+	mocktest.ActAs(t, stub, "PortMSP", "officer")
+	mocktest.Succeed(t, stub, r, "update_clearance_status", "AGR1", "SHP1", "On Hold")
+	if res := getAgreement(t, r, stub, "AGR1"); res.Clearance_shipment != "SHP1" || res.Clearance_status != "On Hold" {
+		t.Errorf("clearance of the Agreement: %+v", res)
+	}
+}
+
+func TestLiquidatedDamages(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	mocktest.Reject(t, stub, r, "set_liquidated_damages", "AGR9 Not Found.", "AGR9", "0.5", "10", "2")
+	mocktest.Succeed(t, stub, r, "create_agreement", agreementArgs("AGR1")...)
+	mocktest.Reject(t, stub, r, "set_liquidated_damages", "must be non-negative numbers", "AGR1", "-1", "10", "2")
+	if valAsBytes := mocktest.Succeed(t, stub, r, "get_liquidated_damages", "AGR1"); len(valAsBytes) != 0 {
+		t.Errorf("get_liquidated_damages before they are set: %s", valAsBytes)
+	}
+	mocktest.Succeed(t, stub, r, "set_liquidated_damages", "AGR1", "0.5", "10", "2")
+	res := LiquidatedDamages{}
+	json.Unmarshal(mocktest.Succeed(t, stub, r, "get_liquidated_damages", "AGR1"), &res)
+	if res != (LiquidatedDamages{AgreementID: "AGR1", RatePerDay: "0.5", CapPercent: "10", GraceDays: "2"}) {
+		t.Errorf("get_liquidated_damages: %+v", res)
+	}
+}
+
+func TestRecordShippedQuantity(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	mocktest.Reject(t, stub, r, "record_shipped_quantity", "AGR9 Not Found.", "AGR9", "SHP1", `[{"item_id":"ITM-1","quantity":"10"}]`)
+	mocktest.Succeed(t, stub, r, "create_agreement", agreementArgs("AGR1")...)
+	mocktest.Reject(t, stub, r, "record_shipped_quantity", "non-empty JSON array", "AGR1", "SHP1", "[]")
+
+	//only a shipment, through the shipment domain, or the admin books quantities
+	mocktest.ActAs(t, stub, "MalloryMSP", "mallory")
+	mocktest.Reject(t, stub, r, "record_shipped_quantity", "Only the shipment chaincode or the admin MSP books shipped quantities",
+		"AGR1", "SHP1", `[{"item_id":"ITM-1","quantity":"100"}]`)
+	mocktest.Reject(t, stub, r, "release_shipped_quantity", "Only the shipment chaincode or the admin MSP releases shipped quantities",
+		"AGR1", "SHP1", `[{"item_id":"ITM-1","quantity":"100"}]`)
+	stub.Creator = nil
+
+	mocktest.Reject(t, stub, r, "record_shipped_quantity", "Item ITM-9 is not ordered", "AGR1", "SHP1", `[{"item_id":"ITM-9","quantity":"10"}]`)
+	mocktest.Reject(t, stub, r, "record_shipped_quantity", "must be a positive number", "AGR1", "SHP1", `[{"item_id":"ITM-1","quantity":"0"}]`)
+
+	mocktest.Succeed(t, stub, r, "record_shipped_quantity", "AGR1", "SHP1", `[{"item_id":"ITM-1","quantity":"40"}]`)
+	if res := getAgreement(t, r, stub, "AGR1"); res.Shipping_status != "Partially Shipped" {
+		t.Errorf("shipping status after a partial shipment: %s", res.Shipping_status)
+	}
+	mocktest.Reject(t, stub, r, "record_shipped_quantity", "already booked", "AGR1", "SHP1", `[{"item_id":"ITM-1","quantity":"10"}]`)
+	mocktest.Reject(t, stub, r, "record_shipped_quantity", "Over-shipment of item ITM-1", "AGR1", "SHP2", `[{"item_id":"ITM-1","quantity":"61"}]`)
+	mocktest.Succeed(t, stub, r, "record_shipped_quantity", "AGR1", "SHP2", `[{"item_id":"ITM-1","quantity":"60"}]`)
+	if res := getAgreement(t, r, stub, "AGR1"); res.Shipping_status != "Fully Shipped" {
+		t.Errorf("shipping status after the last shipment: %s", res.Shipping_status)
+	}
+
+	balance := ShippedBalance{}
+	json.Unmarshal(mocktest.Succeed(t, stub, r, "get_shipped_balance", "AGR1"), &balance)
+	if len(balance.Lines) != 1 || balance.Lines[0].Shipped != "100" || balance.Lines[0].Remaining != "0" || len(balance.Shipments) != 2 {
+		t.Errorf("get_shipped_balance: %+v", balance)
+	}
+	mocktest.Reject(t, stub, r, "get_shipped_balance", "AGR9 Not Found.", "AGR9")
+
+	mocktest.Reject(t, stub, r, "release_shipped_quantity", "SHP9 is not booked", "AGR1", "SHP9", `[{"item_id":"ITM-1","quantity":"10"}]`)
+	mocktest.Succeed(t, stub, r, "release_shipped_quantity", "AGR1", "SHP2", `[{"item_id":"ITM-1","quantity":"60"}]`)
+	if res := getAgreement(t, r, stub, "AGR1"); res.Shipping_status != "Partially Shipped" {
+		t.Errorf("shipping status after a shipment is released: %s", res.Shipping_status)
+	}
+	mocktest.Reject(t, stub, r, "release_shipped_quantity", "SHP2 is not booked", "AGR1", "SHP2", `[{"item_id":"ITM-1","quantity":"60"}]`)
+	mocktest.Succeed(t, stub, r, "release_shipped_quantity", "AGR1", "SHP1", `[{"item_id":"ITM-1","quantity":"40"}]`)
+	balance = ShippedBalance{}
+	json.Unmarshal(mocktest.Succeed(t, stub, r, "get_shipped_balance", "AGR1"), &balance)
+	if balance.Lines[0].Shipped != "0" || balance.Lines[0].Remaining != "100" || len(balance.Shipments) != 0 {
+		t.Errorf("get_shipped_balance after the release: %+v", balance)
+	}
+	if res := getAgreement(t, r, stub, "AGR1"); res.Shipping_status != "Not Shipped" {
+		t.Errorf("shipping status after every shipment is released: %s", res.Shipping_status)
+	}
+}
+
+func TestGetTradeRecord(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	mocktest.Reject(t, stub, r, "get_trade_record", "AGR9 Not Found.", "AGR9")
+	mocktest.Succeed(t, stub, r, "create_agreement", agreementArgs("AGR1")...)
+	record := struct {
+		Agreement Agreement `json:"agreement"`
+		PO po.PO `json:"po"`
+		Payments []json.RawMessage `json:"payments"`
+		Shipments []json.RawMessage `json:"shipments"`
+		ShippedBalance ShippedBalance `json:"shipped_balance"`
+	}{}
+	if err := json.Unmarshal(mocktest.Succeed(t, stub, r, "get_trade_record", "AGR1"), &record); err != nil {
+		t.Fatalf("get_trade_record: %s", err)
+	}
+	if record.Agreement.AgreementID != "AGR1" || record.PO.TransID != "PO1" || len(record.Payments) != 0 ||
+		len(record.Shipments) != 0 || record.ShippedBalance.Lines[0].Remaining != "100" {
+		t.Errorf("get_trade_record: %+v", record)
+	}
+}
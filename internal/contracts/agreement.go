@@ -0,0 +1,248 @@
+package contracts
+
+import (
+"encoding/json"
+
+"github.com/hyperledger/fabric-contract-api-go/contractapi"
+"github.com/wipro-blockchain/TF-v1/internal/agreement"
+"github.com/wipro-blockchain/TF-v1/internal/payment"
+"github.com/wipro-blockchain/TF-v1/internal/po"
+"github.com/wipro-blockchain/TF-v1/internal/router"
+"github.com/wipro-blockchain/TF-v1/internal/shipment"
+)
+
+// AgreementContract is the typed contract of the Agreement domain
+type AgreementContract struct {
+	contractapi.Contract
+	base
+}
+
+// TradeRecord is an Agreement with its PO, payments, shipments and shipped balance, a part is null when its chaincode is not registered
+type TradeRecord struct {
+	Agreement *agreement.Agreement `json:"agreement"`
+	PO *po.PO `json:"po" metadata:",optional"`
+	Payments []*payment.Payment `json:"payments" metadata:",optional"`
+	Shipments []*shipment.Shipment `json:"shipments" metadata:",optional"`
+	ShippedBalance agreement.ShippedBalance `json:"shipped_balance"`
+}
+
+// ============================================================================================================================
+// NewAgreementContract - the Agreement contract of a router with the agreement domain registered
+// ============================================================================================================================
+func NewAgreementContract(r *router.Router) *AgreementContract {
+	c := &AgreementContract{base: base{router: r}}
+	c.Name = "Agreement"
+	c.Info = info("Agreement", "Trade agreements signed by the buyer, seller and their banks")
+	c.TransactionContextHandler = new(TransactionContext)
+	return c
+}
+// ============================================================================================================================
+// GetEvaluateTransactions - the methods that only read the state
+// ============================================================================================================================
+func (c *AgreementContract) GetEvaluateTransactions() []string {
+	return []string{"GetAgreement", "GetAgreementsByBuyer", "GetAgreementsBySeller", "GetAgreementsByShipper",
+		"GetAgreementsByBuyerBank", "GetAgreementsBySellerBank", "GetAgreementsByPortAuthority", "GetAllAgreements",
+		"GetFraudList", "GetFraudDetails", "GetApprovalStatus", "GetLiquidatedDamages", "GetShippedBalance", "GetTradeRecord"}
+}
+// ============================================================================================================================
+// agreementArgs - the positional arguments of create_agreement and update_agreement
+// ============================================================================================================================
+func agreementArgs(record agreement.Agreement) []string {
+	return []string{record.AgreementID, record.TransID, record.Agreement_status, record.BuyerName, record.SellerName,
+		record.ShipperName, record.BB_name, record.SB_name, record.PortAuthName, record.AgreementCU_date, record.ItemId,
+		record.Item_name, record.Item_quantity, record.Total_Value, record.Delivery_date, record.ExtraCharges,
+		record.Shipper_fees, record.DocumentName, record.DocumentURL, record.TC_Text, record.Buyer_sign,
+		record.BuyerBank_sign, record.Seller_sign, record.SellerBank_sign, record.Industry, record.GoodsPrice}
+}
+// ============================================================================================================================
+// CreateAgreement - create an Agreement, the clearance and shipping status are set by the chaincode. Without an agreementId
+// the chaincode generates one
+// ============================================================================================================================
+func (c *AgreementContract) CreateAgreement(ctx contractapi.TransactionContextInterface, record agreement.Agreement) (*agreement.Agreement, error) {
+	agreementId, err := c.create(ctx, "create_agreement", agreementArgs(record)...)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetAgreement(ctx, agreementId)
+}
+// ============================================================================================================================
+// UpdateAgreement - replace an Agreement, refused when record has a version and the Agreement changed since
+// ============================================================================================================================
+func (c *AgreementContract) UpdateAgreement(ctx contractapi.TransactionContextInterface, record agreement.Agreement) (*agreement.Agreement, error) {
+	if err := c.invoke(ctx, "update_agreement", router.WithVersion(agreementArgs(record), record.Version)...); err != nil {
+		return nil, err
+	}
+	return c.GetAgreement(ctx, record.AgreementID)
+}
+// ============================================================================================================================
+// DeleteAgreement - delete an Agreement
+// ============================================================================================================================
+func (c *AgreementContract) DeleteAgreement(ctx contractapi.TransactionContextInterface, agreementId string) error {
+	return c.invoke(ctx, "delete_agreement", agreementId)
+}
+// ============================================================================================================================
+// AddFraud - add a party to the fraud list
+// ============================================================================================================================
+func (c *AgreementContract) AddFraud(ctx contractapi.TransactionContextInterface, fraudId string, fraudName string) error {
+	return c.invoke(ctx, "update_fraud_list", fraudId, fraudName)
+}
+// ============================================================================================================================
+// UpdateClearanceStatus - mirror the port clearance status of a shipment on its Agreement
+// ============================================================================================================================
+func (c *AgreementContract) UpdateClearanceStatus(ctx contractapi.TransactionContextInterface, agreementId string, shipmentId string, clearanceStatus string) (*agreement.Agreement, error) {
+	if err := c.invoke(ctx, "update_clearance_status", agreementId, shipmentId, clearanceStatus); err != nil {
+		return nil, err
+	}
+	return c.GetAgreement(ctx, agreementId)
+}
+// ============================================================================================================================
+// SetLiquidatedDamages - set the late delivery terms of an Agreement
+// ============================================================================================================================
+func (c *AgreementContract) SetLiquidatedDamages(ctx contractapi.TransactionContextInterface, terms agreement.LiquidatedDamages) (*agreement.LiquidatedDamages, error) {
+	if err := c.invoke(ctx, "set_liquidated_damages", terms.AgreementID, terms.RatePerDay, terms.CapPercent, terms.GraceDays); err != nil {
+		return nil, err
+	}
+	return c.GetLiquidatedDamages(ctx, terms.AgreementID)
+}
+// ============================================================================================================================
+// RecordShippedQuantity - book the items of a shipment against the ordered quantity of an Agreement
+// ============================================================================================================================
+func (c *AgreementContract) RecordShippedQuantity(ctx contractapi.TransactionContextInterface, agreementId string, shipmentId string, items []agreement.ShipmentItem) (*agreement.ShippedBalance, error) {
+	itemsAsBytes, _ := json.Marshal(items)
+	if err := c.invoke(ctx, "record_shipped_quantity", agreementId, shipmentId, string(itemsAsBytes)); err != nil {
+		return nil, err
+	}
+	return c.GetShippedBalance(ctx, agreementId)
+}
+// ============================================================================================================================
+// ReleaseShippedQuantity - give the items of a deleted shipment back to the ordered quantity of an Agreement
+// ============================================================================================================================
+func (c *AgreementContract) ReleaseShippedQuantity(ctx contractapi.TransactionContextInterface, agreementId string, shipmentId string, items []agreement.ShipmentItem) (*agreement.ShippedBalance, error) {
+	itemsAsBytes, _ := json.Marshal(items)
+	if err := c.invoke(ctx, "release_shipped_quantity", agreementId, shipmentId, string(itemsAsBytes)); err != nil {
+		return nil, err
+	}
+	return c.GetShippedBalance(ctx, agreementId)
+}
+// ============================================================================================================================
+// GetAgreement - an Agreement by agreementId
+// ============================================================================================================================
+func (c *AgreementContract) GetAgreement(ctx contractapi.TransactionContextInterface, agreementId string) (*agreement.Agreement, error) {
+	record := &agreement.Agreement{}
+	if err := c.query(ctx, record, "getAgreement_byID", agreementId); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+// ============================================================================================================================
+// agreements - the Agreements returned by a list query
+// ============================================================================================================================
+func (c *AgreementContract) agreements(ctx contractapi.TransactionContextInterface, function string, arg string) ([]*agreement.Agreement, error) {
+	var records []*agreement.Agreement
+	if err := c.queryList(ctx, &records, function, arg); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+// ============================================================================================================================
+// GetAgreementsByBuyer - the Agreements of a buyer
+// ============================================================================================================================
+func (c *AgreementContract) GetAgreementsByBuyer(ctx contractapi.TransactionContextInterface, buyerName string) ([]*agreement.Agreement, error) {
+	return c.agreements(ctx, "getAgreement_byBuyer", buyerName)
+}
+// ============================================================================================================================
+// GetAgreementsBySeller - the Agreements of a seller
+// ============================================================================================================================
+func (c *AgreementContract) GetAgreementsBySeller(ctx contractapi.TransactionContextInterface, sellerName string) ([]*agreement.Agreement, error) {
+	return c.agreements(ctx, "getAgreement_bySeller", sellerName)
+}
+// ============================================================================================================================
+// GetAgreementsByShipper - the Agreements of a shipper
+// ============================================================================================================================
+func (c *AgreementContract) GetAgreementsByShipper(ctx contractapi.TransactionContextInterface, shipperName string) ([]*agreement.Agreement, error) {
+	return c.agreements(ctx, "getAgreement_byShipper", shipperName)
+}
+// ============================================================================================================================
+// GetAgreementsByBuyerBank - the Agreements of a buyer bank
+// ============================================================================================================================
+func (c *AgreementContract) GetAgreementsByBuyerBank(ctx contractapi.TransactionContextInterface, bankName string) ([]*agreement.Agreement, error) {
+	return c.agreements(ctx, "getAgreement_byBuyerBank", bankName)
+}
+// ============================================================================================================================
+// GetAgreementsBySellerBank - the Agreements of a seller bank
+// ============================================================================================================================
+func (c *AgreementContract) GetAgreementsBySellerBank(ctx contractapi.TransactionContextInterface, bankName string) ([]*agreement.Agreement, error) {
+	return c.agreements(ctx, "getAgreement_bySellerBank", bankName)
+}
+// ============================================================================================================================
+// GetAgreementsByPortAuthority - the Agreements of a port authority
+// ============================================================================================================================
+func (c *AgreementContract) GetAgreementsByPortAuthority(ctx contractapi.TransactionContextInterface, portAuthName string) ([]*agreement.Agreement, error) {
+	return c.agreements(ctx, "getAgreement_byPortAuthority", portAuthName)
+}
+// ============================================================================================================================
+// GetAllAgreements - every Agreement
+// ============================================================================================================================
+func (c *AgreementContract) GetAllAgreements(ctx contractapi.TransactionContextInterface) ([]*agreement.Agreement, error) {
+	return c.agreements(ctx, "get_AllAgreement", " ")
+}
+// ============================================================================================================================
+// GetFraudList - every party on the fraud list
+// ============================================================================================================================
+func (c *AgreementContract) GetFraudList(ctx contractapi.TransactionContextInterface) ([]*agreement.Fraud_list, error) {
+	var records []*agreement.Fraud_list
+	if err := c.queryList(ctx, &records, "get_fraud_list", " "); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+// ============================================================================================================================
+// GetFraudDetails - the fraud list entries of a party
+// ============================================================================================================================
+func (c *AgreementContract) GetFraudDetails(ctx contractapi.TransactionContextInterface, fraudName string) ([]*agreement.Fraud_list, error) {
+	var records []*agreement.Fraud_list
+	if err := c.queryList(ctx, &records, "get_fraud_details", fraudName); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+// ============================================================================================================================
+// GetApprovalStatus - the signature of a party on an Agreement, keyed by agreementId and the party's sign field
+// ============================================================================================================================
+func (c *AgreementContract) GetApprovalStatus(ctx contractapi.TransactionContextInterface, user string, agreementId string) (map[string]string, error) {
+	status := map[string]string{}
+	if err := c.query(ctx, &status, "getApprovalStatus", user, agreementId); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+// ============================================================================================================================
+// GetLiquidatedDamages - the late delivery terms of an Agreement
+// ============================================================================================================================
+func (c *AgreementContract) GetLiquidatedDamages(ctx contractapi.TransactionContextInterface, agreementId string) (*agreement.LiquidatedDamages, error) {
+	terms := &agreement.LiquidatedDamages{}
+	if err := c.query(ctx, terms, "get_liquidated_damages", agreementId); err != nil {
+		return nil, err
+	}
+	return terms, nil
+}
+// ============================================================================================================================
+// GetShippedBalance - shipped versus ordered quantity of every line of an Agreement
+// ============================================================================================================================
+func (c *AgreementContract) GetShippedBalance(ctx contractapi.TransactionContextInterface, agreementId string) (*agreement.ShippedBalance, error) {
+	balance := &agreement.ShippedBalance{}
+	if err := c.query(ctx, balance, "get_shipped_balance", agreementId); err != nil {
+		return nil, err
+	}
+	return balance, nil
+}
+// ============================================================================================================================
+// GetTradeRecord - an Agreement with its PO, payments, shipments and shipped balance
+// ============================================================================================================================
+func (c *AgreementContract) GetTradeRecord(ctx contractapi.TransactionContextInterface, agreementId string) (*TradeRecord, error) {
+	record := &TradeRecord{}
+	if err := c.query(ctx, record, "get_trade_record", agreementId); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
@@ -0,0 +1,213 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements.  See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership.  The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License.  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+// Package contracts serves a router.Router through the Fabric 2.x contract API. Every registered domain
+// gets a typed contract (PO, Agreement, Payment, Shipment) whose methods take and return the domain
+// records, and the default Legacy contract keeps the original function names, e.g. create_po or
+// getAgreement_byID, working for existing clients.
+package contracts
+
+import (
+"errors"
+"fmt"
+"sort"
+"strings"
+"encoding/json"
+
+"github.com/hyperledger/fabric-chaincode-go/shim"
+"github.com/hyperledger/fabric-contract-api-go/contractapi"
+"github.com/hyperledger/fabric-contract-api-go/metadata"
+"github.com/wipro-blockchain/TF-v1/internal/router"
+)
+
+var Version = "2.0.0"				//version reported in the contract metadata
+
+// base runs the functions of a router for a contract, only transaction methods may be exported
+type base struct {
+	router *router.Router
+}
+
+// TransactionContext is the context of every contract method: its stub reads back what the method wrote, e.g. CreatePO
+// reads the PO it created, as a peer only reads the state committed before the transaction
+type TransactionContext struct {
+	contractapi.TransactionContext
+	cache *router.Cache
+}
+
+// ============================================================================================================================
+// SetStub - set the stub of the transaction, read through a router.Cache
+// ============================================================================================================================
+func (ctx *TransactionContext) SetStub(stub shim.ChaincodeStubInterface) {
+	ctx.TransactionContext.SetStub(stub)
+	ctx.cache = router.NewCache(stub)
+}
+// ============================================================================================================================
+// GetStub - the stub of the transaction, reading back its writes
+// ============================================================================================================================
+func (ctx *TransactionContext) GetStub() shim.ChaincodeStubInterface {
+	return ctx.cache
+}
+
+// ============================================================================================================================
+// New - a chaincode serving r, the Legacy contract is the default followed by a typed contract per registered domain
+// ============================================================================================================================
+func New(r *router.Router) (*contractapi.ContractChaincode, error) {
+	legacy := &LegacyContract{base: base{router: r}}
+	legacy.Name = "Legacy"
+	legacy.Info = info(r.Name() + " original functions", "Functions of " + r.Name() + " by their original names, e.g. create_po")
+	legacy.UnknownTransaction = legacy.dispatch
+	legacy.TransactionContextHandler = new(TransactionContext)
+	contracts := []contractapi.ContractInterface{legacy}
+	for _, role := range r.Roles() {
+		switch role {
+		case "po":
+			contracts = append(contracts, NewPOContract(r))
+		case "agreement":
+			contracts = append(contracts, NewAgreementContract(r))
+		case "payment":
+			contracts = append(contracts, NewPaymentContract(r))
+		case "shipment":
+			contracts = append(contracts, NewShipmentContract(r))
+		default:
+			return nil, errors.New("contracts: no typed contract for role " + role)
+		}
+	}
+	cc, err := contractapi.NewChaincode(contracts...)
+	if err != nil {
+		return nil, err
+	}
+	cc.DefaultContract = legacy.Name
+	cc.Info = info(r.Name(), "Trade-finance chaincode " + r.Name())
+	return cc, nil
+}
+// ============================================================================================================================
+// info - contract metadata with the common version and license
+// ============================================================================================================================
+func info(title string, description string) metadata.InfoMetadata {
+	return metadata.InfoMetadata{
+		Title: title,
+		Description: description,
+		Version: Version,
+		License: &metadata.LicenseMetadata{Name: "Apache-2.0", URL: "http://www.apache.org/licenses/LICENSE-2.0"},
+	}
+}
+// ============================================================================================================================
+// invoke - run an invoke function by its original name, an errEvent becomes an error and rejects the transaction
+// ============================================================================================================================
+func (b *base) invoke(ctx contractapi.TransactionContextInterface, function string, args ...string) error {
+	_, err := b.invokeBytes(ctx, function, args...)
+	return err
+}
+// ============================================================================================================================
+// create - run a create function by its original name and return the ID of the record: the first argument, or the ID the
+// chaincode generated and answered with when that is empty
+// ============================================================================================================================
+func (b *base) create(ctx contractapi.TransactionContextInterface, function string, args ...string) (string, error) {
+	valAsBytes, err := b.invokeBytes(ctx, function, args...)
+	if err != nil {
+		return "", err
+	}
+	if id := strings.TrimSpace(string(valAsBytes)); id != "" {
+		return id, nil
+	}
+	return args[0], nil
+}
+// ============================================================================================================================
+// invokeBytes - run an invoke function by its original name and return its response
+// ============================================================================================================================
+func (b *base) invokeBytes(ctx contractapi.TransactionContextInterface, function string, args ...string) ([]byte, error) {
+	stub := ctx.GetStub()
+	recorder := router.NewRecorder(stub)
+	valAsBytes, err := b.router.Invoke(recorder, function, args)
+	if err == nil {
+		err = recorder.Failure()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return valAsBytes, recorder.Replay(stub)
+}
+// ============================================================================================================================
+// query - run a query function by its original name and decode its response into v
+// ============================================================================================================================
+func (b *base) query(ctx contractapi.TransactionContextInterface, v interface{}, function string, args ...string) error {
+	valAsBytes, err := b.queryBytes(ctx, function, args...)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(valAsBytes, v)
+}
+// ============================================================================================================================
+// queryBytes - run a query function by its original name, an empty response means the record was not found
+// ============================================================================================================================
+func (b *base) queryBytes(ctx contractapi.TransactionContextInterface, function string, args ...string) ([]byte, error) {
+	recorder := router.NewRecorder(ctx.GetStub())
+	valAsBytes, err := b.router.Query(recorder, function, args)
+	if err == nil {
+		err = recorder.Failure()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(valAsBytes) == 0 {
+		return nil, errors.New(strings.TrimSpace(strings.Join(args, " ")) + " Not Found")
+	}
+	return valAsBytes, nil
+}
+// ============================================================================================================================
+// queryList - run a list query and decode it into a slice v, the by-name queries answer with an object keyed by ID
+// ============================================================================================================================
+func (b *base) queryList(ctx contractapi.TransactionContextInterface, v interface{}, function string, args ...string) error {
+	valAsBytes, err := b.queryBytes(ctx, function, args...)
+	if err != nil {
+		return err
+	}
+	items, err := ListJSON(valAsBytes)
+	if err != nil {
+		return fmt.Errorf("%s returned malformed JSON: %s", function, err.Error())
+	}
+	itemsAsBytes, _ := json.Marshal(items)
+	return json.Unmarshal(itemsAsBytes, v)
+}
+// ============================================================================================================================
+// ListJSON - the records of a list response, either a JSON array or an object keyed by ID in ID order
+// ============================================================================================================================
+func ListJSON(valAsBytes []byte) ([]json.RawMessage, error) {
+	items := []json.RawMessage{}
+	trimmed := strings.TrimSpace(string(valAsBytes))
+	if strings.HasPrefix(trimmed, "[") {
+		err := json.Unmarshal([]byte(trimmed), &items)
+		return items, err
+	}
+	//the by-name queries build their object by hand and can leave a comma before the closing brace
+	trimmed = strings.TrimSuffix(strings.TrimSpace(strings.TrimSuffix(trimmed, "}")), ",") + "}"
+	byID := map[string]json.RawMessage{}
+	if err := json.Unmarshal([]byte(trimmed), &byID); err != nil {
+		return nil, err
+	}
+	var ids []string
+	for id := range byID {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		items = append(items, byID[id])
+	}
+	return items, nil
+}
@@ -0,0 +1,179 @@
+package contracts
+
+import (
+"strings"
+"testing"
+"encoding/json"
+
+"github.com/hyperledger/fabric-chaincode-go/shim"
+"github.com/wipro-blockchain/TF-v1/internal/mockstub"
+"github.com/wipro-blockchain/TF-v1/internal/mocktest"
+"github.com/wipro-blockchain/TF-v1/internal/po"
+"github.com/wipro-blockchain/TF-v1/internal/router"
+)
+
+// legacyChaincode runs the Legacy contract as a mockstub.Chaincode, so calls get their function and arguments
+type legacyChaincode struct {
+	contract *LegacyContract
+}
+
+func (l legacyChaincode) Init(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+	return l.contract.router.Init(stub, function, args)
+}
+
+func (l legacyChaincode) Call(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+	ctx := new(TransactionContext)
+	ctx.SetStub(stub)
+	valAsString, err := l.contract.dispatch(ctx)
+	return []byte(valAsString), err
+}
+
+// transaction runs contract methods as a mockstub.Chaincode, each call in a transaction with a context of its own
+type transaction func(ctx *TransactionContext) error
+
+func (f transaction) Init(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+	return nil, nil
+}
+
+func (f transaction) Call(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+	ctx := new(TransactionContext)
+	ctx.SetStub(stub)
+	return nil, f(ctx)
+}
+
+// run - run f as one transaction on the stub
+func run(stub *mockstub.MockStub, f func(ctx *TransactionContext) error) error {
+	_, err := stub.Call(transaction(f), "contract")
+	return err
+}
+
+// newManagePO - the PO chaincode on an initialized in-memory ledger
+func newManagePO(t *testing.T) (*router.Router, *mockstub.MockStub) {
+	r := router.New("ManagePO")
+	r.Register("po", po.New())
+	stub := mockstub.New("managePO")
+	if _, err := stub.Init(r, " "); err != nil || stub.Failed() {
+		t.Fatalf("init failed: %v %s", err, stub.LastEvent().Payload)
+	}
+	return r, stub
+}
+
+func poRecord(transId string, buyerName string) po.PO {
+	return po.PO{TransID: transId, SellerName: "Sellerco", BuyerName: buyerName, ExpectedDeliveryDate: "2024-03-01",
+		PO_date: "2024-01-15", PO_status: "Created", ItemId: "ITM-1", Item_name: "Rice", Item_quantity: "100", Price: "25",
+		Buyer_sign: "true", Seller_sign: "false"}
+}
+
+func TestListJSON(t *testing.T) {
+	cases := []struct {
+		name string
+		response string
+		expected string
+	}{
+		{"array", `[{"id":"B"},{"id":"A"}]`, `[{"id":"B"},{"id":"A"}]`},
+		{"object in ID order", `{"B":{"id":"B"},"A":{"id":"A"}}`, `[{"id":"A"},{"id":"B"}]`},
+		{"trailing comma", `{"A":{"id":"A"},}`, `[{"id":"A"}]`},
+		{"empty object", `{}`, `[]`},
+	}
+	for _, c := range cases {
+		items, err := ListJSON([]byte(c.response))
+		itemsAsBytes, _ := json.Marshal(items)
+		if err != nil || string(itemsAsBytes) != c.expected {
+			t.Errorf("%s: %s %v", c.name, itemsAsBytes, err)
+		}
+	}
+	if _, err := ListJSON([]byte(`{"A":`)); err == nil {
+		t.Errorf("malformed JSON was accepted")
+	}
+}
+
+func TestNew(t *testing.T) {
+	r := router.New("ManagePO")
+	r.Register("po", po.New())
+	cc, err := New(r)
+	if err != nil || cc.DefaultContract != "Legacy" || cc.Info.Version != Version {
+		t.Errorf("New: %+v %v", cc, err)
+	}
+
+	other := router.New("ManageCustoms")
+	other.Register("customs", po.New())
+	if _, err = New(other); err == nil || err.Error() != "contracts: no typed contract for role customs" {
+		t.Errorf("New with an unknown role: %v", err)
+	}
+}
+
+func TestPOContract(t *testing.T) {
+	r, stub := newManagePO(t)
+	c := NewPOContract(r)
+	var created *po.PO
+	err := run(stub, func(ctx *TransactionContext) (err error) {
+		created, err = c.CreatePO(ctx, poRecord("PO1", "Buyerco"))
+		return err
+	})
+	if err != nil || created.TransID != "PO1" || created.Seller_Remarks != "NA" {
+		t.Fatalf("CreatePO: %+v %v", created, err)
+	}
+	if stub.Message() != "PO created succcessfully" {
+		t.Errorf("CreatePO event: %s", stub.LastEvent().Payload)
+	}
+	err = run(stub, func(ctx *TransactionContext) (err error) {
+		_, err = c.CreatePO(ctx, poRecord("PO1", "Buyerco"))
+		return err
+	})
+	if err == nil || err.Error() != "This PO arleady exists" {
+		t.Errorf("duplicate CreatePO: %v", err)
+	}
+	for _, record := range []po.PO{poRecord("PO2", "Otherbuy"), poRecord("PO3", "Buyerco")} {
+		record := record
+		run(stub, func(ctx *TransactionContext) (err error) {
+			_, err = c.CreatePO(ctx, record)
+			return err
+		})
+	}
+
+	var records []*po.PO
+	err = run(stub, func(ctx *TransactionContext) (err error) {
+		records, err = c.GetPOsByBuyer(ctx, "Buyerco")
+		return err
+	})
+	if err != nil || len(records) != 2 || records[0].TransID != "PO1" || records[1].TransID != "PO3" {
+		t.Errorf("GetPOsByBuyer: %v %v", records, err)
+	}
+	err = run(stub, func(ctx *TransactionContext) (err error) {
+		records, err = c.GetAllPOs(ctx)
+		return err
+	})
+	if err != nil || len(records) != 3 {
+		t.Errorf("GetAllPOs: %v %v", records, err)
+	}
+	if err = run(stub, func(ctx *TransactionContext) error { return c.DeletePO(ctx, "PO1") }); err != nil {
+		t.Errorf("DeletePO: %v", err)
+	}
+	err = run(stub, func(ctx *TransactionContext) (err error) {
+		_, err = c.GetPO(ctx, "PO1")
+		return err
+	})
+	if err == nil || err.Error() != "PO1 Not Found" {
+		t.Errorf("GetPO of a deleted PO: %v", err)
+	}
+}
+
+func TestLegacyDispatch(t *testing.T) {
+	r, stub := newManagePO(t)
+	legacy := legacyChaincode{contract: &LegacyContract{base: base{router: r}}}
+	mocktest.Succeed(t, stub, legacy, "create_po", "PO1", "Sellerco", "Buyerco", "2024-03-01",
+		"2024-01-15", "Created", "ITM-1", "Rice", "100", "25", "true", "false")
+	if valAsBytes := mocktest.Succeed(t, stub, legacy, "Legacy:getPO_byID", "PO1"); !strings.Contains(string(valAsBytes), `"transId"`) {
+		t.Errorf("Legacy:getPO_byID: %s", valAsBytes)
+	}
+	mocktest.Reject(t, stub, legacy, "Legacy:create_pos", "Received unknown function invocation")
+	before := len(stub.State)
+	_, err := stub.Call(legacy, "create_po", "PO1", "Sellerco", "Buyerco", "2024-03-01",
+		"2024-01-15", "Created", "ITM-1", "Rice", "200", "25", "true", "false")
+	if err == nil || stub.Failed() || len(stub.State) != before {
+		t.Errorf("a refused legacy call is committed: %v %s", err, stub.LastEvent().Payload)
+	}
+	if functions := strings.Join(legacy.contract.Functions(nil), ","); !strings.Contains(functions, "create_po,") {
+		t.Errorf("Functions: %s", functions)
+	}
+}
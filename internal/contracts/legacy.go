@@ -0,0 +1,42 @@
+package contracts
+
+import (
+"strings"
+
+"github.com/hyperledger/fabric-contract-api-go/contractapi"
+"github.com/wipro-blockchain/TF-v1/internal/router"
+)
+
+// LegacyContract is the default contract, it answers the original function names, e.g. create_po or
+// getAgreement_byID, with the original arguments, events and responses
+type LegacyContract struct {
+	contractapi.Contract
+	base
+}
+
+// ============================================================================================================================
+// Functions - the original function names this chaincode answers
+// ============================================================================================================================
+func (c *LegacyContract) Functions(ctx contractapi.TransactionContextInterface) []string {
+	return c.router.Functions()
+}
+// ============================================================================================================================
+// dispatch - run a function that no typed contract knows by its original name, e.g. create_po or Legacy:create_po. As in
+// the typed contracts, an errEvent becomes an error and rejects the transaction
+// ============================================================================================================================
+func (c *LegacyContract) dispatch(ctx contractapi.TransactionContextInterface) (string, error) {
+	stub := ctx.GetStub()
+	function, args := stub.GetFunctionAndParameters()
+	if i := strings.Index(function, ":"); i >= 0 {
+		function = function[i+1:]
+	}
+	recorder := router.NewRecorder(stub)
+	valAsBytes, err := c.router.Call(recorder, function, args)
+	if err == nil {
+		err = recorder.Failure()
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(valAsBytes), recorder.Replay(stub)
+}
@@ -0,0 +1,238 @@
+package contracts
+
+import (
+"strconv"
+
+"github.com/hyperledger/fabric-contract-api-go/contractapi"
+"github.com/wipro-blockchain/TF-v1/internal/payment"
+"github.com/wipro-blockchain/TF-v1/internal/router"
+)
+
+// PaymentContract is the typed contract of the Payment domain
+type PaymentContract struct {
+	contractapi.Contract
+	base
+}
+
+// ============================================================================================================================
+// NewPaymentContract - the Payment contract of a router with the payment domain registered
+// ============================================================================================================================
+func NewPaymentContract(r *router.Router) *PaymentContract {
+	c := &PaymentContract{base: base{router: r}}
+	c.Name = "Payment"
+	c.Info = info("Payment", "Payments of an Agreement with escrow, bank reconciliation and ISO 20022 messages")
+	c.TransactionContextHandler = new(TransactionContext)
+	return c
+}
+// ============================================================================================================================
+// GetEvaluateTransactions - the methods that only read the state
+// ============================================================================================================================
+func (c *PaymentContract) GetEvaluateTransactions() []string {
+	return []string{"GetPayment", "GetPaymentsByBuyer", "GetPaymentsBySeller", "GetPaymentsByAgreement", "GetAllPayments",
+		"GetAccountDetails", "GetEscrow", "GetEscrowMovements", "GetReconciliation", "GetReconciliationExceptions",
+		"GetPaymentPain001"}
+}
+// ============================================================================================================================
+// CreatePayment - create a Payment, the accounts are assigned by the chaincode. Without a paymentId the chaincode generates one
+// ============================================================================================================================
+func (c *PaymentContract) CreatePayment(ctx contractapi.TransactionContextInterface, record payment.Payment) (*payment.Payment, error) {
+	paymentId, err := c.create(ctx, "createPayment", record.PaymentID, record.AgreementID, record.BuyerName, record.SellerName,
+		record.AmountTransferred, record.PaymentCUDate, record.PaymentStatus, record.PaymentDeadlineDate,
+		record.BuyerBank_sign, record.BB_name, record.SB_name)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetPayment(ctx, paymentId)
+}
+// ============================================================================================================================
+// UpdatePayment - replace a Payment, refused when record has a version and the Payment changed since
+// ============================================================================================================================
+func (c *PaymentContract) UpdatePayment(ctx contractapi.TransactionContextInterface, record payment.Payment) (*payment.Payment, error) {
+	err := c.invoke(ctx, "updatePayment", router.WithVersion([]string{record.PaymentID, record.AgreementID, record.BuyerName,
+		record.SellerName, record.BuyerAccount, record.SellerAccount, record.AmountTransferred, record.PaymentCUDate,
+		record.PaymentStatus, record.PaymentDeadlineDate, record.BuyerBank_sign, record.BB_name, record.SB_name}, record.Version)...)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetPayment(ctx, record.PaymentID)
+}
+// ============================================================================================================================
+// DeletePayment - delete a Payment
+// ============================================================================================================================
+func (c *PaymentContract) DeletePayment(ctx contractapi.TransactionContextInterface, paymentId string) error {
+	return c.invoke(ctx, "deletePayment", paymentId)
+}
+// ============================================================================================================================
+// CreateEscrow - hold the amount of a Payment until every condition is satisfied
+// ============================================================================================================================
+func (c *PaymentContract) CreateEscrow(ctx contractapi.TransactionContextInterface, paymentId string, conditions []string) (*payment.Escrow, error) {
+	if err := c.invoke(ctx, "createEscrow", append([]string{paymentId}, conditions...)...); err != nil {
+		return nil, err
+	}
+	return c.GetEscrow(ctx, paymentId)
+}
+// ============================================================================================================================
+// SatisfyEscrowCondition - mark a release condition as met, the funds are released with the last one
+// ============================================================================================================================
+func (c *PaymentContract) SatisfyEscrowCondition(ctx contractapi.TransactionContextInterface, paymentId string, condition string, satisfiedBy string) (*payment.Escrow, error) {
+	if err := c.invoke(ctx, "satisfyEscrowCondition", paymentId, condition, satisfiedBy); err != nil {
+		return nil, err
+	}
+	return c.GetEscrow(ctx, paymentId)
+}
+// ============================================================================================================================
+// RefundEscrow - return the escrowed funds to the buyer
+// ============================================================================================================================
+func (c *PaymentContract) RefundEscrow(ctx contractapi.TransactionContextInterface, paymentId string, reason string) (*payment.Escrow, error) {
+	if err := c.invoke(ctx, "refundEscrow", paymentId, reason); err != nil {
+		return nil, err
+	}
+	return c.GetEscrow(ctx, paymentId)
+}
+// ============================================================================================================================
+// ReconcileStatement - match the lines of a bank statement, "json" or "csv", to the payments. dateFormat names the format of
+// its dates, e.g. "DD/MM/YYYY", or is empty for YYYY-MM-DD
+// ============================================================================================================================
+func (c *PaymentContract) ReconcileStatement(ctx contractapi.TransactionContextInterface, statementId string, format string, statement string, dateToleranceDays int, dateFormat string) (*payment.Reconciliation, error) {
+	if err := c.invoke(ctx, "reconcile_statement", statementId, format, statement, strconv.Itoa(dateToleranceDays), dateFormat); err != nil {
+		return nil, err
+	}
+	return c.GetReconciliation(ctx, statementId)
+}
+// ============================================================================================================================
+// ResolveReconciliationException - close an open exception of a reconciled statement
+// ============================================================================================================================
+func (c *PaymentContract) ResolveReconciliationException(ctx contractapi.TransactionContextInterface, statementId string, lineNo int, resolution string) (*payment.Reconciliation, error) {
+	if err := c.invoke(ctx, "resolve_reconciliation_exception", statementId, strconv.Itoa(lineNo), resolution); err != nil {
+		return nil, err
+	}
+	return c.GetReconciliation(ctx, statementId)
+}
+// ============================================================================================================================
+// ExportPain001 - assign a pain.001 message ID to a settled Payment and return the pain.001 XML
+// ============================================================================================================================
+func (c *PaymentContract) ExportPain001(ctx contractapi.TransactionContextInterface, paymentId string) (string, error) {
+	if err := c.invoke(ctx, "exportPain001", paymentId); err != nil {
+		return "", err
+	}
+	return c.GetPaymentPain001(ctx, paymentId)
+}
+// ============================================================================================================================
+// ImportCamt054 - apply a camt.054 bank notification to the payments
+// ============================================================================================================================
+func (c *PaymentContract) ImportCamt054(ctx contractapi.TransactionContextInterface, document string) error {
+	return c.invoke(ctx, "importCamt054", document)
+}
+// ============================================================================================================================
+// ApplyLiquidatedDamages - deduct the liquidated damages of the late Shipments of an Agreement from its settled Payments
+// ============================================================================================================================
+func (c *PaymentContract) ApplyLiquidatedDamages(ctx contractapi.TransactionContextInterface, agreementId string) ([]*payment.Payment, error) {
+	if err := c.invoke(ctx, "apply_liquidated_damages", agreementId); err != nil {
+		return nil, err
+	}
+	return c.GetPaymentsByAgreement(ctx, agreementId)
+}
+// ============================================================================================================================
+// GetPayment - a Payment by paymentId
+// ============================================================================================================================
+func (c *PaymentContract) GetPayment(ctx contractapi.TransactionContextInterface, paymentId string) (*payment.Payment, error) {
+	record := &payment.Payment{}
+	if err := c.query(ctx, record, "getPaymentByID", paymentId); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+// ============================================================================================================================
+// payments - the Payments returned by a list query
+// ============================================================================================================================
+func (c *PaymentContract) payments(ctx contractapi.TransactionContextInterface, function string, arg string) ([]*payment.Payment, error) {
+	var records []*payment.Payment
+	if err := c.queryList(ctx, &records, function, arg); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+// ============================================================================================================================
+// GetPaymentsByBuyer - the Payments of a buyer
+// ============================================================================================================================
+func (c *PaymentContract) GetPaymentsByBuyer(ctx contractapi.TransactionContextInterface, buyerName string) ([]*payment.Payment, error) {
+	return c.payments(ctx, "getPaymentByBuyer", buyerName)
+}
+// ============================================================================================================================
+// GetPaymentsBySeller - the Payments of a seller
+// ============================================================================================================================
+func (c *PaymentContract) GetPaymentsBySeller(ctx contractapi.TransactionContextInterface, sellerName string) ([]*payment.Payment, error) {
+	return c.payments(ctx, "getPaymentBySeller", sellerName)
+}
+// ============================================================================================================================
+// GetPaymentsByAgreement - the Payments of an Agreement
+// ============================================================================================================================
+func (c *PaymentContract) GetPaymentsByAgreement(ctx contractapi.TransactionContextInterface, agreementId string) ([]*payment.Payment, error) {
+	return c.payments(ctx, "getPaymentByAgreement", agreementId)
+}
+// ============================================================================================================================
+// GetAllPayments - every Payment
+// ============================================================================================================================
+func (c *PaymentContract) GetAllPayments(ctx contractapi.TransactionContextInterface) ([]*payment.Payment, error) {
+	return c.payments(ctx, "getAllPayment", " ")
+}
+// ============================================================================================================================
+// GetAccountDetails - the balances of the buyer, seller and escrow accounts
+// ============================================================================================================================
+func (c *PaymentContract) GetAccountDetails(ctx contractapi.TransactionContextInterface) (*payment.AccountInfo, error) {
+	accounts := &payment.AccountInfo{}
+	if err := c.query(ctx, accounts, "getAccountDetails"); err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+// ============================================================================================================================
+// GetEscrow - the escrow of a Payment
+// ============================================================================================================================
+func (c *PaymentContract) GetEscrow(ctx contractapi.TransactionContextInterface, paymentId string) (*payment.Escrow, error) {
+	escrow := &payment.Escrow{}
+	if err := c.query(ctx, escrow, "getEscrowByPaymentID", paymentId); err != nil {
+		return nil, err
+	}
+	return escrow, nil
+}
+// ============================================================================================================================
+// GetEscrowMovements - the escrow movements of a Payment, of every Payment when paymentId is empty
+// ============================================================================================================================
+func (c *PaymentContract) GetEscrowMovements(ctx contractapi.TransactionContextInterface, paymentId string) ([]*payment.EscrowMovement, error) {
+	var movements []*payment.EscrowMovement
+	if err := c.queryList(ctx, &movements, "getEscrowMovements", paymentId); err != nil {
+		return nil, err
+	}
+	return movements, nil
+}
+// ============================================================================================================================
+// GetReconciliation - a reconciled bank statement
+// ============================================================================================================================
+func (c *PaymentContract) GetReconciliation(ctx contractapi.TransactionContextInterface, statementId string) (*payment.Reconciliation, error) {
+	reconciliation := &payment.Reconciliation{}
+	if err := c.query(ctx, reconciliation, "get_reconciliation", statementId); err != nil {
+		return nil, err
+	}
+	return reconciliation, nil
+}
+// ============================================================================================================================
+// GetReconciliationExceptions - the open reconciliation exceptions by statementId
+// ============================================================================================================================
+func (c *PaymentContract) GetReconciliationExceptions(ctx contractapi.TransactionContextInterface) (map[string][]payment.ReconciliationResult, error) {
+	exceptions := map[string][]payment.ReconciliationResult{}
+	if err := c.query(ctx, &exceptions, "get_reconciliation_exceptions", " "); err != nil {
+		return nil, err
+	}
+	return exceptions, nil
+}
+// ============================================================================================================================
+// GetPaymentPain001 - a Payment as pain.001 XML
+// ============================================================================================================================
+func (c *PaymentContract) GetPaymentPain001(ctx contractapi.TransactionContextInterface, paymentId string) (string, error) {
+	valAsBytes, err := c.queryBytes(ctx, "getPaymentPain001", paymentId)
+	if err != nil {
+		return "", err
+	}
+	return string(valAsBytes), nil
+}
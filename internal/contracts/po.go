@@ -0,0 +1,100 @@
+package contracts
+
+import (
+"github.com/hyperledger/fabric-contract-api-go/contractapi"
+"github.com/wipro-blockchain/TF-v1/internal/po"
+"github.com/wipro-blockchain/TF-v1/internal/router"
+)
+
+// POContract is the typed contract of the PO domain
+type POContract struct {
+	contractapi.Contract
+	base
+}
+
+// ============================================================================================================================
+// NewPOContract - the PO contract of a router with the po domain registered
+// ============================================================================================================================
+func NewPOContract(r *router.Router) *POContract {
+	c := &POContract{base: base{router: r}}
+	c.Name = "PO"
+	c.Info = info("PO", "Purchase orders between a buyer and a seller")
+	c.TransactionContextHandler = new(TransactionContext)
+	return c
+}
+// ============================================================================================================================
+// GetEvaluateTransactions - the methods that only read the state
+// ============================================================================================================================
+func (c *POContract) GetEvaluateTransactions() []string {
+	return []string{"GetPO", "GetPOsByBuyer", "GetPOsBySeller", "GetAllPOs"}
+}
+// ============================================================================================================================
+// CreatePO - create a PO, seller_remarks is ignored. Without a transId the chaincode generates one
+// ============================================================================================================================
+func (c *POContract) CreatePO(ctx contractapi.TransactionContextInterface, record po.PO) (*po.PO, error) {
+	transId, err := c.create(ctx, "create_po", record.TransID, record.SellerName, record.BuyerName, record.ExpectedDeliveryDate,
+		record.PO_date, record.PO_status, record.ItemId, record.Item_name, record.Item_quantity, record.Price,
+		record.Buyer_sign, record.Seller_sign)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetPO(ctx, transId)
+}
+// ============================================================================================================================
+// UpdatePO - replace a PO, refused when record has a version and the PO changed since
+// ============================================================================================================================
+func (c *POContract) UpdatePO(ctx contractapi.TransactionContextInterface, record po.PO) (*po.PO, error) {
+	err := c.invoke(ctx, "update_po", router.WithVersion([]string{record.TransID, record.SellerName, record.BuyerName,
+		record.ExpectedDeliveryDate, record.PO_date, record.PO_status, record.ItemId, record.Item_name, record.Item_quantity,
+		record.Price, record.Buyer_sign, record.Seller_sign, record.Seller_Remarks}, record.Version)...)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetPO(ctx, record.TransID)
+}
+// ============================================================================================================================
+// DeletePO - delete a PO
+// ============================================================================================================================
+func (c *POContract) DeletePO(ctx contractapi.TransactionContextInterface, transId string) error {
+	return c.invoke(ctx, "delete_po", transId)
+}
+// ============================================================================================================================
+// GetPO - a PO by transId
+// ============================================================================================================================
+func (c *POContract) GetPO(ctx contractapi.TransactionContextInterface, transId string) (*po.PO, error) {
+	record := &po.PO{}
+	if err := c.query(ctx, record, "getPO_byID", transId); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+// ============================================================================================================================
+// GetPOsByBuyer - the POs of a buyer
+// ============================================================================================================================
+func (c *POContract) GetPOsByBuyer(ctx contractapi.TransactionContextInterface, buyerName string) ([]*po.PO, error) {
+	var records []*po.PO
+	if err := c.queryList(ctx, &records, "getPO_byBuyer", buyerName); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+// ============================================================================================================================
+// GetPOsBySeller - the POs of a seller
+// ============================================================================================================================
+func (c *POContract) GetPOsBySeller(ctx contractapi.TransactionContextInterface, sellerName string) ([]*po.PO, error) {
+	var records []*po.PO
+	if err := c.queryList(ctx, &records, "getPO_bySeller", sellerName); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+// ============================================================================================================================
+// GetAllPOs - every PO
+// ============================================================================================================================
+func (c *POContract) GetAllPOs(ctx contractapi.TransactionContextInterface) ([]*po.PO, error) {
+	var records []*po.PO
+	if err := c.queryList(ctx, &records, "get_AllPO", " "); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
@@ -0,0 +1,306 @@
+package contracts
+
+import (
+"strconv"
+"encoding/json"
+
+"github.com/hyperledger/fabric-contract-api-go/contractapi"
+"github.com/wipro-blockchain/TF-v1/internal/router"
+"github.com/wipro-blockchain/TF-v1/internal/shipment"
+)
+
+// ShipmentContract is the typed contract of the Shipment domain
+type ShipmentContract struct {
+	contractapi.Contract
+	base
+}
+
+// ============================================================================================================================
+// NewShipmentContract - the Shipment contract of a router with the shipment domain registered
+// ============================================================================================================================
+func NewShipmentContract(r *router.Router) *ShipmentContract {
+	c := &ShipmentContract{base: base{router: r}}
+	c.Name = "Shipment"
+	c.Info = info("Shipment", "Shipments of an Agreement with tracking, bills of lading, cold chain, clearance and delivery SLA")
+	c.TransactionContextHandler = new(TransactionContext)
+	return c
+}
+// ============================================================================================================================
+// GetEvaluateTransactions - the methods that only read the state
+// ============================================================================================================================
+func (c *ShipmentContract) GetEvaluateTransactions() []string {
+	return []string{"GetShipment", "GetShipmentsByStatus", "GetShipmentsByShipper", "GetShipmentsByAgreement",
+		"GetAllShipments", "GetShipmentTimeline", "GetEBL", "GetSensorReadings", "GetTelemetrySummary", "GetClearance",
+		"GetDeliverySLA", "GetShipperPerformance", "GetShipmentItems"}
+}
+// ============================================================================================================================
+// shipmentArgs - the positional arguments of create_shipment and update_shipment
+// ============================================================================================================================
+func shipmentArgs(record shipment.Shipment) []string {
+	return []string{record.ShipmentID, record.TransID, record.AgreementID, record.Shipment_status, record.Source,
+		record.Destination, record.ActualDelivery_date, record.Shipment_date, record.ShipperName}
+}
+// ============================================================================================================================
+// CreateShipment - create a Shipment, the clearance status is set by the chaincode. Without a shipmentId the chaincode
+// generates one
+// ============================================================================================================================
+func (c *ShipmentContract) CreateShipment(ctx contractapi.TransactionContextInterface, record shipment.Shipment) (*shipment.Shipment, error) {
+	shipmentId, err := c.create(ctx, "create_shipment", shipmentArgs(record)...)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetShipment(ctx, shipmentId)
+}
+// ============================================================================================================================
+// UpdateShipment - replace a Shipment, refused when record has a version and the Shipment changed since
+// ============================================================================================================================
+func (c *ShipmentContract) UpdateShipment(ctx contractapi.TransactionContextInterface, record shipment.Shipment) (*shipment.Shipment, error) {
+	if err := c.invoke(ctx, "update_shipment", router.WithVersion(shipmentArgs(record), record.Version)...); err != nil {
+		return nil, err
+	}
+	return c.GetShipment(ctx, record.ShipmentID)
+}
+// ============================================================================================================================
+// DeleteShipment - delete a Shipment
+// ============================================================================================================================
+func (c *ShipmentContract) DeleteShipment(ctx contractapi.TransactionContextInterface, shipmentId string) error {
+	return c.invoke(ctx, "delete_shipment", shipmentId)
+}
+// ============================================================================================================================
+// AddTrackingEvent - append a tracking event to a Shipment, the sequence is assigned by the chaincode
+// ============================================================================================================================
+func (c *ShipmentContract) AddTrackingEvent(ctx contractapi.TransactionContextInterface, event shipment.TrackingEvent) ([]*shipment.TrackingEvent, error) {
+	if err := c.invoke(ctx, "add_tracking_event", event.ShipmentID, event.EventType, event.Location, event.Timestamp, event.ReportingParty); err != nil {
+		return nil, err
+	}
+	return c.GetShipmentTimeline(ctx, event.ShipmentID)
+}
+// ============================================================================================================================
+// IssueEBL - issue the bill of lading of a Shipment
+// ============================================================================================================================
+func (c *ShipmentContract) IssueEBL(ctx contractapi.TransactionContextInterface, shipmentId string, eblId string) (*shipment.BillOfLading, error) {
+	if err := c.invoke(ctx, "issue_ebl", shipmentId, eblId); err != nil {
+		return nil, err
+	}
+	return c.GetEBL(ctx, shipmentId)
+}
+// ============================================================================================================================
+// TransferEBL - endorse the bill of lading of a Shipment to a new holder
+// ============================================================================================================================
+func (c *ShipmentContract) TransferEBL(ctx contractapi.TransactionContextInterface, shipmentId string, currentHolder string, newHolder string) (*shipment.BillOfLading, error) {
+	if err := c.invoke(ctx, "transfer_ebl", shipmentId, currentHolder, newHolder); err != nil {
+		return nil, err
+	}
+	return c.GetEBL(ctx, shipmentId)
+}
+// ============================================================================================================================
+// SurrenderEBL - surrender the bill of lading of a Shipment at destination
+// ============================================================================================================================
+func (c *ShipmentContract) SurrenderEBL(ctx contractapi.TransactionContextInterface, shipmentId string, holder string, location string) (*shipment.BillOfLading, error) {
+	if err := c.invoke(ctx, "surrender_ebl", shipmentId, holder, location); err != nil {
+		return nil, err
+	}
+	return c.GetEBL(ctx, shipmentId)
+}
+// ============================================================================================================================
+// ReleaseCargo - release the cargo of a Shipment whose bill of lading was surrendered
+// ============================================================================================================================
+func (c *ShipmentContract) ReleaseCargo(ctx contractapi.TransactionContextInterface, shipmentId string) (*shipment.Shipment, error) {
+	if err := c.invoke(ctx, "release_cargo", shipmentId); err != nil {
+		return nil, err
+	}
+	return c.GetShipment(ctx, shipmentId)
+}
+// ============================================================================================================================
+// SetColdChainThresholds - configure the temperature and humidity range of a cold-chain Shipment
+// ============================================================================================================================
+func (c *ShipmentContract) SetColdChainThresholds(ctx contractapi.TransactionContextInterface, shipmentId string, minTemperature float64, maxTemperature float64, minHumidity float64, maxHumidity float64) error {
+	return c.invoke(ctx, "set_cold_chain_thresholds", shipmentId, formatFloat(minTemperature), formatFloat(maxTemperature),
+		formatFloat(minHumidity), formatFloat(maxHumidity))
+}
+// ============================================================================================================================
+// RegisterSensorDevice - trust a sensor device, by its PEM encoded public key, for a Shipment
+// ============================================================================================================================
+func (c *ShipmentContract) RegisterSensorDevice(ctx contractapi.TransactionContextInterface, shipmentId string, deviceId string, publicKey string) error {
+	return c.invoke(ctx, "register_sensor_device", shipmentId, deviceId, publicKey)
+}
+// ============================================================================================================================
+// AddSensorReadings - record a batch of signed sensor readings, the excursions are set by the chaincode
+// ============================================================================================================================
+func (c *ShipmentContract) AddSensorReadings(ctx contractapi.TransactionContextInterface, shipmentId string, readings []shipment.SensorReading) (*shipment.TelemetrySummary, error) {
+	readingsAsBytes, _ := json.Marshal(readings)
+	if err := c.invoke(ctx, "add_sensor_readings", shipmentId, string(readingsAsBytes)); err != nil {
+		return nil, err
+	}
+	return c.GetTelemetrySummary(ctx, shipmentId)
+}
+// ============================================================================================================================
+// PortClearanceAction - the port authority acts on a Shipment: RequestDocuments, PlaceHold, Inspect or Clear
+// ============================================================================================================================
+func (c *ShipmentContract) PortClearanceAction(ctx contractapi.TransactionContextInterface, shipmentId string, portAuthority string, action string, reason string) (*shipment.Clearance, error) {
+	if err := c.invoke(ctx, "port_clearance_action", shipmentId, portAuthority, action, reason); err != nil {
+		return nil, err
+	}
+	return c.GetClearance(ctx, shipmentId)
+}
+// ============================================================================================================================
+// SubmitClearanceDocuments - answer a document request of the port authority
+// ============================================================================================================================
+func (c *ShipmentContract) SubmitClearanceDocuments(ctx contractapi.TransactionContextInterface, shipmentId string, party string, documents string) (*shipment.Clearance, error) {
+	if err := c.invoke(ctx, "submit_clearance_documents", shipmentId, party, documents); err != nil {
+		return nil, err
+	}
+	return c.GetClearance(ctx, shipmentId)
+}
+// ============================================================================================================================
+// EvaluateDeliverySLA - compare the planned and actual delivery of a Shipment
+// ============================================================================================================================
+func (c *ShipmentContract) EvaluateDeliverySLA(ctx contractapi.TransactionContextInterface, shipmentId string) (*shipment.DeliverySLA, error) {
+	if err := c.invoke(ctx, "evaluate_delivery_sla", shipmentId); err != nil {
+		return nil, err
+	}
+	return c.GetDeliverySLA(ctx, shipmentId)
+}
+// ============================================================================================================================
+// SetShipmentItems - record the Agreement lines a Shipment carries
+// ============================================================================================================================
+func (c *ShipmentContract) SetShipmentItems(ctx contractapi.TransactionContextInterface, shipmentId string, items []shipment.ShipmentItem) ([]*shipment.ShipmentItem, error) {
+	itemsAsBytes, _ := json.Marshal(items)
+	if err := c.invoke(ctx, "set_shipment_items", shipmentId, string(itemsAsBytes)); err != nil {
+		return nil, err
+	}
+	return c.GetShipmentItems(ctx, shipmentId)
+}
+// ============================================================================================================================
+// GetShipment - a Shipment by shipmentId
+// ============================================================================================================================
+func (c *ShipmentContract) GetShipment(ctx contractapi.TransactionContextInterface, shipmentId string) (*shipment.Shipment, error) {
+	record := &shipment.Shipment{}
+	if err := c.query(ctx, record, "getShipment_byID", shipmentId); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+// ============================================================================================================================
+// shipments - the Shipments returned by a list query
+// ============================================================================================================================
+func (c *ShipmentContract) shipments(ctx contractapi.TransactionContextInterface, function string, arg string) ([]*shipment.Shipment, error) {
+	var records []*shipment.Shipment
+	if err := c.queryList(ctx, &records, function, arg); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+// ============================================================================================================================
+// GetShipmentsByStatus - the Shipments in a status
+// ============================================================================================================================
+func (c *ShipmentContract) GetShipmentsByStatus(ctx contractapi.TransactionContextInterface, status string) ([]*shipment.Shipment, error) {
+	return c.shipments(ctx, "getShipment_byStatus", status)
+}
+// ============================================================================================================================
+// GetShipmentsByShipper - the Shipments of a shipper
+// ============================================================================================================================
+func (c *ShipmentContract) GetShipmentsByShipper(ctx contractapi.TransactionContextInterface, shipperName string) ([]*shipment.Shipment, error) {
+	return c.shipments(ctx, "getShipment_byShipper", shipperName)
+}
+// ============================================================================================================================
+// GetShipmentsByAgreement - the Shipments of an Agreement
+// ============================================================================================================================
+func (c *ShipmentContract) GetShipmentsByAgreement(ctx contractapi.TransactionContextInterface, agreementId string) ([]*shipment.Shipment, error) {
+	return c.shipments(ctx, "getShipment_byAgreement", agreementId)
+}
+// ============================================================================================================================
+// GetAllShipments - every Shipment
+// ============================================================================================================================
+func (c *ShipmentContract) GetAllShipments(ctx contractapi.TransactionContextInterface) ([]*shipment.Shipment, error) {
+	return c.shipments(ctx, "get_AllShipment", " ")
+}
+// ============================================================================================================================
+// GetShipmentTimeline - the tracking events of a Shipment in sequence
+// ============================================================================================================================
+func (c *ShipmentContract) GetShipmentTimeline(ctx contractapi.TransactionContextInterface, shipmentId string) ([]*shipment.TrackingEvent, error) {
+	var events []*shipment.TrackingEvent
+	if err := c.queryList(ctx, &events, "get_shipment_timeline", shipmentId); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+// ============================================================================================================================
+// GetEBL - the bill of lading of a Shipment
+// ============================================================================================================================
+func (c *ShipmentContract) GetEBL(ctx contractapi.TransactionContextInterface, shipmentId string) (*shipment.BillOfLading, error) {
+	ebl := &shipment.BillOfLading{}
+	if err := c.query(ctx, ebl, "get_ebl", shipmentId); err != nil {
+		return nil, err
+	}
+	return ebl, nil
+}
+// ============================================================================================================================
+// GetSensorReadings - the sensor readings of a Shipment
+// ============================================================================================================================
+func (c *ShipmentContract) GetSensorReadings(ctx contractapi.TransactionContextInterface, shipmentId string) ([]*shipment.SensorReading, error) {
+	var readings []*shipment.SensorReading
+	if err := c.queryList(ctx, &readings, "get_sensor_readings", shipmentId); err != nil {
+		return nil, err
+	}
+	return readings, nil
+}
+// ============================================================================================================================
+// GetTelemetrySummary - the cold-chain summary of a Shipment
+// ============================================================================================================================
+func (c *ShipmentContract) GetTelemetrySummary(ctx contractapi.TransactionContextInterface, shipmentId string) (*shipment.TelemetrySummary, error) {
+	summary := &shipment.TelemetrySummary{}
+	if err := c.query(ctx, summary, "get_telemetry_summary", shipmentId); err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+// ============================================================================================================================
+// GetClearance - the port clearance of a Shipment
+// ============================================================================================================================
+func (c *ShipmentContract) GetClearance(ctx contractapi.TransactionContextInterface, shipmentId string) (*shipment.Clearance, error) {
+	clearance := &shipment.Clearance{}
+	if err := c.query(ctx, clearance, "get_clearance", shipmentId); err != nil {
+		return nil, err
+	}
+	return clearance, nil
+}
+// ============================================================================================================================
+// GetDeliverySLA - the delivery SLA of a Shipment
+// ============================================================================================================================
+func (c *ShipmentContract) GetDeliverySLA(ctx contractapi.TransactionContextInterface, shipmentId string) (*shipment.DeliverySLA, error) {
+	sla := &shipment.DeliverySLA{}
+	if err := c.query(ctx, sla, "get_delivery_sla", shipmentId); err != nil {
+		return nil, err
+	}
+	return sla, nil
+}
+// ============================================================================================================================
+// GetShipperPerformance - on-time performance of a shipper, of every shipper when shipperName is empty
+// ============================================================================================================================
+func (c *ShipmentContract) GetShipperPerformance(ctx contractapi.TransactionContextInterface, shipperName string) ([]*shipment.ShipperPerformance, error) {
+	if shipperName == "" {
+		shipperName = " "
+	}
+	var performance []*shipment.ShipperPerformance
+	if err := c.queryList(ctx, &performance, "get_shipper_performance", shipperName); err != nil {
+		return nil, err
+	}
+	return performance, nil
+}
+// ============================================================================================================================
+// GetShipmentItems - the Agreement lines a Shipment carries
+// ============================================================================================================================
+func (c *ShipmentContract) GetShipmentItems(ctx contractapi.TransactionContextInterface, shipmentId string) ([]*shipment.ShipmentItem, error) {
+	var items []*shipment.ShipmentItem
+	if err := c.queryList(ctx, &items, "get_shipment_items", shipmentId); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+// ============================================================================================================================
+// formatFloat - a float argument in the form the chaincode parses
+// ============================================================================================================================
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,85 @@
+package gateway
+
+import (
+"crypto/sha256"
+"encoding/hex"
+"errors"
+"fmt"
+"net/http"
+"os"
+"strings"
+"encoding/json"
+)
+
+// Caller is a client of the API, known by the SHA-256 of its bearer token, whose requests run as its own identity
+type Caller struct {
+	Name string `json:"name"`									// e.g. Buyerco, for the request log
+	TokenSHA256 string `json:"tokenSha256"`					// hex SHA-256 of the bearer token, the token itself is not kept
+	MSPID string `json:"mspId"`
+	CommonName string `json:"commonName,omitempty"`			// on the simulated network, the certificate is made up for it
+	Cert string `json:"cert,omitempty"`						// on the peers, PEM file of the certificate
+	Key string `json:"key,omitempty"`						// on the peers, PEM file of the private key
+}
+
+// caller is an authenticated caller and the Ledger its requests run on
+type caller struct {
+	Caller
+	ledger Ledger
+}
+
+// ============================================================================================================================
+// ReadCallers - read the callers of the API from a JSON file holding an array of them
+// ============================================================================================================================
+func ReadCallers(path string) ([]Caller, error) {
+	callersAsBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var callers []Caller
+	if err = json.Unmarshal(callersAsBytes, &callers); err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err.Error())
+	}
+	if len(callers) == 0 {
+		return nil, errors.New(path + " names no caller")
+	}
+	return callers, nil
+}
+// ============================================================================================================================
+// TokenSHA256 - the hex SHA-256 of a bearer token, as a Caller keeps it
+// ============================================================================================================================
+func TokenSHA256(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+// ============================================================================================================================
+// AddCaller - let c call the API, its requests run on ledger, a Ledger submitting as c. Once a caller is added every
+// request but those of the API and event documents must carry the bearer token of one
+// ============================================================================================================================
+func (g *Gateway) AddCaller(c Caller, ledger Ledger) error {
+	key := strings.ToLower(c.TokenSHA256)
+	if sum, err := hex.DecodeString(key); err != nil || len(sum) != sha256.Size {
+		return errors.New("The tokenSha256 of caller " + c.Name + " must be 64 hex digits.")
+	}
+	if g.callers == nil {
+		g.callers = map[string]*caller{}
+	}
+	if _, found := g.callers[key]; found {
+		return errors.New("Caller " + c.Name + " has the token of another caller.")
+	}
+	g.callers[key] = &caller{Caller: c, ledger: ledger}
+	return nil
+}
+// ============================================================================================================================
+// authenticate - the caller of the bearer token of a request
+// ============================================================================================================================
+func (g *Gateway) authenticate(r *http.Request) (*caller, error) {
+	token := strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+	if token == "" || !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+		return nil, &Error{Status: http.StatusUnauthorized, Message: "A bearer token is required"}
+	}
+	c, found := g.callers[TokenSHA256(token)]
+	if !found {
+		return nil, &Error{Status: http.StatusUnauthorized, Message: "Unknown bearer token"}
+	}
+	return c, nil
+}
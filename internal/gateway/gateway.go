@@ -0,0 +1,318 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements.  See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership.  The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License.  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+// Package gateway serves the trade-finance chaincodes as a REST/JSON API. Every endpoint runs the
+// original invoke and query functions, e.g. POST /pos runs create_po, on a Ledger: a client of the
+// peers or the in-process simulated network. Each caller authenticates with a bearer token and its
+// requests run on a Ledger submitting as its own identity. An errEvent becomes an HTTP error status and
+// the API is described by the OpenAPI document at /openapi.json, the chaincode events by the catalog at /events.
+package gateway
+
+import (
+"errors"
+"io"
+"net/http"
+"strings"
+"encoding/json"
+
+"github.com/wipro-blockchain/TF-v1/events"
+"github.com/wipro-blockchain/TF-v1/internal/contracts"
+)
+
+// Gateway is the http.Handler of the REST API
+type Gateway struct {
+	Ledger Ledger									// runs the requests while no caller is added, see AddCaller
+	routes []*route
+	callers map[string]*caller						// by the SHA-256 of their bearer token
+}
+
+// route is one endpoint, the table the OpenAPI document is built from
+type route struct {
+	method string
+	path string										// e.g. /pos/{id}
+	summary string
+	functions []string								// the chaincode functions it runs
+	query []string									// the query parameters it filters by, at most one is used
+	body interface{}								// a value of the request body type, nil if there is none
+	response interface{}							// a value of the response body type, nil if there is none
+	status int										// the status of a success
+	handle func(g *Gateway, req *request) (interface{}, error)
+}
+
+// request is an HTTP request matched to a route
+type request struct {
+	*http.Request
+	params map[string]string						// the path parameters
+	txID string										// the last transaction submitted
+	response []byte									// the response of that transaction
+	location string									// the path of a created resource
+}
+
+// Error is an HTTP error answer
+type Error struct {
+	Status int `json:"-"`
+	Message string `json:"error"`
+	Function string `json:"function,omitempty"`		// the chaincode function that failed
+	TxID string `json:"txId,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// ============================================================================================================================
+// New - a gateway on a Ledger
+// ============================================================================================================================
+func New(ledger Ledger) *Gateway {
+	return &Gateway{Ledger: ledger, routes: routes()}
+}
+// ============================================================================================================================
+// ServeHTTP - run the route matching the method and path, a path without a route for the method is 405
+// ============================================================================================================================
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet && r.URL.Path == "/openapi.json" {
+		writeJSON(w, http.StatusOK, g.OpenAPI())
+		return
+	}
+	if r.Method == http.MethodGet && r.URL.Path == "/events" {					//the event catalog, for listeners choosing a filter
+		writeJSON(w, http.StatusOK, map[string]interface{}{"schemaVersion": events.SchemaVersion, "events": events.Catalog})
+		return
+	}
+	if r.Method == http.MethodGet && r.URL.Path == "/events/schema.json" {
+		writeJSON(w, http.StatusOK, json.RawMessage(events.Schema()))
+		return
+	}
+	gw := g
+	if len(g.callers) > 0 {
+		c, err := g.authenticate(r)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="trade-finance"`)
+			writeError(w, err)
+			return
+		}
+		gw = &Gateway{Ledger: c.ledger, routes: g.routes, callers: g.callers}		//the request runs as its caller
+	}
+	pathFound := false
+	for _, rt := range g.routes {
+		params, ok := matchPath(rt.path, r.URL.Path)
+		if !ok {
+			continue
+		}
+		pathFound = true
+		if rt.method != r.Method {
+			continue
+		}
+		req := &request{Request: r, params: params}
+		result, err := rt.handle(gw, req)
+		if req.txID != "" {
+			w.Header().Set("X-Transaction-ID", req.txID)
+		}
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		if req.location != "" {
+			w.Header().Set("Location", req.location)
+		}
+		if result == nil {
+			w.WriteHeader(rt.status)
+			return
+		}
+		writeJSON(w, rt.status, result)
+		return
+	}
+	if pathFound {
+		writeError(w, &Error{Status: http.StatusMethodNotAllowed, Message: r.Method + " is not allowed on " + r.URL.Path})
+		return
+	}
+	writeError(w, &Error{Status: http.StatusNotFound, Message: "No endpoint " + r.URL.Path})
+}
+// ============================================================================================================================
+// matchPath - the parameters of a path matching a pattern, {name} matches one segment
+// ============================================================================================================================
+func matchPath(pattern string, path string) (map[string]string, bool) {
+	patternSegments := strings.Split(strings.Trim(pattern, "/"), "/")
+	pathSegments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(patternSegments) != len(pathSegments) {
+		return nil, false
+	}
+	params := map[string]string{}
+	for i, segment := range patternSegments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			if pathSegments[i] == "" {
+				return nil, false
+			}
+			params[segment[1:len(segment)-1]] = pathSegments[i]
+		}else if segment != pathSegments[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+// ============================================================================================================================
+// submit - run an invoke function, an errEvent becomes an Error with the status of its message. With an Idempotency-Key
+// header the invoke runs through execute_once, a retry gets the outcome of the first run without running it again
+// ============================================================================================================================
+func (g *Gateway) submit(req *request, function string, args ...string) error {
+	var tx *Transaction
+	var err error
+	if key := req.Header.Get("Idempotency-Key"); key != "" {
+		tx, err = g.Ledger.Submit("execute_once", append([]string{key, function}, args...)...)
+	}else{
+		tx, err = g.Ledger.Submit(function, args...)
+	}
+	if err != nil {
+		return &Error{Status: http.StatusBadGateway, Message: err.Error(), Function: function}
+	}
+	req.txID, req.response = tx.ID, tx.Payload
+	if tx.Event != nil && tx.Event.Name == "errEvent" {
+		message := eventMessage(tx.Event.Payload)
+		return &Error{Status: statusOf(message), Message: message, Function: function, TxID: tx.ID}
+	}
+	return nil
+}
+// ============================================================================================================================
+// createdID - the ID of the record created by the last transaction submitted, the one the chaincode generated when the
+// request gave none
+// ============================================================================================================================
+func createdID(req *request, id string) string {
+	if generated := strings.TrimSpace(string(req.response)); generated != "" {
+		return generated
+	}
+	return id
+}
+// ============================================================================================================================
+// replaying - whether the Idempotency-Key of a request was used before for function, the request is then answered from its
+// stored outcome rather than checked again against a state the first run changed
+// ============================================================================================================================
+func (g *Gateway) replaying(req *request, function string) bool {
+	key := req.Header.Get("Idempotency-Key")
+	if key == "" {
+		return false
+	}
+	_, err := g.evaluate("get_idempotency_outcome", key, function)
+	return err == nil
+}
+// ============================================================================================================================
+// evaluate - run a query function, an empty response means the record was not found
+// ============================================================================================================================
+func (g *Gateway) evaluate(function string, args ...string) (json.RawMessage, error) {
+	tx, err := g.Ledger.Evaluate(function, args...)
+	if err != nil {
+		return nil, &Error{Status: http.StatusBadGateway, Message: err.Error(), Function: function}
+	}
+	if tx.Event != nil && tx.Event.Name == "errEvent" {
+		message := eventMessage(tx.Event.Payload)
+		return nil, &Error{Status: statusOf(message), Message: message, Function: function}
+	}
+	if len(strings.TrimSpace(string(tx.Payload))) == 0 {
+		return nil, &Error{Status: http.StatusNotFound, Message: strings.TrimSpace(strings.Join(args, " ")) + " Not Found", Function: function}
+	}
+	if !json.Valid(tx.Payload) {
+		return nil, &Error{Status: http.StatusBadGateway, Message: function + " returned malformed JSON", Function: function}
+	}
+	return json.RawMessage(tx.Payload), nil
+}
+// ============================================================================================================================
+// evaluateList - run a list query, always answered as a JSON array
+// ============================================================================================================================
+func (g *Gateway) evaluateList(function string, args ...string) ([]json.RawMessage, error) {
+	tx, err := g.Ledger.Evaluate(function, args...)
+	if err != nil {
+		return nil, &Error{Status: http.StatusBadGateway, Message: err.Error(), Function: function}
+	}
+	if tx.Event != nil && tx.Event.Name == "errEvent" {
+		message := eventMessage(tx.Event.Payload)
+		if statusOf(message) == http.StatusNotFound {
+			return []json.RawMessage{}, nil					//the by-name queries report an unknown name as not found
+		}
+		return nil, &Error{Status: statusOf(message), Message: message, Function: function}
+	}
+	if len(strings.TrimSpace(string(tx.Payload))) == 0 {
+		return []json.RawMessage{}, nil
+	}
+	items, err := contracts.ListJSON(tx.Payload)
+	if err != nil {
+		return nil, &Error{Status: http.StatusBadGateway, Message: function + " returned malformed JSON: " + err.Error(), Function: function}
+	}
+	return items, nil
+}
+// ============================================================================================================================
+// eventMessage - the "message" field of an event payload, or the payload itself
+// ============================================================================================================================
+func eventMessage(payload []byte) string {
+	msg := struct {
+		Message string `json:"message"`
+	}{}
+	if json.Unmarshal(payload, &msg) == nil && msg.Message != "" {
+		return strings.TrimSpace(msg.Message)
+	}
+	return string(payload)
+}
+// ============================================================================================================================
+// statusOf - the HTTP status of an errEvent message, a refusal without a more specific status is 422
+// ============================================================================================================================
+func statusOf(message string) int {
+	lower := strings.ToLower(message)
+	switch {
+	case strings.Contains(lower, "not found"):
+		return http.StatusNotFound
+	case strings.Contains(lower, "already") || strings.Contains(lower, "arleady") || strings.Contains(lower, "version conflict"):
+		return http.StatusConflict
+	case strings.Contains(lower, "incorrect number of arguments") || strings.Contains(lower, "must") ||
+		strings.Contains(lower, "not a number") || strings.Contains(lower, "invalid") || strings.Contains(lower, "unknown"):
+		return http.StatusBadRequest
+	}
+	return http.StatusUnprocessableEntity
+}
+// ============================================================================================================================
+// decodeBody - decode the JSON request body into v, unknown fields are refused
+// ============================================================================================================================
+func decodeBody(req *request, v interface{}) error {
+	decoder := json.NewDecoder(req.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return &Error{Status: http.StatusBadRequest, Message: "Malformed request body: " + err.Error()}
+	}
+	return nil
+}
+// ============================================================================================================================
+// decodeOptionalBody - decode the JSON request body into v when there is one
+// ============================================================================================================================
+func decodeOptionalBody(req *request, v interface{}) error {
+	decoder := json.NewDecoder(req.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil && err != io.EOF {
+		return &Error{Status: http.StatusBadRequest, Message: "Malformed request body: " + err.Error()}
+	}
+	return nil
+}
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	var e *Error
+	if !errors.As(err, &e) {
+		e = &Error{Status: http.StatusInternalServerError, Message: err.Error()}
+	}
+	writeJSON(w, e.Status, e)
+}
@@ -0,0 +1,412 @@
+package gateway
+
+import (
+"errors"
+"net/http"
+"net/http/httptest"
+"strings"
+"testing"
+"encoding/json"
+
+"github.com/wipro-blockchain/TF-v1/events"
+"github.com/wipro-blockchain/TF-v1/internal/simulator"
+)
+
+// client sends requests to a gateway on a fresh simulated network
+type client struct {
+	t *testing.T
+	handler http.Handler
+	idempotencyKey string					// sent as the Idempotency-Key header when set
+}
+
+func newClient(t *testing.T) *client {
+	ledger, err := NewSimulated(false, simulator.DefaultBalance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &client{t: t, handler: New(ledger)}
+}
+
+// do sends a request and checks its status, the response body is decoded into out when given
+func (c *client) do(method string, path string, body string, status int, out interface{}) *httptest.ResponseRecorder {
+	c.t.Helper()
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	if c.idempotencyKey != "" {
+		r.Header.Set("Idempotency-Key", c.idempotencyKey)
+	}
+	w := httptest.NewRecorder()
+	c.handler.ServeHTTP(w, r)
+	if w.Code != status {
+		c.t.Fatalf("%s %s: status %d, expected %d: %s", method, path, w.Code, status, w.Body.String())
+	}
+	if out != nil {
+		if err := json.Unmarshal(w.Body.Bytes(), out); err != nil {
+			c.t.Fatalf("%s %s: %s: %s", method, path, err, w.Body.String())
+		}
+	}
+	return w
+}
+
+// fail sends a request expected to fail and returns its error answer
+func (c *client) fail(method string, path string, body string, status int) Error {
+	c.t.Helper()
+	e := Error{}
+	c.do(method, path, body, status, &e)
+	return e
+}
+
+const poBody = `{"transId": "PO1", "sellerName": "Sellerco", "buyerName": "Buyerco", "expectedDeliveryDate": "2024-03-01",
+	"po_status": "Created", "po_date": "2024-01-15", "item_id": "ITM-1", "item_name": "Rice", "item_quantity": "100",
+	"price": "25", "buyer_sign": "true", "seller_sign": "false"}`
+
+const agreementBody = `{"agreementId": "AGR1", "transId": "PO1", "agreement_status": "Created", "buyer_name": "Buyerco",
+	"seller_name": "Sellerco", "shipper_name": "Shipco", "bb_name": "Buybank", "sb_name": "Sellbank",
+	"agreementPortAuth_name": "Portauth", "agreementCU_date": "2024-01-16", "item_id": "ITM-1", "item_name": "Rice",
+	"item_quantity": "100", "total_value": "2500", "delivery_date": "2024-03-01", "extraCharges": "0", "shipper_fees": "100",
+	"document_name": "Contract", "document_url": "http://docs/contract", "tc_text": "Terms", "buyer_sign": "true",
+	"buyerBank_sign": "false", "seller_sign": "false", "sellerBank_sign": "false", "industry": "Food", "goodsPrice": "25"}`
+
+const paymentBody = `{"paymentId": "PAY1", "agreementId": "AGR1", "buyerName": "Buyerco", "sellerName": "Sellerco",
+	"amountTransferred": "2500", "paymentCUDate": "2024-02-01", "paymentStatus": "Created", "paymentDeadlineDate": "2024-03-01",
+	"buyerBank_sign": "false", "bb_name": "Buybank", "sb_name": "Sellbank"}`
+
+const shipmentBody = `{"shipmentId": "SHP1", "transId": "PO1", "agreementId": "AGR1", "shipment_status": "Created",
+	"source": "Mumbai", "destination": "Rotterdam", "actualDelivery_date": "", "shipment_date": "2024-02-01", "shipper_name": "Shipco"}`
+
+func TestTrade(t *testing.T) {
+	ledger, err := NewSimulated(false, simulator.DefaultBalance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shipper, err := ledger.As("ShipMSP", "shipper")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &client{t: t, handler: New(ledger)}
+	record := map[string]interface{}{}
+	w := c.do("POST", "/pos", poBody, http.StatusCreated, &record)
+	if w.Header().Get("Location") != "/pos/PO1" || w.Header().Get("X-Transaction-ID") == "" || record["po_status"] != "Created" {
+		t.Errorf("POST /pos: %v %v", w.Header(), record)
+	}
+	if e := c.fail("POST", "/pos", poBody, http.StatusConflict); e.Function != "create_po" || e.Message != "This PO arleady exists" {
+		t.Errorf("duplicate PO: %+v", e)
+	}
+	c.do("POST", "/pos/PO1/accept", `{"seller_remarks": "Can deliver by March"}`, http.StatusOK, &record)
+	if record["po_status"] != "Accepted" || record["seller_sign"] != "true" || record["seller_remarks"] != "Can deliver by March" {
+		t.Errorf("accept: %v", record)
+	}
+
+	c.do("POST", "/agreements", agreementBody, http.StatusCreated, &record)
+	c.fail("POST", "/payments", paymentBody, http.StatusUnprocessableEntity)
+	for _, party := range []string{"buyerBank", "seller", "sellerBank"} {
+		c.do("POST", "/agreements/AGR1/sign", `{"party": "` + party + `"}`, http.StatusOK, &record)
+	}
+	if record["agreement_status"] != "Approved By Seller Bank" {
+		t.Errorf("signed agreement: %v", record)
+	}
+	c.fail("POST", "/agreements/AGR1/sign", `{"party": "shipper"}`, http.StatusBadRequest)
+	approval := map[string]string{}
+	c.do("GET", "/agreements/AGR1/approval?user=Buyerco", "", http.StatusOK, &approval)
+	c.fail("GET", "/agreements/AGR1/approval", "", http.StatusBadRequest)
+	c.fail("POST", "/queries/update_agreement", `{"args": ["{\"agreementId\": \"AGR1\", \"seller_sign\": \"false\"}"]}`, http.StatusBadRequest)
+
+	c.do("POST", "/payments", paymentBody, http.StatusCreated, &record)
+	c.do("POST", "/payments/PAY1/escrow", `{"conditions": ["ShipmentDelivered"]}`, http.StatusCreated, &record)
+	c.do("POST", "/payments/PAY1/settle", "", http.StatusOK, &record)
+	if record["paymentStatus"] != "Paid" || record["buyerBank_sign"] != "true" {
+		t.Errorf("settle: %v", record)
+	}
+	if e := c.fail("POST", "/payments/PAY1/settle", "", http.StatusConflict); e.Message != "Payment PAY1 is already settled" {
+		t.Errorf("second settle: %+v", e)
+	}
+	c.do("GET", "/payments/PAY1/escrow", "", http.StatusOK, &record)
+	if record["escrowStatus"] != "Held" {
+		t.Errorf("escrow after settle: %v", record)
+	}
+
+	c.do("POST", "/shipments", shipmentBody, http.StatusCreated, &record)
+	var timeline []map[string]interface{}
+	c.do("POST", "/shipments/SHP1/tracking", `{"event_type": "Delivered", "location": "Rotterdam", "timestamp": "2024-02-28",
+		"reporting_party": "Shipco"}`, http.StatusCreated, &timeline)
+	if len(timeline) != 1 || timeline[0]["event_type"] != "Delivered" {
+		t.Errorf("timeline: %v", timeline)
+	}
+	c.fail("POST", "/shipments/SHP1/tracking", `{"shipmentId": "SHP2", "event_type": "Delivered"}`, http.StatusBadRequest)
+	c.do("POST", "/transactions/payment:register_party", `{"args": ["Shipco", "ShipMSP"]}`, http.StatusOK, nil)
+	c.fail("POST", "/payments/PAY1/escrow/conditions/ShipmentDelivered", `{"satisfiedBy": "Shipco"}`, http.StatusUnprocessableEntity)
+	(&client{t: t, handler: New(shipper)}).do("POST", "/payments/PAY1/escrow/conditions/ShipmentDelivered", `{"satisfiedBy": "Shipco"}`,
+		http.StatusOK, &record)
+	if record["escrowStatus"] != "Released" {
+		t.Errorf("released escrow: %v", record)
+	}
+	c.do("GET", "/accounts", "", http.StatusOK, &record)
+	if record["buyerAccountBalance"] != "97500.00" || record["sellerAccountBalance"] != "102500.00" {
+		t.Errorf("accounts: %v", record)
+	}
+
+	trade := struct {
+		PO map[string]interface{} `json:"po"`
+		Payments []map[string]interface{} `json:"payments"`
+		Shipments []map[string]interface{} `json:"shipments"`
+	}{}
+	c.do("GET", "/agreements/AGR1/trade-record", "", http.StatusOK, &trade)
+	if trade.PO["po_status"] != "Accepted" || len(trade.Payments) != 1 || len(trade.Shipments) != 1 {
+		t.Errorf("trade record: %+v", trade)
+	}
+}
+
+func TestResources(t *testing.T) {
+	c := newClient(t)
+	c.do("POST", "/pos", poBody, http.StatusCreated, nil)
+	c.do("POST", "/pos", strings.Replace(strings.Replace(poBody, "PO1", "PO2", 1), "Buyerco", "Otherbuy", 1), http.StatusCreated, nil)
+
+	var records []map[string]interface{}
+	c.do("GET", "/pos", "", http.StatusOK, &records)
+	if len(records) != 2 || records[0]["transId"] != "PO1" || records[1]["transId"] != "PO2" {
+		t.Errorf("GET /pos: %v", records)
+	}
+	c.do("GET", "/pos?buyer=Otherbuy", "", http.StatusOK, &records)
+	if len(records) != 1 || records[0]["transId"] != "PO2" {
+		t.Errorf("GET /pos?buyer=Otherbuy: %v", records)
+	}
+	c.do("GET", "/pos?buyer=Nobody", "", http.StatusOK, &records)
+	if len(records) != 0 {
+		t.Errorf("GET /pos?buyer=Nobody: %v", records)
+	}
+	c.fail("GET", "/pos?buyer=Buyerco&seller=Sellerco", "", http.StatusBadRequest)
+
+	record := map[string]interface{}{}
+	c.do("PUT", "/pos/PO1", strings.Replace(poBody, `"transId": "PO1", `, "", 1), http.StatusOK, &record)
+	if record["transId"] != "PO1" {
+		t.Errorf("PUT /pos/PO1: %v", record)
+	}
+	c.fail("PUT", "/pos/PO1", strings.Replace(poBody, "PO1", "PO2", 1), http.StatusBadRequest)
+	if e := c.fail("POST", "/pos", `{"transId": "PO3", "colour": "red"}`, http.StatusBadRequest); !strings.Contains(e.Message, "colour") {
+		t.Errorf("unknown field: %+v", e)
+	}
+
+	c.do("DELETE", "/pos/PO1", "", http.StatusNoContent, nil)
+	if e := c.fail("GET", "/pos/PO1", "", http.StatusNotFound); e.Function != "getPO_byID" {
+		t.Errorf("GET of a deleted PO: %+v", e)
+	}
+	c.fail("DELETE", "/pos/PO1", "", http.StatusNotFound)
+	c.do("PATCH", "/pos/PO2", `{"po_status": "Accepted", "seller_sign": "true"}`, http.StatusOK, &record)
+	if record["po_status"] != "Accepted" || record["buyerName"] != "Otherbuy" || record["item_name"] != "Rice" {
+		t.Errorf("PATCH /pos/PO2: %v", record)
+	}
+	if e := c.fail("PATCH", "/pos/PO2", `{"colour": "red"}`, http.StatusBadRequest); !strings.Contains(e.Message, "unknown field(s) colour") {
+		t.Errorf("PATCH of an unknown field: %+v", e)
+	}
+	c.fail("PATCH", "/pos/PO2", `{"transId": "PO1"}`, http.StatusBadRequest)
+	c.fail("PATCH", "/pos/PO9", `{"po_status": "Accepted"}`, http.StatusNotFound)
+	if record["version"] != 2.0 || record["lastModifiedTxId"] == "" {
+		t.Errorf("version of PO2: %v", record)
+	}
+	if e := c.fail("PATCH", "/pos/PO2", `{"seller_remarks": "Late", "version": 1}`, http.StatusConflict); !strings.Contains(e.Message, "at version 2") {
+		t.Errorf("PATCH of a stale version: %+v", e)
+	}
+	c.do("PATCH", "/pos/PO2", `{"seller_remarks": "Late", "version": 2}`, http.StatusOK, &record)
+	stale := strings.Replace(strings.Replace(poBody, "PO1", "PO2", 1), `{`, `{"version": 2, `, 1)
+	c.fail("PUT", "/pos/PO2", stale, http.StatusConflict)
+	c.do("PUT", "/pos/PO2", strings.Replace(stale, `"version": 2`, `"version": 3`, 1), http.StatusOK, &record)
+	if record["version"] != 4.0 || record["buyerName"] != "Buyerco" {
+		t.Errorf("PUT /pos/PO2 at version 3: %v", record)
+	}
+	c.fail("POST", "/pos/PO2", "", http.StatusMethodNotAllowed)
+	c.fail("GET", "/customs", "", http.StatusNotFound)
+}
+
+func TestOpenAPI(t *testing.T) {
+	c := newClient(t)
+	doc := struct {
+		OpenAPI string `json:"openapi"`
+		Paths map[string]map[string]struct {
+			OperationID string `json:"operationId"`
+			Description string `json:"description"`
+			Parameters []struct {
+				Name string `json:"name"`
+				In string `json:"in"`
+			} `json:"parameters"`
+		} `json:"paths"`
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]interface{} `json:"properties"`
+				Required []string `json:"required"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}{}
+	c.do("GET", "/openapi.json", "", http.StatusOK, &doc)
+	if doc.OpenAPI != "3.0.3" {
+		t.Errorf("openapi: %s", doc.OpenAPI)
+	}
+	operationIDs := map[string]bool{}
+	for path, item := range doc.Paths {
+		for method, operation := range item {
+			if operationIDs[operation.OperationID] {
+				t.Errorf("%s %s: duplicate operationId %s", method, path, operation.OperationID)
+			}
+			operationIDs[operation.OperationID] = true
+		}
+	}
+	if op := doc.Paths["/pos"]["get"]; op.OperationID != "getPos" || op.Description != "Runs get_AllPO, getPO_byBuyer, getPO_bySeller" || len(op.Parameters) != 2 {
+		t.Errorf("GET /pos: %+v", op)
+	}
+	if op := doc.Paths["/payments/{id}/settle"]["post"]; op.OperationID != "postPaymentsIdSettle" || op.Parameters[0].In != "path" {
+		t.Errorf("POST /payments/{id}/settle: %+v", op)
+	}
+	po := doc.Components.Schemas["PO"]
+	if po.Properties["po_status"] == nil || strings.Contains(strings.Join(po.Required, ","), "seller_remarks") {
+		t.Errorf("PO schema: %+v", po)
+	}
+	if doc.Components.Schemas["TradeRecord"].Properties["payments"] == nil || doc.Components.Schemas["Error"].Properties["error"] == nil {
+		t.Errorf("schemas: %v", doc.Components.Schemas)
+	}
+}
+
+// brokenLedger rejects every transaction, like an unreachable peer
+type brokenLedger struct{}
+
+func (brokenLedger) Submit(function string, args ...string) (*Transaction, error) {
+	return nil, errors.New("connection refused")
+}
+
+func (brokenLedger) Evaluate(function string, args ...string) (*Transaction, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestAuthentication(t *testing.T) {
+	ledger, err := NewSimulated(false, simulator.DefaultBalance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buyer, err := ledger.As("BuyerMSP", "buyer-admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := New(ledger)
+	if err = g.AddCaller(Caller{Name: "Buyerco", TokenSHA256: TokenSHA256("buyer-token")}, buyer); err != nil {
+		t.Fatal(err)
+	}
+	if err = g.AddCaller(Caller{Name: "Other", TokenSHA256: TokenSHA256("buyer-token")}, buyer); err == nil {
+		t.Errorf("a second caller with the same token was added")
+	}
+	if err = g.AddCaller(Caller{Name: "Other", TokenSHA256: "buyer-token"}, buyer); err == nil {
+		t.Errorf("a caller with a malformed token hash was added")
+	}
+	c := &client{t: t, handler: g}
+	if e := c.fail("POST", "/transactions/register_chaincode", `{"args": ["po", "managePO"]}`, http.StatusUnauthorized); e.Message != "A bearer token is required" {
+		t.Errorf("unauthenticated request: %+v", e)
+	}
+	c.do("GET", "/openapi.json", "", http.StatusOK, nil)
+
+	do := func(token string, method string, path string, body string, status int, out interface{}) {
+		t.Helper()
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		r.Header.Set("Authorization", "Bearer " + token)
+		w := httptest.NewRecorder()
+		g.ServeHTTP(w, r)
+		if w.Code != status {
+			t.Fatalf("%s %s: status %d, expected %d: %s", method, path, w.Code, status, w.Body.String())
+		}
+		if out != nil {
+			json.Unmarshal(w.Body.Bytes(), out)
+		}
+	}
+	do("other-token", "GET", "/pos/PO1", "", http.StatusUnauthorized, nil)
+	tx := Transaction{}
+	do("buyer-token", "POST", "/transactions/managePO:register_chaincode", `{"args": ["agreement", "manageAgreement"]}`, http.StatusOK, &tx)
+	if tx.Event == nil || tx.Event.Name != "errEvent" || !strings.Contains(string(tx.Event.Payload), "not BuyerMSP:buyer-admin") {
+		t.Errorf("register_chaincode by a caller who is not the admin: %+v", tx.Event)
+	}
+	record := map[string]interface{}{}
+	do("buyer-token", "POST", "/pos", poBody, http.StatusCreated, &record)
+	if record["createdBy"] != "BuyerMSP:buyer-admin" {
+		t.Errorf("the PO was not created as the caller: %v", record["createdBy"])
+	}
+}
+
+func TestEventCatalog(t *testing.T) {
+	c := newClient(t)
+	catalog := struct {
+		SchemaVersion string `json:"schemaVersion"`
+		Events []events.Type `json:"events"`
+	}{}
+	c.do("GET", "/events", "", http.StatusOK, &catalog)
+	if catalog.SchemaVersion != events.SchemaVersion || len(catalog.Events) != len(events.Catalog) {
+		t.Fatalf("GET /events: %+v", catalog)
+	}
+	for i, entry := range catalog.Events {
+		if entry != events.Catalog[i] {
+			t.Errorf("GET /events entry %d: %+v, expected %+v", i, entry, events.Catalog[i])
+		}
+	}
+	schema := map[string]interface{}{}
+	c.do("GET", "/events/schema.json", "", http.StatusOK, &schema)
+	if schema["$id"] != "https://github.com/wipro-blockchain/TF-v1/events/v1" {
+		t.Errorf("GET /events/schema.json: %v", schema)
+	}
+}
+
+func TestGeneratedID(t *testing.T) {
+	c := newClient(t)
+	record := map[string]interface{}{}
+	w := c.do("POST", "/pos", strings.Replace(poBody, `"transId": "PO1", `, "", 1), http.StatusCreated, &record)
+	transId, _ := record["transId"].(string)
+	if !strings.HasPrefix(transId, "PO-") || w.Header().Get("Location") != "/pos/" + transId {
+		t.Errorf("POST /pos without a transId: %v %v", w.Header(), record)
+	}
+	c.do("GET", "/pos/" + transId, "", http.StatusOK, &record)
+}
+
+func TestIdempotencyKey(t *testing.T) {
+	c := newClient(t)
+	record := map[string]interface{}{}
+	c.idempotencyKey = "create-PO1"
+	for i := 0; i < 2; i++ {
+		c.do("POST", "/pos", poBody, http.StatusCreated, &record)
+	}
+	c.idempotencyKey = ""
+	c.do("POST", "/agreements", agreementBody, http.StatusCreated, &record)
+	for _, party := range []string{"buyerBank", "seller", "sellerBank"} {
+		c.do("POST", "/agreements/AGR1/sign", `{"party": "` + party + `"}`, http.StatusOK, &record)
+	}
+	c.do("POST", "/payments", paymentBody, http.StatusCreated, &record)
+
+	c.idempotencyKey = "settle-PAY1"
+	for i := 0; i < 2; i++ {
+		c.do("POST", "/payments/PAY1/settle", "", http.StatusOK, &record)
+		if record["paymentStatus"] != "Paid" {
+			t.Errorf("settle %d: %v", i, record)
+		}
+	}
+	if e := c.fail("POST", "/payments", strings.Replace(paymentBody, "PAY1", "PAY2", 1), http.StatusUnprocessableEntity);
+		!strings.Contains(e.Message, "other arguments") {
+		t.Errorf("reused key: %+v", e)
+	}
+	c.idempotencyKey = ""
+	c.fail("POST", "/payments/PAY1/settle", "", http.StatusConflict)
+	c.do("GET", "/accounts", "", http.StatusOK, &record)
+	if record["buyerAccountBalance"] != "97500.00" || record["sellerAccountBalance"] != "102500.00" {
+		t.Errorf("accounts after a retried settlement: %v", record)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	c := &client{t: t, handler: New(brokenLedger{})}
+	if e := c.fail("GET", "/pos/PO1", "", http.StatusBadGateway); e.Message != "connection refused" {
+		t.Errorf("unreachable ledger: %+v", e)
+	}
+	cases := map[string]int{
+		"PO1 Not Found.": http.StatusNotFound,
+		"This Agreement already exists.": http.StatusConflict,
+		"Escrow is already Released.": http.StatusConflict,
+		"Incorrect number of arguments. Expecting 13": http.StatusBadRequest,
+		"Unknown tracking event type Lost.": http.StatusBadRequest,
+		"Seller name exists in Fraud list. So, Agreement auto-rejected by System.": http.StatusUnprocessableEntity,
+	}
+	for message, status := range cases {
+		if statusOf(message) != status {
+			t.Errorf("statusOf(%s) = %d", message, statusOf(message))
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package gateway
+
+import (
+tfclient "github.com/wipro-blockchain/TF-v1/client"
+"google.golang.org/grpc"
+)
+
+// Ledger runs the chaincode functions by their original names, a client of the peers or the simulated network
+type Ledger = tfclient.Transport
+
+// Transaction is the outcome of a function, the error of Submit and Evaluate means it was rejected
+type Transaction = tfclient.Transaction
+
+// Event is a chaincode event, e.g. po.created or errEvent, see package events
+type Event = tfclient.Event
+
+// ============================================================================================================================
+// NewSimulated - a Ledger on a fresh simulated network, the single TradeFinance chaincode or the four separate ones
+// ============================================================================================================================
+func NewSimulated(single bool, balance string) (*tfclient.Simulated, error) {
+	return tfclient.NewSimulated(single, balance)
+}
+// ============================================================================================================================
+// NewPeer - a Ledger on the peers of a connection profile over conn, submitting as id
+// ============================================================================================================================
+func NewPeer(conn *grpc.ClientConn, profile *tfclient.Profile, id tfclient.Identity) (*tfclient.Peer, error) {
+	return tfclient.NewPeer(conn, profile, id)
+}
@@ -0,0 +1,176 @@
+package gateway
+
+import (
+"net/http"
+"reflect"
+"strconv"
+"strings"
+
+"github.com/wipro-blockchain/TF-v1/internal/contracts"
+)
+
+// ============================================================================================================================
+// OpenAPI - the OpenAPI 3.0 document of the API, built from the route table and the record types
+// ============================================================================================================================
+func (g *Gateway) OpenAPI() map[string]interface{} {
+	schemas := &schemaSet{schemas: map[string]interface{}{}, types: map[reflect.Type]string{}}
+	errorRef := schemas.of(reflect.TypeOf(Error{}))
+	paths := map[string]interface{}{}
+	for _, rt := range g.routes {
+		item, found := paths[rt.path].(map[string]interface{})
+		if !found {
+			item = map[string]interface{}{}
+			paths[rt.path] = item
+		}
+		var parameters []interface{}
+		for _, segment := range strings.Split(rt.path, "/") {
+			if strings.HasPrefix(segment, "{") {
+				parameters = append(parameters, map[string]interface{}{"name": strings.Trim(segment, "{}"), "in": "path",
+					"required": true, "schema": map[string]interface{}{"type": "string"}})
+			}
+		}
+		for _, param := range rt.query {
+			parameters = append(parameters, map[string]interface{}{"name": param, "in": "query",
+				"schema": map[string]interface{}{"type": "string"}})
+		}
+		if rt.method != http.MethodGet && !strings.HasPrefix(rt.path, "/queries/") {
+			parameters = append(parameters, map[string]interface{}{"name": "Idempotency-Key", "in": "header",
+				"description": "Runs the invoke once, a retry with the same key gets its outcome and another invoke with it is refused",
+				"schema": map[string]interface{}{"type": "string"}})
+		}
+		success := map[string]interface{}{"description": http.StatusText(rt.status)}
+		if rt.response != nil {
+			success["content"] = jsonContent(schemas.ofValue(rt.response))
+		}
+		operation := map[string]interface{}{
+			"summary": rt.summary,
+			"description": "Runs " + strings.Join(rt.functions, ", "),
+			"operationId": operationID(rt),
+			"responses": map[string]interface{}{
+				strconv.Itoa(rt.status): success,
+				"default": map[string]interface{}{"description": "The error, e.g. 404 for a missing record, 409 for a duplicate or 422 for a refused transaction",
+					"content": jsonContent(errorRef)},
+			},
+		}
+		if len(parameters) > 0 {
+			operation["parameters"] = parameters
+		}
+		if rt.body != nil {
+			operation["requestBody"] = map[string]interface{}{"required": true, "content": jsonContent(schemas.ofValue(rt.body))}
+		}
+		item[strings.ToLower(rt.method)] = operation
+	}
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title": "Trade-finance gateway",
+			"description": "REST/JSON access to the PO, Agreement, Payment and Shipment chaincodes",
+			"version": contracts.Version,
+			"license": map[string]interface{}{"name": "Apache-2.0", "url": "http://www.apache.org/licenses/LICENSE-2.0"},
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": schemas.schemas,
+			"securitySchemes": map[string]interface{}{"bearer": map[string]interface{}{"type": "http", "scheme": "bearer",
+				"description": "The token of a caller, whose requests run as its own identity"}},
+		},
+		"security": []interface{}{map[string]interface{}{"bearer": []string{}}},
+	}
+}
+// ============================================================================================================================
+// operationID - a unique name of a route, e.g. GET /pos/{id} is getPosId
+// ============================================================================================================================
+func operationID(rt *route) string {
+	id := strings.ToLower(rt.method)
+	for _, segment := range strings.Split(rt.path, "/") {
+		for _, word := range strings.FieldsFunc(strings.Trim(segment, "{}"), func(r rune) bool { return r == '-' }) {
+			id += strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+	return id
+}
+
+func jsonContent(schema interface{}) map[string]interface{} {
+	return map[string]interface{}{"application/json": map[string]interface{}{"schema": schema}}
+}
+
+// schemaSet collects the named schemas of the document, a struct type is described once and referenced
+type schemaSet struct {
+	schemas map[string]interface{}
+	types map[reflect.Type]string
+}
+
+// ============================================================================================================================
+// ofValue - the schema of the type of a value, []interface{}{record} stands for an array of records
+// ============================================================================================================================
+func (s *schemaSet) ofValue(v interface{}) interface{} {
+	if items, ok := v.([]interface{}); ok && len(items) == 1 {
+		return map[string]interface{}{"type": "array", "items": s.ofValue(items[0])}
+	}
+	return s.of(reflect.TypeOf(v))
+}
+// ============================================================================================================================
+// of - the schema of a type, a struct becomes a reference to a named schema
+// ============================================================================================================================
+func (s *schemaSet) of(t reflect.Type) interface{} {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return s.of(t.Elem())
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "format": "byte"}	//encoding/json writes a []byte as base64
+		}
+		return map[string]interface{}{"type": "array", "items": s.of(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": s.of(t.Elem())}
+	case reflect.Struct:
+		return map[string]interface{}{"$ref": "#/components/schemas/" + s.named(t)}
+	}
+	return map[string]interface{}{}
+}
+// ============================================================================================================================
+// named - the name of the schema of a struct type, the package is prefixed when two packages have a type of that name
+// ============================================================================================================================
+func (s *schemaSet) named(t reflect.Type) string {
+	if name, found := s.types[t]; found {
+		return name
+	}
+	name := t.Name()
+	if _, taken := s.schemas[name]; taken {
+		pkg := t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:]
+		name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+	}
+	s.types[t] = name
+	s.schemas[name] = map[string]interface{}{}					//reserved before the fields, a type may refer to itself
+	properties := map[string]interface{}{}
+	var required []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if field.PkgPath != "" || tag == "-" {
+			continue
+		}
+		fieldName := strings.Split(tag, ",")[0]
+		if fieldName == "" {
+			fieldName = field.Name
+		}
+		properties[fieldName] = s.of(field.Type)
+		if !strings.Contains(tag, "omitempty") && !strings.Contains(field.Tag.Get("metadata"), "optional") {
+			required = append(required, fieldName)
+		}
+	}
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	s.schemas[name] = schema
+	return name
+}
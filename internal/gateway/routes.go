@@ -0,0 +1,601 @@
+package gateway
+
+import (
+"encoding/json"
+"net/http"
+"reflect"
+"strconv"
+"strings"
+
+"github.com/wipro-blockchain/TF-v1/internal/agreement"
+"github.com/wipro-blockchain/TF-v1/internal/contracts"
+"github.com/wipro-blockchain/TF-v1/internal/payment"
+"github.com/wipro-blockchain/TF-v1/internal/po"
+"github.com/wipro-blockchain/TF-v1/internal/router"
+"github.com/wipro-blockchain/TF-v1/internal/shipment"
+"github.com/wipro-blockchain/TF-v1/internal/simulator"
+)
+
+// filter is a query parameter of a list endpoint and the query function it runs
+type filter struct {
+	param string
+	function string
+}
+
+// SignRequest names the party signing an Agreement: buyer, buyerBank, seller or sellerBank
+type SignRequest struct {
+	Party string `json:"party"`
+}
+
+// AcceptRequest carries the remarks of the seller accepting a PO
+type AcceptRequest struct {
+	Seller_Remarks string `json:"seller_remarks,omitempty"`
+}
+
+// EscrowRequest lists the conditions releasing an escrow, e.g. ShipmentDelivered
+type EscrowRequest struct {
+	Conditions []string `json:"conditions"`
+}
+
+// ConditionRequest names the party that satisfied an escrow condition
+type ConditionRequest struct {
+	SatisfiedBy string `json:"satisfiedBy"`
+}
+
+// RefundRequest gives the reason of an escrow refund
+type RefundRequest struct {
+	Reason string `json:"reason"`
+}
+
+// StatementRequest is a bank statement to reconcile, format "json" or "csv"
+type StatementRequest struct {
+	StatementID string `json:"statementId"`
+	Format string `json:"format"`
+	Statement string `json:"statement"`
+	DateToleranceDays int `json:"dateToleranceDays"`
+	DateFormat string `json:"dateFormat,omitempty"`		// e.g. DD/MM/YYYY, YYYY-MM-DD when empty
+}
+
+// ClearanceRequest is an action of the port authority: RequestDocuments, PlaceHold, Inspect or Clear
+type ClearanceRequest struct {
+	PortAuthority string `json:"portAuthority"`
+	Action string `json:"action"`
+	Reason string `json:"reason"`
+}
+
+// CallRequest is the positional arguments of a chaincode function run by its original name
+type CallRequest struct {
+	Args []string `json:"args"`
+}
+
+// ============================================================================================================================
+// routes - every endpoint of the API
+// ============================================================================================================================
+func routes() []*route {
+	var rts []*route
+	rts = append(rts, resource("/pos", "PO", po.PO{}, "create_po", "update_po", "delete_po", "getPO_byID", "get_AllPO",
+		[]filter{{"buyer", "getPO_byBuyer"}, {"seller", "getPO_bySeller"}}, createPO, updatePO)...)
+	rts = append(rts,
+		&route{method: http.MethodPost, path: "/pos/{id}/accept", summary: "The seller accepts and signs a PO",
+			functions: []string{"getPO_byID", "update_po"}, body: AcceptRequest{}, response: po.PO{}, status: http.StatusOK, handle: acceptPO},
+	)
+
+	rts = append(rts, resource("/agreements", "Agreement", agreement.Agreement{}, "create_agreement", "update_agreement",
+		"delete_agreement", "getAgreement_byID", "get_AllAgreement", []filter{{"buyer", "getAgreement_byBuyer"},
+		{"seller", "getAgreement_bySeller"}, {"shipper", "getAgreement_byShipper"}, {"buyerBank", "getAgreement_byBuyerBank"},
+		{"sellerBank", "getAgreement_bySellerBank"}, {"portAuthority", "getAgreement_byPortAuthority"}},
+		createAgreement, updateAgreement)...)
+	rts = append(rts,
+		&route{method: http.MethodPost, path: "/agreements/{id}/sign", summary: "A party signs an Agreement, the last signature approves it",
+			functions: []string{"getAgreement_byID", "update_agreement"}, body: SignRequest{}, response: agreement.Agreement{},
+			status: http.StatusOK, handle: signAgreement},
+		&route{method: http.MethodGet, path: "/agreements/{id}/approval", summary: "The approval status of an Agreement for a user",
+			functions: []string{"getApprovalStatus"}, query: []string{"user"}, response: map[string]string{}, status: http.StatusOK,
+			handle: func(g *Gateway, req *request) (interface{}, error) {
+				user := req.URL.Query().Get("user")
+				if user == "" {
+					return nil, &Error{Status: http.StatusBadRequest, Message: "The user query parameter is required"}
+				}
+				return g.evaluate("getApprovalStatus", user, req.params["id"])
+			}},
+		query("/agreements/{id}/trade-record", "The PO, Agreement, Payments and Shipments of a trade", "get_trade_record", contracts.TradeRecord{}),
+		query("/agreements/{id}/shipped-balance", "The shipped versus ordered quantity of every line", "get_shipped_balance", agreement.ShippedBalance{}),
+		query("/agreements/{id}/liquidated-damages", "The liquidated damages terms of an Agreement", "get_liquidated_damages", agreement.LiquidatedDamages{}),
+		&route{method: http.MethodPut, path: "/agreements/{id}/liquidated-damages", summary: "Set the liquidated damages terms of an Agreement",
+			functions: []string{"set_liquidated_damages", "get_liquidated_damages"}, body: agreement.LiquidatedDamages{},
+			response: agreement.LiquidatedDamages{}, status: http.StatusOK,
+			handle: func(g *Gateway, req *request) (interface{}, error) {
+				terms := agreement.LiquidatedDamages{}
+				if err := decodeBody(req, &terms); err != nil {
+					return nil, err
+				}
+				if err := g.submit(req, "set_liquidated_damages", req.params["id"], terms.RatePerDay, terms.CapPercent, terms.GraceDays); err != nil {
+					return nil, err
+				}
+				return g.evaluate("get_liquidated_damages", req.params["id"])
+			}},
+		&route{method: http.MethodPost, path: "/agreements/{id}/liquidated-damages/apply", summary: "Deduct the liquidated damages of the late Shipments of an Agreement from its settled Payments",
+			functions: []string{"apply_liquidated_damages", "getPaymentByAgreement"}, response: []payment.Payment{}, status: http.StatusOK,
+			handle: func(g *Gateway, req *request) (interface{}, error) {
+				if err := g.submit(req, "apply_liquidated_damages", req.params["id"]); err != nil {
+					return nil, err
+				}
+				return g.evaluate("getPaymentByAgreement", req.params["id"])
+			}},
+		&route{method: http.MethodGet, path: "/frauds", summary: "The fraud list, or the entries of a name",
+			functions: []string{"get_fraud_list", "get_fraud_details"}, query: []string{"name"}, response: []agreement.Fraud_list{},
+			status: http.StatusOK, handle: list("get_fraud_list", []filter{{"name", "get_fraud_details"}})},
+		&route{method: http.MethodPost, path: "/frauds", summary: "Add a name to the fraud list",
+			functions: []string{"update_fraud_list"}, body: agreement.Fraud_list{}, response: agreement.Fraud_list{},
+			status: http.StatusCreated, handle: func(g *Gateway, req *request) (interface{}, error) {
+				record := agreement.Fraud_list{}
+				if err := decodeBody(req, &record); err != nil {
+					return nil, err
+				}
+				if err := g.submit(req, "update_fraud_list", record.FraudID, record.FraudName); err != nil {
+					return nil, err
+				}
+				return record, nil
+			}},
+	)
+
+	rts = append(rts, resource("/payments", "Payment", payment.Payment{}, "createPayment", "updatePayment", "deletePayment",
+		"getPaymentByID", "getAllPayment", []filter{{"buyer", "getPaymentByBuyer"}, {"seller", "getPaymentBySeller"},
+		{"agreement", "getPaymentByAgreement"}}, createPayment, updatePayment)...)
+	rts = append(rts,
+		&route{method: http.MethodPost, path: "/payments/{id}/settle", summary: "The buyer bank signs a Payment and the amount is debited, into escrow if it has one",
+			functions: []string{"getPaymentByID", "updatePayment"}, response: payment.Payment{}, status: http.StatusOK, handle: settlePayment},
+		&route{method: http.MethodPost, path: "/payments/{id}/escrow", summary: "Hold the amount of a Payment until every condition is satisfied",
+			functions: []string{"createEscrow", "getEscrowByPaymentID"}, body: EscrowRequest{}, response: payment.Escrow{},
+			status: http.StatusCreated, handle: func(g *Gateway, req *request) (interface{}, error) {
+				body := EscrowRequest{}
+				if err := decodeBody(req, &body); err != nil {
+					return nil, err
+				}
+				if err := g.submit(req, "createEscrow", append([]string{req.params["id"]}, body.Conditions...)...); err != nil {
+					return nil, err
+				}
+				req.location = req.URL.Path
+				return g.evaluate("getEscrowByPaymentID", req.params["id"])
+			}},
+		query("/payments/{id}/escrow", "The escrow of a Payment", "getEscrowByPaymentID", payment.Escrow{}),
+		&route{method: http.MethodGet, path: "/payments/{id}/escrow/movements", summary: "The fund movements of an escrow",
+			functions: []string{"getEscrowMovements"}, response: []payment.EscrowMovement{}, status: http.StatusOK,
+			handle: func(g *Gateway, req *request) (interface{}, error) {
+				return g.evaluateList("getEscrowMovements", req.params["id"])
+			}},
+		&route{method: http.MethodPost, path: "/payments/{id}/escrow/conditions/{condition}", summary: "Mark a release condition as met, the funds are released with the last one",
+			functions: []string{"satisfyEscrowCondition", "getEscrowByPaymentID"}, body: ConditionRequest{}, response: payment.Escrow{},
+			status: http.StatusOK, handle: func(g *Gateway, req *request) (interface{}, error) {
+				body := ConditionRequest{}
+				if err := decodeBody(req, &body); err != nil {
+					return nil, err
+				}
+				if err := g.submit(req, "satisfyEscrowCondition", req.params["id"], req.params["condition"], body.SatisfiedBy); err != nil {
+					return nil, err
+				}
+				return g.evaluate("getEscrowByPaymentID", req.params["id"])
+			}},
+		&route{method: http.MethodPost, path: "/payments/{id}/escrow/refund", summary: "Return the escrowed funds to the buyer",
+			functions: []string{"refundEscrow", "getEscrowByPaymentID"}, body: RefundRequest{}, response: payment.Escrow{},
+			status: http.StatusOK, handle: func(g *Gateway, req *request) (interface{}, error) {
+				body := RefundRequest{}
+				if err := decodeBody(req, &body); err != nil {
+					return nil, err
+				}
+				if err := g.submit(req, "refundEscrow", req.params["id"], body.Reason); err != nil {
+					return nil, err
+				}
+				return g.evaluate("getEscrowByPaymentID", req.params["id"])
+			}},
+		&route{method: http.MethodGet, path: "/accounts", summary: "The buyer and seller accounts and balances",
+			functions: []string{"getAccountDetails"}, response: payment.AccountInfo{}, status: http.StatusOK,
+			handle: func(g *Gateway, req *request) (interface{}, error) {
+				return g.evaluate("getAccountDetails")
+			}},
+		&route{method: http.MethodPost, path: "/statements", summary: "Reconcile a bank statement with the payments",
+			functions: []string{"reconcile_statement", "get_reconciliation"}, body: StatementRequest{}, response: payment.Reconciliation{},
+			status: http.StatusCreated, handle: func(g *Gateway, req *request) (interface{}, error) {
+				body := StatementRequest{}
+				if err := decodeBody(req, &body); err != nil {
+					return nil, err
+				}
+				if err := g.submit(req, "reconcile_statement", body.StatementID, body.Format, body.Statement, strconv.Itoa(body.DateToleranceDays), body.DateFormat); err != nil {
+					return nil, err
+				}
+				req.location = "/statements/" + body.StatementID
+				return g.evaluate("get_reconciliation", body.StatementID)
+			}},
+		query("/statements/{id}", "The reconciliation of a bank statement", "get_reconciliation", payment.Reconciliation{}),
+	)
+
+	rts = append(rts, resource("/shipments", "Shipment", shipment.Shipment{}, "create_shipment", "update_shipment",
+		"delete_shipment", "getShipment_byID", "get_AllShipment", []filter{{"agreement", "getShipment_byAgreement"},
+		{"shipper", "getShipment_byShipper"}, {"status", "getShipment_byStatus"}}, createShipment, updateShipment)...)
+	rts = append(rts,
+		&route{method: http.MethodPost, path: "/shipments/{id}/tracking", summary: "Report a milestone of a Shipment, e.g. Delivered",
+			functions: []string{"add_tracking_event", "get_shipment_timeline"}, body: shipment.TrackingEvent{},
+			response: []shipment.TrackingEvent{}, status: http.StatusCreated, handle: func(g *Gateway, req *request) (interface{}, error) {
+				event := shipment.TrackingEvent{}
+				if err := decodeBody(req, &event); err != nil {
+					return nil, err
+				}
+				if err := checkID(req, &event.ShipmentID); err != nil {
+					return nil, err
+				}
+				if err := g.submit(req, "add_tracking_event", event.ShipmentID, event.EventType, event.Location, event.Timestamp, event.ReportingParty); err != nil {
+					return nil, err
+				}
+				req.location = req.URL.Path
+				return g.evaluateList("get_shipment_timeline", event.ShipmentID)
+			}},
+		&route{method: http.MethodGet, path: "/shipments/{id}/tracking", summary: "The milestones of a Shipment in order",
+			functions: []string{"get_shipment_timeline"}, response: []shipment.TrackingEvent{}, status: http.StatusOK,
+			handle: func(g *Gateway, req *request) (interface{}, error) {
+				return g.evaluateList("get_shipment_timeline", req.params["id"])
+			}},
+		query("/shipments/{id}/clearance", "The port clearance of a Shipment", "get_clearance", shipment.Clearance{}),
+		&route{method: http.MethodPost, path: "/shipments/{id}/clearance", summary: "The port authority acts on a Shipment",
+			functions: []string{"port_clearance_action", "get_clearance"}, body: ClearanceRequest{}, response: shipment.Clearance{},
+			status: http.StatusOK, handle: func(g *Gateway, req *request) (interface{}, error) {
+				body := ClearanceRequest{}
+				if err := decodeBody(req, &body); err != nil {
+					return nil, err
+				}
+				if err := g.submit(req, "port_clearance_action", req.params["id"], body.PortAuthority, body.Action, body.Reason); err != nil {
+					return nil, err
+				}
+				return g.evaluate("get_clearance", req.params["id"])
+			}},
+		query("/shipments/{id}/sla", "The delivery SLA evaluation of a Shipment", "get_delivery_sla", shipment.DeliverySLA{}),
+		&route{method: http.MethodPost, path: "/shipments/{id}/sla", summary: "Compare the planned and actual delivery of a Shipment",
+			functions: []string{"evaluate_delivery_sla", "get_delivery_sla"}, response: shipment.DeliverySLA{}, status: http.StatusOK,
+			handle: func(g *Gateway, req *request) (interface{}, error) {
+				if err := g.submit(req, "evaluate_delivery_sla", req.params["id"]); err != nil {
+					return nil, err
+				}
+				return g.evaluate("get_delivery_sla", req.params["id"])
+			}},
+		query("/shipments/{id}/ebl", "The electronic bill of lading of a Shipment", "get_ebl", shipment.BillOfLading{}),
+	)
+
+	rts = append(rts,
+		&route{method: http.MethodPost, path: "/transactions/{function}", summary: "Submit an invoke function by its original name, its errEvent is returned not refused",
+			functions: []string{"{function}"}, body: CallRequest{}, response: Transaction{}, status: http.StatusOK, handle: call(true)},
+		&route{method: http.MethodPost, path: "/queries/{function}", summary: "Evaluate a query function by its original name",
+			functions: []string{"{function}"}, body: CallRequest{}, response: Transaction{}, status: http.StatusOK, handle: call(false)},
+	)
+	return rts
+}
+// domains runs every function of the four roles, to tell a query from an invoke whatever chaincodes the Ledger runs
+var domains, _ = simulator.NewChaincode("tradeFinance", "po", "agreement", "payment", "shipment")
+
+// ============================================================================================================================
+// call - run a function by its original name and answer with the Transaction, for the HTTP transport of the client package.
+// An invoke is refused on the query path, where it would run without being committed
+// ============================================================================================================================
+func call(submit bool) func(g *Gateway, req *request) (interface{}, error) {
+	return func(g *Gateway, req *request) (interface{}, error) {
+		body := CallRequest{}
+		if err := decodeBody(req, &body); err != nil {
+			return nil, err
+		}
+		function := req.params["function"]
+		if name := function[strings.LastIndex(function, ":")+1:]; !submit && !domains.Router.IsQuery(name) {
+			return nil, &Error{Status: http.StatusBadRequest, Message: function + " is not a query, submit it through POST /transactions/" + function, Function: function}
+		}
+		var tx *Transaction
+		var err error
+		if key := req.Header.Get("Idempotency-Key"); submit && key != "" {
+			tx, err = g.Ledger.Submit("execute_once", append([]string{key, function}, body.Args...)...)
+		}else if submit {
+			tx, err = g.Ledger.Submit(function, body.Args...)
+		}else{
+			tx, err = g.Ledger.Evaluate(function, body.Args...)
+		}
+		if err != nil {
+			return nil, &Error{Status: http.StatusBadGateway, Message: err.Error(), Function: function}
+		}
+		if submit {
+			req.txID = tx.ID
+		}
+		return tx, nil
+	}
+}
+// ============================================================================================================================
+// resource - the create, list, read, replace and delete endpoints of a record type
+// ============================================================================================================================
+func resource(path string, name string, record interface{}, create string, update string, remove string, get string, all string,
+	filters []filter, createHandler func(g *Gateway, req *request) (interface{}, error),
+	updateHandler func(g *Gateway, req *request) (interface{}, error)) []*route {
+	listFunctions := []string{all}
+	var params []string
+	for _, f := range filters {
+		listFunctions = append(listFunctions, f.function)
+		params = append(params, f.param)
+	}
+	return []*route{
+		{method: http.MethodPost, path: path, summary: "Create a " + name, functions: []string{create, get}, body: record,
+			response: record, status: http.StatusCreated, handle: createHandler},
+		{method: http.MethodGet, path: path, summary: "List the " + name + "s, or those matching one filter",
+			functions: listFunctions, query: params, response: []interface{}{record}, status: http.StatusOK, handle: list(all, filters)},
+		query(path + "/{id}", "A " + name + " by its ID", get, record),
+		{method: http.MethodPut, path: path + "/{id}", summary: "Replace a " + name, functions: []string{update, get}, body: record,
+			response: record, status: http.StatusOK, handle: updateHandler},
+		{method: http.MethodPatch, path: path + "/{id}", summary: "Change the fields of a " + name + " given in a JSON Merge Patch, null clears one",
+			functions: []string{update, get}, body: map[string]string{}, response: record, status: http.StatusOK,
+			handle: func(g *Gateway, req *request) (interface{}, error) {
+				patch := map[string]json.RawMessage{}
+				if err := decodeBody(req, &patch); err != nil {
+					return nil, err
+				}
+				id := idField(record)
+				if raw, found := patch[id]; found {
+					var bodyID string
+					json.Unmarshal(raw, &bodyID)
+					if err := checkID(req, &bodyID); err != nil {
+						return nil, err
+					}
+				}
+				patch[id], _ = json.Marshal(req.params["id"])
+				patchAsBytes, _ := json.Marshal(patch)
+				if err := g.submit(req, update, string(patchAsBytes)); err != nil {	//one JSON object updates only its fields
+					return nil, err
+				}
+				return g.evaluate(get, req.params["id"])
+			}},
+		{method: http.MethodDelete, path: path + "/{id}", summary: "Delete a " + name, functions: []string{get, remove},
+			status: http.StatusNoContent, handle: func(g *Gateway, req *request) (interface{}, error) {
+				if _, err := g.evaluate(get, req.params["id"]); err != nil {	//the delete functions succeed on a missing record
+					return nil, err
+				}
+				return nil, g.submit(req, remove, req.params["id"])
+			}},
+	}
+}
+// ============================================================================================================================
+// idField - the JSON name of the ID of a record type, its first field
+// ============================================================================================================================
+func idField(record interface{}) string {
+	return strings.Split(reflect.TypeOf(record).Field(0).Tag.Get("json"), ",")[0]
+}
+// ============================================================================================================================
+// query - an endpoint reading one record by the ID in its path
+// ============================================================================================================================
+func query(path string, summary string, function string, response interface{}) *route {
+	return &route{method: http.MethodGet, path: path, summary: summary, functions: []string{function}, response: response,
+		status: http.StatusOK, handle: func(g *Gateway, req *request) (interface{}, error) {
+			return g.evaluate(function, req.params["id"])
+		}}
+}
+// ============================================================================================================================
+// list - a list endpoint, all without a filter or the function of the one filter given
+// ============================================================================================================================
+func list(all string, filters []filter) func(g *Gateway, req *request) (interface{}, error) {
+	return func(g *Gateway, req *request) (interface{}, error) {
+		values := req.URL.Query()
+		var used []filter
+		for _, f := range filters {
+			if values.Get(f.param) != "" {
+				used = append(used, f)
+			}
+		}
+		switch len(used) {
+		case 0:
+			return g.evaluateList(all, " ")					//the list-all functions expect a placeholder argument
+		case 1:
+			return g.evaluateList(used[0].function, values.Get(used[0].param))
+		}
+		return nil, &Error{Status: http.StatusBadRequest, Message: "Filter by one of " + used[0].param + " and " + used[1].param + ", not both"}
+	}
+}
+// ============================================================================================================================
+// checkID - take the ID of a record from the path, a different ID in the body is refused
+// ============================================================================================================================
+func checkID(req *request, id *string) error {
+	if *id != "" && *id != req.params["id"] {
+		return &Error{Status: http.StatusBadRequest, Message: "The body names " + *id + " but the path names " + req.params["id"]}
+	}
+	*id = req.params["id"]
+	return nil
+}
+// ============================================================================================================================
+// read - decode the record of a query into v
+// ============================================================================================================================
+func (g *Gateway) read(v interface{}, function string, args ...string) error {
+	valAsBytes, err := g.evaluate(function, args...)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(valAsBytes, v)
+}
+
+func poArgs(record po.PO) []string {
+	return []string{record.TransID, record.SellerName, record.BuyerName, record.ExpectedDeliveryDate, record.PO_date,
+		record.PO_status, record.ItemId, record.Item_name, record.Item_quantity, record.Price, record.Buyer_sign, record.Seller_sign}
+}
+
+func createPO(g *Gateway, req *request) (interface{}, error) {
+	record := po.PO{}
+	if err := decodeBody(req, &record); err != nil {
+		return nil, err
+	}
+	if err := g.submit(req, "create_po", poArgs(record)...); err != nil {
+		return nil, err
+	}
+	transId := createdID(req, record.TransID)
+	req.location = "/pos/" + transId
+	return g.evaluate("getPO_byID", transId)
+}
+
+func updatePO(g *Gateway, req *request) (interface{}, error) {
+	record := po.PO{}
+	if err := decodeBody(req, &record); err != nil {
+		return nil, err
+	}
+	if err := checkID(req, &record.TransID); err != nil {
+		return nil, err
+	}
+	if err := g.submit(req, "update_po", router.WithVersion(append(poArgs(record), record.Seller_Remarks), record.Version)...); err != nil {
+		return nil, err
+	}
+	return g.evaluate("getPO_byID", record.TransID)
+}
+// ============================================================================================================================
+// acceptPO - the seller signs a PO and its status becomes Accepted
+// ============================================================================================================================
+func acceptPO(g *Gateway, req *request) (interface{}, error) {
+	body := AcceptRequest{}
+	if err := decodeOptionalBody(req, &body); err != nil {
+		return nil, err
+	}
+	patch := map[string]string{"transId": req.params["id"], "po_status": "Accepted", "seller_sign": "true"}
+	if body.Seller_Remarks != "" {
+		patch["seller_remarks"] = body.Seller_Remarks
+	}
+	patchAsBytes, _ := json.Marshal(patch)
+	if err := g.submit(req, "update_po", string(patchAsBytes)); err != nil {
+		return nil, err
+	}
+	return g.evaluate("getPO_byID", req.params["id"])
+}
+
+func agreementArgs(record agreement.Agreement) []string {
+	return []string{record.AgreementID, record.TransID, record.Agreement_status, record.BuyerName, record.SellerName,
+		record.ShipperName, record.BB_name, record.SB_name, record.PortAuthName, record.AgreementCU_date, record.ItemId,
+		record.Item_name, record.Item_quantity, record.Total_Value, record.Delivery_date, record.ExtraCharges,
+		record.Shipper_fees, record.DocumentName, record.DocumentURL, record.TC_Text, record.Buyer_sign,
+		record.BuyerBank_sign, record.Seller_sign, record.SellerBank_sign, record.Industry, record.GoodsPrice}
+}
+
+func createAgreement(g *Gateway, req *request) (interface{}, error) {
+	record := agreement.Agreement{}
+	if err := decodeBody(req, &record); err != nil {
+		return nil, err
+	}
+	if err := g.submit(req, "create_agreement", agreementArgs(record)...); err != nil {
+		return nil, err
+	}
+	agreementId := createdID(req, record.AgreementID)
+	req.location = "/agreements/" + agreementId
+	return g.evaluate("getAgreement_byID", agreementId)
+}
+
+func updateAgreement(g *Gateway, req *request) (interface{}, error) {
+	record := agreement.Agreement{}
+	if err := decodeBody(req, &record); err != nil {
+		return nil, err
+	}
+	if err := checkID(req, &record.AgreementID); err != nil {
+		return nil, err
+	}
+	if err := g.submit(req, "update_agreement", router.WithVersion(agreementArgs(record), record.Version)...); err != nil {
+		return nil, err
+	}
+	return g.evaluate("getAgreement_byID", record.AgreementID)
+}
+// ============================================================================================================================
+// signAgreement - one party signs an Agreement, update_agreement sets the status from the signatures
+// ============================================================================================================================
+func signAgreement(g *Gateway, req *request) (interface{}, error) {
+	body := SignRequest{}
+	if err := decodeBody(req, &body); err != nil {
+		return nil, err
+	}
+	fields := map[string]string{"buyer": "buyer_sign", "buyerBank": "buyerBank_sign", "seller": "seller_sign", "sellerBank": "sellerBank_sign"}
+	field, found := fields[body.Party]
+	if !found {
+		return nil, &Error{Status: http.StatusBadRequest, Message: "Unknown party " + body.Party + ", expecting buyer, buyerBank, seller or sellerBank"}
+	}
+	patchAsBytes, _ := json.Marshal(map[string]string{"agreementId": req.params["id"], field: "true"})
+	if err := g.submit(req, "update_agreement", string(patchAsBytes)); err != nil {		//only the signature changes
+		return nil, err
+	}
+	return g.evaluate("getAgreement_byID", req.params["id"])
+}
+
+func createPayment(g *Gateway, req *request) (interface{}, error) {
+	record := payment.Payment{}
+	if err := decodeBody(req, &record); err != nil {
+		return nil, err
+	}
+	err := g.submit(req, "createPayment", record.PaymentID, record.AgreementID, record.BuyerName, record.SellerName,
+		record.AmountTransferred, record.PaymentCUDate, record.PaymentStatus, record.PaymentDeadlineDate,
+		record.BuyerBank_sign, record.BB_name, record.SB_name)
+	if err != nil {
+		return nil, err
+	}
+	paymentId := createdID(req, record.PaymentID)
+	req.location = "/payments/" + paymentId
+	return g.evaluate("getPaymentByID", paymentId)
+}
+
+func paymentUpdateArgs(record payment.Payment) []string {
+	return []string{record.PaymentID, record.AgreementID, record.BuyerName, record.SellerName, record.BuyerAccount,
+		record.SellerAccount, record.AmountTransferred, record.PaymentCUDate, record.PaymentStatus,
+		record.PaymentDeadlineDate, record.BuyerBank_sign, record.BB_name, record.SB_name}
+}
+
+func updatePayment(g *Gateway, req *request) (interface{}, error) {
+	record := payment.Payment{}
+	if err := decodeBody(req, &record); err != nil {
+		return nil, err
+	}
+	if err := checkID(req, &record.PaymentID); err != nil {
+		return nil, err
+	}
+	if err := g.submit(req, "updatePayment", router.WithVersion(paymentUpdateArgs(record), record.Version)...); err != nil {
+		return nil, err
+	}
+	return g.evaluate("getPaymentByID", record.PaymentID)
+}
+// ============================================================================================================================
+// settlePayment - the buyer bank signs a Payment, updatePayment debits the buyer again on every signed update so a
+// Payment is settled only once. A retry with the Idempotency-Key of the settlement answers with its outcome
+// ============================================================================================================================
+func settlePayment(g *Gateway, req *request) (interface{}, error) {
+	record := payment.Payment{}
+	if err := g.read(&record, "getPaymentByID", req.params["id"]); err != nil {
+		return nil, err
+	}
+	if record.BuyerBank_sign == "true" && !g.replaying(req, "updatePayment") {
+		return nil, &Error{Status: http.StatusConflict, Message: "Payment " + record.PaymentID + " is already settled"}
+	}
+	patchAsBytes, _ := json.Marshal(map[string]string{"paymentId": record.PaymentID, "buyerBank_sign": "true", "paymentStatus": "Paid"})
+	if err := g.submit(req, "updatePayment", string(patchAsBytes)); err != nil {	//the same arguments on every retry
+		return nil, err
+	}
+	return g.evaluate("getPaymentByID", record.PaymentID)
+}
+
+func shipmentArgs(record shipment.Shipment) []string {
+	return []string{record.ShipmentID, record.TransID, record.AgreementID, record.Shipment_status, record.Source,
+		record.Destination, record.ActualDelivery_date, record.Shipment_date, record.ShipperName}
+}
+
+func createShipment(g *Gateway, req *request) (interface{}, error) {
+	record := shipment.Shipment{}
+	if err := decodeBody(req, &record); err != nil {
+		return nil, err
+	}
+	if err := g.submit(req, "create_shipment", shipmentArgs(record)...); err != nil {
+		return nil, err
+	}
+	shipmentId := createdID(req, record.ShipmentID)
+	req.location = "/shipments/" + shipmentId
+	return g.evaluate("getShipment_byID", shipmentId)
+}
+
+func updateShipment(g *Gateway, req *request) (interface{}, error) {
+	record := shipment.Shipment{}
+	if err := decodeBody(req, &record); err != nil {
+		return nil, err
+	}
+	if err := checkID(req, &record.ShipmentID); err != nil {
+		return nil, err
+	}
+	if err := g.submit(req, "update_shipment", router.WithVersion(shipmentArgs(record), record.Version)...); err != nil {
+		return nil, err
+	}
+	return g.evaluate("getShipment_byID", record.ShipmentID)
+}
@@ -0,0 +1,350 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements.  See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership.  The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License.  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+// Package mockstub is an in-memory ChaincodeStubInterface for running the trade-finance functions
+// without a peer. Every call runs as one transaction: its state writes and events are recorded, and its
+// writes are committed when it returns, not when it returns an error, as a peer would. As on a peer,
+// GetState reads the committed state, not the writes of the running transaction.
+package mockstub
+
+import (
+"crypto/ecdsa"
+"crypto/elliptic"
+"crypto/rand"
+"crypto/x509"
+"crypto/x509/pkix"
+"encoding/binary"
+"encoding/pem"
+"fmt"
+"math/big"
+"time"
+"encoding/json"
+
+"github.com/golang/protobuf/proto"
+"github.com/golang/protobuf/ptypes/timestamp"
+"github.com/hyperledger/fabric-chaincode-go/shim"
+"github.com/hyperledger/fabric-protos-go/common"
+pb "github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// Chaincode runs the functions of a chaincode by their original names, e.g. a *router.Router
+type Chaincode interface {
+	Init(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error)
+	Call(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error)
+}
+
+// Write is a state change of a transaction, Value is nil when the key was deleted
+type Write struct {
+	Key string
+	Value []byte
+}
+
+// Event is an event set by a transaction
+type Event struct {
+	Name string
+	Payload []byte
+}
+
+// MockStub keeps the state of one chaincode in memory, the stub methods the chaincodes do not call are left nil
+type MockStub struct {
+	shim.ChaincodeStubInterface
+	Name string
+	State map[string][]byte							// the committed state
+	Writes []Write									// writes of the last transaction, committed when it succeeds
+	Events []Event									// events of the last transaction
+	TxID string
+	TxTime time.Time								// advances by a second with every transaction, set it ahead to move the clock
+	Creator []byte									// the serialized identity of the caller, see Identity
+	function string
+	args []string
+	entry string									// the chaincode the transaction was submitted to
+	ledger *ledger
+}
+
+// ledger is shared by the stubs of the chaincodes deployed together, they count transactions and keep time together
+type ledger struct {
+	peers map[string]*deployed
+	txCount int
+	now time.Time
+}
+
+// deployed is a chaincode reachable through InvokeChaincode, with its own state
+type deployed struct {
+	cc Chaincode
+	stub *MockStub
+}
+
+// ============================================================================================================================
+// New - an empty ledger for the chaincode name
+// ============================================================================================================================
+func New(name string) *MockStub {
+	start := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	s := &MockStub{
+		Name: name,
+		State: map[string][]byte{},
+		TxTime: start,
+		ledger: &ledger{peers: map[string]*deployed{}, now: start},
+	}
+	s.ledger.peers[name] = &deployed{stub: s}
+	return s
+}
+// ============================================================================================================================
+// Deploy - make another chaincode with its own empty state reachable through InvokeChaincode under name, and return its stub
+// ============================================================================================================================
+func (s *MockStub) Deploy(name string, cc Chaincode) *MockStub {
+	other := New(name)
+	other.ledger = s.ledger
+	other.TxTime = s.ledger.now
+	s.ledger.peers[name] = &deployed{cc: cc, stub: other}
+	return other
+}
+// ============================================================================================================================
+// Init - run the Init of a chaincode as a transaction, the chaincode is reachable by the name of the stub from then on
+// ============================================================================================================================
+func (s *MockStub) Init(cc Chaincode, args ...string) ([]byte, error) {
+	s.ledger.peers[s.Name].cc = cc
+	return s.transact("init", args, func() ([]byte, error) {
+		return cc.Init(s, "init", args)
+	})
+}
+// ============================================================================================================================
+// Call - run a function of a chaincode as a transaction
+// ============================================================================================================================
+func (s *MockStub) Call(cc Chaincode, function string, args ...string) ([]byte, error) {
+	return s.transact(function, args, func() ([]byte, error) {
+		return cc.Call(s, function, args)
+	})
+}
+// ============================================================================================================================
+// transact - start a new transaction, run f and commit its writes with those of the chaincodes it called, none when it fails
+// ============================================================================================================================
+func (s *MockStub) transact(function string, args []string, f func() ([]byte, error)) ([]byte, error) {
+	if s.TxTime.After(s.ledger.now) {
+		s.ledger.now = s.TxTime
+	}
+	s.ledger.txCount++
+	s.ledger.now = s.ledger.now.Add(time.Second)
+	s.TxID = fmt.Sprintf("tx%d", s.ledger.txCount)
+	s.TxTime = s.ledger.now
+	s.function, s.args, s.entry = function, args, s.Name
+	for _, peer := range s.ledger.peers {
+		peer.stub.Writes, peer.stub.Events = nil, nil
+	}
+	s.Writes, s.Events = nil, nil
+	valAsBytes, err := f()
+	if err != nil {
+		for _, peer := range s.ledger.peers {
+			peer.stub.Writes, peer.stub.Events = nil, nil
+		}
+		s.Writes, s.Events = nil, nil
+		return valAsBytes, err
+	}
+	for _, peer := range s.ledger.peers {
+		peer.stub.commit()								//the stub of every chaincode, this one among them
+	}
+	return valAsBytes, err
+}
+// ============================================================================================================================
+// commit - apply the writes of the current transaction to the committed state, in the order they were made
+// ============================================================================================================================
+func (s *MockStub) commit() {
+	for _, w := range s.Writes {
+		if w.Value == nil {
+			delete(s.State, w.Key)
+		}else{
+			s.State[w.Key] = w.Value
+		}
+	}
+}
+// ============================================================================================================================
+// LastEvent - the event a peer would emit for the last transaction, Fabric keeps only the last one set
+// ============================================================================================================================
+func (s *MockStub) LastEvent() Event {
+	if len(s.Events) == 0 {
+		return Event{}
+	}
+	return s.Events[len(s.Events)-1]
+}
+// ============================================================================================================================
+// Message - the "message" field of the last event, empty when there is none
+// ============================================================================================================================
+func (s *MockStub) Message() string {
+	msg := struct {
+		Message string `json:"message"`
+	}{}
+	json.Unmarshal(s.LastEvent().Payload, &msg)
+	return msg.Message
+}
+// ============================================================================================================================
+// Failed - whether the last transaction ended with an errEvent
+// ============================================================================================================================
+func (s *MockStub) Failed() bool {
+	return s.LastEvent().Name == "errEvent"
+}
+// ============================================================================================================================
+// GetState - the committed value of a key, nil when it does not exist. The writes of the running transaction are not seen
+// ============================================================================================================================
+func (s *MockStub) GetState(key string) ([]byte, error) {
+	return s.State[key], nil
+}
+// ============================================================================================================================
+// PutState - write a key, committed with the transaction
+// ============================================================================================================================
+func (s *MockStub) PutState(key string, value []byte) error {
+	if key == "" {
+		return fmt.Errorf("key must not be an empty string")
+	}
+	s.Writes = append(s.Writes, Write{Key: key, Value: value})
+	return nil
+}
+// ============================================================================================================================
+// DelState - delete a key, committed with the transaction
+// ============================================================================================================================
+func (s *MockStub) DelState(key string) error {
+	s.Writes = append(s.Writes, Write{Key: key})
+	return nil
+}
+// ============================================================================================================================
+// SetEvent - record an event
+// ============================================================================================================================
+func (s *MockStub) SetEvent(name string, payload []byte) error {
+	if name == "" {
+		return fmt.Errorf("event name can not be empty string")
+	}
+	s.Events = append(s.Events, Event{Name: name, Payload: payload})
+	return nil
+}
+// ============================================================================================================================
+// InvokeChaincode - run a function of a deployed chaincode on its own state as part of the current transaction, its events
+// are not passed on and its writes are undone when it fails
+// ============================================================================================================================
+func (s *MockStub) InvokeChaincode(chaincodeName string, args [][]byte, channel string) pb.Response {
+	peer, found := s.ledger.peers[chaincodeName]
+	if !found || peer.cc == nil || len(args) == 0 {
+		return shim.Error("Chaincode " + chaincodeName + " could not be found")
+	}
+	var ccArgs []string
+	for _, arg := range args[1:] {
+		ccArgs = append(ccArgs, string(arg))
+	}
+	other := peer.stub
+	other.TxID, other.TxTime, other.Creator = s.TxID, s.TxTime, s.Creator
+	other.function, other.args, other.entry = string(args[0]), ccArgs, s.entry
+	written := len(other.Writes)
+	valAsBytes, err := peer.cc.Call(other, string(args[0]), ccArgs)
+	if err != nil {
+		other.Writes = other.Writes[:written]
+		return shim.Error(err.Error())
+	}
+	return shim.Success(valAsBytes)
+}
+// ============================================================================================================================
+// GetFunctionAndParameters - the function and arguments of the current transaction
+// ============================================================================================================================
+func (s *MockStub) GetFunctionAndParameters() (string, []string) {
+	return s.function, s.args
+}
+// ============================================================================================================================
+// GetStringArgs - the function and arguments of the current transaction as one list
+// ============================================================================================================================
+func (s *MockStub) GetStringArgs() []string {
+	return append([]string{s.function}, s.args...)
+}
+// ============================================================================================================================
+// GetArgs - the function and arguments of the current transaction as bytes
+// ============================================================================================================================
+func (s *MockStub) GetArgs() [][]byte {
+	var args [][]byte
+	for _, arg := range s.GetStringArgs() {
+		args = append(args, []byte(arg))
+	}
+	return args
+}
+// ============================================================================================================================
+// GetTxID - the ID of the current transaction
+// ============================================================================================================================
+func (s *MockStub) GetTxID() string {
+	return s.TxID
+}
+// ============================================================================================================================
+// GetChannelID - the channel of the mock ledger
+// ============================================================================================================================
+func (s *MockStub) GetChannelID() string {
+	return "mychannel"
+}
+// ============================================================================================================================
+// GetTxTimestamp - the time of the current transaction
+// ============================================================================================================================
+func (s *MockStub) GetTxTimestamp() (*timestamp.Timestamp, error) {
+	return &timestamp.Timestamp{Seconds: s.TxTime.Unix(), Nanos: int32(s.TxTime.Nanosecond())}, nil
+}
+// ============================================================================================================================
+// Identity - a caller of the MSP mspID with a self-signed certificate for commonName, serialized as a peer passes it to
+// GetCreator: an msp.SerializedIdentity protobuf of the MSP ID and the PEM certificate. Set it as Creator
+// ============================================================================================================================
+func Identity(mspID string, commonName string) ([]byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	template := &x509.Certificate{SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: commonName},
+		NotBefore: time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC), NotAfter: time.Date(2040, time.January, 1, 0, 0, 0, 0, time.UTC)}
+	certAsBytes, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certAsBytes})
+	return append(protoField(1, []byte(mspID)), protoField(2, certPEM)...), nil
+}
+// ============================================================================================================================
+// protoField - a length-delimited protobuf field
+// ============================================================================================================================
+func protoField(number int, value []byte) []byte {
+	field := binary.AppendUvarint([]byte{byte(number << 3 | 2)}, uint64(len(value)))
+	return append(field, value...)
+}
+// ============================================================================================================================
+// GetCreator - the serialized identity of the caller
+// ============================================================================================================================
+func (s *MockStub) GetCreator() ([]byte, error) {
+	return s.Creator, nil
+}
+// ============================================================================================================================
+// GetSignedProposal - a proposal naming the chaincode the transaction was submitted to, unsigned, the caller is in Creator
+// ============================================================================================================================
+func (s *MockStub) GetSignedProposal() (*pb.SignedProposal, error) {
+	extensionAsBytes, err := proto.Marshal(&pb.ChaincodeHeaderExtension{ChaincodeId: &pb.ChaincodeID{Name: s.entry}})
+	if err != nil {
+		return nil, err
+	}
+	channelHeaderAsBytes, err := proto.Marshal(&common.ChannelHeader{Type: int32(common.HeaderType_ENDORSER_TRANSACTION),
+		ChannelId: s.GetChannelID(), TxId: s.TxID, Extension: extensionAsBytes})
+	if err != nil {
+		return nil, err
+	}
+	headerAsBytes, err := proto.Marshal(&common.Header{ChannelHeader: channelHeaderAsBytes})
+	if err != nil {
+		return nil, err
+	}
+	proposalAsBytes, err := proto.Marshal(&pb.Proposal{Header: headerAsBytes})
+	if err != nil {
+		return nil, err
+	}
+	return &pb.SignedProposal{ProposalBytes: proposalAsBytes}, nil
+}
@@ -0,0 +1,55 @@
+// Package mocktest holds the test assertions on the mock ledger, apart from mockstub so that the programs running the
+// chaincodes on it do not link the testing package
+package mocktest
+
+import (
+"strings"
+"testing"
+
+"github.com/wipro-blockchain/TF-v1/internal/mockstub"
+)
+
+// ============================================================================================================================
+// Succeed - run a function and stop the test unless it ends without an error or errEvent, returns its response
+// ============================================================================================================================
+func Succeed(t testing.TB, s *mockstub.MockStub, cc mockstub.Chaincode, function string, args ...string) []byte {
+	t.Helper()
+	valAsBytes, err := s.Call(cc, function, args...)
+	if err != nil {
+		t.Fatalf("%s failed: %s", function, err)
+	}
+	if s.Failed() {
+		t.Fatalf("%s failed: %s", function, s.LastEvent().Payload)
+	}
+	return valAsBytes
+}
+// ============================================================================================================================
+// Reject - run a function and stop the test unless it ends with an error or errEvent containing message
+// ============================================================================================================================
+func Reject(t testing.TB, s *mockstub.MockStub, cc mockstub.Chaincode, function string, message string, args ...string) {
+	t.Helper()
+	_, err := s.Call(cc, function, args...)
+	switch {
+	case err != nil:
+		if !strings.Contains(err.Error(), message) {
+			t.Fatalf("%s failed with %q, expected %q", function, err, message)
+		}
+	case s.Failed():
+		if !strings.Contains(string(s.LastEvent().Payload), message) {
+			t.Fatalf("%s failed with %s, expected %q", function, s.LastEvent().Payload, message)
+		}
+	default:
+		t.Fatalf("%s succeeded, expected %q", function, message)
+	}
+}
+// ============================================================================================================================
+// ActAs - submit the next transactions on s as the certificate commonName of mspID
+// ============================================================================================================================
+func ActAs(t testing.TB, s *mockstub.MockStub, mspID string, commonName string) {
+	t.Helper()
+	creator, err := mockstub.Identity(mspID, commonName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Creator = creator
+}
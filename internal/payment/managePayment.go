@@ -0,0 +1,2715 @@
+/*/*
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements.  See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership.  The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License.  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package payment
+
+import (
+"errors"
+"strconv"
+"strings"
+"time"
+"math"
+"regexp"
+"sort"
+"unicode"
+"encoding/json"
+"encoding/csv"
+"encoding/xml"
+	//"time"
+	//"strings"
+
+"github.com/hyperledger/fabric-chaincode-go/shim"
+"github.com/wipro-blockchain/TF-v1/events"
+"github.com/wipro-blockchain/TF-v1/internal/router"
+)
+
+// ManagePayment is the Payment domain of the trade-finance chaincodes
+type ManagePayment struct {
+	linker router.Linker						// reaches the other chaincodes, see router.Linker
+}
+
+var PaymentIndexStr = "_PaymentIndex"	//name for the key/value that will store a list of all known payments
+var paymentCreateFields = []string{"paymentId", "agreementId", "buyerName", "sellerName", "amountTransferred", "paymentCUDate",
+	"paymentStatus", "paymentDeadlineDate", "buyerBank_sign", "bb_name", "sb_name"}	//createPayment arguments by JSON name, in order
+var paymentUpdateFields = []string{"paymentId", "agreementId", "buyerName", "sellerName", "buyerAccount", "sellerAccount",
+	"amountTransferred", "paymentCUDate", "paymentStatus", "paymentDeadlineDate", "buyerBank_sign", "bb_name", "sb_name", "version"}	//updatePayment arguments by JSON name, in order
+
+var AccountIndexStr = "_AccountIndex"	//name for the key/value that will store a list of all known accounts
+var BuyerAccountNumber = "965832147012"
+var SellerAccountNumber = "741258963512"
+var EscrowAccountNumber = "100000000001"		//account owned by the chaincode that holds funds in escrow
+
+var EscrowIndexStr = "_EscrowIndex"		//name for the key/value that will store a list of all known escrows
+var ReconciliationIndexStr = "_ReconciliationIndex"		//name for the key/value that will store a list of all reconciled statements
+var ReconciledPaymentsStr = "_ReconciledPayments"		//name for the key/value that will store the statement line matched to each payment
+
+var PaymentCurrency = "USD"				//currency of every amount handled by this chaincode
+var Pain001Namespace = "urn:iso:std:iso:20022:tech:xsd:pain.001.001.03"
+var Camt054Namespace = "urn:iso:std:iso:20022:tech:xsd:camt.054.001.02"
+
+var StatementDateFormats = map[string]string{		//date formats of a bank statement by name, only its bank knows whether 02/01 is in January or February
+	"YYYY-MM-DD": "2006-01-02",
+	"DD/MM/YYYY": "02/01/2006",
+	"MM/DD/YYYY": "01/02/2006",
+	"YYYY/MM/DD": "2006/01/02",
+	"DD-MM-YYYY": "02-01-2006",
+	"RFC3339": time.RFC3339,
+}
+var DefaultStatementDateFormat = "YYYY-MM-DD"
+var PaymentDateLayouts = []string{"2006-01-02", time.RFC3339}		//the unambiguous layouts of a payment date
+
+type Payment struct{
+	PaymentID string `json:"paymentId"`					//the fieldtags are needed to keep case from bouncing around
+	AgreementID string `json:"agreementId"`
+	BuyerName string `json:"buyerName"`					//the fieldtags are needed to keep case from bouncing around
+	SellerName string `json:"sellerName"`
+	BuyerAccount string `json:"buyerAccount" metadata:",optional"`
+	SellerAccount string `json:"sellerAccount" metadata:",optional"`
+	AmountTransferred string `json:"amountTransferred"`
+	PaymentStatus string `json:"paymentStatus"`
+	PaymentCUDate string `json:"paymentCUDate"`
+	PaymentDeadlineDate string `json:"paymentDeadlineDate"`
+	BuyerBank_sign string `json:"buyerBank_sign"`
+	BB_name string `json:"bb_name"`
+	SB_name string `json:"sb_name"`
+	Pain001MsgID string `json:"pain001MsgId" metadata:",optional"`
+	Pain001CreDtTm string `json:"pain001CreDtTm" metadata:",optional"`
+	Camt054MsgID string `json:"camt054MsgId" metadata:",optional"`
+	LiquidatedDamages string `json:"liquidatedDamages" metadata:",optional"`			// deducted for late delivery, see liquidatedDamagesDue
+	Version int `json:"version" metadata:",optional"`						// incremented by every write
+	LastModifiedTxID string `json:"lastModifiedTxId" metadata:",optional"`	// the transaction of the last write
+	CreatedAt string `json:"createdAt" metadata:",optional"`					// the ledger time of the create, see router.TxStamp
+	CreatedBy string `json:"createdBy" metadata:",optional"`					// the caller of the create
+	UpdatedAt string `json:"updatedAt" metadata:",optional"`					// the ledger time of the last write
+	UpdatedBy string `json:"updatedBy" metadata:",optional"`					// the caller of the last write
+}
+
+type AccountInfo struct{
+	BuyerAccountNumber string `json:"buyerAccountNumber"`
+	BuyerAccountBalance string `json:"buyerAccountBalance"`
+	SellerAccountNumber string `json:"sellerAccountNumber"`
+	SellerAccountBalance string `json:"sellerAccountBalance"`
+	EscrowAccountNumber string `json:"escrowAccountNumber"`
+	EscrowAccountBalance string `json:"escrowAccountBalance"`
+}
+
+type StatementLine struct{
+	Date string `json:"date"`
+	Amount string `json:"amount"`
+	Reference string `json:"reference"`
+	Counterparty string `json:"counterparty"`
+}
+
+type ReconciliationResult struct{
+	LineNo string `json:"lineNo"`
+	Date string `json:"date"`
+	Amount string `json:"amount"`
+	Reference string `json:"reference"`
+	Counterparty string `json:"counterparty"`
+	PaymentID string `json:"paymentId"`
+	MatchStatus string `json:"matchStatus"`				//Matched, PartiallyMatched, Unmatched
+	Reason string `json:"reason"`
+	ExceptionStatus string `json:"exceptionStatus"`		//Open, Resolved; empty when matched
+	Resolution string `json:"resolution"`
+}
+
+type Reconciliation struct{
+	StatementID string `json:"statementId"`
+	DateToleranceDays string `json:"dateToleranceDays"`
+	DateFormat string `json:"dateFormat"`
+	Results []ReconciliationResult `json:"results"`
+}
+
+type Escrow struct{
+	EscrowID string `json:"escrowId"`
+	PaymentID string `json:"paymentId"`
+	AgreementID string `json:"agreementId"`
+	Amount string `json:"amount"`
+	EscrowStatus string `json:"escrowStatus"`				//Pending, Held, Released, Refunded, Cancelled
+	Conditions []EscrowCondition `json:"conditions"`
+	Movements []EscrowMovement `json:"movements"`
+}
+
+type EscrowCondition struct{
+	Condition string `json:"condition"`					//e.g. ShipmentDelivered, PortCleared, BuyerAccepted
+	Satisfied string `json:"satisfied"`
+	SatisfiedBy string `json:"satisfiedBy"`
+}
+
+type linkedAgreement struct{					// The fields of the Agreement of a Payment read through the linker
+	AgreementID string `json:"agreementId"`
+	Agreement_status string `json:"agreement_status"`
+	Buyer_name string `json:"buyer_name"`
+	Seller_name string `json:"seller_name"`
+	Shipper_name string `json:"shipper_name"`
+	BB_name string `json:"bb_name"`
+	SB_name string `json:"sb_name"`
+	PortAuth_name string `json:"agreementPortAuth_name"`
+	Clearance_status string `json:"clearance_status"`
+}
+
+type EscrowMovement struct{
+	MovementID string `json:"movementId"`
+	MovementType string `json:"movementType"`			//Deposit, Release, Refund, Deduct
+	FromAccount string `json:"fromAccount"`
+	ToAccount string `json:"toAccount"`
+	Amount string `json:"amount"`
+	Reason string `json:"reason"`
+	TxID string `json:"txId"`
+}
+// ============================================================================================================================
+// New - Payment management, reaching the other chaincodes through linker
+// ============================================================================================================================
+func New(linker router.Linker) *ManagePayment {
+	return &ManagePayment{linker: linker}
+}
+// ============================================================================================================================
+// paymentKey - key under which a Payment is stored, apart from the records of the other types and the indexes
+// ============================================================================================================================
+func paymentKey(paymentId string) string {
+	return "Payment_" + paymentId
+}
+
+// ============================================================================================================================
+// Init - reset all the things
+// ============================================================================================================================
+func (t *ManagePayment) Init(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+	var balance string
+	var err error
+
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting \"Initial_Value\" as an argument.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	// Initialize the chaincode
+	
+	balance = args[0]
+	router.Println("ManagePayment chaincode is deployed successfully.")
+
+	accountIndex := AccountInfo{}
+	accountIndex.BuyerAccountBalance = balance
+	accountIndex.SellerAccountBalance = balance
+	accountIndex.EscrowAccountBalance = "0.00"
+	err = t.putAccounts(stub, accountIndex)
+	if err != nil {
+		return nil, err
+	}
+	
+	var empty []string
+	jsonAsBytes, _ := json.Marshal(empty)								//marshal an emtpy array of strings to clear the index
+	err = stub.PutState(PaymentIndexStr, jsonAsBytes)
+	if err != nil {
+		return nil, err
+	}
+	err = stub.PutState(EscrowIndexStr, jsonAsBytes)
+	if err != nil {
+		return nil, err
+	}
+	err = stub.PutState(ReconciliationIndexStr, jsonAsBytes)
+	if err != nil {
+		return nil, err
+	}
+	err = router.Emit(stub, events.Event{Type: events.ChaincodeDeployed, EntityID: "ManagePayment",
+		Message: "ManagePayment chaincode is deployed successfully."}, nil, nil)
+	if err != nil {
+		return nil, err
+	} 
+	return nil, nil
+}
+
+
+// ============================================================================================================================
+// KeyMoves - the records of Payment management to move from their bare ID to their namespaced key, see router.Migrator
+// ============================================================================================================================
+func (t *ManagePayment) KeyMoves() []router.KeyMove {
+	return []router.KeyMove{{Index: PaymentIndexStr, Key: paymentKey}}
+}
+// ============================================================================================================================
+// Invokes - the invoke functions of Payment management, by function name
+// ============================================================================================================================
+func (t *ManagePayment) Invokes() map[string]router.Handler {
+	return map[string]router.Handler{
+		"createPayment": router.Named(t.createPayment, "createPayment", paymentCreateFields, nil),					//writes a value to the chaincode state
+		"deletePayment": t.deletePayment,					//create a new payment
+		"updatePayment": router.Named(t.updatePayment, "updatePayment", paymentUpdateFields, t.getPaymentByID),					//create a new trade order
+		"createEscrow": t.createEscrow,					//put a payment into escrow mode
+		"satisfyEscrowCondition": t.satisfyEscrowCondition,					//mark a release condition as met
+		"refundEscrow": t.refundEscrow,					//return escrowed funds to the buyer
+		"reconcile_statement": t.reconcile_statement,					//match bank statement lines to payments
+		"resolve_reconciliation_exception": t.resolve_reconciliation_exception,					//close an open reconciliation exception
+		"exportPain001": t.exportPain001,					//assign a pain.001 message ID to a settled payment
+		"importCamt054": t.importCamt054,					//apply a camt.054 bank notification
+		"apply_liquidated_damages": t.apply_liquidated_damages,					//deduct late delivery damages from the payments of an agreement
+	}
+}
+
+// ============================================================================================================================
+// Queries - the query functions of Payment management, by function name
+// ============================================================================================================================
+func (t *ManagePayment) Queries() map[string]router.Handler {
+	return map[string]router.Handler{
+		"getPaymentByID": t.getPaymentByID,					//read a variable
+		"getPaymentByBuyer": t.getPaymentByBuyer,					//read a variable
+		"getPaymentBySeller": t.getPaymentBySeller,					//read a variable
+		"getAllPayment": t.getAllPayment,					//read a variable
+		"getAccountDetails": t.getAccountDetails,					//read a variable
+		"getEscrowByPaymentID": t.getEscrowByPaymentID,					//read the escrow of a payment
+		"getEscrowMovements": t.getEscrowMovements,					//read escrow movements
+		"get_reconciliation": t.get_reconciliation,					//read a reconciled statement
+		"get_reconciliation_exceptions": t.get_reconciliation_exceptions,					//read open reconciliation exceptions
+		"getPaymentPain001": t.getPaymentPain001,					//render a payment as pain.001 XML
+		"getPaymentByAgreement": t.getPaymentByAgreement,					//read the payments of an agreement
+	}
+}
+
+// ============================================================================================================================
+// getPaymentByID - display Payment details for a specific ID from chaincode state
+// ============================================================================================================================
+func (t *ManagePayment) getPaymentByID(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var paymentId string
+	var err error
+	router.Println("start getPaymentByID")
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting \"paymentID\" as an argument.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	// set paymentId
+	paymentId = args[0]
+	valAsbytes, err := stub.GetState(paymentKey(paymentId))									//get the var from chaincode state
+	if err != nil {
+		errMsg := "{ \"message\" : " + router.JSONString(paymentId + " not Found.") + ", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	router.Print("valAsbytes : ")
+	router.Println(valAsbytes)
+	router.Println("end getPaymentByID")
+	return valAsbytes, nil													//send it onward
+}
+// ============================================================================================================================
+//  getPaymentByBuyer - get Payment details by buyer name from chaincode state
+// ============================================================================================================================
+func (t *ManagePayment) getPaymentByBuyer(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var errResp, jsonResp, buyerName string
+	var paymentIndex []string
+	var valIndex Payment
+	var err error
+	router.Println("start getPaymentByBuyer")
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting \"Buyer_Name\" arguments.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+
+	// set buyer name
+	buyerName = args[0]
+	router.Println("buyerName : " + buyerName)
+	paymentAsBytes, err := stub.GetState(PaymentIndexStr)
+	if err != nil {
+		return nil, errors.New("Failed to get Payment index")
+	}
+	router.Print("paymentAsBytes : ")
+	router.Println(paymentAsBytes)
+	json.Unmarshal(paymentAsBytes, &paymentIndex)								//un stringify it aka JSON.parse()
+	router.Print("paymentIndex : ")
+	router.Println(paymentIndex)
+	router.Println("len(paymentIndex) : ")
+	router.Println(len(paymentIndex))
+	jsonResp = "{"
+	for i,val := range paymentIndex{
+		router.Println(strconv.Itoa(i) + " - looking at " + val + " for getPaymentByBuyer")
+		valueAsBytes, err := stub.GetState(paymentKey(val))
+		if err != nil {
+			errResp = "{\"Error\":\"Failed to get state for " + val + "\"}"
+			return nil, errors.New(errResp)
+		}
+		router.Print("valueAsBytes : ")
+		router.Println(valueAsBytes)
+		json.Unmarshal(valueAsBytes, &valIndex)
+		router.Print("valIndex: ")
+		router.Print(valIndex)
+		if valIndex.BuyerName == buyerName{
+			router.Println("Buyer found")
+			jsonResp = jsonResp + "\""+ val + "\":" + string(valueAsBytes[:])
+			router.Println("jsonResp inside if")
+			router.Println(jsonResp)
+			if i < len(paymentIndex)-1 {
+				jsonResp = jsonResp + ","
+			}
+		}else{
+			errMsg := "{ \"message\" : " + router.JSONString(buyerName + " Not Found.") + ", \"code\" : \"503\"}"
+			err = stub.SetEvent("errEvent", []byte(errMsg))
+			if err != nil {
+				return nil, err
+			} 
+			return nil, nil
+		}
+	}
+	jsonResp = jsonResp + "}"
+	router.Println("jsonResp : " + jsonResp)
+	router.Print("jsonResp in bytes : ")
+	router.Println([]byte(jsonResp))
+	router.Println("end getPaymentByBuyer")
+	return []byte(jsonResp), nil													//send it onward
+}
+// ============================================================================================================================
+//  getPaymentBySeller - display Payment details for a specific Seller from chaincode state
+// ============================================================================================================================
+func (t *ManagePayment) getPaymentBySeller(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var errResp, sellerName, jsonResp string
+	var paymentIndex []string
+	var valIndex Payment
+	var err error
+	router.Println("start getPaymentBySeller")
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting \"Seller_Name\" arguments.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	// set seller name
+	sellerName = args[0]
+	router.Println("sellerName: " + sellerName)
+	paymentAsBytes, err := stub.GetState(PaymentIndexStr)
+	if err != nil {
+		return nil, errors.New("Failed to get Payment index")
+	}
+	router.Print("paymentAsBytes : ")
+	router.Println(paymentAsBytes)
+	json.Unmarshal(paymentAsBytes, &paymentIndex)								//un stringify it aka JSON.parse()
+	router.Print("paymentIndex : ")
+	router.Println(paymentIndex)
+	router.Println("len(paymentIndex) : ")
+	router.Println(len(paymentIndex))
+	jsonResp = "{"
+	for i,val := range paymentIndex{
+		router.Println(strconv.Itoa(i) + " - looking at " + val + " for getting sellerName")
+		valueAsBytes, err := stub.GetState(paymentKey(val))
+		if err != nil {
+			errResp = "{\"Error\":\"Failed to get state for " + val + "\"}"
+			return nil, errors.New(errResp)
+		}
+		router.Print("valueAsBytes : ")
+		router.Println(valueAsBytes)
+		json.Unmarshal(valueAsBytes, &valIndex)
+		router.Print("valIndex: ")
+		router.Print(valIndex)
+		if valIndex.SellerName == sellerName{
+			router.Println("Seller found")
+			jsonResp = jsonResp + "\""+ val + "\":" + string(valueAsBytes[:])
+			router.Println("jsonResp inside if")
+			router.Println(jsonResp)
+			if i < len(paymentIndex)-1 {
+				jsonResp = jsonResp + ","
+			}
+		}else{
+			errMsg := "{ \"message\" : " + router.JSONString(sellerName + " Not Found.") + ", \"code\" : \"503\"}"
+			err = stub.SetEvent("errEvent", []byte(errMsg))
+			if err != nil {
+				return nil, err
+			} 
+			return nil, nil
+		}
+	}
+	
+	jsonResp = jsonResp + "}"
+	router.Println("jsonResp : " + jsonResp)
+	router.Print("jsonResp in bytes : ")
+	router.Println([]byte(jsonResp))
+	router.Println("end getPaymentBySeller")
+
+	return []byte(jsonResp), nil											//send it onward
+}
+// ============================================================================================================================
+//  getAllPayment- display details of all Payment from chaincode state
+// ============================================================================================================================
+func (t *ManagePayment) getAllPayment(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var jsonResp, errResp string
+	var paymentIndex []string
+	var err error
+	router.Println("start getAllPayment")
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting \" \" arguments.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	paymentAsBytes, err := stub.GetState(PaymentIndexStr)
+	if err != nil {
+		return nil, errors.New("Failed to get Payment index")
+	}
+	router.Print("paymentAsBytes : ")
+	router.Println(paymentAsBytes)
+	json.Unmarshal(paymentAsBytes, &paymentIndex)								//un stringify it aka JSON.parse()
+	router.Print("paymentIndex : ")
+	router.Println(paymentIndex)
+	jsonResp = "{"
+	for i,val := range paymentIndex{
+		router.Println(strconv.Itoa(i) + " - looking at " + val + " for all Payment")
+		valueAsBytes, err := stub.GetState(paymentKey(val))
+		if err != nil {
+			errResp = "{\"Error\":\"Failed to get state for " + val + "\"}"
+			return nil, errors.New(errResp)
+		}
+		router.Print("valueAsBytes : ")
+		router.Println(valueAsBytes)
+		jsonResp = jsonResp + "\""+ val + "\":" + string(valueAsBytes[:])
+		if i < len(paymentIndex)-1 {
+			jsonResp = jsonResp + ","
+		}
+	}
+	router.Println("len(paymentIndex) : ")
+	router.Println(len(paymentIndex))
+	jsonResp = jsonResp + "}"
+	router.Println("jsonResp : " + jsonResp)
+	router.Print("jsonResp in bytes : ")
+	router.Println([]byte(jsonResp))
+	router.Println("end getAllPayment")
+	return []byte(jsonResp), nil
+											//send it onward
+}
+// ============================================================================================================================
+//  getAccountDetails - get account details from chaincode
+// ============================================================================================================================
+func (t *ManagePayment) getAccountDetails(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var err error
+	router.Println("start getAccountDetails")
+	
+	accountAsBytes, err := stub.GetState(AccountIndexStr)
+	if err != nil {
+		return nil, errors.New("Failed to get Account index")
+	}
+	router.Print("accountAsBytes : ")
+	router.Println(accountAsBytes)
+	
+	router.Println("end getAccountDetails")
+	return accountAsBytes, nil													//send it onward
+}
+// ============================================================================================================================
+//  updateBalance - move transferAmount from the buyer to the seller account, a negative amount moves it back. accountIndex is
+//  the caller's copy of the accounts, stored by the caller with putAccounts
+// ============================================================================================================================
+func updateBalance(accountIndex *AccountInfo, transferAmount float64) {
+	router.Println("start updateBalance")
+	accountBuyerBal, _ := strconv.ParseFloat(accountIndex.BuyerAccountBalance, 64)
+	accountSellerBal, _ := strconv.ParseFloat(accountIndex.SellerAccountBalance, 64)
+	buyerAccountBalance	:= accountBuyerBal - transferAmount
+	sellerAccountBalance := accountSellerBal + transferAmount
+	accountIndex.BuyerAccountBalance = strconv.FormatFloat(buyerAccountBalance, 'f', 2, 64)
+	accountIndex.SellerAccountBalance = strconv.FormatFloat(sellerAccountBalance, 'f', 2, 64)
+	router.Println("end updateBalance")
+}
+// ============================================================================================================================
+//  readAccounts - get the buyer, seller and escrow accounts from chaincode state. A transaction that moves funds more than
+//  once reads them once and stores them once, as it does not read its own writes
+// ============================================================================================================================
+func readAccounts(stub shim.ChaincodeStubInterface) (AccountInfo, error) {
+	var accountIndex AccountInfo
+	accountAsBytes, err := stub.GetState(AccountIndexStr)
+	if err != nil {
+		return accountIndex, errors.New("Failed to get Account index")
+	}
+	json.Unmarshal(accountAsBytes, &accountIndex)
+	return accountIndex, nil
+}
+// ============================================================================================================================
+//  putAccounts - store the buyer, seller and escrow accounts into chaincode state
+// ============================================================================================================================
+func (t *ManagePayment) putAccounts(stub shim.ChaincodeStubInterface, accountIndex AccountInfo) error {
+	if accountIndex.EscrowAccountBalance == "" {
+		accountIndex.EscrowAccountBalance = "0.00"
+	}
+	//build the Account json string manually
+	account := `{`+
+		`"buyerAccountNumber" : "` +  BuyerAccountNumber  + `", `+
+		`"buyerAccountBalance" : "` + accountIndex.BuyerAccountBalance   + `", `+
+		`"sellerAccountNumber" : "` +  SellerAccountNumber  + `", `+
+		`"sellerAccountBalance" : "` + accountIndex.SellerAccountBalance   + `", `+
+		`"escrowAccountNumber" : "` +  EscrowAccountNumber  + `", `+
+		`"escrowAccountBalance" : "` + accountIndex.EscrowAccountBalance   + `"`+
+		`}`
+	router.Println("In putAccounts account to commit::" + account)
+
+	return stub.PutState(AccountIndexStr, []byte(account))			//store Account with id as key
+}
+// ============================================================================================================================
+// Delete - remove a Payment from state
+// ============================================================================================================================
+func (t *ManagePayment) deletePayment(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting \"paymentID\" arguments.\", \"code\" : \"503\"}"
+		err := stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	// set paymentId
+	paymentId := args[0]
+	recordAsBytes, err := stub.GetState(paymentKey(paymentId))
+	if err != nil {
+		errMsg := "{ \"message\" : " + router.JSONString("Failed to get state for " + paymentId) + ", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	err = stub.DelState(paymentKey(paymentId))													//remove the key from chaincode state
+	if err != nil {
+		errMsg := "{ \"message\" : \"Failed to delete state\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+
+	//get the payment index
+	paymentAsBytes, err := stub.GetState(PaymentIndexStr)
+	if err != nil {
+		return nil, errors.New("Failed to get Payment index")
+	}
+	router.Println("paymentAsBytes in delete payment")
+	router.Println(paymentAsBytes);
+	var paymentIndex []string
+	json.Unmarshal(paymentAsBytes, &paymentIndex)								//un stringify it aka JSON.parse()
+	router.Println("paymentIndex in delete payment")
+	router.Println(paymentIndex);
+	//remove payment from index
+	for i,val := range paymentIndex{
+		router.Println(strconv.Itoa(i) + " - looking at " + val + " for " + paymentId)
+		if val == paymentId{															//find the correct payment
+			router.Println("found payment")
+			paymentIndex = append(paymentIndex[:i], paymentIndex[i+1:]...)			//remove it
+			for x:= range paymentIndex{											//debug prints...
+				router.Println(strconv.Itoa(x) + " - " + paymentIndex[x])
+			}
+			break
+		}
+	}
+	jsonAsBytes, _ := json.Marshal(paymentIndex)									//save new index
+	err = stub.PutState(PaymentIndexStr, jsonAsBytes)
+	err = router.Emit(stub, events.Event{Type: events.PaymentDeleted, EntityID: paymentId, Message: "Payment deleted succcessfully"},
+		recordAsBytes, nil)
+	if err != nil {
+		return nil, err
+	} 
+	return nil, nil
+}
+
+// ============================================================================================================================
+// Write - update Payment into chaincode state
+// ============================================================================================================================
+func (t *ManagePayment) updatePayment(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var jsonResp string
+	var err error
+	router.Println("running updatePayment()")
+
+	args, expectedVersion := router.ExpectedVersion(args, 13)
+	if len(args) != 13 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting 13 arguments, or 14 with the expected version.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	//set paymentId
+	paymentId := args[0]
+	paymentAsBytes, err := stub.GetState(paymentKey(paymentId))									//get the var from chaincode state
+	if err != nil {
+		jsonResp = "{\"Error\":\"Failed to get state for " + paymentId + "\"}"
+		return nil, errors.New(jsonResp)
+	}
+	router.Print("paymentAsBytes in update payment")
+	router.Println(paymentAsBytes);
+	res := Payment{}
+	json.Unmarshal(paymentAsBytes, &res)
+	previous := res
+	if res.PaymentID == paymentId{
+		router.Println("Payment found with id : " + paymentId)
+		router.Println(res);
+		err = router.CheckVersion(paymentId, res.Version, expectedVersion)
+		if err != nil {
+			err = router.VersionEvent(stub, err)
+			if err != nil {
+				return nil, err
+			}
+			return nil, nil
+		}
+		if res.AgreementID != args[1] || res.BuyerName != args[2] || res.SellerName != args[3] {
+			err = t.checkLinkedAgreement(stub, args[1], args[2], args[3])
+			if err != nil {
+				errMsg := "{ \"paymentID\" : " + router.JSONString(paymentId) + ", \"message\" : " + router.JSONString(err.Error() + ".") + ", \"code\" : \"503\"}"
+				err = stub.SetEvent("errEvent", []byte(errMsg))
+				if err != nil {
+					return nil, err
+				} 
+				return nil, nil
+			}
+		}
+
+		if args[7] != res.PaymentCUDate {
+			err = router.CheckNotPast(stub, "paymentCUDate", args[7])
+			if err != nil {
+				err = router.ErrorEvent(stub, err)
+				if err != nil {
+					return nil, err
+				}
+				return nil, nil
+			}
+		}
+
+		res.AgreementID = args[1]
+		res.BuyerName = args[2]
+		res.SellerName = args[3]
+		res.BuyerAccount = args[4]
+		res.SellerAccount = args[5]
+		res.AmountTransferred = args[6]
+		res.PaymentCUDate = args[7]
+		res.PaymentStatus = args[8]
+		res.PaymentDeadlineDate = args[9]
+		res.BuyerBank_sign = args[10]
+		res.BB_name = args[11]
+		res.SB_name = args[12]
+		res.Version++
+		res.LastModifiedTxID = stub.GetTxID()
+		res.UpdatedAt, res.UpdatedBy, err = router.TxStamp(stub)
+		if err != nil {
+			return nil, err
+		}
+	}else{
+		errMsg := "{ \"message\" : " + router.JSONString(paymentId + " Not Found.") + ", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	
+	if res.BuyerBank_sign == "true"{
+		escrowAsBytes, err := stub.GetState(escrowKey(paymentId))
+		if err != nil {
+			return nil, errors.New("Failed to get Escrow for " + paymentId)
+		}
+		escrow := Escrow{}
+		json.Unmarshal(escrowAsBytes, &escrow)
+		accounts, err := readAccounts(stub)
+		if err != nil {
+			return nil, err
+		}
+		damages, err := t.liquidatedDamagesDue(stub, res)
+		if err != nil {
+			return nil, err
+		}
+		if escrow.PaymentID == paymentId{
+			router.Println("Payment is in escrow mode, moving funds into escrow :: " + res.AmountTransferred)
+			if escrow.EscrowStatus == "Pending"{
+				escrow.Amount = res.AmountTransferred
+				amount, _ := strconv.ParseFloat(escrow.Amount, 64)
+				err = t.moveEscrowFunds(stub, &escrow, &accounts, "Deposit", amount, "Buyer bank signed payment")
+				if err != nil {
+					return nil, err
+				}
+				if damages > 0 {
+					err = t.deductLiquidatedDamages(stub, &res, &escrow, &accounts, damages)
+					if err != nil {
+						return nil, err
+					}
+				}
+				if escrowSatisfied(escrow){					//the conditions were met before the deposit
+					err = t.moveEscrowFunds(stub, &escrow, &accounts, "Release", escrowBalance(escrow), "All release conditions satisfied")
+					if err != nil {
+						return nil, err
+					}
+				}
+				err = t.putAccounts(stub, accounts)
+				if err != nil {
+					return nil, err
+				}
+				err = t.putEscrow(stub, escrow)
+				if err != nil {
+					return nil, err
+				}
+			}else if (escrow.EscrowStatus == "Refunded" || escrow.EscrowStatus == "Cancelled") && router.Signed(previous.BuyerBank_sign, res.BuyerBank_sign){
+				errMsg := "{ \"paymentID\" : " + router.JSONString(paymentId) + ", \"message\" : " + router.JSONString("Payment can not be settled, its escrow is " + escrow.EscrowStatus + ".") + ", \"code\" : \"503\"}"
+				err = stub.SetEvent("errEvent", []byte(errMsg))
+				if err != nil {
+					return nil, err
+				} 
+				return nil, nil
+			}
+		}else if router.Signed(previous.BuyerBank_sign, res.BuyerBank_sign){					//only the update signing it moves funds
+			router.Println("Buyer Bank sign is true with amount to be transferred :: " + res.AmountTransferred)
+			amount, _ := strconv.ParseFloat(res.AmountTransferred, 64)
+			updateBalance(&accounts, amount - damages)					//the seller is paid less the damages it owes
+			res.LiquidatedDamages = addAmount(res.LiquidatedDamages, damages)
+			err = t.putAccounts(stub, accounts)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	order := paymentJSON(res)										//build the Payment json string
+	err = stub.PutState(paymentKey(paymentId), []byte(order))									//store Payment with id as key
+	if err != nil {
+		return nil, err
+	}
+
+	eventType := events.PaymentUpdated
+	if router.Signed(previous.BuyerBank_sign, res.BuyerBank_sign) {
+		eventType = events.PaymentSettled
+	}
+	err = router.Emit(stub, events.Event{Type: eventType, EntityID: paymentId, Status: res.PaymentStatus,
+		Message: "Payment updated succcessfully"}, paymentAsBytes, order)
+	if err != nil {
+		return nil, err
+	} 	
+	router.Println("end updatePayment()")
+	return nil, nil
+}
+
+// ============================================================================================================================
+// paymentJSON - build the Payment json string manually
+// ============================================================================================================================
+func paymentJSON(res Payment) string {
+	return `{`+
+		`"paymentId" : "` + res.PaymentID   + `", `+
+		`"agreementId" : "` + res.AgreementID   + `", `+
+		`"buyerName" : "` + res.BuyerName   + `", `+
+		`"sellerName" : "` + res.SellerName   + `", `+
+		`"buyerAccount" : "` + res.BuyerAccount   + `", `+
+		`"sellerAccount" : "` + res.SellerAccount   + `", `+
+		`"amountTransferred" : "` + res.AmountTransferred   + `", `+
+		`"paymentCUDate" : "` + res.PaymentCUDate   + `", `+
+		`"paymentStatus" : "` + res.PaymentStatus   + `", `+
+		`"paymentDeadlineDate" : "` + res.PaymentDeadlineDate   + `", `+
+		`"buyerBank_sign" : "` + res.BuyerBank_sign   + `", `+
+		`"bb_name" : "` + res.BB_name   + `", `+
+		`"sb_name" : "` + res.SB_name   + `", `+
+		`"pain001MsgId" : "` + res.Pain001MsgID   + `", `+
+		`"pain001CreDtTm" : "` + res.Pain001CreDtTm   + `", `+
+		`"camt054MsgId" : "` + res.Camt054MsgID   + `", `+
+		`"liquidatedDamages" : "` + res.LiquidatedDamages   + `", `+
+		`"version" : ` + strconv.Itoa(res.Version)   + `, `+
+		`"lastModifiedTxId" : "` + res.LastModifiedTxID   + `", `+
+		`"createdAt" : "` + res.CreatedAt   + `", `+
+		`"createdBy" : ` + router.JSONString(res.CreatedBy)   + `, `+
+		`"updatedAt" : "` + res.UpdatedAt   + `", `+
+		`"updatedBy" : ` + router.JSONString(res.UpdatedBy)   + ``+
+		`}`
+}
+
+// ============================================================================================================================
+// Init Payment- create a new Payment, store into chaincode state
+// ============================================================================================================================
+func (t *ManagePayment) createPayment(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var err error
+	if len(args) != 11 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting 11 arguments.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	//input sanitation
+	router.Println("- start createPayment")
+	/*if len(args[0]) <= 0 {
+		return nil, errors.New("1st argument must be a non-empty string")
+	}
+	if len(args[1]) <= 0 {
+		return nil, errors.New("2nd argument must be a non-empty string")
+	}
+	if len(args[2]) <= 0 {
+		return nil, errors.New("3rd argument must be a non-empty string")
+	}
+	if len(args[3]) <= 0 {
+		return nil, errors.New("4th argument must be a non-empty string")
+	}
+	if len(args[4]) <= 0 {
+		return nil, errors.New("5th argument must be a non-empty string")
+	}
+	if len(args[5]) <= 0 {
+		return nil, errors.New("6th argument must be a non-empty string")
+	}
+	if len(args[6]) <= 0 {
+		return nil, errors.New("7th argument must be a non-empty string")
+	}
+	if len(args[7]) <= 0 {
+		return nil, errors.New("8th argument must be a non-empty string")
+	}
+	if len(args[8]) <= 0 {
+		return nil, errors.New("9th argument must be a non-empty string")
+	}
+	if len(args[9]) <= 0 {
+		return nil, errors.New("10th argument must be a non-empty string")
+	}
+*/
+	paymentId := args[0]
+	if paymentId == "" {
+		paymentId, err = router.NewID(stub, "PAY-", paymentKey)			//no ID given, one is derived from the transaction ID
+		if err != nil {
+			return nil, err
+		}
+	}
+	agreementId := args[1]
+	buyerName := args[2]
+	sellerName := args[3]
+	buyerAccount := BuyerAccountNumber
+	sellerAccount := SellerAccountNumber
+	amountTransferred := args[4]
+	paymentCUDate := args[5]
+	paymentStatus := args[6]
+	paymentDeadlineDate := args[7]
+	buyerBank_sign := args[8]
+	bb_name := args[9]
+	sb_name := args[10]
+	createdAt, createdBy, err := router.TxStamp(stub)
+	if err != nil {
+		return nil, err
+	}
+	paymentCUDate, err = router.RecordDate(stub, "paymentCUDate", paymentCUDate)
+	if err != nil {
+		err = router.ErrorEvent(stub, err)
+		if err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+	err = router.CheckNotPast(stub, "paymentDeadlineDate", paymentDeadlineDate)
+	if err != nil {
+		err = router.ErrorEvent(stub, err)
+		if err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	paymentAsBytes, err := stub.GetState(paymentKey(paymentId))
+	if err != nil {
+		return nil, errors.New("Failed to get Payment paymentId")
+	}
+	router.Print("paymentAsBytes: ")
+	router.Println(paymentAsBytes)
+	res := Payment{}
+	json.Unmarshal(paymentAsBytes, &res)
+	router.Print("res: ")
+	router.Println(res)
+	if res.PaymentID == paymentId{
+		router.Println("This Payment arleady exists: " + paymentId)
+		errMsg := "{ \"message\" : \"This Payment arleady exists.\", \"code\" : \"503\"}"
+		err := stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil				//all stop a payment by this name exists
+	}
+	err = t.checkLinkedAgreement(stub, agreementId, buyerName, sellerName)
+	if err != nil {
+		errMsg := "{ \"paymentID\" : " + router.JSONString(paymentId) + ", \"message\" : " + router.JSONString(err.Error() + ".") + ", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	
+	//build the Payment json string manually
+	
+	order := `{`+
+		`"paymentId" : "` + paymentId   + `", `+
+		`"agreementId" : "` + agreementId   + `", `+
+		`"buyerName" : "` + buyerName   + `", `+
+		`"sellerName" : "` + sellerName   + `", `+
+		`"buyerAccount" : "` + buyerAccount   + `", `+
+		`"sellerAccount" : "` + sellerAccount   + `", `+
+		`"amountTransferred" : "` + amountTransferred   + `", `+
+		`"paymentCUDate" : "` + paymentCUDate   + `", `+
+		`"paymentStatus" : "` + paymentStatus   + `", `+
+		`"paymentDeadlineDate" : "` + paymentDeadlineDate   + `", `+
+		`"buyerBank_sign" : "` + buyerBank_sign   + `", `+
+		`"bb_name" : "` + bb_name   + `", `+
+		`"sb_name" : "` + sb_name   + `", `+
+		`"pain001MsgId" : "", `+
+		`"pain001CreDtTm" : "", `+
+		`"camt054MsgId" : "", `+
+		`"liquidatedDamages" : "", `+
+		`"version" : 1, `+
+		`"lastModifiedTxId" : "` + stub.GetTxID()   + `", `+
+		`"createdAt" : "` + createdAt   + `", `+
+		`"createdBy" : ` + router.JSONString(createdBy)   + `, `+
+		`"updatedAt" : "` + createdAt   + `", `+
+		`"updatedBy" : ` + router.JSONString(createdBy)   + ``+
+		`}`
+
+	err = stub.PutState(paymentKey(paymentId), []byte(order))									//store Payment with id as key
+	if err != nil {
+		return nil, err
+	}
+	
+	//get the Payment index
+	paymentIndexAsBytes, err := stub.GetState(PaymentIndexStr)
+	if err != nil {
+		return nil, errors.New("Failed to get Payment index")
+	}
+	var paymentIndex []string
+	router.Print("paymentIndexAsBytes: ")
+	router.Println(paymentIndexAsBytes)
+	
+	json.Unmarshal(paymentIndexAsBytes, &paymentIndex)							//un stringify it aka JSON.parse()
+	router.Print("paymentIndexAsBytes after unmarshal..before append: ")
+	router.Println(paymentIndexAsBytes)
+	
+	//append
+	paymentIndex = append(paymentIndex, paymentId)									//add Payment paymentId to index list
+	router.Println("! Payment index: ", paymentIndex)
+	jsonAsBytes, _ := json.Marshal(paymentIndex)
+	router.Print("jsonAsBytes: ")
+	router.Println(jsonAsBytes)
+	err = stub.PutState(PaymentIndexStr, jsonAsBytes)						//store name of Payment
+	if err != nil {
+		return nil, err
+	}
+
+	err = router.Emit(stub, events.Event{Type: events.PaymentCreated, EntityID: paymentId, Status: paymentStatus,
+		Message: "Payment created succcessfully"}, nil, order)
+	if err != nil {
+		return nil, err
+	} 
+
+	router.Println("end createPayment()")
+	return []byte(paymentId), nil
+}
+// ============================================================================================================================
+// escrowKey - key under which the escrow of a payment is stored
+// ============================================================================================================================
+func escrowKey(paymentId string) string {
+	return "Escrow_" + paymentId
+}
+// ============================================================================================================================
+// createEscrow - put a Payment into escrow mode with its release conditions
+// ============================================================================================================================
+func (t *ManagePayment) createEscrow(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	// createEscrow("paymentId", "condition1", "condition2", ...)
+	var err error
+	if len(args) < 2 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting \"paymentID\" and at least one release condition.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	router.Println("start createEscrow")
+	paymentId := args[0]
+
+	paymentAsBytes, err := stub.GetState(paymentKey(paymentId))
+	if err != nil {
+		return nil, errors.New("Failed to get Payment paymentId")
+	}
+	payment := Payment{}
+	json.Unmarshal(paymentAsBytes, &payment)
+	if payment.PaymentID != paymentId{
+		errMsg := "{ \"message\" : " + router.JSONString(paymentId + " Not Found.") + ", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	if payment.BuyerBank_sign == "true"{
+		errMsg := "{ \"paymentID\" : " + router.JSONString(paymentId) + ", \"message\" : \"Payment is already signed by Buyer Bank, it can not be put in escrow.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+
+	escrowAsBytes, err := stub.GetState(escrowKey(paymentId))
+	if err != nil {
+		return nil, errors.New("Failed to get Escrow for " + paymentId)
+	}
+	res := Escrow{}
+	json.Unmarshal(escrowAsBytes, &res)
+	if res.PaymentID == paymentId{
+		router.Println("This Escrow already exists: " + paymentId)
+		errMsg := "{ \"message\" : \"This Escrow already exists.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+
+	res.EscrowID = escrowKey(paymentId)
+	res.PaymentID = paymentId
+	res.AgreementID = payment.AgreementID
+	res.Amount = payment.AmountTransferred
+	res.EscrowStatus = "Pending"
+	for _, condition := range args[1:]{
+		res.Conditions = append(res.Conditions, EscrowCondition{Condition: condition, Satisfied: "false"})
+	}
+	res.Movements = []EscrowMovement{}
+
+	err = t.putEscrow(stub, res)
+	if err != nil {
+		return nil, err
+	}
+
+	//get the Escrow index
+	escrowIndexAsBytes, err := stub.GetState(EscrowIndexStr)
+	if err != nil {
+		return nil, errors.New("Failed to get Escrow index")
+	}
+	var escrowIndex []string
+	json.Unmarshal(escrowIndexAsBytes, &escrowIndex)							//un stringify it aka JSON.parse()
+	escrowIndex = append(escrowIndex, res.EscrowID)								//add escrowId to index list
+	router.Println("! Escrow index: ", escrowIndex)
+	jsonAsBytes, _ := json.Marshal(escrowIndex)
+	err = stub.PutState(EscrowIndexStr, jsonAsBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	err = router.Emit(stub, events.Event{Type: events.EscrowCreated, EntityID: paymentId, Status: res.EscrowStatus,
+		Message: "Escrow created succcessfully"}, nil, res)
+	if err != nil {
+		return nil, err
+	} 
+	router.Println("end createEscrow")
+	return nil, nil
+}
+// ============================================================================================================================
+// satisfyEscrowCondition - mark a release condition as met, release the funds to the seller once all are met.
+// satisfiedBy must be a party of the Agreement. ShipmentDelivered and PortCleared are checked against the Shipment and
+// the Agreement, any other condition must be submitted by an identity acting for satisfiedBy
+// ============================================================================================================================
+func (t *ManagePayment) satisfyEscrowCondition(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	// satisfyEscrowCondition("paymentId", "condition", "satisfiedBy")
+	var err error
+	if len(args) != 3 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting 3 arguments.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	router.Println("start satisfyEscrowCondition")
+	paymentId := args[0]
+	condition := args[1]
+	satisfiedBy := args[2]
+
+	escrowAsBytes, err := stub.GetState(escrowKey(paymentId))
+	if err != nil {
+		return nil, errors.New("Failed to get Escrow for " + paymentId)
+	}
+	res := Escrow{}
+	json.Unmarshal(escrowAsBytes, &res)
+	if res.PaymentID != paymentId{
+		errMsg := "{ \"message\" : " + router.JSONString("Escrow for " + paymentId + " Not Found.") + ", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	if res.EscrowStatus != "Pending" && res.EscrowStatus != "Held"{
+		errMsg := "{ \"paymentID\" : " + router.JSONString(paymentId) + ", \"message\" : " + router.JSONString("Escrow is already " + res.EscrowStatus + ".") + ", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+
+	found := false
+	for i := range res.Conditions{
+		if res.Conditions[i].Condition == condition{
+			found = true
+			res.Conditions[i].Satisfied = "true"
+			res.Conditions[i].SatisfiedBy = satisfiedBy
+		}
+	}
+	if !found{
+		return nil, router.ErrorEvent(stub, errors.New(condition + " is not a release condition of this Escrow."))
+	}
+	err = t.checkEscrowCondition(stub, res.AgreementID, condition, satisfiedBy)
+	if err != nil {
+		return nil, router.ErrorEvent(stub, err)
+	}
+	allSatisfied := escrowSatisfied(res)
+
+	eventType, message := events.EscrowConditionSatisfied, "Escrow condition " + condition + " satisfied succcessfully"
+	if allSatisfied && res.EscrowStatus == "Held"{
+		router.Println("All escrow conditions satisfied, releasing funds to seller")
+		paymentAsBytes, err := stub.GetState(paymentKey(paymentId))
+		if err != nil {
+			return nil, errors.New("Failed to get Payment " + paymentId)
+		}
+		payment := Payment{}
+		json.Unmarshal(paymentAsBytes, &payment)
+		accounts, err := readAccounts(stub)
+		if err != nil {
+			return nil, err
+		}
+		damages, err := t.liquidatedDamagesDue(stub, payment)
+		if err != nil {
+			return nil, err
+		}
+		if damages > 0 {
+			err = t.deductLiquidatedDamages(stub, &payment, &res, &accounts, damages)
+			if err != nil {
+				return nil, err
+			}
+			err = t.putPayment(stub, payment)
+			if err != nil {
+				return nil, err
+			}
+		}
+		err = t.moveEscrowFunds(stub, &res, &accounts, "Release", escrowBalance(res), "All release conditions satisfied")
+		if err != nil {
+			return nil, err
+		}
+		err = t.putAccounts(stub, accounts)
+		if err != nil {
+			return nil, err
+		}
+		err = t.putEscrow(stub, res)
+		if err != nil {
+			return nil, err
+		}
+		eventType, message = events.EscrowReleased, "Escrow released to seller succcessfully"
+	}else{
+		err = t.putEscrow(stub, res)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = router.Emit(stub, events.Event{Type: eventType, EntityID: paymentId, Status: res.EscrowStatus,
+		Data: map[string]interface{}{"condition": condition}, Message: message}, escrowAsBytes, res)
+	if err != nil {
+		return nil, err
+	} 
+	router.Println("end satisfyEscrowCondition")
+	return nil, nil
+}
+// ============================================================================================================================
+// refundEscrow - return escrowed funds to the buyer on cancellation or a dispute settled in the buyer's favour. Only the
+// seller or its bank, who give the funds up, can refund a held escrow; any party of the Payment can cancel a pending one
+// ============================================================================================================================
+func (t *ManagePayment) refundEscrow(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	// refundEscrow("paymentId", "reason")
+	var err error
+	if len(args) != 2 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting 2 arguments.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	router.Println("start refundEscrow")
+	paymentId := args[0]
+	reason := args[1]
+
+	escrowAsBytes, err := stub.GetState(escrowKey(paymentId))
+	if err != nil {
+		return nil, errors.New("Failed to get Escrow for " + paymentId)
+	}
+	res := Escrow{}
+	json.Unmarshal(escrowAsBytes, &res)
+	if res.PaymentID != paymentId{
+		errMsg := "{ \"message\" : " + router.JSONString("Escrow for " + paymentId + " Not Found.") + ", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+
+	paymentAsBytes, err := stub.GetState(paymentKey(paymentId))
+	if err != nil {
+		return nil, errors.New("Failed to get Payment " + paymentId)
+	}
+	payment := Payment{}
+	json.Unmarshal(paymentAsBytes, &payment)
+	if res.EscrowStatus == "Held"{
+		_, err = router.ActingParty(stub, payment.SellerName, payment.SB_name)
+	}else if res.EscrowStatus == "Pending"{
+		_, err = router.ActingParty(stub, payment.BuyerName, payment.SellerName, payment.BB_name, payment.SB_name)
+	}
+	if err != nil {
+		return nil, router.ErrorEvent(stub, errors.New("Escrow is " + res.EscrowStatus + ". " + err.Error()))
+	}
+
+	message := ""
+	if res.EscrowStatus == "Held"{
+		accounts, err := readAccounts(stub)
+		if err != nil {
+			return nil, err
+		}
+		err = t.moveEscrowFunds(stub, &res, &accounts, "Refund", escrowBalance(res), reason)
+		if err != nil {
+			return nil, err
+		}
+		err = t.putAccounts(stub, accounts)
+		if err != nil {
+			return nil, err
+		}
+		err = t.putEscrow(stub, res)
+		if err != nil {
+			return nil, err
+		}
+		message = "Escrow refunded to buyer succcessfully"
+	}else if res.EscrowStatus == "Pending"{
+		res.EscrowStatus = "Cancelled"						//nothing was deposited yet, so there is nothing to move
+		err = t.putEscrow(stub, res)
+		if err != nil {
+			return nil, err
+		}
+		message = "Escrow cancelled succcessfully"
+	}else{
+		errMsg := "{ \"paymentID\" : " + router.JSONString(paymentId) + ", \"message\" : " + router.JSONString("Escrow is already " + res.EscrowStatus + ".") + ", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+
+	err = router.Emit(stub, events.Event{Type: events.EscrowRefunded, EntityID: paymentId, Status: res.EscrowStatus,
+		Data: map[string]interface{}{"reason": reason}, Message: message}, escrowAsBytes, res)
+	if err != nil {
+		return nil, err
+	} 
+	router.Println("end refundEscrow")
+	return nil, nil
+}
+// ============================================================================================================================
+// escrowSatisfied - whether every release condition of an Escrow is met
+// ============================================================================================================================
+func escrowSatisfied(res Escrow) bool {
+	for _, condition := range res.Conditions{
+		if condition.Satisfied != "true"{
+			return false
+		}
+	}
+	return true
+}
+// ============================================================================================================================
+// checkEscrowCondition - refuse a release condition the ledger does not bear out. satisfiedBy must be a party of the
+// Agreement and the caller must act for it; a delivered Shipment of the Agreement stands for ShipmentDelivered and a cleared
+// Agreement for PortCleared, any other condition is vouched for by the caller
+// ============================================================================================================================
+func (t *ManagePayment) checkEscrowCondition(stub shim.ChaincodeStubInterface, agreementId string, condition string, satisfiedBy string) error {
+	agreement, err := t.getLinkedAgreement(stub, agreementId)
+	if err != nil {
+		return err
+	}
+	party := false
+	for _, name := range []string{agreement.Buyer_name, agreement.Seller_name, agreement.Shipper_name, agreement.BB_name,
+		agreement.SB_name, agreement.PortAuth_name}{
+		if name != "" && name == satisfiedBy{
+			party = true
+		}
+	}
+	if !party{
+		return errors.New(satisfiedBy + " is not a party of Agreement " + agreementId)
+	}
+	if err = router.CheckParty(stub, satisfiedBy); err != nil {
+		return err
+	}
+	if condition == "ShipmentDelivered"{
+		shipmentsAsBytes, err := t.linker.QueryLinked(stub, "shipment", "getShipment_byAgreement", agreementId)
+		if err != nil {
+			return errors.New("Failed to query the Shipments of Agreement " + agreementId + ": " + err.Error())
+		}
+		var shipments []struct{
+			Shipment_status string `json:"shipment_status"`
+		}
+		json.Unmarshal(shipmentsAsBytes, &shipments)
+		for _, shipment := range shipments{
+			if shipment.Shipment_status == "Delivered" || shipment.Shipment_status == "Cargo Released"{
+				return nil
+			}
+		}
+		return errors.New("No Shipment of Agreement " + agreementId + " is delivered yet")
+	}
+	if condition == "PortCleared"{
+		if agreement.Clearance_status != "Cleared"{
+			return errors.New("Agreement " + agreementId + " is not cleared by the port authority yet")
+		}
+		return nil
+	}
+	return nil
+}
+// ============================================================================================================================
+// moveEscrowFunds - move amount into (Deposit) or out of (Release, Refund, Deduct) the escrow account and record the movement.
+// accounts is the caller's copy of the accounts, the caller stores them and the Escrow after its last movement
+// ============================================================================================================================
+func (t *ManagePayment) moveEscrowFunds(stub shim.ChaincodeStubInterface, res *Escrow, accounts *AccountInfo, movementType string, amount float64, reason string) error {
+	var from, to string
+	router.Println("start moveEscrowFunds with " + movementType)
+
+	buyerBal, _ := strconv.ParseFloat(accounts.BuyerAccountBalance, 64)
+	sellerBal, _ := strconv.ParseFloat(accounts.SellerAccountBalance, 64)
+	escrowBal, _ := strconv.ParseFloat(accounts.EscrowAccountBalance, 64)
+
+	if movementType == "Deposit"{
+		buyerBal = buyerBal - amount
+		escrowBal = escrowBal + amount
+		from, to = BuyerAccountNumber, EscrowAccountNumber
+		res.EscrowStatus = "Held"
+	}else if movementType == "Release"{
+		escrowBal = escrowBal - amount
+		sellerBal = sellerBal + amount
+		from, to = EscrowAccountNumber, SellerAccountNumber
+		res.EscrowStatus = "Released"
+	}else if movementType == "Refund"{
+		escrowBal = escrowBal - amount
+		buyerBal = buyerBal + amount
+		from, to = EscrowAccountNumber, BuyerAccountNumber
+		res.EscrowStatus = "Refunded"
+	}else if movementType == "Deduct"{				//liquidated damages go back to the buyer, the rest stays held
+		escrowBal = escrowBal - amount
+		buyerBal = buyerBal + amount
+		from, to = EscrowAccountNumber, BuyerAccountNumber
+	}else{
+		return errors.New("Unknown escrow movement " + movementType)
+	}
+
+	accounts.BuyerAccountBalance = strconv.FormatFloat(buyerBal, 'f', 2, 64)
+	accounts.SellerAccountBalance = strconv.FormatFloat(sellerBal, 'f', 2, 64)
+	accounts.EscrowAccountBalance = strconv.FormatFloat(escrowBal, 'f', 2, 64)
+
+	movement := EscrowMovement{}
+	movement.MovementID = res.EscrowID + "_" + strconv.Itoa(len(res.Movements))
+	movement.MovementType = movementType
+	movement.FromAccount = from
+	movement.ToAccount = to
+	movement.Amount = strconv.FormatFloat(amount, 'f', 2, 64)
+	movement.Reason = reason
+	movement.TxID = stub.GetTxID()
+	res.Movements = append(res.Movements, movement)
+	router.Println("end moveEscrowFunds")
+	return nil
+}
+// ============================================================================================================================
+// escrowBalance - the funds an Escrow still holds, its deposit less what was moved out of it
+// ============================================================================================================================
+func escrowBalance(res Escrow) float64 {
+	balance := 0.0
+	for _, movement := range res.Movements{
+		amount, _ := strconv.ParseFloat(movement.Amount, 64)
+		if movement.MovementType == "Deposit"{
+			balance += amount
+		}else{
+			balance -= amount
+		}
+	}
+	return math.Round(balance * 100) / 100
+}
+// ============================================================================================================================
+// addAmount - an amount field increased by amount, with two decimals
+// ============================================================================================================================
+func addAmount(field string, amount float64) string {
+	value, _ := strconv.ParseFloat(field, 64)
+	return strconv.FormatFloat(value + amount, 'f', 2, 64)
+}
+// ============================================================================================================================
+// liquidatedDamagesOwed - the liquidated damages of the evaluated delivery SLAs of the Shipments of an Agreement, none while
+// the shipment chaincode is not registered
+// ============================================================================================================================
+func (t *ManagePayment) liquidatedDamagesOwed(stub shim.ChaincodeStubInterface, agreementId string) (float64, error) {
+	if !t.linker.Linked(stub, "shipment") {
+		return 0, nil
+	}
+	shipmentsAsBytes, err := t.linker.QueryLinked(stub, "shipment", "getShipment_byAgreement", agreementId)
+	if err != nil {
+		return 0, errors.New("Failed to query the Shipments of Agreement " + agreementId + ": " + err.Error())
+	}
+	var shipments []struct{
+		ShipmentID string `json:"shipmentId"`
+	}
+	json.Unmarshal(shipmentsAsBytes, &shipments)
+	owed := 0.0
+	for _, shipment := range shipments{
+		slaAsBytes, err := t.linker.QueryLinked(stub, "shipment", "get_delivery_sla", shipment.ShipmentID)
+		if err != nil {
+			return 0, errors.New("Failed to query the delivery SLA of Shipment " + shipment.ShipmentID + ": " + err.Error())
+		}
+		sla := struct{
+			LiquidatedDamages string `json:"liquidated_damages"`
+		}{}
+		json.Unmarshal(slaAsBytes, &sla)
+		amount, _ := strconv.ParseFloat(sla.LiquidatedDamages, 64)
+		owed += amount
+	}
+	return owed, nil
+}
+// ============================================================================================================================
+// agreementPayments - the Payments of an Agreement
+// ============================================================================================================================
+func (t *ManagePayment) agreementPayments(stub shim.ChaincodeStubInterface, agreementId string) ([]Payment, error) {
+	var payments []Payment
+	paymentsAsBytes, err := t.getPaymentByAgreement(stub, []string{agreementId})
+	if err != nil {
+		return nil, err
+	}
+	json.Unmarshal(paymentsAsBytes, &payments)
+	return payments, nil
+}
+// ============================================================================================================================
+// damagesDue - what res still has to bear of owed, the liquidated damages of its Agreement: owed less what was deducted from
+// any of payments, the Payments of the Agreement, and at most what is left of res
+// ============================================================================================================================
+func damagesDue(owed float64, res Payment, payments []Payment) float64 {
+	deducted, _ := strconv.ParseFloat(res.LiquidatedDamages, 64)
+	left, _ := strconv.ParseFloat(res.AmountTransferred, 64)
+	left = left - deducted
+	for _, payment := range payments{
+		if payment.PaymentID != res.PaymentID{
+			amount, _ := strconv.ParseFloat(payment.LiquidatedDamages, 64)
+			deducted += amount
+		}
+	}
+	return math.Max(0, math.Round(math.Min(owed - deducted, left) * 100) / 100)
+}
+// ============================================================================================================================
+// liquidatedDamagesDue - the liquidated damages for late delivery still to be deducted from res, see damagesDue
+// ============================================================================================================================
+func (t *ManagePayment) liquidatedDamagesDue(stub shim.ChaincodeStubInterface, res Payment) (float64, error) {
+	owed, err := t.liquidatedDamagesOwed(stub, res.AgreementID)
+	if err != nil || owed == 0 {
+		return 0, err
+	}
+	payments, err := t.agreementPayments(stub, res.AgreementID)
+	if err != nil {
+		return 0, err
+	}
+	return damagesDue(owed, res, payments), nil
+}
+// ============================================================================================================================
+// deductLiquidatedDamages - deduct damages from a Payment: out of its Escrow while held, back from the seller once it is paid.
+// res, escrow and accounts are the caller's copies, the caller stores them; escrow is empty for a Payment without one
+// ============================================================================================================================
+func (t *ManagePayment) deductLiquidatedDamages(stub shim.ChaincodeStubInterface, res *Payment, escrow *Escrow, accounts *AccountInfo, damages float64) error {
+	if escrow.PaymentID == res.PaymentID && escrow.EscrowStatus == "Held"{
+		damages = math.Min(damages, escrowBalance(*escrow))
+		err := t.moveEscrowFunds(stub, escrow, accounts, "Deduct", damages, "Liquidated damages for late delivery")
+		if err != nil {
+			return err
+		}
+	}else{
+		updateBalance(accounts, -damages)
+	}
+	res.LiquidatedDamages = addAmount(res.LiquidatedDamages, damages)
+	return nil
+}
+// ============================================================================================================================
+// putPayment - store a Payment after a write by the chaincode itself, e.g. a deduction
+// ============================================================================================================================
+func (t *ManagePayment) putPayment(stub shim.ChaincodeStubInterface, res Payment) error {
+	var err error
+	res.Version++
+	res.LastModifiedTxID = stub.GetTxID()
+	res.UpdatedAt, res.UpdatedBy, err = router.TxStamp(stub)
+	if err != nil {
+		return err
+	}
+	return stub.PutState(paymentKey(res.PaymentID), []byte(paymentJSON(res)))
+}
+// ============================================================================================================================
+// apply_liquidated_damages - deduct the liquidated damages of the late Shipments of an Agreement from its Payments. A held
+// escrow gives them back to the buyer, a paid Payment takes them back from the seller; a Payment not settled yet is paid
+// less when the buyer bank signs it or its escrow is released
+// ============================================================================================================================
+func (t *ManagePayment) apply_liquidated_damages(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	// apply_liquidated_damages("agreementId")
+	var err error
+	if len(args) != 1 {
+		return nil, router.ErrorEvent(stub, errors.New("Incorrect number of arguments. Expecting \"agreementId\" as an argument."))
+	}
+	router.Println("start apply_liquidated_damages")
+	agreementId := args[0]
+	owed, err := t.liquidatedDamagesOwed(stub, agreementId)
+	if err != nil {
+		return nil, err
+	}
+	payments, err := t.agreementPayments(stub, agreementId)
+	if err != nil {
+		return nil, err
+	}
+	accounts, err := readAccounts(stub)
+	if err != nil {
+		return nil, err
+	}
+	before, after := map[string]string{}, map[string]string{}
+	total := 0.0
+	for i := range payments{
+		res := &payments[i]
+		escrowAsBytes, err := stub.GetState(escrowKey(res.PaymentID))
+		if err != nil {
+			return nil, errors.New("Failed to get Escrow for " + res.PaymentID)
+		}
+		escrow := Escrow{}
+		json.Unmarshal(escrowAsBytes, &escrow)
+		settled := escrow.EscrowStatus == "Held" || escrow.EscrowStatus == "Released"
+		if escrow.PaymentID != res.PaymentID{
+			settled = res.BuyerBank_sign == "true"
+		}
+		damages := damagesDue(owed, *res, payments)
+		if !settled || damages == 0{
+			continue
+		}
+		before[res.PaymentID] = res.LiquidatedDamages
+		err = t.deductLiquidatedDamages(stub, res, &escrow, &accounts, damages)
+		if err != nil {
+			return nil, err
+		}
+		if escrow.PaymentID == res.PaymentID && escrow.EscrowStatus == "Held"{
+			err = t.putEscrow(stub, escrow)
+			if err != nil {
+				return nil, err
+			}
+		}
+		err = t.putPayment(stub, *res)
+		if err != nil {
+			return nil, err
+		}
+		after[res.PaymentID] = res.LiquidatedDamages
+		total += damages
+	}
+	if total == 0{
+		return nil, router.ErrorEvent(stub, errors.New("No liquidated damages are due from a settled Payment of Agreement " + agreementId + "."))
+	}
+	err = t.putAccounts(stub, accounts)
+	if err != nil {
+		return nil, err
+	}
+	err = router.Emit(stub, events.Event{Type: events.PaymentDamagesDeducted, EntityID: agreementId,
+		Data: map[string]interface{}{"amount": strconv.FormatFloat(total, 'f', 2, 64)},
+		Message: "Liquidated damages deducted succcessfully"}, before, after)
+	if err != nil {
+		return nil, err
+	}
+	router.Println("end apply_liquidated_damages")
+	return nil, nil
+}
+// ============================================================================================================================
+// putEscrow - store an Escrow into chaincode state
+// ============================================================================================================================
+func (t *ManagePayment) putEscrow(stub shim.ChaincodeStubInterface, res Escrow) error {
+	escrowAsBytes, err := json.Marshal(res)
+	if err != nil {
+		return errors.New("Error while marshalling Escrow")
+	}
+	router.Println("In putEscrow escrow to commit::" + string(escrowAsBytes))
+	return stub.PutState(res.EscrowID, escrowAsBytes)
+}
+// ============================================================================================================================
+// getEscrowByPaymentID - get the Escrow of a Payment from chaincode state
+// ============================================================================================================================
+func (t *ManagePayment) getEscrowByPaymentID(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var err error
+	router.Println("start getEscrowByPaymentID")
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting \"paymentID\" as an argument.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	paymentId := args[0]
+	valAsbytes, err := stub.GetState(escrowKey(paymentId))
+	if err != nil {
+		errMsg := "{ \"message\" : " + router.JSONString("Escrow for " + paymentId + " not Found.") + ", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	router.Println("end getEscrowByPaymentID")
+	return valAsbytes, nil													//send it onward
+}
+// ============================================================================================================================
+// getEscrowMovements - get the escrow movements of one Payment, or of every Payment when "" is passed
+// ============================================================================================================================
+func (t *ManagePayment) getEscrowMovements(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var escrowIndex, keys []string
+	var movements []EscrowMovement
+	var err error
+	router.Println("start getEscrowMovements")
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting \"paymentID\" or \" \" as an argument.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	if args[0] != "" && args[0] != " " {
+		keys = []string{escrowKey(args[0])}
+	}else{
+		escrowAsBytes, err := stub.GetState(EscrowIndexStr)
+		if err != nil {
+			return nil, errors.New("Failed to get Escrow index")
+		}
+		json.Unmarshal(escrowAsBytes, &escrowIndex)							//un stringify it aka JSON.parse()
+		keys = escrowIndex
+	}
+	for _, val := range keys{
+		valueAsBytes, err := stub.GetState(val)
+		if err != nil {
+			return nil, errors.New("{\"Error\":\"Failed to get state for " + val + "\"}")
+		}
+		res := Escrow{}
+		json.Unmarshal(valueAsBytes, &res)
+		movements = append(movements, res.Movements...)
+	}
+	if movements == nil {
+		movements = []EscrowMovement{}
+	}
+	jsonAsBytes, _ := json.Marshal(movements)
+	router.Println("end getEscrowMovements")
+	return jsonAsBytes, nil													//send it onward
+}
+// ============================================================================================================================
+// reconciliationKey - key under which the reconciliation of a bank statement is stored
+// ============================================================================================================================
+func reconciliationKey(statementId string) string {
+	return "Reconciliation_" + statementId
+}
+// ============================================================================================================================
+// parseStatementLines - read bank statement lines given as a JSON array or as CSV (date,amount,reference,counterparty)
+// ============================================================================================================================
+func parseStatementLines(format string, data string) ([]StatementLine, error) {
+	var lines []StatementLine
+	if strings.ToLower(format) == "json" {
+		err := json.Unmarshal([]byte(data), &lines)
+		if err != nil {
+			return nil, errors.New("Statement lines are not a valid JSON array")
+		}
+		return lines, nil
+	}
+	if strings.ToLower(format) != "csv" {
+		return nil, errors.New("Unknown statement format " + format + ", expecting json or csv")
+	}
+	records, err := csv.NewReader(strings.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, errors.New("Statement lines are not valid CSV")
+	}
+	for i, record := range records {
+		if len(record) != 4 {
+			return nil, errors.New("CSV line " + strconv.Itoa(i+1) + " must have 4 columns: date,amount,reference,counterparty")
+		}
+		if i == 0 && strings.ToLower(strings.TrimSpace(record[0])) == "date" {
+			continue											//skip the header row
+		}
+		lines = append(lines, StatementLine{
+			Date: strings.TrimSpace(record[0]),
+			Amount: strings.TrimSpace(record[1]),
+			Reference: strings.TrimSpace(record[2]),
+			Counterparty: strings.TrimSpace(record[3]),
+		})
+	}
+	return lines, nil
+}
+// ============================================================================================================================
+// statementDateLayout - the layout of a named statement date format, the default one when none is named
+// ============================================================================================================================
+func statementDateLayout(format string) (string, error) {
+	if format == "" {
+		format = DefaultStatementDateFormat
+	}
+	layout, found := StatementDateFormats[format]
+	if !found {
+		var formats []string
+		for name := range StatementDateFormats {
+			formats = append(formats, name)
+		}
+		sort.Strings(formats)
+		return "", errors.New("Unknown date format " + format + ", expecting one of " + strings.Join(formats, ", "))
+	}
+	return layout, nil
+}
+// ============================================================================================================================
+// parsePaymentDate - parse the date of a Payment, in one of the unambiguous layouts
+// ============================================================================================================================
+func parsePaymentDate(date string) (time.Time, error) {
+	for _, layout := range PaymentDateLayouts {
+		parsed, err := time.Parse(layout, strings.TrimSpace(date))
+		if err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, errors.New("Unknown date format " + date)
+}
+// ============================================================================================================================
+// referenceTokens - the words of a statement reference, e.g. Trade, AGR1 and INV-7 for "Trade AGR1/INV-7"; an ID is
+// matched against a whole word, so AGR1 is not found in AGR10
+// ============================================================================================================================
+func referenceTokens(reference string) map[string]bool {
+	tokens := make(map[string]bool)
+	for _, token := range strings.FieldsFunc(reference, func(c rune) bool {
+		return !unicode.IsLetter(c) && !unicode.IsDigit(c) && c != '-' && c != '_'
+	}) {
+		tokens[token] = true
+	}
+	return tokens
+}
+// ============================================================================================================================
+// matchStatementLine - match one statement line against the known payments by agreement ID, amount and date tolerance.
+// used holds the statement line already matched to a payment, by this statement or an earlier one
+// ============================================================================================================================
+func matchStatementLine(line StatementLine, layout string, payments []Payment, used map[string]string, toleranceDays float64) ReconciliationResult {
+	result := ReconciliationResult{
+		Date: line.Date,
+		Amount: line.Amount,
+		Reference: line.Reference,
+		Counterparty: line.Counterparty,
+		MatchStatus: "Unmatched",
+		Reason: "No payment found for reference " + line.Reference,
+	}
+	lineAmount, amountErr := strconv.ParseFloat(line.Amount, 64)
+	lineDate, dateErr := time.Parse(layout, strings.TrimSpace(line.Date))
+	tokens := referenceTokens(line.Reference)
+
+	for _, payment := range payments {
+		if payment.AgreementID == "" || !tokens[payment.AgreementID] {
+			continue
+		}
+		if matchedBy, found := used[payment.PaymentID]; found {
+			if result.MatchStatus == "Unmatched" {
+				result.Reason = "Payment " + payment.PaymentID + " is already reconciled with " + matchedBy
+			}
+			continue
+		}
+		var reasons []string
+		paymentAmount, err := strconv.ParseFloat(payment.AmountTransferred, 64)
+		if amountErr != nil || err != nil {
+			reasons = append(reasons, "amount could not be compared")
+		}else if math.Abs(paymentAmount - lineAmount) > 0.005 {
+			reasons = append(reasons, "amount differs: statement " + line.Amount + ", payment " + payment.AmountTransferred)
+		}
+		paymentDate, err := parsePaymentDate(payment.PaymentCUDate)
+		if dateErr != nil || err != nil {
+			reasons = append(reasons, "date could not be compared")
+		}else if math.Abs(lineDate.Sub(paymentDate).Hours()) > toleranceDays*24 {
+			reasons = append(reasons, "date outside tolerance: statement " + line.Date + ", payment " + payment.PaymentCUDate)
+		}
+
+		if len(reasons) == 0 {
+			result.PaymentID = payment.PaymentID
+			result.MatchStatus = "Matched"
+			result.Reason = ""
+			return result
+		}
+		if result.MatchStatus == "Unmatched" {				//keep the first candidate, a later one may still match fully
+			result.PaymentID = payment.PaymentID
+			result.MatchStatus = "PartiallyMatched"
+			result.Reason = strings.Join(reasons, "; ")
+		}
+	}
+	return result
+}
+// ============================================================================================================================
+// reconcile_statement - match bank statement lines to Payments and store matched, unmatched and partially matched results.
+// The dates of the statement are in the named date format, YYYY-MM-DD when none is given. A payment is matched once, a
+// line of a later statement naming it again is left unmatched
+// ============================================================================================================================
+func (t *ManagePayment) reconcile_statement(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	// reconcile_statement("statementId", "json"|"csv", "statement lines", "dateToleranceDays", "dateFormat")
+	var err error
+	if len(args) != 4 && len(args) != 5 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting 4 arguments, or 5 with the date format.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	router.Println("start reconcile_statement")
+	statementId := args[0]
+	toleranceDays, err := strconv.ParseFloat(args[3], 64)
+	if err != nil || toleranceDays < 0 {
+		errMsg := "{ \"message\" : \"dateToleranceDays must be a non-negative number.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	dateFormat := ""
+	if len(args) == 5 {
+		dateFormat = strings.TrimSpace(args[4])
+	}
+	layout, err := statementDateLayout(dateFormat)
+	if err != nil {
+		return nil, router.ErrorEvent(stub, err)
+	}
+	lines, err := parseStatementLines(args[1], args[2])
+	if err != nil {
+		errMsg := "{ \"message\" : " + router.JSONString(err.Error()) + ", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+
+	reconciliationAsBytes, err := stub.GetState(reconciliationKey(statementId))
+	if err != nil {
+		return nil, errors.New("Failed to get Reconciliation for " + statementId)
+	}
+	res := Reconciliation{}
+	json.Unmarshal(reconciliationAsBytes, &res)
+	if res.StatementID == statementId{
+		router.Println("This Statement is already reconciled: " + statementId)
+		errMsg := "{ \"message\" : \"This Statement is already reconciled.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+
+	//load every known payment once
+	var paymentIndex []string
+	var payments []Payment
+	paymentAsBytes, err := stub.GetState(PaymentIndexStr)
+	if err != nil {
+		return nil, errors.New("Failed to get Payment index")
+	}
+	json.Unmarshal(paymentAsBytes, &paymentIndex)								//un stringify it aka JSON.parse()
+	for _, val := range paymentIndex{
+		valueAsBytes, err := stub.GetState(paymentKey(val))
+		if err != nil {
+			return nil, errors.New("{\"Error\":\"Failed to get state for " + val + "\"}")
+		}
+		payment := Payment{}
+		json.Unmarshal(valueAsBytes, &payment)
+		payments = append(payments, payment)
+	}
+
+	//the payments matched by earlier statements
+	used := make(map[string]string)
+	usedAsBytes, err := stub.GetState(ReconciledPaymentsStr)
+	if err != nil {
+		return nil, errors.New("Failed to get Reconciled payments")
+	}
+	json.Unmarshal(usedAsBytes, &used)
+
+	res.StatementID = statementId
+	res.DateToleranceDays = args[3]
+	res.DateFormat = dateFormat
+	if res.DateFormat == "" {
+		res.DateFormat = DefaultStatementDateFormat
+	}
+	res.Results = []ReconciliationResult{}
+	matched, partial, unmatched := 0, 0, 0
+	for i, line := range lines{
+		result := matchStatementLine(line, layout, payments, used, toleranceDays)
+		result.LineNo = strconv.Itoa(i+1)
+		if result.MatchStatus == "Matched" {
+			used[result.PaymentID] = "statement " + statementId + " line " + result.LineNo
+			matched++
+		}else{
+			result.ExceptionStatus = "Open"
+			if result.MatchStatus == "PartiallyMatched" {
+				partial++
+			}else{
+				unmatched++
+			}
+		}
+		res.Results = append(res.Results, result)
+	}
+
+	jsonAsBytes, _ := json.Marshal(res)
+	err = stub.PutState(reconciliationKey(statementId), jsonAsBytes)
+	if err != nil {
+		return nil, err
+	}
+	usedAsBytes, _ = json.Marshal(used)
+	err = stub.PutState(ReconciledPaymentsStr, usedAsBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	//get the Reconciliation index
+	var reconciliationIndex []string
+	reconciliationIndexAsBytes, err := stub.GetState(ReconciliationIndexStr)
+	if err != nil {
+		return nil, errors.New("Failed to get Reconciliation index")
+	}
+	json.Unmarshal(reconciliationIndexAsBytes, &reconciliationIndex)			//un stringify it aka JSON.parse()
+	reconciliationIndex = append(reconciliationIndex, statementId)
+	jsonAsBytes, _ = json.Marshal(reconciliationIndex)
+	err = stub.PutState(ReconciliationIndexStr, jsonAsBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	err = router.Emit(stub, events.Event{Type: events.StatementReconciled, EntityID: statementId,
+		Data: map[string]interface{}{"matched": matched, "partiallyMatched": partial, "unmatched": unmatched},
+		Message: "Statement reconciled succcessfully"}, nil, res)
+	if err != nil {
+		return nil, err
+	} 
+	router.Println("end reconcile_statement")
+	return nil, nil
+}
+// ============================================================================================================================
+// resolve_reconciliation_exception - close an open exception of a reconciled statement
+// ============================================================================================================================
+func (t *ManagePayment) resolve_reconciliation_exception(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	// resolve_reconciliation_exception("statementId", "lineNo", "resolution")
+	var err error
+	if len(args) != 3 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting 3 arguments.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	router.Println("start resolve_reconciliation_exception")
+	statementId := args[0]
+	lineNo := args[1]
+
+	reconciliationAsBytes, err := stub.GetState(reconciliationKey(statementId))
+	if err != nil {
+		return nil, errors.New("Failed to get Reconciliation for " + statementId)
+	}
+	res := Reconciliation{}
+	json.Unmarshal(reconciliationAsBytes, &res)
+	if res.StatementID != statementId{
+		errMsg := "{ \"message\" : " + router.JSONString("Statement " + statementId + " Not Found.") + ", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	found := false
+	for i := range res.Results{
+		if res.Results[i].LineNo == lineNo && res.Results[i].ExceptionStatus == "Open"{
+			res.Results[i].ExceptionStatus = "Resolved"
+			res.Results[i].Resolution = args[2]
+			found = true
+		}
+	}
+	if !found{
+		errMsg := "{ \"message\" : " + router.JSONString("No open exception for line " + lineNo + " of statement " + statementId + ".") + ", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	jsonAsBytes, _ := json.Marshal(res)
+	err = stub.PutState(reconciliationKey(statementId), jsonAsBytes)
+	if err != nil {
+		return nil, err
+	}
+	err = router.Emit(stub, events.Event{Type: events.StatementExceptionResolved, EntityID: statementId,
+		Data: map[string]interface{}{"lineNo": lineNo}, Message: "Reconciliation exception resolved succcessfully"},
+		reconciliationAsBytes, jsonAsBytes)
+	if err != nil {
+		return nil, err
+	} 
+	router.Println("end resolve_reconciliation_exception")
+	return nil, nil
+}
+// ============================================================================================================================
+// get_reconciliation - get the reconciliation results of a statement from chaincode state
+// ============================================================================================================================
+func (t *ManagePayment) get_reconciliation(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var err error
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting \"statementID\" as an argument.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	statementId := args[0]
+	valAsbytes, err := stub.GetState(reconciliationKey(statementId))
+	if err != nil {
+		errMsg := "{ \"message\" : " + router.JSONString("Statement " + statementId + " not Found.") + ", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	return valAsbytes, nil													//send it onward
+}
+// ============================================================================================================================
+// get_reconciliation_exceptions - get the open exceptions of every reconciled statement
+// ============================================================================================================================
+func (t *ManagePayment) get_reconciliation_exceptions(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var reconciliationIndex []string
+	var err error
+	router.Println("start get_reconciliation_exceptions")
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting \" \" as an argument.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	reconciliationIndexAsBytes, err := stub.GetState(ReconciliationIndexStr)
+	if err != nil {
+		return nil, errors.New("Failed to get Reconciliation index")
+	}
+	json.Unmarshal(reconciliationIndexAsBytes, &reconciliationIndex)			//un stringify it aka JSON.parse()
+
+	exceptions := make(map[string][]ReconciliationResult)
+	for _, statementId := range reconciliationIndex{
+		valueAsBytes, err := stub.GetState(reconciliationKey(statementId))
+		if err != nil {
+			return nil, errors.New("{\"Error\":\"Failed to get state for " + statementId + "\"}")
+		}
+		res := Reconciliation{}
+		json.Unmarshal(valueAsBytes, &res)
+		for _, result := range res.Results{
+			if result.ExceptionStatus == "Open"{
+				exceptions[statementId] = append(exceptions[statementId], result)
+			}
+		}
+	}
+	jsonAsBytes, _ := json.Marshal(exceptions)
+	router.Println("end get_reconciliation_exceptions")
+	return jsonAsBytes, nil													//send it onward
+}
+// ============================================================================================================================
+// ISO 20022 messages - only the elements this chaincode produces or reads are modelled
+// ============================================================================================================================
+type Pain001Document struct{
+	XMLName xml.Name `xml:"Document"`
+	Xmlns string `xml:"xmlns,attr"`
+	CstmrCdtTrfInitn Pain001Initiation `xml:"CstmrCdtTrfInitn"`
+}
+
+type Pain001Initiation struct{
+	GrpHdr Pain001GroupHeader `xml:"GrpHdr"`
+	PmtInf Pain001PaymentInfo `xml:"PmtInf"`
+}
+
+type Pain001GroupHeader struct{
+	MsgId string `xml:"MsgId"`
+	CreDtTm string `xml:"CreDtTm"`
+	NbOfTxs string `xml:"NbOfTxs"`
+	CtrlSum string `xml:"CtrlSum"`
+	InitgPty Iso20022Party `xml:"InitgPty"`
+}
+
+type Pain001PaymentInfo struct{
+	PmtInfId string `xml:"PmtInfId"`
+	PmtMtd string `xml:"PmtMtd"`
+	NbOfTxs string `xml:"NbOfTxs"`
+	CtrlSum string `xml:"CtrlSum"`
+	ReqdExctnDt string `xml:"ReqdExctnDt"`
+	Dbtr Iso20022Party `xml:"Dbtr"`
+	DbtrAcct Iso20022Account `xml:"DbtrAcct"`
+	DbtrAgt Iso20022Agent `xml:"DbtrAgt"`
+	CdtTrfTxInf Pain001Transaction `xml:"CdtTrfTxInf"`
+}
+
+type Pain001Transaction struct{
+	EndToEndId string `xml:"PmtId>EndToEndId"`
+	InstdAmt Iso20022Amount `xml:"Amt>InstdAmt"`
+	CdtrAgt Iso20022Agent `xml:"CdtrAgt"`
+	Cdtr Iso20022Party `xml:"Cdtr"`
+	CdtrAcct Iso20022Account `xml:"CdtrAcct"`
+	Ustrd string `xml:"RmtInf>Ustrd"`
+}
+
+type Iso20022Party struct{
+	Nm string `xml:"Nm"`
+}
+
+type Iso20022Account struct{
+	Id string `xml:"Id>Othr>Id"`
+}
+
+type Iso20022Agent struct{
+	Nm string `xml:"FinInstnId>Nm"`
+}
+
+type Iso20022Amount struct{
+	Ccy string `xml:"Ccy,attr"`
+	Value string `xml:",chardata"`
+}
+
+type Camt054Document struct{
+	XMLName xml.Name `xml:"Document"`
+	Xmlns string `xml:"xmlns,attr"`
+	Notification Camt054Notification `xml:"BkToCstmrDbtCdtNtfctn"`
+}
+
+type Camt054Notification struct{
+	MsgId string `xml:"GrpHdr>MsgId"`
+	CreDtTm string `xml:"GrpHdr>CreDtTm"`
+	Ntfctn []Camt054Ntfctn `xml:"Ntfctn"`
+}
+
+type Camt054Ntfctn struct{
+	Id string `xml:"Id"`
+	CreDtTm string `xml:"CreDtTm"`
+	AcctId string `xml:"Acct>Id>Othr>Id"`
+	Ntry []Camt054Entry `xml:"Ntry"`
+}
+
+type Camt054Entry struct{
+	Amt Iso20022Amount `xml:"Amt"`
+	CdtDbtInd string `xml:"CdtDbtInd"`
+	Sts string `xml:"Sts"`
+	BookgDt string `xml:"BookgDt>Dt"`
+	EndToEndIds []string `xml:"NtryDtls>TxDtls>Refs>EndToEndId"`
+}
+
+var iso20022Max35Text = regexp.MustCompile(`^.{1,35}$`)
+var iso20022Max140Text = regexp.MustCompile(`^.{1,140}$`)
+var iso20022Currency = regexp.MustCompile(`^[A-Z]{3}$`)
+var iso20022Amount = regexp.MustCompile(`^[0-9]{1,13}(\.[0-9]{1,5})?$`)
+var iso20022NbOfTxs = regexp.MustCompile(`^[0-9]{1,15}$`)
+var iso20022Date = regexp.MustCompile(`^[0-9]{4}-[0-9]{2}-[0-9]{2}$`)
+// ============================================================================================================================
+// checkIso20022Field - check a field against the facet of its ISO 20022 schema type
+// ============================================================================================================================
+func checkIso20022Field(name string, value string, facet *regexp.Regexp) error {
+	if !facet.MatchString(value) {
+		return errors.New(name + " value '" + value + "' does not conform to the schema")
+	}
+	return nil
+}
+// ============================================================================================================================
+// checkIso20022DateTime - check an ISODateTime field
+// ============================================================================================================================
+func checkIso20022DateTime(name string, value string) error {
+	if _, err := time.Parse("2006-01-02T15:04:05", value); err == nil {
+		return nil
+	}
+	if _, err := time.Parse(time.RFC3339, value); err == nil {
+		return nil
+	}
+	return errors.New(name + " value '" + value + "' is not an ISODateTime")
+}
+// ============================================================================================================================
+// validatePain001 - check a pain.001.001.03 document for the mandatory elements and the facets of its schema, restated
+// above as patterns. It is not a validation against the XSD, which the chaincode does not carry: the elements this
+// chaincode does not model are neither rendered nor checked
+// ============================================================================================================================
+func validatePain001(doc Pain001Document) error {
+	hdr := doc.CstmrCdtTrfInitn.GrpHdr
+	pmt := doc.CstmrCdtTrfInitn.PmtInf
+	tx := pmt.CdtTrfTxInf
+	if doc.Xmlns != Pain001Namespace {
+		return errors.New("Document namespace must be " + Pain001Namespace)
+	}
+	if pmt.PmtMtd != "TRF" {
+		return errors.New("PmtMtd must be TRF")
+	}
+	if err := checkIso20022DateTime("GrpHdr/CreDtTm", hdr.CreDtTm); err != nil {
+		return err
+	}
+	checks := []struct{
+		name string
+		value string
+		facet *regexp.Regexp
+	}{
+		{"GrpHdr/MsgId", hdr.MsgId, iso20022Max35Text},
+		{"GrpHdr/NbOfTxs", hdr.NbOfTxs, iso20022NbOfTxs},
+		{"GrpHdr/CtrlSum", hdr.CtrlSum, iso20022Amount},
+		{"GrpHdr/InitgPty/Nm", hdr.InitgPty.Nm, iso20022Max140Text},
+		{"PmtInf/PmtInfId", pmt.PmtInfId, iso20022Max35Text},
+		{"PmtInf/NbOfTxs", pmt.NbOfTxs, iso20022NbOfTxs},
+		{"PmtInf/CtrlSum", pmt.CtrlSum, iso20022Amount},
+		{"PmtInf/ReqdExctnDt", pmt.ReqdExctnDt, iso20022Date},
+		{"PmtInf/Dbtr/Nm", pmt.Dbtr.Nm, iso20022Max140Text},
+		{"PmtInf/DbtrAcct/Id/Othr/Id", pmt.DbtrAcct.Id, iso20022Max35Text},
+		{"PmtInf/DbtrAgt/FinInstnId/Nm", pmt.DbtrAgt.Nm, iso20022Max140Text},
+		{"CdtTrfTxInf/PmtId/EndToEndId", tx.EndToEndId, iso20022Max35Text},
+		{"CdtTrfTxInf/Amt/InstdAmt", tx.InstdAmt.Value, iso20022Amount},
+		{"CdtTrfTxInf/Amt/InstdAmt/@Ccy", tx.InstdAmt.Ccy, iso20022Currency},
+		{"CdtTrfTxInf/CdtrAgt/FinInstnId/Nm", tx.CdtrAgt.Nm, iso20022Max140Text},
+		{"CdtTrfTxInf/Cdtr/Nm", tx.Cdtr.Nm, iso20022Max140Text},
+		{"CdtTrfTxInf/CdtrAcct/Id/Othr/Id", tx.CdtrAcct.Id, iso20022Max35Text},
+		{"CdtTrfTxInf/RmtInf/Ustrd", tx.Ustrd, iso20022Max140Text},
+	}
+	for _, check := range checks {
+		if err := checkIso20022Field(check.name, check.value, check.facet); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+// ============================================================================================================================
+// validateCamt054 - check a camt.054.001.02 document for the mandatory elements and the facets of its schema that this
+// chaincode reads, restated as patterns like validatePain001; the XSD itself is not applied
+// ============================================================================================================================
+func validateCamt054(doc Camt054Document) error {
+	if doc.Xmlns != Camt054Namespace {
+		return errors.New("Document namespace must be " + Camt054Namespace)
+	}
+	if err := checkIso20022Field("GrpHdr/MsgId", doc.Notification.MsgId, iso20022Max35Text); err != nil {
+		return err
+	}
+	if err := checkIso20022DateTime("GrpHdr/CreDtTm", doc.Notification.CreDtTm); err != nil {
+		return err
+	}
+	if len(doc.Notification.Ntfctn) == 0 {
+		return errors.New("At least one Ntfctn is required")
+	}
+	for _, ntfctn := range doc.Notification.Ntfctn {
+		if err := checkIso20022Field("Ntfctn/Id", ntfctn.Id, iso20022Max35Text); err != nil {
+			return err
+		}
+		if err := checkIso20022DateTime("Ntfctn/CreDtTm", ntfctn.CreDtTm); err != nil {
+			return err
+		}
+		if err := checkIso20022Field("Ntfctn/Acct/Id/Othr/Id", ntfctn.AcctId, iso20022Max35Text); err != nil {
+			return err
+		}
+		for _, ntry := range ntfctn.Ntry {
+			if err := checkIso20022Field("Ntry/Amt", ntry.Amt.Value, iso20022Amount); err != nil {
+				return err
+			}
+			if err := checkIso20022Field("Ntry/Amt/@Ccy", ntry.Amt.Ccy, iso20022Currency); err != nil {
+				return err
+			}
+			if ntry.CdtDbtInd != "CRDT" && ntry.CdtDbtInd != "DBIT" {
+				return errors.New("Ntry/CdtDbtInd must be CRDT or DBIT")
+			}
+			if ntry.Sts != "BOOK" && ntry.Sts != "PDNG" && ntry.Sts != "INFO" {
+				return errors.New("Ntry/Sts must be BOOK, PDNG or INFO")
+			}
+			for _, endToEndId := range ntry.EndToEndIds {
+				if err := checkIso20022Field("Ntry/NtryDtls/TxDtls/Refs/EndToEndId", endToEndId, iso20022Max35Text); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+// ============================================================================================================================
+// pain001MsgId - build a pain.001 message ID from a transaction ID, MsgId is limited to 35 characters
+// ============================================================================================================================
+func pain001MsgId(txId string) string {
+	msgId := "P001-" + txId
+	if len(msgId) > 35 {
+		msgId = msgId[:35]
+	}
+	return msgId
+}
+// ============================================================================================================================
+// exportPain001 - assign a pain.001 message ID to a settled Payment so it can be rendered with getPaymentPain001
+// ============================================================================================================================
+func (t *ManagePayment) exportPain001(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var err error
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting \"paymentID\" as an argument.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	router.Println("start exportPain001")
+	paymentId := args[0]
+	paymentAsBytes, err := stub.GetState(paymentKey(paymentId))
+	if err != nil {
+		return nil, errors.New("Failed to get Payment paymentId")
+	}
+	res := Payment{}
+	json.Unmarshal(paymentAsBytes, &res)
+	if res.PaymentID != paymentId{
+		errMsg := "{ \"message\" : " + router.JSONString(paymentId + " Not Found.") + ", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	if res.BuyerBank_sign != "true"{
+		errMsg := "{ \"paymentID\" : " + router.JSONString(paymentId) + ", \"message\" : \"Only a settled payment can be exported as pain.001.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	txTimestamp, err := stub.GetTxTimestamp()
+	if err != nil {
+		return nil, errors.New("Failed to get transaction timestamp")
+	}
+	res.Pain001MsgID = pain001MsgId(stub.GetTxID())
+	res.Pain001CreDtTm = time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos)).UTC().Format("2006-01-02T15:04:05")
+
+	_, err = renderPain001(res)
+	if err != nil {
+		errMsg := "{ \"paymentID\" : " + router.JSONString(paymentId) + ", \"message\" : " + router.JSONString(err.Error()) + ", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	res.Version++
+	res.LastModifiedTxID = stub.GetTxID()
+	res.UpdatedAt, res.UpdatedBy, err = router.TxStamp(stub)
+	if err != nil {
+		return nil, err
+	}
+	order := paymentJSON(res)
+	err = stub.PutState(paymentKey(paymentId), []byte(order))						//store Payment with id as key
+	if err != nil {
+		return nil, err
+	}
+	err = router.Emit(stub, events.Event{Type: events.PaymentExported, EntityID: paymentId, Status: res.PaymentStatus,
+		Data: map[string]interface{}{"msgId": res.Pain001MsgID}, Message: "Payment exported as pain.001 succcessfully"}, paymentAsBytes, order)
+	if err != nil {
+		return nil, err
+	} 
+	router.Println("end exportPain001")
+	return nil, nil
+}
+// ============================================================================================================================
+// renderPain001 - render a Payment as a validated pain.001 credit transfer initiation
+// ============================================================================================================================
+func renderPain001(res Payment) ([]byte, error) {
+	amount, err := strconv.ParseFloat(res.AmountTransferred, 64)
+	if err != nil {
+		return nil, errors.New("Error while converting string 'amountTransferred' to float")
+	}
+	executionDate := res.PaymentCUDate
+	if parsed, err := parsePaymentDate(res.PaymentCUDate); err == nil {
+		executionDate = parsed.Format("2006-01-02")
+	}
+	amountStr := strconv.FormatFloat(amount, 'f', 2, 64)
+	doc := Pain001Document{Xmlns: Pain001Namespace}
+	doc.CstmrCdtTrfInitn.GrpHdr = Pain001GroupHeader{
+		MsgId: res.Pain001MsgID,
+		CreDtTm: res.Pain001CreDtTm,
+		NbOfTxs: "1",
+		CtrlSum: amountStr,
+		InitgPty: Iso20022Party{Nm: res.BuyerName},
+	}
+	doc.CstmrCdtTrfInitn.PmtInf = Pain001PaymentInfo{
+		PmtInfId: res.PaymentID,
+		PmtMtd: "TRF",
+		NbOfTxs: "1",
+		CtrlSum: amountStr,
+		ReqdExctnDt: executionDate,
+		Dbtr: Iso20022Party{Nm: res.BuyerName},
+		DbtrAcct: Iso20022Account{Id: res.BuyerAccount},
+		DbtrAgt: Iso20022Agent{Nm: res.BB_name},
+		CdtTrfTxInf: Pain001Transaction{
+			EndToEndId: res.PaymentID,
+			InstdAmt: Iso20022Amount{Ccy: PaymentCurrency, Value: amountStr},
+			CdtrAgt: Iso20022Agent{Nm: res.SB_name},
+			Cdtr: Iso20022Party{Nm: res.SellerName},
+			CdtrAcct: Iso20022Account{Id: res.SellerAccount},
+			Ustrd: res.AgreementID,
+		},
+	}
+	err = validatePain001(doc)
+	if err != nil {
+		return nil, err
+	}
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, errors.New("Error while marshalling pain.001")
+	}
+	return append([]byte(xml.Header), out...), nil
+}
+// ============================================================================================================================
+// getPaymentPain001 - get an exported Payment as a pain.001 XML message
+// ============================================================================================================================
+func (t *ManagePayment) getPaymentPain001(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var err error
+	router.Println("start getPaymentPain001")
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting \"paymentID\" as an argument.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	paymentId := args[0]
+	paymentAsBytes, err := stub.GetState(paymentKey(paymentId))
+	if err != nil {
+		return nil, errors.New("Failed to get Payment paymentId")
+	}
+	res := Payment{}
+	json.Unmarshal(paymentAsBytes, &res)
+	if res.PaymentID != paymentId || res.Pain001MsgID == ""{
+		errMsg := "{ \"message\" : " + router.JSONString(paymentId + " has not been exported with exportPain001.") + ", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	router.Println("end getPaymentPain001")
+	return renderPain001(res)
+}
+// ============================================================================================================================
+// camt054StatementID - the statement under which the entries of a camt.054 notification are reconciled
+// ============================================================================================================================
+func camt054StatementID(msgId string) string {
+	return "camt054-" + msgId
+}
+// ============================================================================================================================
+// importCamt054 - apply a camt.054 debit/credit notification to the Payments it references. Each entry is recorded as a
+// line of the statement camt054-<MsgId>; an entry naming no known payment, or another amount, is left as an open
+// exception for resolve_reconciliation_exception. A notification is imported once, its MsgId can not be replayed
+// ============================================================================================================================
+func (t *ManagePayment) importCamt054(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	// importCamt054("camt.054 XML")
+	var err error
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting the camt.054 XML as an argument.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	router.Println("start importCamt054")
+	doc := Camt054Document{}
+	err = xml.Unmarshal([]byte(args[0]), &doc)
+	if err == nil {
+		doc.Xmlns = doc.XMLName.Space
+		err = validateCamt054(doc)
+	}
+	if err != nil {
+		errMsg := "{ \"message\" : " + router.JSONString("Invalid camt.054 message: " + err.Error()) + ", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+
+	statementId := camt054StatementID(doc.Notification.MsgId)
+	reconciliationAsBytes, err := stub.GetState(reconciliationKey(statementId))
+	if err != nil {
+		return nil, errors.New("Failed to get Reconciliation for " + statementId)
+	}
+	reconciliation := Reconciliation{}
+	json.Unmarshal(reconciliationAsBytes, &reconciliation)
+	if reconciliation.StatementID == statementId {
+		return nil, router.ErrorEvent(stub, errors.New("camt.054 message " + doc.Notification.MsgId + " was already imported."))
+	}
+	reconciliation = Reconciliation{StatementID: statementId, DateToleranceDays: "0", DateFormat: DefaultStatementDateFormat,
+		Results: []ReconciliationResult{}}
+	exception := func(result ReconciliationResult, matchStatus string, reason string) {
+		result.MatchStatus = matchStatus
+		result.Reason = reason
+		result.ExceptionStatus = "Open"
+		reconciliation.Results = append(reconciliation.Results, result)
+	}
+
+	updated := []string{}
+	for _, ntfctn := range doc.Notification.Ntfctn {
+		for _, ntry := range ntfctn.Ntry {
+			line := ReconciliationResult{Date: ntry.BookgDt, Amount: ntry.Amt.Value, Counterparty: ntfctn.AcctId}
+			if len(ntry.EndToEndIds) == 0 {
+				line.LineNo = strconv.Itoa(len(reconciliation.Results)+1)
+				exception(line, "Unmatched", "Entry without an EndToEndId")
+			}
+			for _, paymentId := range ntry.EndToEndIds {
+				result := line
+				result.LineNo = strconv.Itoa(len(reconciliation.Results)+1)
+				result.Reference = paymentId
+				paymentAsBytes, err := stub.GetState(paymentKey(paymentId))
+				if err != nil {
+					return nil, errors.New("Failed to get Payment " + paymentId)
+				}
+				res := Payment{}
+				json.Unmarshal(paymentAsBytes, &res)
+				if res.PaymentID != paymentId {
+					exception(result, "Unmatched", "No payment found for reference " + paymentId)
+					continue
+				}
+				result.PaymentID = paymentId
+				paymentAmount, _ := strconv.ParseFloat(res.AmountTransferred, 64)
+				entryAmount, _ := strconv.ParseFloat(ntry.Amt.Value, 64)
+				if math.Abs(paymentAmount - entryAmount) > 0.005 {
+					exception(result, "PartiallyMatched", "amount differs: notification " + ntry.Amt.Value + ", payment " + res.AmountTransferred)
+					continue
+				}
+				result.MatchStatus = "Matched"
+				reconciliation.Results = append(reconciliation.Results, result)
+				if ntry.Sts == "BOOK" && ntry.CdtDbtInd == "DBIT" {
+					res.PaymentStatus = "Debited"
+				}else if ntry.Sts == "BOOK" && ntry.CdtDbtInd == "CRDT" {
+					res.PaymentStatus = "Credited"
+				}else if ntry.Sts == "PDNG" {
+					res.PaymentStatus = "Pending at Bank"
+				}else{
+					continue									//INFO entries do not change the payment
+				}
+				res.Camt054MsgID = doc.Notification.MsgId
+				res.Version++
+				res.LastModifiedTxID = stub.GetTxID()
+				res.UpdatedAt, res.UpdatedBy, err = router.TxStamp(stub)
+				if err != nil {
+					return nil, err
+				}
+				err = stub.PutState(paymentKey(paymentId), []byte(paymentJSON(res)))
+				if err != nil {
+					return nil, err
+				}
+				updated = append(updated, paymentId)
+			}
+		}
+	}
+	exceptions := 0
+	for _, result := range reconciliation.Results {
+		if result.ExceptionStatus == "Open" {
+			exceptions++
+		}
+	}
+	jsonAsBytes, _ := json.Marshal(reconciliation)
+	err = stub.PutState(reconciliationKey(statementId), jsonAsBytes)
+	if err != nil {
+		return nil, err
+	}
+	var reconciliationIndex []string
+	reconciliationIndexAsBytes, err := stub.GetState(ReconciliationIndexStr)
+	if err != nil {
+		return nil, errors.New("Failed to get Reconciliation index")
+	}
+	json.Unmarshal(reconciliationIndexAsBytes, &reconciliationIndex)			//un stringify it aka JSON.parse()
+	reconciliationIndex = append(reconciliationIndex, statementId)
+	jsonAsBytes, _ = json.Marshal(reconciliationIndex)
+	err = stub.PutState(ReconciliationIndexStr, jsonAsBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	err = router.Emit(stub, events.Event{Type: events.PaymentNotified, EntityID: doc.Notification.MsgId,
+		Data: map[string]interface{}{"paymentIds": updated, "statementId": statementId, "exceptions": exceptions},
+		Message: "camt.054 notification applied succcessfully"}, nil, nil)
+	if err != nil {
+		return nil, err
+	} 
+	router.Println("end importCamt054")
+	return nil, nil
+}
+// ============================================================================================================================
+// checkLinkedAgreement - confirm with ManageAgreement that the Agreement exists, every party signed it and it is between the
+// same parties
+// ============================================================================================================================
+func (t *ManagePayment) checkLinkedAgreement(stub shim.ChaincodeStubInterface, agreementId string, buyerName string, sellerName string) error {
+	agreement, err := t.getLinkedAgreement(stub, agreementId)
+	if err != nil {
+		return err
+	}
+	if agreement.Agreement_status != router.ApprovedStatus {
+		return errors.New("Agreement " + agreementId + " is not approved by every party, its status is '" + agreement.Agreement_status + "'")
+	}
+	if agreement.Buyer_name != buyerName || agreement.Seller_name != sellerName {
+		return errors.New("Buyer and seller do not match Agreement " + agreementId)
+	}
+	return nil
+}
+// ============================================================================================================================
+// getLinkedAgreement - read an Agreement from ManageAgreement
+// ============================================================================================================================
+func (t *ManagePayment) getLinkedAgreement(stub shim.ChaincodeStubInterface, agreementId string) (linkedAgreement, error) {
+	agreement := linkedAgreement{}
+	agreementAsBytes, err := t.linker.QueryLinked(stub, "agreement", "getAgreement_byID", agreementId)
+	if err != nil {
+		return agreement, errors.New("Failed to query Agreement " + agreementId + ": " + err.Error())
+	}
+	json.Unmarshal(agreementAsBytes, &agreement)
+	if agreement.AgreementID != agreementId || agreementId == "" {
+		return agreement, errors.New("Agreement " + agreementId + " Not Found")
+	}
+	return agreement, nil
+}
+// ============================================================================================================================
+// getPaymentByAgreement - get the payments of an agreement as a JSON array, used for the linked trade record
+// ============================================================================================================================
+func (t *ManagePayment) getPaymentByAgreement(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var paymentIndex []string
+	var err error
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting \"agreementId\" as an argument\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	paymentIndexAsBytes, err := stub.GetState(PaymentIndexStr)
+	if err != nil {
+		return nil, errors.New("Failed to get Payment index")
+	}
+	json.Unmarshal(paymentIndexAsBytes, &paymentIndex)
+	payments := []json.RawMessage{}
+	for _, val := range paymentIndex {
+		valueAsBytes, err := stub.GetState(paymentKey(val))
+		if err != nil {
+			return nil, errors.New("Failed to get state for " + val)
+		}
+		res := Payment{}
+		json.Unmarshal(valueAsBytes, &res)
+		if res.AgreementID == args[0] {
+			payments = append(payments, json.RawMessage(valueAsBytes))
+		}
+	}
+	paymentsAsBytes, _ := json.Marshal(payments)
+	return paymentsAsBytes, nil
+}
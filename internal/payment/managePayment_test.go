@@ -0,0 +1,534 @@
+package payment
+
+import (
+"strings"
+"testing"
+"encoding/json"
+"encoding/xml"
+
+"github.com/wipro-blockchain/TF-v1/events"
+"github.com/wipro-blockchain/TF-v1/internal/agreement"
+"github.com/wipro-blockchain/TF-v1/internal/mockstub"
+"github.com/wipro-blockchain/TF-v1/internal/mocktest"
+"github.com/wipro-blockchain/TF-v1/internal/po"
+"github.com/wipro-blockchain/TF-v1/internal/router"
+"github.com/wipro-blockchain/TF-v1/internal/shipment"
+)
+
+// newTradeFinance - all four domains on a ledger initialized with 10000.00 per account, with PO PO1 and the approved
+// Agreement AGR1 between Buyerco and Sellerco
+func newTradeFinance(t *testing.T) (*router.Router, *mockstub.MockStub) {
+	r := router.New("TradeFinance")
+	r.Register("po", po.New())
+	r.Register("agreement", agreement.New(r))
+	r.Register("payment", New(r))
+	r.Register("shipment", shipment.New(r))
+	stub := mockstub.New("tradeFinance")
+	if _, err := stub.Init(r, "10000.00"); err != nil || stub.Failed() {
+		t.Fatalf("init failed: %v %s", err, stub.LastEvent().Payload)
+	}
+	mocktest.Succeed(t, stub, r, "create_po", "PO1", "Sellerco", "Buyerco", "2024-03-01", "2024-01-15", "Accepted", "ITM-1", "Rice",
+		"100", "25", "true", "true")
+	agreementArgs := []string{"AGR1", "PO1", "Created", "Buyerco", "Sellerco", "Shipco", "Buybank", "Sellbank", "Portauth",
+		"2024-01-16", "ITM-1", "Rice", "100", "2500", "2024-03-01", "0", "100", "Contract", "http://docs/contract", "Terms",
+		"true", "false", "false", "false", "Food", "25"}
+	mocktest.Succeed(t, stub, r, "create_agreement", agreementArgs...)
+	agreementArgs[21], agreementArgs[22], agreementArgs[23] = "true", "true", "true"
+	mocktest.Succeed(t, stub, r, "update_agreement", agreementArgs...)
+	return r, stub
+}
+
+// paymentArgs - the createPayment arguments of an unsigned Payment of 2500 for AGR1
+func paymentArgs(paymentId string) []string {
+	return []string{paymentId, "AGR1", "Buyerco", "Sellerco", "2500", "2024-02-01", "Created", "2024-03-01", "false",
+		"Buybank", "Sellbank"}
+}
+
+// updateArgs - the updatePayment arguments of a Payment of 2500 for AGR1, signed by the buyer bank when signed is "true"
+func updateArgs(paymentId string, signed string) []string {
+	return []string{paymentId, "AGR1", "Buyerco", "Sellerco", BuyerAccountNumber, SellerAccountNumber, "2500", "2024-02-01",
+		"Paid", "2024-03-01", signed, "Buybank", "Sellbank"}
+}
+
+// registerParties - register the identity of every party of AGR1, each in its own MSP
+func registerParties(t *testing.T, r *router.Router, stub *mockstub.MockStub) {
+	for party, identity := range map[string]string{"Buyerco": "BuyerMSP", "Sellerco": "SellerMSP", "Shipco": "ShipMSP",
+		"Buybank": "BuybankMSP", "Sellbank": "SellbankMSP", "Portauth": "PortMSP"} {
+		mocktest.Succeed(t, stub, r, "register_party", party, identity)
+	}
+}
+
+func getPayment(t *testing.T, r *router.Router, stub *mockstub.MockStub, paymentId string) Payment {
+	res := Payment{}
+	if err := json.Unmarshal(mocktest.Succeed(t, stub, r, "getPaymentByID", paymentId), &res); err != nil {
+		t.Fatalf("getPaymentByID(%s): %s", paymentId, err)
+	}
+	return res
+}
+
+func getAccounts(t *testing.T, r *router.Router, stub *mockstub.MockStub) AccountInfo {
+	res := AccountInfo{}
+	if err := json.Unmarshal(mocktest.Succeed(t, stub, r, "getAccountDetails"), &res); err != nil {
+		t.Fatalf("getAccountDetails: %s", err)
+	}
+	return res
+}
+
+func getEscrow(t *testing.T, r *router.Router, stub *mockstub.MockStub, paymentId string) Escrow {
+	res := Escrow{}
+	if err := json.Unmarshal(mocktest.Succeed(t, stub, r, "getEscrowByPaymentID", paymentId), &res); err != nil {
+		t.Fatalf("getEscrowByPaymentID(%s): %s", paymentId, err)
+	}
+	return res
+}
+
+func TestInit(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	accounts := getAccounts(t, r, stub)
+	if accounts.BuyerAccountBalance != "10000.00" || accounts.SellerAccountBalance != "10000.00" || accounts.EscrowAccountBalance != "0.00" {
+		t.Errorf("accounts after init: %+v", accounts)
+	}
+}
+
+func TestArgumentCounts(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	for _, function := range []string{"createPayment", "updatePayment", "deletePayment", "createEscrow",
+		"satisfyEscrowCondition", "refundEscrow", "reconcile_statement", "resolve_reconciliation_exception", "exportPain001",
+		"importCamt054", "apply_liquidated_damages", "getPaymentByID", "getPaymentByBuyer", "getPaymentBySeller", "getAllPayment",
+		"getEscrowByPaymentID", "getEscrowMovements", "get_reconciliation", "get_reconciliation_exceptions", "getPaymentPain001",
+		"getPaymentByAgreement"} {
+		mocktest.Reject(t, stub, r, function, "Incorrect number of arguments")
+	}
+}
+
+func TestCreatePayment(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	mocktest.Succeed(t, stub, r, "createPayment", paymentArgs("PAY1")...)
+	if stub.Message() != "Payment created succcessfully" {
+		t.Errorf("createPayment event: %s", stub.LastEvent().Payload)
+	}
+	res := getPayment(t, r, stub, "PAY1")
+	if res.AgreementID != "AGR1" || res.AmountTransferred != "2500" || res.BuyerAccount != BuyerAccountNumber || res.SellerAccount != SellerAccountNumber {
+		t.Errorf("created Payment: %+v", res)
+	}
+	mocktest.Reject(t, stub, r, "createPayment", "This Payment arleady exists.", paymentArgs("PAY1")...)
+}
+
+func TestCreatePaymentLinkedAgreement(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	args := paymentArgs("PAY1")
+	args[1] = "AGR9"
+	mocktest.Reject(t, stub, r, "createPayment", "Agreement AGR9 Not Found", args...)
+
+	args = paymentArgs("PAY1")
+	args[3] = "Othersell"
+	mocktest.Reject(t, stub, r, "createPayment", "Buyer and seller do not match Agreement AGR1", args...)
+
+	agreementArgs := []string{"AGR2", "PO1", "Created", "Buyerco", "Sellerco", "Shipco", "Buybank", "Sellbank", "Portauth",
+		"2024-01-16", "ITM-1", "Rice", "100", "2500", "2024-03-01", "0", "100", "Contract", "http://docs/contract", "Terms",
+		"true", "false", "false", "false", "Food", "25"}
+	mocktest.Succeed(t, stub, r, "create_agreement", agreementArgs...)
+	args = paymentArgs("PAY1")
+	args[1] = "AGR2"
+	mocktest.Reject(t, stub, r, "createPayment", "Agreement AGR2 is not approved by every party, its status is 'Created'", args...)
+
+	//signed by the buyer bank and the seller, the seller bank has not signed yet
+	agreementArgs[21], agreementArgs[22] = "true", "true"
+	mocktest.Succeed(t, stub, r, "update_agreement", agreementArgs...)
+	mocktest.Reject(t, stub, r, "createPayment", "its status is 'Approved By Seller'", args...)
+}
+
+func TestUpdatePaymentSettles(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	mocktest.Succeed(t, stub, r, "createPayment", paymentArgs("PAY1")...)
+	mocktest.Succeed(t, stub, r, "updatePayment", updateArgs("PAY1", "false")...)
+	if accounts := getAccounts(t, r, stub); accounts.BuyerAccountBalance != "10000.00" {
+		t.Errorf("unsigned payment moved funds: %+v", accounts)
+	}
+	mocktest.Succeed(t, stub, r, "updatePayment", updateArgs("PAY1", "true")...)
+	if stub.Message() != "Payment updated succcessfully" {
+		t.Errorf("updatePayment event: %s", stub.LastEvent().Payload)
+	}
+	accounts := getAccounts(t, r, stub)
+	if accounts.BuyerAccountBalance != "7500.00" || accounts.SellerAccountBalance != "12500.00" {
+		t.Errorf("accounts after settlement: %+v", accounts)
+	}
+	if res := getPayment(t, r, stub, "PAY1"); res.PaymentStatus != "Paid" || res.BuyerBank_sign != "true" {
+		t.Errorf("updated Payment: %+v", res)
+	}
+	mocktest.Succeed(t, stub, r, "updatePayment", updateArgs("PAY1", "true")...)
+	if accounts = getAccounts(t, r, stub); accounts.BuyerAccountBalance != "7500.00" || accounts.SellerAccountBalance != "12500.00" {
+		t.Errorf("a second update of a signed payment moved funds: %+v", accounts)
+	}
+}
+
+func TestUpdatePaymentErrors(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	mocktest.Reject(t, stub, r, "updatePayment", "PAY9 Not Found.", updateArgs("PAY9", "false")...)
+	mocktest.Succeed(t, stub, r, "createPayment", paymentArgs("PAY1")...)
+	args := updateArgs("PAY1", "true")
+	args[1] = "AGR9"
+	mocktest.Reject(t, stub, r, "updatePayment", "Agreement AGR9 Not Found", args...)
+	if accounts := getAccounts(t, r, stub); accounts.BuyerAccountBalance != "10000.00" {
+		t.Errorf("rejected update moved funds: %+v", accounts)
+	}
+}
+
+func TestDeletePayment(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	mocktest.Succeed(t, stub, r, "createPayment", paymentArgs("PAY1")...)
+	mocktest.Succeed(t, stub, r, "createPayment", paymentArgs("PAY2")...)
+	mocktest.Succeed(t, stub, r, "deletePayment", "PAY1")
+	if stub.Message() != "Payment deleted succcessfully" {
+		t.Errorf("deletePayment event: %s", stub.LastEvent().Payload)
+	}
+	if valAsBytes := mocktest.Succeed(t, stub, r, "getPaymentByID", "PAY1"); len(valAsBytes) != 0 {
+		t.Errorf("deleted Payment still readable: %s", valAsBytes)
+	}
+	if string(stub.State[PaymentIndexStr]) != `["PAY2"]` {
+		t.Errorf("Payment index after delete: %s", stub.State[PaymentIndexStr])
+	}
+}
+
+func TestGetPaymentLists(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	mocktest.Succeed(t, stub, r, "createPayment", paymentArgs("PAY1")...)
+	mocktest.Succeed(t, stub, r, "createPayment", paymentArgs("PAY2")...)
+	for _, query := range [][]string{{"getAllPayment", " "}, {"getPaymentByBuyer", "Buyerco"}, {"getPaymentBySeller", "Sellerco"}} {
+		if valAsBytes := string(mocktest.Succeed(t, stub, r, query[0], query[1])); !strings.Contains(valAsBytes, `"PAY1"`) || !strings.Contains(valAsBytes, `"PAY2"`) {
+			t.Errorf("%s: %s", query[0], valAsBytes)
+		}
+	}
+	var byAgreement []Payment
+	if err := json.Unmarshal(mocktest.Succeed(t, stub, r, "getPaymentByAgreement", "AGR1"), &byAgreement); err != nil || len(byAgreement) != 2 {
+		t.Errorf("getPaymentByAgreement: %v %v", byAgreement, err)
+	}
+}
+
+func TestEscrowRelease(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	mocktest.Succeed(t, stub, r, "createPayment", paymentArgs("PAY1")...)
+	mocktest.Succeed(t, stub, r, "createEscrow", "PAY1", "ShipmentDelivered", "PortCleared")
+	if stub.Message() != "Escrow created succcessfully" {
+		t.Errorf("createEscrow event: %s", stub.LastEvent().Payload)
+	}
+	mocktest.Reject(t, stub, r, "createEscrow", "This Escrow already exists.", "PAY1", "ShipmentDelivered")
+
+	mocktest.Succeed(t, stub, r, "updatePayment", updateArgs("PAY1", "true")...)
+	accounts := getAccounts(t, r, stub)
+	if accounts.BuyerAccountBalance != "7500.00" || accounts.EscrowAccountBalance != "2500.00" || accounts.SellerAccountBalance != "10000.00" {
+		t.Errorf("accounts after deposit: %+v", accounts)
+	}
+
+	//the conditions are checked against the Shipments and the Agreement, in the name of a party the caller acts for
+	registerParties(t, r, stub)
+	mocktest.ActAs(t, stub, "ShipMSP", "clerk")
+	mocktest.Reject(t, stub, r, "satisfyEscrowCondition", "No Shipment of Agreement AGR1 is delivered yet", "PAY1", "ShipmentDelivered", "Shipco")
+	mocktest.Succeed(t, stub, r, "create_shipment", "SHP1", "PO1", "AGR1", "Created", "Mumbai", "Rotterdam", "", "2024-02-01", "Shipco")
+	mocktest.Succeed(t, stub, r, "add_tracking_event", "SHP1", "Delivered", "Rotterdam", "2024-02-28T10:00:00Z", "Shipco")
+	mocktest.Reject(t, stub, r, "satisfyEscrowCondition", "Mallory is not a party of Agreement AGR1", "PAY1", "ShipmentDelivered", "Mallory")
+	mocktest.Reject(t, stub, r, "satisfyEscrowCondition", "The caller ShipMSP:clerk does not act for Sellerco", "PAY1", "ShipmentDelivered", "Sellerco")
+	mocktest.Succeed(t, stub, r, "satisfyEscrowCondition", "PAY1", "ShipmentDelivered", "Shipco")
+	if stub.Message() != "Escrow condition ShipmentDelivered satisfied succcessfully" {
+		t.Errorf("satisfyEscrowCondition event: %s", stub.LastEvent().Payload)
+	}
+	if escrow := getEscrow(t, r, stub, "PAY1"); escrow.Conditions[0].SatisfiedBy != "Shipco" {
+		t.Errorf("satisfied condition: %+v", escrow.Conditions[0])
+	}
+	mocktest.Reject(t, stub, r, "satisfyEscrowCondition", "BuyerAccepted is not a release condition", "PAY1", "BuyerAccepted", "Buyerco")
+	mocktest.ActAs(t, stub, "PortMSP", "officer")
+	mocktest.Reject(t, stub, r, "satisfyEscrowCondition", "Agreement AGR1 is not cleared by the port authority yet", "PAY1", "PortCleared", "Portauth")
+	mocktest.Succeed(t, stub, r, "port_clearance_action", "SHP1", "Portauth", "Clear", "Inspected at Rotterdam")
+	mocktest.Succeed(t, stub, r, "satisfyEscrowCondition", "PAY1", "PortCleared", "Portauth")
+	if stub.Message() != "Escrow released to seller succcessfully" {
+		t.Errorf("satisfyEscrowCondition event: %s", stub.LastEvent().Payload)
+	}
+	accounts = getAccounts(t, r, stub)
+	if accounts.EscrowAccountBalance != "0.00" || accounts.SellerAccountBalance != "12500.00" {
+		t.Errorf("accounts after release: %+v", accounts)
+	}
+	escrow := getEscrow(t, r, stub, "PAY1")
+	if escrow.EscrowStatus != "Released" || len(escrow.Movements) != 2 || escrow.Movements[1].MovementType != "Release" {
+		t.Errorf("released Escrow: %+v", escrow)
+	}
+	mocktest.Reject(t, stub, r, "refundEscrow", "Escrow is already Released.", "PAY1", "Too late")
+}
+
+func TestEscrowSettlement(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	registerParties(t, r, stub)
+	mocktest.Succeed(t, stub, r, "createPayment", paymentArgs("PAY1")...)
+	mocktest.Succeed(t, stub, r, "createEscrow", "PAY1", "BuyerAccepted")
+
+	//a condition the ledger does not record is vouched for by its party
+	mocktest.ActAs(t, stub, "SellerMSP", "clerk")
+	mocktest.Reject(t, stub, r, "satisfyEscrowCondition", "The caller SellerMSP:clerk does not act for Buyerco", "PAY1", "BuyerAccepted", "Buyerco")
+	mocktest.ActAs(t, stub, "BuyerMSP", "clerk")
+	mocktest.Succeed(t, stub, r, "satisfyEscrowCondition", "PAY1", "BuyerAccepted", "Buyerco")
+	if escrow := getEscrow(t, r, stub, "PAY1"); escrow.EscrowStatus != "Pending" {
+		t.Errorf("Escrow satisfied before the deposit: %+v", escrow)
+	}
+
+	//the funds met every condition before they were deposited, so they go on to the seller at once
+	mocktest.Succeed(t, stub, r, "updatePayment", updateArgs("PAY1", "true")...)
+	accounts := getAccounts(t, r, stub)
+	if accounts.BuyerAccountBalance != "7500.00" || accounts.EscrowAccountBalance != "0.00" || accounts.SellerAccountBalance != "12500.00" {
+		t.Errorf("accounts after settlement: %+v", accounts)
+	}
+	if escrow := getEscrow(t, r, stub, "PAY1"); escrow.EscrowStatus != "Released" || len(escrow.Movements) != 2 {
+		t.Errorf("Escrow after settlement: %+v", escrow)
+	}
+
+	//a cancelled escrow can not be settled
+	mocktest.Succeed(t, stub, r, "createPayment", paymentArgs("PAY2")...)
+	mocktest.Succeed(t, stub, r, "createEscrow", "PAY2", "ShipmentDelivered")
+	mocktest.Succeed(t, stub, r, "refundEscrow", "PAY2", "Order cancelled")
+	mocktest.Reject(t, stub, r, "updatePayment", "Payment can not be settled, its escrow is Cancelled.", updateArgs("PAY2", "true")...)
+	if accounts := getAccounts(t, r, stub); accounts.BuyerAccountBalance != "7500.00" {
+		t.Errorf("accounts after a refused settlement: %+v", accounts)
+	}
+}
+
+func TestEscrowRefund(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	mocktest.Succeed(t, stub, r, "createPayment", paymentArgs("PAY1")...)
+	mocktest.Succeed(t, stub, r, "createEscrow", "PAY1", "ShipmentDelivered")
+	mocktest.Succeed(t, stub, r, "updatePayment", updateArgs("PAY1", "true")...)
+	mocktest.Reject(t, stub, r, "refundEscrow", "acts for none of Sellerco, Sellbank", "PAY1", "Shipment lost")
+	registerParties(t, r, stub)
+	mocktest.ActAs(t, stub, "BuyerMSP", "clerk")
+	mocktest.Reject(t, stub, r, "refundEscrow", "Escrow is Held. The caller BuyerMSP:clerk acts for none of Sellerco, Sellbank", "PAY1", "Shipment lost")
+	mocktest.ActAs(t, stub, "SellbankMSP", "officer")
+	mocktest.Succeed(t, stub, r, "refundEscrow", "PAY1", "Shipment lost")
+	if stub.Message() != "Escrow refunded to buyer succcessfully" {
+		t.Errorf("refundEscrow event: %s", stub.LastEvent().Payload)
+	}
+	if accounts := getAccounts(t, r, stub); accounts.BuyerAccountBalance != "10000.00" || accounts.EscrowAccountBalance != "0.00" {
+		t.Errorf("accounts after refund: %+v", accounts)
+	}
+	mocktest.Reject(t, stub, r, "satisfyEscrowCondition", "Escrow is already Refunded.", "PAY1", "ShipmentDelivered", "Shipco")
+
+	var movements []EscrowMovement
+	if err := json.Unmarshal(mocktest.Succeed(t, stub, r, "getEscrowMovements", "PAY1"), &movements); err != nil || len(movements) != 2 {
+		t.Errorf("getEscrowMovements: %v %v", movements, err)
+	}
+}
+
+func TestEscrowErrors(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	mocktest.Reject(t, stub, r, "createEscrow", "PAY9 Not Found.", "PAY9", "ShipmentDelivered")
+	mocktest.Reject(t, stub, r, "satisfyEscrowCondition", "Escrow for PAY9 Not Found.", "PAY9", "ShipmentDelivered", "Shipco")
+	mocktest.Reject(t, stub, r, "refundEscrow", "Escrow for PAY9 Not Found.", "PAY9", "Lost")
+
+	mocktest.Succeed(t, stub, r, "createPayment", paymentArgs("PAY1")...)
+	mocktest.Succeed(t, stub, r, "updatePayment", updateArgs("PAY1", "true")...)
+	mocktest.Reject(t, stub, r, "createEscrow", "it can not be put in escrow", "PAY1", "ShipmentDelivered")
+
+	mocktest.Succeed(t, stub, r, "createPayment", paymentArgs("PAY2")...)
+	mocktest.Succeed(t, stub, r, "createEscrow", "PAY2", "ShipmentDelivered")
+	registerParties(t, r, stub)
+	mocktest.ActAs(t, stub, "ShipMSP", "clerk")
+	mocktest.Reject(t, stub, r, "refundEscrow", "acts for none of Buyerco, Sellerco, Buybank, Sellbank", "PAY2", "Order cancelled")
+	mocktest.ActAs(t, stub, "BuyerMSP", "clerk")
+	mocktest.Succeed(t, stub, r, "refundEscrow", "PAY2", "Order cancelled")
+	if stub.Message() != "Escrow cancelled succcessfully" {
+		t.Errorf("refundEscrow of a pending Escrow: %s", stub.LastEvent().Payload)
+	}
+}
+
+// deliverLate - deliver Shipment SHP1 of AGR1 five days late, 37.50 of liquidated damages with the terms of the test
+func deliverLate(t *testing.T, r *router.Router, stub *mockstub.MockStub) {
+	mocktest.Succeed(t, stub, r, "set_liquidated_damages", "AGR1", "0.5", "10", "2")
+	mocktest.Succeed(t, stub, r, "create_shipment", "SHP1", "PO1", "AGR1", "Created", "Mumbai", "Rotterdam", "", "2024-02-01", "Shipco")
+	mocktest.Succeed(t, stub, r, "add_tracking_event", "SHP1", "Delivered", "Rotterdam", "2024-03-06", "Shipco")
+}
+
+func TestLiquidatedDamages(t *testing.T) {
+	//a Payment signed after the late delivery pays the seller less the damages
+	r, stub := newTradeFinance(t)
+	mocktest.Succeed(t, stub, r, "createPayment", paymentArgs("PAY1")...)
+	deliverLate(t, r, stub)
+	mocktest.Reject(t, stub, r, "apply_liquidated_damages", "No liquidated damages are due from a settled Payment of Agreement AGR1.", "AGR1")
+	mocktest.Succeed(t, stub, r, "updatePayment", updateArgs("PAY1", "true")...)
+	accounts := getAccounts(t, r, stub)
+	if accounts.BuyerAccountBalance != "7537.50" || accounts.SellerAccountBalance != "12462.50" {
+		t.Errorf("accounts after settlement: %+v", accounts)
+	}
+	if res := getPayment(t, r, stub, "PAY1"); res.LiquidatedDamages != "37.50" {
+		t.Errorf("Payment after settlement: %+v", res)
+	}
+	mocktest.Reject(t, stub, r, "apply_liquidated_damages", "No liquidated damages are due", "AGR1")
+
+	//a Payment paid before the delivery is paid back by the seller
+	r, stub = newTradeFinance(t)
+	mocktest.Succeed(t, stub, r, "createPayment", paymentArgs("PAY1")...)
+	mocktest.Succeed(t, stub, r, "updatePayment", updateArgs("PAY1", "true")...)
+	deliverLate(t, r, stub)
+	mocktest.Succeed(t, stub, r, "apply_liquidated_damages", "AGR1")
+	if event, err := events.Decode(stub.LastEvent().Name, stub.LastEvent().Payload); err != nil || event.Data["amount"] != "37.50" ||
+		len(event.Changes) != 1 || event.Changes[0].Field != "PAY1" {
+		t.Errorf("apply_liquidated_damages event: %s", stub.LastEvent().Payload)
+	}
+	accounts = getAccounts(t, r, stub)
+	if accounts.BuyerAccountBalance != "7537.50" || accounts.SellerAccountBalance != "12462.50" {
+		t.Errorf("accounts after the deduction: %+v", accounts)
+	}
+	mocktest.Reject(t, stub, r, "apply_liquidated_damages", "No liquidated damages are due", "AGR1")
+
+	//a held escrow gives the damages back to the buyer and releases the rest
+	r, stub = newTradeFinance(t)
+	mocktest.Succeed(t, stub, r, "createPayment", paymentArgs("PAY1")...)
+	mocktest.Succeed(t, stub, r, "createEscrow", "PAY1", "ShipmentDelivered")
+	mocktest.Succeed(t, stub, r, "updatePayment", updateArgs("PAY1", "true")...)
+	deliverLate(t, r, stub)
+	mocktest.Succeed(t, stub, r, "apply_liquidated_damages", "AGR1")
+	registerParties(t, r, stub)
+	mocktest.ActAs(t, stub, "ShipMSP", "clerk")
+	mocktest.Succeed(t, stub, r, "satisfyEscrowCondition", "PAY1", "ShipmentDelivered", "Shipco")
+	accounts = getAccounts(t, r, stub)
+	if accounts.BuyerAccountBalance != "7537.50" || accounts.EscrowAccountBalance != "0.00" || accounts.SellerAccountBalance != "12462.50" {
+		t.Errorf("accounts after release: %+v", accounts)
+	}
+	escrow := getEscrow(t, r, stub, "PAY1")
+	if escrow.EscrowStatus != "Released" || len(escrow.Movements) != 3 || escrow.Movements[1].MovementType != "Deduct" ||
+		escrow.Movements[2].Amount != "2462.50" {
+		t.Errorf("released Escrow: %+v", escrow)
+	}
+}
+
+func TestReconcileStatement(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	mocktest.Succeed(t, stub, r, "createPayment", paymentArgs("PAY1")...)
+	mocktest.Succeed(t, stub, r, "createPayment", paymentArgs("PAY2")...)
+	statement := "date,amount,reference,counterparty\n" +
+		"2024-02-02,2500,Trade AGR1,Buyerco\n" +
+		"2024-02-20,2500.00,Trade AGR1,Buyerco\n" +
+		"2024-02-01,99,Unknown,Someone\n"
+	mocktest.Succeed(t, stub, r, "reconcile_statement", "ST1", "csv", statement, "3")
+	event, err := events.Decode(stub.LastEvent().Name, stub.LastEvent().Payload)
+	if err != nil || event.Type != events.StatementReconciled || event.Data["matched"] != 1.0 || event.Data["partiallyMatched"] != 1.0 ||
+		event.Data["unmatched"] != 1.0 {
+		t.Errorf("reconcile_statement event: %s", stub.LastEvent().Payload)
+	}
+	mocktest.Reject(t, stub, r, "reconcile_statement", "This Statement is already reconciled.", "ST1", "csv", statement, "3")
+
+	res := Reconciliation{}
+	if err := json.Unmarshal(mocktest.Succeed(t, stub, r, "get_reconciliation", "ST1"), &res); err != nil || len(res.Results) != 3 {
+		t.Fatalf("get_reconciliation: %+v %v", res, err)
+	}
+	if res.Results[0].MatchStatus != "Matched" || res.Results[1].MatchStatus != "PartiallyMatched" || res.Results[2].MatchStatus != "Unmatched" {
+		t.Errorf("reconciliation results: %+v", res.Results)
+	}
+	if res.Results[1].PaymentID != "PAY2" || !strings.Contains(res.Results[1].Reason, "date outside tolerance") {
+		t.Errorf("partial match: %+v", res.Results[1])
+	}
+
+	mocktest.Succeed(t, stub, r, "resolve_reconciliation_exception", "ST1", "3", "Bank fee")
+	mocktest.Reject(t, stub, r, "resolve_reconciliation_exception", "No open exception for line 3", "ST1", "3", "Bank fee")
+	exceptions := map[string][]ReconciliationResult{}
+	if err := json.Unmarshal(mocktest.Succeed(t, stub, r, "get_reconciliation_exceptions", " "), &exceptions); err != nil || len(exceptions["ST1"]) != 1 {
+		t.Errorf("get_reconciliation_exceptions: %v %v", exceptions, err)
+	}
+}
+
+func TestReconcileStatementMatching(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	mocktest.Succeed(t, stub, r, "createPayment", paymentArgs("PAY1")...)
+	reconcile := func(statementId string, dateFormat string, statement string) Reconciliation {
+		mocktest.Succeed(t, stub, r, "reconcile_statement", statementId, "csv", statement, "1", dateFormat)
+		res := Reconciliation{}
+		json.Unmarshal(mocktest.Succeed(t, stub, r, "get_reconciliation", statementId), &res)
+		return res
+	}
+
+	//the reference names the agreement as a whole word, and 01/02 is read in the format the statement names
+	res := reconcile("ST1", "MM/DD/YYYY", "01/02/2024,2500,Trade AGR10,Buyerco\n01/02/2024,2500,Trade AGR1,Buyerco\n")
+	if res.DateFormat != "MM/DD/YYYY" || res.Results[0].MatchStatus != "Unmatched" || res.Results[1].MatchStatus != "PartiallyMatched" ||
+		!strings.Contains(res.Results[1].Reason, "date outside tolerance") {
+		t.Errorf("statement ST1 in MM/DD/YYYY: %+v", res)
+	}
+	res = reconcile("ST2", "DD/MM/YYYY", "01/02/2024,2500,AGR1/INV-7,Buyerco\n")
+	if res.Results[0].MatchStatus != "Matched" || res.Results[0].PaymentID != "PAY1" {
+		t.Errorf("statement ST2 in DD/MM/YYYY: %+v", res)
+	}
+
+	//a payment is matched by one statement line only, whichever statement it comes in
+	res = reconcile("ST3", "", "2024-02-01,2500,Trade AGR1,Buyerco\n")
+	if res.DateFormat != "YYYY-MM-DD" || res.Results[0].MatchStatus != "Unmatched" ||
+		res.Results[0].Reason != "Payment PAY1 is already reconciled with statement ST2 line 1" {
+		t.Errorf("statement ST3 repeating a matched payment: %+v", res)
+	}
+	mocktest.Reject(t, stub, r, "reconcile_statement", "Unknown date format DD.MM.YYYY, expecting one of DD-MM-YYYY, DD/MM/YYYY",
+		"ST4", "csv", "", "1", "DD.MM.YYYY")
+}
+
+func TestReconcileStatementErrors(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	mocktest.Reject(t, stub, r, "reconcile_statement", "dateToleranceDays must be a non-negative number.", "ST1", "csv", "", "-1")
+	mocktest.Reject(t, stub, r, "reconcile_statement", "Unknown statement format xls", "ST1", "xls", "", "1")
+	mocktest.Reject(t, stub, r, "reconcile_statement", "Statement lines are not a valid JSON array", "ST1", "json", "{", "1")
+	mocktest.Reject(t, stub, r, "reconcile_statement", "must have 4 columns", "ST1", "csv", "2024-02-01,1", "1")
+	mocktest.Reject(t, stub, r, "resolve_reconciliation_exception", "Statement ST9 Not Found.", "ST9", "1", "Fixed")
+}
+
+func TestPain001(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	mocktest.Succeed(t, stub, r, "createPayment", paymentArgs("PAY1")...)
+	mocktest.Reject(t, stub, r, "exportPain001", "Only a settled payment can be exported as pain.001.", "PAY1")
+	mocktest.Reject(t, stub, r, "getPaymentPain001", "PAY1 has not been exported with exportPain001.", "PAY1")
+
+	mocktest.Succeed(t, stub, r, "updatePayment", updateArgs("PAY1", "true")...)
+	mocktest.Succeed(t, stub, r, "exportPain001", "PAY1")
+	msgId := "P001-" + stub.TxID
+	if res := getPayment(t, r, stub, "PAY1"); res.Pain001MsgID != msgId || res.Pain001CreDtTm == "" {
+		t.Errorf("exported Payment: %+v", res)
+	}
+	doc := Pain001Document{}
+	if err := xml.Unmarshal(mocktest.Succeed(t, stub, r, "getPaymentPain001", "PAY1"), &doc); err != nil {
+		t.Fatalf("getPaymentPain001: %s", err)
+	}
+	tx := doc.CstmrCdtTrfInitn.PmtInf.CdtTrfTxInf
+	if doc.CstmrCdtTrfInitn.GrpHdr.MsgId != msgId || tx.EndToEndId != "PAY1" || tx.InstdAmt.Value != "2500.00" || tx.Ustrd != "AGR1" {
+		t.Errorf("pain.001: %+v", doc.CstmrCdtTrfInitn)
+	}
+}
+
+// camt054 - a camt.054 notification with one booked entry for paymentId
+func camt054(msgId string, cdtDbtInd string, amount string, paymentId string) string {
+	return `<Document xmlns="` + Camt054Namespace + `"><BkToCstmrDbtCdtNtfctn>` +
+		`<GrpHdr><MsgId>` + msgId + `</MsgId><CreDtTm>2024-02-02T10:00:00</CreDtTm></GrpHdr>` +
+		`<Ntfctn><Id>N1</Id><CreDtTm>2024-02-02T10:00:00</CreDtTm><Acct><Id><Othr><Id>` + BuyerAccountNumber + `</Id></Othr></Id></Acct>` +
+		`<Ntry><Amt Ccy="USD">` + amount + `</Amt><CdtDbtInd>` + cdtDbtInd + `</CdtDbtInd><Sts>BOOK</Sts><BookgDt><Dt>2024-02-02</Dt></BookgDt>` +
+		`<NtryDtls><TxDtls><Refs><EndToEndId>` + paymentId + `</EndToEndId></Refs></TxDtls></NtryDtls></Ntry>` +
+		`</Ntfctn></BkToCstmrDbtCdtNtfctn></Document>`
+}
+
+func TestImportCamt054(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	mocktest.Succeed(t, stub, r, "createPayment", paymentArgs("PAY1")...)
+	mocktest.Reject(t, stub, r, "importCamt054", "Ntry/CdtDbtInd must be CRDT or DBIT", camt054("C1", "BOTH", "2500.00", "PAY1"))
+	mocktest.Reject(t, stub, r, "importCamt054", "Invalid camt.054 message", "<Document>")
+
+	mocktest.Succeed(t, stub, r, "importCamt054", camt054("C1", "DBIT", "2500.00", "PAY1"))
+	if stub.Message() != "camt.054 notification applied succcessfully" {
+		t.Errorf("importCamt054 event: %s", stub.LastEvent().Payload)
+	}
+	if res := getPayment(t, r, stub, "PAY1"); res.PaymentStatus != "Debited" || res.Camt054MsgID != "C1" {
+		t.Errorf("notified Payment: %+v", res)
+	}
+	mocktest.Reject(t, stub, r, "importCamt054", "camt.054 message C1 was already imported.", camt054("C1", "CRDT", "2500.00", "PAY1"))
+
+	//the entries naming no payment, or another amount, are left as exceptions
+	mocktest.Succeed(t, stub, r, "importCamt054", camt054("C2", "CRDT", "99.00", "PAY1"))
+	mocktest.Succeed(t, stub, r, "importCamt054", camt054("C3", "CRDT", "2500.00", "PAY9"))
+	if res := getPayment(t, r, stub, "PAY1"); res.PaymentStatus != "Debited" || res.Camt054MsgID != "C1" {
+		t.Errorf("Payment after unmatched entries: %+v", res)
+	}
+	exceptions := map[string][]ReconciliationResult{}
+	json.Unmarshal(mocktest.Succeed(t, stub, r, "get_reconciliation_exceptions", " "), &exceptions)
+	if len(exceptions) != 2 || exceptions["camt054-C2"][0].MatchStatus != "PartiallyMatched" ||
+		exceptions["camt054-C3"][0].Reason != "No payment found for reference PAY9" {
+		t.Errorf("camt.054 exceptions: %+v", exceptions)
+	}
+	mocktest.Succeed(t, stub, r, "resolve_reconciliation_exception", "camt054-C3", "1", "Payment of another chaincode")
+}
@@ -0,0 +1,688 @@
+/*/*
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements.  See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership.  The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License.  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package po
+
+import (
+"errors"
+"strconv"
+"encoding/json"
+
+"github.com/hyperledger/fabric-chaincode-go/shim"
+"github.com/wipro-blockchain/TF-v1/events"
+"github.com/wipro-blockchain/TF-v1/internal/router"
+)
+
+// ManagePO is the PO domain of the trade-finance chaincodes
+type ManagePO struct {
+}
+
+var POIndexStr = "_POindex"				//name for the key/value that will store a list of all known PO
+var poCreateFields = []string{"transId", "sellerName", "buyerName", "expectedDeliveryDate", "po_date", "po_status", "item_id",
+	"item_name", "item_quantity", "price", "buyer_sign", "seller_sign"}						//create_po arguments by JSON name, in order
+var poUpdateFields = append(append([]string{}, poCreateFields...), "seller_remarks", "version")	//update_po arguments by JSON name, in order
+
+type PO struct{							// Attributes of a PO 
+	TransID string `json:"transId"`					
+	SellerName string `json:"sellerName"`
+	BuyerName string `json:"buyerName"`					
+	ExpectedDeliveryDate string `json:"expectedDeliveryDate"`
+	PO_status string `json:"po_status"`
+	PO_date string `json:"po_date"`
+	ItemId string `json:"item_id"`
+	Item_name string `json:"item_name"`
+	Item_quantity string `json:"item_quantity"`
+	Price string `json:"price"`
+	Buyer_sign string `json:"buyer_sign"`
+	Seller_sign string `json:"seller_sign"`
+	Seller_Remarks string `json:"seller_remarks" metadata:",optional"`
+	Version int `json:"version" metadata:",optional"`						// incremented by every write
+	LastModifiedTxID string `json:"lastModifiedTxId" metadata:",optional"`	// the transaction of the last write
+	CreatedAt string `json:"createdAt" metadata:",optional"`					// the ledger time of the create, see router.TxStamp
+	CreatedBy string `json:"createdBy" metadata:",optional"`					// the caller of the create
+	UpdatedAt string `json:"updatedAt" metadata:",optional"`					// the ledger time of the last write
+	UpdatedBy string `json:"updatedBy" metadata:",optional"`					// the caller of the last write
+}
+// ============================================================================================================================
+// New - PO management
+// ============================================================================================================================
+func New() *ManagePO {
+	return &ManagePO{}
+}
+// ============================================================================================================================
+// poKey - key under which a PO is stored, apart from the records of the other types and the indexes
+// ============================================================================================================================
+func poKey(transId string) string {
+	return "PO_" + transId
+}
+// ============================================================================================================================
+// Init - reset all the things
+// ============================================================================================================================
+func (t *ManagePO) Init(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+	var msg string
+	var err error
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting ' ' as an argument\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	// Initialize the chaincode
+	msg = args[0]
+	// Write the state to the ledger
+	err = stub.PutState("abc", []byte(msg))				//making a test var "abc", I find it handy to read/write to it right away to test the network
+	if err != nil {
+		return nil, err
+	}
+	var empty []string
+	jsonAsBytes, _ := json.Marshal(empty)								//marshal an emtpy array of strings to clear the index
+	err = stub.PutState(POIndexStr, jsonAsBytes)
+	if err != nil {
+		return nil, err
+	}
+	err = router.Emit(stub, events.Event{Type: events.ChaincodeDeployed, EntityID: "ManagePO",
+		Message: "ManagePO chaincode is deployed successfully."}, nil, nil)
+	if err != nil {
+		return nil, err
+	} 
+	return nil, nil
+}
+// ============================================================================================================================
+// KeyMoves - the records of PO management to move from their bare ID to their namespaced key, see router.Migrator
+// ============================================================================================================================
+func (t *ManagePO) KeyMoves() []router.KeyMove {
+	return []router.KeyMove{{Index: POIndexStr, Key: poKey}}
+}
+// ============================================================================================================================
+// Invokes - the invoke functions of PO management, by function name
+// ============================================================================================================================
+func (t *ManagePO) Invokes() map[string]router.Handler {
+	return map[string]router.Handler{
+		"create_po": router.Named(t.create_po, "create_po", poCreateFields, nil),					//create a new PO
+		"delete_po": t.delete_po,					// delete a PO
+		"update_po": router.Named(t.update_po, "update_po", poUpdateFields, t.getPO_byID),					//update a PO
+	}
+}
+// ============================================================================================================================
+// Queries - the query functions of PO management, by function name
+// ============================================================================================================================
+func (t *ManagePO) Queries() map[string]router.Handler {
+	return map[string]router.Handler{
+		"getPO_byID": t.getPO_byID,					//Read a PO by transId
+		"getPO_byBuyer": t.getPO_byBuyer,					//Read a PO by Buyer's name
+		"getPO_bySeller": t.getPO_bySeller,					//Read a PO by Seller's name
+		"get_AllPO": t.get_AllPO,					//Read all POs
+	}
+}
+// ============================================================================================================================
+// getPO_byID - get PO details for a specific ID from chaincode state
+// ============================================================================================================================
+func (t *ManagePO) getPO_byID(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var transId string
+	var err error
+	router.Println("start getPO_byID")
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting 'transId' as an argument\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	// set transId
+	transId = args[0]
+	valAsbytes, err := stub.GetState(poKey(transId))									//get the transId from chaincode state
+	if err != nil {
+		errMsg := "{ \"message\" : " + router.JSONString(transId + " not Found.") + ", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	//router.Print("valAsbytes : ")
+	//router.Println(valAsbytes)
+	router.Println("end getPO_byID")
+	return valAsbytes, nil													//send it onward
+}
+// ============================================================================================================================
+//  getPO_byBuyer - get PO details by buyer's name from chaincode state
+// ============================================================================================================================
+func (t *ManagePO) getPO_byBuyer(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var jsonResp, buyerName, errResp string
+	var poIndex []string
+	var valIndex PO
+	router.Println("start getPO_byBuyer")
+	var err error
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting 'buyerName' as an argument\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	// set buyer's name
+	buyerName = args[0]
+	//router.Println("buyerName" + buyerName)
+	poAsBytes, err := stub.GetState(POIndexStr)
+	if err != nil {
+		return nil, errors.New("Failed to get PO index string")
+	}
+	//router.Print("poAsBytes : ")
+	//router.Println(poAsBytes)
+	json.Unmarshal(poAsBytes, &poIndex)								//un stringify it aka JSON.parse()
+	router.Print("poIndex : ")
+	router.Println(poIndex)
+	//router.Println("len(poIndex) : ")
+	//router.Println(len(poIndex))
+	jsonResp = "{"
+	for i,val := range poIndex{
+		router.Println(strconv.Itoa(i) + " - looking at " + val + " for getPO_byBuyer")
+		valueAsBytes, err := stub.GetState(poKey(val))
+		if err != nil {
+			errResp = "{\"Error\":\"Failed to get state for " + val + "\"}"
+			return nil, errors.New(errResp)
+		}
+		//router.Print("valueAsBytes : ")
+		//router.Println(valueAsBytes)
+		json.Unmarshal(valueAsBytes, &valIndex)
+		router.Print("valIndex: ")
+		router.Print(valIndex)
+		if valIndex.BuyerName == buyerName{
+			router.Println("Buyer found")
+			jsonResp = jsonResp + "\""+ val + "\":" + string(valueAsBytes[:])
+			//router.Println("jsonResp inside if")
+			//router.Println(jsonResp)
+			if i < len(poIndex)-1 {
+				jsonResp = jsonResp + ","
+			}
+		} 
+	}
+	jsonResp = jsonResp + "}"
+	router.Println("jsonResp : " + jsonResp)
+	//router.Print("jsonResp in bytes : ")
+	//router.Println([]byte(jsonResp))
+	router.Println("end getPO_byBuyer")
+	return []byte(jsonResp), nil											//send it onward
+}
+
+// ============================================================================================================================
+//  getPO_bySeller - get PO details for a specific Seller from chaincode state
+// ============================================================================================================================
+func (t *ManagePO) getPO_bySeller(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var jsonResp, sellerName, errResp string
+	var poIndex []string
+	var valIndex PO
+	router.Println("start getPO_bySeller")
+	var err error
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting 'sellerName' as an argument\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	// set seller name
+	sellerName = args[0]
+	//router.Println("buyerName" + sellerName)
+	poAsBytes, err := stub.GetState(POIndexStr)
+	if err != nil {
+		return nil, errors.New("Failed to get PO index")
+	}
+	//router.Print("poAsBytes : ")
+	//router.Println(poAsBytes)
+	json.Unmarshal(poAsBytes, &poIndex)								//un stringify it aka JSON.parse()
+	router.Print("poIndex : ")
+	router.Println(poIndex)
+	//router.Println("len(poIndex) : ")
+	//router.Println(len(poIndex))
+	jsonResp = "{"
+	for i,val := range poIndex{
+		router.Println(strconv.Itoa(i) + " - looking at " + val + " for getting sellerName")
+		valueAsBytes, err := stub.GetState(poKey(val))
+		if err != nil {
+			errResp = "{\"Error\":\"Failed to get state for " + val + "\"}"
+			return nil, errors.New(errResp)
+		}
+		//router.Print("valueAsBytes : ")
+		//router.Println(valueAsBytes)
+		json.Unmarshal(valueAsBytes, &valIndex)
+		router.Print("valIndex: ")
+		router.Print(valIndex)
+		if valIndex.SellerName == sellerName{
+			router.Println("Seller found")
+			jsonResp = jsonResp + "\""+ val + "\":" + string(valueAsBytes[:])
+			//router.Println("jsonResp inside if")
+			//router.Println(jsonResp)
+			if i < len(poIndex)-1 {
+				jsonResp = jsonResp + ","
+			}
+		}
+		
+	}
+	
+	jsonResp = jsonResp + "}"
+	router.Println("jsonResp : " + jsonResp)
+	//router.Print("jsonResp in bytes : ")
+	//router.Println([]byte(jsonResp))
+	router.Println("end getPO_bySeller")
+	return []byte(jsonResp), nil											//send it onward
+}
+// ============================================================================================================================
+//  get_AllPO- get details of all PO from chaincode state
+// ============================================================================================================================
+func (t *ManagePO) get_AllPO(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var jsonResp, errResp string
+	var poIndex []string
+	router.Println("start get_AllPO")
+	var err error
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting \" \" as an argument\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	poAsBytes, err := stub.GetState(POIndexStr)
+	if err != nil {
+		return nil, errors.New("Failed to get PO index")
+	}
+	//router.Print("poAsBytes : ")
+	//router.Println(poAsBytes)
+	json.Unmarshal(poAsBytes, &poIndex)								//un stringify it aka JSON.parse()
+	//router.Print("poIndex : ")
+	//router.Println(poIndex)
+	jsonResp = "{"
+	for i,val := range poIndex{
+		router.Println(strconv.Itoa(i) + " - looking at " + val + " for all PO")
+		valueAsBytes, err := stub.GetState(poKey(val))
+		if err != nil {
+			errResp = "{\"Error\":\"Failed to get state for " + val + "\"}"
+			return nil, errors.New(errResp)
+		}
+		//router.Print("valueAsBytes : ")
+		//router.Println(valueAsBytes)
+		jsonResp = jsonResp + "\""+ val + "\":" + string(valueAsBytes[:])
+		if i < len(poIndex)-1 {
+			jsonResp = jsonResp + ","
+		}
+	}
+	//router.Println("len(poIndex) : ")
+	//router.Println(len(poIndex))
+	jsonResp = jsonResp + "}"
+	//router.Println("jsonResp : " + jsonResp)
+	//router.Print("jsonResp in bytes : ")
+	//router.Println([]byte(jsonResp))
+	router.Println("end get_AllPO")
+	return []byte(jsonResp), nil
+											//send it onward
+}
+// ============================================================================================================================
+// Delete - remove a PO from chain
+// ============================================================================================================================
+func (t *ManagePO) delete_po(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting 'transId' as an argument\", \"code\" : \"503\"}"
+		err := stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	// set transId
+	transId := args[0]
+	recordAsBytes, err := stub.GetState(poKey(transId))
+	if err != nil {
+		errMsg := "{ \"message\" : " + router.JSONString("Failed to get state for " + transId) + ", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	err = stub.DelState(poKey(transId))													//remove the PO from chaincode
+	if err != nil {
+		errMsg := "{ \"message\" : \"Failed to delete state\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+
+	//get the PO index
+	poAsBytes, err := stub.GetState(POIndexStr)
+	if err != nil {
+		errMsg := "{ \"message\" : \"Failed to get PO index\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	//router.Println("poAsBytes in delete po")
+	//router.Println(poAsBytes);
+	var poIndex []string
+	json.Unmarshal(poAsBytes, &poIndex)								//un stringify it aka JSON.parse()
+	//router.Println("poIndex in delete po")
+	//router.Println(poIndex);
+	//remove marble from index
+	for i,val := range poIndex{
+		router.Println(strconv.Itoa(i) + " - looking at " + val + " for " + transId)
+		if val == transId{															//find the correct PO
+			router.Println("found PO with matching transId")
+			poIndex = append(poIndex[:i], poIndex[i+1:]...)			//remove it
+			for x:= range poIndex{											//debug prints...
+				router.Println(strconv.Itoa(x) + " - " + poIndex[x])
+			}
+			break
+		}
+	}
+	jsonAsBytes, _ := json.Marshal(poIndex)									//save new index
+	err = stub.PutState(POIndexStr, jsonAsBytes)
+
+	err = router.Emit(stub, events.Event{Type: events.PODeleted, EntityID: transId, Message: "PO deleted succcessfully"},
+		recordAsBytes, nil)
+	if err != nil {
+		return nil, err
+	} 
+
+	router.Println("PO deleted succcessfully")
+	return nil, nil
+}
+// ============================================================================================================================
+// Write - update PO into chaincode state
+// ============================================================================================================================
+func (t *ManagePO) update_po(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var err error
+	router.Println("Updating PO")
+	args, expectedVersion := router.ExpectedVersion(args, 13)
+	if len(args) != 13 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting 13, or 14 with the expected version\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	// set transId
+	transId := args[0]
+	poAsBytes, err := stub.GetState(poKey(transId))									//get the PO for the specified transId from chaincode state
+	if err != nil {
+		errMsg := "{ \"message\" : " + router.JSONString("Failed to get state for " + transId) + ", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	res := PO{}
+	json.Unmarshal(poAsBytes, &res)
+	previous := res
+	if res.TransID == transId{
+		router.Println("PO found with transId : " + transId)
+		err = router.CheckVersion(transId, res.Version, expectedVersion)
+		if err != nil {
+			err = router.VersionEvent(stub, err)
+			if err != nil {
+				return nil, err
+			}
+			return nil, nil
+		}
+		if args[4] != res.PO_date {
+			err = router.CheckNotPast(stub, "po_date", args[4])
+			if err != nil {
+				err = router.ErrorEvent(stub, err)
+				if err != nil {
+					return nil, err
+				}
+				return nil, nil
+			}
+		}
+
+		res.SellerName = args[1]
+		res.BuyerName = args[2]
+		res.ExpectedDeliveryDate = args[3]
+		res.PO_date = args[4]
+		res.PO_status = args[5]
+		res.ItemId = args[6]
+		res.Item_name = args[7]
+		res.Item_quantity = args[8]
+		res.Price = args[9]
+		res.Buyer_sign = args[10]
+		res.Seller_sign = args[11]
+		res.Seller_Remarks = args[12]
+		res.Version++
+		res.LastModifiedTxID = stub.GetTxID()
+		res.UpdatedAt, res.UpdatedBy, err = router.TxStamp(stub)
+		if err != nil {
+			return nil, err
+		}
+	}else{
+		errMsg := "{ \"message\" : " + router.JSONString(transId + " Not Found.") + ", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	
+	//build the PO json string manually
+	po_json := 	`{`+
+		`"transId": "` + res.TransID + `" , `+
+		`"sellerName": "` + res.SellerName + `" , `+
+		`"buyerName": "` + res.BuyerName + `" , `+
+		`"expectedDeliveryDate": "` + res.ExpectedDeliveryDate + `" , `+ 
+		`"po_date": "` + res.PO_date + `" , `+ 
+		`"po_status": "` + res.PO_status + `" , `+ 
+		`"item_id": "` + res.ItemId + `" , `+ 
+		`"item_name": "` + res.Item_name + `" , `+ 
+		`"item_quantity": "` +  res.Item_quantity + `", `+ 
+		`"price": "` + res.Price + `" , `+ 
+		`"buyer_sign": "` + res.Buyer_sign + `" , `+ 
+		`"seller_sign": "` + res.Seller_sign + `" , `+ 
+		`"seller_remarks": "` +  res.Seller_Remarks + `" , `+ 
+		`"version": ` + strconv.Itoa(res.Version) + ` , `+ 
+		`"lastModifiedTxId": "` + res.LastModifiedTxID + `" , `+ 
+		`"createdAt": "` + res.CreatedAt + `" , `+
+		`"createdBy": ` + router.JSONString(res.CreatedBy) + ` , `+
+		`"updatedAt": "` + res.UpdatedAt + `" , `+
+		`"updatedBy": ` + router.JSONString(res.UpdatedBy) + ` `+
+	`}`
+	err = stub.PutState(poKey(transId), []byte(po_json))									//store PO with id as key
+	if err != nil {
+		return nil, err
+	}
+
+	eventType := events.POUpdated
+	if router.Signed(previous.Buyer_sign, res.Buyer_sign) || router.Signed(previous.Seller_sign, res.Seller_sign) {
+		eventType = events.POSigned
+	}
+	err = router.Emit(stub, events.Event{Type: eventType, EntityID: transId, Status: res.PO_status, Message: "PO updated succcessfully"},
+		poAsBytes, po_json)
+	if err != nil {
+		return nil, err
+	} 
+
+	router.Println("PO updated succcessfully")
+	return nil, nil
+}
+// ============================================================================================================================
+// create PO - create a new PO, store into chaincode state
+// ============================================================================================================================
+func (t *ManagePO) create_po(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var err error
+	if len(args) != 12 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting 12\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	router.Println("start create_po")
+	/*if len(args[0]) <= 0 {
+		return nil, errors.New("1st argument must be a non-empty string")
+	}
+	if len(args[1]) <= 0 {
+		return nil, errors.New("2nd argument must be a non-empty string")
+	}
+	if len(args[2]) <= 0 {
+		return nil, errors.New("3rd argument must be a non-empty string")
+	}
+	if len(args[3]) <= 0 {
+		return nil, errors.New("4th argument must be a non-empty string")
+	}
+	if len(args[4]) <= 0 {
+		return nil, errors.New("5th argument must be a non-empty string")
+	}
+	if len(args[5]) <= 0 {
+		return nil, errors.New("6th argument must be a non-empty string")
+	}
+	if len(args[6]) <= 0 {
+		return nil, errors.New("7th argument must be a non-empty string")
+	}
+	if len(args[7]) <= 0 {
+		return nil, errors.New("8th argument must be a non-empty string")
+	}
+	if len(args[8]) <= 0 {
+		return nil, errors.New("9th argument must be a non-empty string")
+		}*/
+		transId := args[0]
+		if transId == "" {
+			transId, err = router.NewID(stub, "PO-", poKey)			//no ID given, one is derived from the transaction ID
+			if err != nil {
+				return nil, err
+			}
+		}
+		sellerName := args[1]
+		buyerName := args[2]
+		expectedDeliveryDate := args[3]
+		po_date := args[4]
+		po_status := args[5]
+		item_id := args[6]
+		item_name := args[7]
+		item_quantity := args[8]
+		price := args[9]
+		buyer_sign := args[10]
+		seller_sign := args[11]
+		seller_remarks := "NA"
+		createdAt, createdBy, err := router.TxStamp(stub)
+		if err != nil {
+			return nil, err
+		}
+		po_date, err = router.RecordDate(stub, "po_date", po_date)
+		if err != nil {
+			err = router.ErrorEvent(stub, err)
+			if err != nil {
+				return nil, err
+			}
+			return nil, nil
+		}
+		err = router.CheckNotPast(stub, "expectedDeliveryDate", expectedDeliveryDate)
+		if err != nil {
+			err = router.ErrorEvent(stub, err)
+			if err != nil {
+				return nil, err
+			}
+			return nil, nil
+		}
+
+		poAsBytes, err := stub.GetState(poKey(transId))
+		if err != nil {
+			return nil, errors.New("Failed to get PO transID")
+		}
+	
+		res := PO{}
+		json.Unmarshal(poAsBytes, &res)
+		if res.TransID == transId{
+			errMsg := "{ \"message\" : \"This PO arleady exists\", \"code\" : \"503\"}"
+			err := stub.SetEvent("errEvent", []byte(errMsg))
+			if err != nil {
+				return nil, err
+			} 
+		return nil, nil				//all stop a PO by this name exists
+	}
+	
+	//build the PO json string manually
+	po_json := 	`{`+
+		`"transId": "` + transId + `" , `+
+		`"sellerName": "` + sellerName + `" , `+
+		`"buyerName": "` + buyerName + `" , `+
+		`"expectedDeliveryDate": "` + expectedDeliveryDate + `" , `+ 
+		`"po_date": "` + po_date + `" , `+ 
+		`"po_status": "` + po_status + `" , `+ 
+		`"item_id": "` + item_id + `" , `+ 
+		`"item_name": "` + item_name + `" , `+ 
+		`"item_quantity": "` +  item_quantity + `", `+ 
+		`"price": "` + price + `" , `+ 
+		`"buyer_sign": "` + buyer_sign + `" , `+ 
+		`"seller_sign": "` + seller_sign + `" , `+ 
+		`"seller_remarks": "` +  seller_remarks + `" , `+ 
+		`"version": 1 , `+ 
+		`"lastModifiedTxId": "` + stub.GetTxID() + `" , `+ 
+		`"createdAt": "` + createdAt + `" , `+
+		`"createdBy": ` + router.JSONString(createdBy) + ` , `+
+		`"updatedAt": "` + createdAt + `" , `+
+		`"updatedBy": ` + router.JSONString(createdBy) + ` `+
+	`}`
+	
+	router.Print("po_json in bytes array: ")
+	router.Println([]byte(po_json))
+	err = stub.PutState(poKey(transId), []byte(po_json))									//store PO with transId as key
+	if err != nil {
+		return nil, err
+	}
+	//get the PO index
+	poIndexAsBytes, err := stub.GetState(POIndexStr)
+	if err != nil {
+		return nil, errors.New("Failed to get PO index")
+	}
+	var poIndex []string
+	//router.Print("poIndexAsBytes: ")
+	//router.Println(poIndexAsBytes)
+	
+	json.Unmarshal(poIndexAsBytes, &poIndex)							//un stringify it aka JSON.parse()
+	//router.Print("poIndex after unmarshal..before append: ")
+	//router.Println(poIndex)
+	//append
+	poIndex = append(poIndex, transId)									//add PO transID to index list
+	//router.Println("! PO index after appending transId: ", poIndex)
+	jsonAsBytes, _ := json.Marshal(poIndex)
+	//router.Print("jsonAsBytes: ")
+	//router.Println(jsonAsBytes)
+	err = stub.PutState(POIndexStr, jsonAsBytes)						//store name of PO
+	if err != nil {
+		return nil, err
+	}
+
+	err = router.Emit(stub, events.Event{Type: events.POCreated, EntityID: transId, Status: po_status, Message: "PO created succcessfully"},
+		nil, po_json)
+	if err != nil {
+		return nil, err
+	} 
+
+	router.Println("end create_po")
+	return []byte(transId), nil
+}
@@ -0,0 +1,212 @@
+package po
+
+import (
+"strings"
+"testing"
+"time"
+"encoding/json"
+
+"github.com/wipro-blockchain/TF-v1/internal/mockstub"
+"github.com/wipro-blockchain/TF-v1/internal/mocktest"
+"github.com/wipro-blockchain/TF-v1/internal/router"
+)
+
+// newManagePO - the PO chaincode on an initialized in-memory ledger
+func newManagePO(t *testing.T) (*router.Router, *mockstub.MockStub) {
+	r := router.New("ManagePO")
+	r.Register("po", New())
+	stub := mockstub.New("managePO")
+	if _, err := stub.Init(r, " "); err != nil || stub.Failed() {
+		t.Fatalf("init failed: %v %s", err, stub.LastEvent().Payload)
+	}
+	return r, stub
+}
+
+// poArgs - the create_po arguments of a PO between Buyerco and Sellerco
+func poArgs(transId string) []string {
+	return []string{transId, "Sellerco", "Buyerco", "2024-03-01", "2024-01-15", "Created", "ITM-1", "Rice", "100", "25",
+		"true", "false"}
+}
+
+func getPO(t *testing.T, r *router.Router, stub *mockstub.MockStub, transId string) PO {
+	res := PO{}
+	if err := json.Unmarshal(mocktest.Succeed(t, stub, r, "getPO_byID", transId), &res); err != nil {
+		t.Fatalf("getPO_byID(%s): %s", transId, err)
+	}
+	return res
+}
+
+func TestInit(t *testing.T) {
+	r, stub := newManagePO(t)
+	if stub.Message() != "ManagePO chaincode is deployed successfully." {
+		t.Errorf("init event: %s", stub.LastEvent().Payload)
+	}
+	if string(stub.State[POIndexStr]) != "null" {
+		t.Errorf("PO index after init: %s", stub.State[POIndexStr])
+	}
+	stub.Init(r)
+	if !stub.Failed() || !strings.Contains(stub.Message(), "Incorrect number of arguments") {
+		t.Errorf("init without arguments: %s", stub.LastEvent().Payload)
+	}
+}
+
+func TestArgumentCounts(t *testing.T) {
+	r, stub := newManagePO(t)
+	for _, function := range []string{"create_po", "update_po", "delete_po", "getPO_byID", "getPO_byBuyer", "getPO_bySeller", "get_AllPO"} {
+		mocktest.Reject(t, stub, r, function, "Incorrect number of arguments")
+	}
+}
+
+func TestCreatePO(t *testing.T) {
+	r, stub := newManagePO(t)
+	buyer, err := mockstub.Identity("BuyerMSP", "buyer-admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stub.Creator = buyer
+	mocktest.Succeed(t, stub, r, "create_po", poArgs("PO1")...)
+	if stub.Message() != "PO created succcessfully" {
+		t.Errorf("create_po event: %s", stub.LastEvent().Payload)
+	}
+	txID, createdAt := stub.TxID, stub.TxTime.Format(time.RFC3339)
+	keys := []string{}
+	for _, w := range stub.Writes {
+		keys = append(keys, w.Key)
+	}
+	if strings.Join(keys, ",") != poKey("PO1")+","+POIndexStr {
+		t.Errorf("create_po writes: %v", keys)
+	}
+	res := getPO(t, r, stub, "PO1")
+	expected := PO{TransID: "PO1", SellerName: "Sellerco", BuyerName: "Buyerco", ExpectedDeliveryDate: "2024-03-01",
+		PO_date: "2024-01-15", PO_status: "Created", ItemId: "ITM-1", Item_name: "Rice", Item_quantity: "100", Price: "25",
+		Buyer_sign: "true", Seller_sign: "false", Seller_Remarks: "NA", Version: 1, LastModifiedTxID: txID,
+		CreatedAt: createdAt, CreatedBy: "BuyerMSP:buyer-admin", UpdatedAt: createdAt, UpdatedBy: "BuyerMSP:buyer-admin"}
+	if res != expected {
+		t.Errorf("getPO_byID: %+v", res)
+	}
+}
+
+func TestCreatePODuplicate(t *testing.T) {
+	r, stub := newManagePO(t)
+	mocktest.Succeed(t, stub, r, "create_po", poArgs("PO1")...)
+	mocktest.Reject(t, stub, r, "create_po", "This PO arleady exists", poArgs("PO1")...)
+	if len(stub.Writes) != 0 {
+		t.Errorf("duplicate create_po wrote %d keys", len(stub.Writes))
+	}
+}
+
+func TestUpdatePO(t *testing.T) {
+	r, stub := newManagePO(t)
+	mocktest.Succeed(t, stub, r, "create_po", poArgs("PO1")...)
+	args := append(poArgs("PO1"), "Accepted with changes")
+	args[5] = "Accepted"
+	args[11] = "true"
+	mocktest.Succeed(t, stub, r, "update_po", args...)
+	if stub.Message() != "PO updated succcessfully" {
+		t.Errorf("update_po event: %s", stub.LastEvent().Payload)
+	}
+	res := getPO(t, r, stub, "PO1")
+	if res.PO_status != "Accepted" || res.Seller_sign != "true" || res.Seller_Remarks != "Accepted with changes" {
+		t.Errorf("updated PO: %+v", res)
+	}
+}
+
+func TestLedgerTimestamps(t *testing.T) {
+	r, stub := newManagePO(t)
+	buyer, _ := mockstub.Identity("BuyerMSP", "buyer-admin")
+	seller, _ := mockstub.Identity("SellerMSP", "seller-admin")
+	stub.Creator = buyer
+	mocktest.Succeed(t, stub, r, "create_po", poArgs("PO1")...)
+	created := getPO(t, r, stub, "PO1")
+	stub.Creator = seller
+	stub.TxTime = stub.TxTime.Add(48 * time.Hour)
+	mocktest.Reject(t, stub, r, "update_po", "po_date 2023-01-01 is in the past, the ledger date is 2024-01-03",
+		`{"transId": "PO1", "po_status": "Accepted", "seller_sign": "true", "po_date": "2023-01-01"}`)
+	mocktest.Succeed(t, stub, r, "update_po", `{"transId": "PO1", "po_status": "Accepted", "seller_sign": "true"}`)
+	updatedAt := stub.TxTime.Format(time.RFC3339)
+	res := getPO(t, r, stub, "PO1")
+	if res.CreatedAt != created.CreatedAt || res.CreatedBy != "BuyerMSP:buyer-admin" || res.UpdatedAt != updatedAt ||
+		res.UpdatedBy != "SellerMSP:seller-admin" || res.UpdatedAt <= res.CreatedAt {
+		t.Errorf("timestamps after update: %+v", res)
+	}
+
+	//the business dates a caller supplies are checked against the ledger time
+	args := poArgs("PO2")
+	args[3] = "2023-12-31"
+	mocktest.Reject(t, stub, r, "create_po", "expectedDeliveryDate 2023-12-31 is in the past, the ledger date is 2024-01-03", args...)
+	args[3] = "next week"
+	mocktest.Reject(t, stub, r, "create_po", "expectedDeliveryDate must be a date like 2024-03-01, not next week", args...)
+	args[3] = stub.TxTime.Format("2006-01-02")
+	args[4] = "2023-12-31"
+	mocktest.Reject(t, stub, r, "create_po", "po_date 2023-12-31 is in the past, the ledger date is 2024-01-03", args...)
+	args[4] = ""
+	mocktest.Succeed(t, stub, r, "create_po", args...)
+	if res = getPO(t, r, stub, "PO2"); res.PO_date != "2024-01-03" {
+		t.Errorf("po_date of a PO created without one: %q", res.PO_date)
+	}
+
+	//the caller is escaped in the stored record
+	stub.Creator, _ = mockstub.Identity("BuyerMSP", `buyer "admin"`)
+	args[0] = "PO3"
+	mocktest.Succeed(t, stub, r, "create_po", args...)
+	if res = getPO(t, r, stub, "PO3"); res.CreatedBy != `BuyerMSP:buyer "admin"` {
+		t.Errorf("createdBy of a caller with a quote: %q", res.CreatedBy)
+	}
+}
+
+func TestUpdatePOMissing(t *testing.T) {
+	r, stub := newManagePO(t)
+	mocktest.Reject(t, stub, r, "update_po", "PO9 Not Found.", append(poArgs("PO9"), "NA")...)
+}
+
+func TestDeletePO(t *testing.T) {
+	r, stub := newManagePO(t)
+	mocktest.Succeed(t, stub, r, "create_po", poArgs("PO1")...)
+	mocktest.Succeed(t, stub, r, "create_po", poArgs("PO2")...)
+	mocktest.Succeed(t, stub, r, "delete_po", "PO1")
+	if stub.Message() != "PO deleted succcessfully" {
+		t.Errorf("delete_po event: %s", stub.LastEvent().Payload)
+	}
+	if valAsBytes := mocktest.Succeed(t, stub, r, "getPO_byID", "PO1"); len(valAsBytes) != 0 {
+		t.Errorf("deleted PO still readable: %s", valAsBytes)
+	}
+	if string(stub.State[POIndexStr]) != `["PO2"]` {
+		t.Errorf("PO index after delete: %s", stub.State[POIndexStr])
+	}
+}
+
+func TestGetPOMissing(t *testing.T) {
+	r, stub := newManagePO(t)
+	if valAsBytes := mocktest.Succeed(t, stub, r, "getPO_byID", "PO9"); len(valAsBytes) != 0 {
+		t.Errorf("getPO_byID of a missing PO: %s", valAsBytes)
+	}
+}
+
+func TestGetPOLists(t *testing.T) {
+	r, stub := newManagePO(t)
+	mocktest.Succeed(t, stub, r, "create_po", poArgs("PO1")...)
+	other := poArgs("PO2")
+	other[1], other[2] = "Othersell", "Otherbuy"
+	mocktest.Succeed(t, stub, r, "create_po", other...)
+
+	byID := map[string]PO{}
+	if err := json.Unmarshal(mocktest.Succeed(t, stub, r, "get_AllPO", " "), &byID); err != nil || len(byID) != 2 {
+		t.Errorf("get_AllPO: %v %v", byID, err)
+	}
+	byBuyer := string(mocktest.Succeed(t, stub, r, "getPO_byBuyer", "Otherbuy"))
+	if !strings.Contains(byBuyer, `"PO2":`) || strings.Contains(byBuyer, `"PO1":`) {
+		t.Errorf("getPO_byBuyer: %s", byBuyer)
+	}
+	bySeller := string(mocktest.Succeed(t, stub, r, "getPO_bySeller", "Sellerco"))
+	if !strings.Contains(bySeller, `"PO1":`) || strings.Contains(bySeller, `"PO2":`) {
+		t.Errorf("getPO_bySeller: %s", bySeller)
+	}
+	if none := string(mocktest.Succeed(t, stub, r, "getPO_byBuyer", "Nobody")); none != "{}" {
+		t.Errorf("getPO_byBuyer without match: %s", none)
+	}
+}
+
+func TestUnknownFunction(t *testing.T) {
+	r, stub := newManagePO(t)
+	mocktest.Reject(t, stub, r, "create_pos", "Received unknown function invocation")
+}
@@ -0,0 +1,176 @@
+package projector
+
+import (
+"context"
+"database/sql"
+"errors"
+"sort"
+
+tfclient "github.com/wipro-blockchain/TF-v1/client"
+"github.com/wipro-blockchain/TF-v1/internal/contracts"
+)
+
+// Drift is a difference between the projection and the ledger: a record one of them does not hold, or a column whose values
+// differ. A NULL value is nil
+type Drift struct {
+	Table string `json:"table"`
+	ID string `json:"id"`
+	Missing string `json:"missing,omitempty"`		// "projection" or "ledger" when only the other holds the record
+	Column string `json:"column,omitempty"`
+	Projection *string `json:"projection,omitempty"`
+	Ledger *string `json:"ledger,omitempty"`
+}
+
+// ============================================================================================================================
+// String - the drift as one line, e.g. payments PAY1 payment_status: "Created" in the projection, "Paid" on the ledger
+// ============================================================================================================================
+func (d Drift) String() string {
+	if d.Missing != "" {
+		return d.Table + " " + d.ID + ": missing from the " + d.Missing
+	}
+	return d.Table + " " + d.ID + " " + d.Column + ": " + quoted(d.Projection) + " in the projection, " + quoted(d.Ledger) + " on the ledger"
+}
+// ============================================================================================================================
+// Drift - compare every row of the projection with the record on the ledger, in table and ID order. The projection should be
+// up to date with the log, the records the ledger changed since are reported as drift too
+// ============================================================================================================================
+func (p *Projector) Drift(ctx context.Context) ([]Drift, error) {
+	if p.Ledger == nil {
+		return nil, errors.New("projector: drift is checked against a ledger, none is set")
+	}
+	var drift []Drift
+	for _, t := range Tables {
+		projected, err := p.Rows(ctx, t)
+		if err != nil {
+			return nil, err
+		}
+		onLedger, err := p.ledgerRows(t)
+		if err != nil {
+			return nil, err
+		}
+		var ids []string
+		for id := range projected {
+			ids = append(ids, id)
+		}
+		for id := range onLedger {
+			if _, found := projected[id]; !found {
+				ids = append(ids, id)
+			}
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			row, inProjection := projected[id]
+			record, inLedger := onLedger[id]
+			switch {
+			case !inProjection:
+				drift = append(drift, Drift{Table: t.Name, ID: id, Missing: "projection"})
+			case !inLedger:
+				drift = append(drift, Drift{Table: t.Name, ID: id, Missing: "ledger"})
+			default:
+				for _, c := range t.Columns {
+					projectedValue, ledgerValue := value(row, c.Name), value(record, c.Name)
+					if quoted(projectedValue) != quoted(ledgerValue) {
+						drift = append(drift, Drift{Table: t.Name, ID: id, Column: c.Name, Projection: projectedValue, Ledger: ledgerValue})
+					}
+				}
+			}
+		}
+	}
+	return drift, nil
+}
+// ============================================================================================================================
+// Repair - make the drifted rows those of the ledger: a record the ledger holds replaces its row, the row of one it does not
+// hold is deleted
+// ============================================================================================================================
+func (p *Projector) Repair(ctx context.Context, drift []Drift) error {
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	repaired := map[string]bool{}
+	for _, d := range drift {
+		t := tableNamed(d.Table)
+		if t == nil || repaired[d.Table + "/" + d.ID] {
+			continue
+		}
+		repaired[d.Table + "/" + d.ID] = true
+		if d.Missing == "ledger" {
+			_, err = tx.ExecContext(ctx, "DELETE FROM " + t.Name + " WHERE " + t.Key + " = " + p.Dialect.placeholders(1), d.ID)
+		}else{
+			err = p.repairRow(ctx, tx, t, d.ID)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+// ============================================================================================================================
+// repairRow - replace a row with the record in the list of the ledger, the fraud entries have no query by ID
+// ============================================================================================================================
+func (p *Projector) repairRow(ctx context.Context, tx *sql.Tx, t *Table, id string) error {
+	onLedger, err := p.ledgerRows(t)
+	if err != nil {
+		return err
+	}
+	row, found := onLedger[id]
+	if !found {
+		return nil
+	}
+	return save(ctx, tx, p.Dialect, t, row)
+}
+// ============================================================================================================================
+// ledgerRows - every record of a table as the ledger holds it, by ID
+// ============================================================================================================================
+func (p *Projector) ledgerRows(t *Table) (map[string]Row, error) {
+	listAsBytes, err := p.Ledger.Evaluate(t.list, " ")
+	if errors.Is(err, tfclient.ErrNotFound) {
+		return map[string]Row{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	records, err := contracts.ListJSON(listAsBytes)
+	if err != nil {
+		return nil, errors.New("projector: malformed " + t.list + " response: " + err.Error())
+	}
+	rows := map[string]Row{}
+	for _, record := range records {
+		row, err := ledgerRow(t, record)
+		if err != nil {
+			return nil, err
+		}
+		rows[row[t.Key]] = row
+	}
+	return rows, nil
+}
+// ============================================================================================================================
+// tableNamed - a table by name, nil when there is none
+// ============================================================================================================================
+func tableNamed(name string) *Table {
+	for _, t := range Tables {
+		if t.Name == name {
+			return t
+		}
+	}
+	return nil
+}
+// ============================================================================================================================
+// value - a column of a row, nil when it is NULL
+// ============================================================================================================================
+func value(row Row, name string) *string {
+	if v, found := row[name]; found {
+		return &v
+	}
+	return nil
+}
+// ============================================================================================================================
+// quoted - a value in quotes, NULL when there is none
+// ============================================================================================================================
+func quoted(v *string) string {
+	if v == nil {
+		return "NULL"
+	}
+	return "\"" + *v + "\""
+}
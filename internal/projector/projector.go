@@ -0,0 +1,438 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements.  See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership.  The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License.  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+// Package projector keeps a relational projection of the trade records in SQLite or Postgres for reporting: a table each
+// for the POs, Agreements, Payments, Shipments and fraud entries, with a column per record field. It applies the changes
+// of the catalog events of the event log in order, each event in one database transaction with the checkpoint of the
+// projection, so a restarted projector carries on where it stopped. A rebuild replays the log from its first event, and
+// the projection can be compared with the ledger to find the records that drifted.
+package projector
+
+import (
+"bytes"
+"context"
+"database/sql"
+"errors"
+"strconv"
+"strings"
+"time"
+"encoding/json"
+
+tfclient "github.com/wipro-blockchain/TF-v1/client"
+"github.com/wipro-blockchain/TF-v1/events"
+"github.com/wipro-blockchain/TF-v1/internal/relay"
+)
+
+// Row is a record as the projection holds it, its column values by column name, a NULL column is left out. A string field
+// is held as is, any other value as its JSON
+type Row map[string]string
+
+// Projector applies the events of a log to the projection in a database
+type Projector struct {
+	DB *sql.DB
+	Dialect Dialect
+	Log *relay.Log
+	Ledger *tfclient.Client							// reads the records an event does not describe and checks drift, none when nil
+	Name string										// names the checkpoint, "trade" when empty
+	PollInterval time.Duration						// how often a following projector reads the log, 1s when 0
+	Logf func(format string, args ...interface{})	// reports the progress when set
+}
+
+// recordEvents are the events whose changes are those of the record of their entity, the others change records the
+// projection does not hold, e.g. the escrow, or change none
+var recordEvents = []string{
+	events.POCreated, events.POUpdated, events.POSigned, events.PODeleted,
+	events.AgreementCreated, events.AgreementUpdated, events.AgreementSigned, events.AgreementDeleted,
+	events.AgreementClearanceUpdated, events.AgreementShipped, events.FraudListed,
+	events.PaymentCreated, events.PaymentUpdated, events.PaymentSettled, events.PaymentDeleted, events.PaymentExported,
+	events.ShipmentCreated, events.ShipmentUpdated, events.ShipmentDeleted, events.ShipmentTracked, events.ShipmentDelivered,
+	events.ShipmentReleased,
+}
+
+// ============================================================================================================================
+// Init - create the tables that do not exist yet
+// ============================================================================================================================
+func (p *Projector) Init(ctx context.Context) error {
+	for _, statement := range Schema() {
+		if _, err := p.DB.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+// ============================================================================================================================
+// Checkpoint - the sequence number of the last event applied, 0 before the first
+// ============================================================================================================================
+func (p *Projector) Checkpoint(ctx context.Context) (uint64, error) {
+	var seq int64
+	err := p.DB.QueryRowContext(ctx, "SELECT seq FROM " + checkpointTable + " WHERE name = " + p.Dialect.placeholders(1),
+		p.name()).Scan(&seq)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return uint64(seq), err
+}
+// ============================================================================================================================
+// Run - apply the events of the log after the checkpoint. With follow it keeps reading the log until ctx is done, without it
+// returns once the projection is up to date with the log
+// ============================================================================================================================
+func (p *Projector) Run(ctx context.Context, follow bool) error {
+	checkpoint, err := p.Checkpoint(ctx)
+	if err != nil {
+		return err
+	}
+	for {
+		records, err := p.Log.Read(checkpoint)
+		if err != nil {
+			return err
+		}
+		for _, record := range records {
+			if err = p.project(ctx, record); err != nil {
+				return errors.New("projector: event " + strconv.FormatUint(record.Seq, 10) + " " + record.Name + ": " + err.Error())
+			}
+			checkpoint = record.Seq
+		}
+		if len(records) > 0 {
+			p.logf("projected up to event %d", checkpoint)
+		}
+		if !follow {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(p.pollInterval()):
+		}
+	}
+}
+// ============================================================================================================================
+// Rebuild - empty the tables and apply the log again from its first event, the log must hold every event since genesis
+// ============================================================================================================================
+func (p *Projector) Rebuild(ctx context.Context) error {
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, t := range Tables {
+		if _, err = tx.ExecContext(ctx, "DELETE FROM " + t.Name); err != nil {
+			return err
+		}
+	}
+	if _, err = tx.ExecContext(ctx, "DELETE FROM " + checkpointTable + " WHERE name = " + p.Dialect.placeholders(1), p.name()); err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+	p.logf("rebuilding the projection from the first event")
+	return p.Run(ctx, false)
+}
+// ============================================================================================================================
+// project - apply an event of the log and move the checkpoint past it in one transaction, a batch applies the event of each
+// of its steps
+// ============================================================================================================================
+func (p *Projector) project(ctx context.Context, record relay.Record) error {
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	var applied []events.Event
+	if _, found := events.Lookup(record.Name); found {
+		event := events.Event{}
+		if err = json.Unmarshal(record.Payload, &event); err != nil {
+			return err
+		}
+		applied = append(applied, event)
+		if record.Name == events.BatchExecuted {
+			applied = nil
+			stepsAsBytes, _ := json.Marshal(event.Data["steps"])
+			if err = json.Unmarshal(stepsAsBytes, &applied); err != nil {
+				return errors.New("malformed batch steps: " + err.Error())
+			}
+		}
+	}
+	for _, event := range applied {
+		if err = p.apply(ctx, tx, event); err != nil {
+			return err
+		}
+	}
+	if _, err = tx.ExecContext(ctx, "DELETE FROM " + checkpointTable + " WHERE name = " + p.Dialect.placeholders(1), p.name()); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, "INSERT INTO " + checkpointTable + " (name, seq) VALUES (" + p.Dialect.placeholders(2) + ")",
+		p.name(), int64(record.Seq))
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+// ============================================================================================================================
+// apply - apply one catalog event. The changes of a record event are applied to its row, unless the row is already at the
+// version of the event, e.g. an event replayed by execute_once. With a Ledger, the records the transaction changed through
+// another chaincode, whose events Fabric does not keep, are read again: the Agreement of a Shipment, the Payment of an
+// escrow and the Payments of a bank notification
+// ============================================================================================================================
+func (p *Projector) apply(ctx context.Context, tx *sql.Tx, event events.Event) error {
+	t := TableOf(event.EntityType)
+	if t != nil && contains(recordEvents, event.Type) {
+		if err := p.applyChanges(ctx, tx, t, event); err != nil {
+			return err
+		}
+	}
+	if p.Ledger == nil {
+		return nil
+	}
+	switch event.EntityType {
+	case "shipment":
+		shipment, found, err := load(ctx, tx, p.Dialect, TableOf("shipment"), event.EntityID)
+		if err != nil || !found || shipment["agreement_id"] == "" {
+			return err
+		}
+		return p.refresh(ctx, tx, TableOf("agreement"), shipment["agreement_id"])
+	case "escrow":
+		return p.refresh(ctx, tx, TableOf("payment"), event.EntityID)
+	case "notification":
+		paymentIds, _ := event.Data["paymentIds"].([]interface{})
+		for _, paymentId := range paymentIds {
+			id, _ := paymentId.(string)
+			if err := p.refresh(ctx, tx, TableOf("payment"), id); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+// ============================================================================================================================
+// applyChanges - apply the changed fields of an event to the row of its record, with the version, transaction, time and
+// caller of the event. A created record is stamped as created by it, a deleted one loses its row
+// ============================================================================================================================
+func (p *Projector) applyChanges(ctx context.Context, tx *sql.Tx, t *Table, event events.Event) error {
+	row, found, err := load(ctx, tx, p.Dialect, t, event.EntityID)
+	if err != nil {
+		return err
+	}
+	if event.Type == t.Entity + ".deleted" {
+		if !found {
+			return nil
+		}
+		_, err = tx.ExecContext(ctx, "DELETE FROM " + t.Name + " WHERE " + t.Key + " = " + p.Dialect.placeholders(1), event.EntityID)
+		return err
+	}
+	if found && event.Version > 0 && row["version"] != "" {
+		if version, _ := strconv.Atoi(row["version"]); version >= event.Version {
+			return nil										//applied already
+		}
+	}
+	if !found {
+		row = Row{t.Key: event.EntityID}
+	}
+	for _, change := range event.Changes {
+		c := t.column(change.Field)
+		if c == nil {
+			continue
+		}
+		if value, isNull := columnValue(change.To); isNull {
+			delete(row, c.Name)
+		}else{
+			row[c.Name] = value
+		}
+	}
+	stamps := map[string]string{"last_modified_tx_id": event.TxID, "updated_at": event.Timestamp, "updated_by": event.Actor}
+	if event.Version > 0 {
+		stamps["version"] = strconv.Itoa(event.Version)
+	}
+	if !found {
+		stamps["created_at"], stamps["created_by"] = event.Timestamp, event.Actor
+	}
+	for name, value := range stamps {
+		for _, c := range t.Columns {
+			if c.Name == name {
+				row[name] = value
+			}
+		}
+	}
+	return save(ctx, tx, p.Dialect, t, row)
+}
+// ============================================================================================================================
+// refresh - replace the row of a record with the record as the ledger holds it, a record the ledger does not hold is left
+// ============================================================================================================================
+func (p *Projector) refresh(ctx context.Context, tx *sql.Tx, t *Table, id string) error {
+	if id == "" || t.get == "" {
+		return nil
+	}
+	recordAsBytes, err := p.Ledger.Evaluate(t.get, id)
+	if errors.Is(err, tfclient.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	row, err := ledgerRow(t, recordAsBytes)
+	if err != nil {
+		return err
+	}
+	return save(ctx, tx, p.Dialect, t, row)
+}
+// ============================================================================================================================
+// Rows - every row of a table, by ID
+// ============================================================================================================================
+func (p *Projector) Rows(ctx context.Context, t *Table) (map[string]Row, error) {
+	names := t.names()
+	rows, err := p.DB.QueryContext(ctx, "SELECT " + strings.Join(names, ", ") + " FROM " + t.Name + " ORDER BY " + t.Key)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	found := map[string]Row{}
+	for rows.Next() {
+		row, err := scan(rows, names)
+		if err != nil {
+			return nil, err
+		}
+		found[row[t.Key]] = row
+	}
+	return found, rows.Err()
+}
+// ============================================================================================================================
+// load - the row of a record, found is false when it has none
+// ============================================================================================================================
+func load(ctx context.Context, tx *sql.Tx, d Dialect, t *Table, id string) (Row, bool, error) {
+	names := t.names()
+	rows, err := tx.QueryContext(ctx, "SELECT " + strings.Join(names, ", ") + " FROM " + t.Name + " WHERE " + t.Key + " = " + d.placeholders(1), id)
+	if err != nil {
+		return nil, false, err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return nil, false, rows.Err()
+	}
+	row, err := scan(rows, names)
+	return row, err == nil, err
+}
+// ============================================================================================================================
+// scan - the row at the cursor, a NULL column is left out
+// ============================================================================================================================
+func scan(rows *sql.Rows, names []string) (Row, error) {
+	values := make([]sql.NullString, len(names))
+	targets := make([]interface{}, len(names))
+	for i := range values {
+		targets[i] = &values[i]
+	}
+	if err := rows.Scan(targets...); err != nil {
+		return nil, err
+	}
+	row := Row{}
+	for i, value := range values {
+		if value.Valid {
+			row[names[i]] = value.String
+		}
+	}
+	return row, nil
+}
+// ============================================================================================================================
+// save - replace the row of a record
+// ============================================================================================================================
+func save(ctx context.Context, tx *sql.Tx, d Dialect, t *Table, row Row) error {
+	_, err := tx.ExecContext(ctx, "DELETE FROM " + t.Name + " WHERE " + t.Key + " = " + d.placeholders(1), row[t.Key])
+	if err != nil {
+		return err
+	}
+	var names []string
+	var values []interface{}
+	for _, c := range t.Columns {
+		value, found := row[c.Name]
+		if !found {
+			continue
+		}
+		names = append(names, c.Name)
+		if number, err := strconv.ParseInt(value, 10, 64); c.Integer && err == nil {
+			values = append(values, number)
+		}else{
+			values = append(values, value)
+		}
+	}
+	_, err = tx.ExecContext(ctx, "INSERT INTO " + t.Name + " (" + strings.Join(names, ", ") + ") VALUES (" + d.placeholders(len(names)) + ")", values...)
+	return err
+}
+// ============================================================================================================================
+// ledgerRow - the row of a record as the ledger returns it
+// ============================================================================================================================
+func ledgerRow(t *Table, recordAsBytes []byte) (Row, error) {
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(recordAsBytes, &fields); err != nil {
+		return nil, errors.New("malformed " + t.Entity + " record: " + err.Error())
+	}
+	row := Row{}
+	for _, c := range t.Columns {
+		if value, isNull := columnValue(fields[c.Field]); !isNull {
+			row[c.Name] = value
+		}
+	}
+	return row, nil
+}
+// ============================================================================================================================
+// columnValue - the column value of a JSON field value, a string as is and any other value as compact JSON
+// ============================================================================================================================
+func columnValue(value json.RawMessage) (string, bool) {
+	if len(value) == 0 || string(value) == "null" {
+		return "", true
+	}
+	var text string
+	if json.Unmarshal(value, &text) == nil {
+		return text, false
+	}
+	compacted := bytes.Buffer{}
+	if json.Compact(&compacted, value) != nil {
+		return string(value), false
+	}
+	return compacted.String(), false
+}
+// ============================================================================================================================
+// contains - whether a list holds a value
+// ============================================================================================================================
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+// ============================================================================================================================
+// name, pollInterval, logf - the settings, or their defaults
+// ============================================================================================================================
+func (p *Projector) name() string {
+	if p.Name == "" {
+		return "trade"
+	}
+	return p.Name
+}
+func (p *Projector) pollInterval() time.Duration {
+	if p.PollInterval == 0 {
+		return time.Second
+	}
+	return p.PollInterval
+}
+func (p *Projector) logf(format string, args ...interface{}) {
+	if p.Logf != nil {
+		p.Logf(format, args...)
+	}
+}
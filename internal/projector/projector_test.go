@@ -0,0 +1,427 @@
+package projector_test
+
+import (
+"context"
+"database/sql"
+"database/sql/driver"
+"errors"
+"fmt"
+"io"
+"os"
+"regexp"
+"sort"
+"strings"
+"sync"
+"testing"
+"encoding/json"
+
+tfclient "github.com/wipro-blockchain/TF-v1/client"
+"github.com/wipro-blockchain/TF-v1/events"
+"github.com/wipro-blockchain/TF-v1/internal/projector"
+"github.com/wipro-blockchain/TF-v1/internal/relay"
+"github.com/wipro-blockchain/TF-v1/internal/router"
+"github.com/wipro-blockchain/TF-v1/internal/simulator"
+)
+
+// memoryDriver is a database/sql driver keeping its tables in memory, it runs only the statements the projector sends:
+// CREATE TABLE, INSERT, DELETE with or without an ID, and SELECT with an ID or in key order. Placeholders must be those of
+// its dialect, ? or $1
+type memoryDriver struct {
+	numbered bool
+	mu sync.Mutex
+	databases map[string]*memoryDB
+}
+
+type memoryDB struct {
+	tables map[string][]map[string]driver.Value
+}
+
+type memoryConn struct {
+	driver *memoryDriver
+	db *memoryDB
+	snapshot map[string][]map[string]driver.Value	// the tables when the transaction began, nil outside one
+}
+
+type memoryStmt struct {
+	conn *memoryConn
+	query string
+}
+
+type memoryRows struct {
+	columns []string
+	rows [][]driver.Value
+}
+
+var statements = []*regexp.Regexp{
+	regexp.MustCompile(`^CREATE TABLE IF NOT EXISTS (\w+) \(.*\)$`),
+	regexp.MustCompile(`^INSERT INTO (\w+) \(([\w, ]+)\) VALUES \(([?$\d, ]+)\)$`),
+	regexp.MustCompile(`^DELETE FROM (\w+)(?: WHERE (\w+) = ([?$\d]+))?$`),
+	regexp.MustCompile(`^SELECT ([\w, ]+) FROM (\w+)(?: WHERE (\w+) = ([?$\d]+))?(?: ORDER BY (\w+))?$`),
+}
+
+func init() {
+	sql.Register("memory", &memoryDriver{databases: map[string]*memoryDB{}})
+	sql.Register("memory-numbered", &memoryDriver{numbered: true, databases: map[string]*memoryDB{}})
+}
+
+func (d *memoryDriver) Open(name string) (driver.Conn, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.databases[name] == nil {
+		d.databases[name] = &memoryDB{tables: map[string][]map[string]driver.Value{}}
+	}
+	return &memoryConn{driver: d, db: d.databases[name]}, nil
+}
+
+func (c *memoryConn) Prepare(query string) (driver.Stmt, error) { return &memoryStmt{conn: c, query: query}, nil }
+func (c *memoryConn) Close() error { return nil }
+func (c *memoryConn) Begin() (driver.Tx, error) {
+	c.driver.mu.Lock()
+	defer c.driver.mu.Unlock()
+	c.snapshot = map[string][]map[string]driver.Value{}
+	for name, rows := range c.db.tables {
+		c.snapshot[name] = append([]map[string]driver.Value{}, rows...)
+	}
+	return c, nil
+}
+func (c *memoryConn) Commit() error { c.snapshot = nil; return nil }
+func (c *memoryConn) Rollback() error {
+	c.driver.mu.Lock()
+	defer c.driver.mu.Unlock()
+	if c.snapshot != nil {
+		c.db.tables, c.snapshot = c.snapshot, nil
+	}
+	return nil
+}
+
+func (s *memoryStmt) Close() error { return nil }
+func (s *memoryStmt) NumInput() int { return -1 }
+
+// placeholders checks that the placeholders of a statement are those of the dialect, one per argument
+func (s *memoryStmt) placeholders(marks []string, args []driver.Value) error {
+	if len(marks) != len(args) {
+		return fmt.Errorf("%d placeholders for %d arguments in %s", len(marks), len(args), s.query)
+	}
+	for i, mark := range marks {
+		expected := "?"
+		if s.conn.driver.numbered {
+			expected = fmt.Sprintf("$%d", i + 1)
+		}
+		if strings.TrimSpace(mark) != expected {
+			return fmt.Errorf("placeholder %s, expected %s in %s", mark, expected, s.query)
+		}
+	}
+	return nil
+}
+
+func (s *memoryStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.conn.driver.mu.Lock()
+	defer s.conn.driver.mu.Unlock()
+	tables := s.conn.db.tables
+	if m := statements[0].FindStringSubmatch(s.query); m != nil {
+		if tables[m[1]] == nil {
+			tables[m[1]] = []map[string]driver.Value{}
+		}
+		return driver.RowsAffected(0), nil
+	}
+	if m := statements[1].FindStringSubmatch(s.query); m != nil {
+		if err := s.placeholders(strings.Split(m[3], ","), args); err != nil {
+			return nil, err
+		}
+		row := map[string]driver.Value{}
+		for i, column := range strings.Split(m[2], ", ") {
+			row[column] = args[i]
+		}
+		tables[m[1]] = append(tables[m[1]], row)
+		return driver.RowsAffected(1), nil
+	}
+	if m := statements[2].FindStringSubmatch(s.query); m != nil {
+		var marks []string
+		if m[3] != "" {
+			marks = []string{m[3]}
+		}
+		if err := s.placeholders(marks, args); err != nil {
+			return nil, err
+		}
+		kept := []map[string]driver.Value{}
+		for _, row := range tables[m[1]] {
+			if m[2] != "" && row[m[2]] != args[0] {
+				kept = append(kept, row)
+			}
+		}
+		tables[m[1]] = kept
+		return driver.RowsAffected(1), nil
+	}
+	return nil, errors.New("memory driver: can not run " + s.query)
+}
+
+func (s *memoryStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.conn.driver.mu.Lock()
+	defer s.conn.driver.mu.Unlock()
+	m := statements[3].FindStringSubmatch(s.query)
+	if m == nil {
+		return nil, errors.New("memory driver: can not run " + s.query)
+	}
+	var marks []string
+	if m[4] != "" {
+		marks = []string{m[4]}
+	}
+	if err := s.placeholders(marks, args); err != nil {
+		return nil, err
+	}
+	table, found := s.conn.db.tables[m[2]]
+	if !found {
+		return nil, errors.New("memory driver: no table " + m[2])
+	}
+	result := &memoryRows{columns: strings.Split(m[1], ", ")}
+	selected := []map[string]driver.Value{}
+	for _, row := range table {
+		if m[3] == "" || row[m[3]] == args[0] {
+			selected = append(selected, row)
+		}
+	}
+	if m[5] != "" {
+		sort.Slice(selected, func(i, j int) bool { return fmt.Sprint(selected[i][m[5]]) < fmt.Sprint(selected[j][m[5]]) })
+	}
+	for _, row := range selected {
+		var values []driver.Value
+		for _, column := range result.columns {
+			values = append(values, row[column])
+		}
+		result.rows = append(result.rows, values)
+	}
+	return result, nil
+}
+
+func (r *memoryRows) Columns() []string { return r.columns }
+func (r *memoryRows) Close() error { return nil }
+func (r *memoryRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+// ============================================================================================================================
+// newProjector - a projector on an empty database of the driver, reading the events of a fresh simulated network, the four
+// separate chaincodes or the single TradeFinance chaincode, and checking it as the ledger
+// ============================================================================================================================
+func newProjector(t *testing.T, driverName string, single bool) (*projector.Projector, *simulator.Network) {
+	ledger, err := tfclient.NewSimulated(single, simulator.DefaultBalance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eventLog := &relay.Log{}
+	relay.Watch(ledger.Network, eventLog, func(err error) { t.Error(err) })
+	db, err := sql.Open(driverName, t.Name() + driverName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	p := &projector.Projector{DB: db, Dialect: projector.DialectOf(driverName), Log: eventLog, Ledger: tfclient.New(ledger)}
+	if driverName == "memory-numbered" {
+		p.Dialect = projector.Postgres
+	}
+	if err = p.Init(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return p, ledger.Network
+}
+
+// ============================================================================================================================
+// runScript - run the trade of the simulator script
+// ============================================================================================================================
+func runScript(t *testing.T, n *simulator.Network) {
+	script, err := os.Open("../../simulator/scripts/trade.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer script.Close()
+	stdout := os.Stdout
+	os.Stdout, _ = os.Open(os.DevNull)
+	defer func() { os.Stdout = stdout }()
+	if err = (&simulator.Console{Network: n, Out: io.Discard}).Run(script); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// ============================================================================================================================
+// dump - every row of every table of the projection, one line per row
+// ============================================================================================================================
+func dump(t *testing.T, p *projector.Projector) string {
+	var lines []string
+	for _, table := range projector.Tables {
+		rows, err := p.Rows(context.Background(), table)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for id, row := range rows {
+			lines = append(lines, fmt.Sprintf("%s %s %v", table.Name, id, row))
+		}
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n")
+}
+
+func TestProjection(t *testing.T) {
+	for _, driverName := range []string{"memory", "memory-numbered"} {
+		p, n := newProjector(t, driverName, false)
+		ctx := context.Background()
+		runScript(t, n)
+		n.Call("update_fraud_list", "FRD1", "Fraudco")
+		if err := p.Run(ctx, false); err != nil {
+			t.Fatal(err)
+		}
+		records, _ := p.Log.Read(0)
+		if checkpoint, err := p.Checkpoint(ctx); err != nil || checkpoint != records[len(records)-1].Seq {
+			t.Errorf("%s: checkpoint %d of %d events: %v", driverName, checkpoint, len(records), err)
+		}
+		rows := map[string]map[string]projector.Row{}
+		for _, table := range projector.Tables {
+			rows[table.Name], _ = p.Rows(ctx, table)
+		}
+		po, payment, shipment := rows["pos"]["PO1"], rows["payments"]["PAY1"], rows["shipments"]["SHP1"]
+		if po["seller_name"] != "Sellerco" || po["version"] != "1" || po["created_at"] == "" || po["last_modified_tx_id"] == "" {
+			t.Errorf("%s: PO1 %v", driverName, po)
+		}
+		if payment["payment_status"] != "Paid" || payment["buyer_bank_sign"] != "true" || payment["version"] != "2" {
+			t.Errorf("%s: PAY1 %v", driverName, payment)
+		}
+		if shipment["shipment_status"] != "Delivered" || shipment["agreement_id"] != "AGR1" {
+			t.Errorf("%s: SHP1 %v", driverName, shipment)
+		}
+		if rows["agreements"]["AGR1"]["seller_sign"] != "true" || rows["fraud_entries"]["FRD1"]["fraud_name"] != "Fraudco" {
+			t.Errorf("%s: AGR1 %v, FRD1 %v", driverName, rows["agreements"]["AGR1"], rows["fraud_entries"]["FRD1"])
+		}
+		if drift, err := p.Drift(ctx); err != nil || len(drift) != 0 {
+			t.Errorf("%s: drift after the trade: %v %v", driverName, drift, err)
+		}
+	}
+}
+
+func TestReplayAndRebuild(t *testing.T) {
+	p, n := newProjector(t, "memory", false)
+	ctx := context.Background()
+	runScript(t, n)
+	if err := p.Run(ctx, false); err != nil {
+		t.Fatal(err)
+	}
+	projected := dump(t, p)
+
+	//an event applied again, e.g. replayed by execute_once, leaves the newer row as it is
+	records, _ := p.Log.Read(0)
+	for _, record := range records {
+		if record.Name == events.PaymentCreated {
+			p.Log.Append(record)
+		}
+	}
+	if err := p.Run(ctx, false); err != nil {
+		t.Fatal(err)
+	}
+	if again := dump(t, p); again != projected {
+		t.Errorf("replayed payment.created changed the projection:\n%s\n%s", again, projected)
+	}
+
+	n.Call("delete_po", "PO1")
+	if err := p.Run(ctx, false); err != nil {
+		t.Fatal(err)
+	}
+	rows, _ := p.Rows(ctx, projector.TableOf("po"))
+	if _, found := rows["PO1"]; found {
+		t.Errorf("PO1 was deleted: %v", rows)
+	}
+	incremental := dump(t, p)
+	if err := p.Rebuild(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if rebuilt := dump(t, p); rebuilt != incremental {
+		t.Errorf("rebuilt projection:\n%s\nincremental:\n%s", rebuilt, incremental)
+	}
+}
+
+func TestDrift(t *testing.T) {
+	p, n := newProjector(t, "memory", true)
+	ctx := context.Background()
+	runScript(t, n)
+	if err := p.Run(ctx, false); err != nil {
+		t.Fatal(err)
+	}
+	if drift, err := p.Drift(ctx); err != nil || len(drift) != 0 {
+		t.Fatalf("drift after the trade: %v %v", drift, err)
+	}
+
+	//changes the projection does not see: a write to its tables, and a transaction missing from the event log
+	tx, _ := p.DB.Begin()
+	tx.Exec("DELETE FROM payments WHERE payment_id = ?", "PAY1")
+	tx.Exec("INSERT INTO payments (payment_id, agreement_id, payment_status) VALUES (?, ?, ?)", "PAY1", "AGR1", "Created")
+	tx.Exec("INSERT INTO pos (trans_id, seller_name) VALUES (?, ?)", "PO9", "Ghostco")
+	tx.Commit()
+	p.Log = &relay.Log{}
+	n.Call("create_po", "PO2", "Sellerco", "Buyerco", "2024-03-01", "2024-01-15", "Created", "ITM-1", "Rice", "100", "25", "true", "false")
+	drift, err := p.Drift(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found []string
+	for _, d := range drift {
+		found = append(found, d.String())
+	}
+	report := strings.Join(found, "\n")
+	for _, expected := range []string{`payments PAY1 payment_status: "Created" in the projection, "Paid" on the ledger`,
+		`payments PAY1 version: NULL in the projection, "2" on the ledger`, "pos PO2: missing from the projection",
+		"pos PO9: missing from the ledger"} {
+		if !strings.Contains(report, expected) {
+			t.Errorf("drift is missing %s:\n%s", expected, report)
+		}
+	}
+	if err = p.Repair(ctx, drift); err != nil {
+		t.Fatal(err)
+	}
+	if drift, err = p.Drift(ctx); err != nil || len(drift) != 0 {
+		t.Errorf("drift after the repair: %v %v", drift, err)
+	}
+}
+
+func TestBatch(t *testing.T) {
+	p, n := newProjector(t, "memory", true)
+	ctx := context.Background()
+	steps := []router.BatchStep{
+		{Function: "create_po", Args: []string{"PO1", "Sellerco", "Buyerco", "2024-03-01", "2024-01-15", "Created", "ITM-1", "Rice", "100", "25", "true", "false"}},
+		{Function: "update_po", Args: []string{`{"transId": "PO1", "seller_sign": "true"}`}},
+		{Function: "create_po", Args: []string{"PO2", "Sellerco", "Buyerco", "2024-03-01", "2024-01-15", "Created", "ITM-1", "Rice", "100", "25", "true", "false"}},
+	}
+	stepsAsBytes, _ := json.Marshal(steps)
+	if res := n.Call("execute_batch", string(stepsAsBytes)); res.Failed() {
+		t.Fatal(res.Message())
+	}
+	if err := p.Run(ctx, false); err != nil {
+		t.Fatal(err)
+	}
+	rows, _ := p.Rows(ctx, projector.TableOf("po"))
+	if len(rows) != 2 || rows["PO1"]["seller_sign"] != "true" || rows["PO1"]["version"] != "2" {
+		t.Errorf("batch: %v", rows)
+	}
+	if drift, err := p.Drift(ctx); err != nil || len(drift) != 0 {
+		t.Errorf("drift after the batch: %v %v", drift, err)
+	}
+}
+
+func TestSchema(t *testing.T) {
+	payments := projector.TableOf("payment")
+	if payments.Name != "payments" || payments.Key != "payment_id" {
+		t.Errorf("payments table: %+v", payments)
+	}
+	schema := strings.Join(projector.Schema(), "\n")
+	for _, column := range []string{"payment_id TEXT PRIMARY KEY", "buyer_bank_sign TEXT", "version INTEGER",
+		"last_modified_tx_id TEXT", "agreement_port_auth_name TEXT", "pain001_msg_id TEXT", "seq BIGINT NOT NULL"} {
+		if !strings.Contains(schema, column) {
+			t.Errorf("schema is missing %s:\n%s", column, schema)
+		}
+	}
+	if projector.DialectOf("pgx") != projector.Postgres || projector.DialectOf("sqlite3") != projector.SQLite {
+		t.Errorf("DialectOf")
+	}
+}
@@ -0,0 +1,160 @@
+package projector
+
+import (
+"reflect"
+"strconv"
+"strings"
+"unicode"
+
+"github.com/wipro-blockchain/TF-v1/internal/agreement"
+"github.com/wipro-blockchain/TF-v1/internal/payment"
+"github.com/wipro-blockchain/TF-v1/internal/po"
+"github.com/wipro-blockchain/TF-v1/internal/shipment"
+)
+
+// Dialect is the SQL of a database, SQLite and Postgres differ only in their placeholders
+type Dialect struct {
+	Name string
+	numbered bool									// $1, $2 instead of ?
+}
+
+var SQLite = Dialect{Name: "sqlite"}
+var Postgres = Dialect{Name: "postgres", numbered: true}
+
+// ============================================================================================================================
+// DialectOf - the dialect of a database/sql driver name, Postgres for postgres and pgx, SQLite otherwise
+// ============================================================================================================================
+func DialectOf(driver string) Dialect {
+	if driver == "postgres" || driver == "pgx" {
+		return Postgres
+	}
+	return SQLite
+}
+// ============================================================================================================================
+// placeholders - n placeholders separated by commas
+// ============================================================================================================================
+func (d Dialect) placeholders(n int) string {
+	marks := make([]string, n)
+	for i := range marks {
+		marks[i] = "?"
+		if d.numbered {
+			marks[i] = "$" + strconv.Itoa(i + 1)
+		}
+	}
+	return strings.Join(marks, ", ")
+}
+
+// Column is a column of a table, holding a top-level field of the record
+type Column struct {
+	Name string										// e.g. buyer_bank_sign
+	Field string									// e.g. buyerBank_sign
+	Integer bool									// INTEGER rather than TEXT, e.g. version
+}
+
+// Table is the projection of one type of record, a row per record with a column per field
+type Table struct {
+	Name string										// e.g. payments
+	Entity string									// the entity type of its events, e.g. payment
+	Key string										// the column of the record ID, e.g. payment_id
+	Columns []Column
+	get string										// the query reading a record by ID
+	list string										// the query reading every record
+}
+
+// Tables are the tables of the projection, their columns follow the fields of the chaincode records
+var Tables = []*Table{
+	newTable("pos", "po", "transId", po.PO{}, "getPO_byID", "get_AllPO"),
+	newTable("agreements", "agreement", "agreementId", agreement.Agreement{}, "getAgreement_byID", "get_AllAgreement"),
+	newTable("payments", "payment", "paymentId", payment.Payment{}, "getPaymentByID", "getAllPayment"),
+	newTable("shipments", "shipment", "shipmentId", shipment.Shipment{}, "getShipment_byID", "get_AllShipment"),
+	newTable("fraud_entries", "fraud", "fraudId", agreement.Fraud_list{}, "", "get_fraud_list"),
+}
+
+// checkpointTable keeps the sequence number of the last event applied, by projection name
+const checkpointTable = "projector_checkpoints"
+
+// ============================================================================================================================
+// newTable - the table of a record type, a column for each JSON field of record
+// ============================================================================================================================
+func newTable(name string, entity string, keyField string, record interface{}, get string, list string) *Table {
+	t := &Table{Name: name, Entity: entity, Key: snake(keyField), get: get, list: list}
+	recordType := reflect.TypeOf(record)
+	for i := 0; i < recordType.NumField(); i++ {
+		field := strings.Split(recordType.Field(i).Tag.Get("json"), ",")[0]
+		if field == "" || field == "-" {
+			continue
+		}
+		t.Columns = append(t.Columns, Column{Name: snake(field), Field: field, Integer: recordType.Field(i).Type.Kind() == reflect.Int})
+	}
+	return t
+}
+// ============================================================================================================================
+// TableOf - the table of an entity type, nil when it is not projected
+// ============================================================================================================================
+func TableOf(entity string) *Table {
+	for _, t := range Tables {
+		if t.Entity == entity {
+			return t
+		}
+	}
+	return nil
+}
+// ============================================================================================================================
+// column - the column of a record field, nil when the table has none
+// ============================================================================================================================
+func (t *Table) column(field string) *Column {
+	for i := range t.Columns {
+		if t.Columns[i].Field == field {
+			return &t.Columns[i]
+		}
+	}
+	return nil
+}
+// ============================================================================================================================
+// names - the column names in order
+// ============================================================================================================================
+func (t *Table) names() []string {
+	var names []string
+	for _, c := range t.Columns {
+		names = append(names, c.Name)
+	}
+	return names
+}
+// ============================================================================================================================
+// Schema - the statements creating the tables that do not exist yet, the same for SQLite and Postgres
+// ============================================================================================================================
+func Schema() []string {
+	var statements []string
+	for _, t := range Tables {
+		var columns []string
+		for _, c := range t.Columns {
+			definition := c.Name + " TEXT"
+			if c.Integer {
+				definition = c.Name + " INTEGER"
+			}
+			if c.Name == t.Key {
+				definition += " PRIMARY KEY"
+			}
+			columns = append(columns, definition)
+		}
+		statements = append(statements, "CREATE TABLE IF NOT EXISTS " + t.Name + " (" + strings.Join(columns, ", ") + ")")
+	}
+	return append(statements, "CREATE TABLE IF NOT EXISTS " + checkpointTable + " (name TEXT PRIMARY KEY, seq BIGINT NOT NULL)")
+}
+// ============================================================================================================================
+// snake - the column name of a JSON field, e.g. buyer_bank_sign for buyerBank_sign or last_modified_tx_id for lastModifiedTxId
+// ============================================================================================================================
+func snake(field string) string {
+	var name []rune
+	runes := []rune(field)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && runes[i-1] != '_' && !unicode.IsUpper(runes[i-1]) {
+				name = append(name, '_')
+			}
+			r = unicode.ToLower(r)
+		}
+		name = append(name, r)
+	}
+	return string(name)
+}
@@ -0,0 +1,163 @@
+package relay
+
+import (
+"bytes"
+"context"
+"errors"
+"os"
+"strconv"
+"sync"
+"encoding/json"
+
+tfclient "github.com/wipro-blockchain/TF-v1/client"
+"github.com/wipro-blockchain/TF-v1/events"
+"github.com/wipro-blockchain/TF-v1/internal/simulator"
+)
+
+// Record is a committed chaincode event in the event log
+type Record struct {
+	Seq uint64 `json:"seq"`						// position in the log, from 1
+	Chaincode string `json:"chaincode,omitempty"`
+	TxID string `json:"txId"`
+	Block uint64 `json:"block,omitempty"`			// the block of the transaction, when followed from the channel
+	Name string `json:"name"`						// e.g. agreement.signed, see package events
+	Payload json.RawMessage `json:"payload"`
+}
+
+// Blocks is the block stream of a channel, a client.Peer
+type Blocks interface {
+	FollowBlocks(ctx context.Context, start uint64, f func(*tfclient.CommittedEvent) error) error
+}
+
+// Log is an append-only event log, one JSON record per line in the file at Path, in memory when Path is empty. A writer, e.g.
+// the gateway, and the relay may share the file: a line is appended with one write, and a last line without its newline is
+// still being written
+type Log struct {
+	Path string
+	mu sync.Mutex
+	records []Record								// the log, when in memory
+	last uint64									// the last sequence number, once the file was read
+	read bool
+}
+
+// ============================================================================================================================
+// Append - add an event at the end of the log, it is given the next sequence number
+// ============================================================================================================================
+func (l *Log) Append(record Record) (Record, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !json.Valid(record.Payload) {
+		record.Payload, _ = json.Marshal(string(record.Payload))	//a payload that is not JSON is kept as a string
+	}
+	if l.Path == "" {
+		record.Seq = uint64(len(l.records)) + 1
+		l.records = append(l.records, record)
+		return record, nil
+	}
+	if !l.read {
+		records, err := l.scan(0)
+		if err != nil {
+			return record, err
+		}
+		if len(records) > 0 {
+			l.last = records[len(records)-1].Seq
+		}
+		l.read = true
+	}
+	record.Seq = l.last + 1
+	line, _ := json.Marshal(record)
+	file, err := os.OpenFile(l.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return record, err
+	}
+	defer file.Close()
+	if _, err = file.Write(append(line, '\n')); err != nil {
+		return record, err
+	}
+	l.last = record.Seq
+	return record, nil
+}
+// ============================================================================================================================
+// Read - the events after the sequence number after, in order, none when the log file does not exist yet
+// ============================================================================================================================
+func (l *Log) Read(after uint64) ([]Record, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.Path == "" {
+		if after >= uint64(len(l.records)) {
+			return nil, nil
+		}
+		return append([]Record{}, l.records[after:]...), nil
+	}
+	return l.scan(after)
+}
+// ============================================================================================================================
+// scan - the complete lines of the log file after the sequence number after
+// ============================================================================================================================
+func (l *Log) scan(after uint64) ([]Record, error) {
+	logAsBytes, err := os.ReadFile(l.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var records []Record
+	complete := logAsBytes[:bytes.LastIndexByte(logAsBytes, '\n') + 1]
+	for i, line := range bytes.Split(complete, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		record := Record{}
+		if err = json.Unmarshal(line, &record); err != nil || record.Seq == 0 {
+			return nil, errors.New(l.Path + ": malformed event record on line " + strconv.Itoa(i + 1))
+		}
+		if record.Seq > after {
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
+// ============================================================================================================================
+// Watch - append the event of every invoke the simulated network commits to the log, report is called with an error to append.
+// An evtsender event is logged under its catalog name
+// ============================================================================================================================
+func Watch(n *simulator.Network, l *Log, report func(error)) {
+	n.Listen(func(res simulator.Result) {
+		if len(res.Events) == 0 {
+			return
+		}
+		event := res.Events[len(res.Events)-1]			//a peer emits only the last event of a transaction
+		_, err := l.Append(Record{Chaincode: res.Chaincode, TxID: res.TxID, Name: events.CatalogName(event.Name, event.Payload), Payload: event.Payload})
+		if err != nil && report != nil {
+			report(err)
+		}
+	})
+}
+// ============================================================================================================================
+// FollowChannel - append the chaincode event of every valid transaction the channel commits to the log, until ctx is done.
+// It carries on from the block of the last event in the log, skipping the transactions of that block already logged, so
+// that a restart neither loses nor repeats an event. An empty log starts from the first block of the channel
+// ============================================================================================================================
+func FollowChannel(ctx context.Context, blocks Blocks, l *Log) error {
+	records, err := l.Read(0)
+	if err != nil {
+		return err
+	}
+	var start uint64
+	logged := map[string]bool{}								//the transactions of block start in the log
+	for _, record := range records {
+		if record.Block != start {
+			start, logged = record.Block, map[string]bool{}
+		}
+		logged[record.TxID] = true
+	}
+	return blocks.FollowBlocks(ctx, start, func(committed *tfclient.CommittedEvent) error {
+		if committed.Block == start && logged[committed.TxID] {
+			return nil
+		}
+		_, err := l.Append(Record{Chaincode: committed.Chaincode, TxID: committed.TxID, Block: committed.Block,
+			Name: events.CatalogName(committed.Name, committed.Payload), Payload: committed.Payload})
+		return err
+	})
+}
@@ -0,0 +1,260 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements.  See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership.  The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License.  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+// Package relay sends the committed chaincode events to partner webhooks. It reads them from an event log, written from the
+// blocks of the channel, by the gateway on the simulated network or, in tests, by the simulated network, and sends each subscription the events it filters for, by event name,
+// entity and party. Every request is signed with the subscription's secret. A failed request is retried with exponential
+// backoff, and an event that could not be delivered is kept as a dead letter. A checkpoint per subscription lets a restarted
+// relay carry on where it stopped, so an event is sent at least once.
+package relay
+
+import (
+"bytes"
+"context"
+"crypto/hmac"
+"crypto/sha256"
+"encoding/hex"
+"errors"
+"fmt"
+"io"
+"net/http"
+"strconv"
+"sync"
+"time"
+"encoding/json"
+
+"github.com/wipro-blockchain/TF-v1/events"
+)
+
+// The headers of a webhook request
+const (
+	EventHeader = "X-TF-Event"						// the event name, e.g. agreement.signed
+	DeliveryHeader = "X-TF-Delivery"				// <subscription>-<seq>, the same on every attempt, for the partner to drop repeats
+	TimestampHeader = "X-TF-Timestamp"				// the Unix time of the attempt
+	SignatureHeader = "X-TF-Signature"				// sha256=<hex HMAC-SHA256 of "<timestamp>.<body>" with the secret>
+)
+
+// Relay sends the events of a log to the webhooks of its subscriptions
+type Relay struct {
+	Log *Log
+	Subscriptions []*Subscription
+	Checkpoints *Checkpoints						// in memory when nil
+	DeadLetters *DeadLetters						// in memory when nil
+	Client *http.Client								// a client with a 10 second timeout when nil
+	MaxAttempts int									// attempts of a delivery, 8 when 0
+	Backoff time.Duration							// wait after the first failed attempt, doubled after each one, 1s when 0
+	MaxBackoff time.Duration						// the longest wait, 5 minutes when 0
+	PollInterval time.Duration						// how often a following relay reads the log, 1s when 0
+	Logf func(format string, args ...interface{})	// reports deliveries and failures when set
+	once sync.Once
+}
+
+// ============================================================================================================================
+// Run - send every subscription the events of the log after its checkpoint, each subscription in turn of its own so that a
+// failing webhook holds up only its own events. With follow it keeps reading the log until ctx is done, without it returns
+// once the log is sent. An error is one of the log or the stores, the subscription stops at the event it could not finish
+// ============================================================================================================================
+func (r *Relay) Run(ctx context.Context, follow bool) error {
+	r.defaults()
+	errs := make(chan error, len(r.Subscriptions))
+	for _, sub := range r.Subscriptions {
+		if err := sub.Check(); err != nil {
+			return err
+		}
+	}
+	for _, sub := range r.Subscriptions {
+		go func(sub *Subscription) {
+			errs <- r.serve(ctx, sub, follow)
+		}(sub)
+	}
+	var first error
+	for range r.Subscriptions {
+		if err := <-errs; err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+// ============================================================================================================================
+// defaults - fill in the settings left empty
+// ============================================================================================================================
+func (r *Relay) defaults() {
+	r.once.Do(func() {
+		if r.Checkpoints == nil {
+			r.Checkpoints = &Checkpoints{}
+		}
+		if r.DeadLetters == nil {
+			r.DeadLetters = &DeadLetters{}
+		}
+		if r.Client == nil {
+			r.Client = &http.Client{Timeout: 10 * time.Second}
+		}
+		if r.MaxAttempts == 0 {
+			r.MaxAttempts = 8
+		}
+		if r.Backoff == 0 {
+			r.Backoff = time.Second
+		}
+		if r.MaxBackoff == 0 {
+			r.MaxBackoff = 5 * time.Minute
+		}
+		if r.PollInterval == 0 {
+			r.PollInterval = time.Second
+		}
+		if r.Logf == nil {
+			r.Logf = func(string, ...interface{}) {}
+		}
+	})
+}
+// ============================================================================================================================
+// serve - send a subscription its events. The log is read from the start to learn the parties of every entity, the events up
+// to the checkpoint were sent before
+// ============================================================================================================================
+func (r *Relay) serve(ctx context.Context, sub *Subscription, follow bool) error {
+	checkpoint, err := r.Checkpoints.Get(sub.ID)
+	if err != nil {
+		return err
+	}
+	known := parties{}
+	var read uint64
+	for {
+		records, err := r.Log.Read(read)
+		if err != nil {
+			return err
+		}
+		for _, record := range records {
+			read = record.Seq
+			event := events.Event{}
+			json.Unmarshal(record.Payload, &event)
+			involved := known.add(event)
+			if record.Seq <= checkpoint || !sub.Matches(record, event, involved) {
+				continue
+			}
+			if err = r.deliver(ctx, sub, record); err != nil {
+				if ctx.Err() != nil {
+					return nil								//stopped, the event is sent again after a restart
+				}
+				return err
+			}
+			if err = r.Checkpoints.Set(sub.ID, record.Seq); err != nil {
+				return err
+			}
+			checkpoint = record.Seq
+		}
+		if !follow {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(r.PollInterval):
+		}
+	}
+}
+// ============================================================================================================================
+// deliver - send an event to a subscription until it is accepted, refused or out of attempts, keeping it as a dead letter in
+// the last two cases. A 2xx answer accepts it, a 408, 429 or 5xx answer or no answer is retried, any other answer refuses it
+// ============================================================================================================================
+func (r *Relay) deliver(ctx context.Context, sub *Subscription, record Record) error {
+	deliveryID := sub.ID + "-" + strconv.FormatUint(record.Seq, 10)
+	wait := r.Backoff
+	var outcome string
+	attempt := 1
+	for ; ; attempt++ {
+		status, err := r.post(ctx, sub, deliveryID, record)
+		if err == nil && status / 100 == 2 {
+			r.Logf("%s: %s %s delivered, answered %d", sub.ID, deliveryID, record.Name, status)
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			outcome = err.Error()
+		}else{
+			outcome = "answered " + strconv.Itoa(status) + " " + http.StatusText(status)
+		}
+		retry := err != nil || status == http.StatusRequestTimeout || status == http.StatusTooManyRequests || status >= 500
+		if !retry || attempt == r.MaxAttempts {
+			break
+		}
+		r.Logf("%s: %s %s attempt %d %s, retrying in %s", sub.ID, deliveryID, record.Name, attempt, outcome, wait)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		wait *= 2
+		if wait > r.MaxBackoff {
+			wait = r.MaxBackoff
+		}
+	}
+	r.Logf("%s: %s %s dead after %d attempts, %s", sub.ID, deliveryID, record.Name, attempt, outcome)
+	return r.DeadLetters.Add(DeadLetter{Subscription: sub.ID, DeliveryID: deliveryID, URL: sub.URL, Record: record,
+		Attempts: attempt, Error: outcome})
+}
+// ============================================================================================================================
+// post - one attempt at a delivery: the event payload, signed, with its name and delivery ID in the headers
+// ============================================================================================================================
+func (r *Relay) post(ctx context.Context, sub *Subscription, deliveryID string, record Record) (int, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req, err := http.NewRequestWithContext(ctx, "POST", sub.URL, bytes.NewReader(record.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, record.Name)
+	req.Header.Set(DeliveryHeader, deliveryID)
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, Sign(sub.Secret, timestamp, record.Payload))
+	resp, err := r.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64 << 10))	//lets the connection be reused
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
+// ============================================================================================================================
+// Sign - the signature header of a webhook body sent at timestamp, sha256= and the hex HMAC-SHA256 of "<timestamp>.<body>"
+// ============================================================================================================================
+func Sign(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+// ============================================================================================================================
+// Verify - check a webhook request on the partner's side: its signature with the shared secret, and that it was signed at most
+// tolerance ago, so a captured request can not be replayed later
+// ============================================================================================================================
+func Verify(secret string, header http.Header, body []byte, tolerance time.Duration) error {
+	timestamp := header.Get(TimestampHeader)
+	signed, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errors.New("relay: missing or malformed " + TimestampHeader)
+	}
+	if !hmac.Equal([]byte(header.Get(SignatureHeader)), []byte(Sign(secret, timestamp, body))) {
+		return errors.New("relay: the signature does not match")
+	}
+	if age := time.Since(time.Unix(signed, 0)); age > tolerance || age < -tolerance {
+		return fmt.Errorf("relay: the request was signed %s ago, more than %s", age.Round(time.Second), tolerance)
+	}
+	return nil
+}
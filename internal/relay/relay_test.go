@@ -0,0 +1,329 @@
+package relay_test
+
+import (
+"context"
+"io"
+"net/http"
+"net/http/httptest"
+"os"
+"path/filepath"
+"strconv"
+"strings"
+"sync"
+"testing"
+"time"
+
+tfclient "github.com/wipro-blockchain/TF-v1/client"
+"github.com/wipro-blockchain/TF-v1/events"
+"github.com/wipro-blockchain/TF-v1/internal/relay"
+"github.com/wipro-blockchain/TF-v1/internal/simulator"
+)
+
+// delivery is a webhook request as the partner received it
+type delivery struct {
+	Subscription string
+	ID string
+	Name string
+	Event *events.Event
+	Verified error
+}
+
+// partner is a webhook endpoint answering with the statuses of answers in turn, then 200
+type partner struct {
+	mu sync.Mutex
+	secret string
+	answers []int
+	received []delivery
+	server *httptest.Server
+}
+
+func newPartner(t *testing.T, secret string, answers ...int) *partner {
+	p := &partner{secret: secret, answers: answers}
+	p.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		d := delivery{Subscription: req.URL.Path[1:], ID: req.Header.Get(relay.DeliveryHeader), Name: req.Header.Get(relay.EventHeader)}
+		d.Event, d.Verified = events.Decode(d.Name, body)
+		if d.Verified == nil {
+			d.Verified = relay.Verify(p.secret, req.Header, body, time.Minute)
+		}
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.received = append(p.received, d)
+		status := http.StatusOK
+		if len(p.answers) > 0 {
+			status, p.answers = p.answers[0], p.answers[1:]
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+// ============================================================================================================================
+// trade - a simulated network running the trade of the simulator script, its events appended to a log in memory
+// ============================================================================================================================
+func trade(t *testing.T) (*simulator.Network, *relay.Log) {
+	n, err := simulator.New(simulator.DefaultBalance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eventLog := &relay.Log{}
+	relay.Watch(n, eventLog, func(err error) { t.Error(err) })
+	script, err := os.Open("../../simulator/scripts/trade.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer script.Close()
+	stdout := os.Stdout
+	os.Stdout, _ = os.Open(os.DevNull)
+	defer func() { os.Stdout = stdout }()
+	console := &simulator.Console{Network: n, Out: io.Discard}
+	if err = console.Run(script); err != nil {
+		t.Fatal(err)
+	}
+	n.Call("create_po", "PO2", "Otherseller", "Otherbuyer", "2024-03-01", "2024-01-15", "Accepted", "ITM-1", "Rice", "100", "25",
+		"true", "true")
+	res := n.Call("create_agreement", "AGR2", "PO2", "Created", "Otherbuyer", "Otherseller", "Shipco", "Buybank", "Sellbank",
+		"Portauth", "2024-01-16", "ITM-1", "Rice", "100", "2500", "2024-03-01", "0", "100", "Contract", "http://docs/contract",
+		"Terms", "true", "false", "false", "false", "Food", "25")
+	if res.Failed() {
+		t.Fatal(res.Message())
+	}
+	return n, eventLog
+}
+
+func TestRelay(t *testing.T) {
+	n, eventLog := trade(t)
+	p := newPartner(t, "s3cret")
+	r := &relay.Relay{Log: eventLog, Subscriptions: []*relay.Subscription{
+		{ID: "sellerco", URL: p.server.URL + "/sellerco", Secret: "s3cret", Events: []string{"agreement.*", events.PaymentSettled},
+			Parties: []string{"Sellerco"}},
+		{ID: "escrow", URL: p.server.URL + "/escrow", Secret: "s3cret", Entities: []string{"escrow"}, Parties: []string{"Buyerco"}},
+	}}
+	if err := r.Run(context.Background(), false); err != nil {
+		t.Fatal(err)
+	}
+	received := map[string][]string{}
+	for _, d := range p.received {
+		if d.Verified != nil || !strings.HasPrefix(d.ID, d.Subscription + "-") {
+			t.Errorf("delivery %+v", d)
+			continue
+		}
+		received[d.Subscription] = append(received[d.Subscription], d.Name + " " + d.Event.EntityID)
+	}
+	if got := strings.Join(received["sellerco"], ", "); got != "agreement.created AGR1, agreement.signed AGR1, payment.settled PAY1" {
+		t.Errorf("sellerco was sent %s", got)
+	}
+	if got := strings.Join(received["escrow"], ", "); got != "escrow.created PAY1, escrow.released PAY1" {
+		t.Errorf("escrow was sent %s", got)
+	}
+
+	//a relay run again carries on after its checkpoints
+	p.received = nil
+	n.Call("update_agreement", "AGR1", "PO1", "Created", "Buyerco", "Sellerco", "Shipco", "Buybank", "Sellbank", "Portauth",
+		"2024-01-16", "ITM-1", "Rice", "100", "2500", "2024-03-01", "0", "100", "Contract", "http://docs/contract", "New terms",
+		"true", "true", "true", "true", "Food", "25")
+	if err := r.Run(context.Background(), false); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.received) != 1 || p.received[0].Verified != nil || p.received[0].Event.Changed("tc_text") == nil {
+		t.Errorf("after the checkpoint: %+v", p.received)
+	}
+}
+
+func TestRetries(t *testing.T) {
+	_, eventLog := trade(t)
+	subscription := func(p *partner) []*relay.Subscription {
+		return []*relay.Subscription{{ID: "po", URL: p.server.URL + "/po", Secret: "s3cret", Events: []string{events.POCreated},
+			Parties: []string{"Sellerco"}}}
+	}
+
+	//a delivery is retried with the same delivery ID until it is accepted
+	flaky := newPartner(t, "s3cret", http.StatusServiceUnavailable, http.StatusTooManyRequests)
+	r := &relay.Relay{Log: eventLog, Subscriptions: subscription(flaky), Backoff: time.Millisecond}
+	if err := r.Run(context.Background(), false); err != nil {
+		t.Fatal(err)
+	}
+	if len(flaky.received) != 3 || flaky.received[0].ID != flaky.received[2].ID {
+		t.Errorf("retries: %+v", flaky.received)
+	}
+	if letters, _ := r.DeadLetters.List(); len(letters) != 0 {
+		t.Errorf("dead letters of an accepted delivery: %+v", letters)
+	}
+
+	//out of attempts, or refused, it becomes a dead letter and the relay carries on
+	for status, attempts := range map[int]int{http.StatusInternalServerError: 3, http.StatusBadRequest: 1} {
+		failing := newPartner(t, "s3cret", status, status, status, status)
+		r = &relay.Relay{Log: eventLog, Subscriptions: subscription(failing), MaxAttempts: 3, Backoff: time.Millisecond}
+		if err := r.Run(context.Background(), false); err != nil {
+			t.Fatal(err)
+		}
+		letters, _ := r.DeadLetters.List()
+		if len(failing.received) != attempts || len(letters) != 1 || letters[0].Attempts != attempts ||
+			letters[0].Record.Name != events.POCreated || !strings.Contains(letters[0].Error, strconv.Itoa(status)) {
+			t.Errorf("answered %d: %d attempts, %+v", status, len(failing.received), letters)
+		}
+		if checkpoint, _ := r.Checkpoints.Get("po"); checkpoint != letters[0].Record.Seq {
+			t.Errorf("answered %d: checkpoint %d", status, checkpoint)
+		}
+	}
+}
+
+func TestFollow(t *testing.T) {
+	n, err := simulator.NewTradeFinance(simulator.DefaultBalance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eventLog := &relay.Log{Path: filepath.Join(t.TempDir(), "events.jsonl")}
+	relay.Watch(n, eventLog, func(err error) { t.Error(err) })
+	p := newPartner(t, "s3cret")
+	r := &relay.Relay{Log: eventLog, PollInterval: time.Millisecond, Subscriptions: []*relay.Subscription{
+		{ID: "po", URL: p.server.URL + "/po", Secret: "s3cret", Events: []string{"po.*"}}}}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- r.Run(ctx, true) }()
+	n.Call("create_po", "PO1", "Sellerco", "Buyerco", "2024-03-01", "2024-01-15", "Created", "ITM-1", "Rice", "100", "25", "true", "false")
+	for start := time.Now(); time.Since(start) < 5 * time.Second; time.Sleep(time.Millisecond) {
+		p.mu.Lock()
+		sent := len(p.received)
+		p.mu.Unlock()
+		if sent > 0 {
+			break
+		}
+	}
+	cancel()
+	if err = <-done; err != nil {
+		t.Fatal(err)
+	}
+	if len(p.received) != 1 || p.received[0].Name != events.POCreated || p.received[0].ID != "po-1" {
+		t.Errorf("followed: %+v", p.received)
+	}
+}
+
+func TestLog(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "events.jsonl")
+	eventLog := &relay.Log{Path: path}
+	for _, name := range []string{events.POCreated, events.POSigned} {
+		if _, err := eventLog.Append(relay.Record{TxID: "tx1", Name: name, Payload: []byte(`{"type": "` + name + `"}`)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	file, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	file.WriteString(`{"seq": 3, "name": "po.upd`)				//a line still being written
+	file.Close()
+	records, err := (&relay.Log{Path: path}).Read(1)
+	if err != nil || len(records) != 1 || records[0].Seq != 2 || records[0].Name != events.POSigned {
+		t.Errorf("Read(1): %+v %v", records, err)
+	}
+	os.WriteFile(path, []byte("{\"seq\": 1, \"name\": \"po.created\", \"payload\": {}}\n"), 0644)
+	record, err := (&relay.Log{Path: path}).Append(relay.Record{Name: events.PODeleted, Payload: []byte("not JSON")})
+	if err != nil || record.Seq != 2 || string(record.Payload) != `"not JSON"` {
+		t.Errorf("Append to an existing log: %+v %v", record, err)
+	}
+	os.WriteFile(path, []byte("{\"name\": \"po.created\"}\n"), 0644)
+	if _, err = (&relay.Log{Path: path}).Read(0); err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("Read of a malformed log: %v", err)
+	}
+
+	checkpoints := filepath.Join(dir, "checkpoints.json")
+	if err = (&relay.Checkpoints{Path: checkpoints}).Set("po", 7); err != nil {
+		t.Fatal(err)
+	}
+	if seq, err := (&relay.Checkpoints{Path: checkpoints}).Get("po"); err != nil || seq != 7 {
+		t.Errorf("checkpoint: %d %v", seq, err)
+	}
+	deadLetters := &relay.DeadLetters{Path: filepath.Join(dir, "dead-letters.jsonl")}
+	deadLetters.Add(relay.DeadLetter{Subscription: "po", DeliveryID: "po-1", Attempts: 8})
+	deadLetters.Add(relay.DeadLetter{Subscription: "po", DeliveryID: "po-2", Attempts: 1})
+	if letters, err := deadLetters.List(); err != nil || len(letters) != 2 || letters[1].DeliveryID != "po-2" || letters[1].FailedAt == "" {
+		t.Errorf("dead letters: %+v %v", letters, err)
+	}
+}
+
+// channel is the block stream of a channel holding the events of committed, it stops after the first stop events
+type channel struct {
+	committed []*tfclient.CommittedEvent
+	stop int
+	starts []uint64
+}
+
+func (c *channel) FollowBlocks(ctx context.Context, start uint64, f func(*tfclient.CommittedEvent) error) error {
+	c.starts = append(c.starts, start)
+	for i, committed := range c.committed {
+		if i == c.stop {
+			return nil
+		}
+		if committed.Block < start {
+			continue
+		}
+		if err := f(committed); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestFollowChannel(t *testing.T) {
+	event := func(block uint64, txID string, name string) *tfclient.CommittedEvent {
+		return &tfclient.CommittedEvent{Block: block, TxID: txID, Chaincode: "tradeFinance",
+			Event: tfclient.Event{Name: name, Payload: []byte(`{"type": "` + name + `", "entityId": "PO1"}`)}}
+	}
+	c := &channel{committed: []*tfclient.CommittedEvent{event(3, "tx1", events.POCreated), event(5, "tx2", events.POSigned),
+		event(5, "tx3", events.POUpdated), event(6, "tx4", events.PODeleted)}, stop: 2}
+	eventLog := &relay.Log{Path: filepath.Join(t.TempDir(), "events.jsonl")}
+	if err := relay.FollowChannel(context.Background(), c, eventLog); err != nil {
+		t.Fatal(err)
+	}
+	c.stop = -1													//restarted in the middle of block 5
+	if err := relay.FollowChannel(context.Background(), c, &relay.Log{Path: eventLog.Path}); err != nil {
+		t.Fatal(err)
+	}
+	records, err := eventLog.Read(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var logged []string
+	for _, record := range records {
+		logged = append(logged, strconv.FormatUint(record.Seq, 10) + " " + record.TxID + "@" + strconv.FormatUint(record.Block, 10) + " " + record.Name)
+	}
+	want := "1 tx1@3 po.created, 2 tx2@5 po.signed, 3 tx3@5 po.updated, 4 tx4@6 po.deleted"
+	if strings.Join(logged, ", ") != want || len(c.starts) != 2 || c.starts[0] != 0 || c.starts[1] != 5 {
+		t.Errorf("followed from %v: %s", c.starts, strings.Join(logged, ", "))
+	}
+}
+
+func TestSubscriptions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "subscriptions.json")
+	cases := map[string]string{
+		`[{"id": "a", "url": "http://partner/hook", "secret": "k", "events": ["payment.*"], "parties": ["Sellerco"]}]`: "",
+		`[{"id": "a", "url": "http://partner/hook"}]`: "needs an id, a url and a secret",
+		`[{"id": "a", "url": "http://partner/hook", "secret": "k", "events": ["payment.paid"]}]`: "payment.paid",
+		`[{"id": "a", "url": "http://partner/hook", "secret": "k", "entities": ["invoice"]}]`: "invoice is not an entity",
+		`[{"id": "a", "url": "http://a", "secret": "k"}, {"id": "a", "url": "http://b", "secret": "k"}]`: "listed twice",
+		`[{"id": "a", "url": "http://partner/hook", "secret": "k", "party": "Sellerco"}]`: "",
+	}
+	for subscriptions, message := range cases {
+		os.WriteFile(path, []byte(subscriptions), 0644)
+		_, err := relay.LoadSubscriptions(path)
+		if (message == "" && err != nil) || (message != "" && (err == nil || !strings.Contains(err.Error(), message))) {
+			t.Errorf("%s: %v, expected %q", subscriptions, err, message)
+		}
+	}
+
+	header := http.Header{}
+	timestamp := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+	header.Set(relay.TimestampHeader, timestamp)
+	header.Set(relay.SignatureHeader, relay.Sign("k", timestamp, []byte(`{"a": 1}`)))
+	if err := relay.Verify("k", header, []byte(`{"a": 1}`), 2 * time.Hour); err != nil {
+		t.Errorf("Verify: %v", err)
+	}
+	for message, check := range map[string]error{
+		"does not match": relay.Verify("k", header, []byte(`{"a": 2}`), 2 * time.Hour),
+		"more than": relay.Verify("k", header, []byte(`{"a": 1}`), time.Minute),
+	} {
+		if check == nil || !strings.Contains(check.Error(), message) {
+			t.Errorf("Verify: %v, expected %q", check, message)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package relay
+
+import (
+"bytes"
+"os"
+"sync"
+"time"
+"encoding/json"
+)
+
+// Checkpoints keeps, for each subscription, the sequence number of the last event it was sent, a relay restarted on the same
+// log carries on after it. They are saved as one JSON object in the file at Path, only in memory when Path is empty
+type Checkpoints struct {
+	Path string
+	mu sync.Mutex
+	seqs map[string]uint64
+}
+
+// ============================================================================================================================
+// Get - the checkpoint of a subscription, 0 before its first event
+// ============================================================================================================================
+func (c *Checkpoints) Get(subscription string) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.load(); err != nil {
+		return 0, err
+	}
+	return c.seqs[subscription], nil
+}
+// ============================================================================================================================
+// Set - move the checkpoint of a subscription to seq and save the checkpoints
+// ============================================================================================================================
+func (c *Checkpoints) Set(subscription string, seq uint64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.load(); err != nil {
+		return err
+	}
+	c.seqs[subscription] = seq
+	if c.Path == "" {
+		return nil
+	}
+	seqsAsBytes, _ := json.MarshalIndent(c.seqs, "", "  ")
+	temp := c.Path + ".tmp"
+	if err := os.WriteFile(temp, seqsAsBytes, 0644); err != nil {
+		return err
+	}
+	return os.Rename(temp, c.Path)					//a crash leaves the old or the new checkpoints, never half of them
+}
+// ============================================================================================================================
+// load - read the checkpoint file once, none when it does not exist yet
+// ============================================================================================================================
+func (c *Checkpoints) load() error {
+	if c.seqs != nil {
+		return nil
+	}
+	c.seqs = map[string]uint64{}
+	if c.Path == "" {
+		return nil
+	}
+	seqsAsBytes, err := os.ReadFile(c.Path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err == nil {
+		err = json.Unmarshal(seqsAsBytes, &c.seqs)
+	}
+	if err != nil {
+		c.seqs = nil
+		return err
+	}
+	return nil
+}
+
+// DeadLetter is an event a subscriber was not sent, after the last attempt or an answer refusing it
+type DeadLetter struct {
+	Subscription string `json:"subscription"`
+	DeliveryID string `json:"deliveryId"`
+	URL string `json:"url"`
+	Record Record `json:"record"`
+	Attempts int `json:"attempts"`
+	Error string `json:"error"`						// the outcome of the last attempt
+	FailedAt string `json:"failedAt"`				// RFC 3339
+}
+
+// DeadLetters keeps the events that could not be delivered, one JSON dead letter per line in the file at Path, in memory when
+// Path is empty, to inspect and send again by hand
+type DeadLetters struct {
+	Path string
+	mu sync.Mutex
+	letters []DeadLetter
+}
+
+// ============================================================================================================================
+// Add - keep a dead letter
+// ============================================================================================================================
+func (d *DeadLetters) Add(letter DeadLetter) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if letter.FailedAt == "" {
+		letter.FailedAt = time.Now().UTC().Format(time.RFC3339)
+	}
+	if d.Path == "" {
+		d.letters = append(d.letters, letter)
+		return nil
+	}
+	line, _ := json.Marshal(letter)
+	file, err := os.OpenFile(d.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
+// ============================================================================================================================
+// List - the dead letters in the order they were added
+// ============================================================================================================================
+func (d *DeadLetters) List() ([]DeadLetter, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.Path == "" {
+		return append([]DeadLetter{}, d.letters...), nil
+	}
+	lettersAsBytes, err := os.ReadFile(d.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var letters []DeadLetter
+	decoder := json.NewDecoder(bytes.NewReader(lettersAsBytes))
+	for decoder.More() {
+		letter := DeadLetter{}
+		if err = decoder.Decode(&letter); err != nil {
+			return nil, err
+		}
+		letters = append(letters, letter)
+	}
+	return letters, nil
+}
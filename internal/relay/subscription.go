@@ -0,0 +1,170 @@
+package relay
+
+import (
+"errors"
+"os"
+"regexp"
+"encoding/json"
+
+"github.com/wipro-blockchain/TF-v1/events"
+)
+
+// Subscription is a partner webhook and the events it is sent, an empty filter lets every event through
+type Subscription struct {
+	ID string `json:"id"`							// names the subscription in the checkpoints, dead letters and delivery IDs
+	URL string `json:"url"`
+	Secret string `json:"secret"`					// the HMAC key of the signature, shared with the partner
+	Events []string `json:"events,omitempty"`		// catalog names, "<entity>.*" for every event of an entity, e.g. agreement.*
+	Entities []string `json:"entities,omitempty"`	// entity types, e.g. agreement or payment
+	Parties []string `json:"parties,omitempty"`		// party names as the records hold them, e.g. Sellerco
+	matcher *regexp.Regexp
+}
+
+// partyFields are the record fields naming a party, by the name each record uses
+var partyFields = []string{"buyerName", "sellerName", "buyer_name", "seller_name", "shipper_name", "bb_name", "sb_name",
+	"agreementPortAuth_name"}
+
+// ============================================================================================================================
+// LoadSubscriptions - read a JSON array of subscriptions from a file and check them
+// ============================================================================================================================
+func LoadSubscriptions(path string) ([]*Subscription, error) {
+	subsAsBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var subs []*Subscription
+	if err = json.Unmarshal(subsAsBytes, &subs); err != nil {
+		return nil, errors.New(path + ": " + err.Error())
+	}
+	seen := map[string]bool{}
+	for _, sub := range subs {
+		if err = sub.Check(); err != nil {
+			return nil, errors.New(path + ": " + err.Error())
+		}
+		if seen[sub.ID] {
+			return nil, errors.New(path + ": subscription " + sub.ID + " is listed twice")
+		}
+		seen[sub.ID] = true
+	}
+	return subs, nil
+}
+// ============================================================================================================================
+// Check - whether a subscription can be served: an ID, a URL, a secret to sign with and the names of catalog events and entities
+// ============================================================================================================================
+func (sub *Subscription) Check() error {
+	if sub.ID == "" || sub.URL == "" || sub.Secret == "" {
+		return errors.New("a subscription needs an id, a url and a secret")
+	}
+	sub.matcher = nil
+	if len(sub.Events) > 0 {
+		filter, err := events.Filter(sub.Events...)
+		if err != nil {
+			return errors.New("subscription " + sub.ID + ": " + err.Error())
+		}
+		sub.matcher = regexp.MustCompile(filter)
+	}
+	for _, entity := range sub.Entities {
+		if len(events.Names(entity)) == 0 {
+			return errors.New("subscription " + sub.ID + ": " + entity + " is not an entity of the event catalog")
+		}
+	}
+	return nil
+}
+// ============================================================================================================================
+// Matches - whether a subscription is sent an event, of the given parties. Without an events filter it is sent every catalog
+// event, an errEvent only when it names it
+// ============================================================================================================================
+func (sub *Subscription) Matches(record Record, event events.Event, parties []string) bool {
+	if sub.matcher != nil {
+		if !sub.matcher.MatchString(record.Name) {
+			return false
+		}
+	}else if _, found := events.Lookup(record.Name); !found {
+		return false
+	}
+	if len(sub.Entities) > 0 && !contains(sub.Entities, event.EntityType) {
+		return false
+	}
+	if len(sub.Parties) == 0 {
+		return true
+	}
+	for _, party := range parties {
+		if contains(sub.Parties, party) {
+			return true
+		}
+	}
+	return false
+}
+
+// parties remembers the parties of every entity of the log, an event names only the fields it changed, so the parties of a
+// signature or a settlement are those of its entity's earlier events and of the entities it refers to
+type parties map[string][]string
+
+// ============================================================================================================================
+// add - the parties of an event, remembered for its entity: the party fields it sets, and the parties of its entity so far and
+// of the PO, Agreement, Payment or Shipment it refers to
+// ============================================================================================================================
+func (p parties) add(event events.Event) []string {
+	if event.EntityType == "" || event.EntityID == "" {
+		return nil
+	}
+	key := event.EntityType + "/" + event.EntityID
+	found := append([]string{}, p[key]...)
+	linked := []string{}
+	for _, change := range event.Changes {
+		value := ""
+		if json.Unmarshal(change.To, &value) != nil || value == "" {
+			continue
+		}
+		switch {
+		case contains(partyFields, change.Field):
+			found = appendNew(found, value)
+		case change.Field == "agreementId" && event.EntityType != "agreement":
+			linked = append(linked, "agreement/" + value)
+		case change.Field == "transId" && event.EntityType != "po":
+			linked = append(linked, "po/" + value)
+		}
+	}
+	switch event.EntityType {
+	case "escrow":
+		linked = append(linked, "payment/" + event.EntityID)
+	case "ebl":
+		if shipmentId, ok := event.Data["shipmentId"].(string); ok {
+			linked = append(linked, "shipment/" + shipmentId)
+		}
+	case "notification":
+		paymentIds, _ := event.Data["paymentIds"].([]interface{})
+		for _, paymentId := range paymentIds {
+			if id, ok := paymentId.(string); ok {
+				linked = append(linked, "payment/" + id)
+			}
+		}
+	}
+	for _, link := range linked {
+		for _, party := range p[link] {
+			found = appendNew(found, party)
+		}
+	}
+	p[key] = found
+	return found
+}
+// ============================================================================================================================
+// appendNew - add a value to a list unless it holds it already
+// ============================================================================================================================
+func appendNew(values []string, value string) []string {
+	if contains(values, value) {
+		return values
+	}
+	return append(values, value)
+}
+// ============================================================================================================================
+// contains - whether a list holds a value
+// ============================================================================================================================
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
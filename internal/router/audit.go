@@ -0,0 +1,92 @@
+package router
+
+import (
+"errors"
+"time"
+
+"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
+"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+// DateLayouts are the layouts of the business dates a caller supplies, e.g. an expected delivery date
+var DateLayouts = []string{"2006-01-02", time.RFC3339}
+
+// ============================================================================================================================
+// TxStamp - when and by whom a record is written: the time of the transaction as RFC 3339 in UTC, the same on every
+// endorsing peer, and its Caller. Unlike the dates in the arguments neither can be set by the caller
+// ============================================================================================================================
+func TxStamp(stub shim.ChaincodeStubInterface) (string, string, error) {
+	txTime, err := ledgerTime(stub)
+	if err != nil {
+		return "", "", err
+	}
+	return txTime.Format(time.RFC3339), Caller(stub), nil
+}
+// ============================================================================================================================
+// Caller - the identity that submitted the transaction, its MSP and the common name of its certificate, e.g.
+// Org1MSP:User1@org1.example.com. Empty when the transaction carries no identity, e.g. on a test ledger
+// ============================================================================================================================
+func Caller(stub shim.ChaincodeStubInterface) string {
+	identity, err := cid.New(stub)
+	if err != nil {
+		return ""
+	}
+	mspID, _ := identity.GetMSPID()
+	cert, _ := identity.GetX509Certificate()
+	if cert == nil {
+		return mspID
+	}
+	return mspID + ":" + cert.Subject.CommonName
+}
+// ============================================================================================================================
+// CheckNotPast - refuse a business date before the day of the transaction, e.g. an expected delivery date that has already
+// gone by when the record is created. field names the date in the error, an empty date is not checked
+// ============================================================================================================================
+func CheckNotPast(stub shim.ChaincodeStubInterface, field string, date string) error {
+	if date == "" {
+		return nil
+	}
+	var parsed time.Time
+	var err error
+	for _, layout := range DateLayouts {
+		if parsed, err = time.Parse(layout, date); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return errors.New(field + " must be a date like 2024-03-01, not " + date)
+	}
+	txTime, err := ledgerTime(stub)
+	if err != nil {
+		return err
+	}
+	today := txTime.Format("2006-01-02")
+	if parsed.UTC().Format("2006-01-02") < today {
+		return errors.New(field + " " + date + " is in the past, the ledger date is " + today)
+	}
+	return nil
+}
+// ============================================================================================================================
+// RecordDate - the date of a record being created, e.g. the PO date: the ledger date when date is empty, else date, refused
+// as CheckNotPast refuses it so a record can not be backdated
+// ============================================================================================================================
+func RecordDate(stub shim.ChaincodeStubInterface, field string, date string) (string, error) {
+	if date != "" {
+		return date, CheckNotPast(stub, field, date)
+	}
+	txTime, err := ledgerTime(stub)
+	if err != nil {
+		return "", err
+	}
+	return txTime.Format("2006-01-02"), nil
+}
+// ============================================================================================================================
+// ledgerTime - the time of the transaction in UTC
+// ============================================================================================================================
+func ledgerTime(stub shim.ChaincodeStubInterface) (time.Time, error) {
+	txTimestamp, err := stub.GetTxTimestamp()
+	if err != nil {
+		return time.Time{}, errors.New("Failed to get transaction timestamp")
+	}
+	return time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos)).UTC(), nil
+}
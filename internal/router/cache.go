@@ -0,0 +1,48 @@
+package router
+
+import (
+"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+// Cache is a stub that answers GetState with the value written through it earlier in the transaction, e.g. by an earlier
+// step of execute_batch or by a linked domain. A peer's GetState only returns the state committed before the transaction
+type Cache struct {
+	shim.ChaincodeStubInterface
+	writes map[string][]byte						// the value written for each key, nil when it was deleted
+}
+
+// ============================================================================================================================
+// NewCache - wrap a stub to read the writes of the current transaction back
+// ============================================================================================================================
+func NewCache(stub shim.ChaincodeStubInterface) *Cache {
+	return &Cache{ChaincodeStubInterface: stub, writes: map[string][]byte{}}
+}
+// ============================================================================================================================
+// GetState - the value written in this transaction, or the committed one when the key was not written
+// ============================================================================================================================
+func (c *Cache) GetState(key string) ([]byte, error) {
+	if value, found := c.writes[key]; found {
+		return value, nil
+	}
+	return c.ChaincodeStubInterface.GetState(key)
+}
+// ============================================================================================================================
+// PutState - write a key and keep its value for the reads that follow
+// ============================================================================================================================
+func (c *Cache) PutState(key string, value []byte) error {
+	if err := c.ChaincodeStubInterface.PutState(key, value); err != nil {
+		return err
+	}
+	c.writes[key] = value
+	return nil
+}
+// ============================================================================================================================
+// DelState - delete a key, the reads that follow find nothing
+// ============================================================================================================================
+func (c *Cache) DelState(key string) error {
+	if err := c.ChaincodeStubInterface.DelState(key); err != nil {
+		return err
+	}
+	c.writes[key] = nil
+	return nil
+}
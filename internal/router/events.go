@@ -0,0 +1,204 @@
+package router
+
+import (
+"bytes"
+"errors"
+"encoding/json"
+
+"github.com/hyperledger/fabric-chaincode-go/shim"
+"github.com/wipro-blockchain/TF-v1/events"
+)
+
+var LegacyEventsStr = "_LegacyEvents"		//name for the key/value that will store whether the events are emitted as evtsender
+
+// stampFields are the record fields every write sets, the event carries them itself rather than as changes
+var stampFields = []string{"version", "lastModifiedTxId", "createdAt", "createdBy", "updatedAt", "updatedBy"}
+
+// ============================================================================================================================
+// Emit - send the catalog event of a successful invoke. event names the type, the entity and its status, Emit adds the
+// transaction, its time and caller, and the fields that changed from before to after: the record as stored before and after
+// the invoke, as JSON bytes or a value to marshal, nil when there was none
+// ============================================================================================================================
+func Emit(stub shim.ChaincodeStubInterface, event events.Event, before interface{}, after interface{}) error {
+	t, found := events.Lookup(event.Type)
+	if !found {
+		return errors.New("router: " + event.Type + " is not an event of the catalog")
+	}
+	var err error
+	event.SchemaVersion, event.EntityType, event.TxID, event.Code = events.SchemaVersion, t.Entity, stub.GetTxID(), "200"
+	event.Timestamp, event.Actor, err = TxStamp(stub)
+	if err != nil {
+		return err
+	}
+	event.Changes, err = Diff(before, after)
+	if err != nil {
+		return err
+	}
+	if event.Version == 0 {
+		event.Version = recordVersion(after)
+	}
+	eventAsBytes, _ := json.Marshal(event)
+	legacyAsBytes, err := stub.GetState(LegacyEventsStr)
+	if err != nil {
+		return errors.New("Failed to get the legacy events setting")
+	}
+	if string(legacyAsBytes) != "true" {
+		return stub.SetEvent(event.Type, eventAsBytes)
+	}
+	payload := map[string]interface{}{}
+	json.Unmarshal(eventAsBytes, &payload)
+	if field, found := events.LegacyIDFields[event.EntityType]; found {
+		payload[field] = event.EntityID
+	}
+	eventAsBytes, _ = json.Marshal(payload)
+	return stub.SetEvent(events.LegacyName, eventAsBytes)
+}
+// ============================================================================================================================
+// set_legacy_events - emit the events under their former name evtsender, "true", or under their catalog names, "false".
+// The admin turns them on for the listeners not yet moved to the catalog names and off once they all have
+// ============================================================================================================================
+func (r *Router) set_legacy_events(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var err error
+	if len(args) != 1 || (args[0] != "true" && args[0] != "false") {
+		return nil, ErrorEvent(stub, errors.New("Incorrect number of arguments. Expecting \"true\" or \"false\" as an argument."))
+	}
+	if err = CheckAdmin(stub); err != nil {
+		return nil, ErrorEvent(stub, err)
+	}
+	previousAsBytes, err := stub.GetState(LegacyEventsStr)
+	if err != nil {
+		return nil, errors.New("Failed to get the legacy events setting")
+	}
+	previous := map[string]bool{"legacyEvents": string(previousAsBytes) == "true"}
+	err = stub.PutState(LegacyEventsStr, []byte(args[0]))
+	if err != nil {
+		return nil, err
+	}
+	err = Emit(stub, events.Event{Type: events.ChaincodeLegacyEventsSet, EntityID: r.name, Status: args[0],
+		Message: "Legacy events set to " + args[0]}, previous, map[string]bool{"legacyEvents": args[0] == "true"})
+	if err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+// ============================================================================================================================
+// get_event_catalog - the catalog of the events, their names and entities, with the JSON Schema of their payload
+// ============================================================================================================================
+func (r *Router) get_event_catalog(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	catalog := struct {
+		SchemaVersion string `json:"schemaVersion"`
+		Events []events.Type `json:"events"`
+		Schema json.RawMessage `json:"schema"`
+	}{events.SchemaVersion, events.Catalog, events.Schema()}
+	catalogAsBytes, _ := json.Marshal(catalog)
+	return catalogAsBytes, nil
+}
+// ============================================================================================================================
+// Diff - the top-level fields of a record that differ between before and after, in the order of after, then the fields only
+// before has. The fields every write stamps are left out
+// ============================================================================================================================
+func Diff(before interface{}, after interface{}) ([]events.Change, error) {
+	beforeFields, beforeOrder, err := fields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, afterOrder, err := fields(after)
+	if err != nil {
+		return nil, err
+	}
+	changes := []events.Change{}
+	for _, field := range append(afterOrder, beforeOrder...) {
+		if contains(stampFields, field) {
+			continue
+		}
+		from, to := beforeFields[field], afterFields[field]
+		if from == nil && to == nil {
+			continue										//a field only before has, already compared
+		}
+		if !bytes.Equal(from, to) {
+			changes = append(changes, events.Change{Field: field, From: orNull(from), To: orNull(to)})
+		}
+		delete(beforeFields, field)
+		delete(afterFields, field)
+	}
+	return changes, nil
+}
+// ============================================================================================================================
+// fields - the compacted top-level fields of a record and their order, none for nil
+// ============================================================================================================================
+func fields(record interface{}) (map[string]json.RawMessage, []string, error) {
+	var recordAsBytes []byte
+	switch r := record.(type) {
+	case nil:
+		return map[string]json.RawMessage{}, nil, nil
+	case []byte:
+		recordAsBytes = r
+	case string:
+		recordAsBytes = []byte(r)
+	default:
+		recordAsBytes, _ = json.Marshal(r)
+	}
+	if len(recordAsBytes) == 0 {
+		return map[string]json.RawMessage{}, nil, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(recordAsBytes))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return nil, nil, errors.New("router: the record of an event is not a JSON object")
+	}
+	found := map[string]json.RawMessage{}
+	order := []string{}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+		field, _ := token.(string)
+		var value json.RawMessage
+		if err = decoder.Decode(&value); err != nil {
+			return nil, nil, err
+		}
+		compacted := bytes.Buffer{}
+		json.Compact(&compacted, value)
+		found[field] = compacted.Bytes()
+		order = append(order, field)
+	}
+	return found, order, nil
+}
+// ============================================================================================================================
+// recordVersion - the version field of a record, 0 when it has none
+// ============================================================================================================================
+func recordVersion(record interface{}) int {
+	found, _, err := fields(record)
+	if err != nil {
+		return 0
+	}
+	version := 0
+	json.Unmarshal(found["version"], &version)
+	return version
+}
+// ============================================================================================================================
+// orNull - a JSON value, null when there is none
+// ============================================================================================================================
+func orNull(value json.RawMessage) json.RawMessage {
+	if value == nil {
+		return json.RawMessage("null")
+	}
+	return value
+}
+// ============================================================================================================================
+// contains - whether a list holds a value
+// ============================================================================================================================
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+// ============================================================================================================================
+// Signed - whether a sign field went from unsigned to "true", an update doing so for any party emits a signed event
+// ============================================================================================================================
+func Signed(before string, after string) bool {
+	return before != "true" && after == "true"
+}
@@ -0,0 +1,151 @@
+package router
+
+import (
+"crypto/sha256"
+"encoding/hex"
+"fmt"
+"strings"
+"encoding/json"
+
+"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+// Outcome is the stored result of an invoke run by execute_once, replayed when its idempotency key is used again
+type Outcome struct {
+	Key string `json:"key"`
+	Function string `json:"function"`
+	Fingerprint string `json:"fingerprint"`				// SHA-256 of the function and its arguments
+	TxID string `json:"txId"`							// the transaction that ran the invoke
+	Response string `json:"response"`
+	EventName string `json:"eventName"`					// the last event, the one a peer emits
+	EventPayload string `json:"eventPayload"`
+}
+
+// ============================================================================================================================
+// outcomeKey - key under which the outcome of an idempotency key is stored
+// ============================================================================================================================
+func outcomeKey(key string) string {
+	return "Idempotency_" + key
+}
+// ============================================================================================================================
+// fingerprint - a digest of an invoke, the same function and arguments always give the same one
+// ============================================================================================================================
+func fingerprint(function string, args []string) string {
+	invokeAsBytes, _ := json.Marshal(append([]string{function}, args...))
+	sum := sha256.Sum256(invokeAsBytes)
+	return hex.EncodeToString(sum[:])
+}
+// ============================================================================================================================
+// execute_once - run an invoke under an idempotency key. The first call to succeed stores its outcome; a retry with the same
+// key and arguments gets that outcome again without running the invoke, so a payment is not created or settled twice. An
+// errEvent is not stored, the contract API and the gateway reject its transaction, so a retry runs the invoke again. Reusing
+// a key for another invoke is refused.
+// ============================================================================================================================
+func (r *Router) execute_once(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	// execute_once("idempotencyKey", "createPayment", "PAY1", "AGR1", ...)
+	var err error
+	if len(args) < 2 || strings.TrimSpace(args[0]) == "" {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting an idempotency key, a function and its arguments.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+	key, function := args[0], args[1]
+	h, found := r.invokes[function]
+	if !found || function == "execute_once" {
+		errMsg := "{ \"message\" : " + JSONString(function + " is not an invoke function of " + r.name) + ", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+	Println("start execute_once " + key)
+	digest := fingerprint(function, args[2:])
+	outcomeAsBytes, err := stub.GetState(outcomeKey(key))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get the outcome of idempotency key %s", key)
+	}
+	if len(outcomeAsBytes) > 0 {
+		outcome := Outcome{}
+		if err = json.Unmarshal(outcomeAsBytes, &outcome); err != nil {
+			return nil, fmt.Errorf("Malformed outcome of idempotency key %s", key)
+		}
+		if outcome.Fingerprint != digest {
+			errMsg := "{ \"message\" : " + JSONString("Idempotency key " + key + " was used by transaction " + outcome.TxID + " for " +
+				outcome.Function + " with other arguments, use a new key for a new invoke") + ", \"code\" : \"422\"}"
+			err = stub.SetEvent("errEvent", []byte(errMsg))
+			if err != nil {
+				return nil, err
+			}
+			return nil, nil
+		}
+		Println("replaying the outcome of transaction " + outcome.TxID)
+		if outcome.EventName != "" {
+			err = stub.SetEvent(outcome.EventName, []byte(outcome.EventPayload))
+			if err != nil {
+				return nil, err
+			}
+		}
+		return []byte(outcome.Response), nil
+	}
+
+	recorder := NewRecorder(stub)
+	valAsBytes, err := h(recorder, args[2:])
+	if err != nil {
+		return nil, err										//the transaction is rejected, a retry runs the invoke again
+	}
+	if recorder.Failure() != nil {
+		Println("end execute_once, the refusal is not stored")
+		return valAsBytes, recorder.Replay(stub)
+	}
+	outcome := Outcome{Key: key, Function: function, Fingerprint: digest, TxID: stub.GetTxID(), Response: string(valAsBytes)}
+	if len(recorder.Events) > 0 {
+		last := recorder.Events[len(recorder.Events)-1]
+		outcome.EventName, outcome.EventPayload = last.Name, string(last.Payload)
+	}
+	outcomeAsBytes, _ = json.Marshal(outcome)
+	err = stub.PutState(outcomeKey(key), outcomeAsBytes)
+	if err != nil {
+		return nil, err
+	}
+	err = recorder.Replay(stub)
+	if err != nil {
+		return nil, err
+	}
+	Println("end execute_once")
+	return valAsBytes, nil
+}
+// ============================================================================================================================
+// get_idempotency_outcome - the stored outcome of an idempotency key, empty when no invoke ran under it. The optional second
+// argument is a function: the outcome is then returned only when the key was used for that function, and a network of several
+// chaincodes asks the one owning it
+// ============================================================================================================================
+func (r *Router) get_idempotency_outcome(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	// get_idempotency_outcome("idempotencyKey") or get_idempotency_outcome("idempotencyKey", "updatePayment")
+	var err error
+	if len(args) != 1 && len(args) != 2 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting the idempotency key and optionally its function as arguments.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+	outcomeAsBytes, err := stub.GetState(outcomeKey(args[0]))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get the outcome of idempotency key %s", args[0])
+	}
+	if len(args) == 2 && len(outcomeAsBytes) > 0 {
+		outcome := Outcome{}
+		if err = json.Unmarshal(outcomeAsBytes, &outcome); err != nil {
+			return nil, fmt.Errorf("Malformed outcome of idempotency key %s", args[0])
+		}
+		if outcome.Function != args[1] {
+			return nil, nil
+		}
+	}
+	return outcomeAsBytes, nil
+}
@@ -0,0 +1,151 @@
+package router
+
+import (
+"errors"
+"strings"
+"encoding/json"
+
+"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
+"github.com/hyperledger/fabric-chaincode-go/shim"
+"github.com/wipro-blockchain/TF-v1/events"
+)
+
+var AdminStr = "_Admin"		//name for the key/value that will store the MSP administering the chaincode
+var PartyDirectoryStr = "_PartyDirectory"		//name for the key/value that will store the identity of each trade party
+
+// admin is the MSP administering a chaincode, the MSP of the identity that deployed it
+type admin struct {
+	MSPID string `json:"mspId"`
+}
+
+// ============================================================================================================================
+// recordAdmin - on the first init, record the MSP of the caller as the admin MSP. A later init, a reset, is refused to the
+// other MSPs and keeps the admin. The chaincode is deployed with --init-required, so the first init is the deployer's
+// ============================================================================================================================
+func recordAdmin(stub shim.ChaincodeStubInterface) error {
+	adminAsBytes, err := stub.GetState(AdminStr)
+	if err != nil {
+		return errors.New("Failed to get Admin")
+	}
+	if adminAsBytes != nil {
+		return CheckAdmin(stub)
+	}
+	adminAsBytes, _ = json.Marshal(admin{MSPID: callerMSP(stub)})
+	return stub.PutState(AdminStr, adminAsBytes)
+}
+// ============================================================================================================================
+// CheckAdmin - refuse a caller outside the admin MSP, e.g. for register_chaincode. A chaincode without an admin, deployed
+// before one was recorded, is administered by no one until its next init
+// ============================================================================================================================
+func CheckAdmin(stub shim.ChaincodeStubInterface) error {
+	adminAsBytes, err := stub.GetState(AdminStr)
+	if err != nil {
+		return errors.New("Failed to get Admin")
+	}
+	if adminAsBytes == nil {
+		return errors.New("No admin MSP is recorded for this chaincode, run init")
+	}
+	res := admin{}
+	json.Unmarshal(adminAsBytes, &res)
+	if callerMSP(stub) != res.MSPID {
+		return errors.New("Only the admin MSP " + res.MSPID + " can do this, not " + Caller(stub))
+	}
+	return nil
+}
+// ============================================================================================================================
+// callerMSP - the MSP of the identity that submitted the transaction, empty when it carries none, e.g. on a test ledger
+// ============================================================================================================================
+func callerMSP(stub shim.ChaincodeStubInterface) string {
+	identity, err := cid.New(stub)
+	if err != nil {
+		return ""
+	}
+	mspID, _ := identity.GetMSPID()
+	return mspID
+}
+// ============================================================================================================================
+// register_party - record the identity acting for a trade party, e.g. register_party("Sellbank", "SellbankMSP") for any member
+// of the MSP or register_party("Buyerco", "BuyerMSP:buyer-admin") for one certificate. Only the admin MSP can; a party the
+// records name, e.g. the holder of a bill of lading, is then checked against the caller
+// ============================================================================================================================
+func (r *Router) register_party(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var err error
+	if len(args) != 2 || args[0] == "" || args[1] == "" {
+		return nil, ErrorEvent(stub, errors.New("Incorrect number of arguments. Expecting \"party\" and \"identity\" as arguments."))
+	}
+	if err = CheckAdmin(stub); err != nil {
+		return nil, ErrorEvent(stub, err)
+	}
+	directory, err := parties(stub)
+	if err != nil {
+		return nil, err
+	}
+	previous := map[string]string{}
+	if identity, found := directory[args[0]]; found {
+		previous[args[0]] = identity
+	}
+	directory[args[0]] = args[1]
+	directoryAsBytes, _ := json.Marshal(directory)
+	err = stub.PutState(PartyDirectoryStr, directoryAsBytes)
+	if err != nil {
+		return nil, err
+	}
+	err = Emit(stub, events.Event{Type: events.PartyRegistered, EntityID: args[0], Status: args[1],
+		Message: "Party registered succcessfully"}, previous, map[string]string{args[0]: args[1]})
+	if err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+// ============================================================================================================================
+// get_parties - the identity of each registered trade party
+// ============================================================================================================================
+func (r *Router) get_parties(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	directory, err := parties(stub)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(directory)
+}
+// ============================================================================================================================
+// parties - the party directory, the identity of each party by name
+// ============================================================================================================================
+func parties(stub shim.ChaincodeStubInterface) (map[string]string, error) {
+	directory := map[string]string{}
+	directoryAsBytes, err := stub.GetState(PartyDirectoryStr)
+	if err != nil {
+		return nil, errors.New("Failed to get Party directory")
+	}
+	json.Unmarshal(directoryAsBytes, &directory)
+	return directory, nil
+}
+// ============================================================================================================================
+// CheckParty - refuse a caller that does not act for the party: its identity must be the one registered for the party, the
+// whole MSP or one certificate of it. A party without a registered identity can not act
+// ============================================================================================================================
+func CheckParty(stub shim.ChaincodeStubInterface, party string) error {
+	directory, err := parties(stub)
+	if err != nil {
+		return err
+	}
+	identity, found := directory[party]
+	if !found {
+		return errors.New(party + " has no registered identity, the admin registers it with register_party")
+	}
+	caller := Caller(stub)
+	if identity != caller && identity != callerMSP(stub) {
+		return errors.New("The caller " + caller + " does not act for " + party)
+	}
+	return nil
+}
+// ============================================================================================================================
+// ActingParty - the first of the parties the caller acts for, an error when it acts for none of them
+// ============================================================================================================================
+func ActingParty(stub shim.ChaincodeStubInterface, candidates ...string) (string, error) {
+	for _, party := range candidates {
+		if party != "" && CheckParty(stub, party) == nil {
+			return party, nil
+		}
+	}
+	return "", errors.New("The caller " + Caller(stub) + " acts for none of " + strings.Join(candidates, ", "))
+}
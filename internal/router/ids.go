@@ -0,0 +1,32 @@
+package router
+
+import (
+"crypto/sha256"
+"encoding/hex"
+"fmt"
+"strconv"
+"strings"
+
+"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+// ============================================================================================================================
+// NewID - the ID of a record created without one: the type prefix, e.g. PO-, and a digest of the transaction ID, so every
+// peer endorsing the transaction derives the same ID. key is the state key of a record of the type; an ID already taken,
+// e.g. by a record created earlier in the same batch, gets a -2, -3, ... suffix
+// ============================================================================================================================
+func NewID(stub shim.ChaincodeStubInterface, prefix string, key func(string) string) (string, error) {
+	sum := sha256.Sum256([]byte(stub.GetTxID()))
+	base := prefix + strings.ToUpper(hex.EncodeToString(sum[:8]))
+	id := base
+	for n := 2; ; n++ {
+		valAsBytes, err := stub.GetState(key(id))
+		if err != nil {
+			return "", fmt.Errorf("Failed to get state for %s", id)
+		}
+		if len(valAsBytes) == 0 {
+			return id, nil
+		}
+		id = base + "-" + strconv.Itoa(n)
+	}
+}
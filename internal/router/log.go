@@ -0,0 +1,30 @@
+package router
+
+import (
+"fmt"
+"io"
+"os"
+)
+
+// Output is where the chaincodes print their progress, standard output as on a peer. The programs running them on the
+// mock ledger set it to io.Discard unless asked to show it, and keep their own standard output
+var Output io.Writer = os.Stdout
+
+// ============================================================================================================================
+// Println - print the progress of a chaincode function on Output, as fmt.Println
+// ============================================================================================================================
+func Println(a ...interface{}) {
+	fmt.Fprintln(Output, a...)
+}
+// ============================================================================================================================
+// Print - print the progress of a chaincode function on Output, as fmt.Print
+// ============================================================================================================================
+func Print(a ...interface{}) {
+	fmt.Fprint(Output, a...)
+}
+// ============================================================================================================================
+// Printf - print the progress of a chaincode function on Output, as fmt.Printf
+// ============================================================================================================================
+func Printf(format string, a ...interface{}) {
+	fmt.Fprintf(Output, format, a...)
+}
@@ -0,0 +1,88 @@
+package router
+
+import (
+"errors"
+"strconv"
+"encoding/json"
+
+"github.com/hyperledger/fabric-chaincode-go/shim"
+"github.com/wipro-blockchain/TF-v1/events"
+)
+
+// KeyMove is a record type stored under a namespaced key, e.g. PO_<transId>, whose records were stored under their bare
+// ID before: Index is the state key of the list of their IDs and Key the namespaced key of an ID
+type KeyMove struct {
+	Index string
+	Key func(id string) string
+}
+
+// Migrator is a Domain with records to move to their namespaced keys, see migrate_keys
+type Migrator interface {
+	KeyMoves() []KeyMove
+}
+
+// ============================================================================================================================
+// migrate_keys - move the records of every domain from their bare ID to their namespaced key, run once by the admin after
+// an upgrade from the former layout. A record already under its namespaced key is left as it is, so a second run moves
+// nothing. Every bare key is deleted after all the records are copied, as two types could list the same ID
+// ============================================================================================================================
+func (r *Router) migrate_keys(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var err error
+	if len(args) != 0 {
+		return nil, ErrorEvent(stub, errors.New("Incorrect number of arguments. Expecting no arguments."))
+	}
+	if err = CheckAdmin(stub); err != nil {
+		return nil, ErrorEvent(stub, err)
+	}
+	moved := map[string]interface{}{}
+	var bareKeys []string
+	for _, role := range r.roles {
+		migrator, ok := r.domains[role].(Migrator)
+		if !ok {
+			continue
+		}
+		for _, move := range migrator.KeyMoves() {
+			var index []string
+			indexAsBytes, err := stub.GetState(move.Index)
+			if err != nil {
+				return nil, errors.New("Failed to get state for " + move.Index)
+			}
+			json.Unmarshal(indexAsBytes, &index)
+			for _, id := range index {
+				valAsBytes, err := stub.GetState(move.Key(id))
+				if err != nil {
+					return nil, errors.New("Failed to get state for " + id)
+				}
+				if len(valAsBytes) != 0 {
+					continue										//already under its namespaced key
+				}
+				valAsBytes, err = stub.GetState(id)
+				if err != nil {
+					return nil, errors.New("Failed to get state for " + id)
+				}
+				if len(valAsBytes) == 0 {
+					continue										//listed but deleted
+				}
+				err = stub.PutState(move.Key(id), valAsBytes)
+				if err != nil {
+					return nil, err
+				}
+				bareKeys = append(bareKeys, id)
+				moved[move.Key(id)] = id
+			}
+		}
+	}
+	for _, id := range bareKeys {
+		err = stub.DelState(id)
+		if err != nil {
+			return nil, err
+		}
+	}
+	Println("migrate_keys moved " + strconv.Itoa(len(moved)) + " records")
+	err = Emit(stub, events.Event{Type: events.ChaincodeKeysMigrated, EntityID: r.name,
+		Message: strconv.Itoa(len(moved)) + " records moved to their namespaced keys", Data: map[string]interface{}{"moved": moved}}, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
@@ -0,0 +1,130 @@
+package router
+
+import (
+"bytes"
+"errors"
+"sort"
+"strings"
+"sync"
+"encoding/json"
+
+"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+// ============================================================================================================================
+// Named - a create or update function that also takes its arguments as one JSON object, e.g.
+// update_po("{\"transId\": \"PO1\", \"seller_sign\": \"true\"}"). fields are the JSON names of the positional arguments in
+// order, the first names the record. An update gives the query reading the current record: the object is a JSON Merge
+// Patch, a field not supplied keeps its value and null clears it. A create gives nil, a field not supplied is empty and the
+// create generates the ID when it is not supplied.
+// Unknown fields are refused and the positional arguments keep working while they are deprecated, which is logged on the
+// first positional call only.
+// ============================================================================================================================
+func Named(h Handler, function string, fields []string, current Handler) Handler {
+	var deprecated sync.Once
+	return func(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+		if len(args) != 1 || !strings.HasPrefix(strings.TrimSpace(args[0]), "{") {
+			deprecated.Do(func() {
+				Println(function + ": positional arguments are deprecated, pass one JSON object of named fields")
+			})
+			return h(stub, args)
+		}
+		positional, err := namedArgs(stub, args[0], fields, current)
+		if err != nil {
+			errMsg := "{ \"message\" : " + JSONString(function + ": " + err.Error()) + ", \"code\" : \"503\"}"
+			err = stub.SetEvent("errEvent", []byte(errMsg))
+			if err != nil {
+				return nil, err
+			}
+			return nil, nil
+		}
+		return h(stub, positional)
+	}
+}
+// ============================================================================================================================
+// namedArgs - the positional arguments of a JSON object of named fields, merged onto the current record of an update
+// ============================================================================================================================
+func namedArgs(stub shim.ChaincodeStubInterface, object string, fields []string, current Handler) ([]string, error) {
+	patch := map[string]json.RawMessage{}
+	decoder := json.NewDecoder(strings.NewReader(object))
+	decoder.UseNumber()
+	if err := decoder.Decode(&patch); err != nil {
+		return nil, errors.New("malformed JSON object: " + err.Error())
+	}
+	known := map[string]bool{}
+	for _, field := range fields {
+		known[field] = true
+	}
+	var unknown []string
+	for field := range patch {
+		if !known[field] {
+			unknown = append(unknown, field)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, errors.New("unknown field(s) " + strings.Join(unknown, ", ") + ", expecting " + strings.Join(fields, ", "))
+	}
+	values := map[string]string{}
+	id, err := fieldValue(fields[0], patch[fields[0]])
+	if err != nil {
+		return nil, err
+	}
+	if id == "" && current != nil {
+		return nil, errors.New(fields[0] + " is required")
+	}
+	if current != nil {
+		//read through a recorder, an errEvent of the query must not become the event of the update
+		valAsBytes, err := current(NewRecorder(stub), []string{id})
+		if err != nil {
+			return nil, err
+		}
+		record := map[string]json.RawMessage{}
+		if len(bytes.TrimSpace(valAsBytes)) > 0 && json.Unmarshal(valAsBytes, &record) == nil {
+			for _, field := range fields {
+				if value, err := fieldValue(field, record[field]); err == nil {
+					values[field] = value
+				}
+			}
+		}
+	}
+	for field, raw := range patch {
+		value, err := fieldValue(field, raw)
+		if err != nil {
+			return nil, err
+		}
+		values[field] = value
+	}
+	args := make([]string, len(fields))
+	for i, field := range fields {
+		args[i] = values[field]
+	}
+	return args, nil
+}
+// ============================================================================================================================
+// fieldValue - the argument of a field: a string as is, a number or boolean as written, null or missing as empty
+// ============================================================================================================================
+func fieldValue(field string, raw json.RawMessage) (string, error) {
+	trimmed := strings.TrimSpace(string(raw))
+	if trimmed == "" || trimmed == "null" {
+		return "", nil
+	}
+	if strings.HasPrefix(trimmed, "\"") {
+		var value string
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return "", errors.New(field + " is not a valid string")
+		}
+		return value, nil
+	}
+	if strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+		return "", errors.New(field + " must be a string")
+	}
+	return trimmed, nil
+}
+// ============================================================================================================================
+// JSONString - s as a JSON string, quoted and escaped, for a record or payload built by hand
+// ============================================================================================================================
+func JSONString(s string) string {
+	valAsBytes, _ := json.Marshal(s)
+	return string(valAsBytes)
+}
@@ -0,0 +1,76 @@
+package router
+
+import (
+"errors"
+"encoding/json"
+
+"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+// Event is an event set by a chaincode function
+type Event struct {
+	Name string
+	Payload []byte
+}
+
+// Recorder is a stub that keeps the events set through it instead of sending them, every other call goes to the wrapped stub
+type Recorder struct {
+	shim.ChaincodeStubInterface
+	Events []Event
+}
+
+// ============================================================================================================================
+// NewRecorder - wrap a stub to record its events
+// ============================================================================================================================
+func NewRecorder(stub shim.ChaincodeStubInterface) *Recorder {
+	return &Recorder{ChaincodeStubInterface: stub}
+}
+// ============================================================================================================================
+// SetEvent - record the event
+// ============================================================================================================================
+func (r *Recorder) SetEvent(name string, payload []byte) error {
+	r.Events = append(r.Events, Event{Name: name, Payload: payload})
+	return nil
+}
+// ============================================================================================================================
+// Failure - the message of the last event as an error when it is an errEvent, Fabric keeps only the last event of a transaction
+// ============================================================================================================================
+func (r *Recorder) Failure() error {
+	if len(r.Events) == 0 || r.Events[len(r.Events)-1].Name != "errEvent" {
+		return nil
+	}
+	payload := r.Events[len(r.Events)-1].Payload
+	errMsg := struct {
+		Message string `json:"message"`
+	}{}
+	if json.Unmarshal(payload, &errMsg) != nil || errMsg.Message == "" {
+		return errors.New(string(payload))
+	}
+	return errors.New(errMsg.Message)
+}
+// ============================================================================================================================
+// LastPayload - payload of the last recorded event as JSON, null when there is none
+// ============================================================================================================================
+func (r *Recorder) LastPayload() json.RawMessage {
+	if len(r.Events) == 0 {
+		return json.RawMessage("null")
+	}
+	payload := r.Events[len(r.Events)-1].Payload
+	var v interface{}
+	if json.Unmarshal(payload, &v) != nil {
+		payload, _ = json.Marshal(string(payload))
+	}
+	return json.RawMessage(payload)
+}
+// ============================================================================================================================
+// Replay - send the recorded events on the wrapped stub
+// ============================================================================================================================
+func (r *Recorder) Replay(stub shim.ChaincodeStubInterface) error {
+	for _, event := range r.Events {
+		err := stub.SetEvent(event.Name, event.Payload)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package router
+
+import (
+"errors"
+"encoding/json"
+
+"github.com/golang/protobuf/proto"
+"github.com/hyperledger/fabric-chaincode-go/shim"
+"github.com/hyperledger/fabric-protos-go/common"
+pb "github.com/hyperledger/fabric-protos-go/peer"
+"github.com/wipro-blockchain/TF-v1/events"
+)
+
+var ChaincodeRegistryStr = "_ChaincodeRegistry"		//name for the key/value that will store the deployed names of the other chaincodes
+
+// ============================================================================================================================
+// register_chaincode - record the deployed name of another chaincode, e.g. register_chaincode("agreement", "<name>"). Only the
+// admin MSP can, the other chaincodes trust what the registered one answers
+// ============================================================================================================================
+func (r *Router) register_chaincode(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var err error
+	if len(args) != 2 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting \"role\" and \"chaincodeName\" as arguments.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+	if err = CheckAdmin(stub); err != nil {
+		return nil, ErrorEvent(stub, err)
+	}
+	registry := map[string]string{}
+	registryAsBytes, err := stub.GetState(ChaincodeRegistryStr)
+	if err != nil {
+		return nil, errors.New("Failed to get Chaincode registry")
+	}
+	json.Unmarshal(registryAsBytes, &registry)
+	previous := map[string]string{}
+	if name, found := registry[args[0]]; found {
+		previous[args[0]] = name
+	}
+	registry[args[0]] = args[1]
+	registryAsBytes, _ = json.Marshal(registry)
+	err = stub.PutState(ChaincodeRegistryStr, registryAsBytes)
+	if err != nil {
+		return nil, err
+	}
+	err = Emit(stub, events.Event{Type: events.ChaincodeRegistered, EntityID: args[1], Status: args[0],
+		Message: "Chaincode registered succcessfully"}, previous, map[string]string{args[0]: args[1]})
+	if err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+// ============================================================================================================================
+// chaincodeName - the registered deployed name of another chaincode
+// ============================================================================================================================
+func chaincodeName(stub shim.ChaincodeStubInterface, role string) (string, error) {
+	registry := map[string]string{}
+	registryAsBytes, err := stub.GetState(ChaincodeRegistryStr)
+	if err != nil {
+		return "", errors.New("Failed to get Chaincode registry")
+	}
+	json.Unmarshal(registryAsBytes, &registry)
+	if registry[role] == "" {
+		return "", errors.New("The " + role + " chaincode is not registered")
+	}
+	return registry[role], nil
+}
+// ============================================================================================================================
+// invokeChaincode - call a function of a registered chaincode on the same channel, a failed response becomes an error
+// ============================================================================================================================
+func invokeChaincode(stub shim.ChaincodeStubInterface, role string, function string, args []string) ([]byte, error) {
+	ccName, err := chaincodeName(stub, role)
+	if err != nil {
+		return nil, err
+	}
+	response := stub.InvokeChaincode(ccName, chaincodeArgs(function, args...), "")
+	if response.Status != shim.OK {
+		return nil, errors.New(response.Message)
+	}
+	return response.Payload, nil
+}
+// ============================================================================================================================
+// CheckLinked - refuse a caller other than the domain of role: another domain of this chaincode, or the registered chaincode
+// of role when the transaction was submitted to that chaincode, which then invoked this one
+// ============================================================================================================================
+func CheckLinked(stub shim.ChaincodeStubInterface, role string) error {
+	if _, linked := stub.(*linkedStub); linked {
+		return nil
+	}
+	ccName, err := chaincodeName(stub, role)
+	if err != nil {
+		return errors.New("Only the " + role + " chaincode can do this")
+	}
+	if entryChaincode(stub) != ccName {
+		return errors.New("Only the " + role + " chaincode " + ccName + " can do this")
+	}
+	return nil
+}
+// ============================================================================================================================
+// entryChaincode - the chaincode the transaction was submitted to, read from the signed proposal; empty when it can not be read
+// ============================================================================================================================
+func entryChaincode(stub shim.ChaincodeStubInterface) string {
+	signedProposal, err := stub.GetSignedProposal()
+	if err != nil || signedProposal == nil {
+		return ""
+	}
+	proposal := &pb.Proposal{}
+	header := &common.Header{}
+	channelHeader := &common.ChannelHeader{}
+	extension := &pb.ChaincodeHeaderExtension{}
+	if proto.Unmarshal(signedProposal.ProposalBytes, proposal) != nil || proto.Unmarshal(proposal.Header, header) != nil ||
+		proto.Unmarshal(header.ChannelHeader, channelHeader) != nil || proto.Unmarshal(channelHeader.Extension, extension) != nil ||
+		extension.ChaincodeId == nil {
+		return ""
+	}
+	return extension.ChaincodeId.Name
+}
+// ============================================================================================================================
+// chaincodeArgs - function name and arguments in the form expected by InvokeChaincode
+// ============================================================================================================================
+func chaincodeArgs(function string, args ...string) [][]byte {
+	ccArgs := [][]byte{[]byte(function)}
+	for _, arg := range args {
+		ccArgs = append(ccArgs, []byte(arg))
+	}
+	return ccArgs
+}
@@ -0,0 +1,326 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements.  See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership.  The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License.  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+// Package router dispatches the trade-finance functions by their original names, e.g. create_po or
+// getAgreement_byID. Each domain (po, agreement, payment, shipment) registers its functions with a
+// Router; the contracts package serves a Router with one domain for a single-domain chaincode or with
+// all four for TradeFinance, both as typed contract methods and under the original names.
+package router
+
+import (
+"errors"
+"fmt"
+"sort"
+"encoding/json"
+
+"github.com/hyperledger/fabric-chaincode-go/shim"
+"github.com/wipro-blockchain/TF-v1/events"
+)
+
+// ApprovedStatus is the status of an Agreement every party signed, the other records of a trade need it
+const ApprovedStatus = "Approved By Seller Bank"
+
+// Handler runs one invoke or query function of a domain
+type Handler func(stub shim.ChaincodeStubInterface, args []string) ([]byte, error)
+
+// Domain is the set of functions of one part of the trade, e.g. the POs or the payments
+type Domain interface {
+	Init(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error)
+	Invokes() map[string]Handler
+	Queries() map[string]Handler
+}
+
+// Linker reaches the functions of another domain by role: "po", "agreement", "payment" or "shipment"
+type Linker interface {
+	Linked(stub shim.ChaincodeStubInterface, role string) bool
+	InvokeLinked(stub shim.ChaincodeStubInterface, role string, function string, args ...string) ([]byte, error)
+	QueryLinked(stub shim.ChaincodeStubInterface, role string, function string, args ...string) ([]byte, error)
+}
+
+// Router dispatches the functions of its domains and links them to each other
+type Router struct {
+	name string										// chaincode name used in messages, e.g. "ManagePO"
+	roles []string
+	domains map[string]Domain
+	invokes map[string]Handler
+	queries map[string]Handler
+	linkedInvokes map[string]map[string]Handler
+	linkedQueries map[string]map[string]Handler
+}
+
+// ============================================================================================================================
+// New - create a Router with no domains, register them with Register
+// ============================================================================================================================
+func New(name string) *Router {
+	r := &Router{
+		name: name,
+		domains: map[string]Domain{},
+		invokes: map[string]Handler{},
+		queries: map[string]Handler{},
+		linkedInvokes: map[string]map[string]Handler{},
+		linkedQueries: map[string]map[string]Handler{},
+	}
+	r.invokes["register_chaincode"] = r.register_chaincode			//record the deployed name of another chaincode
+	r.invokes["execute_batch"] = r.execute_batch						//run several invokes as one transaction
+	r.invokes["execute_once"] = r.execute_once						//run an invoke under an idempotency key
+	r.invokes["register_party"] = r.register_party					//record the identity acting for a trade party
+	r.invokes["set_legacy_events"] = r.set_legacy_events				//emit the events under their former name evtsender while listeners move
+	r.invokes["migrate_keys"] = r.migrate_keys						//move the records of the former key layout to their namespaced keys
+	r.queries["get_idempotency_outcome"] = r.get_idempotency_outcome	//the outcome stored by execute_once
+	r.queries["get_event_catalog"] = r.get_event_catalog				//the events this chaincode emits and their schema
+	r.queries["get_parties"] = r.get_parties							//the identity of each registered trade party
+	return r
+}
+// ============================================================================================================================
+// Register - add the functions of a domain under its role, function names must be unique across domains
+// ============================================================================================================================
+func (r *Router) Register(role string, d Domain) {
+	invokes := d.Invokes()
+	queries := d.Queries()
+	for function, h := range invokes {
+		if _, found := r.invokes[function]; found || function == "init" {
+			panic("router: invoke function " + function + " of " + role + " is already registered")
+		}
+		r.invokes[function] = h
+	}
+	for function, h := range queries {
+		if _, found := r.queries[function]; found {
+			panic("router: query function " + function + " of " + role + " is already registered")
+		}
+		r.queries[function] = h
+	}
+	r.roles = append(r.roles, role)
+	r.domains[role] = d
+	r.linkedInvokes[role] = invokes
+	r.linkedQueries[role] = queries
+}
+// ============================================================================================================================
+// Init - initialize the state of every domain, the first error stops the deployment. The first init records the admin MSP,
+// a later one is a reset only the admin can run
+// ============================================================================================================================
+func (r *Router) Init(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+	var err error
+	stub = NewCache(stub)
+	if err = recordAdmin(stub); err != nil {
+		return nil, ErrorEvent(stub, err)
+	}
+	recorder := NewRecorder(stub)
+	for _, role := range r.roles {
+		_, err = r.domains[role].Init(recorder, function, args)
+		if err != nil {
+			return nil, err
+		}
+		if recorder.Failure() != nil {
+			return nil, recorder.Replay(stub)
+		}
+	}
+	if len(r.roles) == 1 {
+		return nil, recorder.Replay(stub)					//a single domain keeps its own deployment message
+	}
+	err = Emit(stub, events.Event{Type: events.ChaincodeDeployed, EntityID: r.name, Message: r.name + " chaincode is deployed successfully."},
+		nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+// ============================================================================================================================
+// Invoke - dispatch an invoke function to the domain that owns it. Its reads see its own writes through a Cache, as the
+// functions and the domains they call expect
+// ============================================================================================================================
+func (r *Router) Invoke(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+	Println("invoke is running " + function)
+	stub = NewCache(stub)
+	if function == "init" {													//initialize the chaincode state, used as reset
+		return r.Init(stub, "init", args)
+	}
+	if h, found := r.invokes[function]; found {
+		return h(stub, args)
+	}
+	Println("invoke did not find func: " + function)					//error
+	errMsg := "{ \"message\" : \"Received unknown function invocation\", \"code\" : \"503\"}"
+	err := stub.SetEvent("errEvent", []byte(errMsg))
+	if err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+// ============================================================================================================================
+// Query - dispatch a query function to the domain that owns it
+// ============================================================================================================================
+func (r *Router) Query(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+	Println("query is running " + function)
+	if h, found := r.queries[function]; found {
+		return h(stub, args)
+	}
+	Println("query did not find func: " + function)						//error
+	errMsg := "{ \"message\" : \"Received unknown function query\", \"code\" : \"503\"}"
+	err := stub.SetEvent("errEvent", []byte(errMsg))
+	if err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+// ============================================================================================================================
+// Call - run a function by its original name, Fabric 2.x has no separate query entry point
+// ============================================================================================================================
+func (r *Router) Call(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+	if _, found := r.queries[function]; found {
+		return r.Query(stub, function, args)
+	}
+	return r.Invoke(stub, function, args)
+}
+// ============================================================================================================================
+// IsQuery - whether a function only reads the state
+// ============================================================================================================================
+func (r *Router) IsQuery(function string) bool {
+	_, found := r.queries[function]
+	return found
+}
+// ============================================================================================================================
+// Roles - the roles of the registered domains in registration order
+// ============================================================================================================================
+func (r *Router) Roles() []string {
+	return append([]string{}, r.roles...)
+}
+// ============================================================================================================================
+// Name - the chaincode name used in messages
+// ============================================================================================================================
+func (r *Router) Name() string {
+	return r.name
+}
+// ============================================================================================================================
+// Functions - the original names of every invoke and query function, sorted
+// ============================================================================================================================
+func (r *Router) Functions() []string {
+	functions := []string{"init"}
+	for function := range r.invokes {
+		functions = append(functions, function)
+	}
+	for function := range r.queries {
+		functions = append(functions, function)
+	}
+	sort.Strings(functions)
+	return functions
+}
+// ============================================================================================================================
+// Linked - whether a domain can be reached, either in this chaincode or through the registry
+// ============================================================================================================================
+func (r *Router) Linked(stub shim.ChaincodeStubInterface, role string) bool {
+	if _, found := r.domains[role]; found {
+		return true
+	}
+	_, err := chaincodeName(stub, role)
+	return err == nil
+}
+// ============================================================================================================================
+// InvokeLinked - invoke a function of another domain, in the same transaction when it is part of this chaincode
+// ============================================================================================================================
+func (r *Router) InvokeLinked(stub shim.ChaincodeStubInterface, role string, function string, args ...string) ([]byte, error) {
+	if invokes, found := r.linkedInvokes[role]; found {
+		return callLinked(stub, invokes, role, function, args)
+	}
+	return invokeChaincode(stub, role, function, args)
+}
+// ============================================================================================================================
+// QueryLinked - query a function of another domain, in the same transaction when it is part of this chaincode
+// ============================================================================================================================
+func (r *Router) QueryLinked(stub shim.ChaincodeStubInterface, role string, function string, args ...string) ([]byte, error) {
+	if queries, found := r.linkedQueries[role]; found {
+		return callLinked(stub, queries, role, function, args)
+	}
+	return invokeChaincode(stub, role, function, args)
+}
+// ============================================================================================================================
+// callLinked - run a function of a domain of this chaincode, its events are kept from the caller and an errEvent becomes an error
+// ============================================================================================================================
+func callLinked(stub shim.ChaincodeStubInterface, handlers map[string]Handler, role string, function string, args []string) ([]byte, error) {
+	h, found := handlers[function]
+	if !found {
+		return nil, errors.New("The " + role + " chaincode has no function " + function)
+	}
+	recorder := NewRecorder(stub)
+	valAsBytes, err := h(&linkedStub{recorder}, args)
+	if err != nil {
+		return nil, err
+	}
+	return valAsBytes, recorder.Failure()
+}
+// linkedStub is the stub of a function called by another domain of this chaincode, see CheckLinked
+type linkedStub struct {
+	*Recorder
+}
+// ============================================================================================================================
+// BatchStep - one invoke of execute_batch
+// ============================================================================================================================
+type BatchStep struct {
+	Function string `json:"function"`
+	Args []string `json:"args"`
+}
+// ============================================================================================================================
+// execute_batch - run several invokes in order as one transaction, nothing is committed unless every step succeeds. A step
+// reads the writes of the earlier ones through the Cache of Invoke, e.g. the PO a create_agreement step needs
+// ============================================================================================================================
+func (r *Router) execute_batch(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	// execute_batch("[{\"function\": \"create_agreement\", \"args\": [...]}, {\"function\": \"createPayment\", \"args\": [...]}]")
+	var err error
+	var steps []BatchStep
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting a JSON array of steps as an argument.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+	err = json.Unmarshal([]byte(args[0]), &steps)
+	if err != nil || len(steps) == 0 {
+		errMsg := "{ \"message\" : \"Batch must be a non-empty JSON array of steps.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+	Println("start execute_batch")
+	results := []json.RawMessage{}
+	for i, step := range steps {
+		h, found := r.invokes[step.Function]
+		if !found || step.Function == "execute_batch" {
+			return nil, fmt.Errorf("Batch step %d: %s is not an invoke function of %s", i, step.Function, r.name)
+		}
+		recorder := NewRecorder(stub)
+		_, err = h(recorder, step.Args)
+		if err == nil {
+			err = recorder.Failure()
+		}
+		if err != nil {
+			//returning the error rejects the transaction, so the earlier steps are not committed either
+			return nil, fmt.Errorf("Batch step %d (%s) failed: %s", i, step.Function, err.Error())
+		}
+		results = append(results, recorder.LastPayload())
+	}
+	err = Emit(stub, events.Event{Type: events.BatchExecuted, EntityID: stub.GetTxID(), Data: map[string]interface{}{"steps": results},
+		Message: "Batch executed succcessfully"}, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	Println("end execute_batch")
+	return nil, nil
+}
@@ -0,0 +1,635 @@
+package router_test
+
+import (
+"errors"
+"os"
+"strings"
+"testing"
+"encoding/json"
+
+"github.com/hyperledger/fabric-chaincode-go/shim"
+"github.com/wipro-blockchain/TF-v1/events"
+"github.com/wipro-blockchain/TF-v1/internal/agreement"
+"github.com/wipro-blockchain/TF-v1/internal/mockstub"
+"github.com/wipro-blockchain/TF-v1/internal/mocktest"
+"github.com/wipro-blockchain/TF-v1/internal/payment"
+"github.com/wipro-blockchain/TF-v1/internal/po"
+"github.com/wipro-blockchain/TF-v1/internal/router"
+"github.com/wipro-blockchain/TF-v1/internal/shipment"
+)
+
+// newTradeFinance - all four domains in one chaincode on an initialized in-memory ledger
+func newTradeFinance(t *testing.T) (*router.Router, *mockstub.MockStub) {
+	r := router.New("TradeFinance")
+	r.Register("po", po.New())
+	r.Register("agreement", agreement.New(r))
+	r.Register("payment", payment.New(r))
+	r.Register("shipment", shipment.New(r))
+	stub := mockstub.New("tradeFinance")
+	if _, err := stub.Init(r, " "); err != nil || stub.Failed() {
+		t.Fatalf("init failed: %v %s", err, stub.LastEvent().Payload)
+	}
+	return r, stub
+}
+
+// poArgs - the create_po arguments of an accepted PO between Buyerco and Sellerco
+func poArgs(transId string) []string {
+	return []string{transId, "Sellerco", "Buyerco", "2024-03-01", "2024-01-15", "Accepted", "ITM-1", "Rice", "100", "25",
+		"true", "true"}
+}
+
+// agreementArgs - the create_agreement arguments of an Agreement of PO1
+func agreementArgs(agreementId string) []string {
+	return []string{agreementId, "PO1", "Created", "Buyerco", "Sellerco", "Shipco", "Buybank", "Sellbank", "Portauth",
+		"2024-01-16", "ITM-1", "Rice", "100", "2500", "2024-03-01", "0", "100", "Contract", "http://docs/contract", "Terms",
+		"true", "false", "false", "false", "Food", "25"}
+}
+
+// batch - the execute_batch argument running each function with its arguments in order
+func batch(steps ...router.BatchStep) string {
+	stepsAsBytes, _ := json.Marshal(steps)
+	return string(stepsAsBytes)
+}
+
+func TestInit(t *testing.T) {
+	_, stub := newTradeFinance(t)
+	if stub.Message() != "TradeFinance chaincode is deployed successfully." {
+		t.Errorf("init event: %s", stub.LastEvent().Payload)
+	}
+
+	single := router.New("ManagePO")
+	single.Register("po", po.New())
+	stub = mockstub.New("managePO")
+	stub.Init(single, " ")
+	if stub.Message() != "ManagePO chaincode is deployed successfully." || len(stub.Events) != 1 {
+		t.Errorf("single domain init events: %+v", stub.Events)
+	}
+}
+
+func TestFunctions(t *testing.T) {
+	r, _ := newTradeFinance(t)
+	functions := strings.Join(r.Functions(), ",")
+	for _, function := range []string{"init", "register_chaincode", "execute_batch", "create_po", "getAgreement_byID",
+		"createPayment", "get_shipment_timeline"} {
+		if !strings.Contains(","+functions+",", ","+function+",") {
+			t.Errorf("Functions is missing %s", function)
+		}
+	}
+	if !r.IsQuery("getPO_byID") || r.IsQuery("create_po") {
+		t.Errorf("IsQuery does not separate queries from invokes")
+	}
+	if roles := strings.Join(r.Roles(), ","); roles != "po,agreement,payment,shipment" {
+		t.Errorf("Roles: %s", roles)
+	}
+}
+
+func TestRegisterDuplicateFunction(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("registering a domain twice did not panic")
+		}
+	}()
+	r := router.New("ManagePO")
+	r.Register("po", po.New())
+	r.Register("po2", po.New())
+}
+
+func TestUnknownFunction(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	mocktest.Reject(t, stub, r, "create_pos", "Received unknown function invocation")
+	r.Query(stub, "getPO_byIDs", []string{"PO1"})
+	if !stub.Failed() || stub.Message() != "Received unknown function query" {
+		t.Errorf("unknown query: %s", stub.LastEvent().Payload)
+	}
+}
+
+func TestOutput(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	var out strings.Builder
+	router.Output = &out
+	defer func() { router.Output = os.Stdout }()
+	mocktest.Succeed(t, stub, r, "create_po", poArgs("PO1")...)
+	if !strings.Contains(out.String(), "invoke is running create_po") {
+		t.Errorf("progress of create_po: %q", out.String())
+	}
+	mocktest.Succeed(t, stub, r, "create_po", poArgs("PO2")...)
+	if n := strings.Count(out.String(), "create_po: positional arguments are deprecated"); n != 1 {
+		t.Errorf("the deprecation of positional arguments is logged %d times", n)
+	}
+}
+
+func TestExecuteBatch(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	mocktest.Succeed(t, stub, r, "execute_batch", batch(
+		router.BatchStep{Function: "create_po", Args: poArgs("PO1")},
+		router.BatchStep{Function: "create_agreement", Args: agreementArgs("AGR1")},
+	))
+	if stub.Message() != "Batch executed succcessfully" {
+		t.Errorf("execute_batch event: %s", stub.LastEvent().Payload)
+	}
+	result := struct {
+		Data struct {
+			Steps []events.Event `json:"steps"`
+		} `json:"data"`
+	}{}
+	json.Unmarshal(stub.LastEvent().Payload, &result)
+	if steps := result.Data.Steps; stub.LastEvent().Name != events.BatchExecuted || len(steps) != 2 ||
+		steps[0].Type != events.POCreated || steps[1].Type != events.AgreementCreated || steps[1].Message != "Agreement created succcessfully" {
+		t.Errorf("execute_batch steps: %s", stub.LastEvent().Payload)
+	}
+	if len(stub.Events) != 1 {
+		t.Errorf("execute_batch emitted %d events", len(stub.Events))
+	}
+
+	//a step reads what the earlier ones wrote, so two POs created without an ID get two IDs
+	mocktest.Succeed(t, stub, r, "execute_batch", batch(
+		router.BatchStep{Function: "create_po", Args: poArgs("")},
+		router.BatchStep{Function: "create_po", Args: poArgs("")},
+	))
+	json.Unmarshal(stub.LastEvent().Payload, &result)
+	if steps := result.Data.Steps; len(steps) != 2 || !strings.HasPrefix(steps[0].EntityID, "PO-") || steps[1].EntityID != steps[0].EntityID+"-2" {
+		t.Errorf("IDs generated in one batch: %s", stub.LastEvent().Payload)
+	}
+	if index := string(stub.State[po.POIndexStr]); strings.Count(index, `"PO-`) != 2 {
+		t.Errorf("PO index after the batch: %s", index)
+	}
+}
+
+func TestExecuteBatchRollback(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	mocktest.Reject(t, stub, r, "execute_batch", "Batch step 1 (create_agreement) failed: PO PO2 Not Found", batch(
+		router.BatchStep{Function: "create_po", Args: poArgs("PO1")},
+		router.BatchStep{Function: "create_agreement", Args: append([]string{"AGR1", "PO2"}, agreementArgs("AGR1")[2:]...)},
+	))
+	if valAsBytes := mocktest.Succeed(t, stub, r, "getPO_byID", "PO1"); len(valAsBytes) != 0 {
+		t.Errorf("PO of a failed batch was committed: %s", valAsBytes)
+	}
+}
+
+func TestExecuteBatchRejects(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	mocktest.Reject(t, stub, r, "execute_batch", "Batch must be a non-empty JSON array of steps.", "[]")
+	mocktest.Reject(t, stub, r, "execute_batch", "getPO_byID is not an invoke function of TradeFinance",
+		batch(router.BatchStep{Function: "getPO_byID", Args: []string{"PO1"}}))
+	mocktest.Reject(t, stub, r, "execute_batch", "execute_batch is not an invoke function", batch(router.BatchStep{Function: "execute_batch"}))
+}
+
+func TestRegisterChaincode(t *testing.T) {
+	managePO := router.New("ManagePO")
+	managePO.Register("po", po.New())
+	manageAgreement := router.New("ManageAgreement")
+	manageAgreement.Register("agreement", agreement.New(manageAgreement))
+
+	stub := mockstub.New("manageAgreement")
+	poStub := stub.Deploy("managePO", managePO)
+	if _, err := poStub.Init(managePO, " "); err != nil || poStub.Failed() {
+		t.Fatalf("init of ManagePO failed: %v %s", err, poStub.LastEvent().Payload)
+	}
+	if _, err := stub.Init(manageAgreement, " "); err != nil || stub.Failed() {
+		t.Fatalf("init of ManageAgreement failed: %v %s", err, stub.LastEvent().Payload)
+	}
+	mocktest.Succeed(t, poStub, managePO, "create_po", poArgs("PO1")...)
+
+	mocktest.Reject(t, stub, manageAgreement, "create_agreement", "The po chaincode is not registered", agreementArgs("AGR1")...)
+	mocktest.Succeed(t, stub, manageAgreement, "register_chaincode", "po", "managePO")
+	if stub.Message() != "Chaincode registered succcessfully" {
+		t.Errorf("register_chaincode event: %s", stub.LastEvent().Payload)
+	}
+	mocktest.Succeed(t, stub, manageAgreement, "create_agreement", agreementArgs("AGR1")...)
+
+	args := agreementArgs("AGR2")
+	args[1] = "PO9"
+	mocktest.Reject(t, stub, manageAgreement, "create_agreement", "PO PO9 Not Found", args...)
+	if _, found := poStub.State["Agreement_AGR1"]; found {
+		t.Errorf("the Agreement was written to the state of ManagePO")
+	}
+}
+
+func TestAdmin(t *testing.T) {
+	admin, _ := mockstub.Identity("AdminMSP", "admin")
+	other, _ := mockstub.Identity("BuyerMSP", "buyer-admin")
+	managePO := router.New("ManagePO")
+	managePO.Register("po", po.New())
+	stub := mockstub.New("managePO")
+	stub.Creator = admin
+	if _, err := stub.Init(managePO, " "); err != nil || stub.Failed() {
+		t.Fatalf("init failed: %v %s", err, stub.LastEvent().Payload)
+	}
+	mocktest.Succeed(t, stub, managePO, "register_chaincode", "agreement", "manageAgreement")
+
+	//another MSP can neither point a role at its own chaincode nor reset the state
+	stub.Creator = other
+	mocktest.Reject(t, stub, managePO, "register_chaincode", "Only the admin MSP AdminMSP can do this, not BuyerMSP:buyer-admin",
+		"agreement", "rogueAgreement")
+	mocktest.Reject(t, stub, managePO, "init", "Only the admin MSP AdminMSP", " ")
+	stub.Creator = admin
+	mocktest.Succeed(t, stub, managePO, "init", " ")
+	if !strings.Contains(string(stub.State[router.ChaincodeRegistryStr]), `"agreement":"manageAgreement"`) {
+		t.Errorf("registry: %s", stub.State[router.ChaincodeRegistryStr])
+	}
+}
+
+func TestMigrateKeys(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	mocktest.Succeed(t, stub, r, "create_po", poArgs("PO1")...)
+	mocktest.Succeed(t, stub, r, "create_agreement", agreementArgs("AGR1")...)
+	mocktest.Succeed(t, stub, r, "update_fraud_list", "F1", "Fraudco")
+	//the layout before the namespaced keys: every record under its bare ID
+	for bare, key := range map[string]string{"PO1": "PO_PO1", "AGR1": "Agreement_AGR1", "F1": "Fraud_F1"} {
+		stub.State[bare] = stub.State[key]
+		delete(stub.State, key)
+	}
+	if record := mocktest.Succeed(t, stub, r, "getPO_byID", "PO1"); len(record) != 0 {
+		t.Errorf("PO1 is found before the migration: %s", record)
+	}
+
+	buyer, _ := mockstub.Identity("BuyerMSP", "buyer-admin")
+	stub.Creator = buyer
+	mocktest.Reject(t, stub, r, "migrate_keys", "Only the admin MSP")
+	stub.Creator = nil
+	mocktest.Succeed(t, stub, r, "migrate_keys")
+	event, err := events.Decode(stub.LastEvent().Name, stub.LastEvent().Payload)
+	if err != nil || event.Type != events.ChaincodeKeysMigrated || event.Data["moved"].(map[string]interface{})["Agreement_AGR1"] != "AGR1" {
+		t.Errorf("migrate_keys event: %s", stub.LastEvent().Payload)
+	}
+	for _, bare := range []string{"PO1", "AGR1", "F1"} {
+		if _, found := stub.State[bare]; found {
+			t.Errorf("%s is still stored under its bare ID", bare)
+		}
+	}
+	record := mocktest.Succeed(t, stub, r, "getPO_byID", "PO1")
+	if !strings.Contains(string(record), `"transId": "PO1"`) {
+		t.Errorf("migrated PO1: %s", record)
+	}
+	mocktest.Succeed(t, stub, r, "getAgreement_byID", "AGR1")
+	fraud := mocktest.Succeed(t, stub, r, "get_fraud_list", " ")
+	if !strings.Contains(string(fraud), "Fraudco") {
+		t.Errorf("migrated fraud list: %s", fraud)
+	}
+	mocktest.Succeed(t, stub, r, "migrate_keys")
+	if !strings.Contains(stub.Message(), "0 records moved") {
+		t.Errorf("second migrate_keys: %s", stub.LastEvent().Payload)
+	}
+}
+
+func TestLegacyEvents(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	mocktest.Reject(t, stub, r, "set_legacy_events", `Expecting \"true\" or \"false\"`, "yes")
+	mocktest.ActAs(t, stub, "BuyerMSP", "buyer-admin")
+	mocktest.Reject(t, stub, r, "set_legacy_events", "Only the admin MSP", "true")
+	stub.Creator = nil
+	mocktest.Succeed(t, stub, r, "set_legacy_events", "true")
+	if stub.LastEvent().Name != events.LegacyName {
+		t.Errorf("set_legacy_events emitted %s", stub.LastEvent().Name)
+	}
+
+	//a listener of evtsender reads the former fields, a catalog listener the type
+	mocktest.Succeed(t, stub, r, "create_po", poArgs("PO1")...)
+	legacy := map[string]string{}
+	json.Unmarshal(stub.LastEvent().Payload, &legacy)
+	if stub.LastEvent().Name != events.LegacyName || legacy["transID"] != "PO1" || legacy["code"] != "200" || legacy["message"] == "" {
+		t.Errorf("legacy create_po event %s: %s", stub.LastEvent().Name, stub.LastEvent().Payload)
+	}
+	event, err := events.Decode(stub.LastEvent().Name, stub.LastEvent().Payload)
+	if err != nil || event.Type != events.POCreated || event.EntityID != "PO1" {
+		t.Errorf("Decode of a legacy event: %+v %v", event, err)
+	}
+
+	mocktest.Succeed(t, stub, r, "set_legacy_events", "false")
+	mocktest.Succeed(t, stub, r, "delete_po", "PO1")
+	if stub.LastEvent().Name != events.PODeleted {
+		t.Errorf("delete_po after the legacy events are off emitted %s", stub.LastEvent().Name)
+	}
+}
+
+func TestParties(t *testing.T) {
+	admin, _ := mockstub.Identity("AdminMSP", "admin")
+	buyer, _ := mockstub.Identity("BuyerMSP", "buyer-admin")
+	clerk, _ := mockstub.Identity("BuyerMSP", "buyer-clerk")
+	r := router.New("ManageShipment")
+	r.Register("shipment", shipment.New(r))
+	stub := mockstub.New("manageShipment")
+	stub.Creator = admin
+	if _, err := stub.Init(r, " "); err != nil || stub.Failed() {
+		t.Fatalf("init failed: %v %s", err, stub.LastEvent().Payload)
+	}
+	mocktest.Succeed(t, stub, r, "register_party", "Buyerco", "BuyerMSP:buyer-admin")
+	mocktest.Succeed(t, stub, r, "register_party", "Buybank", "BuybankMSP")
+	if stub.LastEvent().Name != events.PartyRegistered {
+		t.Errorf("register_party event: %s", stub.LastEvent().Name)
+	}
+	stub.Creator = buyer
+	mocktest.Reject(t, stub, r, "register_party", "Only the admin MSP AdminMSP", "Buyerco", "BuyerMSP")
+	if err := router.CheckParty(stub, "Buyerco"); err != nil {
+		t.Errorf("CheckParty(Buyerco) as its certificate: %v", err)
+	}
+	if err := router.CheckParty(stub, "Buybank"); err == nil || !strings.Contains(err.Error(), "does not act for Buybank") {
+		t.Errorf("CheckParty(Buybank) as the buyer: %v", err)
+	}
+	if err := router.CheckParty(stub, "Sellerco"); err == nil || !strings.Contains(err.Error(), "no registered identity") {
+		t.Errorf("CheckParty(Sellerco): %v", err)
+	}
+	stub.Creator = clerk
+	if party, err := router.ActingParty(stub, "Buyerco", "Buybank"); err == nil {
+		t.Errorf("ActingParty as another certificate of the buyer MSP: %s", party)
+	}
+	parties := map[string]string{}
+	json.Unmarshal(mocktest.Succeed(t, stub, r, "get_parties"), &parties)
+	if len(parties) != 2 || parties["Buybank"] != "BuybankMSP" {
+		t.Errorf("get_parties: %v", parties)
+	}
+}
+
+// clearing is a shipment domain that only passes a clearance on to the agreement domain, as port_clearance_action does
+type clearing struct {
+	linker router.Linker
+}
+
+func (c clearing) Init(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+	return nil, nil
+}
+
+func (c clearing) Invokes() map[string]router.Handler {
+	return map[string]router.Handler{"clear": func(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+		return c.linker.InvokeLinked(stub, "agreement", "update_clearance_status", args...)
+	}}
+}
+
+func (c clearing) Queries() map[string]router.Handler {
+	return map[string]router.Handler{}
+}
+
+func TestCheckLinked(t *testing.T) {
+	managePO := router.New("ManagePO")
+	managePO.Register("po", po.New())
+	manageAgreement := router.New("ManageAgreement")
+	manageAgreement.Register("agreement", agreement.New(manageAgreement))
+	manageShipment := router.New("ManageShipment")
+	manageShipment.Register("shipment", clearing{manageShipment})
+
+	stub := mockstub.New("manageAgreement")
+	poStub := stub.Deploy("managePO", managePO)
+	shipmentStub := stub.Deploy("manageShipment", manageShipment)
+	for cc, s := range map[mockstub.Chaincode]*mockstub.MockStub{managePO: poStub, manageAgreement: stub, manageShipment: shipmentStub} {
+		if _, err := s.Init(cc, " "); err != nil || s.Failed() {
+			t.Fatalf("init of %s failed: %v %s", s.Name, err, s.LastEvent().Payload)
+		}
+	}
+	mocktest.Succeed(t, stub, manageAgreement, "register_chaincode", "po", "managePO")
+	mocktest.Succeed(t, shipmentStub, manageShipment, "register_chaincode", "agreement", "manageAgreement")
+	mocktest.Succeed(t, stub, manageAgreement, "register_party", "Portauth", "PortMSP")
+	mocktest.Succeed(t, poStub, managePO, "create_po", poArgs("PO1")...)
+	mocktest.Succeed(t, stub, manageAgreement, "create_agreement", agreementArgs("AGR1")...)
+
+	//a caller who is neither the shipment chaincode nor the port authority can not clear the Agreement
+	mocktest.ActAs(t, stub, "MalloryMSP", "mallory")
+	mocktest.Reject(t, stub, manageAgreement, "update_clearance_status", "Only the shipment chaincode or the port authority",
+		"AGR1", "SHP1", "Cleared")
+	mocktest.ActAs(t, shipmentStub, "MalloryMSP", "mallory")
+	mocktest.Succeed(t, shipmentStub, manageShipment, "clear", "AGR1", "SHP1", "Cleared")
+	res := agreement.Agreement{}
+	if json.Unmarshal(stub.State["Agreement_AGR1"], &res); res.Clearance_status != "" {
+		t.Errorf("cleared through a shipment chaincode the agreement chaincode has not registered: %+v", res)
+	}
+
+	stub.Creator = nil
+	mocktest.Succeed(t, stub, manageAgreement, "register_chaincode", "shipment", "manageShipment")
+	mocktest.Succeed(t, shipmentStub, manageShipment, "clear", "AGR1", "SHP1", "On Hold")
+	if json.Unmarshal(stub.State["Agreement_AGR1"], &res); res.Clearance_status != "On Hold" {
+		t.Errorf("clearance through the shipment chaincode: %+v", res)
+	}
+	mocktest.ActAs(t, stub, "PortMSP", "officer")
+	mocktest.Succeed(t, stub, manageAgreement, "update_clearance_status", "AGR1", "SHP1", "Cleared")
+}
+
+func TestNamedArgs(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	mocktest.Succeed(t, stub, r, "create_po", `{"transId": "PO1", "sellerName": "Sellerco", "buyerName": "Buyerco", "po_status": "Created",
+		"item_quantity": 100, "price": "25", "buyer_sign": true}`)
+	mocktest.Succeed(t, stub, r, "update_po", `{"transId": "PO1", "po_status": "Accepted", "seller_sign": "true", "price": null}`)
+	record := po.PO{}
+	json.Unmarshal(mocktest.Succeed(t, stub, r, "getPO_byID", "PO1"), &record)
+	if record.PO_status != "Accepted" || record.Seller_sign != "true" || record.Buyer_sign != "true" || record.Item_quantity != "100" ||
+		record.SellerName != "Sellerco" || record.Price != "" {
+		t.Errorf("merged PO: %+v", record)
+	}
+
+	mocktest.Succeed(t, stub, r, "create_agreement", agreementArgs("AGR1")...)
+	mocktest.Succeed(t, stub, r, "update_agreement", `{"agreementId": "AGR1", "buyerBank_sign": "true"}`)
+	contract := agreement.Agreement{}
+	json.Unmarshal(mocktest.Succeed(t, stub, r, "getAgreement_byID", "AGR1"), &contract)
+	if contract.BuyerBank_sign != "true" || contract.Buyer_sign != "true" || contract.Total_Value != "2500" || contract.TC_Text != "Terms" {
+		t.Errorf("merged Agreement: %+v", contract)
+	}
+	mocktest.Succeed(t, stub, r, "execute_batch", batch(router.BatchStep{Function: "update_agreement",
+		Args: []string{`{"agreementId": "AGR1", "seller_sign": "true"}`}}))
+	json.Unmarshal(mocktest.Succeed(t, stub, r, "getAgreement_byID", "AGR1"), &contract)
+	if contract.Seller_sign != "true" || contract.BuyerBank_sign != "true" {
+		t.Errorf("Agreement merged in a batch: %+v", contract)
+	}
+}
+
+func TestNamedArgsRejects(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	mocktest.Succeed(t, stub, r, "create_po", poArgs("PO1")...)
+	mocktest.Reject(t, stub, r, "update_po", "update_po: unknown field(s) bogus, clearance_status", `{"transId": "PO1", "bogus": "x", "clearance_status": "x"}`)
+	mocktest.Reject(t, stub, r, "update_po", "update_po: transId is required", `{"po_status": "Accepted"}`)
+	mocktest.Reject(t, stub, r, "update_po", "update_po: price must be a string", `{"transId": "PO1", "price": [25]}`)
+	mocktest.Reject(t, stub, r, "update_po", "update_po: malformed JSON object", `{"transId": "PO1",`)
+	mocktest.Reject(t, stub, r, "update_po", "PO9 Not Found", `{"transId": "PO9", "po_status": "Accepted"}`)
+	mocktest.Reject(t, stub, r, "create_po", "create_po: unknown field(s) seller_remarks", `{"transId": "PO2", "seller_remarks": "x"}`)
+	record := po.PO{}
+	json.Unmarshal(mocktest.Succeed(t, stub, r, "getPO_byID", "PO1"), &record)
+	if record.PO_status != "Accepted" || record.Price != "25" {
+		t.Errorf("a refused update changed the PO: %+v", record)
+	}
+}
+
+func TestCheckVersion(t *testing.T) {
+	if err := router.CheckVersion("PO1", 3, ""); err != nil {
+		t.Errorf("an update without an expected version: %v", err)
+	}
+	if err := router.CheckVersion("PO1", 3, "3"); err != nil {
+		t.Errorf("an update of the current version: %v", err)
+	}
+	if err := router.CheckVersion("PO1", 3, "2"); !errors.Is(err, router.ErrVersionConflict) {
+		t.Errorf("an update of a stale version: %v", err)
+	}
+	if err := router.CheckVersion("PO1", 3, "three"); err == nil || errors.Is(err, router.ErrVersionConflict) {
+		t.Errorf("a malformed version: %v", err)
+	}
+	args, expected := router.ExpectedVersion(router.WithVersion([]string{"PO1", "x"}, 3), 2)
+	if strings.Join(args, ",") != "PO1,x" || expected != "3" {
+		t.Errorf("ExpectedVersion: %v %q", args, expected)
+	}
+	if args, expected = router.ExpectedVersion(router.WithVersion([]string{"PO1", "x"}, 0), 2); len(args) != 2 || expected != "" {
+		t.Errorf("ExpectedVersion without a version: %v %q", args, expected)
+	}
+}
+
+func TestExecuteOnce(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	mocktest.Succeed(t, stub, r, "create_po", poArgs("PO1")...)
+	mocktest.Succeed(t, stub, r, "create_agreement", agreementArgs("AGR1")...)
+	mocktest.Succeed(t, stub, r, "update_agreement", `{"agreementId": "AGR1", "buyerBank_sign": "true", "seller_sign": "true", "sellerBank_sign": "true"}`)
+	paymentArgs := []string{"PAY1", "AGR1", "Buyerco", "Sellerco", "2500", "2024-02-01", "Created", "2024-03-01", "false",
+		"Buybank", "Sellbank"}
+
+	//a retry of a create gets the original outcome instead of "already exists"
+	for i := 0; i < 2; i++ {
+		mocktest.Succeed(t, stub, r, "execute_once", append([]string{"create-PAY1", "createPayment"}, paymentArgs...)...)
+		if stub.Message() != "Payment created succcessfully" {
+			t.Errorf("createPayment run %d: %s", i, stub.LastEvent().Payload)
+		}
+	}
+	createTx := ""
+	outcome := router.Outcome{}
+	json.Unmarshal(mocktest.Succeed(t, stub, r, "get_idempotency_outcome", "create-PAY1"), &outcome)
+	if createTx = outcome.TxID; outcome.Function != "createPayment" || createTx == "" || outcome.EventName != events.PaymentCreated {
+		t.Errorf("stored outcome: %+v", outcome)
+	}
+
+	//a retry of a settlement does not debit the buyer again, the accounts start empty
+	settle := `{"paymentId": "PAY1", "buyerBank_sign": "true", "paymentStatus": "Paid"}`
+	for i := 0; i < 2; i++ {
+		mocktest.Succeed(t, stub, r, "execute_once", "settle-PAY1", "updatePayment", settle)
+		if stub.Message() != "Payment updated succcessfully" {
+			t.Errorf("updatePayment run %d: %s", i, stub.LastEvent().Payload)
+		}
+		if i == 1 && len(stub.Writes) != 0 {
+			t.Errorf("the replay wrote %+v", stub.Writes)
+		}
+	}
+	accounts := payment.AccountInfo{}
+	json.Unmarshal(mocktest.Succeed(t, stub, r, "getAccountDetails"), &accounts)
+	if accounts.BuyerAccountBalance != "-2500.00" || accounts.SellerAccountBalance != "2500.00" {
+		t.Errorf("balances after a retried settlement: %+v", accounts)
+	}
+
+	mocktest.Reject(t, stub, r, "execute_once", "Idempotency key create-PAY1 was used by transaction "+createTx+" for createPayment with other arguments",
+		append([]string{"create-PAY1", "createPayment", "PAY2"}, paymentArgs[1:]...)...)
+	mocktest.Reject(t, stub, r, "execute_once", "Idempotency key settle-PAY1 was used", "settle-PAY1", "deletePayment", "PAY1")
+
+	//a refusal is not stored, the retry runs the invoke again
+	mocktest.Reject(t, stub, r, "execute_once", "PO9 Not Found", "update-PO9", "update_po", `{"transId": "PO9", "po_status": "Accepted"}`)
+	if valAsBytes := mocktest.Succeed(t, stub, r, "get_idempotency_outcome", "update-PO9"); len(valAsBytes) != 0 {
+		t.Errorf("stored outcome of a refusal: %s", valAsBytes)
+	}
+	mocktest.Succeed(t, stub, r, "create_po", poArgs("PO9")...)
+	mocktest.Succeed(t, stub, r, "execute_once", "update-PO9", "update_po", `{"transId": "PO9", "po_status": "Accepted"}`)
+}
+
+func TestExecuteOnceRejects(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	mocktest.Reject(t, stub, r, "execute_once", "Incorrect number of arguments", "key-only")
+	mocktest.Reject(t, stub, r, "execute_once", "Incorrect number of arguments", " ", "create_po")
+	mocktest.Reject(t, stub, r, "execute_once", "getPO_byID is not an invoke function of TradeFinance", "k1", "getPO_byID", "PO1")
+	mocktest.Reject(t, stub, r, "execute_once", "execute_once is not an invoke function of TradeFinance", "k1", "execute_once", "k2", "create_po")
+	mocktest.Reject(t, stub, r, "get_idempotency_outcome", "Incorrect number of arguments")
+	if valAsBytes := mocktest.Succeed(t, stub, r, "get_idempotency_outcome", "k1"); len(valAsBytes) != 0 {
+		t.Errorf("outcome of a refused execute_once: %s", valAsBytes)
+	}
+}
+
+func TestGeneratedIDs(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	poId := string(mocktest.Succeed(t, stub, r, "create_po", poArgs("")...))
+	event := events.Event{}
+	json.Unmarshal(stub.LastEvent().Payload, &event)
+	if !strings.HasPrefix(poId, "PO-") || event.EntityID != poId {
+		t.Fatalf("generated PO ID %q, event %s", poId, stub.LastEvent().Payload)
+	}
+	//the same transaction derives the same ID, a taken one gets a suffix
+	if id, err := router.NewID(stub, "PO-", func(id string) string { return "PO_" + id }); err != nil || id != poId+"-2" {
+		t.Errorf("NewID of a taken ID: %s %v", id, err)
+	}
+	namedId := string(mocktest.Succeed(t, stub, r, "create_po", `{"sellerName": "Sellerco", "buyerName": "Buyerco", "po_status": "Created"}`))
+	if !strings.HasPrefix(namedId, "PO-") || namedId == poId {
+		t.Errorf("generated ID of a named create_po: %q", namedId)
+	}
+
+	args := agreementArgs("")
+	args[1] = poId
+	agreementId := string(mocktest.Succeed(t, stub, r, "create_agreement", args...))
+	if !strings.HasPrefix(agreementId, "AGR-") {
+		t.Fatalf("generated Agreement ID %q", agreementId)
+	}
+	mocktest.Succeed(t, stub, r, "update_agreement", `{"agreementId": "` + agreementId + `", "buyerBank_sign": "true", "seller_sign": "true", "sellerBank_sign": "true"}`)
+	paymentId := string(mocktest.Succeed(t, stub, r, "createPayment", "", agreementId, "Buyerco", "Sellerco", "2500", "2024-02-01", "Created",
+		"2024-03-01", "false", "Buybank", "Sellbank"))
+	shipmentId := string(mocktest.Succeed(t, stub, r, "create_shipment", "", poId, agreementId, "Created", "Mumbai", "Rotterdam", "",
+		"2024-02-01", "Shipco"))
+	fraudId := string(mocktest.Succeed(t, stub, r, "update_fraud_list", "", "Crookco"))
+	if !strings.HasPrefix(paymentId, "PAY-") || !strings.HasPrefix(shipmentId, "SHP-") || !strings.HasPrefix(fraudId, "FRD-") {
+		t.Errorf("generated IDs %q %q %q", paymentId, shipmentId, fraudId)
+	}
+
+	//records of different types do not collide on the same ID
+	mocktest.Succeed(t, stub, r, "create_po", poArgs(agreementId)...)
+	record := po.PO{}
+	json.Unmarshal(mocktest.Succeed(t, stub, r, "getPO_byID", agreementId), &record)
+	contract := agreement.Agreement{}
+	json.Unmarshal(mocktest.Succeed(t, stub, r, "getAgreement_byID", agreementId), &contract)
+	if record.TransID != agreementId || contract.AgreementID != agreementId || contract.TransID != poId {
+		t.Errorf("a PO and an Agreement of the same ID: %+v %+v", record, contract)
+	}
+}
+
+func TestEvents(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	buyer, err := mockstub.Identity("BuyerMSP", "buyer-admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stub.Creator = buyer
+	args := poArgs("PO1")
+	args[5], args[11] = "Created", "false"
+	mocktest.Succeed(t, stub, r, "create_po", args...)
+	created, err := events.Decode(stub.LastEvent().Name, stub.LastEvent().Payload)
+	if err != nil || created.Type != events.POCreated || created.EntityType != "po" || created.EntityID != "PO1" ||
+		created.Status != "Created" || created.Actor != "BuyerMSP:buyer-admin" || created.TxID != stub.TxID || created.Version != 1 {
+		t.Fatalf("create_po event: %+v %v", created, err)
+	}
+	if change := created.Changed("item_name"); change == nil || string(change.From) != "null" || string(change.To) != `"Rice"` ||
+		created.Changed("lastModifiedTxId") != nil {
+		t.Errorf("create_po changes: %+v", created.Changes)
+	}
+
+	//an update lists only the fields it changed, and is a signed event when it adds a signature
+	mocktest.Succeed(t, stub, r, "update_po", `{"transId": "PO1", "po_status": "Accepted", "seller_sign": "true"}`)
+	signed, err := events.Decode(stub.LastEvent().Name, stub.LastEvent().Payload)
+	if err != nil || signed.Type != events.POSigned || signed.Status != "Accepted" || signed.Version != 2 || len(signed.Changes) != 2 ||
+		signed.Changes[0].Field != "po_status" || string(signed.Changes[1].From) != `"false"` || string(signed.Changes[1].To) != `"true"` {
+		t.Errorf("update_po event: %s %v", stub.LastEvent().Payload, err)
+	}
+	mocktest.Succeed(t, stub, r, "update_po", `{"transId": "PO1", "seller_remarks": "March"}`)
+	if stub.LastEvent().Name != events.POUpdated {
+		t.Errorf("update_po without a signature: %s", stub.LastEvent().Name)
+	}
+	mocktest.Succeed(t, stub, r, "delete_po", "PO1")
+	deleted, err := events.Decode(stub.LastEvent().Name, stub.LastEvent().Payload)
+	if change := deleted.Changed("transId"); err != nil || deleted.Type != events.PODeleted || change == nil || string(change.To) != "null" {
+		t.Errorf("delete_po event: %s %v", stub.LastEvent().Payload, err)
+	}
+
+	catalog := struct {
+		SchemaVersion string `json:"schemaVersion"`
+		Events []events.Type `json:"events"`
+		Schema map[string]interface{} `json:"schema"`
+	}{}
+	json.Unmarshal(mocktest.Succeed(t, stub, r, "get_event_catalog"), &catalog)
+	if catalog.SchemaVersion != events.SchemaVersion || len(catalog.Events) != len(events.Catalog) || catalog.Schema["title"] == nil {
+		t.Errorf("get_event_catalog: %+v", catalog)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	changes, err := router.Diff(`{"a": "1", "b": {"c": 2}, "version": 1, "gone": true}`, map[string]interface{}{"a": "1", "b": map[string]int{"c": 3}, "version": 2})
+	if err != nil || len(changes) != 2 || changes[0].Field != "b" || string(changes[0].To) != `{"c":3}` || changes[1].Field != "gone" ||
+		string(changes[1].To) != "null" {
+		t.Errorf("Diff: %+v %v", changes, err)
+	}
+	if _, err = router.Diff(`["not", "a", "record"]`, nil); err == nil {
+		t.Errorf("Diff of an array succeeded")
+	}
+}
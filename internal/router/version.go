@@ -0,0 +1,67 @@
+package router
+
+import (
+"errors"
+"fmt"
+"strconv"
+
+"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+// ErrVersionConflict is the error of an update based on a stale record
+var ErrVersionConflict = errors.New("version conflict")
+
+// ============================================================================================================================
+// CheckVersion - refuse an update based on a stale record. expected is the version the caller read, empty skips the check so
+// the updates written before versions were kept go on working
+// ============================================================================================================================
+func CheckVersion(id string, current int, expected string) error {
+	if expected == "" {
+		return nil
+	}
+	version, err := strconv.Atoi(expected)
+	if err != nil {
+		return errors.New("version must be a number, not " + expected)
+	}
+	if version != current {
+		return fmt.Errorf("%w on %s: expected version %s but it is at version %d, read it again and retry", ErrVersionConflict,
+			id, expected, current)
+	}
+	return nil
+}
+// ============================================================================================================================
+// ExpectedVersion - the optional last argument of an update, the version it is based on, and the arguments before it
+// ============================================================================================================================
+func ExpectedVersion(args []string, count int) ([]string, string) {
+	if len(args) == count + 1 {
+		return args[:count], args[count]
+	}
+	return args, ""
+}
+// ============================================================================================================================
+// WithVersion - the arguments of an update followed by the version of the record it is based on, the argument ExpectedVersion
+// takes. A record without a version, e.g. one built rather than read, replaces the stored one unchecked
+// ============================================================================================================================
+func WithVersion(args []string, version int) []string {
+	if version == 0 {
+		return args
+	}
+	return append(args, strconv.Itoa(version))
+}
+// ============================================================================================================================
+// VersionEvent - send the errEvent of a CheckVersion error, a conflict has the code 409 to tell it apart from the other refusals
+// ============================================================================================================================
+func VersionEvent(stub shim.ChaincodeStubInterface, err error) error {
+	if errors.Is(err, ErrVersionConflict) {
+		errMsg := "{ \"message\" : " + JSONString(err.Error()) + ", \"code\" : \"409\"}"
+		return stub.SetEvent("errEvent", []byte(errMsg))
+	}
+	return ErrorEvent(stub, err)
+}
+// ============================================================================================================================
+// ErrorEvent - send the errEvent of a refusal, the message of err with the code 503
+// ============================================================================================================================
+func ErrorEvent(stub shim.ChaincodeStubInterface, err error) error {
+	errMsg := "{ \"message\" : " + JSONString(err.Error()) + ", \"code\" : \"503\"}"
+	return stub.SetEvent("errEvent", []byte(errMsg))
+}
@@ -0,0 +1,270 @@
+package scenario
+
+import (
+"bytes"
+"fmt"
+"io"
+"sort"
+"strconv"
+"strings"
+"encoding/json"
+
+"github.com/wipro-blockchain/TF-v1/internal/simulator"
+)
+
+// Report is the outcome of a scenario, it stops at the first step that differs from its expectations
+type Report struct {
+	Scenario *Scenario
+	Steps []StepReport								// the steps that ran
+	NotRun int										// the steps after a failed one
+}
+
+// StepReport is the outcome of one step
+type StepReport struct {
+	Index int
+	Step Step
+	Result simulator.Result
+	Differences []Difference
+}
+
+// Difference is one expectation a step did not meet
+type Difference struct {
+	What string										// e.g. "event", "state manageAgreement AGR1"
+	Path string										// the field, e.g. ".agreement_status", empty for the whole value
+	Expected string
+	Actual string
+}
+
+// missing stands for a field or key that does not exist
+const missing = "(missing)"
+
+// ============================================================================================================================
+// Run - run a scenario on a fresh network
+// ============================================================================================================================
+func Run(s *Scenario) (*Report, error) {
+	balance := s.Balance
+	if balance == "" {
+		balance = simulator.DefaultBalance
+	}
+	var n *simulator.Network
+	var err error
+	if s.Single {
+		n, err = simulator.NewTradeFinance(balance)
+	}else{
+		n, err = simulator.New(balance)
+	}
+	if err != nil {
+		return nil, err
+	}
+	report := &Report{Scenario: s}
+	for i, step := range s.Steps {
+		var res simulator.Result
+		if step.As == "" {
+			res = n.Call(step.Function, step.Args...)
+		}else{
+			creator, err := simulator.Identity(step.As)
+			if err != nil {
+				return nil, err
+			}
+			res = n.CallAs(creator, step.Function, step.Args...)
+		}
+		stepReport := StepReport{Index: i, Step: step, Result: res, Differences: check(n, step, res)}
+		report.Steps = append(report.Steps, stepReport)
+		if len(stepReport.Differences) > 0 {
+			report.NotRun = len(s.Steps) - i - 1
+			break
+		}
+	}
+	return report, nil
+}
+// ============================================================================================================================
+// Passed - whether every step met its expectations
+// ============================================================================================================================
+func (r *Report) Passed() bool {
+	return len(r.Steps) > 0 && len(r.Steps[len(r.Steps)-1].Differences) == 0 && r.NotRun == 0
+}
+// ============================================================================================================================
+// check - the expectations of a step its transaction did not meet
+// ============================================================================================================================
+func check(n *simulator.Network, step Step, res simulator.Result) []Difference {
+	var diffs []Difference
+	expect := step.Expect
+	if res.Chaincode == "" {									//the function was not found, nothing else can be checked
+		return []Difference{{What: "function", Expected: step.Function, Actual: res.Err.Error()}}
+	}
+	if res.Failed() != expect.Fail {
+		diffs = append(diffs, Difference{What: "outcome", Expected: outcome(expect.Fail), Actual: outcome(res.Failed()) + ": " + res.Message()})
+	}
+	if expect.Message != nil && res.Message() != *expect.Message {
+		diffs = append(diffs, Difference{What: "message", Expected: quote(*expect.Message), Actual: quote(res.Message())})
+	}
+	if expect.Event != nil {
+		diffs = append(diffs, checkEvent(*expect.Event, res)...)
+	}
+	if expect.Response != nil {
+		diffs = append(diffs, match("response", "", expect.Response, decode(res.Response))...)
+	}
+	for _, state := range expect.State {
+		name := state.Chaincode
+		if name == "" {
+			name = res.Chaincode
+		}
+		what := "state " + name + " " + state.Key
+		cc, found := n.Chaincode(name)
+		if !found {
+			diffs = append(diffs, Difference{What: what, Expected: "a chaincode " + name, Actual: missing})
+			continue
+		}
+		valAsBytes, found := cc.Stub.State[state.Key]
+		switch {
+		case state.Absent && found:
+			diffs = append(diffs, Difference{What: what, Expected: missing, Actual: string(valAsBytes)})
+		case !state.Absent && !found:
+			diffs = append(diffs, Difference{What: what, Expected: format(state.Value), Actual: missing})
+		case found && state.Value != nil:
+			diffs = append(diffs, match(what, "", state.Value, decode(valAsBytes))...)
+		}
+	}
+	return diffs
+}
+// ============================================================================================================================
+// checkEvent - compare the last event of a transaction with the expected one
+// ============================================================================================================================
+func checkEvent(expected Event, res simulator.Result) []Difference {
+	if len(res.Events) == 0 {
+		return []Difference{{What: "event", Expected: expected.Name, Actual: missing}}
+	}
+	event := res.Events[len(res.Events)-1]
+	if event.Name != expected.Name {
+		return []Difference{{What: "event", Expected: expected.Name, Actual: event.Name + " " + string(event.Payload)}}
+	}
+	if expected.Payload == nil {
+		return nil
+	}
+	return match("event " + event.Name, "", expected.Payload, decode(event.Payload))
+}
+// ============================================================================================================================
+// match - the differences between an expected value and an actual one, an expected object only names the fields it checks
+// ============================================================================================================================
+func match(what string, path string, expected interface{}, actual interface{}) []Difference {
+	switch exp := expected.(type) {
+	case map[string]interface{}:
+		act, ok := actual.(map[string]interface{})
+		if !ok {
+			return []Difference{{What: what, Path: path, Expected: "an object", Actual: format(actual)}}
+		}
+		keys := make([]string, 0, len(exp))
+		for key := range exp {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		var diffs []Difference
+		for _, key := range keys {
+			value, found := act[key]
+			if !found {
+				diffs = append(diffs, Difference{What: what, Path: path + "." + key, Expected: format(exp[key]), Actual: missing})
+				continue
+			}
+			diffs = append(diffs, match(what, path + "." + key, exp[key], value)...)
+		}
+		return diffs
+	case []interface{}:
+		act, ok := actual.([]interface{})
+		if !ok {
+			return []Difference{{What: what, Path: path, Expected: "an array", Actual: format(actual)}}
+		}
+		if len(act) != len(exp) {
+			return []Difference{{What: what, Path: path, Expected: strconv.Itoa(len(exp)) + " items", Actual: strconv.Itoa(len(act)) + " items: " + format(actual)}}
+		}
+		var diffs []Difference
+		for i := range exp {
+			diffs = append(diffs, match(what, path + "[" + strconv.Itoa(i) + "]", exp[i], act[i])...)
+		}
+		return diffs
+	case json.Number:
+		if act, ok := actual.(json.Number); ok {
+			expFloat, errExp := exp.Float64()
+			actFloat, errAct := act.Float64()
+			if act == exp || (errExp == nil && errAct == nil && expFloat == actFloat) {
+				return nil
+			}
+		}
+	default:
+		if exp == actual {
+			return nil
+		}
+	}
+	return []Difference{{What: what, Path: path, Expected: format(expected), Actual: format(actual)}}
+}
+// ============================================================================================================================
+// decode - a JSON payload, or the payload as a string when it is not JSON, e.g. the pain.001 XML
+// ============================================================================================================================
+func decode(payload []byte) interface{} {
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	if decoder.Decode(&value) != nil {
+		return string(payload)
+	}
+	return value
+}
+// ============================================================================================================================
+// format - a value as compact JSON
+// ============================================================================================================================
+func format(value interface{}) string {
+	valAsBytes, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(valAsBytes)
+}
+
+func quote(s string) string {
+	return strconv.Quote(s)
+}
+
+func outcome(failed bool) string {
+	if failed {
+		return "failure"
+	}
+	return "success"
+}
+
+func stepName(i int, step Step) string {
+	if step.Name == "" {
+		return strconv.Itoa(i+1)
+	}
+	return strconv.Itoa(i+1) + " (" + step.Name + ")"
+}
+// ============================================================================================================================
+// Write - print the report, the steps that passed are listed only when verbose
+// ============================================================================================================================
+func (r *Report) Write(w io.Writer, verbose bool) {
+	title := r.Scenario.Name
+	if r.Scenario.File != "" {
+		title = r.Scenario.File + ": " + title
+	}
+	if r.Passed() {
+		fmt.Fprintf(w, "PASS %s (%d steps)\n", title, len(r.Steps))
+	}else{
+		fmt.Fprintf(w, "FAIL %s\n", title)
+	}
+	for _, step := range r.Steps {
+		if len(step.Differences) == 0 {
+			if verbose {
+				fmt.Fprintf(w, "  ok   step %s: %s %s\n", stepName(step.Index, step.Step), step.Result.TxID, step.Step.Function)
+			}
+			continue
+		}
+		fmt.Fprintf(w, "  FAIL step %s: %s %s %s\n", stepName(step.Index, step.Step), step.Result.TxID, step.Step.Function,
+			strings.Join(step.Step.Args, " "))
+		for _, diff := range step.Differences {
+			fmt.Fprintf(w, "    %s%s\n", diff.What, diff.Path)
+			fmt.Fprintf(w, "      - expected: %s\n", diff.Expected)
+			fmt.Fprintf(w, "      + actual:   %s\n", diff.Actual)
+		}
+	}
+	if r.NotRun > 0 {
+		fmt.Fprintf(w, "  %d later step(s) not run\n", r.NotRun)
+	}
+}
@@ -0,0 +1,127 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements.  See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership.  The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License.  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+// Package scenario runs declarative trade scenarios, JSON files listing the functions of a trade with the
+// event, response and ledger state each one is expected to leave, on a simulated network and reports
+// every difference between what was expected and what happened.
+package scenario
+
+import (
+"bytes"
+"errors"
+"io/ioutil"
+"encoding/json"
+
+"github.com/wipro-blockchain/TF-v1/internal/simulator"
+)
+
+// Scenario is one trade, run on a fresh network
+type Scenario struct {
+	Name string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Single bool `json:"single,omitempty"`						// run on the single TradeFinance chaincode instead of the four
+	Balance string `json:"balance,omitempty"`					// opening balance of the accounts, simulator.DefaultBalance by default
+	Steps []Step `json:"steps"`
+	File string `json:"-"`
+}
+
+// Step is one transaction of a scenario and what it is expected to leave
+type Step struct {
+	Name string `json:"name"`
+	Function string `json:"function"`						// a function name, or <chaincode>:<function>
+	Args []string `json:"args"`
+	As string `json:"as,omitempty"`							// the caller as <mspId>:<commonName>, the admin by default
+	Expect Expect `json:"expect"`
+}
+
+// Expect is what a step is expected to leave, a field left out is not checked
+type Expect struct {
+	Fail bool `json:"fail,omitempty"`						// the step is expected to be rejected or to end with an errEvent
+	Message *string `json:"message,omitempty"`				// the "message" field of the event, or the error
+	Event *Event `json:"event,omitempty"`
+	Response interface{} `json:"response,omitempty"`		// the fields the response must have
+	State []State `json:"state,omitempty"`
+}
+
+// Event is an expected event, a peer emits only the last event set by a transaction
+type Event struct {
+	Name string `json:"name"`
+	Payload interface{} `json:"payload,omitempty"`			// the fields the payload must have
+}
+
+// State is an expected state value of a chaincode
+type State struct {
+	Chaincode string `json:"chaincode,omitempty"`			// the deployed name, the chaincode that ran the step by default
+	Key string `json:"key"`
+	Value interface{} `json:"value,omitempty"`				// the fields the value must have
+	Absent bool `json:"absent,omitempty"`					// the key must not exist
+}
+
+// ============================================================================================================================
+// Load - read a scenario file, unknown fields are rejected so a misspelt expectation is not silently skipped
+// ============================================================================================================================
+func Load(file string) (*Scenario, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	s, err := Parse(data)
+	if err != nil {
+		return nil, errors.New(file + ": " + err.Error())
+	}
+	s.File = file
+	return s, nil
+}
+// ============================================================================================================================
+// Parse - decode and check a scenario
+// ============================================================================================================================
+func Parse(data []byte) (*Scenario, error) {
+	s := &Scenario{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	decoder.UseNumber()
+	if err := decoder.Decode(s); err != nil {
+		return nil, err
+	}
+	if s.Name == "" {
+		return nil, errors.New("The scenario has no name")
+	}
+	if len(s.Steps) == 0 {
+		return nil, errors.New("The scenario has no steps")
+	}
+	for i, step := range s.Steps {
+		if step.Function == "" {
+			return nil, errors.New("Step " + stepName(i, step) + " has no function")
+		}
+		if step.As != "" {
+			if _, err := simulator.Identity(step.As); err != nil {
+				return nil, errors.New("Step " + stepName(i, step) + ": " + err.Error())
+			}
+		}
+		for _, state := range step.Expect.State {
+			if state.Key == "" {
+				return nil, errors.New("Step " + stepName(i, step) + " expects the state of an empty key")
+			}
+			if state.Absent && state.Value != nil {
+				return nil, errors.New("Step " + stepName(i, step) + " expects " + state.Key + " to be absent and to have a value")
+			}
+		}
+	}
+	return s, nil
+}
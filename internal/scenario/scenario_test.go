@@ -0,0 +1,115 @@
+package scenario
+
+import (
+"bytes"
+"path/filepath"
+"strings"
+"testing"
+)
+
+// TestScenarios runs every scenario of the repository, so the business scenarios are regression tests too
+func TestScenarios(t *testing.T) {
+	files, err := filepath.Glob("../../scenarios/*.json")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no scenarios found: %v", err)
+	}
+	for _, file := range files {
+		s, err := Load(file)
+		if err != nil {
+			t.Errorf("%s", err)
+			continue
+		}
+		report, err := Run(s)
+		if err != nil {
+			t.Errorf("%s: %s", file, err)
+			continue
+		}
+		if !report.Passed() {
+			var out bytes.Buffer
+			report.Write(&out, false)
+			t.Errorf("%s", out.String())
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	cases := map[string]string{
+		`{"name": "x", "steps": [{"function": "create_po", "expect": {"evnt": {"name": "evtsender"}}}]}`: `unknown field "evnt"`,
+		`{"steps": [{"function": "create_po"}]}`: "The scenario has no name",
+		`{"name": "x", "steps": []}`: "The scenario has no steps",
+		`{"name": "x", "steps": [{"name": "raise"}]}`: "Step 1 (raise) has no function",
+		`{"name": "x", "steps": [{"function": "create_po", "as": "BuyerMSP"}]}`: "Step 1: An identity is <mspId>:<commonName>, not BuyerMSP",
+		`{"name": "x", "steps": [{"function": "create_po", "expect": {"state": [{"key": "PO1", "absent": true, "value": {}}]}}]}`: "Step 1 expects PO1 to be absent and to have a value",
+	}
+	for data, message := range cases {
+		if _, err := Parse([]byte(data)); err == nil || !strings.Contains(err.Error(), message) {
+			t.Errorf("Parse(%s): %v", data, err)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	actual := decode([]byte(`{"po_status": "Created", "amount": 2500.0, "lines": [{"item_id": "ITM-1"}], "extra": "ignored"}`))
+	expected := decode([]byte(`{"po_status": "Created", "amount": 2500, "lines": [{"item_id": "ITM-1"}]}`))
+	if diffs := match("state", "", expected, actual); len(diffs) != 0 {
+		t.Errorf("a matching subset differs: %+v", diffs)
+	}
+
+	expected = decode([]byte(`{"po_status": "Accepted", "amount": "2500", "lines": [], "seller_sign": "true"}`))
+	var paths []string
+	for _, diff := range match("state", "", expected, actual) {
+		paths = append(paths, diff.Path + " " + diff.Expected + " " + diff.Actual)
+	}
+	if strings.Join(paths, "|") != `.amount "2500" 2500.0|.lines 0 items 1 items: [{"item_id":"ITM-1"}]|.po_status "Accepted" "Created"|.seller_sign "true" (missing)` {
+		t.Errorf("differences: %q", paths)
+	}
+}
+
+func TestReport(t *testing.T) {
+	s, err := Parse([]byte(`{"name": "Wrong status", "steps": [
+		{"name": "raise", "function": "create_po", "args": ["PO1", "Sellerco", "Buyerco", "2024-03-01", "2024-01-15", "Created", "ITM-1", "Rice", "100", "25", "true", "false"],
+			"expect": {"message": "PO created", "state": [{"key": "PO_PO1", "value": {"po_status": "Accepted"}}]}},
+		{"name": "read", "function": "getPO_byID", "args": ["PO1"]}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := Run(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Passed() || len(report.Steps) != 1 || report.NotRun != 1 || len(report.Steps[0].Differences) != 2 {
+		t.Fatalf("report: %+v", report)
+	}
+	var out bytes.Buffer
+	report.Write(&out, false)
+	expected := `FAIL Wrong status
+  FAIL step 1 (raise): tx17 create_po PO1 Sellerco Buyerco 2024-03-01 2024-01-15 Created ITM-1 Rice 100 25 true false
+    message
+      - expected: "PO created"
+      + actual:   "PO created succcessfully"
+    state managePO PO_PO1.po_status
+      - expected: "Accepted"
+      + actual:   "Created"
+  1 later step(s) not run
+`
+	if out.String() != expected {
+		t.Errorf("report:\n%s", out.String())
+	}
+}
+
+func TestRunSingle(t *testing.T) {
+	s, err := Parse([]byte(`{"name": "Single", "single": true, "balance": "500.00", "steps": [
+		{"function": "getAccountDetails", "expect": {"response": {"buyerAccountBalance": "500.00"}}},
+		{"function": "nosuchfunction", "expect": {}}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := Run(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Passed() || len(report.Steps) != 2 || report.Steps[0].Result.Chaincode != "tradeFinance" ||
+		len(report.Steps[1].Differences) != 1 || report.Steps[1].Differences[0].What != "function" {
+		t.Errorf("report: %+v", report)
+	}
+}
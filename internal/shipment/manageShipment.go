@@ -0,0 +1,2462 @@
+/*/*
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license shipments.  See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership.  The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License.  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+package shipment
+
+import (
+"errors"
+"strconv"
+"sort"
+"time"
+"math"
+"crypto/ecdsa"
+"crypto/sha256"
+"crypto/x509"
+"encoding/base64"
+"encoding/json"
+"encoding/pem"
+
+"github.com/hyperledger/fabric-chaincode-go/shim"
+"github.com/wipro-blockchain/TF-v1/events"
+"github.com/wipro-blockchain/TF-v1/internal/router"
+)
+
+// ManageShipment is the Shipment domain of the trade-finance chaincodes
+type ManageShipment struct {
+	linker router.Linker						// reaches the other chaincodes, see router.Linker
+}
+
+var ShipmentIndexStr = "_Shipmentindex"				//name for the key/value that will store a list of all known Shipment
+var shipmentFields = []string{"shipmentId", "transId", "agreementId", "shipment_status", "source", "destination",
+	"actualDelivery_date", "shipment_date", "shipper_name"}		//create_shipment arguments by JSON name, in order
+var shipmentUpdateFields = append(append([]string{}, shipmentFields...), "version")	//update_shipment arguments by JSON name, in order
+
+type Shipment struct{							// Attributes of a Shipment 
+	ShipmentID string `json:"shipmentId"`	
+	TransID string `json:"transId"`
+	AgreementID string `json:"agreementId"`
+	Shipment_status string `json:"shipment_status"`
+	Source string `json:"source"`					
+	Destination string `json:"destination"`
+	ActualDelivery_date string `json:"actualDelivery_date"`
+	Shipment_date string `json:"shipment_date"`
+	ShipperName string `json:"shipper_name"`
+	Clearance_status string `json:"clearance_status" metadata:",optional"`
+	Version int `json:"version" metadata:",optional"`						// incremented by every write
+	LastModifiedTxID string `json:"lastModifiedTxId" metadata:",optional"`	// the transaction of the last write
+	CreatedAt string `json:"createdAt" metadata:",optional"`					// the ledger time of the create, see router.TxStamp
+	CreatedBy string `json:"createdBy" metadata:",optional"`					// the caller of the create
+	UpdatedAt string `json:"updatedAt" metadata:",optional"`					// the ledger time of the last write
+	UpdatedBy string `json:"updatedBy" metadata:",optional"`					// the caller of the last write
+}
+
+type ShipmentItem struct{						// Quantity of an Agreement line carried by a Shipment
+	ItemId string `json:"item_id"`
+	Quantity string `json:"quantity"`
+}
+
+type TrackingEvent struct{						// A milestone reported for a Shipment
+	ShipmentID string `json:"shipmentId"`
+	EventType string `json:"event_type"`
+	Location string `json:"location"`
+	Timestamp string `json:"timestamp"`
+	ReportingParty string `json:"reporting_party"`
+	Sequence int `json:"sequence" metadata:",optional"`
+}
+
+var TrackingEventStatus = map[string]string{		// Shipment_status derived from the latest tracking event
+	"PickedUp": "Picked Up",
+	"DepartedPort": "Departed Port",
+	"ArrivedPort": "Arrived Port",
+	"CustomsHold": "Customs Hold",
+	"CustomsCleared": "Customs Cleared",
+	"OutForDelivery": "Out For Delivery",
+	"Delivered": "Delivered",
+	"Exception": "Exception",
+}
+
+type BillOfLading struct{						// Electronic bill of lading, the title to the goods of a Shipment
+	EblID string `json:"eblId"`
+	ShipmentID string `json:"shipmentId"`
+	Issuer string `json:"issuer"`
+	Holder string `json:"holder"`
+	Ebl_status string `json:"ebl_status"`				// Issued, Surrendered
+	SurrenderedAt string `json:"surrendered_at"`
+	Endorsement_chain []string `json:"endorsement_chain"`	// the holders in turn: shipper, seller bank, buyer bank, buyer
+	Endorsements []Endorsement `json:"endorsements"`
+}
+
+type Endorsement struct{
+	FromHolder string `json:"from_holder"`
+	ToHolder string `json:"to_holder"`
+	TxID string `json:"txId"`
+}
+
+type ColdChainConfig struct{					// Thresholds and trusted sensor devices of a cold-chain Shipment
+	ShipmentID string `json:"shipmentId"`
+	MinTemperature string `json:"min_temperature"`
+	MaxTemperature string `json:"max_temperature"`
+	MinHumidity string `json:"min_humidity"`
+	MaxHumidity string `json:"max_humidity"`
+	Devices map[string]string `json:"devices"`		// deviceId -> PEM encoded ECDSA public key
+}
+
+type SensorReading struct{
+	DeviceID string `json:"deviceId"`
+	Timestamp string `json:"timestamp"`
+	Temperature float64 `json:"temperature"`
+	Humidity float64 `json:"humidity"`
+	Latitude float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Signature string `json:"signature"`				// base64 ASN.1 ECDSA signature over sensorReadingPayload
+	Excursion []string `json:"excursion" metadata:",optional"`				// metrics out of range when the reading was taken
+}
+
+type TelemetrySummary struct{
+	ShipmentID string `json:"shipmentId"`
+	Readings int `json:"readings"`
+	Excursions int `json:"excursions"`
+	MinTemperature float64 `json:"min_temperature"`
+	MaxTemperature float64 `json:"max_temperature"`
+	MinHumidity float64 `json:"min_humidity"`
+	MaxHumidity float64 `json:"max_humidity"`
+	TemperatureOutOfRangeSeconds int64 `json:"temperature_out_of_range_seconds"`
+	HumidityOutOfRangeSeconds int64 `json:"humidity_out_of_range_seconds"`
+	OutOfRangeSeconds int64 `json:"out_of_range_seconds"`
+	FirstReading string `json:"first_reading"`
+	LastReading string `json:"last_reading"`
+}
+
+type Clearance struct{							// Customs and port clearance of a Shipment by the port authority of its Agreement
+	ShipmentID string `json:"shipmentId"`
+	AgreementID string `json:"agreementId"`
+	PortAuthName string `json:"port_authority"`
+	Clearance_status string `json:"clearance_status"`		// Documents Requested, Documents Submitted, On Hold, Inspected, Cleared
+	Actions []ClearanceAction `json:"actions"`
+}
+
+type ClearanceAction struct{
+	Action string `json:"action"`
+	Party string `json:"party"`
+	Reason string `json:"reason"`
+	TxID string `json:"txId"`
+}
+
+type DeliverySLA struct{						// Planned versus actual delivery of a Shipment
+	ShipmentID string `json:"shipmentId"`
+	AgreementID string `json:"agreementId"`
+	ShipperName string `json:"shipper_name"`
+	PlannedDelivery_date string `json:"plannedDelivery_date"`		// Agreement.Delivery_date
+	ExpectedDelivery_date string `json:"expectedDelivery_date"`		// PO.ExpectedDeliveryDate, empty when the PO chaincode is not registered
+	ActualDelivery_date string `json:"actualDelivery_date"`
+	VarianceDays int `json:"variance_days"`							// actual minus planned, negative when early
+	PoVarianceDays int `json:"po_variance_days"`
+	DaysLate int `json:"days_late"`
+	SLA_status string `json:"sla_status"`								// On Time, Late
+	LiquidatedDamages string `json:"liquidated_damages"`
+}
+
+type ShipperPerformance struct{
+	ShipperName string `json:"shipper_name"`
+	Deliveries int `json:"deliveries"`
+	OnTime int `json:"on_time"`
+	Late int `json:"late"`
+	OnTimeRate string `json:"on_time_rate"`
+	AverageDaysLate string `json:"average_days_late"`
+	LiquidatedDamages string `json:"liquidated_damages"`
+}
+
+type linkedAgreement struct{					// The fields of the Agreement of a Shipment read through the linker
+	AgreementID string `json:"agreementId"`
+	TransID string `json:"transId"`
+	Agreement_status string `json:"agreement_status"`
+	BuyerName string `json:"buyer_name"`
+	ShipperName string `json:"shipper_name"`
+	BB_name string `json:"bb_name"`
+	SB_name string `json:"sb_name"`
+	PortAuthName string `json:"agreementPortAuth_name"`
+}
+
+var ClearanceActionStatus = map[string]string{		// Clearance_status after each port authority action
+	"RequestDocuments": "Documents Requested",
+	"PlaceHold": "On Hold",
+	"Inspect": "Inspected",
+	"Clear": "Cleared",
+}
+
+var TrackingTimeLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02"}
+var LegacyDateLayouts = []string{"02/01/2006", "02-01-2006", "02.01.2006", "2006/01/02", "20060102", "2 Jan 2006",
+	"Jan 2, 2006", "02-Jan-2006"}		//dates of the Agreements written before dates were checked, a network that wrote month first lists 01/02/2006
+// ============================================================================================================================
+// New - Shipment management, reaching the other chaincodes through linker
+// ============================================================================================================================
+func New(linker router.Linker) *ManageShipment {
+	return &ManageShipment{linker: linker}
+}
+// ============================================================================================================================
+// shipmentKey - key under which a Shipment is stored, apart from the records of the other types and the indexes
+// ============================================================================================================================
+func shipmentKey(shipmentId string) string {
+	return "Shipment_" + shipmentId
+}
+// ============================================================================================================================
+// Init - reset all the things
+// ============================================================================================================================
+func (t *ManageShipment) Init(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+	var msg string
+	var err error
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting \"Intial_Value\" as an argument\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	// Initialize the chaincode
+	msg = args[0]
+	router.Println("ManageShipment chaincode is deployed successfully.");
+	
+	// Write the state to the ledger
+	err = stub.PutState("abc", []byte(msg))				//making a test var "abc", I find it handy to read/write to it right away to test the network
+	if err != nil {
+		return nil, err
+	}
+	
+	var empty []string
+	jsonAsBytes, _ := json.Marshal(empty)								//marshal an emtpy array of strings to clear the index
+	err = stub.PutState(ShipmentIndexStr, jsonAsBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	err = router.Emit(stub, events.Event{Type: events.ChaincodeDeployed, EntityID: "ManageShipment",
+		Message: "ManageShipment chaincode is deployed successfully."}, nil, nil)
+	if err != nil {
+		return nil, err
+	} 
+	return nil, nil
+}
+// ============================================================================================================================
+// KeyMoves - the records of Shipment management to move from their bare ID to their namespaced key, see router.Migrator
+// ============================================================================================================================
+func (t *ManageShipment) KeyMoves() []router.KeyMove {
+	return []router.KeyMove{{Index: ShipmentIndexStr, Key: shipmentKey}}
+}
+// ============================================================================================================================
+// Invokes - the invoke functions of Shipment management, by function name
+// ============================================================================================================================
+func (t *ManageShipment) Invokes() map[string]router.Handler {
+	return map[string]router.Handler{
+		"create_shipment": router.Named(t.create_shipment, "create_shipment", shipmentFields, nil),					//create a new Shipment
+		"delete_shipment": t.delete_shipment,					// delete an Shipment
+		"update_shipment": router.Named(t.update_shipment, "update_shipment", shipmentUpdateFields, t.getShipment_byID),					//update an Shipment
+		"add_tracking_event": t.add_tracking_event,					//append a tracking event to a Shipment
+		"issue_ebl": t.issue_ebl,					//issue the bill of lading of a Shipment
+		"transfer_ebl": t.transfer_ebl,					//endorse the bill of lading to a new holder
+		"surrender_ebl": t.surrender_ebl,					//surrender the bill of lading at destination
+		"release_cargo": t.release_cargo,					//release the cargo of a surrendered bill of lading
+		"set_cold_chain_thresholds": t.set_cold_chain_thresholds,					//configure cold-chain thresholds of a Shipment
+		"register_sensor_device": t.register_sensor_device,					//trust a sensor device for a Shipment
+		"add_sensor_readings": t.add_sensor_readings,					//record a batch of signed sensor readings
+		"port_clearance_action": t.port_clearance_action,					//port authority acts on a Shipment
+		"submit_clearance_documents": t.submit_clearance_documents,					//answer a port authority document request
+		"evaluate_delivery_sla": t.evaluate_delivery_sla,					//compare planned and actual delivery of a Shipment
+		"set_shipment_items": t.set_shipment_items,					//record the items a Shipment carries
+	}
+}
+// ============================================================================================================================
+// Queries - the query functions of Shipment management, by function name
+// ============================================================================================================================
+func (t *ManageShipment) Queries() map[string]router.Handler {
+	return map[string]router.Handler{
+		"getShipment_byID": t.getShipment_byID,					//Read a Shipment by ShipmentID
+		"getShipment_byStatus": t.getShipment_byStatus,					//Read a Shipment by Shipper
+		"get_AllShipment": t.get_AllShipment,					//Read all Shipments
+		"getShipment_byShipper": t.getShipment_byShipper,					//Read a Shipment by Shipper
+		"getShipment_byAgreement": t.getShipment_byAgreement,					//Read the Shipments of an Agreement
+		"get_shipment_timeline": t.get_shipment_timeline,					//Read the tracking events of a Shipment
+		"get_ebl": t.get_ebl,					//Read the bill of lading of a Shipment
+		"get_sensor_readings": t.get_sensor_readings,					//Read the sensor readings of a Shipment
+		"get_telemetry_summary": t.get_telemetry_summary,					//Read the cold-chain summary of a Shipment
+		"get_clearance": t.get_clearance,					//Read the port clearance of a Shipment
+		"get_delivery_sla": t.get_delivery_sla,					//Read the delivery SLA of a Shipment
+		"get_shipper_performance": t.get_shipper_performance,					//Read on-time performance per shipper
+		"get_shipment_items": t.get_shipment_items,					//Read the items a Shipment carries
+	}
+}
+// ============================================================================================================================
+// getShipment_byID - get Shipment details for a specific ShipmentID from chaincode state
+// ============================================================================================================================
+func (t *ManageShipment) getShipment_byID(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var shipmentId string
+	var err error
+	router.Println("Fetching Shipment by shipmentID")
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting \"ShipmentID\" as an argument\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	// set shipmentId
+	shipmentId = args[0]
+	valAsbytes, err := stub.GetState(shipmentKey(shipmentId))									//get the shipmentId from chaincode state
+	if err != nil {
+		errMsg := "{ \"message\" : " + router.JSONString(shipmentId + " not Found.") + ", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	router.Print("valAsbytes : ")
+	router.Println(valAsbytes)
+	router.Println("Fetched Shipment by shipmentID")
+	return valAsbytes, nil													//send it onward
+}
+// ============================================================================================================================
+//  getShipment_byShipper - get Shipment details by Shipper from chaincode state
+// ============================================================================================================================
+func (t *ManageShipment) getShipment_byShipper(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var jsonResp, shipper_name, errResp string
+	var shipmentIndex []string
+	var valIndex Shipment
+	router.Println("Fetching Shipment by Shipper")
+	var err error
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting \"Shipper_Name\" as an argument\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	// set Shipper name
+	shipper_name = args[0]
+	router.Println("shipper_name : " + shipper_name)
+	shipmentAsBytes, err := stub.GetState(ShipmentIndexStr)
+	if err != nil {
+		return nil, errors.New("Failed to get Shipment index string")
+	}
+	router.Print("shipmentAsBytes : ")
+	router.Println(shipmentAsBytes)
+	json.Unmarshal(shipmentAsBytes, &shipmentIndex)								//un stringify it aka JSON.parse()
+	router.Print("shipmentIndex : ")
+	router.Println(shipmentIndex)
+	router.Println("len(shipmentIndex) : ")
+	router.Println(len(shipmentIndex))
+	jsonResp = "{"
+	for i,val := range shipmentIndex{
+		router.Println(strconv.Itoa(i) + " - looking at " + val + " for getShipment_byShipper")
+		valueAsBytes, err := stub.GetState(shipmentKey(val))
+		if err != nil {
+			errResp = "{\"Error\":\"Failed to get state for " + val + "\"}"
+			return nil, errors.New(errResp)
+		}
+		router.Print("valueAsBytes : ")
+		router.Println(valueAsBytes)
+		json.Unmarshal(valueAsBytes, &valIndex)
+		router.Print("valIndex: ")
+		router.Print(valIndex)
+		if valIndex.ShipperName == shipper_name{
+			router.Println("Shipper found")
+			jsonResp = jsonResp + "\""+ val + "\":" + string(valueAsBytes[:])
+			router.Println("jsonResp inside if")
+			router.Println(jsonResp)
+			if i < len(shipmentIndex)-1 {
+				jsonResp = jsonResp + ","
+			}
+		} else{
+			errMsg := "{ \"message\" : " + router.JSONString(shipper_name + " Not Found.") + ", \"code\" : \"503\"}"
+			err = stub.SetEvent("errEvent", []byte(errMsg))
+			if err != nil {
+				return nil, err
+			} 
+			return nil, nil
+		}
+	}
+	jsonResp = jsonResp + "}"
+	router.Println("jsonResp : " + jsonResp)
+	router.Print("jsonResp in bytes : ")
+	router.Println([]byte(jsonResp))
+	router.Println("Fetched Shipment by Shipper")
+	return []byte(jsonResp), nil											//send it onward
+}
+
+// ============================================================================================================================
+//  getShipment_byStatus - get Shipment details for a specific Seller from chaincode state
+// ============================================================================================================================
+func (t *ManageShipment) getShipment_byStatus(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var jsonResp, shipment_status, errResp string
+	var shipmentIndex []string
+	var valIndex Shipment
+	router.Println("Fetching Shipment by Shipment Status")
+	var err error
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting \"Shipment_status\" as an argument\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	// set shipment_status
+	shipment_status = args[0]
+	router.Println("shipment_status: " + shipment_status)
+	shipmentAsBytes, err := stub.GetState(ShipmentIndexStr)
+	if err != nil {
+		return nil, errors.New("Failed to get Shipment index")
+	}
+	router.Print("shipmentAsBytes : ")
+	router.Println(shipmentAsBytes)
+	json.Unmarshal(shipmentAsBytes, &shipmentIndex)								//un stringify it aka JSON.parse()
+	router.Print("shipmentIndex : ")
+	router.Println(shipmentIndex)
+	router.Println("len(shipmentIndex) : ")
+	router.Println(len(shipmentIndex))
+	jsonResp = "{"
+	for i,val := range shipmentIndex{
+		router.Println(strconv.Itoa(i) + " - looking at " + val + " for getting sellerName")
+		valueAsBytes, err := stub.GetState(shipmentKey(val))
+		if err != nil {
+			errResp = "{\"Error\":\"Failed to get state for " + val + "\"}"
+			return nil, errors.New(errResp)
+		}
+		router.Print("valueAsBytes : ")
+		router.Println(valueAsBytes)
+		json.Unmarshal(valueAsBytes, &valIndex)
+		router.Print("valIndex: ")
+		router.Print(valIndex)
+		if valIndex.Shipment_status == shipment_status{
+			router.Println("Shipment found")
+			jsonResp = jsonResp + "\""+ val + "\":" + string(valueAsBytes[:])
+			router.Println("jsonResp inside if")
+			router.Println(jsonResp)
+			if i < len(shipmentIndex)-1 {
+				jsonResp = jsonResp + ","
+			}
+		}else{
+			errMsg := "{ \"message\" : " + router.JSONString(shipment_status + " Not Found.") + ", \"code\" : \"503\"}"
+			err = stub.SetEvent("errEvent", []byte(errMsg))
+			if err != nil {
+				return nil, err
+			} 
+			return nil, nil
+		}
+		
+	}
+	
+	jsonResp = jsonResp + "}"
+	router.Println("jsonResp : " + jsonResp)
+	router.Print("jsonResp in bytes : ")
+	router.Println([]byte(jsonResp))
+	router.Println("Fetched Shipment by Shipment Status")
+	return []byte(jsonResp), nil											//send it onward
+}
+// ============================================================================================================================
+//  get_AllShipment- get details of all Shipment from chaincode state
+// ============================================================================================================================
+func (t *ManageShipment) get_AllShipment(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var jsonResp, errResp string
+	var shipmentIndex []string
+	router.Println("Fetching All Shipments")
+	var err error
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting \" \" as an argument\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	shipmentAsBytes, err := stub.GetState(ShipmentIndexStr)
+	if err != nil {
+		return nil, errors.New("Failed to get Shipment index")
+	}
+	router.Print("shipmentAsBytes : ")
+	router.Println(shipmentAsBytes)
+	json.Unmarshal(shipmentAsBytes, &shipmentIndex)								//un stringify it aka JSON.parse()
+	router.Print("shipmentIndex : ")
+	router.Println(shipmentIndex)
+	jsonResp = "{"
+	for i,val := range shipmentIndex{
+		router.Println(strconv.Itoa(i) + " - looking at " + val + " for all Shipment")
+		valueAsBytes, err := stub.GetState(shipmentKey(val))
+		if err != nil {
+			errResp = "{\"Error\":\"Failed to get state for " + val + "\"}"
+			return nil, errors.New(errResp)
+		}
+		router.Print("valueAsBytes : ")
+		router.Println(valueAsBytes)
+		jsonResp = jsonResp + "\""+ val + "\":" + string(valueAsBytes[:])
+		if i < len(shipmentIndex)-1 {
+			jsonResp = jsonResp + ","
+		}
+	}
+	router.Println("len(shipmentIndex) : ")
+	router.Println(len(shipmentIndex))
+	jsonResp = jsonResp + "}"
+	router.Println("jsonResp : " + jsonResp)
+	router.Print("jsonResp in bytes : ")
+	router.Println([]byte(jsonResp))
+	router.Println("Fetched All Shipments")
+	return []byte(jsonResp), nil
+											//send it onward
+}
+// ============================================================================================================================
+// delete_shipment - remove a Shipment from chain, the items booked against its Agreement are given back
+// ============================================================================================================================
+func (t *ManageShipment) delete_shipment(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting \"shipmentID\" as an argument.\", \"code\" : \"503\"}"
+		err := stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	// set shipmentId
+	shipmentId := args[0]
+	recordAsBytes, err := stub.GetState(shipmentKey(shipmentId))
+	if err != nil {
+		errMsg := "{ \"message\" : " + router.JSONString("Failed to get state for " + shipmentId) + ", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	shipment := Shipment{}
+	json.Unmarshal(recordAsBytes, &shipment)
+	itemsAsBytes, err := stub.GetState(shipmentItemsKey(shipmentId))
+	if err != nil {
+		return nil, errors.New("Failed to get Shipment items")
+	}
+	if len(itemsAsBytes) > 0 {
+		_, err = t.linker.InvokeLinked(stub, "agreement", "release_shipped_quantity", shipment.AgreementID, shipmentId, string(itemsAsBytes))
+		if err != nil {
+			errMsg := "{ \"shipmentID\" : " + router.JSONString(shipmentId) + ", \"message\" : " + router.JSONString(err.Error()) + ", \"code\" : \"503\"}"
+			err = stub.SetEvent("errEvent", []byte(errMsg))
+			if err != nil {
+				return nil, err
+			} 
+			return nil, nil
+		}
+		err = stub.DelState(shipmentItemsKey(shipmentId))
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = stub.DelState(shipmentKey(shipmentId))													//remove the Shipment from chaincode
+	if err != nil {
+		errMsg := "{ \"message\" : \"Failed to delete state\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+
+	//get the Shipment index
+	shipmentAsBytes, err := stub.GetState(ShipmentIndexStr)
+	if err != nil {
+		return nil, errors.New("Failed to get Shipment index")
+	}
+	router.Println("shipmentAsBytes in delete shipment")
+	router.Println(shipmentAsBytes);
+	var shipmentIndex []string
+	json.Unmarshal(shipmentAsBytes, &shipmentIndex)								//un stringify it aka JSON.parse()
+	router.Println("shipmentIndex in delete shipment")
+	router.Println(shipmentIndex);
+	//remove shipment from index
+	for i,val := range shipmentIndex{
+		router.Println(strconv.Itoa(i) + " - looking at " + val + " for " + shipmentId)
+		if val == shipmentId{															//find the correct Shipment
+			router.Println("Found Shipment with matching shipmentId")
+			shipmentIndex = append(shipmentIndex[:i], shipmentIndex[i+1:]...)			//remove it
+			for x:= range shipmentIndex{											//debug prints...
+				router.Println(strconv.Itoa(x) + " - " + shipmentIndex[x])
+			}
+			break
+		}
+	}
+	jsonAsBytes, _ := json.Marshal(shipmentIndex)									//save new index
+	err = stub.PutState(ShipmentIndexStr, jsonAsBytes)
+	err = router.Emit(stub, events.Event{Type: events.ShipmentDeleted, EntityID: shipmentId, Message: "Shipment deleted succcessfully"},
+		recordAsBytes, nil)
+	if err != nil {
+		return nil, err
+	} 
+	return nil, nil
+}
+// ============================================================================================================================
+// update_shipment - update Shipment into chaincode state. The status and the actual delivery date are those of the latest
+// tracking event, an update must leave them as they are
+// ============================================================================================================================
+func (t *ManageShipment) update_shipment(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var jsonResp string
+	var err error
+	router.Println("Updating Shipment")
+	args, expectedVersion := router.ExpectedVersion(args, 9)
+	if len(args) != 9{
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting 9 arguments, or 10 with the expected version.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	// set shipmentId
+	shipmentId := args[0]
+	shipmentAsBytes, err := stub.GetState(shipmentKey(shipmentId))									//get the Shipment for the specified shipmentId from chaincode state
+	if err != nil {
+		jsonResp = "{\"Error\":\"Failed to get state for " + shipmentId + "\"}"
+		return nil, errors.New(jsonResp)
+	}
+	router.Print("shipmentAsBytes in update shipment")
+	router.Println(shipmentAsBytes);
+	res := Shipment{}
+	json.Unmarshal(shipmentAsBytes, &res)
+	if res.ShipmentID == shipmentId{
+		router.Println("Shipment found with shipmentId : " + shipmentId)
+		router.Println(res);
+		err = router.CheckVersion(shipmentId, res.Version, expectedVersion)
+		if err != nil {
+			err = router.VersionEvent(stub, err)
+			if err != nil {
+				return nil, err
+			}
+			return nil, nil
+		}
+		if res.TransID != args[1] || res.AgreementID != args[2] {
+			err = t.checkLinkedAgreement(stub, args[2], args[1])
+			if err != nil {
+				errMsg := "{ \"shipmentID\" : " + router.JSONString(shipmentId) + ", \"message\" : " + router.JSONString(err.Error() + ".") + ", \"code\" : \"503\"}"
+				err = stub.SetEvent("errEvent", []byte(errMsg))
+				if err != nil {
+					return nil, err
+				}
+				return nil, nil
+			}
+		}
+
+		if args[3] != res.Shipment_status || args[6] != res.ActualDelivery_date {
+			errMsg := "{ \"shipmentID\" : " + router.JSONString(shipmentId) + ", \"message\" : \"shipment_status and actualDelivery_date follow the tracking events, add one with add_tracking_event.\", \"code\" : \"503\"}"
+			err = stub.SetEvent("errEvent", []byte(errMsg))
+			if err != nil {
+				return nil, err
+			}
+			return nil, nil
+		}
+
+		if args[7] != res.Shipment_date {
+			err = router.CheckNotPast(stub, "shipment_date", args[7])
+			if err != nil {
+				err = router.ErrorEvent(stub, err)
+				if err != nil {
+					return nil, err
+				}
+				return nil, nil
+			}
+		}
+
+		res.TransID = args[1]
+		res.AgreementID = args[2]
+		res.Source = args[4]
+		res.Destination = args[5]
+		res.Shipment_date = args[7]
+		res.ShipperName	= args[8]
+		res.Version++
+		res.LastModifiedTxID = stub.GetTxID()
+		res.UpdatedAt, res.UpdatedBy, err = router.TxStamp(stub)
+		if err != nil {
+			return nil, err
+		}
+	}else{
+		errMsg := "{ \"message\" : " + router.JSONString(shipmentId + " Not Found.") + ", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	
+	input := shipmentJSON(res)										//build the Shipment json string
+	err = stub.PutState(shipmentKey(shipmentId), []byte(input))									//store Shipment with id as key
+	if err != nil {
+		return nil, err
+	}
+	err = router.Emit(stub, events.Event{Type: events.ShipmentUpdated, EntityID: shipmentId, Status: res.Shipment_status,
+		Message: "Shipment updated succcessfully"}, shipmentAsBytes, input)
+	if err != nil {
+		return nil, err
+	} 
+	router.Println("Updated Shipment succcessfully.")
+	return nil, nil
+}
+// ============================================================================================================================
+// shipmentJSON - build the Shipment json string manually
+// ============================================================================================================================
+func shipmentJSON(res Shipment) string {
+	return `{`+
+		`"shipmentId": "` + res.ShipmentID + `" , `+
+		`"transId": "` + res.TransID + `" , `+ 
+		`"agreementId": "` + res.AgreementID + `" , `+ 
+		`"shipment_status": "` + res.Shipment_status + `" , `+ 
+		`"source": "` + res.Source + `" , `+
+		`"destination": "` + res.Destination + `" , `+
+		`"actualDelivery_date": "` + res.ActualDelivery_date + `" , `+ 
+		`"shipment_date": "` + res.Shipment_date + `" , `+ 
+		`"shipper_name": "` + res.ShipperName + `" , `+ 
+		`"clearance_status": "` + res.Clearance_status + `" , `+ 
+		`"version": ` + strconv.Itoa(res.Version) + ` , `+ 
+		`"lastModifiedTxId": "` + res.LastModifiedTxID + `" , `+ 
+		`"createdAt": "` + res.CreatedAt + `" , `+
+		`"createdBy": ` + router.JSONString(res.CreatedBy) + ` , `+
+		`"updatedAt": "` + res.UpdatedAt + `" , `+
+		`"updatedBy": ` + router.JSONString(res.UpdatedBy) + ` `+
+		`}`
+}
+// ============================================================================================================================
+// create Shipment - create a new Shipment, store into chaincode state
+// ============================================================================================================================
+func (t *ManageShipment) create_shipment(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var err error
+	if len(args) != 9{
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting 9 arguments.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	router.Println("Creating Shipment")
+		
+		shipmentId := args[0]
+		if shipmentId == "" {
+			shipmentId, err = router.NewID(stub, "SHP-", shipmentKey)			//no ID given, one is derived from the transaction ID
+			if err != nil {
+				return nil, err
+			}
+		}
+		transId := args[1]
+		agreementId := args[2]
+		shipment_status := args[3]
+		source := args[4]
+		destination := args[5]
+		actualDelivery_date := args[6]
+		shipment_date := args[7]
+		shipper_name	:= args[8]
+		createdAt, createdBy, err := router.TxStamp(stub)
+		if err != nil {
+			return nil, err
+		}
+		shipment_date, err = router.RecordDate(stub, "shipment_date", shipment_date)
+		if err != nil {
+			err = router.ErrorEvent(stub, err)
+			if err != nil {
+				return nil, err
+			}
+			return nil, nil
+		}
+		
+		shipmentAsBytes, err := stub.GetState(shipmentKey(shipmentId))
+		if err != nil {
+			return nil, errors.New("Failed to get Shipment ID")
+		}
+		router.Print("shipmentAsBytes: ")
+		router.Println(shipmentAsBytes)
+		res := Shipment{}
+		json.Unmarshal(shipmentAsBytes, &res)
+		router.Print("res: ")
+		router.Println(res)
+		if res.ShipmentID == shipmentId{
+			router.Println("This Shipment already exists: " + shipmentId)
+			errMsg := "{ \"message\" : " + router.JSONString(shipmentId + " already exists.") + ", \"code\" : \"503\"}"
+			err := stub.SetEvent("errEvent", []byte(errMsg))
+			if err != nil {
+				return nil, err
+			} 
+		return nil, nil				//all stop a Shipment by this name exists
+	}
+	err = t.checkLinkedAgreement(stub, agreementId, transId)
+	if err != nil {
+		errMsg := "{ \"shipmentID\" : " + router.JSONString(shipmentId) + ", \"message\" : " + router.JSONString(err.Error() + ".") + ", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	
+	//build the Shipment json string manually
+	input := 	`{`+
+		`"shipmentId": "` + shipmentId + `" , `+
+		`"transId": "` + transId + `" , `+ 
+		`"agreementId": "` + agreementId + `" , `+ 
+		`"shipment_status": "` + shipment_status + `" , `+ 
+		`"source": "` + source + `" , `+
+		`"destination": "` + destination + `" , `+
+		`"actualDelivery_date": "` + actualDelivery_date + `" , `+ 
+		`"shipment_date": "` + shipment_date + `" , `+ 
+		`"shipper_name": "` + shipper_name + `" , `+ 
+		`"clearance_status": "" , `+ 
+		`"version": 1 , `+ 
+		`"lastModifiedTxId": "` + stub.GetTxID() + `" , `+ 
+		`"createdAt": "` + createdAt + `" , `+
+		`"createdBy": ` + router.JSONString(createdBy) + ` , `+
+		`"updatedAt": "` + createdAt + `" , `+
+		`"updatedBy": ` + router.JSONString(createdBy) + ` `+
+		`}`
+		router.Println("input: " + input)
+		router.Print("input in bytes array: ")
+		router.Println([]byte(input))
+	err = stub.PutState(shipmentKey(shipmentId), []byte(input))									//store Shipment with shipmentId as key
+	if err != nil {
+		return nil, err
+	}
+	//get the Shipment index
+	shipmentIndexAsBytes, err := stub.GetState(ShipmentIndexStr)
+	if err != nil {
+		return nil, errors.New("Failed to get Shipment index")
+	}
+	var shipmentIndex []string
+	router.Print("shipmentIndexAsBytes: ")
+	router.Println(shipmentIndexAsBytes)
+	
+	json.Unmarshal(shipmentIndexAsBytes, &shipmentIndex)							//un stringify it aka JSON.parse()
+	router.Print("shipmentIndex after unmarshal..before append: ")
+	router.Println(shipmentIndex)
+	//append
+	shipmentIndex = append(shipmentIndex, shipmentId)									//add Shipment transID to index list
+	router.Println("! Shipment index after appending shipmentId: ", shipmentIndex)
+	jsonAsBytes, _ := json.Marshal(shipmentIndex)
+	router.Print("jsonAsBytes: ")
+	router.Println(jsonAsBytes)
+	err = stub.PutState(ShipmentIndexStr, jsonAsBytes)						//store name of Shipment
+	if err != nil {
+		return nil, err
+	}
+	err = router.Emit(stub, events.Event{Type: events.ShipmentCreated, EntityID: shipmentId, Status: shipment_status,
+		Message: "Shipment created succcessfully"}, nil, input)
+	if err != nil {
+		return nil, err
+	}
+	
+	router.Println("Shipment created succcessfully.")
+	return []byte(shipmentId), nil
+}
+// ============================================================================================================================
+// trackingKey - key under which the tracking events of a Shipment are stored
+// ============================================================================================================================
+func trackingKey(shipmentId string) string {
+	return "Tracking_" + shipmentId
+}
+// ============================================================================================================================
+// parseTrackingTime - parse the timestamp of a tracking event
+// ============================================================================================================================
+func parseTrackingTime(timestamp string) (time.Time, error) {
+	for _, layout := range TrackingTimeLayouts {
+		parsed, err := time.Parse(layout, timestamp)
+		if err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, errors.New("Unknown timestamp format " + timestamp)
+}
+// ============================================================================================================================
+// getTrackingEvents - get the tracking events of a Shipment ordered by timestamp, oldest first
+// ============================================================================================================================
+func getTrackingEvents(stub shim.ChaincodeStubInterface, shipmentId string) ([]TrackingEvent, error) {
+	var events []TrackingEvent
+	eventsAsBytes, err := stub.GetState(trackingKey(shipmentId))
+	if err != nil {
+		return nil, errors.New("Failed to get tracking events for " + shipmentId)
+	}
+	json.Unmarshal(eventsAsBytes, &events)
+	sort.SliceStable(events, func(i, j int) bool {
+		ti, _ := parseTrackingTime(events[i].Timestamp)
+		tj, _ := parseTrackingTime(events[j].Timestamp)
+		if ti.Equal(tj) {
+			return events[i].Sequence < events[j].Sequence
+		}
+		return ti.Before(tj)
+	})
+	return events, nil
+}
+// ============================================================================================================================
+// add_tracking_event - append a tracking event to a Shipment and derive its status from the latest event, reported by the admin
+// MSP or the shipper. A Shipment with an outstanding bill of lading is delivered only once the bill is surrendered, a delivery
+// dated before the ledger date is refused, and so is a delivery whose SLA can not be evaluated
+// ============================================================================================================================
+func (t *ManageShipment) add_tracking_event(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	// add_tracking_event("shipmentId", "eventType", "location", "timestamp", "reportingParty")
+	var err error
+	if len(args) != 5 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting 5 arguments.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	router.Println("Adding tracking event")
+	shipmentId := args[0]
+	eventType := args[1]
+	location := args[2]
+	timestamp := args[3]
+	reportingParty := args[4]
+
+	if _, ok := TrackingEventStatus[eventType]; !ok {
+		errMsg := "{ \"message\" : " + router.JSONString("Unknown tracking event type " + eventType + ".") + ", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	trackedAt, err := parseTrackingTime(timestamp)
+	if err != nil {
+		errMsg := "{ \"message\" : " + router.JSONString(err.Error()) + ", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+
+	shipmentAsBytes, err := stub.GetState(shipmentKey(shipmentId))
+	if err != nil {
+		return nil, errors.New("Failed to get Shipment ID")
+	}
+	res := Shipment{}
+	json.Unmarshal(shipmentAsBytes, &res)
+	if res.ShipmentID != shipmentId{
+		errMsg := "{ \"message\" : " + router.JSONString(shipmentId + " Not Found.") + ", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	if router.CheckAdmin(stub) != nil {
+		if err = router.CheckParty(stub, res.ShipperName); err != nil {
+			return nil, router.ErrorEvent(stub, errors.New("Only the admin MSP or the shipper adds a tracking event. " + err.Error()))
+		}
+	}
+
+	if eventType == "Delivered" {
+		err = router.CheckNotPast(stub, "The delivery of " + shipmentId + " on", trackedAt.UTC().Format("2006-01-02"))
+		if err != nil {
+			return nil, router.ErrorEvent(stub, err)					//the delivery date sets the liquidated damages
+		}
+		ebl, err := getEbl(stub, shipmentId)
+		if err != nil {
+			return nil, err
+		}
+		if ebl.Ebl_status == "Issued" {
+			return nil, router.ErrorEvent(stub, errors.New("Shipment " + shipmentId + " can not be delivered while its bill of lading " +
+				ebl.EblID + " is held by " + ebl.Holder + ", it must be surrendered first."))
+		}
+	}
+
+	timeline, err := getTrackingEvents(stub, shipmentId)
+	if err != nil {
+		return nil, err
+	}
+	event := TrackingEvent{
+		ShipmentID: shipmentId,
+		EventType: eventType,
+		Location: location,
+		Timestamp: timestamp,
+		ReportingParty: reportingParty,
+		Sequence: len(timeline) + 1,
+	}
+	timeline = append(timeline, event)
+	eventsAsBytes, _ := json.Marshal(timeline)
+	err = stub.PutState(trackingKey(shipmentId), eventsAsBytes)					//timeline are only ever appended
+	if err != nil {
+		return nil, err
+	}
+
+	//derive the current status from the latest event
+	timeline, err = getTrackingEvents(stub, shipmentId)
+	if err != nil {
+		return nil, err
+	}
+	latest := timeline[len(timeline)-1]
+	res.Shipment_status = TrackingEventStatus[latest.EventType]
+	if latest.EventType == "Delivered" {
+		res.ActualDelivery_date = latest.Timestamp
+	}
+	res.Version++
+	res.LastModifiedTxID = stub.GetTxID()
+	res.UpdatedAt, res.UpdatedBy, err = router.TxStamp(stub)
+	if err != nil {
+		return nil, err
+	}
+	input := shipmentJSON(res)
+	err = stub.PutState(shipmentKey(shipmentId), []byte(input))
+	if err != nil {
+		return nil, err
+	}
+	if latest.EventType == "Delivered" {
+		_, err = t.putDeliverySLA(stub, res)
+		if err != nil {
+			return nil, errors.New("Delivery SLA of " + shipmentId + " not evaluated, the delivery is not recorded: " + err.Error())
+		}
+	}
+
+	trackedType := events.ShipmentTracked
+	if eventType == "Delivered" {
+		trackedType = events.ShipmentDelivered
+	}
+	err = router.Emit(stub, events.Event{Type: trackedType, EntityID: shipmentId, Status: res.Shipment_status,
+		Data: map[string]interface{}{"eventType": eventType, "location": location, "sequence": event.Sequence},
+		Message: "Tracking event added succcessfully"}, shipmentAsBytes, input)
+	if err != nil {
+		return nil, err
+	}
+	router.Println("Tracking event added succcessfully.")
+	return nil, nil
+}
+// ============================================================================================================================
+// get_shipment_timeline - get every tracking event of a Shipment, oldest first
+// ============================================================================================================================
+func (t *ManageShipment) get_shipment_timeline(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var err error
+	router.Println("Fetching Shipment timeline")
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting \"ShipmentID\" as an argument\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	events, err := getTrackingEvents(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+	if events == nil {
+		events = []TrackingEvent{}
+	}
+	eventsAsBytes, _ := json.Marshal(events)
+	router.Println("Fetched Shipment timeline")
+	return eventsAsBytes, nil
+}
+// ============================================================================================================================
+// eblKey - key under which the bill of lading of a Shipment is stored
+// ============================================================================================================================
+func eblKey(shipmentId string) string {
+	return "EBL_" + shipmentId
+}
+// ============================================================================================================================
+// getEbl - get the bill of lading of a Shipment, EblID is empty when none was issued
+// ============================================================================================================================
+func getEbl(stub shim.ChaincodeStubInterface, shipmentId string) (BillOfLading, error) {
+	res := BillOfLading{}
+	eblAsBytes, err := stub.GetState(eblKey(shipmentId))
+	if err != nil {
+		return res, errors.New("Failed to get bill of lading for " + shipmentId)
+	}
+	json.Unmarshal(eblAsBytes, &res)
+	return res, nil
+}
+// ============================================================================================================================
+// putEbl - store the bill of lading of a Shipment into chaincode state
+// ============================================================================================================================
+func putEbl(stub shim.ChaincodeStubInterface, res BillOfLading) error {
+	eblAsBytes, err := json.Marshal(res)
+	if err != nil {
+		return errors.New("Error while marshalling bill of lading")
+	}
+	return stub.PutState(eblKey(res.ShipmentID), eblAsBytes)
+}
+// ============================================================================================================================
+// nextHolder - the party the bill of lading is endorsed to after its holder, empty when the holder is the last of the chain.
+// A bill issued before the chain was recorded has none and can only be surrendered
+// ============================================================================================================================
+func nextHolder(res BillOfLading) string {
+	for i, party := range res.Endorsement_chain {
+		if party == res.Holder && i+1 < len(res.Endorsement_chain) {
+			return res.Endorsement_chain[i+1]
+		}
+	}
+	return ""
+}
+// ============================================================================================================================
+// issue_ebl - issue the electronic bill of lading of a Shipment, by its shipper who is the first holder. The bill is then
+// endorsed along the chain of the Agreement: shipper, seller bank, buyer bank and buyer
+// ============================================================================================================================
+func (t *ManageShipment) issue_ebl(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	// issue_ebl("shipmentId", "eblId")
+	var err error
+	if len(args) != 2 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting 2 arguments.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	router.Println("Issuing bill of lading")
+	shipmentId := args[0]
+	eblId := args[1]
+
+	shipmentAsBytes, err := stub.GetState(shipmentKey(shipmentId))
+	if err != nil {
+		return nil, errors.New("Failed to get Shipment ID")
+	}
+	shipment := Shipment{}
+	json.Unmarshal(shipmentAsBytes, &shipment)
+	if shipment.ShipmentID != shipmentId{
+		errMsg := "{ \"message\" : " + router.JSONString(shipmentId + " Not Found.") + ", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	res, err := getEbl(stub, shipmentId)
+	if err != nil {
+		return nil, err
+	}
+	if res.EblID != ""{
+		errMsg := "{ \"shipmentID\" : " + router.JSONString(shipmentId) + ", \"message\" : \"A bill of lading was already issued for this Shipment.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	if err = router.CheckParty(stub, shipment.ShipperName); err != nil {
+		return nil, router.ErrorEvent(stub, err)
+	}
+	parties, err := t.agreementParties(stub, shipment.AgreementID)
+	if err != nil {
+		return nil, router.ErrorEvent(stub, err)
+	}
+
+	previousAsBytes, _ := json.Marshal(res)
+	res.EblID = eblId
+	res.ShipmentID = shipmentId
+	res.Issuer = shipment.ShipperName
+	res.Holder = shipment.ShipperName
+	res.Ebl_status = "Issued"
+	res.Endorsement_chain = []string{shipment.ShipperName, parties.SB_name, parties.BB_name, parties.BuyerName}
+	res.Endorsements = []Endorsement{}
+	err = putEbl(stub, res)
+	if err != nil {
+		return nil, err
+	}
+	err = router.Emit(stub, events.Event{Type: events.BillOfLadingIssued, EntityID: eblId, Status: res.Ebl_status,
+		Data: map[string]interface{}{"shipmentId": shipmentId}, Message: "Bill of lading issued succcessfully"}, previousAsBytes, res)
+	if err != nil {
+		return nil, err
+	}
+	router.Println("Bill of lading issued succcessfully.")
+	return nil, nil
+}
+// ============================================================================================================================
+// transfer_ebl - endorse the bill of lading from its current holder, who must be the caller, to the next holder of the chain
+// ============================================================================================================================
+func (t *ManageShipment) transfer_ebl(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	// transfer_ebl("shipmentId", "currentHolder", "newHolder")
+	var err error
+	if len(args) != 3 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting 3 arguments.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	router.Println("Transferring bill of lading")
+	shipmentId := args[0]
+	currentHolder := args[1]
+	newHolder := args[2]
+
+	res, err := getEbl(stub, shipmentId)
+	if err != nil {
+		return nil, err
+	}
+	errText := ""
+	if res.EblID == ""{
+		errText = "No bill of lading was issued for this Shipment."
+	}else if res.Ebl_status != "Issued"{
+		errText = "Bill of lading is already " + res.Ebl_status + "."
+	}else if res.Holder != currentHolder{
+		errText = currentHolder + " is not the holder of the bill of lading."
+	}else if next := nextHolder(res); newHolder != next{
+		errText = "The bill of lading is endorsed from " + currentHolder + " to " + next + ", not to " + newHolder + "."
+	}
+	if errText != ""{
+		return nil, router.ErrorEvent(stub, errors.New(errText))
+	}
+	if err = router.CheckParty(stub, currentHolder); err != nil {
+		return nil, router.ErrorEvent(stub, err)
+	}
+
+	previousAsBytes, _ := json.Marshal(res)
+	res.Endorsements = append(res.Endorsements, Endorsement{FromHolder: currentHolder, ToHolder: newHolder, TxID: stub.GetTxID()})
+	res.Holder = newHolder
+	err = putEbl(stub, res)
+	if err != nil {
+		return nil, err
+	}
+	err = router.Emit(stub, events.Event{Type: events.BillOfLadingTransferred, EntityID: res.EblID, Status: res.Ebl_status,
+		Data: map[string]interface{}{"shipmentId": shipmentId}, Message: "Bill of lading transferred succcessfully"}, previousAsBytes, res)
+	if err != nil {
+		return nil, err
+	}
+	router.Println("Bill of lading transferred succcessfully.")
+	return nil, nil
+}
+// ============================================================================================================================
+// surrender_ebl - surrender the bill of lading at the destination of the Shipment, by its last holder, the buyer
+// ============================================================================================================================
+func (t *ManageShipment) surrender_ebl(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	// surrender_ebl("shipmentId", "holder", "location")
+	var err error
+	if len(args) != 3 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting 3 arguments.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	router.Println("Surrendering bill of lading")
+	shipmentId := args[0]
+	holder := args[1]
+	location := args[2]
+
+	shipmentAsBytes, err := stub.GetState(shipmentKey(shipmentId))
+	if err != nil {
+		return nil, errors.New("Failed to get Shipment ID")
+	}
+	shipment := Shipment{}
+	json.Unmarshal(shipmentAsBytes, &shipment)
+	res, err := getEbl(stub, shipmentId)
+	if err != nil {
+		return nil, err
+	}
+	errText := ""
+	if res.EblID == "" || shipment.ShipmentID != shipmentId{
+		errText = "No bill of lading was issued for this Shipment."
+	}else if res.Ebl_status != "Issued"{
+		errText = "Bill of lading is already " + res.Ebl_status + "."
+	}else if res.Holder != holder{
+		errText = holder + " is not the holder of the bill of lading."
+	}else if next := nextHolder(res); next != ""{
+		errText = "Bill of lading must be endorsed to " + next + " before it is surrendered."
+	}else if location != shipment.Destination{
+		errText = "Bill of lading can only be surrendered at the destination " + shipment.Destination + "."
+	}
+	if errText != ""{
+		return nil, router.ErrorEvent(stub, errors.New(errText))
+	}
+	if err = router.CheckParty(stub, holder); err != nil {
+		return nil, router.ErrorEvent(stub, err)
+	}
+
+	previousAsBytes, _ := json.Marshal(res)
+	res.Ebl_status = "Surrendered"
+	res.SurrenderedAt = location
+	err = putEbl(stub, res)
+	if err != nil {
+		return nil, err
+	}
+	err = router.Emit(stub, events.Event{Type: events.BillOfLadingSurrendered, EntityID: res.EblID, Status: res.Ebl_status,
+		Data: map[string]interface{}{"shipmentId": shipmentId}, Message: "Bill of lading surrendered succcessfully"}, previousAsBytes, res)
+	if err != nil {
+		return nil, err
+	}
+	router.Println("Bill of lading surrendered succcessfully.")
+	return nil, nil
+}
+// ============================================================================================================================
+// release_cargo - release the cargo of a Shipment, only allowed once its bill of lading has been surrendered and the port
+// authority cleared it. A Shipment without a clearance record is not cleared
+// ============================================================================================================================
+func (t *ManageShipment) release_cargo(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var err error
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting \"shipmentID\" as an argument.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	router.Println("Releasing cargo")
+	shipmentId := args[0]
+	shipmentAsBytes, err := stub.GetState(shipmentKey(shipmentId))
+	if err != nil {
+		return nil, errors.New("Failed to get Shipment ID")
+	}
+	res := Shipment{}
+	json.Unmarshal(shipmentAsBytes, &res)
+	if res.ShipmentID != shipmentId{
+		errMsg := "{ \"message\" : " + router.JSONString(shipmentId + " Not Found.") + ", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	ebl, err := getEbl(stub, shipmentId)
+	if err != nil {
+		return nil, err
+	}
+	if ebl.Ebl_status != "Surrendered"{
+		errMsg := "{ \"shipmentID\" : " + router.JSONString(shipmentId) + ", \"message\" : \"Cargo can not be released before the bill of lading is surrendered.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	clearance, err := getClearance(stub, shipmentId)
+	if err != nil {
+		return nil, err
+	}
+	if clearance.ShipmentID != shipmentId{
+		errMsg := "{ \"shipmentID\" : " + router.JSONString(shipmentId) + ", \"message\" : \"Cargo can not be released before port clearance, no clearance is recorded.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	if clearance.Clearance_status != "Cleared"{
+		errMsg := "{ \"shipmentID\" : " + router.JSONString(shipmentId) + ", \"message\" : " + router.JSONString("Cargo can not be released before port clearance, clearance is " + clearance.Clearance_status + ".") + ", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	res.Shipment_status = "Cargo Released"
+	res.Version++
+	res.LastModifiedTxID = stub.GetTxID()
+	res.UpdatedAt, res.UpdatedBy, err = router.TxStamp(stub)
+	if err != nil {
+		return nil, err
+	}
+	input := shipmentJSON(res)
+	err = stub.PutState(shipmentKey(shipmentId), []byte(input))
+	if err != nil {
+		return nil, err
+	}
+	err = router.Emit(stub, events.Event{Type: events.ShipmentReleased, EntityID: shipmentId, Status: res.Shipment_status,
+		Message: "Cargo released succcessfully"}, shipmentAsBytes, input)
+	if err != nil {
+		return nil, err
+	}
+	router.Println("Cargo released succcessfully.")
+	return nil, nil
+}
+// ============================================================================================================================
+// get_ebl - get the bill of lading of a Shipment from chaincode state
+// ============================================================================================================================
+func (t *ManageShipment) get_ebl(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var err error
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting \"ShipmentID\" as an argument\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	valAsbytes, err := stub.GetState(eblKey(args[0]))
+	if err != nil {
+		errMsg := "{ \"message\" : " + router.JSONString("Bill of lading for " + args[0] + " not Found.") + ", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	return valAsbytes, nil
+}
+// ============================================================================================================================
+// coldChainKey, telemetryKey - keys under which the cold-chain config and sensor readings of a Shipment are stored
+// ============================================================================================================================
+func coldChainKey(shipmentId string) string {
+	return "ColdChain_" + shipmentId
+}
+func telemetryKey(shipmentId string) string {
+	return "Telemetry_" + shipmentId
+}
+// ============================================================================================================================
+// getColdChainConfig - get the cold-chain config of a Shipment, ShipmentID is empty when none was set
+// ============================================================================================================================
+func getColdChainConfig(stub shim.ChaincodeStubInterface, shipmentId string) (ColdChainConfig, error) {
+	res := ColdChainConfig{}
+	configAsBytes, err := stub.GetState(coldChainKey(shipmentId))
+	if err != nil {
+		return res, errors.New("Failed to get cold-chain config for " + shipmentId)
+	}
+	json.Unmarshal(configAsBytes, &res)
+	if res.Devices == nil {
+		res.Devices = map[string]string{}
+	}
+	return res, nil
+}
+// ============================================================================================================================
+// putColdChainConfig - store the cold-chain config of a Shipment into chaincode state
+// ============================================================================================================================
+func putColdChainConfig(stub shim.ChaincodeStubInterface, res ColdChainConfig) error {
+	configAsBytes, err := json.Marshal(res)
+	if err != nil {
+		return errors.New("Error while marshalling cold-chain config")
+	}
+	return stub.PutState(coldChainKey(res.ShipmentID), configAsBytes)
+}
+// ============================================================================================================================
+// sensorReadingPayload - the bytes a sensor device signs for one reading
+// ============================================================================================================================
+func sensorReadingPayload(reading SensorReading) []byte {
+	return []byte(reading.DeviceID + "|" + reading.Timestamp + "|" +
+		strconv.FormatFloat(reading.Temperature, 'f', -1, 64) + "|" +
+		strconv.FormatFloat(reading.Humidity, 'f', -1, 64) + "|" +
+		strconv.FormatFloat(reading.Latitude, 'f', -1, 64) + "|" +
+		strconv.FormatFloat(reading.Longitude, 'f', -1, 64))
+}
+// ============================================================================================================================
+// verifySensorReading - check the signature of a reading against the public key of its device
+// ============================================================================================================================
+func verifySensorReading(publicKeyPEM string, reading SensorReading) error {
+	block, _ := pem.Decode([]byte(publicKeyPEM))
+	if block == nil {
+		return errors.New("Public key of device " + reading.DeviceID + " is not PEM encoded")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return errors.New("Public key of device " + reading.DeviceID + " can not be parsed")
+	}
+	ecdsaKey, ok := key.(*ecdsa.PublicKey)
+	if !ok {
+		return errors.New("Public key of device " + reading.DeviceID + " is not an ECDSA key")
+	}
+	signature, err := base64.StdEncoding.DecodeString(reading.Signature)
+	if err != nil {
+		return errors.New("Signature of reading " + reading.Timestamp + " is not base64 encoded")
+	}
+	digest := sha256.Sum256(sensorReadingPayload(reading))
+	if !ecdsa.VerifyASN1(ecdsaKey, digest[:], signature) {
+		return errors.New("Signature of reading " + reading.Timestamp + " from device " + reading.DeviceID + " is invalid")
+	}
+	return nil
+}
+// ============================================================================================================================
+// readingExcursions - metrics of a reading outside the thresholds of the Shipment
+// ============================================================================================================================
+func readingExcursions(config ColdChainConfig, reading SensorReading) []string {
+	excursions := []string{}
+	minTemp, _ := strconv.ParseFloat(config.MinTemperature, 64)
+	maxTemp, _ := strconv.ParseFloat(config.MaxTemperature, 64)
+	minHum, _ := strconv.ParseFloat(config.MinHumidity, 64)
+	maxHum, _ := strconv.ParseFloat(config.MaxHumidity, 64)
+	if reading.Temperature < minTemp || reading.Temperature > maxTemp {
+		excursions = append(excursions, "temperature")
+	}
+	if reading.Humidity < minHum || reading.Humidity > maxHum {
+		excursions = append(excursions, "humidity")
+	}
+	return excursions
+}
+// ============================================================================================================================
+// set_cold_chain_thresholds - configure the temperature and humidity range of a Shipment, set by the admin MSP or the shipper
+// ============================================================================================================================
+func (t *ManageShipment) set_cold_chain_thresholds(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	// set_cold_chain_thresholds("shipmentId", "minTemperature", "maxTemperature", "minHumidity", "maxHumidity")
+	var err error
+	if len(args) != 5 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting 5 arguments.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	router.Println("Setting cold-chain thresholds")
+	shipmentId := args[0]
+	var bounds [4]float64
+	for i := 0; i < 4; i++ {
+		bounds[i], err = strconv.ParseFloat(args[i+1], 64)
+		if err != nil {
+			errMsg := "{ \"message\" : " + router.JSONString("Threshold " + args[i+1] + " is not a number.") + ", \"code\" : \"503\"}"
+			err = stub.SetEvent("errEvent", []byte(errMsg))
+			if err != nil {
+				return nil, err
+			} 
+			return nil, nil
+		}
+	}
+	if bounds[0] > bounds[1] || bounds[2] > bounds[3] {
+		errMsg := "{ \"message\" : \"Minimum thresholds must not exceed maximum thresholds.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	shipmentAsBytes, err := stub.GetState(shipmentKey(shipmentId))
+	if err != nil {
+		return nil, errors.New("Failed to get Shipment ID")
+	}
+	shipment := Shipment{}
+	json.Unmarshal(shipmentAsBytes, &shipment)
+	if shipment.ShipmentID != shipmentId{
+		errMsg := "{ \"message\" : " + router.JSONString(shipmentId + " Not Found.") + ", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	if router.CheckAdmin(stub) != nil {
+		if err = router.CheckParty(stub, shipment.ShipperName); err != nil {
+			return nil, router.ErrorEvent(stub, errors.New("Only the admin MSP or the shipper sets the cold-chain thresholds. " + err.Error()))
+		}
+	}
+
+	res, err := getColdChainConfig(stub, shipmentId)
+	if err != nil {
+		return nil, err
+	}
+	previousAsBytes, _ := json.Marshal(res)
+	res.ShipmentID = shipmentId
+	res.MinTemperature = args[1]
+	res.MaxTemperature = args[2]
+	res.MinHumidity = args[3]
+	res.MaxHumidity = args[4]
+	err = putColdChainConfig(stub, res)
+	if err != nil {
+		return nil, err
+	}
+	err = router.Emit(stub, events.Event{Type: events.ShipmentThresholdsSet, EntityID: shipmentId, Status: shipment.Shipment_status,
+		Message: "Cold-chain thresholds set succcessfully"}, previousAsBytes, res)
+	if err != nil {
+		return nil, err
+	}
+	router.Println("Cold-chain thresholds set succcessfully.")
+	return nil, nil
+}
+// ============================================================================================================================
+// register_sensor_device - trust the readings a sensor device signs for a Shipment. Only the admin MSP or the shipper can
+// register a device, and the key of a registered device is never replaced
+// ============================================================================================================================
+func (t *ManageShipment) register_sensor_device(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	// register_sensor_device("shipmentId", "deviceId", "PEM public key")
+	var err error
+	if len(args) != 3 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting 3 arguments.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	router.Println("Registering sensor device")
+	shipmentId := args[0]
+	deviceId := args[1]
+	res, err := getColdChainConfig(stub, shipmentId)
+	if err != nil {
+		return nil, err
+	}
+	errText := ""
+	if res.ShipmentID != shipmentId{
+		errText = "Cold-chain thresholds must be set before registering devices."
+	}else if _, found := res.Devices[deviceId]; found{
+		errText = "Device " + deviceId + " is already registered for this Shipment, its key can not be replaced."
+	}else if _, err := x509.ParsePKIXPublicKey(pemBytes(args[2])); err != nil{
+		errText = "Public key of device " + deviceId + " can not be parsed."
+	}
+	if errText != ""{
+		errMsg := "{ \"shipmentID\" : " + router.JSONString(shipmentId) + ", \"message\" : " + router.JSONString(errText) + ", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	if router.CheckAdmin(stub) != nil {
+		shipmentAsBytes, err := stub.GetState(shipmentKey(shipmentId))
+		if err != nil {
+			return nil, errors.New("Failed to get Shipment ID")
+		}
+		shipment := Shipment{}
+		json.Unmarshal(shipmentAsBytes, &shipment)
+		if err = router.CheckParty(stub, shipment.ShipperName); err != nil {
+			return nil, router.ErrorEvent(stub, errors.New("Only the admin MSP or the shipper registers a sensor device. " + err.Error()))
+		}
+	}
+	previousAsBytes, _ := json.Marshal(res)
+	res.Devices[deviceId] = args[2]
+	err = putColdChainConfig(stub, res)
+	if err != nil {
+		return nil, err
+	}
+	err = router.Emit(stub, events.Event{Type: events.ShipmentSensorRegistered, EntityID: shipmentId,
+		Data: map[string]interface{}{"deviceId": deviceId}, Message: "Sensor device registered succcessfully"}, previousAsBytes, res)
+	if err != nil {
+		return nil, err
+	}
+	router.Println("Sensor device registered succcessfully.")
+	return nil, nil
+}
+// ============================================================================================================================
+// pemBytes - the DER bytes of a PEM block, nil when it is not PEM encoded
+// ============================================================================================================================
+func pemBytes(publicKeyPEM string) []byte {
+	block, _ := pem.Decode([]byte(publicKeyPEM))
+	if block == nil {
+		return nil
+	}
+	return block.Bytes
+}
+// ============================================================================================================================
+// add_sensor_readings - record a batch of signed sensor readings, flagging excursions against the Shipment thresholds. The
+// readings of a device must be strictly later than its last recorded one, so a reading is never recorded twice
+// ============================================================================================================================
+func (t *ManageShipment) add_sensor_readings(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	// add_sensor_readings("shipmentId", "[{deviceId, timestamp, temperature, humidity, latitude, longitude, signature}, ...]")
+	var err error
+	if len(args) != 2 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting 2 arguments.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	router.Println("Adding sensor readings")
+	shipmentId := args[0]
+	var batch []SensorReading
+	err = json.Unmarshal([]byte(args[1]), &batch)
+	errText := ""
+	if err != nil || len(batch) == 0 {
+		errText = "Sensor readings must be a non-empty JSON array."
+	}
+	config, err := getColdChainConfig(stub, shipmentId)
+	if err != nil {
+		return nil, err
+	}
+	if errText == "" && config.ShipmentID != shipmentId {
+		errText = "Cold-chain thresholds are not set for this Shipment."
+	}
+	readings, err := getSensorReadings(stub, shipmentId)
+	if err != nil {
+		return nil, err
+	}
+	latest := make(map[string]time.Time)						//the last reading of each device
+	for _, reading := range readings {
+		if taken, err := parseTrackingTime(reading.Timestamp); err == nil && taken.After(latest[reading.DeviceID]) {
+			latest[reading.DeviceID] = taken
+		}
+	}
+	excursions := 0
+	for i := range batch {
+		if errText != "" {
+			break
+		}
+		publicKeyPEM, ok := config.Devices[batch[i].DeviceID]
+		if !ok {
+			errText = "Device " + batch[i].DeviceID + " is not registered for this Shipment."
+			break
+		}
+		taken, err := parseTrackingTime(batch[i].Timestamp)
+		if err != nil {
+			errText = err.Error()
+			break
+		}
+		if err := verifySensorReading(publicKeyPEM, batch[i]); err != nil {
+			errText = err.Error()
+			break
+		}
+		if last, found := latest[batch[i].DeviceID]; found && !taken.After(last) {
+			errText = "Reading of device " + batch[i].DeviceID + " at " + batch[i].Timestamp + " is not later than its last reading at " +
+				last.UTC().Format(time.RFC3339) + "."
+			break
+		}
+		latest[batch[i].DeviceID] = taken
+		batch[i].Excursion = readingExcursions(config, batch[i])
+		if len(batch[i].Excursion) > 0 {
+			excursions++
+		}
+	}
+	if errText != "" {
+		return nil, router.ErrorEvent(stub, errors.New(errText + " Batch rejected."))
+	}
+
+	readings = append(readings, batch...)
+	readingsAsBytes, _ := json.Marshal(readings)
+	err = stub.PutState(telemetryKey(shipmentId), readingsAsBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	err = router.Emit(stub, events.Event{Type: events.ShipmentReadingsAdded, EntityID: shipmentId,
+		Data: map[string]interface{}{"readings": len(batch), "excursions": excursions}, Message: "Sensor readings added succcessfully"},
+		nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	router.Println("Sensor readings added succcessfully.")
+	return nil, nil
+}
+// ============================================================================================================================
+// getSensorReadings - get the sensor readings of a Shipment ordered by timestamp, oldest first
+// ============================================================================================================================
+func getSensorReadings(stub shim.ChaincodeStubInterface, shipmentId string) ([]SensorReading, error) {
+	var readings []SensorReading
+	readingsAsBytes, err := stub.GetState(telemetryKey(shipmentId))
+	if err != nil {
+		return nil, errors.New("Failed to get sensor readings for " + shipmentId)
+	}
+	json.Unmarshal(readingsAsBytes, &readings)
+	sort.SliceStable(readings, func(i, j int) bool {
+		ti, _ := parseTrackingTime(readings[i].Timestamp)
+		tj, _ := parseTrackingTime(readings[j].Timestamp)
+		return ti.Before(tj)
+	})
+	if readings == nil {
+		readings = []SensorReading{}
+	}
+	return readings, nil
+}
+// ============================================================================================================================
+// get_sensor_readings - get every sensor reading of a Shipment, oldest first
+// ============================================================================================================================
+func (t *ManageShipment) get_sensor_readings(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var err error
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting \"ShipmentID\" as an argument\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	readings, err := getSensorReadings(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+	readingsAsBytes, _ := json.Marshal(readings)
+	return readingsAsBytes, nil
+}
+// ============================================================================================================================
+// get_telemetry_summary - get min, max and time out of range of the sensor readings of a Shipment
+// ============================================================================================================================
+func (t *ManageShipment) get_telemetry_summary(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var err error
+	router.Println("Fetching telemetry summary")
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting \"ShipmentID\" as an argument\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	shipmentId := args[0]
+	readings, err := getSensorReadings(stub, shipmentId)
+	if err != nil {
+		return nil, err
+	}
+	summary := TelemetrySummary{ShipmentID: shipmentId, Readings: len(readings)}
+	summary.MinTemperature, summary.MinHumidity = math.Inf(1), math.Inf(1)
+	summary.MaxTemperature, summary.MaxHumidity = math.Inf(-1), math.Inf(-1)
+	for i, reading := range readings {
+		summary.MinTemperature = math.Min(summary.MinTemperature, reading.Temperature)
+		summary.MaxTemperature = math.Max(summary.MaxTemperature, reading.Temperature)
+		summary.MinHumidity = math.Min(summary.MinHumidity, reading.Humidity)
+		summary.MaxHumidity = math.Max(summary.MaxHumidity, reading.Humidity)
+		if len(reading.Excursion) > 0 {
+			summary.Excursions++
+		}
+		if i == len(readings)-1 {
+			break
+		}
+		//a reading holds until the next one, so its interval counts as out of range when it is an excursion
+		from, _ := parseTrackingTime(reading.Timestamp)
+		to, _ := parseTrackingTime(readings[i+1].Timestamp)
+		interval := int64(to.Sub(from).Seconds())
+		for _, metric := range reading.Excursion {
+			if metric == "temperature" {
+				summary.TemperatureOutOfRangeSeconds += interval
+			}else if metric == "humidity" {
+				summary.HumidityOutOfRangeSeconds += interval
+			}
+		}
+		if len(reading.Excursion) > 0 {
+			summary.OutOfRangeSeconds += interval
+		}
+	}
+	if len(readings) == 0 {
+		summary.MinTemperature, summary.MaxTemperature, summary.MinHumidity, summary.MaxHumidity = 0, 0, 0, 0
+	}else{
+		summary.FirstReading = readings[0].Timestamp
+		summary.LastReading = readings[len(readings)-1].Timestamp
+	}
+	summaryAsBytes, _ := json.Marshal(summary)
+	router.Println("Fetched telemetry summary")
+	return summaryAsBytes, nil
+}
+// ============================================================================================================================
+// agreementParties - the parties of an Agreement, read from ManageAgreement
+// ============================================================================================================================
+func (t *ManageShipment) agreementParties(stub shim.ChaincodeStubInterface, agreementId string) (linkedAgreement, error) {
+	agreement := linkedAgreement{}
+	agreementAsBytes, err := t.linker.QueryLinked(stub, "agreement", "getAgreement_byID", agreementId)
+	if err != nil {
+		return agreement, errors.New("Failed to query Agreement " + agreementId + ": " + err.Error())
+	}
+	json.Unmarshal(agreementAsBytes, &agreement)
+	if agreement.AgreementID != agreementId || agreementId == "" {
+		return agreement, errors.New("Agreement " + agreementId + " Not Found")
+	}
+	return agreement, nil
+}
+// ============================================================================================================================
+// checkLinkedAgreement - confirm with ManageAgreement that the Agreement exists, belongs to the PO and every party signed it
+// ============================================================================================================================
+func (t *ManageShipment) checkLinkedAgreement(stub shim.ChaincodeStubInterface, agreementId string, transId string) error {
+	agreement, err := t.agreementParties(stub, agreementId)
+	if err != nil {
+		return err
+	}
+	if agreement.TransID != transId {
+		return errors.New("Agreement " + agreementId + " belongs to PO " + agreement.TransID + ", not " + transId)
+	}
+	if agreement.Agreement_status != router.ApprovedStatus {
+		return errors.New("Agreement " + agreementId + " is not approved by every party, its status is '" + agreement.Agreement_status + "'")
+	}
+	return nil
+}
+// ============================================================================================================================
+// getShipment_byAgreement - get the Shipments of an Agreement as a JSON array, used for the linked trade record
+// ============================================================================================================================
+func (t *ManageShipment) getShipment_byAgreement(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var shipmentIndex []string
+	var err error
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting \"AgreementID\" as an argument\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	shipmentIndexAsBytes, err := stub.GetState(ShipmentIndexStr)
+	if err != nil {
+		return nil, errors.New("Failed to get Shipment index")
+	}
+	json.Unmarshal(shipmentIndexAsBytes, &shipmentIndex)
+	shipments := []json.RawMessage{}
+	for _, val := range shipmentIndex {
+		valueAsBytes, err := stub.GetState(shipmentKey(val))
+		if err != nil {
+			return nil, errors.New("Failed to get state for " + val)
+		}
+		res := Shipment{}
+		json.Unmarshal(valueAsBytes, &res)
+		if res.AgreementID == args[0] {
+			shipments = append(shipments, json.RawMessage(valueAsBytes))
+		}
+	}
+	shipmentsAsBytes, _ := json.Marshal(shipments)
+	return shipmentsAsBytes, nil
+}
+// ============================================================================================================================
+// clearanceKey - key under which the port clearance of a Shipment is stored
+// ============================================================================================================================
+func clearanceKey(shipmentId string) string {
+	return "Clearance_" + shipmentId
+}
+// ============================================================================================================================
+// getClearance - get the port clearance of a Shipment, ShipmentID is empty when none was started
+// ============================================================================================================================
+func getClearance(stub shim.ChaincodeStubInterface, shipmentId string) (Clearance, error) {
+	res := Clearance{}
+	clearanceAsBytes, err := stub.GetState(clearanceKey(shipmentId))
+	if err != nil {
+		return res, errors.New("Failed to get clearance for " + shipmentId)
+	}
+	json.Unmarshal(clearanceAsBytes, &res)
+	return res, nil
+}
+// ============================================================================================================================
+// putClearance - store the port clearance of a Shipment and mirror its status on the Shipment and its Agreement
+// ============================================================================================================================
+func (t *ManageShipment) putClearance(stub shim.ChaincodeStubInterface, res Clearance, shipment Shipment) error {
+	clearanceAsBytes, err := json.Marshal(res)
+	if err != nil {
+		return errors.New("Error while marshalling clearance")
+	}
+	err = stub.PutState(clearanceKey(res.ShipmentID), clearanceAsBytes)
+	if err != nil {
+		return err
+	}
+	shipment.Clearance_status = res.Clearance_status
+	shipment.Version++
+	shipment.LastModifiedTxID = stub.GetTxID()
+	shipment.UpdatedAt, shipment.UpdatedBy, err = router.TxStamp(stub)
+	if err != nil {
+		return err
+	}
+	err = stub.PutState(shipmentKey(shipment.ShipmentID), []byte(shipmentJSON(shipment)))
+	if err != nil {
+		return err
+	}
+	_, err = t.linker.InvokeLinked(stub, "agreement", "update_clearance_status", res.AgreementID, res.ShipmentID, res.Clearance_status)
+	return err
+}
+// ============================================================================================================================
+// port_clearance_action - the port authority of the Agreement requests documents, places a hold, inspects or clears a Shipment,
+// the caller must act for it
+// ============================================================================================================================
+func (t *ManageShipment) port_clearance_action(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	// port_clearance_action("shipmentId", "portAuthority", "RequestDocuments"|"PlaceHold"|"Inspect"|"Clear", "reason")
+	var err error
+	if len(args) != 4 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting 4 arguments.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	router.Println("Recording port clearance action")
+	shipmentId := args[0]
+	portAuthority := args[1]
+	action := args[2]
+	reason := args[3]
+
+	shipmentAsBytes, err := stub.GetState(shipmentKey(shipmentId))
+	if err != nil {
+		return nil, errors.New("Failed to get Shipment ID")
+	}
+	shipment := Shipment{}
+	json.Unmarshal(shipmentAsBytes, &shipment)
+	res, err := getClearance(stub, shipmentId)
+	if err != nil {
+		return nil, err
+	}
+
+	errText := ""
+	newStatus, validAction := ClearanceActionStatus[action]
+	if shipment.ShipmentID != shipmentId{
+		errText = shipmentId + " Not Found."
+	}else if !validAction{
+		errText = "Unknown clearance action " + action + "."
+	}else if reason == ""{
+		errText = "A reason is required for every clearance action."
+	}else if res.Clearance_status == "Cleared"{
+		errText = "Shipment is already cleared."
+	}else if action == "Clear" && res.Clearance_status == "Documents Requested"{
+		errText = "Shipment can not be cleared while requested documents are outstanding."
+	}
+	if errText == ""{
+		//only the port authority named on the Agreement may act on the Shipment
+		if !t.linker.Linked(stub, "agreement") {
+			errText = "The agreement chaincode is not registered."
+		}else{
+			agreementAsBytes, err := t.linker.QueryLinked(stub, "agreement", "getAgreement_byID", shipment.AgreementID)
+			agreement := struct{
+				AgreementID string `json:"agreementId"`
+				PortAuthName string `json:"agreementPortAuth_name"`
+			}{}
+			if err == nil {
+				json.Unmarshal(agreementAsBytes, &agreement)
+			}
+			if agreement.AgreementID != shipment.AgreementID || shipment.AgreementID == ""{
+				errText = "Agreement " + shipment.AgreementID + " of the Shipment Not Found."
+			}else if agreement.PortAuthName != portAuthority{
+				errText = portAuthority + " is not the port authority of Agreement " + shipment.AgreementID + "."
+			}else if err = router.CheckParty(stub, portAuthority); err != nil{
+				errText = err.Error() + "."
+			}
+		}
+	}
+	if errText != ""{
+		errMsg := "{ \"shipmentID\" : " + router.JSONString(shipmentId) + ", \"message\" : " + router.JSONString(errText) + ", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+
+	previousAsBytes, _ := json.Marshal(res)
+	res.ShipmentID = shipmentId
+	res.AgreementID = shipment.AgreementID
+	res.PortAuthName = portAuthority
+	res.Clearance_status = newStatus
+	res.Actions = append(res.Actions, ClearanceAction{Action: action, Party: portAuthority, Reason: reason, TxID: stub.GetTxID()})
+	err = t.putClearance(stub, res, shipment)
+	if err != nil {
+		return nil, err
+	}
+	err = router.Emit(stub, events.Event{Type: events.ShipmentClearanceUpdated, EntityID: shipmentId, Status: shipment.Shipment_status,
+		Data: map[string]interface{}{"action": action}, Message: "Clearance action recorded succcessfully"}, previousAsBytes, res)
+	if err != nil {
+		return nil, err
+	}
+	router.Println("Clearance action recorded succcessfully.")
+	return nil, nil
+}
+// ============================================================================================================================
+// submit_clearance_documents - answer an outstanding document request of the port authority
+// ============================================================================================================================
+func (t *ManageShipment) submit_clearance_documents(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	// submit_clearance_documents("shipmentId", "party", "documents")
+	var err error
+	if len(args) != 3 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting 3 arguments.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	router.Println("Submitting clearance documents")
+	shipmentId := args[0]
+	shipmentAsBytes, err := stub.GetState(shipmentKey(shipmentId))
+	if err != nil {
+		return nil, errors.New("Failed to get Shipment ID")
+	}
+	shipment := Shipment{}
+	json.Unmarshal(shipmentAsBytes, &shipment)
+	res, err := getClearance(stub, shipmentId)
+	if err != nil {
+		return nil, err
+	}
+	if res.ShipmentID != shipmentId || res.Clearance_status != "Documents Requested"{
+		errMsg := "{ \"shipmentID\" : " + router.JSONString(shipmentId) + ", \"message\" : \"No documents were requested for this Shipment.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	previousAsBytes, _ := json.Marshal(res)
+	res.Clearance_status = "Documents Submitted"
+	res.Actions = append(res.Actions, ClearanceAction{Action: "SubmitDocuments", Party: args[1], Reason: args[2], TxID: stub.GetTxID()})
+	err = t.putClearance(stub, res, shipment)
+	if err != nil {
+		return nil, err
+	}
+	err = router.Emit(stub, events.Event{Type: events.ShipmentDocumentsSubmitted, EntityID: shipmentId, Status: shipment.Shipment_status,
+		Message: "Clearance documents submitted succcessfully"}, previousAsBytes, res)
+	if err != nil {
+		return nil, err
+	}
+	router.Println("Clearance documents submitted succcessfully.")
+	return nil, nil
+}
+// ============================================================================================================================
+// get_clearance - get the port clearance of a Shipment with every recorded action
+// ============================================================================================================================
+func (t *ManageShipment) get_clearance(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var err error
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting \"ShipmentID\" as an argument\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	valAsbytes, err := stub.GetState(clearanceKey(args[0]))
+	if err != nil {
+		errMsg := "{ \"message\" : " + router.JSONString("Clearance for " + args[0] + " not Found.") + ", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	return valAsbytes, nil
+}
+// ============================================================================================================================
+// slaKey - key under which the delivery SLA of a Shipment is stored
+// ============================================================================================================================
+func slaKey(shipmentId string) string {
+	return "SLA_" + shipmentId
+}
+// ============================================================================================================================
+// parseDeliveryDate - parse a delivery date as a tracking time, or in one of the layouts of the Agreements written before
+// their dates were checked
+// ============================================================================================================================
+func parseDeliveryDate(date string) (time.Time, error) {
+	parsed, err := parseTrackingTime(date)
+	if err == nil {
+		return parsed, nil
+	}
+	for _, layout := range LegacyDateLayouts {
+		parsed, err = time.Parse(layout, date)
+		if err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, errors.New("Unknown date format " + date)
+}
+// ============================================================================================================================
+// daysBetween - whole calendar days from one date to another, negative when to is before from
+// ============================================================================================================================
+func daysBetween(from string, to string) (int, error) {
+	fromTime, err := parseDeliveryDate(from)
+	if err != nil {
+		return 0, err
+	}
+	toTime, err := parseDeliveryDate(to)
+	if err != nil {
+		return 0, err
+	}
+	fromDay := time.Date(fromTime.Year(), fromTime.Month(), fromTime.Day(), 0, 0, 0, 0, time.UTC)
+	toDay := time.Date(toTime.Year(), toTime.Month(), toTime.Day(), 0, 0, 0, 0, time.UTC)
+	return int(math.Round(toDay.Sub(fromDay).Hours() / 24)), nil
+}
+// ============================================================================================================================
+// putDeliverySLA - evaluate a delivered Shipment against its Agreement and PO, and store the result
+// ============================================================================================================================
+func (t *ManageShipment) putDeliverySLA(stub shim.ChaincodeStubInterface, shipment Shipment) (DeliverySLA, error) {
+	res := DeliverySLA{
+		ShipmentID: shipment.ShipmentID,
+		AgreementID: shipment.AgreementID,
+		ShipperName: shipment.ShipperName,
+		ActualDelivery_date: shipment.ActualDelivery_date,
+		LiquidatedDamages: "0.00",
+	}
+	if shipment.ActualDelivery_date == "" {
+		return res, errors.New("Shipment " + shipment.ShipmentID + " has no actual delivery date")
+	}
+	agreementAsBytes, err := t.linker.QueryLinked(stub, "agreement", "getAgreement_byID", shipment.AgreementID)
+	if err != nil {
+		return res, errors.New("Failed to query Agreement " + shipment.AgreementID)
+	}
+	agreement := struct{
+		AgreementID string `json:"agreementId"`
+		TransID string `json:"transId"`
+		Delivery_date string `json:"delivery_date"`
+		Total_Value string `json:"total_value"`
+	}{}
+	json.Unmarshal(agreementAsBytes, &agreement)
+	if agreement.AgreementID != shipment.AgreementID || agreement.AgreementID == "" {
+		return res, errors.New("Agreement " + shipment.AgreementID + " of the Shipment Not Found")
+	}
+	res.PlannedDelivery_date = agreement.Delivery_date
+	res.VarianceDays, err = daysBetween(agreement.Delivery_date, shipment.ActualDelivery_date)
+	if err != nil {
+		return res, err
+	}
+
+	//the PO is optional, its expected date is reported but the Agreement date is the contractual one
+	if t.linker.Linked(stub, "po") {
+		poAsBytes, err := t.linker.QueryLinked(stub, "po", "getPO_byID", agreement.TransID)
+		po := struct{
+			ExpectedDeliveryDate string `json:"expectedDeliveryDate"`
+		}{}
+		if err == nil {
+			json.Unmarshal(poAsBytes, &po)
+		}
+		if po.ExpectedDeliveryDate != "" {
+			res.ExpectedDelivery_date = po.ExpectedDeliveryDate
+			res.PoVarianceDays, _ = daysBetween(po.ExpectedDeliveryDate, shipment.ActualDelivery_date)
+		}
+	}
+
+	res.SLA_status = "On Time"
+	if res.VarianceDays > 0 {
+		res.SLA_status = "Late"
+		res.DaysLate = res.VarianceDays
+	}
+
+	termsAsBytes, err := t.linker.QueryLinked(stub, "agreement", "get_liquidated_damages", shipment.AgreementID)
+	terms := struct{
+		RatePerDay string `json:"rate_per_day"`
+		CapPercent string `json:"cap_percent"`
+		GraceDays string `json:"grace_days"`
+	}{}
+	if err == nil {
+		json.Unmarshal(termsAsBytes, &terms)
+	}
+	if res.DaysLate > 0 && terms.RatePerDay != "" {
+		rate, _ := strconv.ParseFloat(terms.RatePerDay, 64)
+		capPercent, _ := strconv.ParseFloat(terms.CapPercent, 64)
+		graceDays, _ := strconv.Atoi(terms.GraceDays)
+		totalValue, _ := strconv.ParseFloat(agreement.Total_Value, 64)
+		chargeableDays := res.DaysLate - graceDays
+		if chargeableDays > 0 {
+			damages := totalValue * rate / 100 * float64(chargeableDays)
+			if capPercent > 0 {
+				damages = math.Min(damages, totalValue * capPercent / 100)
+			}
+			res.LiquidatedDamages = strconv.FormatFloat(damages, 'f', 2, 64)
+		}
+	}
+
+	slaAsBytes, _ := json.Marshal(res)
+	err = stub.PutState(slaKey(shipment.ShipmentID), slaAsBytes)
+	if err != nil {
+		return res, err
+	}
+	return res, nil
+}
+// ============================================================================================================================
+// evaluate_delivery_sla - compute planned versus actual delivery of a Shipment and any liquidated damages owed
+// ============================================================================================================================
+func (t *ManageShipment) evaluate_delivery_sla(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var err error
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting \"shipmentID\" as an argument.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	router.Println("Evaluating delivery SLA")
+	shipmentId := args[0]
+	shipmentAsBytes, err := stub.GetState(shipmentKey(shipmentId))
+	if err != nil {
+		return nil, errors.New("Failed to get Shipment ID")
+	}
+	shipment := Shipment{}
+	json.Unmarshal(shipmentAsBytes, &shipment)
+	previousAsBytes, err := stub.GetState(slaKey(shipmentId))
+	if err != nil {
+		return nil, errors.New("Failed to get delivery SLA for " + shipmentId)
+	}
+	errText := ""
+	var res DeliverySLA
+	if shipment.ShipmentID != shipmentId{
+		errText = shipmentId + " Not Found."
+	}else if res, err = t.putDeliverySLA(stub, shipment); err != nil{
+		errText = err.Error() + "."
+	}
+	if errText != ""{
+		errMsg := "{ \"shipmentID\" : " + router.JSONString(shipmentId) + ", \"message\" : " + router.JSONString(errText) + ", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	err = router.Emit(stub, events.Event{Type: events.ShipmentSLAEvaluated, EntityID: shipmentId, Status: shipment.Shipment_status,
+		Message: "Delivery SLA evaluated succcessfully"}, previousAsBytes, res)
+	if err != nil {
+		return nil, err
+	}
+	router.Println("Delivery SLA evaluated succcessfully.")
+	return nil, nil
+}
+// ============================================================================================================================
+// get_delivery_sla - get the delivery SLA of a Shipment from chaincode state
+// ============================================================================================================================
+func (t *ManageShipment) get_delivery_sla(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var err error
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting \"ShipmentID\" as an argument\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	valAsbytes, err := stub.GetState(slaKey(args[0]))
+	if err != nil {
+		errMsg := "{ \"message\" : " + router.JSONString("Delivery SLA for " + args[0] + " not Found.") + ", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	return valAsbytes, nil
+}
+// ============================================================================================================================
+// get_shipper_performance - get on-time delivery performance of one shipper, or of every shipper when " " is passed
+// ============================================================================================================================
+func (t *ManageShipment) get_shipper_performance(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var shipmentIndex []string
+	var err error
+	router.Println("Fetching shipper performance")
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting \"Shipper_Name\" or \" \" as an argument\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	shipperName := args[0]
+	shipmentAsBytes, err := stub.GetState(ShipmentIndexStr)
+	if err != nil {
+		return nil, errors.New("Failed to get Shipment index")
+	}
+	json.Unmarshal(shipmentAsBytes, &shipmentIndex)								//un stringify it aka JSON.parse()
+
+	performance := map[string]*ShipperPerformance{}
+	daysLate := map[string]int{}
+	damages := map[string]float64{}
+	for _, val := range shipmentIndex{
+		slaAsBytes, err := stub.GetState(slaKey(val))
+		if err != nil {
+			return nil, errors.New("{\"Error\":\"Failed to get state for " + slaKey(val) + "\"}")
+		}
+		sla := DeliverySLA{}
+		json.Unmarshal(slaAsBytes, &sla)
+		if sla.ShipmentID != val {
+			continue											//not delivered or not evaluated yet
+		}
+		if shipperName != "" && shipperName != " " && sla.ShipperName != shipperName {
+			continue
+		}
+		p, ok := performance[sla.ShipperName]
+		if !ok {
+			p = &ShipperPerformance{ShipperName: sla.ShipperName}
+			performance[sla.ShipperName] = p
+		}
+		p.Deliveries++
+		if sla.SLA_status == "Late" {
+			p.Late++
+			daysLate[sla.ShipperName] += sla.DaysLate
+		}else{
+			p.OnTime++
+		}
+		amount, _ := strconv.ParseFloat(sla.LiquidatedDamages, 64)
+		damages[sla.ShipperName] += amount
+	}
+
+	result := []ShipperPerformance{}
+	for name, p := range performance {
+		p.OnTimeRate = strconv.FormatFloat(float64(p.OnTime) * 100 / float64(p.Deliveries), 'f', 2, 64)
+		p.AverageDaysLate = "0.00"
+		if p.Late > 0 {
+			p.AverageDaysLate = strconv.FormatFloat(float64(daysLate[name]) / float64(p.Late), 'f', 2, 64)
+		}
+		p.LiquidatedDamages = strconv.FormatFloat(damages[name], 'f', 2, 64)
+		result = append(result, *p)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ShipperName < result[j].ShipperName })
+	resultAsBytes, _ := json.Marshal(result)
+	router.Println("Fetched shipper performance")
+	return resultAsBytes, nil
+}
+// ============================================================================================================================
+// shipmentItemsKey - key under which the items of a Shipment are stored
+// ============================================================================================================================
+func shipmentItemsKey(shipmentId string) string {
+	return "ShipmentItems_" + shipmentId
+}
+// ============================================================================================================================
+// set_shipment_items - record the items and quantities a Shipment carries and book them against its Agreement
+// ============================================================================================================================
+func (t *ManageShipment) set_shipment_items(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	// set_shipment_items("shipmentId", "[{item_id, quantity}, ...]")
+	var err error
+	if len(args) != 2 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting 2 arguments.\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	router.Println("Setting Shipment items")
+	shipmentId := args[0]
+	var items []ShipmentItem
+	errText := ""
+	if err = json.Unmarshal([]byte(args[1]), &items); err != nil || len(items) == 0 {
+		errText = "Shipment items must be a non-empty JSON array."
+	}
+	shipmentAsBytes, err := stub.GetState(shipmentKey(shipmentId))
+	if err != nil {
+		return nil, errors.New("Failed to get Shipment ID")
+	}
+	shipment := Shipment{}
+	json.Unmarshal(shipmentAsBytes, &shipment)
+	itemsAsBytes, err := stub.GetState(shipmentItemsKey(shipmentId))
+	if err != nil {
+		return nil, errors.New("Failed to get Shipment items")
+	}
+	if errText == "" && shipment.ShipmentID != shipmentId {
+		errText = shipmentId + " Not Found."
+	}else if errText == "" && len(itemsAsBytes) > 0 {
+		errText = "Items of this Shipment are already recorded."
+	}
+	if errText == "" {
+		_, err = t.linker.InvokeLinked(stub, "agreement", "record_shipped_quantity", shipment.AgreementID, shipmentId, args[1])
+		if err != nil {
+			errText = err.Error()
+		}
+	}
+	if errText != "" {
+		errMsg := "{ \"shipmentID\" : " + router.JSONString(shipmentId) + ", \"message\" : " + router.JSONString(errText) + ", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	itemsAsBytes, _ = json.Marshal(items)
+	err = stub.PutState(shipmentItemsKey(shipmentId), itemsAsBytes)
+	if err != nil {
+		return nil, err
+	}
+	err = router.Emit(stub, events.Event{Type: events.ShipmentItemsRecorded, EntityID: shipmentId, Status: shipment.Shipment_status,
+		Data: map[string]interface{}{"agreementId": shipment.AgreementID}, Message: "Shipment items recorded succcessfully"},
+		nil, map[string]interface{}{"items": items})
+	if err != nil {
+		return nil, err
+	}
+	router.Println("Shipment items recorded succcessfully.")
+	return nil, nil
+}
+// ============================================================================================================================
+// get_shipment_items - get the items and quantities a Shipment carries
+// ============================================================================================================================
+func (t *ManageShipment) get_shipment_items(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	var err error
+	if len(args) != 1 {
+		errMsg := "{ \"message\" : \"Incorrect number of arguments. Expecting \"ShipmentID\" as an argument\", \"code\" : \"503\"}"
+		err = stub.SetEvent("errEvent", []byte(errMsg))
+		if err != nil {
+			return nil, err
+		} 
+		return nil, nil
+	}
+	itemsAsBytes, err := stub.GetState(shipmentItemsKey(args[0]))
+	if err != nil {
+		return nil, errors.New("Failed to get Shipment items")
+	}
+	if len(itemsAsBytes) == 0 {
+		return []byte("[]"), nil
+	}
+	return itemsAsBytes, nil
+}
@@ -0,0 +1,437 @@
+package shipment
+
+import (
+"strings"
+"testing"
+"time"
+"crypto/ecdsa"
+"crypto/elliptic"
+"crypto/rand"
+"crypto/sha256"
+"crypto/x509"
+"encoding/base64"
+"encoding/json"
+"encoding/pem"
+
+"github.com/wipro-blockchain/TF-v1/events"
+"github.com/wipro-blockchain/TF-v1/internal/agreement"
+"github.com/wipro-blockchain/TF-v1/internal/mockstub"
+"github.com/wipro-blockchain/TF-v1/internal/mocktest"
+"github.com/wipro-blockchain/TF-v1/internal/payment"
+"github.com/wipro-blockchain/TF-v1/internal/po"
+"github.com/wipro-blockchain/TF-v1/internal/router"
+)
+
+// newTradeFinance - all four domains on an initialized in-memory ledger with PO PO1 and the approved Agreement AGR1,
+// due on 2024-03-01 and shipped by Shipco, cleared by Portauth
+func newTradeFinance(t *testing.T) (*router.Router, *mockstub.MockStub) {
+	r := router.New("TradeFinance")
+	r.Register("po", po.New())
+	r.Register("agreement", agreement.New(r))
+	r.Register("payment", payment.New(r))
+	r.Register("shipment", New(r))
+	stub := mockstub.New("tradeFinance")
+	if _, err := stub.Init(r, " "); err != nil || stub.Failed() {
+		t.Fatalf("init failed: %v %s", err, stub.LastEvent().Payload)
+	}
+	mocktest.Succeed(t, stub, r, "create_po", "PO1", "Sellerco", "Buyerco", "2024-03-01", "2024-01-15", "Accepted", "ITM-1", "Rice",
+		"100", "25", "true", "true")
+	agreementArgs := []string{"AGR1", "PO1", "Created", "Buyerco", "Sellerco", "Shipco", "Buybank", "Sellbank", "Portauth",
+		"2024-01-16", "ITM-1", "Rice", "100", "2500", "2024-03-01", "0", "100", "Contract", "http://docs/contract", "Terms",
+		"true", "false", "false", "false", "Food", "25"}
+	mocktest.Succeed(t, stub, r, "create_agreement", agreementArgs...)
+	agreementArgs[21], agreementArgs[22], agreementArgs[23] = "true", "true", "true"
+	mocktest.Succeed(t, stub, r, "update_agreement", agreementArgs...)
+	return r, stub
+}
+
+// shipmentArgs - the create_shipment and update_shipment arguments of a Shipment of AGR1 from Mumbai to Rotterdam
+func shipmentArgs(shipmentId string) []string {
+	return []string{shipmentId, "PO1", "AGR1", "Created", "Mumbai", "Rotterdam", "", "2024-02-01", "Shipco"}
+}
+
+func getShipment(t *testing.T, r *router.Router, stub *mockstub.MockStub, shipmentId string) Shipment {
+	res := Shipment{}
+	if err := json.Unmarshal(mocktest.Succeed(t, stub, r, "getShipment_byID", shipmentId), &res); err != nil {
+		t.Fatalf("getShipment_byID(%s): %s", shipmentId, err)
+	}
+	return res
+}
+
+func TestArgumentCounts(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	for _, function := range []string{"create_shipment", "update_shipment", "delete_shipment", "add_tracking_event",
+		"issue_ebl", "transfer_ebl", "surrender_ebl", "release_cargo", "set_cold_chain_thresholds", "register_sensor_device",
+		"add_sensor_readings", "port_clearance_action", "submit_clearance_documents", "evaluate_delivery_sla",
+		"set_shipment_items", "getShipment_byID", "getShipment_byStatus", "get_AllShipment", "getShipment_byShipper",
+		"getShipment_byAgreement", "get_shipment_timeline", "get_ebl", "get_sensor_readings", "get_telemetry_summary",
+		"get_clearance", "get_delivery_sla", "get_shipper_performance", "get_shipment_items"} {
+		mocktest.Reject(t, stub, r, function, "Incorrect number of arguments")
+	}
+}
+
+func TestCreateShipment(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	mocktest.Succeed(t, stub, r, "create_shipment", shipmentArgs("SHP1")...)
+	if stub.Message() != "Shipment created succcessfully" {
+		t.Errorf("create_shipment event: %s", stub.LastEvent().Payload)
+	}
+	expected := Shipment{ShipmentID: "SHP1", TransID: "PO1", AgreementID: "AGR1", Shipment_status: "Created", Source: "Mumbai",
+		Destination: "Rotterdam", Shipment_date: "2024-02-01", ShipperName: "Shipco", Version: 1, LastModifiedTxID: stub.TxID,
+		CreatedAt: stub.TxTime.Format(time.RFC3339), UpdatedAt: stub.TxTime.Format(time.RFC3339)}
+	if res := getShipment(t, r, stub, "SHP1"); res != expected {
+		t.Errorf("created Shipment: %+v", res)
+	}
+	mocktest.Reject(t, stub, r, "create_shipment", "SHP1 already exists.", shipmentArgs("SHP1")...)
+}
+
+func TestCreateShipmentLinkedAgreement(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	args := shipmentArgs("SHP1")
+	args[2] = "AGR9"
+	mocktest.Reject(t, stub, r, "create_shipment", "Agreement AGR9 Not Found", args...)
+	args = shipmentArgs("SHP1")
+	args[1] = "PO2"
+	mocktest.Reject(t, stub, r, "create_shipment", "Agreement AGR1 belongs to PO PO1, not PO2", args...)
+	if len(stub.State[ShipmentIndexStr]) != 0 && string(stub.State[ShipmentIndexStr]) != "null" {
+		t.Errorf("Shipment index after rejected creates: %s", stub.State[ShipmentIndexStr])
+	}
+}
+
+func TestUpdateAndDeleteShipment(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	mocktest.Reject(t, stub, r, "update_shipment", "SHP9 Not Found.", shipmentArgs("SHP9")...)
+	mocktest.Succeed(t, stub, r, "create_shipment", shipmentArgs("SHP1")...)
+	mocktest.Succeed(t, stub, r, "create_shipment", shipmentArgs("SHP2")...)
+	args := shipmentArgs("SHP1")
+	args[5] = "Antwerp"
+	mocktest.Succeed(t, stub, r, "update_shipment", args...)
+	if res := getShipment(t, r, stub, "SHP1"); res.Destination != "Antwerp" || res.Shipment_status != "Created" {
+		t.Errorf("updated Shipment: %+v", res)
+	}
+
+	//the status and the delivery date follow the tracking events
+	args[3] = "Delivered"
+	mocktest.Reject(t, stub, r, "update_shipment", "follow the tracking events, add one with add_tracking_event", args...)
+	args[3], args[6] = "Created", "2024-02-28"
+	mocktest.Reject(t, stub, r, "update_shipment", "follow the tracking events", args...)
+	mocktest.Reject(t, stub, r, "update_shipment", "follow the tracking events", `{"shipmentId": "SHP1", "shipment_status": "Delivered"}`)
+	mocktest.Succeed(t, stub, r, "update_shipment", `{"shipmentId": "SHP1", "destination": "Rotterdam"}`)
+	mocktest.Succeed(t, stub, r, "delete_shipment", "SHP1")
+	if valAsBytes := mocktest.Succeed(t, stub, r, "getShipment_byID", "SHP1"); len(valAsBytes) != 0 {
+		t.Errorf("deleted Shipment still readable: %s", valAsBytes)
+	}
+	if string(stub.State[ShipmentIndexStr]) != `["SHP2"]` {
+		t.Errorf("Shipment index after delete: %s", stub.State[ShipmentIndexStr])
+	}
+}
+
+func TestGetShipmentLists(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	mocktest.Succeed(t, stub, r, "create_shipment", shipmentArgs("SHP1")...)
+	for _, query := range [][]string{{"get_AllShipment", " "}, {"getShipment_byShipper", "Shipco"}, {"getShipment_byStatus", "Created"}} {
+		if valAsBytes := string(mocktest.Succeed(t, stub, r, query[0], query[1])); !strings.Contains(valAsBytes, `"SHP1"`) {
+			t.Errorf("%s: %s", query[0], valAsBytes)
+		}
+	}
+	var byAgreement []Shipment
+	if err := json.Unmarshal(mocktest.Succeed(t, stub, r, "getShipment_byAgreement", "AGR1"), &byAgreement); err != nil || len(byAgreement) != 1 {
+		t.Errorf("getShipment_byAgreement: %v %v", byAgreement, err)
+	}
+}
+
+func TestTrackingEvents(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	mocktest.Succeed(t, stub, r, "create_shipment", shipmentArgs("SHP1")...)
+	mocktest.Reject(t, stub, r, "add_tracking_event", "Unknown tracking event type Lost.", "SHP1", "Lost", "Sea", "2024-02-02", "Shipco")
+	mocktest.Reject(t, stub, r, "add_tracking_event", "Unknown timestamp format yesterday", "SHP1", "PickedUp", "Mumbai", "yesterday", "Shipco")
+	mocktest.Reject(t, stub, r, "add_tracking_event", "SHP9 Not Found.", "SHP9", "PickedUp", "Mumbai", "2024-02-02", "Shipco")
+	mocktest.Reject(t, stub, r, "add_tracking_event", "The delivery of SHP1 on 2023-12-31 is in the past, the ledger date is 2024-01-01",
+		"SHP1", "Delivered", "Rotterdam", "2023-12-31T23:00:00Z", "Shipco")
+
+	mocktest.Succeed(t, stub, r, "add_tracking_event", "SHP1", "DepartedPort", "Mumbai", "2024-02-03T08:00:00Z", "Shipco")
+	mocktest.Succeed(t, stub, r, "add_tracking_event", "SHP1", "PickedUp", "Mumbai", "2024-02-02T08:00:00Z", "Shipco")
+	if res := getShipment(t, r, stub, "SHP1"); res.Shipment_status != "Departed Port" {
+		t.Errorf("status after a late reported event: %s", res.Shipment_status)
+	}
+	var timeline []TrackingEvent
+	json.Unmarshal(mocktest.Succeed(t, stub, r, "get_shipment_timeline", "SHP1"), &timeline)
+	if len(timeline) != 2 || timeline[0].EventType != "PickedUp" || timeline[1].EventType != "DepartedPort" {
+		t.Errorf("get_shipment_timeline: %+v", timeline)
+	}
+}
+
+// registerParties - register the identity of every party of AGR1, each in its own MSP, the buyer as one certificate
+func registerParties(t *testing.T, r *router.Router, stub *mockstub.MockStub) {
+	for party, identity := range map[string]string{"Shipco": "ShipMSP", "Sellbank": "SellbankMSP", "Buybank": "BuybankMSP",
+		"Buyerco": "BuyerMSP:buyer-admin", "Sellerco": "SellerMSP", "Portauth": "PortMSP"} {
+		mocktest.Succeed(t, stub, r, "register_party", party, identity)
+	}
+}
+
+func TestBillOfLading(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	mocktest.Succeed(t, stub, r, "create_shipment", shipmentArgs("SHP1")...)
+	mocktest.Reject(t, stub, r, "issue_ebl", "Shipco has no registered identity", "SHP1", "EBL1")
+	registerParties(t, r, stub)
+	mocktest.Reject(t, stub, r, "transfer_ebl", "No bill of lading was issued", "SHP1", "Shipco", "Sellbank")
+	mocktest.ActAs(t, stub, "BuyerMSP", "buyer-admin")
+	mocktest.Reject(t, stub, r, "issue_ebl", "does not act for Shipco", "SHP1", "EBL1")
+	mocktest.ActAs(t, stub, "ShipMSP", "clerk")
+	mocktest.Succeed(t, stub, r, "issue_ebl", "SHP1", "EBL1")
+	mocktest.Reject(t, stub, r, "issue_ebl", "already issued", "SHP1", "EBL2")
+	mocktest.Reject(t, stub, r, "transfer_ebl", "Sellerco is not the holder", "SHP1", "Sellerco", "Buyerco")
+	mocktest.Reject(t, stub, r, "transfer_ebl", "is not the holder", "SHP1", `Seller "co"`, "Buyerco")
+	if stub.Message() != `Seller "co" is not the holder of the bill of lading.` {
+		t.Errorf("errEvent of a holder with quotes: %s", stub.LastEvent().Payload)
+	}
+	mocktest.Reject(t, stub, r, "transfer_ebl", "endorsed from Shipco to Sellbank, not to Buybank", "SHP1", "Shipco", "Buybank")
+	mocktest.Reject(t, stub, r, "add_tracking_event", "can not be delivered while its bill of lading EBL1 is held by Shipco",
+		"SHP1", "Delivered", "Rotterdam", "2024-02-28T10:00:00Z", "Shipco")
+
+	//each holder endorses the bill to the next one, and no one else can
+	mocktest.ActAs(t, stub, "BuybankMSP", "officer")
+	mocktest.Reject(t, stub, r, "transfer_ebl", "does not act for Shipco", "SHP1", "Shipco", "Sellbank")
+	mocktest.ActAs(t, stub, "ShipMSP", "clerk")
+	mocktest.Succeed(t, stub, r, "transfer_ebl", "SHP1", "Shipco", "Sellbank")
+	mocktest.Reject(t, stub, r, "transfer_ebl", "does not act for Sellbank", "SHP1", "Sellbank", "Buybank")
+	mocktest.ActAs(t, stub, "SellbankMSP", "officer")
+	mocktest.Succeed(t, stub, r, "transfer_ebl", "SHP1", "Sellbank", "Buybank")
+	mocktest.ActAs(t, stub, "BuybankMSP", "officer")
+	mocktest.Reject(t, stub, r, "surrender_ebl", "must be endorsed to Buyerco before it is surrendered", "SHP1", "Buybank", "Rotterdam")
+	mocktest.Succeed(t, stub, r, "transfer_ebl", "SHP1", "Buybank", "Buyerco")
+	mocktest.Reject(t, stub, r, "release_cargo", "before the bill of lading is surrendered", "SHP1")
+	mocktest.Reject(t, stub, r, "surrender_ebl", "does not act for Buyerco", "SHP1", "Buyerco", "Rotterdam")
+	mocktest.ActAs(t, stub, "BuyerMSP", "buyer-clerk")
+	mocktest.Reject(t, stub, r, "surrender_ebl", "does not act for Buyerco", "SHP1", "Buyerco", "Rotterdam")
+	mocktest.ActAs(t, stub, "BuyerMSP", "buyer-admin")
+	mocktest.Reject(t, stub, r, "surrender_ebl", "only be surrendered at the destination Rotterdam", "SHP1", "Buyerco", "Mumbai")
+	mocktest.Succeed(t, stub, r, "surrender_ebl", "SHP1", "Buyerco", "Rotterdam")
+	mocktest.Reject(t, stub, r, "transfer_ebl", "Bill of lading is already Surrendered.", "SHP1", "Buyerco", "Shipco")
+
+	ebl := BillOfLading{}
+	json.Unmarshal(mocktest.Succeed(t, stub, r, "get_ebl", "SHP1"), &ebl)
+	if ebl.Holder != "Buyerco" || ebl.Ebl_status != "Surrendered" || len(ebl.Endorsements) != 3 ||
+		strings.Join(ebl.Endorsement_chain, ",") != "Shipco,Sellbank,Buybank,Buyerco" {
+		t.Errorf("get_ebl: %+v", ebl)
+	}
+	mocktest.Reject(t, stub, r, "add_tracking_event", "Only the admin MSP or the shipper adds a tracking event. The caller BuyerMSP:buyer-admin does not act for Shipco",
+		"SHP1", "Delivered", "Rotterdam", "2024-02-28T10:00:00Z", "Shipco")
+	mocktest.ActAs(t, stub, "ShipMSP", "clerk")
+	mocktest.Succeed(t, stub, r, "add_tracking_event", "SHP1", "Delivered", "Rotterdam", "2024-02-28T10:00:00Z", "Shipco")
+	mocktest.Reject(t, stub, r, "release_cargo", "no clearance is recorded", "SHP1")
+	mocktest.ActAs(t, stub, "PortMSP", "officer")
+	mocktest.Succeed(t, stub, r, "port_clearance_action", "SHP1", "Portauth", "Clear", "Documents in order")
+	mocktest.Succeed(t, stub, r, "release_cargo", "SHP1")
+	if res := getShipment(t, r, stub, "SHP1"); res.Shipment_status != "Cargo Released" {
+		t.Errorf("status after release: %s", res.Shipment_status)
+	}
+}
+
+// sensorDevice - a new ECDSA sensor key and its PEM encoded public key
+func sensorDevice(t *testing.T) (*ecdsa.PrivateKey, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+// signedReading - a reading of deviceId signed with key
+func signedReading(t *testing.T, key *ecdsa.PrivateKey, deviceId string, timestamp string, temperature float64, humidity float64) SensorReading {
+	reading := SensorReading{DeviceID: deviceId, Timestamp: timestamp, Temperature: temperature, Humidity: humidity}
+	digest := sha256.Sum256(sensorReadingPayload(reading))
+	signature, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	reading.Signature = base64.StdEncoding.EncodeToString(signature)
+	return reading
+}
+
+func TestColdChain(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	mocktest.Succeed(t, stub, r, "create_shipment", shipmentArgs("SHP1")...)
+	key, publicKeyPEM := sensorDevice(t)
+	mocktest.Reject(t, stub, r, "register_sensor_device", "Cold-chain thresholds must be set", "SHP1", "DEV1", publicKeyPEM)
+	mocktest.Reject(t, stub, r, "set_cold_chain_thresholds", "Minimum thresholds must not exceed maximum thresholds.", "SHP1", "8", "2", "30", "80")
+	mocktest.Succeed(t, stub, r, "set_cold_chain_thresholds", "SHP1", "2", "8", "30", "80")
+	mocktest.Reject(t, stub, r, "register_sensor_device", "can not be parsed", "SHP1", "DEV1", "not a key")
+	mocktest.Succeed(t, stub, r, "register_sensor_device", "SHP1", "DEV1", publicKeyPEM)
+
+	//another party can neither set the thresholds, register a device nor replace the key of one
+	_, otherPEM := sensorDevice(t)
+	registerParties(t, r, stub)
+	mocktest.ActAs(t, stub, "BuyerMSP", "buyer-admin")
+	mocktest.Reject(t, stub, r, "set_cold_chain_thresholds", "Only the admin MSP or the shipper sets the cold-chain thresholds. The caller BuyerMSP:buyer-admin does not act for Shipco",
+		"SHP1", "-20", "40", "0", "100")
+	mocktest.Reject(t, stub, r, "register_sensor_device", "Only the admin MSP or the shipper registers a sensor device. The caller BuyerMSP:buyer-admin does not act for Shipco",
+		"SHP1", "DEV2", otherPEM)
+	mocktest.Reject(t, stub, r, "register_sensor_device", "Device DEV1 is already registered for this Shipment, its key can not be replaced.",
+		"SHP1", "DEV1", otherPEM)
+	mocktest.ActAs(t, stub, "ShipMSP", "clerk")
+	mocktest.Succeed(t, stub, r, "set_cold_chain_thresholds", "SHP1", "2", "8", "30", "80")
+	mocktest.Succeed(t, stub, r, "register_sensor_device", "SHP1", "DEV2", otherPEM)
+
+	readings := []SensorReading{
+		signedReading(t, key, "DEV1", "2024-02-02T00:00:00Z", 5, 50),
+		signedReading(t, key, "DEV1", "2024-02-02T00:10:00Z", 9.5, 50),
+		signedReading(t, key, "DEV1", "2024-02-02T00:30:00Z", 6, 85),
+		signedReading(t, key, "DEV1", "2024-02-02T00:40:00Z", 6, 50),
+	}
+	tampered := readings[0]
+	tampered.Temperature = 4
+	batch, _ := json.Marshal([]SensorReading{tampered})
+	mocktest.Reject(t, stub, r, "add_sensor_readings", "is invalid Batch rejected.", "SHP1", string(batch))
+	batch, _ = json.Marshal([]SensorReading{readings[1], readings[0]})
+	mocktest.Reject(t, stub, r, "add_sensor_readings", "Reading of device DEV1 at 2024-02-02T00:00:00Z is not later than its last reading at 2024-02-02T00:10:00Z.",
+		"SHP1", string(batch))
+	batch, _ = json.Marshal(readings)
+	mocktest.Succeed(t, stub, r, "add_sensor_readings", "SHP1", string(batch))
+	if event, err := events.Decode(stub.LastEvent().Name, stub.LastEvent().Payload); err != nil || event.Data["readings"] != 4.0 ||
+		event.Data["excursions"] != 2.0 {
+		t.Errorf("add_sensor_readings event: %s", stub.LastEvent().Payload)
+	}
+
+	//a batch sent again is refused rather than counted twice
+	mocktest.Reject(t, stub, r, "add_sensor_readings", "is not later than its last reading at 2024-02-02T00:40:00Z", "SHP1", string(batch))
+
+	summary := TelemetrySummary{}
+	json.Unmarshal(mocktest.Succeed(t, stub, r, "get_telemetry_summary", "SHP1"), &summary)
+	expected := TelemetrySummary{ShipmentID: "SHP1", Readings: 4, Excursions: 2, MinTemperature: 5, MaxTemperature: 9.5,
+		MinHumidity: 50, MaxHumidity: 85, TemperatureOutOfRangeSeconds: 1200, HumidityOutOfRangeSeconds: 600,
+		OutOfRangeSeconds: 1800, FirstReading: "2024-02-02T00:00:00Z", LastReading: "2024-02-02T00:40:00Z"}
+	if summary != expected {
+		t.Errorf("get_telemetry_summary: %+v", summary)
+	}
+}
+
+func TestPortClearance(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	mocktest.Succeed(t, stub, r, "create_shipment", shipmentArgs("SHP1")...)
+	mocktest.Reject(t, stub, r, "port_clearance_action", "Otherport is not the port authority of Agreement AGR1.", "SHP1", "Otherport", "Inspect", "Routine")
+	mocktest.Reject(t, stub, r, "port_clearance_action", "Unknown clearance action Seize.", "SHP1", "Portauth", "Seize", "Routine")
+	mocktest.Reject(t, stub, r, "submit_clearance_documents", "No documents were requested", "SHP1", "Sellerco", "Invoice")
+
+	//only the identity registered for the port authority acts for it
+	mocktest.Reject(t, stub, r, "port_clearance_action", "Portauth has no registered identity", "SHP1", "Portauth", "Inspect", "Routine")
+	registerParties(t, r, stub)
+	mocktest.ActAs(t, stub, "ShipMSP", "clerk")
+	mocktest.Reject(t, stub, r, "port_clearance_action", "The caller ShipMSP:clerk does not act for Portauth.", "SHP1", "Portauth", "Clear", "Routine")
+	mocktest.ActAs(t, stub, "PortMSP", "officer")
+	mocktest.Succeed(t, stub, r, "port_clearance_action", "SHP1", "Portauth", "RequestDocuments", "Missing invoice")
+	mocktest.Reject(t, stub, r, "port_clearance_action", "while requested documents are outstanding", "SHP1", "Portauth", "Clear", "Done")
+	mocktest.Succeed(t, stub, r, "submit_clearance_documents", "SHP1", "Sellerco", "Invoice attached")
+	mocktest.Succeed(t, stub, r, "port_clearance_action", "SHP1", "Portauth", "Clear", "Documents in order")
+	mocktest.Reject(t, stub, r, "port_clearance_action", "Shipment is already cleared.", "SHP1", "Portauth", "PlaceHold", "Late doubt")
+
+	if res := getShipment(t, r, stub, "SHP1"); res.Clearance_status != "Cleared" {
+		t.Errorf("Shipment clearance status: %s", res.Clearance_status)
+	}
+	clearance := Clearance{}
+	json.Unmarshal(mocktest.Succeed(t, stub, r, "get_clearance", "SHP1"), &clearance)
+	if len(clearance.Actions) != 3 || clearance.Actions[1].Action != "SubmitDocuments" {
+		t.Errorf("get_clearance: %+v", clearance)
+	}
+	res := struct {
+		Clearance_status string `json:"clearance_status"`
+	}{}
+	json.Unmarshal(mocktest.Succeed(t, stub, r, "getAgreement_byID", "AGR1"), &res)
+	if res.Clearance_status != "Cleared" {
+		t.Errorf("Agreement clearance status: %s", res.Clearance_status)
+	}
+}
+
+func TestDeliverySLA(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	mocktest.Succeed(t, stub, r, "set_liquidated_damages", "AGR1", "0.5", "10", "2")
+	mocktest.Succeed(t, stub, r, "create_shipment", shipmentArgs("SHP1")...)
+	mocktest.Succeed(t, stub, r, "create_shipment", shipmentArgs("SHP2")...)
+	mocktest.Reject(t, stub, r, "evaluate_delivery_sla", "SHP1 has no actual delivery date.", "SHP1")
+
+	mocktest.Succeed(t, stub, r, "add_tracking_event", "SHP1", "Delivered", "Rotterdam", "2024-03-06", "Shipco")
+	sla := DeliverySLA{}
+	json.Unmarshal(mocktest.Succeed(t, stub, r, "get_delivery_sla", "SHP1"), &sla)
+	if sla.SLA_status != "Late" || sla.DaysLate != 5 || sla.LiquidatedDamages != "37.50" || sla.ExpectedDelivery_date != "2024-03-01" {
+		t.Errorf("get_delivery_sla: %+v", sla)
+	}
+	mocktest.Succeed(t, stub, r, "add_tracking_event", "SHP2", "Delivered", "Rotterdam", "2024-02-28", "Shipco")
+
+	var performance []ShipperPerformance
+	json.Unmarshal(mocktest.Succeed(t, stub, r, "get_shipper_performance", " "), &performance)
+	expected := ShipperPerformance{ShipperName: "Shipco", Deliveries: 2, OnTime: 1, Late: 1, OnTimeRate: "50.00",
+		AverageDaysLate: "5.00", LiquidatedDamages: "37.50"}
+	if len(performance) != 1 || performance[0] != expected {
+		t.Errorf("get_shipper_performance: %+v", performance)
+	}
+
+	//an Agreement written before dates were checked holds its delivery date in a legacy layout, day first
+	legacy := strings.Replace(string(stub.State["Agreement_AGR1"]), `"delivery_date": "2024-03-01"`, `"delivery_date": "01/03/2024"`, 1)
+	stub.State["Agreement_AGR1"] = []byte(legacy)
+	mocktest.Succeed(t, stub, r, "create_shipment", shipmentArgs("SHP3")...)
+	mocktest.Succeed(t, stub, r, "add_tracking_event", "SHP3", "Delivered", "Rotterdam", "2024-03-06", "Shipco")
+	json.Unmarshal(mocktest.Succeed(t, stub, r, "get_delivery_sla", "SHP3"), &sla)
+	if sla.ShipmentID != "SHP3" || sla.DaysLate != 5 || sla.LiquidatedDamages != "37.50" {
+		t.Errorf("get_delivery_sla of a legacy date: %+v", sla)
+	}
+
+	//a date in no known layout leaves the Shipment undelivered rather than delivered without its SLA
+	stub.State["Agreement_AGR1"] = []byte(strings.Replace(legacy, "01/03/2024", "early March", 1))
+	mocktest.Succeed(t, stub, r, "create_shipment", shipmentArgs("SHP4")...)
+	mocktest.Reject(t, stub, r, "add_tracking_event", "Delivery SLA of SHP4 not evaluated, the delivery is not recorded: Unknown date format early March",
+		"SHP4", "Delivered", "Rotterdam", "2024-03-06", "Shipco")
+	if res := getShipment(t, r, stub, "SHP4"); res.Shipment_status == "Delivered" {
+		t.Errorf("Shipment delivered without its SLA: %+v", res)
+	}
+}
+
+func TestShipmentItems(t *testing.T) {
+	r, stub := newTradeFinance(t)
+	mocktest.Succeed(t, stub, r, "create_shipment", shipmentArgs("SHP1")...)
+	if valAsBytes := string(mocktest.Succeed(t, stub, r, "get_shipment_items", "SHP1")); valAsBytes != "[]" {
+		t.Errorf("get_shipment_items before they are set: %s", valAsBytes)
+	}
+	mocktest.Reject(t, stub, r, "set_shipment_items", "Over-shipment of item ITM-1", "SHP1", `[{"item_id":"ITM-1","quantity":"101"}]`)
+	if _, found := stub.State[shipmentItemsKey("SHP1")]; found {
+		t.Errorf("rejected items were recorded")
+	}
+	mocktest.Succeed(t, stub, r, "set_shipment_items", "SHP1", `[{"item_id":"ITM-1","quantity":"40"}]`)
+	mocktest.Reject(t, stub, r, "set_shipment_items", "already recorded", "SHP1", `[{"item_id":"ITM-1","quantity":"10"}]`)
+	var items []ShipmentItem
+	json.Unmarshal(mocktest.Succeed(t, stub, r, "get_shipment_items", "SHP1"), &items)
+	if len(items) != 1 || items[0] != (ShipmentItem{ItemId: "ITM-1", Quantity: "40"}) {
+		t.Errorf("get_shipment_items: %+v", items)
+	}
+	res := struct {
+		Shipping_status string `json:"shipping_status"`
+	}{}
+	json.Unmarshal(mocktest.Succeed(t, stub, r, "getAgreement_byID", "AGR1"), &res)
+	if res.Shipping_status != "Partially Shipped" {
+		t.Errorf("Agreement shipping status: %s", res.Shipping_status)
+	}
+
+	mocktest.Succeed(t, stub, r, "delete_shipment", "SHP1")
+	if _, found := stub.State[shipmentItemsKey("SHP1")]; found {
+		t.Errorf("items of a deleted shipment are kept")
+	}
+	balance := struct {
+		Lines []struct {
+			Remaining string `json:"remaining"`
+		} `json:"lines"`
+		Shipments []string `json:"shipments"`
+	}{}
+	json.Unmarshal(mocktest.Succeed(t, stub, r, "get_shipped_balance", "AGR1"), &balance)
+	if len(balance.Lines) != 1 || balance.Lines[0].Remaining != "100" || len(balance.Shipments) != 0 {
+		t.Errorf("shipped balance after the shipment is deleted: %+v", balance)
+	}
+	json.Unmarshal(mocktest.Succeed(t, stub, r, "getAgreement_byID", "AGR1"), &res)
+	if res.Shipping_status != "Not Shipped" {
+		t.Errorf("Agreement shipping status after the shipment is deleted: %s", res.Shipping_status)
+	}
+	mocktest.Succeed(t, stub, r, "create_shipment", shipmentArgs("SHP2")...)
+	mocktest.Succeed(t, stub, r, "set_shipment_items", "SHP2", `[{"item_id":"ITM-1","quantity":"100"}]`)
+}
@@ -0,0 +1,295 @@
+package simulator
+
+import (
+"bufio"
+"bytes"
+"errors"
+"fmt"
+"io"
+"sort"
+"strings"
+"encoding/json"
+)
+
+// Console reads commands line by line, runs them on a Network and prints each transaction with its event
+type Console struct {
+	Network *Network
+	Out io.Writer
+	Prompt string									// printed before every line, empty for scripts
+	KeepGoing bool									// carry on after a transaction fails unexpectedly
+	creator []byte									// the caller set by as, nil for the admin that deployed the chaincodes
+}
+
+var consoleHelp = `Commands:
+  <function> [args...]            run a function on the chaincode that owns it, e.g. create_po PO1 Sellerco ...
+  <chaincode>:<function> [args...] run a function on one chaincode, e.g. managePO:execute_batch '[...]'
+  ! <function> [args...]          run a function that is expected to fail
+  as [mspId:commonName]           submit the next functions as this identity, e.g. as ShipMSP:shipper, or as the admin
+  functions [chaincode]           list the functions of every chaincode or of one
+  chaincodes                      list the chaincodes
+  state <chaincode> [prefix]      list the state keys of a chaincode
+  get <chaincode> <key>           print a state value
+  help                            print this help
+  quit                            leave
+Arguments are separated by spaces, quote them with "..." or '...' to keep spaces, e.g. JSON. Lines starting with # are comments.
+`
+
+// ============================================================================================================================
+// Run - run every line of in, stops at the first unexpected failure unless KeepGoing is set
+// ============================================================================================================================
+func (c *Console) Run(in io.Reader) error {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)			//statement lines and camt.054 messages can be long
+	lineNo := 0
+	failures := 0
+	for {
+		fmt.Fprint(c.Out, c.Prompt)
+		if !scanner.Scan() {
+			break
+		}
+		lineNo++
+		quit, err := c.Execute(scanner.Text())
+		if err != nil {
+			failures++
+			fmt.Fprintf(c.Out, "line %d: %s\n", lineNo, err)
+			if !c.KeepGoing && c.Prompt == "" {
+				return fmt.Errorf("line %d: %s", lineNo, err)
+			}
+		}
+		if quit {
+			break
+		}
+	}
+	if c.Prompt != "" {
+		fmt.Fprintln(c.Out)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if failures > 0 && c.Prompt == "" {
+		return fmt.Errorf("%d line(s) failed", failures)
+	}
+	return nil
+}
+// ============================================================================================================================
+// Execute - run one command line, the error reports a malformed line or an unexpected outcome
+// ============================================================================================================================
+func (c *Console) Execute(line string) (bool, error) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return false, nil
+	}
+	expectFailure := false
+	if strings.HasPrefix(line, "!") {
+		expectFailure = true
+		line = strings.TrimSpace(line[1:])
+	}
+	words, err := Split(line)
+	if err != nil {
+		return false, err
+	}
+	if len(words) == 0 {
+		return false, errors.New("! needs a function")
+	}
+	switch words[0] {
+	case "quit", "exit":
+		return true, nil
+	case "help":
+		fmt.Fprint(c.Out, consoleHelp)
+		return false, nil
+	case "chaincodes":
+		for _, cc := range c.Network.Chaincodes {
+			fmt.Fprintf(c.Out, "%s (%s)\n", cc.Name, strings.Join(cc.Router.Roles(), ", "))
+		}
+		return false, nil
+	case "functions":
+		return false, c.functions(words[1:])
+	case "state":
+		return false, c.state(words[1:])
+	case "get":
+		return false, c.get(words[1:])
+	case "as":
+		return false, c.as(words[1:])
+	}
+	var res Result
+	if c.creator == nil {
+		res = c.Network.Call(words[0], words[1:]...)
+	}else{
+		res = c.Network.CallAs(c.creator, words[0], words[1:]...)
+	}
+	c.Print(res)
+	if res.Failed() && !expectFailure {
+		return false, errors.New(words[0] + " failed: " + res.Message())
+	}
+	if !res.Failed() && expectFailure {
+		return false, errors.New(words[0] + " succeeded, expected it to fail")
+	}
+	return false, nil
+}
+// ============================================================================================================================
+// Print - print a transaction, its event and its response
+// ============================================================================================================================
+func (c *Console) Print(res Result) {
+	if res.Chaincode == "" {
+		fmt.Fprintf(c.Out, "error: %s\n", res.Err)
+		return
+	}
+	fmt.Fprintf(c.Out, "%s %s %s\n", res.TxID, res.Chaincode, res.Function)
+	if len(res.Events) > 0 {
+		event := res.Events[len(res.Events)-1]			//a peer emits only the last event set by a transaction
+		fmt.Fprintf(c.Out, "  event %s %s\n", event.Name, compactJSON(event.Payload))
+	}
+	if res.Err != nil {
+		fmt.Fprintf(c.Out, "  rejected: %s\n", res.Err)
+	}
+	if len(res.Response) > 0 {
+		fmt.Fprintf(c.Out, "  response:\n%s\n", indentJSON(res.Response, "    "))
+	}
+}
+// ============================================================================================================================
+// functions - print the functions of every chaincode or of the named ones
+// ============================================================================================================================
+func (c *Console) functions(names []string) error {
+	functions := c.Network.Functions()
+	if len(names) == 0 {
+		for name := range functions {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+	for _, name := range names {
+		list, found := functions[name]
+		if !found {
+			return errors.New("Unknown chaincode " + name)
+		}
+		fmt.Fprintf(c.Out, "%s:\n", name)
+		for _, function := range list {
+			fmt.Fprintf(c.Out, "  %s\n", function)
+		}
+	}
+	return nil
+}
+// ============================================================================================================================
+// as - submit the next functions as an identity, or as the admin again when none is given
+// ============================================================================================================================
+func (c *Console) as(args []string) error {
+	if len(args) > 1 {
+		return errors.New("usage: as [mspId:commonName]")
+	}
+	if len(args) == 0 {
+		c.creator = nil
+		return nil
+	}
+	creator, err := Identity(args[0])
+	if err != nil {
+		return err
+	}
+	c.creator = creator
+	return nil
+}
+// ============================================================================================================================
+// state - print the state keys of a chaincode
+// ============================================================================================================================
+func (c *Console) state(args []string) error {
+	if len(args) < 1 || len(args) > 2 {
+		return errors.New("usage: state <chaincode> [prefix]")
+	}
+	cc, found := c.Network.Chaincode(args[0])
+	if !found {
+		return errors.New("Unknown chaincode " + args[0])
+	}
+	prefix := ""
+	if len(args) == 2 {
+		prefix = args[1]
+	}
+	for _, key := range cc.Keys(prefix) {
+		fmt.Fprintln(c.Out, key)
+	}
+	return nil
+}
+// ============================================================================================================================
+// get - print a state value of a chaincode
+// ============================================================================================================================
+func (c *Console) get(args []string) error {
+	if len(args) != 2 {
+		return errors.New("usage: get <chaincode> <key>")
+	}
+	cc, found := c.Network.Chaincode(args[0])
+	if !found {
+		return errors.New("Unknown chaincode " + args[0])
+	}
+	valAsBytes, found := cc.Stub.State[args[1]]
+	if !found {
+		return errors.New(args[1] + " Not Found")
+	}
+	fmt.Fprintln(c.Out, indentJSON(valAsBytes, ""))
+	return nil
+}
+// ============================================================================================================================
+// Split - split a command line into words, "..." and '...' keep spaces and \ escapes the next character outside '...'
+// ============================================================================================================================
+func Split(line string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+	for _, ch := range line {
+		switch {
+		case escaped:
+			word.WriteRune(ch)
+			escaped = false
+		case ch == '\\' && quote != '\'':
+			escaped = true
+			inWord = true
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}else{
+				word.WriteRune(ch)
+			}
+		case ch == '"' || ch == '\'':
+			quote = ch
+			inWord = true
+		case ch == ' ' || ch == '\t':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(ch)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, errors.New("Unterminated " + string(quote) + " quote")
+	}
+	if escaped {
+		return nil, errors.New("Line ends with \\")
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+// ============================================================================================================================
+// compactJSON - a payload on one line, unchanged when it is not JSON
+// ============================================================================================================================
+func compactJSON(payload []byte) string {
+	var out bytes.Buffer
+	if json.Compact(&out, payload) != nil {
+		return string(payload)
+	}
+	return out.String()
+}
+// ============================================================================================================================
+// indentJSON - a payload indented for reading, unchanged when it is not JSON, e.g. the pain.001 XML
+// ============================================================================================================================
+func indentJSON(payload []byte, prefix string) string {
+	var out bytes.Buffer
+	if json.Indent(&out, payload, prefix, "  ") != nil {
+		return prefix + strings.Replace(strings.TrimSpace(string(payload)), "\n", "\n" + prefix, -1)
+	}
+	return prefix + out.String()
+}
@@ -0,0 +1,394 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements.  See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership.  The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License.  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+// Package simulator hosts the trade-finance chaincodes in-process on the in-memory ledger of mockstub,
+// either as the four separate chaincodes linked through register_chaincode or as the single
+// TradeFinance chaincode, and runs their functions by name as one transaction each.
+package simulator
+
+import (
+"errors"
+"sort"
+"strings"
+"encoding/json"
+
+"github.com/wipro-blockchain/TF-v1/internal/agreement"
+"github.com/wipro-blockchain/TF-v1/internal/mockstub"
+"github.com/wipro-blockchain/TF-v1/internal/payment"
+"github.com/wipro-blockchain/TF-v1/internal/po"
+"github.com/wipro-blockchain/TF-v1/internal/router"
+"github.com/wipro-blockchain/TF-v1/internal/shipment"
+)
+
+var DefaultBalance = "100000.00"			//opening balance of the buyer and seller accounts, the Init argument of every chaincode
+
+// Chaincode is one chaincode of the network with its own state
+type Chaincode struct {
+	Name string										// deployed name, e.g. "managePO"
+	Router *router.Router
+	Stub *mockstub.MockStub							// nil for a chaincode on the peers, see NewChaincode
+}
+
+// Network is a set of chaincodes on one in-memory ledger, each function runs on the chaincode that owns it
+type Network struct {
+	Chaincodes []*Chaincode
+	byName map[string]*Chaincode
+	listeners []func(Result)
+}
+
+// Result is the outcome of one transaction
+type Result struct {
+	Chaincode string
+	Function string
+	Args []string
+	TxID string
+	Query bool
+	Response []byte
+	Events []mockstub.Event
+	Err error										// a Go error, the transaction was rejected
+}
+
+// ============================================================================================================================
+// Failed - whether the transaction was rejected or ended with an errEvent
+// ============================================================================================================================
+func (res Result) Failed() bool {
+	return res.Err != nil || (len(res.Events) > 0 && res.Events[len(res.Events)-1].Name == "errEvent")
+}
+
+// domainChaincodes - the deployed name and chaincode name of the separate chaincode of each role
+var domainChaincodes = []struct {
+	role string
+	name string
+	title string
+}{
+	{"po", "managePO", "ManagePO"},
+	{"agreement", "manageAgreement", "ManageAgreement"},
+	{"payment", "managePayment", "ManagePayment"},
+	{"shipment", "manageShipment", "ManageShipment"},
+}
+
+// ============================================================================================================================
+// New - the four separate chaincodes, initialized with balance and registered with each other under their deployed names
+// ============================================================================================================================
+func New(balance string) (*Network, error) {
+	n := &Network{byName: map[string]*Chaincode{}}
+	var ledger *mockstub.MockStub
+	for _, d := range domainChaincodes {
+		r := router.New(d.title)
+		registerDomain(r, d.role)
+		if ledger == nil {
+			ledger = mockstub.New(d.name)
+			n.add(&Chaincode{Name: d.name, Router: r, Stub: ledger})
+		}else{
+			n.add(&Chaincode{Name: d.name, Router: r, Stub: ledger.Deploy(d.name, r)})
+		}
+	}
+	for _, cc := range n.Chaincodes {
+		if err := n.init(cc, balance); err != nil {
+			return nil, err
+		}
+	}
+	for _, cc := range n.Chaincodes {
+		for _, d := range domainChaincodes {
+			if d.name == cc.Name {
+				continue
+			}
+			res := n.run(cc, "register_chaincode", []string{d.role, d.name})
+			if res.Failed() {
+				return nil, errors.New(cc.Name + ": register_chaincode " + d.role + " failed: " + res.Message())
+			}
+		}
+	}
+	return n, nil
+}
+// ============================================================================================================================
+// NewTradeFinance - the single TradeFinance chaincode with all four domains in one state, initialized with balance
+// ============================================================================================================================
+func NewTradeFinance(balance string) (*Network, error) {
+	n := &Network{byName: map[string]*Chaincode{}}
+	r := router.New("TradeFinance")
+	for _, d := range domainChaincodes {
+		registerDomain(r, d.role)
+	}
+	cc := &Chaincode{Name: "tradeFinance", Router: r, Stub: mockstub.New("tradeFinance")}
+	n.add(cc)
+	if err := n.init(cc, balance); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+// ============================================================================================================================
+// NewChaincode - a chaincode without a ledger running the domains of roles under its deployed name, e.g. to look up the
+// chaincode of a function on the peers with Route
+// ============================================================================================================================
+func NewChaincode(name string, roles ...string) (*Chaincode, error) {
+	r := router.New(name)
+	for _, role := range roles {
+		switch role {
+		case "po", "agreement", "payment", "shipment":
+			registerDomain(r, role)
+		default:
+			return nil, errors.New(name + ": unknown role " + role + ", expecting po, agreement, payment or shipment")
+		}
+	}
+	return &Chaincode{Name: name, Router: r}, nil
+}
+// ============================================================================================================================
+// registerDomain - add the domain of role to r, linked through r
+// ============================================================================================================================
+func registerDomain(r *router.Router, role string) {
+	switch role {
+	case "po":
+		r.Register("po", po.New())
+	case "agreement":
+		r.Register("agreement", agreement.New(r))
+	case "payment":
+		r.Register("payment", payment.New(r))
+	case "shipment":
+		r.Register("shipment", shipment.New(r))
+	}
+}
+// ============================================================================================================================
+// add - add a chaincode to the network
+// ============================================================================================================================
+func (n *Network) add(cc *Chaincode) {
+	n.Chaincodes = append(n.Chaincodes, cc)
+	n.byName[cc.Name] = cc
+}
+// ============================================================================================================================
+// init - run the Init of a chaincode, a failed Init stops the network
+// ============================================================================================================================
+func (n *Network) init(cc *Chaincode, balance string) error {
+	res := n.run(cc, "init", []string{balance})
+	if res.Failed() {
+		return errors.New(cc.Name + ": init failed: " + res.Message())
+	}
+	return nil
+}
+// ============================================================================================================================
+// Chaincode - a chaincode by its deployed name
+// ============================================================================================================================
+func (n *Network) Chaincode(name string) (*Chaincode, bool) {
+	cc, found := n.byName[name]
+	return cc, found
+}
+// ============================================================================================================================
+// Lookup - the chaincode that runs a function, "<chaincode>:<function>" picks one for the functions every chaincode has,
+// e.g. managePO:execute_batch, and "<role>:<function>" the one serving a role, e.g. payment:register_party
+// ============================================================================================================================
+func (n *Network) Lookup(function string) (*Chaincode, string, error) {
+	return lookup(n.Chaincodes, function)
+}
+// ============================================================================================================================
+// lookup - the chaincode of chaincodes that runs a function, see Lookup
+// ============================================================================================================================
+func lookup(chaincodes []*Chaincode, function string) (*Chaincode, string, error) {
+	if i := strings.Index(function, ":"); i >= 0 {
+		for _, cc := range chaincodes {
+			if cc.Name == function[:i] {
+				return cc, function[i+1:], nil
+			}
+		}
+		for _, cc := range chaincodes {
+			if cc.serves(function[:i]) {
+				return cc, function[i+1:], nil
+			}
+		}
+		return nil, "", errors.New("Unknown chaincode " + function[:i])
+	}
+	var owners []*Chaincode
+	for _, cc := range chaincodes {
+		if cc.owns(function) {
+			owners = append(owners, cc)
+		}
+	}
+	if len(owners) == 1 {
+		return owners[0], function, nil
+	}
+	if len(owners) == 0 {
+		return nil, "", errors.New("No chaincode has a function " + function)
+	}
+	return nil, "", errors.New(function + " is a function of every chaincode, name one as <chaincode>:" + function)
+}
+// ============================================================================================================================
+// owns - whether a function belongs to the domains of a chaincode, the functions of the router itself belong to every one
+// ============================================================================================================================
+func (cc *Chaincode) owns(function string) bool {
+	for _, f := range cc.Router.Functions() {
+		if f == function {
+			return true
+		}
+	}
+	return false
+}
+// ============================================================================================================================
+// serves - whether a role, e.g. "payment", is one of the domains of a chaincode
+// ============================================================================================================================
+func (cc *Chaincode) serves(role string) bool {
+	for _, r := range cc.Router.Roles() {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+// ============================================================================================================================
+// Route - the chaincode of chaincodes that runs a function with args and the function name on it, as Lookup, except that
+// execute_once and get_idempotency_outcome run on the chaincode of the invoke they name
+// ============================================================================================================================
+func Route(chaincodes []*Chaincode, function string, args []string) (*Chaincode, string, error) {
+	if (function == "execute_once" || function == "get_idempotency_outcome") && len(args) > 1 {
+		if cc, _, err := lookup(chaincodes, args[1]); err == nil {
+			return cc, function, nil						//runs on the chaincode of the invoke it wraps
+		}
+	}
+	return lookup(chaincodes, function)
+}
+// ============================================================================================================================
+// Query - the chaincode of chaincodes that runs a query function with args and the function name on it, as Route. An
+// invoke is refused, as an evaluated invoke would run without being committed
+// ============================================================================================================================
+func Query(chaincodes []*Chaincode, function string, args []string) (*Chaincode, string, error) {
+	cc, name, err := Route(chaincodes, function, args)
+	if err != nil {
+		return nil, "", err
+	}
+	if !cc.Router.IsQuery(name) {
+		return nil, "", errors.New(function + " is not a query, it must be submitted")
+	}
+	return cc, name, nil
+}
+// ============================================================================================================================
+// Call - run a function as one transaction on the chaincode that Route gives
+// ============================================================================================================================
+func (n *Network) Call(function string, args ...string) Result {
+	cc, name, err := Route(n.Chaincodes, function, args)
+	if err != nil {
+		return Result{Function: function, Args: args, Err: err}
+	}
+	return n.run(cc, name, args)
+}
+// ============================================================================================================================
+// CallAs - run a function as Call does, submitted by creator, a serialized identity, see mockstub.Identity
+// ============================================================================================================================
+func (n *Network) CallAs(creator []byte, function string, args ...string) Result {
+	previous := make([][]byte, len(n.Chaincodes))
+	for i, cc := range n.Chaincodes {
+		previous[i], cc.Stub.Creator = cc.Stub.Creator, creator
+	}
+	defer func() {
+		for i, cc := range n.Chaincodes {
+			cc.Stub.Creator = previous[i]
+		}
+	}()
+	return n.Call(function, args...)
+}
+// ============================================================================================================================
+// Identity - a caller given as <mspId>:<commonName>, e.g. ShipMSP:shipper, serialized as CallAs takes it
+// ============================================================================================================================
+func Identity(identity string) ([]byte, error) {
+	parts := strings.SplitN(identity, ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, errors.New("An identity is <mspId>:<commonName>, not " + identity)
+	}
+	return mockstub.Identity(parts[0], parts[1])
+}
+// ============================================================================================================================
+// run - run a function of a chaincode as one transaction
+// ============================================================================================================================
+func (n *Network) run(cc *Chaincode, function string, args []string) Result {
+	var valAsBytes []byte
+	var err error
+	if function == "init" {
+		valAsBytes, err = cc.Stub.Init(cc.Router, args...)
+	}else{
+		valAsBytes, err = cc.Stub.Call(cc.Router, function, args...)
+	}
+	res := n.result(cc, function, args, valAsBytes, err)
+	if !res.Query && res.Err == nil {
+		for _, f := range n.listeners {
+			f(res)
+		}
+	}
+	return res
+}
+// ============================================================================================================================
+// Listen - call f with every invoke the network commits from now on, in commit order, a peer's event listener. A query or a
+// rejected transaction is not committed, an invoke ending with an errEvent is, as on a peer
+// ============================================================================================================================
+func (n *Network) Listen(f func(Result)) {
+	n.listeners = append(n.listeners, f)
+}
+// ============================================================================================================================
+// result - the Result of the transaction that just ran on a chaincode
+// ============================================================================================================================
+func (n *Network) result(cc *Chaincode, function string, args []string, valAsBytes []byte, err error) Result {
+	return Result{
+		Chaincode: cc.Name,
+		Function: function,
+		Args: args,
+		TxID: cc.Stub.TxID,
+		Query: cc.Router.IsQuery(function),
+		Response: valAsBytes,
+		Events: cc.Stub.Events,
+		Err: err,
+	}
+}
+// ============================================================================================================================
+// Message - the error, or the "message" field of the last event
+// ============================================================================================================================
+func (res Result) Message() string {
+	if res.Err != nil {
+		return res.Err.Error()
+	}
+	if len(res.Events) == 0 {
+		return ""
+	}
+	payload := res.Events[len(res.Events)-1].Payload
+	msg := struct {
+		Message string `json:"message"`
+	}{}
+	if json.Unmarshal(payload, &msg) == nil && msg.Message != "" {
+		return msg.Message
+	}
+	return string(payload)
+}
+// ============================================================================================================================
+// Functions - the function names of every chaincode, by deployed name
+// ============================================================================================================================
+func (n *Network) Functions() map[string][]string {
+	functions := map[string][]string{}
+	for _, cc := range n.Chaincodes {
+		functions[cc.Name] = cc.Router.Functions()
+	}
+	return functions
+}
+// ============================================================================================================================
+// Keys - the state keys of a chaincode starting with prefix, sorted
+// ============================================================================================================================
+func (cc *Chaincode) Keys(prefix string) []string {
+	var keys []string
+	for key := range cc.Stub.State {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
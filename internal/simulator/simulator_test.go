@@ -0,0 +1,185 @@
+package simulator
+
+import (
+"bytes"
+"os"
+"strings"
+"testing"
+
+"github.com/wipro-blockchain/TF-v1/internal/mockstub"
+)
+
+func TestSplit(t *testing.T) {
+	cases := []struct {
+		line string
+		words []string
+	}{
+		{"create_po PO1  Sellerco", []string{"create_po", "PO1", "Sellerco"}},
+		{`create_shipment SHP1 "" "Mumbai port"`, []string{"create_shipment", "SHP1", "", "Mumbai port"}},
+		{`add_sensor_readings SHP1 '[{"deviceId": "DEV1"}]'`, []string{"add_sensor_readings", "SHP1", `[{"deviceId": "DEV1"}]`}},
+		{`reconcile_statement ST1 csv "a,b\"c"`, []string{"reconcile_statement", "ST1", "csv", `a,b"c`}},
+		{`get\ name`, []string{"get name"}},
+	}
+	for _, c := range cases {
+		words, err := Split(c.line)
+		if err != nil || strings.Join(words, "|") != strings.Join(c.words, "|") || len(words) != len(c.words) {
+			t.Errorf("Split(%s): %q %v", c.line, words, err)
+		}
+	}
+	for _, line := range []string{`create_po "PO1`, `create_po 'PO1`, `create_po PO1\`} {
+		if _, err := Split(line); err == nil {
+			t.Errorf("Split(%s) accepted a malformed line", line)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	n, err := New(DefaultBalance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := map[string]string{"create_po": "managePO", "getAgreement_byID": "manageAgreement", "createEscrow": "managePayment",
+		"add_tracking_event": "manageShipment", "managePayment:execute_batch": "managePayment"}
+	for function, name := range cases {
+		if cc, _, err := n.Lookup(function); err != nil || cc.Name != name {
+			t.Errorf("Lookup(%s): %v", function, err)
+		}
+	}
+	for function, message := range map[string]string{"execute_batch": "is a function of every chaincode",
+		"create_pos": "No chaincode has a function create_pos", "tradeFinance:create_po": "Unknown chaincode tradeFinance"} {
+		if _, _, err := n.Lookup(function); err == nil || !strings.Contains(err.Error(), message) {
+			t.Errorf("Lookup(%s): %v", function, err)
+		}
+	}
+}
+
+func TestRoute(t *testing.T) {
+	po, err := NewChaincode("managePO", "po")
+	if err != nil {
+		t.Fatal(err)
+	}
+	agreement, err := NewChaincode("manageAgreement", "agreement")
+	if err != nil {
+		t.Fatal(err)
+	}
+	chaincodes := []*Chaincode{po, agreement}
+	if cc, name, err := Route(chaincodes, "create_po", nil); err != nil || cc != po || name != "create_po" {
+		t.Errorf("Route(create_po): %v", err)
+	}
+	if cc, name, err := Route(chaincodes, "execute_once", []string{"key", "create_agreement"}); err != nil || cc != agreement || name != "execute_once" {
+		t.Errorf("Route(execute_once create_agreement): %v", err)
+	}
+	if _, _, err := Route(chaincodes, "createEscrow", nil); err == nil {
+		t.Errorf("Route found a chaincode for a payment function")
+	}
+	if _, err = NewChaincode("managePO", "purchasing"); err == nil || !strings.Contains(err.Error(), "unknown role purchasing") {
+		t.Errorf("NewChaincode with an unknown role: %v", err)
+	}
+}
+
+func TestCallAs(t *testing.T) {
+	n, err := NewTradeFinance(DefaultBalance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	creator, err := mockstub.Identity("BuyerMSP", "buyer-admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res := n.CallAs(creator, "register_party", "Buyerco", "BuyerMSP"); !res.Failed() {
+		t.Errorf("register_party by a caller who is not the admin")
+	}
+	if res := n.Call("register_party", "Buyerco", "BuyerMSP"); res.Failed() {
+		t.Errorf("register_party by the admin: %s", res.Message())
+	}
+}
+
+func TestLinkedChaincodes(t *testing.T) {
+	n, err := New(DefaultBalance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := n.Call("create_agreement", "AGR1", "PO1", "Created", "Buyerco", "Sellerco", "Shipco", "Buybank", "Sellbank",
+		"Portauth", "2024-01-16", "ITM-1", "Rice", "100", "2500", "2024-03-01", "0", "100", "Contract", "http://docs/contract",
+		"Terms", "true", "false", "false", "false", "Food", "25")
+	if !res.Failed() || !strings.Contains(res.Message(), "PO PO1 Not Found") {
+		t.Errorf("create_agreement without its PO: %s", res.Message())
+	}
+	n.Call("create_po", "PO1", "Sellerco", "Buyerco", "2024-03-01", "2024-01-15", "Accepted", "ITM-1", "Rice", "100", "25",
+		"true", "true")
+	res = n.Call("create_agreement", res.Args...)
+	if res.Failed() || res.Chaincode != "manageAgreement" {
+		t.Errorf("create_agreement: %s", res.Message())
+	}
+	if cc, _ := n.Chaincode("managePO"); len(cc.Keys("AGR")) != 0 {
+		t.Errorf("the Agreement was written to the state of managePO")
+	}
+}
+
+func TestTradeFinance(t *testing.T) {
+	n, err := NewTradeFinance(DefaultBalance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res := n.Call("getAccountDetails"); res.Failed() || res.Chaincode != "tradeFinance" || !strings.Contains(string(res.Response), DefaultBalance) {
+		t.Errorf("getAccountDetails: %s %s", res.Message(), res.Response)
+	}
+}
+
+func TestConsole(t *testing.T) {
+	n, err := New(DefaultBalance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	c := &Console{Network: n, Out: &out}
+	script := "# comment\n" +
+		"create_po PO1 Sellerco Buyerco 2024-03-01 2024-01-15 Accepted ITM-1 Rice 100 25 true true\n" +
+		"! create_po PO1 Sellerco Buyerco 2024-03-01 2024-01-15 Accepted ITM-1 Rice 100 25 true true\n" +
+		"getPO_byID PO1\n" +
+		"state managePO PO_\n" +
+		"as BuyerMSP:buyer-admin\n" +
+		"! payment:register_party Buyerco BuyerMSP\n" +
+		"as\n" +
+		"payment:register_party Buyerco BuyerMSP\n"
+	if err = c.Run(strings.NewReader(script)); err != nil {
+		t.Fatalf("Run: %s\n%s", err, out.String())
+	}
+	for _, expected := range []string{`managePO create_po`, `event po.created {"type":"po.created","schemaVersion":"1","entityType":"po","entityId":"PO1"`,
+		`event errEvent`, `"po_status": "Accepted"`, "\nPO_PO1\n", "managePayment register_party"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("output is missing %s:\n%s", expected, out.String())
+		}
+	}
+
+	out.Reset()
+	err = c.Run(strings.NewReader("create_po PO1\ngetPO_byID PO1\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 1: create_po failed: Incorrect number of arguments") || strings.Contains(out.String(), "getPO_byID") {
+		t.Errorf("a failed script line did not stop the script: %v\n%s", err, out.String())
+	}
+	c.KeepGoing = true
+	out.Reset()
+	err = c.Run(strings.NewReader("create_po PO1\ngetPO_byID PO1\n"))
+	if err == nil || err.Error() != "1 line(s) failed" || !strings.Contains(out.String(), "getPO_byID") {
+		t.Errorf("KeepGoing: %v\n%s", err, out.String())
+	}
+}
+
+func TestTradeScript(t *testing.T) {
+	script, err := os.Open("../../simulator/scripts/trade.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer script.Close()
+	n, err := New(DefaultBalance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err = (&Console{Network: n, Out: &out}).Run(script); err != nil {
+		t.Fatalf("trade.txt: %s\n%s", err, out.String())
+	}
+	if !strings.Contains(out.String(), `"sellerAccountBalance": "102500.00"`) {
+		t.Errorf("the escrow was not released to the seller:\n%s", out.String())
+	}
+}
@@ -0,0 +1,250 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements.  See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership.  The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License.  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+
+package main
+
+import (
+"context"
+"database/sql"
+"flag"
+"fmt"
+"io"
+"log"
+"os"
+"os/signal"
+"strings"
+
+tfclient "github.com/wipro-blockchain/TF-v1/client"
+"github.com/wipro-blockchain/TF-v1/internal/projector"
+"github.com/wipro-blockchain/TF-v1/internal/relay"
+"github.com/wipro-blockchain/TF-v1/internal/router"
+"github.com/wipro-blockchain/TF-v1/internal/simulator"
+)
+
+// ============================================================================================================================
+// Main - keep the SQL projection of the trade records up to date with an event log, or with -simulate with the events of
+// scripts run on the in-process simulated network. With -profile the log is written from the blocks of the channel of the
+// peers. -rebuild replays the log from its first event, -drift compares the projection with the ledger and -repair makes
+// the drifted rows those of the ledger
+// ============================================================================================================================
+func main() {
+	driver := flag.String("driver", "sqlite3", "database/sql driver, sqlite3 (built with -tags sqlite) or postgres (built with -tags postgres)")
+	dsn := flag.String("dsn", "trade.db", "data source name of the database, e.g. a SQLite file or postgres://user@host/db")
+	eventLog := flag.String("log", "events.jsonl", "event log to read, as written by the gateway with -event-log or from the channel with -profile")
+	once := flag.Bool("once", false, "apply the events in the log and exit instead of following it")
+	rebuild := flag.Bool("rebuild", false, "empty the projection and apply the log from its first event")
+	drift := flag.Bool("drift", false, "compare the projection with the ledger and exit with status 1 if they differ")
+	repair := flag.Bool("repair", false, "with -drift, replace the drifted rows with the records of the ledger")
+	gatewayURL := flag.String("gateway", "", "URL of the REST gateway reading the ledger, e.g. http://localhost:8080")
+	gatewayToken := flag.String("gateway-token", os.Getenv("TF_GATEWAY_TOKEN"), "bearer token of the projector at the gateway, $TF_GATEWAY_TOKEN by default")
+	profile := flag.String("profile", "", "connection profile of the peers, follow the blocks of their channel into the event log and, without -gateway, read the ledger there")
+	mspID := flag.String("msp-id", "", "with -profile, MSP ID of the identity reading the blocks")
+	cert := flag.String("cert", "", "with -profile, PEM file of the certificate of the identity")
+	key := flag.String("key", "", "with -profile, PEM file of the private key of the identity")
+	simulate := flag.Bool("simulate", false, "run the scripts given as arguments on the simulated network and project their events")
+	single := flag.Bool("single", false, "with -simulate, host the single TradeFinance chaincode instead of the four separate chaincodes")
+	balance := flag.String("balance", simulator.DefaultBalance, "with -simulate, opening balance of the buyer and seller accounts")
+	verbose := flag.Bool("v", false, "with -simulate, show what the chaincodes print while they run")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [-simulate script ...]\n\nFlags:\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if (flag.NArg() > 0 && !*simulate) || (*repair && !*drift) ||
+		(*profile != "" && (*simulate || *once || *drift || *mspID == "" || *cert == "" || *key == "")) {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	db, err := open(*driver, *dsn)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening the database: %s\n", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+	p := &projector.Projector{DB: db, Dialect: projector.DialectOf(*driver), Log: &relay.Log{Path: *eventLog}, Logf: log.Printf}
+	if *gatewayURL != "" {
+		p.Ledger = tfclient.New(&tfclient.HTTPTransport{BaseURL: strings.TrimSuffix(*gatewayURL, "/"), Token: *gatewayToken})
+	}
+	if *simulate {
+		p.Log = &relay.Log{}
+		network, err := runScripts(p.Log, *single, *balance, *verbose)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		p.Ledger = tfclient.New(&tfclient.Simulated{Network: network})
+		*once, *rebuild = true, true					//the simulated ledger starts empty on every run
+	}
+	if *drift && p.Ledger == nil {
+		fmt.Fprintln(os.Stderr, "-drift reads the ledger, give the gateway with -gateway")
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if *profile != "" {
+		var peer *tfclient.Peer
+		ctx, peer, err = followChannel(ctx, *profile, tfclient.Identity{MSPID: *mspID, Cert: *cert, Key: *key}, p.Log)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error following the channel: %s\n", err)
+			os.Exit(1)
+		}
+		if p.Ledger == nil {
+			p.Ledger = tfclient.New(peer)
+		}
+	}
+	if err = p.Init(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating the tables: %s\n", err)
+		os.Exit(1)
+	}
+	if *rebuild {
+		err = p.Rebuild(ctx)
+	}else if *drift {
+		err = p.Run(ctx, false)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error projecting the events: %s\n", err)
+		os.Exit(1)
+	}
+	if *drift {
+		os.Exit(checkDrift(ctx, p, *repair))
+	}
+	if !*once {
+		log.Printf("Projecting the events of %s into %s", *eventLog, *driver)
+	}
+	if !*rebuild || !*once {
+		err = p.Run(ctx, !*once)
+		if cause := context.Cause(ctx); err == nil && cause != ctx.Err() {
+			err = cause											//the channel could no longer be followed
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error projecting the events: %s\n", err)
+			os.Exit(1)
+		}
+	}
+}
+// ============================================================================================================================
+// open - open the database, the driver must be compiled in
+// ============================================================================================================================
+func open(driver string, dsn string) (*sql.DB, error) {
+	found := false
+	for _, name := range sql.Drivers() {
+		found = found || name == driver
+	}
+	if !found {
+		return nil, fmt.Errorf("no %s driver in this build, build with -tags sqlite or -tags postgres (compiled in: %s)",
+			driver, strings.Join(sql.Drivers(), ", "))
+	}
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	return db, db.Ping()
+}
+// ============================================================================================================================
+// checkDrift - print the drift between the projection and the ledger and repair it when asked, the exit status is 1 when
+// drift was found and left
+// ============================================================================================================================
+func checkDrift(ctx context.Context, p *projector.Projector, repair bool) int {
+	drift, err := p.Drift(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error comparing with the ledger: %s\n", err)
+		return 1
+	}
+	for _, d := range drift {
+		fmt.Println(d)
+	}
+	if len(drift) == 0 {
+		fmt.Println("The projection matches the ledger")
+		return 0
+	}
+	if !repair {
+		return 1
+	}
+	if err = p.Repair(ctx, drift); err != nil {
+		fmt.Fprintf(os.Stderr, "Error repairing the projection: %s\n", err)
+		return 1
+	}
+	fmt.Printf("Repaired %d drifted value(s)\n", len(drift))
+	return 0
+}
+// ============================================================================================================================
+// followChannel - append the events of the blocks of the channel of the peers to eventLog while ctx is not done, read as
+// id, whose Peer is returned to read the ledger. The context returned is done once the channel can no longer be followed,
+// its cause is the error
+// ============================================================================================================================
+func followChannel(ctx context.Context, profilePath string, id tfclient.Identity, eventLog *relay.Log) (context.Context, *tfclient.Peer, error) {
+	profile, err := tfclient.ReadProfile(profilePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	conn, err := profile.Dial()
+	if err != nil {
+		return nil, nil, err
+	}
+	peer, err := tfclient.NewPeer(conn, profile, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	go func() {
+		err := relay.FollowChannel(ctx, peer, eventLog)
+		if err != nil {
+			err = fmt.Errorf("following channel %s: %s", profile.Channel, err.Error())
+		}
+		cancel(err)
+	}()
+	log.Printf("Following the blocks of channel %s into %s", profile.Channel, eventLog.Path)
+	return ctx, peer, nil
+}
+// ============================================================================================================================
+// runScripts - run the scripts named as arguments on a fresh simulated network, its committed events appended to eventLog
+// ============================================================================================================================
+func runScripts(eventLog *relay.Log, single bool, balance string, verbose bool) (*simulator.Network, error) {
+	out := os.Stdout
+	if !verbose {
+		router.Output = io.Discard
+	}
+	var network *simulator.Network
+	var err error
+	if single {
+		network, err = simulator.NewTradeFinance(balance)
+	}else{
+		network, err = simulator.New(balance)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Error starting the simulated network: %s", err)
+	}
+	relay.Watch(network, eventLog, nil)
+	console := &simulator.Console{Network: network, Out: out}
+	for _, script := range flag.Args() {
+		in, err := os.Open(script)
+		if err != nil {
+			return nil, fmt.Errorf("Error opening script: %s", err)
+		}
+		err = console.Run(in)
+		in.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", script, err)
+		}
+	}
+	return network, nil
+}
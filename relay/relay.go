@@ -0,0 +1,171 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements.  See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership.  The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License.  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+
+package main
+
+import (
+"context"
+"flag"
+"fmt"
+"io"
+"log"
+"os"
+"os/signal"
+
+tfclient "github.com/wipro-blockchain/TF-v1/client"
+"github.com/wipro-blockchain/TF-v1/internal/relay"
+"github.com/wipro-blockchain/TF-v1/internal/router"
+"github.com/wipro-blockchain/TF-v1/internal/simulator"
+)
+
+// ============================================================================================================================
+// Main - send the chaincode events of an event log to the partner webhooks, or with -simulate those of scripts run on the
+// in-process simulated network. With -profile the log is written from the blocks of the channel of the peers
+// ============================================================================================================================
+func main() {
+	subscriptions := flag.String("subscriptions", "", "JSON file of the webhook subscriptions")
+	eventLog := flag.String("log", "events.jsonl", "event log to read, as written by the gateway with -event-log or from the channel with -profile")
+	profile := flag.String("profile", "", "connection profile of the peers, follow the blocks of their channel into the event log")
+	mspID := flag.String("msp-id", "", "with -profile, MSP ID of the identity reading the blocks")
+	cert := flag.String("cert", "", "with -profile, PEM file of the certificate of the identity")
+	key := flag.String("key", "", "with -profile, PEM file of the private key of the identity")
+	once := flag.Bool("once", false, "send the events in the log and exit instead of following it")
+	checkpoints := flag.String("checkpoints", "relay-checkpoints.json", "file of the last event sent to each subscription")
+	deadLetters := flag.String("dead-letters", "relay-dead-letters.jsonl", "file of the events that could not be delivered")
+	attempts := flag.Int("attempts", 8, "attempts of a delivery before it is a dead letter")
+	simulate := flag.Bool("simulate", false, "run the scripts given as arguments on the simulated network and send their events")
+	single := flag.Bool("single", false, "with -simulate, host the single TradeFinance chaincode instead of the four separate chaincodes")
+	balance := flag.String("balance", simulator.DefaultBalance, "with -simulate, opening balance of the buyer and seller accounts")
+	verbose := flag.Bool("v", false, "with -simulate, show what the chaincodes print while they run")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s -subscriptions file [flags] [-simulate script ...]\n\nFlags:\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if *subscriptions == "" || (flag.NArg() > 0 && !*simulate) ||
+		(*profile != "" && (*simulate || *once || *mspID == "" || *cert == "" || *key == "")) {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	subs, err := relay.LoadSubscriptions(*subscriptions)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading the subscriptions: %s\n", err)
+		os.Exit(1)
+	}
+	r := &relay.Relay{
+		Log: &relay.Log{Path: *eventLog},
+		Subscriptions: subs,
+		Checkpoints: &relay.Checkpoints{Path: *checkpoints},
+		DeadLetters: &relay.DeadLetters{Path: *deadLetters},
+		MaxAttempts: *attempts,
+		Logf: log.Printf,
+	}
+	if *simulate {
+		r.Log, r.Checkpoints = &relay.Log{}, &relay.Checkpoints{}	//the simulated ledger starts empty on every run
+		if err = runScripts(r.Log, *single, *balance, *verbose); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		*once = true
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if *profile != "" {
+		ctx, err = followChannel(ctx, *profile, tfclient.Identity{MSPID: *mspID, Cert: *cert, Key: *key}, r.Log)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error following the channel: %s\n", err)
+			os.Exit(1)
+		}
+	}
+	if !*once {
+		log.Printf("Relaying the events of %s to %d subscriptions", *eventLog, len(subs))
+	}
+	err = r.Run(ctx, !*once)
+	if cause := context.Cause(ctx); err == nil && cause != ctx.Err() {
+		err = cause												//the channel could no longer be followed
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error relaying the events: %s\n", err)
+		os.Exit(1)
+	}
+}
+// ============================================================================================================================
+// followChannel - append the events of the blocks of the channel of the peers to eventLog while ctx is not done, read as
+// id. The context returned is done once the channel can no longer be followed, its cause is the error
+// ============================================================================================================================
+func followChannel(ctx context.Context, profilePath string, id tfclient.Identity, eventLog *relay.Log) (context.Context, error) {
+	profile, err := tfclient.ReadProfile(profilePath)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := profile.Dial()
+	if err != nil {
+		return nil, err
+	}
+	peer, err := tfclient.NewPeer(conn, profile, id)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	go func() {
+		err := relay.FollowChannel(ctx, peer, eventLog)
+		if err != nil {
+			err = fmt.Errorf("following channel %s: %s", profile.Channel, err.Error())
+		}
+		cancel(err)
+	}()
+	log.Printf("Following the blocks of channel %s into %s", profile.Channel, eventLog.Path)
+	return ctx, nil
+}
+// ============================================================================================================================
+// runScripts - run the scripts named as arguments on a fresh simulated network, its committed events appended to eventLog
+// ============================================================================================================================
+func runScripts(eventLog *relay.Log, single bool, balance string, verbose bool) error {
+	out := os.Stdout
+	if !verbose {
+		router.Output = io.Discard
+	}
+	var network *simulator.Network
+	var err error
+	if single {
+		network, err = simulator.NewTradeFinance(balance)
+	}else{
+		network, err = simulator.New(balance)
+	}
+	if err != nil {
+		return fmt.Errorf("Error starting the simulated network: %s", err)
+	}
+	relay.Watch(network, eventLog, nil)
+	console := &simulator.Console{Network: network, Out: out}
+	for _, script := range flag.Args() {
+		in, err := os.Open(script)
+		if err != nil {
+			return fmt.Errorf("Error opening script: %s", err)
+		}
+		err = console.Run(in)
+		in.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %s", script, err)
+		}
+	}
+	return nil
+}
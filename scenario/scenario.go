@@ -0,0 +1,84 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements.  See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership.  The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License.  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+
+package main
+
+import (
+"flag"
+"fmt"
+"io"
+"os"
+"path/filepath"
+
+"github.com/wipro-blockchain/TF-v1/internal/router"
+"github.com/wipro-blockchain/TF-v1/internal/scenario"
+)
+
+// ============================================================================================================================
+// Main - run the given scenario files, or every scenario of a directory, and print a diff report for each one that fails
+// ============================================================================================================================
+func main() {
+	verbose := flag.Bool("v", false, "list the steps that pass as well")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] scenario.json|directory ...\n\nFlags:\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var files []string
+	for _, arg := range flag.Args() {
+		if info, err := os.Stat(arg); err == nil && info.IsDir() {
+			matches, _ := filepath.Glob(filepath.Join(arg, "*.json"))
+			files = append(files, matches...)
+		}else{
+			files = append(files, arg)
+		}
+	}
+
+	out := os.Stdout
+	router.Output = io.Discard
+	failed := 0
+	for _, file := range files {
+		s, err := scenario.Load(file)
+		if err != nil {
+			fmt.Fprintf(out, "FAIL %s\n", err)
+			failed++
+			continue
+		}
+		report, err := scenario.Run(s)
+		if err != nil {
+			fmt.Fprintf(out, "FAIL %s: %s\n", file, err)
+			failed++
+			continue
+		}
+		report.Write(out, *verbose)
+		if !report.Passed() {
+			failed++
+		}
+	}
+	fmt.Fprintf(out, "%d of %d scenario(s) passed\n", len(files) - failed, len(files))
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,92 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements.  See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership.  The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License.  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+
+package main
+
+import (
+"flag"
+"fmt"
+"io"
+"os"
+
+"github.com/wipro-blockchain/TF-v1/internal/router"
+"github.com/wipro-blockchain/TF-v1/internal/simulator"
+)
+
+// ============================================================================================================================
+// Main - host the trade-finance chaincodes in-process on an in-memory ledger, run the given scripts in order or, without
+// scripts, read commands from the terminal
+// ============================================================================================================================
+func main() {
+	single := flag.Bool("single", false, "host the single TradeFinance chaincode instead of the four separate chaincodes")
+	balance := flag.String("balance", simulator.DefaultBalance, "opening balance of the buyer and seller accounts")
+	keepGoing := flag.Bool("keep-going", false, "carry on with a script after a transaction fails unexpectedly")
+	verbose := flag.Bool("v", false, "show what the chaincodes print while they run")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [script ...]\n\nA script holds one command per line, \"-\" reads one from stdin. Flags:\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	out := os.Stdout
+	if !*verbose {
+		router.Output = io.Discard
+	}
+
+	var network *simulator.Network
+	var err error
+	if *single {
+		network, err = simulator.NewTradeFinance(*balance)
+	}else{
+		network, err = simulator.New(*balance)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error starting the simulated network: %s\n", err)
+		os.Exit(1)
+	}
+	console := &simulator.Console{Network: network, Out: out, KeepGoing: *keepGoing}
+
+	if flag.NArg() == 0 {
+		if info, err := os.Stdin.Stat(); err == nil && info.Mode() & os.ModeCharDevice != 0 {
+			console.Prompt = "tf> "
+			fmt.Fprintln(out, "Trade-finance simulator, type help for the commands")
+		}
+		if err = console.Run(os.Stdin); err != nil {
+			os.Exit(1)
+		}
+		return
+	}
+	for _, script := range flag.Args() {
+		in := os.Stdin
+		if script != "-" {
+			in, err = os.Open(script)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error opening script: %s\n", err)
+				os.Exit(1)
+			}
+		}
+		err = console.Run(in)
+		in.Close()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", script, err)
+			os.Exit(1)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements.  See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership.  The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License.  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+
+
+package main
+
+import (
+"fmt"
+
+"github.com/wipro-blockchain/TF-v1/internal/agreement"
+"github.com/wipro-blockchain/TF-v1/internal/contracts"
+"github.com/wipro-blockchain/TF-v1/internal/payment"
+"github.com/wipro-blockchain/TF-v1/internal/po"
+"github.com/wipro-blockchain/TF-v1/internal/router"
+"github.com/wipro-blockchain/TF-v1/internal/shipment"
+)
+
+// ============================================================================================================================
+// Main - start the single trade-finance chaincode with the PO, Agreement, Payment and Shipment functions in one state,
+// so that e.g. accepting a PO, creating its Agreement and reserving the funds commit together through execute_batch
+// ============================================================================================================================
+func main() {
+	r := router.New("TradeFinance")
+	r.Register("po", po.New())
+	r.Register("agreement", agreement.New(r))
+	r.Register("payment", payment.New(r))
+	r.Register("shipment", shipment.New(r))
+	cc, err := contracts.New(r)
+	if err == nil {
+		err = cc.Start()
+	}
+	if err != nil {
+		fmt.Printf("Error starting trade-finance chaincode: %s", err)
+	}
+}